@@ -0,0 +1,24 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAccountActivity reports whether the account is refreshing and how many requests are in flight
+// against it, to spot hung upstream calls or refreshes stranding an account.
+func (h *Handler) GetAccountActivity(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	activity, ok := h.authManager.Activity(id)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+	c.JSON(http.StatusOK, activity)
+}
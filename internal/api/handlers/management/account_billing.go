@@ -0,0 +1,23 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClearAccountBilling returns a billing suspended account to service after its billing was fixed
+// upstream. Accounts that are not suspended are returned unchanged.
+func (h *Handler) ClearAccountBilling(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	auth, ok, _ := h.authManager.ClearBillingSuspension(c.Request.Context(), strings.TrimSpace(c.Param("id")))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+	c.JSON(http.StatusOK, h.accountStatus(auth))
+}
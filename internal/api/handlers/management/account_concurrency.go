@@ -0,0 +1,40 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type accountConcurrencyRequest struct {
+	MaxConcurrent *int `json:"max_concurrent"`
+}
+
+// SetAccountMaxConcurrent replaces how many requests a single account may serve at once; 0 removes
+// the limit.
+func (h *Handler) SetAccountMaxConcurrent(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	var body accountConcurrencyRequest
+	if err := c.ShouldBindJSON(&body); err != nil || body.MaxConcurrent == nil || *body.MaxConcurrent < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "max_concurrent must be a non-negative integer"})
+		return
+	}
+	auth, ok := h.authManager.GetByID(strings.TrimSpace(c.Param("id")))
+	if !ok || auth == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+	auth.SetMaxConcurrent(*body.MaxConcurrent)
+	auth.UpdatedAt = time.Now()
+	updated, err := h.authManager.Update(c.Request.Context(), auth)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, h.accountStatus(updated))
+}
@@ -0,0 +1,45 @@
+package management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type resetCountersRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// ResetAccountCounters clears the per-account request counters shown in the accounts monitor. An
+// empty body or id list resets every account.
+func (h *Handler) ResetAccountCounters(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	var body resetCountersRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"reset": h.authManager.ResetRequestCounts(body.IDs...)})
+}
+
+// ResetAccountTokenUsage clears the per-account token counters shown in the accounts monitor. An
+// empty body or id list resets every account.
+func (h *Handler) ResetAccountTokenUsage(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	var body resetCountersRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"reset": h.authManager.ResetTokenUsage(body.IDs...)})
+}
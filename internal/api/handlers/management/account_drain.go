@@ -0,0 +1,47 @@
+package management
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+type drainRequest struct {
+	TimeoutSeconds int `json:"timeout_seconds"`
+}
+
+// DrainAccount stops routing new requests to an account and disables it once its in-flight requests
+// finished or the timeout (default 5 minutes) elapsed. It answers 202 with the in-flight count while
+// the drain runs, and 200 when the account could be disabled right away.
+func (h *Handler) DrainAccount(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	var body drainRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&body); err != nil || body.TimeoutSeconds < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+	}
+	status, err := h.authManager.DrainAccount(strings.TrimSpace(c.Param("id")), time.Duration(body.TimeoutSeconds)*time.Second)
+	if err != nil {
+		var authErr *coreauth.Error
+		if errors.As(err, &authErr) && authErr.HTTPStatus != 0 {
+			c.JSON(authErr.HTTPStatus, gin.H{"error": authErr.Message})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if status.Draining {
+		c.JSON(http.StatusAccepted, status)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
@@ -0,0 +1,46 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestDrainAccountDisablesIdleAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := coreauth.NewManager(nil, nil, nil)
+	if _, err := manager.Register(context.Background(), &coreauth.Auth{ID: "a.json", Provider: "gemini", Status: coreauth.StatusActive}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	h := &Handler{authManager: manager}
+	router := gin.New()
+	router.POST("/accounts/:id/drain", h.DrainAccount)
+	call := func(path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := call("/accounts/a.json/drain", `{"timeout_seconds":-1}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("negative timeout: status %d", rec.Code)
+	}
+	rec := call("/accounts/a.json/drain", `{"timeout_seconds":30}`)
+	var status coreauth.DrainStatus
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &status) != nil || !status.Disabled || status.Draining || status.InFlight != 0 {
+		t.Fatalf("drain: status %d body %s", rec.Code, rec.Body.String())
+	}
+	if rec = call("/accounts/a.json/drain", ""); rec.Code != http.StatusConflict {
+		t.Fatalf("draining a disabled account: status %d", rec.Code)
+	}
+	if rec = call("/accounts/missing.json/drain", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown account: status %d", rec.Code)
+	}
+}
@@ -0,0 +1,46 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+type accountFlagsRequest struct {
+	Flags map[string]bool `json:"flags"`
+}
+
+// SetAccountFlags replaces the feature flags of a single account. An empty map clears them so the
+// account follows the gated-features defaults again.
+func (h *Handler) SetAccountFlags(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	var body accountFlagsRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	flags, err := coreauth.NormalizeFeatureFlags(body.Flags)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "known_flags": coreauth.KnownFeatureFlags})
+		return
+	}
+	auth, ok := h.authManager.GetByID(strings.TrimSpace(c.Param("id")))
+	if !ok || auth == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+	auth.SetFeatureFlags(flags)
+	auth.UpdatedAt = time.Now()
+	updated, err := h.authManager.Update(c.Request.Context(), auth)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, h.accountStatus(updated))
+}
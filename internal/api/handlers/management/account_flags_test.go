@@ -0,0 +1,55 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestSetAccountFlags(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := coreauth.NewManager(nil, nil, nil)
+	if _, err := manager.Register(context.Background(), &coreauth.Auth{ID: "a.json", Provider: "gemini", Status: coreauth.StatusActive}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	manager.SetGatedFeatures([]string{coreauth.FeatureEagerRefresh})
+	h := &Handler{authManager: manager}
+	router := gin.New()
+	router.PUT("/accounts/:id/flags", h.SetAccountFlags)
+	call := func(path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := call("/accounts/a.json/flags", `{"flags":{"eager-refresh":true,"prefix-affinity":false}}`)
+	var status AccountStatus
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &status) != nil {
+		t.Fatalf("set flags: status %d body %s", rec.Code, rec.Body.String())
+	}
+	if !status.FeatureFlags[coreauth.FeatureEagerRefresh] || len(status.ActiveFeatures) != 1 || status.ActiveFeatures[0] != coreauth.FeatureEagerRefresh {
+		t.Fatalf("unexpected flags: %+v %v", status.FeatureFlags, status.ActiveFeatures)
+	}
+	if auth, _ := manager.GetByID("a.json"); len(auth.FeatureFlags) != 2 {
+		t.Fatalf("flags not stored: %v", auth.FeatureFlags)
+	}
+
+	if rec = call("/accounts/a.json/flags", `{"flags":{}}`); rec.Code != http.StatusOK {
+		t.Fatalf("clear flags: status %d", rec.Code)
+	}
+	if auth, _ := manager.GetByID("a.json"); auth.FeatureFlags != nil {
+		t.Fatalf("flags not cleared: %v", auth.FeatureFlags)
+	}
+	if rec = call("/accounts/a.json/flags", `{"flags":{"warp":true}}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown flag: status %d", rec.Code)
+	}
+	if rec = call("/accounts/missing.json/flags", `{"flags":{}}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown account: status %d", rec.Code)
+	}
+}
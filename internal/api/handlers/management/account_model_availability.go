@@ -0,0 +1,72 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+type modelWindowsRequest struct {
+	ModelWindows map[string]coreauth.ModelWindowSchedule `json:"model_windows"`
+}
+
+// GetAccountModelWindows reports the availability windows configured per model on an account.
+func (h *Handler) GetAccountModelWindows(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	auth, ok := h.authManager.GetByID(strings.TrimSpace(c.Param("id")))
+	if !ok || auth == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+	windows := auth.ModelWindows()
+	if windows == nil {
+		windows = map[string]coreauth.ModelWindowSchedule{}
+	}
+	c.JSON(http.StatusOK, gin.H{"id": auth.ID, "model_windows": windows})
+}
+
+// PutAccountModelWindows replaces the model availability windows of an account. Outside its windows
+// a model is not routed to the account; an empty map removes every restriction.
+func (h *Handler) PutAccountModelWindows(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	var body modelWindowsRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	windows := make(map[string]coreauth.ModelWindowSchedule, len(body.ModelWindows))
+	for model, schedule := range body.ModelWindows {
+		model = strings.TrimSpace(model)
+		if model == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "model name is required"})
+			return
+		}
+		if err := schedule.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": model + ": " + err.Error()})
+			return
+		}
+		windows[model] = schedule
+	}
+	auth, ok := h.authManager.GetByID(strings.TrimSpace(c.Param("id")))
+	if !ok || auth == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+	auth.SetModelWindows(windows)
+	auth.UpdatedAt = time.Now()
+	updated, err := h.authManager.Update(c.Request.Context(), auth)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, h.accountStatus(updated))
+}
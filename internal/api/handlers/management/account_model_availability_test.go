@@ -0,0 +1,54 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestPutAccountModelWindows(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := coreauth.NewManager(nil, nil, nil)
+	if _, err := manager.Register(context.Background(), &coreauth.Auth{ID: "a.json", Provider: "gemini", Status: coreauth.StatusActive}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	h := &Handler{authManager: manager}
+	router := gin.New()
+	router.GET("/accounts/:id/model-windows", h.GetAccountModelWindows)
+	router.PUT("/accounts/:id/model-windows", h.PutAccountModelWindows)
+	call := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	if rec := call(http.MethodPut, "/accounts/a.json/model-windows", `{"model_windows":{"preview":{"timezone":"Nowhere/Land","windows":[{"start":"09:00","end":"17:00"}]}}}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid timezone: status %d", rec.Code)
+	}
+	rec := call(http.MethodPut, "/accounts/a.json/model-windows", `{"model_windows":{"preview":{"timezone":"Europe/Berlin","windows":[{"days":["mon","tue"],"start":"09:00","end":"17:00"}]}}}`)
+	var status AccountStatus
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &status) != nil || status.ModelWindows["preview"].Timezone != "Europe/Berlin" {
+		t.Fatalf("put: status %d body %s", rec.Code, rec.Body.String())
+	}
+	if auth, _ := manager.GetByID("a.json"); len(auth.ModelWindows()["preview"].Windows) != 1 {
+		t.Fatalf("windows not stored: %+v", auth.Metadata)
+	}
+	if rec = call(http.MethodGet, "/accounts/a.json/model-windows", ""); !strings.Contains(rec.Body.String(), `"days":["mon","tue"]`) {
+		t.Fatalf("get: %s", rec.Body.String())
+	}
+	if rec = call(http.MethodPut, "/accounts/a.json/model-windows", `{"model_windows":{}}`); rec.Code != http.StatusOK {
+		t.Fatalf("clear: status %d", rec.Code)
+	}
+	if auth, _ := manager.GetByID("a.json"); auth.ModelWindows() != nil {
+		t.Fatalf("windows not cleared: %+v", auth.Metadata)
+	}
+	if rec = call(http.MethodGet, "/accounts/missing.json/model-windows", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown account: status %d", rec.Code)
+	}
+}
@@ -2,43 +2,253 @@ package management
 
 import (
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 )
 
 // AccountStatus represents the status of a single auth account for monitoring.
 type AccountStatus struct {
-	ID            string                 `json:"id"`
-	Provider      string                 `json:"provider"`
-	Label         string                 `json:"label"`
-	Email         string                 `json:"email,omitempty"`
-	Status        string                 `json:"status"`
-	StatusMessage string                 `json:"status_message,omitempty"`
-	Disabled      bool                   `json:"disabled"`
-	Unavailable   bool                   `json:"unavailable"`
-	QuotaExceeded bool                   `json:"quota_exceeded"`
-	QuotaReason   string                 `json:"quota_reason,omitempty"`
-	NextRecoverAt *time.Time             `json:"next_recover_at,omitempty"`
-	NextRetryAt   *time.Time             `json:"next_retry_at,omitempty"`
-	BackoffLevel  int                    `json:"backoff_level"`
-	LastError     map[string]interface{} `json:"last_error,omitempty"`
-	LastRefresh   *time.Time             `json:"last_refresh,omitempty"`
-	CreatedAt     time.Time              `json:"created_at"`
-	UpdatedAt     time.Time              `json:"updated_at"`
-	Index         uint64                 `json:"index"`
+	ID                    string                                  `json:"id"`
+	Provider              string                                  `json:"provider"`
+	Label                 string                                  `json:"label"`
+	Email                 string                                  `json:"email,omitempty"`
+	Status                string                                  `json:"status"`
+	StatusMessage         string                                  `json:"status_message,omitempty"`
+	Disabled              bool                                    `json:"disabled"`
+	Draining              bool                                    `json:"draining"`
+	Unavailable           bool                                    `json:"unavailable"`
+	QuotaExceeded         bool                                    `json:"quota_exceeded"`
+	QuotaReason           string                                  `json:"quota_reason,omitempty"`
+	NextRecoverAt         *time.Time                              `json:"next_recover_at,omitempty"`
+	NextRetryAt           *time.Time                              `json:"next_retry_at,omitempty"`
+	BackoffLevel          int                                     `json:"backoff_level"`
+	LastError             map[string]interface{}                  `json:"last_error,omitempty"`
+	LastUnavailableReason string                                  `json:"last_unavailable_reason,omitempty"`
+	LastUnavailableAt     *time.Time                              `json:"last_unavailable_at,omitempty"`
+	DuplicateOf           string                                  `json:"duplicate_of,omitempty"`
+	LastRefresh           *time.Time                              `json:"last_refresh,omitempty"`
+	CreatedAt             time.Time                               `json:"created_at"`
+	UpdatedAt             time.Time                               `json:"updated_at"`
+	Index                 uint64                                  `json:"index"`
+	Tags                  []string                                `json:"tags,omitempty"`
+	FeatureFlags          map[string]bool                         `json:"feature_flags,omitempty"`
+	ActiveFeatures        []string                                `json:"active_features,omitempty"`
+	Family                string                                  `json:"family,omitempty"`
+	Priority              int                                     `json:"priority"`
+	Weight                int                                     `json:"weight"`
+	InFlight              int                                     `json:"in_flight"`
+	MaxConcurrent         int                                     `json:"max_concurrent"`
+	ServedSinceRotation   int                                     `json:"served_since_rotation"`
+	RotationRestUntil     *time.Time                              `json:"rotation_rest_until,omitempty"`
+	ModelRemap            map[string]string                       `json:"model_remap,omitempty"`
+	ModelWindows          map[string]coreauth.ModelWindowSchedule `json:"model_windows,omitempty"`
+	BillingHeaders        bool                                    `json:"billing_headers"`
+	ClientErrorCount      int                                     `json:"client_error_count"`
+	LastEagerRefresh      *time.Time                              `json:"last_eager_refresh,omitempty"`
+	PeakReserve           bool                                    `json:"peak_reserve"`
+	Headroom              *coreauth.RequestHeadroom               `json:"headroom,omitempty"`
+	Shard                 *int                                    `json:"shard,omitempty"`
+	RequestCount          int64                                   `json:"request_count"`
+	SuccessCount          int64                                   `json:"success_count"`
+	FailureCount          int64                                   `json:"failure_count"`
+	EmptyResponseCount    int64                                   `json:"empty_response_count"`
+	PromptTokens          int64                                   `json:"prompt_tokens"`
+	CompletionTokens      int64                                   `json:"completion_tokens"`
+	TotalTokens           int64                                   `json:"total_tokens"`
+	ProviderBreaker       coreauth.CircuitState                   `json:"provider_breaker,omitempty"`
+	RecoveryHistory       []coreauth.RecoveryAttempt              `json:"recovery_history,omitempty"`
+	RateLimit             *coreauth.RateLimitObservation          `json:"rate_limit,omitempty"`
 }
 
 // AccountsMonitorResponse is the response structure for the accounts monitor endpoint.
 type AccountsMonitorResponse struct {
-	Timestamp    time.Time       `json:"timestamp"`
-	TotalCount   int             `json:"total_count"`
-	ActiveCount  int             `json:"active_count"`
-	ErrorCount   int             `json:"error_count"`
-	CooldownCount int            `json:"cooldown_count"`
+	Timestamp            time.Time                 `json:"timestamp"`
+	TotalCount           int                       `json:"total_count"`
+	ActiveCount          int                       `json:"active_count"`
+	ErrorCount           int                       `json:"error_count"`
+	CooldownCount        int                       `json:"cooldown_count"`
+	BillingCount         int                       `json:"billing_suspended_count"`
+	EgressCount          int                       `json:"egress_blocked_count"`
+	PeakReservesReleased bool                      `json:"peak_reserves_released"`
+	PersistenceHealthy   bool                      `json:"persistence_healthy"`
+	SelectionStrategy    string                    `json:"selection_strategy"`
+	Shard                *coreauth.ShardAssignment `json:"shard,omitempty"`
+	ByProvider           map[string]ProviderCounts `json:"by_provider"`
+	// TotalMatched counts the accounts matching the request filters before limit and offset apply.
+	TotalMatched int             `json:"total_matched"`
 	Accounts     []AccountStatus `json:"accounts"`
 }
 
+// ProviderCounts breaks the monitor state counts down for one provider.
+type ProviderCounts struct {
+	Total    int `json:"total"`
+	Active   int `json:"active"`
+	Cooldown int `json:"cooldown"`
+	Error    int `json:"error"`
+	// ProviderBreaker is the provider circuit breaker state: closed, open or half_open.
+	ProviderBreaker coreauth.CircuitState `json:"provider_breaker"`
+}
+
+// Monitor states derived from auth runtime flags; they mirror getAccountStatus in the monitor page.
+const (
+	monitorStateActive   = "active"
+	monitorStateCooldown = "cooldown"
+	monitorStateError    = "error"
+	monitorStateDisabled = "disabled"
+	monitorStateBilling  = "billing_suspended"
+	monitorStateEgress   = "egress_blocked"
+)
+
+// accountMonitorState classifies an auth into one of the monitor states.
+func accountMonitorState(auth *coreauth.Auth, now time.Time) string {
+	if auth.Disabled {
+		return monitorStateDisabled
+	}
+	if auth.Status == coreauth.StatusBillingSuspended {
+		return monitorStateBilling
+	}
+	if auth.Status == coreauth.StatusEgressBlocked {
+		return monitorStateEgress
+	}
+	if auth.Quota.Exceeded || (auth.Unavailable && !auth.Quota.NextRecoverAt.IsZero() && auth.Quota.NextRecoverAt.After(now)) {
+		return monitorStateCooldown
+	}
+	if auth.Unavailable || auth.Status == coreauth.StatusError {
+		return monitorStateError
+	}
+	return monitorStateActive
+}
+
+// accountCounts tallies accounts per monitor state. The accounts monitor and the metrics endpoint share
+// it so both report the same numbers.
+type accountCounts struct {
+	total, active, cooldown, errored, billing, egress int
+}
+
+func (c *accountCounts) add(auth *coreauth.Auth, now time.Time) {
+	c.total++
+	switch accountMonitorState(auth, now) {
+	case monitorStateCooldown:
+		c.cooldown++
+	case monitorStateError:
+		c.errored++
+	case monitorStateBilling:
+		c.billing++
+	case monitorStateEgress:
+		c.egress++
+	case monitorStateActive:
+		c.active++
+	}
+}
+
+// accountStatus builds the monitor representation of auth including the state held by the manager.
+func (h *Handler) accountStatus(auth *coreauth.Auth) AccountStatus {
+	status := buildAccountStatus(auth)
+	status.PeakReserve = h.authManager.IsPeakReserve(auth)
+	status.Headroom = h.authManager.RequestHeadroom(auth)
+	status.ActiveFeatures = h.authManager.ActiveFeatures(auth)
+	status.Draining = h.authManager.IsDraining(auth.ID)
+	status.InFlight = h.authManager.InFlight(auth.ID)
+	status.ProviderBreaker = h.authManager.ProviderCircuitState(auth.Provider)
+	if shard, ok := h.authManager.AccountShard(auth.ID); ok {
+		status.Shard = &shard
+	}
+	if rateLimit, ok := h.authManager.RateLimit(auth.ID); ok {
+		status.RateLimit = &rateLimit
+	}
+	requests := h.authManager.RequestCounts(auth.ID)
+	status.RequestCount, status.SuccessCount, status.FailureCount = requests.Requests, requests.Successes, requests.Failures
+	status.EmptyResponseCount = requests.EmptyResponses
+	tokens := h.authManager.TokenUsage(auth.ID)
+	status.PromptTokens, status.CompletionTokens, status.TotalTokens = tokens.PromptTokens, tokens.CompletionTokens, tokens.TotalTokens
+	status.RecoveryHistory = h.authManager.RecoveryHistory(auth.ID)
+	return status
+}
+
+// buildAccountStatus converts an auth record into its monitor representation.
+func buildAccountStatus(auth *coreauth.Auth) AccountStatus {
+	status := AccountStatus{
+		ID:                  auth.ID,
+		Provider:            auth.Provider,
+		Label:               auth.Label,
+		Status:              string(auth.Status),
+		StatusMessage:       auth.StatusMessage,
+		Disabled:            auth.Disabled,
+		Unavailable:         auth.Unavailable,
+		QuotaExceeded:       auth.Quota.Exceeded,
+		QuotaReason:         auth.Quota.Reason,
+		BackoffLevel:        auth.Quota.BackoffLevel,
+		CreatedAt:           auth.CreatedAt,
+		UpdatedAt:           auth.UpdatedAt,
+		Index:               auth.Index,
+		Tags:                auth.Tags,
+		FeatureFlags:        auth.FeatureFlags,
+		Family:              auth.Family(),
+		Priority:            auth.Priority,
+		Weight:              auth.Weight(),
+		MaxConcurrent:       auth.MaxConcurrent(),
+		ServedSinceRotation: auth.ServedSinceRotation,
+		ModelRemap:          auth.ModelRemap(),
+		ModelWindows:        auth.ModelWindows(),
+		BillingHeaders:      len(auth.BillingHeaders()) > 0,
+		ClientErrorCount:    auth.ClientErrors,
+	}
+
+	// Extract email from metadata
+	if auth.Metadata != nil {
+		if email, ok := auth.Metadata["email"].(string); ok {
+			status.Email = email
+		}
+	}
+
+	// Set recovery times if applicable
+	if !auth.Quota.NextRecoverAt.IsZero() {
+		t := auth.Quota.NextRecoverAt
+		status.NextRecoverAt = &t
+	} else if auth.Status == coreauth.StatusEgressBlocked && !auth.NextRetryAfter.IsZero() {
+		t := auth.NextRetryAfter
+		status.NextRecoverAt = &t
+	}
+	if !auth.NextRetryAfter.IsZero() {
+		t := auth.NextRetryAfter
+		status.NextRetryAt = &t
+	}
+	if auth.RotationRestUntil.After(time.Now()) {
+		t := auth.RotationRestUntil
+		status.RotationRestUntil = &t
+	}
+
+	// Extract last refresh from metadata
+	if ts, ok := extractLastRefreshTimestamp(auth.Metadata); ok {
+		status.LastRefresh = &ts
+	}
+	if !auth.LastEagerRefreshAt.IsZero() {
+		t := auth.LastEagerRefreshAt
+		status.LastEagerRefresh = &t
+	}
+	status.DuplicateOf = auth.DuplicateOf
+	if !auth.LastUnavailableAt.IsZero() {
+		t := auth.LastUnavailableAt
+		status.LastUnavailableReason = auth.LastUnavailableReason
+		status.LastUnavailableAt = &t
+	}
+
+	// Copy last error if present
+	if auth.LastError != nil {
+		status.LastError = map[string]interface{}{
+			"code":    auth.LastError.Code,
+			"message": auth.LastError.Message,
+		}
+		if auth.LastError.HTTPStatus != 0 {
+			status.LastError["http_status"] = auth.LastError.HTTPStatus
+		}
+	}
+	return status
+}
+
 // GetAccountsMonitor returns detailed status of all auth accounts for monitoring.
 func (h *Handler) GetAccountsMonitor(c *gin.Context) {
 	if h == nil {
@@ -51,85 +261,73 @@ func (h *Handler) GetAccountsMonitor(c *gin.Context) {
 		return
 	}
 
-	auths := h.authManager.List()
-	now := time.Now()
+	filter, err := parseAccountsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, h.buildAccountsMonitorPage(time.Now(), filter))
+}
+
+// buildAccountsMonitor snapshots every account for the monitor endpoints.
+func (h *Handler) buildAccountsMonitor(now time.Time) AccountsMonitorResponse {
+	return h.buildAccountsMonitorPage(now, accountsFilter{})
+}
 
+// buildAccountsMonitorPage snapshots the accounts matching filter. The summary counts always cover
+// every account.
+func (h *Handler) buildAccountsMonitorPage(now time.Time, filter accountsFilter) AccountsMonitorResponse {
+	auths := slices.DeleteFunc(h.authManager.List(), func(auth *coreauth.Auth) bool { return auth == nil })
+	filter.sort(auths, now)
 	response := AccountsMonitorResponse{
-		Timestamp: now,
-		Accounts:  make([]AccountStatus, 0, len(auths)),
+		Timestamp:            now,
+		PeakReservesReleased: h.authManager.PeakReservesReleased(now),
+		PersistenceHealthy:   h.authManager.RuntimeStatePersistenceHealth().Healthy,
+		SelectionStrategy:    h.authManager.GlobalStrategy(),
+		ByProvider:           make(map[string]ProviderCounts),
+		Accounts:             make([]AccountStatus, 0, len(auths)),
 	}
+	if shard := h.authManager.ShardAssignment(); shard.Enabled {
+		response.Shard = &shard
+	}
+	var counts accountCounts
+	byProvider := make(map[string]*accountCounts)
 
 	for _, auth := range auths {
 		if auth == nil {
 			continue
 		}
 
-		status := AccountStatus{
-			ID:            auth.ID,
-			Provider:      auth.Provider,
-			Label:         auth.Label,
-			Status:        string(auth.Status),
-			StatusMessage: auth.StatusMessage,
-			Disabled:      auth.Disabled,
-			Unavailable:   auth.Unavailable,
-			QuotaExceeded: auth.Quota.Exceeded,
-			QuotaReason:   auth.Quota.Reason,
-			BackoffLevel:  auth.Quota.BackoffLevel,
-			CreatedAt:     auth.CreatedAt,
-			UpdatedAt:     auth.UpdatedAt,
-			Index:         auth.Index,
-		}
-
-		// Extract email from metadata
-		if auth.Metadata != nil {
-			if email, ok := auth.Metadata["email"].(string); ok {
-				status.Email = email
+		if filter.matches(auth, now) {
+			if response.TotalMatched >= filter.offset && (filter.limit == 0 || len(response.Accounts) < filter.limit) {
+				response.Accounts = append(response.Accounts, h.accountStatus(auth))
 			}
+			response.TotalMatched++
 		}
-
-		// Set recovery times if applicable
-		if !auth.Quota.NextRecoverAt.IsZero() {
-			t := auth.Quota.NextRecoverAt
-			status.NextRecoverAt = &t
-		}
-		if !auth.NextRetryAfter.IsZero() {
-			t := auth.NextRetryAfter
-			status.NextRetryAt = &t
-		}
-
-		// Extract last refresh from metadata
-		if ts, ok := extractLastRefreshTimestamp(auth.Metadata); ok {
-			status.LastRefresh = &ts
-		}
-
-		// Copy last error if present
-		if auth.LastError != nil {
-			status.LastError = map[string]interface{}{
-				"code":    auth.LastError.Code,
-				"message": auth.LastError.Message,
-			}
-			if auth.LastError.HTTPStatus != 0 {
-				status.LastError["http_status"] = auth.LastError.HTTPStatus
-			}
+		counts.add(auth, now)
+		providerCounts := byProvider[auth.Provider]
+		if providerCounts == nil {
+			providerCounts = &accountCounts{}
+			byProvider[auth.Provider] = providerCounts
 		}
-
-		response.Accounts = append(response.Accounts, status)
-
-		// Count statistics
-		response.TotalCount++
-		if auth.Disabled {
-			continue
-		}
-		if auth.Quota.Exceeded || (auth.Unavailable && !auth.Quota.NextRecoverAt.IsZero() && auth.Quota.NextRecoverAt.After(now)) {
-			response.CooldownCount++
-		} else if auth.Unavailable || auth.Status == "error" {
-			response.ErrorCount++
-		} else {
-			response.ActiveCount++
+		providerCounts.add(auth, now)
+	}
+	for provider, c := range byProvider {
+		response.ByProvider[provider] = ProviderCounts{
+			Total:           c.total,
+			Active:          c.active,
+			Cooldown:        c.cooldown,
+			Error:           c.errored,
+			ProviderBreaker: h.authManager.ProviderCircuitState(provider),
 		}
 	}
-
-	c.JSON(http.StatusOK, response)
+	response.TotalCount = counts.total
+	response.ActiveCount = counts.active
+	response.ErrorCount = counts.errored
+	response.CooldownCount = counts.cooldown
+	response.BillingCount = counts.billing
+	response.EgressCount = counts.egress
+	return response
 }
 
 // ServeAccountMonitorPage serves the account monitor HTML page.
@@ -180,7 +378,15 @@ const accountMonitorHTML = `<!DOCTYPE html>
         .stat-card.active .value { color: #3fb950; }
         .stat-card.error .value { color: #f85149; }
         .stat-card.cooldown .value { color: #d29922; }
+        .stat-card.billing_suspended .value { color: #a371f7; }
+        .stat-card.egress_blocked .value { color: #db6d28; }
         .stat-card.total .value { color: #58a6ff; }
+        .provider-stats { display: flex; gap: 10px; flex-wrap: wrap; margin-bottom: 20px; font-size: 13px; }
+        .provider-stat { background: #161b22; border: 1px solid #30363d; border-radius: 6px; padding: 6px 10px; }
+        .provider-stat .name { color: #58a6ff; font-weight: 600; margin-right: 6px; }
+        .provider-stat .active { color: #3fb950; }
+        .provider-stat .cooldown { color: #d29922; }
+        .provider-stat .error { color: #f85149; }
         .controls {
             display: flex;
             gap: 10px;
@@ -205,6 +411,10 @@ const accountMonitorHTML = `<!DOCTYPE html>
         button:hover { background: #2ea043; }
         button.secondary { background: #21262d; border-color: #30363d; }
         button.secondary:hover { background: #30363d; }
+        button.danger { background: #21262d; border-color: #f85149; color: #f85149; }
+        button.danger:hover { background: #3d1d1d; }
+        .account-actions { display: flex; justify-content: flex-end; gap: 8px; margin-top: 12px; }
+        .account-actions button { padding: 4px 10px; font-size: 12px; }
         .filter-group { display: flex; gap: 5px; align-items: center; }
         .filter-group label { font-size: 12px; color: #8b949e; }
         .accounts-grid {
@@ -224,6 +434,8 @@ const accountMonitorHTML = `<!DOCTYPE html>
         .account-card.status-active { border-left: 3px solid #3fb950; }
         .account-card.status-error { border-left: 3px solid #f85149; }
         .account-card.status-cooldown { border-left: 3px solid #d29922; }
+        .account-card.status-billing_suspended { border-left: 3px solid #a371f7; }
+        .account-card.status-egress_blocked { border-left: 3px solid #db6d28; }
         .account-card.status-disabled { border-left: 3px solid #484f58; opacity: 0.6; }
         .account-header {
             display: flex;
@@ -269,6 +481,8 @@ const accountMonitorHTML = `<!DOCTYPE html>
         .status-dot.active { background: #3fb950; }
         .status-dot.error { background: #f85149; }
         .status-dot.cooldown { background: #d29922; animation: pulse 2s infinite; }
+        .status-dot.billing_suspended { background: #a371f7; }
+        .status-dot.egress_blocked { background: #db6d28; }
         .status-dot.disabled { background: #484f58; }
         @keyframes pulse {
             0%, 100% { opacity: 1; }
@@ -292,6 +506,8 @@ const accountMonitorHTML = `<!DOCTYPE html>
         .detail-row .value.error { color: #f85149; }
         .detail-row .value.warning { color: #d29922; }
         .detail-row .value.success { color: #3fb950; }
+        .detail-row .value .success { color: #3fb950; }
+        .detail-row .value .error { color: #f85149; }
         .error-message {
             background: #f8514915;
             border: 1px solid #f8514930;
@@ -363,6 +579,8 @@ const accountMonitorHTML = `<!DOCTYPE html>
                         <option value="active">Active</option>
                         <option value="cooldown">Cooldown</option>
                         <option value="error">Error</option>
+                        <option value="billing_suspended">Billing Suspended</option>
+                        <option value="egress_blocked">Egress Blocked</option>
                         <option value="disabled">Disabled</option>
                     </select>
                 </div>
@@ -370,9 +588,10 @@ const accountMonitorHTML = `<!DOCTYPE html>
                 <div class="filter-group">
                     <label>Auto:</label>
                     <select id="autoRefresh">
+                        <option value="live" selected>Live</option>
                         <option value="0">Off</option>
                         <option value="5">5s</option>
-                        <option value="10" selected>10s</option>
+                        <option value="10">10s</option>
                         <option value="30">30s</option>
                         <option value="60">60s</option>
                     </select>
@@ -384,7 +603,10 @@ const accountMonitorHTML = `<!DOCTYPE html>
             <div class="stat-card active"><div class="label">Active</div><div class="value" id="statActive">-</div></div>
             <div class="stat-card cooldown"><div class="label">Cooldown</div><div class="value" id="statCooldown">-</div></div>
             <div class="stat-card error"><div class="label">Error</div><div class="value" id="statError">-</div></div>
+            <div class="stat-card billing_suspended"><div class="label">Billing Suspended</div><div class="value" id="statBilling">-</div></div>
+            <div class="stat-card egress_blocked"><div class="label">Egress Blocked</div><div class="value" id="statEgress">-</div></div>
         </div>
+        <div class="provider-stats" id="providerStats"></div>
         <div class="accounts-grid" id="accountsGrid"></div>
     </div>
     <div class="toast" id="toast"></div>
@@ -392,6 +614,8 @@ const accountMonitorHTML = `<!DOCTYPE html>
     <script>
         let accounts = [];
         let autoRefreshInterval = null;
+        let streamController = null;
+        let streamRetryTimer = null;
         const API_KEY = localStorage.getItem('management_key') || '';
 
         async function fetchAccounts() {
@@ -426,11 +650,22 @@ const accountMonitorHTML = `<!DOCTYPE html>
             document.getElementById('statActive').textContent = data.active_count;
             document.getElementById('statCooldown').textContent = data.cooldown_count;
             document.getElementById('statError').textContent = data.error_count;
+            document.getElementById('statBilling').textContent = data.billing_suspended_count;
+            document.getElementById('statEgress').textContent = data.egress_blocked_count;
             document.getElementById('lastUpdate').textContent = new Date(data.timestamp).toLocaleTimeString();
+            const byProvider = data.by_provider || {};
+            document.getElementById('providerStats').innerHTML = Object.keys(byProvider).sort().map(name => {
+                const p = byProvider[name];
+                return '<div class="provider-stat"><span class="name">' + escapeHtml(name || 'unknown') + '</span>' +
+                    p.total + ' total · <span class="active">' + p.active + ' active</span> · <span class="cooldown">' +
+                    p.cooldown + ' cooldown</span> · <span class="error">' + p.error + ' error</span></div>';
+            }).join('');
         }
 
         function getAccountStatus(account) {
             if (account.disabled) return 'disabled';
+            if (account.status === 'billing_suspended') return 'billing_suspended';
+            if (account.status === 'egress_blocked') return 'egress_blocked';
             if (account.quota_exceeded) return 'cooldown';
             if (account.unavailable && account.next_recover_at) return 'cooldown';
             if (account.unavailable || account.status === 'error') return 'error';
@@ -474,6 +709,22 @@ const accountMonitorHTML = `<!DOCTYPE html>
                     }
                 }
 
+                let nextRetry = '';
+                if (account.next_retry_at) {
+                    const retryAt = new Date(account.next_retry_at).getTime();
+                    nextRetry = retryAt > now ? formatDuration(retryAt - now) : 'due';
+                }
+                let lastAttempt = '';
+                if (account.recovery_history && account.recovery_history.length) {
+                    const attempt = account.recovery_history[account.recovery_history.length - 1];
+                    const failures = account.recovery_history.filter(a => a.result !== 'success').length;
+                    const ago = now - new Date(attempt.at).getTime();
+                    lastAttempt = '<span class="' + (attempt.result === 'success' ? 'success' : 'error') + '">' + escapeHtml(attempt.result) +
+                        (attempt.http_status ? ' (' + attempt.http_status + ')' : '') + '</span> ' +
+                        (ago < 1000 ? 'just now' : formatDuration(ago) + ' ago') + ', ' +
+                        failures + '/' + account.recovery_history.length + ' failed';
+                }
+
                 let errorHtml = '';
                 if (account.last_error && account.last_error.message) {
                     errorHtml = '<div class="error-message">' + escapeHtml(account.last_error.message) + '</div>';
@@ -494,19 +745,36 @@ const accountMonitorHTML = `<!DOCTYPE html>
                     '<div class="account-details">' +
                         (account.quota_reason ? '<div class="detail-row"><span class="label">Quota Reason</span><span class="value warning">' + escapeHtml(account.quota_reason) + '</span></div>' : '') +
                         (recoveryTime ? '<div class="detail-row"><span class="label">Recovery In</span><span class="value countdown">' + recoveryTime + '</span></div>' : '') +
+                        (nextRetry ? '<div class="detail-row"><span class="label">Next Retry In</span><span class="value countdown">' + nextRetry + '</span></div>' : '') +
+                        (lastAttempt ? '<div class="detail-row"><span class="label">Last Retry</span><span class="value">' + lastAttempt + '</span></div>' : '') +
+                        (account.tags && account.tags.length ? '<div class="detail-row"><span class="label">Tags</span><span class="value">' + escapeHtml(account.tags.join(', ')) + '</span></div>' : '') +
+                        (account.active_features && account.active_features.length ? '<div class="detail-row"><span class="label">Features</span><span class="value">' + escapeHtml(account.active_features.join(', ')) + '</span></div>' : '') +
                         (account.backoff_level > 0 ? '<div class="detail-row"><span class="label">Backoff Level</span><span class="value">' + account.backoff_level + '</span></div>' : '') +
+                        (account.rate_limit ? '<div class="detail-row"><span class="label">Rate Limit Left</span><span class="value' + (account.rate_limit.cordoned_until ? ' warning' : '') + '">' + account.rate_limit.remaining + (account.rate_limit.limit ? ' / ' + account.rate_limit.limit : '') + (account.rate_limit.cordoned_until ? ' (cordoned)' : '') + '</span></div>' : '') +
+                        '<div class="detail-row"><span class="label">Requests</span><span class="value">' + (account.request_count || 0) + ' (<span class="success">' + (account.success_count || 0) + ' ok</span> / <span class="error">' + (account.failure_count || 0) + ' failed</span>)</span></div>' +
+                        (account.total_tokens > 0 ? '<div class="detail-row"><span class="label">Tokens</span><span class="value">' + account.total_tokens + ' (' + (account.prompt_tokens || 0) + ' prompt / ' + (account.completion_tokens || 0) + ' completion)</span></div>' : '') +
+                        (account.provider_breaker && account.provider_breaker !== 'closed' ? '<div class="detail-row"><span class="label">Provider breaker</span><span class="value">' + escapeHtml(account.provider_breaker.replace('_', '-')) + '</span></div>' : '') +
+                        (account.empty_response_count > 0 ? '<div class="detail-row"><span class="label">Empty Responses</span><span class="value warning">' + account.empty_response_count + '</span></div>' : '') +
                         '<div class="detail-row"><span class="label">Last Refresh</span><span class="value">' + (account.last_refresh ? new Date(account.last_refresh).toLocaleString() : '-') + '</span></div>' +
                         '<div class="detail-row"><span class="label">Updated</span><span class="value">' + new Date(account.updated_at).toLocaleString() + '</span></div>' +
                     '</div>' +
                     errorHtml +
+                    '<div class="account-actions">' +
+                        (account.disabled
+                            ? '<button data-id="' + escapeHtml(account.id) + '" onclick="setAccountDisabled(this.dataset.id, false)">Enable</button>'
+                            : '<button class="danger" data-id="' + escapeHtml(account.id) + '" onclick="setAccountDisabled(this.dataset.id, true)">Disable</button>') +
+                    '</div>' +
                 '</div>';
             }).join('');
         }
 
         function getStatusText(account, status, recoveryTime) {
             if (status === 'disabled') return 'Disabled';
+            if (account.draining) return 'Draining (no new requests)';
             if (status === 'cooldown') return 'Cooldown' + (recoveryTime ? ' (' + recoveryTime + ')' : '');
             if (status === 'error') return account.status_message || 'Error';
+            if (status === 'billing_suspended') return 'Billing suspended' + (account.status_message ? ': ' + escapeHtml(account.status_message) : '');
+            if (status === 'egress_blocked') return 'Blocked from current egress (not a credential issue)' + (recoveryTime ? ' (' + recoveryTime + ')' : '');
             return 'Active';
         }
 
@@ -522,18 +790,93 @@ const accountMonitorHTML = `<!DOCTYPE html>
             setTimeout(() => toast.classList.remove('show'), 3000);
         }
 
+        async function setAccountDisabled(id, disable) {
+            const action = disable ? 'disable' : 'enable';
+            try {
+                const headers = { 'Content-Type': 'application/json' };
+                if (API_KEY) headers['Authorization'] = 'Bearer ' + API_KEY;
+                const resp = await fetch('/v0/management/accounts/' + encodeURIComponent(id) + '/' + action, { method: 'POST', headers });
+                if (!resp.ok) {
+                    const body = await resp.json().catch(() => ({}));
+                    throw new Error(body.error || 'HTTP ' + resp.status);
+                }
+                showToast('Account ' + action + 'd');
+                refreshData();
+            } catch (e) {
+                showToast('Failed to ' + action + ' account: ' + e.message, true);
+            }
+        }
+
+        function applySnapshot(data) {
+            accounts = data.accounts;
+            updateStats(data);
+            renderAccounts(data);
+        }
+
         async function refreshData() {
             const data = await fetchAccounts();
-            if (data) {
-                accounts = data.accounts;
-                updateStats(data);
-                renderAccounts(data);
+            if (data) applySnapshot(data);
+        }
+
+        function stopPolling() {
+            if (autoRefreshInterval) clearInterval(autoRefreshInterval);
+            autoRefreshInterval = null;
+        }
+
+        function stopStream() {
+            if (streamController) streamController.abort();
+            streamController = null;
+            if (streamRetryTimer) clearTimeout(streamRetryTimer);
+            streamRetryTimer = null;
+        }
+
+        // subscribeStream reads snapshots from the SSE endpoint. fetch is used instead of EventSource so
+        // the management key travels in the Authorization header like every other request.
+        async function subscribeStream() {
+            stopStream();
+            const controller = new AbortController();
+            streamController = controller;
+            const headers = {};
+            if (API_KEY) headers['Authorization'] = 'Bearer ' + API_KEY;
+            try {
+                const resp = await fetch('/v0/management/accounts-monitor/stream', { headers, signal: controller.signal });
+                if (!resp.ok || !resp.body) throw new Error('HTTP ' + resp.status);
+                stopPolling();
+                const reader = resp.body.getReader();
+                const decoder = new TextDecoder();
+                let buffer = '';
+                while (true) {
+                    const { value, done } = await reader.read();
+                    if (done) break;
+                    buffer += decoder.decode(value, { stream: true });
+                    let end;
+                    while ((end = buffer.indexOf('\n\n')) >= 0) {
+                        const block = buffer.slice(0, end);
+                        buffer = buffer.slice(end + 2);
+                        const data = block.split('\n').filter(l => l.startsWith('data:')).map(l => l.slice(5).replace(/^ /, '')).join('\n');
+                        if (data) applySnapshot(JSON.parse(data));
+                    }
+                }
+                throw new Error('stream closed');
+            } catch (e) {
+                if (controller.signal.aborted) return;
+                // Poll until the stream can be reopened.
+                streamController = null;
+                stopPolling();
+                autoRefreshInterval = setInterval(refreshData, 10000);
+                streamRetryTimer = setTimeout(subscribeStream, 30000);
             }
         }
 
         function setupAutoRefresh() {
-            if (autoRefreshInterval) clearInterval(autoRefreshInterval);
-            const seconds = parseInt(document.getElementById('autoRefresh').value);
+            stopStream();
+            stopPolling();
+            const mode = document.getElementById('autoRefresh').value;
+            if (mode === 'live') {
+                subscribeStream();
+                return;
+            }
+            const seconds = parseInt(mode);
             if (seconds > 0) {
                 autoRefreshInterval = setInterval(refreshData, seconds * 1000);
             }
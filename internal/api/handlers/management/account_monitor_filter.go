@@ -0,0 +1,118 @@
+package management
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// accountsFilter narrows the accounts returned by the monitor; the zero value matches every account
+// and returns them all.
+type accountsFilter struct {
+	provider string
+	// status is a monitor state such as "active" or "cooldown".
+	status string
+	// query is matched case-insensitively as a substring of the id, label and email.
+	query  string
+	limit  int
+	offset int
+	// sortKey orders the accounts; empty means index order.
+	sortKey  string
+	sortDesc bool
+}
+
+// accountSortKeys lists the accepted values of the sort query parameter.
+var accountSortKeys = []string{"index", "updated_at", "status", "provider", "backoff_level"}
+
+// accountStateRank orders monitor states for sort=status, most in need of attention first.
+var accountStateRank = map[string]int{
+	monitorStateError:    0,
+	monitorStateCooldown: 1,
+	monitorStateBilling:  2,
+	monitorStateEgress:   3,
+	monitorStateDisabled: 4,
+	monitorStateActive:   5,
+}
+
+// parseAccountsFilter reads the provider, status, q, limit, offset and sort query parameters. sort
+// takes one of accountSortKeys, prefixed with "-" for descending order.
+func parseAccountsFilter(c *gin.Context) (accountsFilter, error) {
+	filter := accountsFilter{
+		provider: strings.ToLower(strings.TrimSpace(c.Query("provider"))),
+		status:   strings.ToLower(strings.TrimSpace(c.Query("status"))),
+		query:    strings.ToLower(strings.TrimSpace(c.Query("q"))),
+	}
+	limit, err := parseLimit(c.Query("limit"))
+	if err != nil {
+		return accountsFilter{}, fmt.Errorf("invalid limit: %w", err)
+	}
+	filter.limit = limit
+	if raw := strings.TrimSpace(c.Query("offset")); raw != "" {
+		offset, errOffset := strconv.Atoi(raw)
+		if errOffset != nil || offset < 0 {
+			return accountsFilter{}, fmt.Errorf("invalid offset: must be a non-negative integer")
+		}
+		filter.offset = offset
+	}
+	if raw := strings.ToLower(strings.TrimSpace(c.Query("sort"))); raw != "" {
+		filter.sortKey = strings.TrimPrefix(raw, "-")
+		filter.sortDesc = strings.HasPrefix(raw, "-")
+		if !slices.Contains(accountSortKeys, filter.sortKey) {
+			return accountsFilter{}, fmt.Errorf("invalid sort: must be one of %s, optionally prefixed with -", strings.Join(accountSortKeys, ", "))
+		}
+	}
+	return filter, nil
+}
+
+// sort orders auths by the filter's sort key, falling back to index and then id so pages stay
+// stable between requests.
+func (f accountsFilter) sort(auths []*coreauth.Auth, now time.Time) {
+	compare := func(a, b *coreauth.Auth) int {
+		switch f.sortKey {
+		case "updated_at":
+			return a.UpdatedAt.Compare(b.UpdatedAt)
+		case "status":
+			return cmp.Compare(accountStateRank[accountMonitorState(a, now)], accountStateRank[accountMonitorState(b, now)])
+		case "provider":
+			return cmp.Compare(strings.ToLower(a.Provider), strings.ToLower(b.Provider))
+		case "backoff_level":
+			return cmp.Compare(a.Quota.BackoffLevel, b.Quota.BackoffLevel)
+		}
+		return 0
+	}
+	slices.SortStableFunc(auths, func(a, b *coreauth.Auth) int {
+		order := compare(a, b)
+		if order == 0 {
+			order = cmp.Or(cmp.Compare(a.Index, b.Index), strings.Compare(a.ID, b.ID))
+		}
+		if f.sortDesc {
+			return -order
+		}
+		return order
+	})
+}
+
+func (f accountsFilter) matches(auth *coreauth.Auth, now time.Time) bool {
+	if f.provider != "" && !strings.EqualFold(auth.Provider, f.provider) {
+		return false
+	}
+	if f.status != "" && accountMonitorState(auth, now) != f.status {
+		return false
+	}
+	if f.query == "" {
+		return true
+	}
+	email, _ := auth.Metadata["email"].(string)
+	for _, field := range []string{auth.ID, auth.Label, email} {
+		if strings.Contains(strings.ToLower(field), f.query) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package management
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+const (
+	// accountMonitorStreamCheckInterval is how often account states are compared for changes.
+	accountMonitorStreamCheckInterval = time.Second
+	accountMonitorStreamHeartbeat     = 30 * time.Second
+)
+
+// accountStatesSignature fingerprints the state of every account so the stream only emits when
+// something visible changed. Request counters are left out; they change on every request.
+func accountStatesSignature(auths []*coreauth.Auth, now time.Time) string {
+	lines := make([]string, 0, len(auths))
+	for _, auth := range auths {
+		if auth == nil {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s|%s|%s|%s|%t|%t|%t|%d|%d|%d",
+			auth.ID, accountMonitorState(auth, now), auth.Status, auth.StatusMessage, auth.Disabled, auth.Unavailable,
+			auth.Quota.Exceeded, auth.Quota.BackoffLevel, auth.Quota.NextRecoverAt.Unix(), auth.NextRetryAfter.Unix()))
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+// StreamAccountsMonitor pushes accounts monitor snapshots as server-sent events whenever an account
+// changes state, with a heartbeat comment when nothing changed for a while. When live usage is on,
+// "usage" events carry the running usage of tracked streams at the configured interval. Snapshots
+// honour the same filter, sort and paging query parameters as GetAccountsMonitor.
+func (h *Handler) StreamAccountsMonitor(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	filter, err := parseAccountsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	ticker := time.NewTicker(accountMonitorStreamCheckInterval)
+	defer ticker.Stop()
+	ctx := c.Request.Context()
+
+	now := time.Now()
+	last := accountStatesSignature(h.authManager.List(), now)
+	lastSent, lastUsage := now, now
+	c.SSEvent("accounts", h.buildAccountsMonitorPage(now, filter))
+	c.Writer.Flush()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case now := <-ticker.C:
+			if signature := accountStatesSignature(h.authManager.List(), now); signature != last {
+				last, lastSent = signature, now
+				c.SSEvent("accounts", h.buildAccountsMonitorPage(now, filter))
+			} else if now.Sub(lastSent) >= accountMonitorStreamHeartbeat {
+				lastSent = now
+				_, _ = io.WriteString(w, ": heartbeat\n\n")
+			}
+			if interval := h.authManager.LiveUsageInterval(); interval > 0 && now.Sub(lastUsage) >= interval {
+				lastUsage = now
+				if streams := h.authManager.LiveStreamUsage(); len(streams) > 0 {
+					lastSent = now
+					c.SSEvent("usage", buildLiveUsageReport(streams, now))
+				}
+			}
+			return true
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package management
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestAccountStatesSignatureTracksStateChanges(t *testing.T) {
+	now := time.Now()
+	auth := &coreauth.Auth{ID: "a.json", Provider: "gemini", Status: coreauth.StatusActive}
+	before := accountStatesSignature([]*coreauth.Auth{auth}, now)
+	auth.ServedSinceRotation++
+	if accountStatesSignature([]*coreauth.Auth{auth}, now) != before {
+		t.Fatal("traffic alone changed the signature")
+	}
+	auth.Unavailable = true
+	auth.Quota = coreauth.QuotaState{NextRecoverAt: now.Add(time.Minute)}
+	cooling := accountStatesSignature([]*coreauth.Auth{auth}, now)
+	if cooling == before {
+		t.Fatal("cooldown did not change the signature")
+	}
+	if accountStatesSignature([]*coreauth.Auth{auth}, now.Add(2*time.Minute)) == cooling {
+		t.Fatal("an expired cooldown did not change the signature")
+	}
+}
+
+func TestStreamAccountsMonitorSendsSnapshots(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := coreauth.NewManager(nil, nil, nil)
+	if _, err := manager.Register(context.Background(), &coreauth.Auth{ID: "a.json", Provider: "gemini", Status: coreauth.StatusActive}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	h := &Handler{authManager: manager}
+	router := gin.New()
+	router.GET("/stream", h.StreamAccountsMonitor)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+	resp, err := http.Get(server.URL + "/stream")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	events := make(chan string, 4)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+		for scanner.Scan() {
+			if line := scanner.Text(); strings.HasPrefix(line, "data:") {
+				events <- line
+			}
+		}
+		close(events)
+	}()
+	first := <-events
+	if !strings.Contains(first, `"disabled":false`) {
+		t.Fatalf("unexpected initial snapshot: %s", first)
+	}
+	auth, _ := manager.GetByID("a.json")
+	auth.Disabled = true
+	if _, err = manager.Update(context.Background(), auth); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	select {
+	case changed := <-events:
+		if !strings.Contains(changed, `"disabled":true`) {
+			t.Fatalf("change not streamed: %s", changed)
+		}
+	case <-time.After(3 * accountMonitorStreamCheckInterval):
+		t.Fatal("no snapshot after the account changed")
+	}
+}
@@ -0,0 +1,133 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestBuildAccountsMonitorCountsByProvider(t *testing.T) {
+	now := time.Now()
+	manager := coreauth.NewManager(nil, nil, nil)
+	for _, auth := range []*coreauth.Auth{
+		{ID: "g1.json", Provider: "gemini", Status: coreauth.StatusActive},
+		{ID: "g2.json", Provider: "gemini", Status: coreauth.StatusActive},
+		{ID: "c1.json", Provider: "codex", Status: coreauth.StatusError, Unavailable: true},
+		{ID: "c2.json", Provider: "codex", Status: coreauth.StatusActive, Unavailable: true, Quota: coreauth.QuotaState{NextRecoverAt: now.Add(time.Hour)}},
+	} {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	h := &Handler{authManager: manager}
+
+	resp := h.buildAccountsMonitor(now)
+	if resp.TotalCount != 4 || resp.ActiveCount != 2 {
+		t.Fatalf("unexpected global counts: total=%d active=%d", resp.TotalCount, resp.ActiveCount)
+	}
+	if got := resp.ByProvider["gemini"]; got != (ProviderCounts{Total: 2, Active: 2, ProviderBreaker: coreauth.CircuitClosed}) {
+		t.Fatalf("unexpected gemini counts: %+v", got)
+	}
+	if got := resp.ByProvider["codex"]; got != (ProviderCounts{Total: 2, Cooldown: 1, Error: 1, ProviderBreaker: coreauth.CircuitClosed}) {
+		t.Fatalf("unexpected codex counts: %+v", got)
+	}
+}
+
+func TestAccountsMonitorFiltersAndPages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := coreauth.NewManager(nil, nil, nil)
+	for _, auth := range []*coreauth.Auth{
+		{ID: "g1.json", Provider: "gemini", Status: coreauth.StatusActive, Metadata: map[string]any{"email": "Alice@example.com"}},
+		{ID: "g2.json", Provider: "gemini", Status: coreauth.StatusActive},
+		{ID: "g3.json", Provider: "gemini", Status: coreauth.StatusActive, Label: "team-alice"},
+		{ID: "c1.json", Provider: "codex", Status: coreauth.StatusError, Unavailable: true},
+	} {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	h := &Handler{authManager: manager}
+	router := gin.New()
+	router.GET("/accounts", h.GetAccountsMonitor)
+	get := func(query string) (int, AccountsMonitorResponse) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/accounts"+query, nil))
+		var resp AccountsMonitorResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec.Code, resp
+	}
+	ids := func(resp AccountsMonitorResponse) string {
+		out := make([]string, 0, len(resp.Accounts))
+		for _, account := range resp.Accounts {
+			out = append(out, account.ID)
+		}
+		return strings.Join(out, ",")
+	}
+
+	if _, resp := get(""); resp.TotalMatched != 4 || len(resp.Accounts) != 4 {
+		t.Fatalf("unfiltered: matched %d, %d accounts", resp.TotalMatched, len(resp.Accounts))
+	}
+	if _, resp := get("?provider=gemini&limit=2&offset=1"); resp.TotalMatched != 3 || ids(resp) != "g2.json,g3.json" || resp.TotalCount != 4 {
+		t.Fatalf("paged gemini: matched %d, accounts %s, total %d", resp.TotalMatched, ids(resp), resp.TotalCount)
+	}
+	if _, resp := get("?status=error"); ids(resp) != "c1.json" {
+		t.Fatalf("status filter: %s", ids(resp))
+	}
+	if _, resp := get("?q=ALICE&limit=10"); resp.TotalMatched != 2 || ids(resp) != "g1.json,g3.json" {
+		t.Fatalf("query filter: matched %d, accounts %s", resp.TotalMatched, ids(resp))
+	}
+	if code, _ := get("?offset=-1"); code != http.StatusBadRequest {
+		t.Fatalf("negative offset: status %d", code)
+	}
+}
+
+func TestAccountsMonitorSorts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := coreauth.NewManager(nil, nil, nil)
+	for _, auth := range []*coreauth.Auth{
+		{ID: "b.json", Provider: "gemini", Status: coreauth.StatusActive},
+		{ID: "a.json", Provider: "codex", Status: coreauth.StatusError, Unavailable: true, Quota: coreauth.QuotaState{BackoffLevel: 2}},
+		{ID: "c.json", Provider: "claude", Disabled: true, Status: coreauth.StatusDisabled, Quota: coreauth.QuotaState{BackoffLevel: 1}},
+	} {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	h := &Handler{authManager: manager}
+	router := gin.New()
+	router.GET("/accounts", h.GetAccountsMonitor)
+	get := func(query string) (int, string) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/accounts"+query, nil))
+		var resp AccountsMonitorResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		ids := make([]string, 0, len(resp.Accounts))
+		for _, account := range resp.Accounts {
+			ids = append(ids, account.ID)
+		}
+		return rec.Code, strings.Join(ids, ",")
+	}
+
+	for query, want := range map[string]string{
+		"":                                      "b.json,a.json,c.json",
+		"?sort=-index":                          "c.json,a.json,b.json",
+		"?sort=status":                          "a.json,c.json,b.json",
+		"?sort=provider":                        "c.json,a.json,b.json",
+		"?sort=-backoff_level":                  "a.json,c.json,b.json",
+		"?sort=-backoff_level&limit=1&offset=1": "c.json",
+	} {
+		if code, got := get(query); code != http.StatusOK || got != want {
+			t.Fatalf("%q: status %d, accounts %s, want %s", query, code, got, want)
+		}
+	}
+	if code, _ := get("?sort=email"); code != http.StatusBadRequest {
+		t.Fatalf("unknown sort key: status %d", code)
+	}
+}
@@ -0,0 +1,40 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type accountPriorityRequest struct {
+	Priority *int `json:"priority"`
+}
+
+// SetAccountPriority replaces the selection priority of a single account. Higher priorities absorb
+// traffic first; lower ones only serve while every higher one is unavailable.
+func (h *Handler) SetAccountPriority(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	var body accountPriorityRequest
+	if err := c.ShouldBindJSON(&body); err != nil || body.Priority == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "priority is required"})
+		return
+	}
+	auth, ok := h.authManager.GetByID(strings.TrimSpace(c.Param("id")))
+	if !ok || auth == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+	auth.SetPriority(*body.Priority)
+	auth.UpdatedAt = time.Now()
+	updated, err := h.authManager.Update(c.Request.Context(), auth)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, h.accountStatus(updated))
+}
@@ -0,0 +1,109 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+// accountProbeTimeout bounds a single account probe.
+const accountProbeTimeout = time.Minute
+
+// AccountProbeResult reports a probe request sent through one account.
+type AccountProbeResult struct {
+	ID         string    `json:"id"`
+	Provider   string    `json:"provider"`
+	Model      string    `json:"model"`
+	Success    bool      `json:"success"`
+	LatencyMs  int64     `json:"latency_ms"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// probePayload builds the smallest useful chat completion: one user word and a single output token.
+func probePayload(model string) ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"model":      model,
+		"messages":   []map[string]string{{"role": "user", "content": "ping"}},
+		"max_tokens": 1,
+		"stream":     false,
+	})
+}
+
+// TestAccount sends a minimal completion through the account to verify its credentials end to end.
+// Cooldowns are bypassed and the outcome is not recorded against the account. The optional JSON body
+// {"model": "..."} picks the model; by default the first model the account serves is used.
+func (h *Handler) TestAccount(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	var body struct {
+		Model string `json:"model"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	auth, ok := h.authManager.GetByID(id)
+	if !ok || auth == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+	model := strings.TrimSpace(body.Model)
+	if model == "" {
+		if models := registry.GetGlobalRegistry().GetClientModels(id); len(models) > 0 {
+			model = models[0]
+		}
+	}
+	if model == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "account serves no models; specify one"})
+		return
+	}
+	payload, err := probePayload(model)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), accountProbeTimeout)
+	defer cancel()
+	req := cliproxyexecutor.Request{Model: model, Payload: payload}
+	opts := cliproxyexecutor.Options{OriginalRequest: payload, SourceFormat: sdktranslator.FormatOpenAI}
+	started := time.Now()
+	_, errProbe := h.authManager.ProbeAccount(ctx, id, req, opts)
+	result := AccountProbeResult{
+		ID:        id,
+		Provider:  auth.Provider,
+		Model:     model,
+		Success:   errProbe == nil,
+		LatencyMs: time.Since(started).Milliseconds(),
+		CheckedAt: time.Now(),
+	}
+	if errProbe != nil {
+		result.Error = errProbe.Error()
+		var se cliproxyexecutor.StatusError
+		if errors.As(errProbe, &se) && se != nil {
+			result.StatusCode = se.StatusCode()
+		}
+		var ae *coreauth.Error
+		if errors.As(errProbe, &ae) && ae.Code == "auth_not_found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
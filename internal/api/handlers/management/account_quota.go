@@ -0,0 +1,23 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResetAccountQuota clears a stale quota cooldown and backoff so the account is selected again right
+// away. Accounts that are not cooling down are returned unchanged.
+func (h *Handler) ResetAccountQuota(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	auth, ok, _ := h.authManager.ResetQuota(c.Request.Context(), strings.TrimSpace(c.Param("id")))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+	c.JSON(http.StatusOK, h.accountStatus(auth))
+}
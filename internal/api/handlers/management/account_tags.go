@@ -0,0 +1,102 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// accountFilter selects accounts by provider, monitor state and ID prefix. Empty fields match everything.
+type accountFilter struct {
+	Provider string `json:"provider"`
+	Status   string `json:"status"`
+	IDPrefix string `json:"id_prefix"`
+}
+
+func (f accountFilter) normalize() accountFilter {
+	return accountFilter{
+		Provider: strings.ToLower(strings.TrimSpace(f.Provider)),
+		Status:   strings.ToLower(strings.TrimSpace(f.Status)),
+		IDPrefix: strings.TrimSpace(f.IDPrefix),
+	}
+}
+
+func (f accountFilter) matches(auth *coreauth.Auth, now time.Time) bool {
+	if auth == nil {
+		return false
+	}
+	if f.Provider != "" && !strings.EqualFold(auth.Provider, f.Provider) {
+		return false
+	}
+	if f.IDPrefix != "" && !strings.HasPrefix(auth.ID, f.IDPrefix) {
+		return false
+	}
+	if f.Status != "" && accountMonitorState(auth, now) != f.Status {
+		return false
+	}
+	return true
+}
+
+type accountTagRequest struct {
+	Filter accountFilter `json:"filter"`
+	Tags   []string      `json:"tags"`
+}
+
+// TagAccounts adds tags to every account matching the filter.
+func (h *Handler) TagAccounts(c *gin.Context) {
+	h.applyAccountTags(c, func(auth *coreauth.Auth, tags []string) bool {
+		return auth.AddTags(tags...)
+	})
+}
+
+// UntagAccounts removes tags from every account matching the filter.
+func (h *Handler) UntagAccounts(c *gin.Context) {
+	h.applyAccountTags(c, func(auth *coreauth.Auth, tags []string) bool {
+		return auth.RemoveTags(tags...)
+	})
+}
+
+func (h *Handler) applyAccountTags(c *gin.Context, apply func(*coreauth.Auth, []string) bool) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	var body accountTagRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	tags := coreauth.NormalizeTags(body.Tags)
+	if len(tags) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tags are required"})
+		return
+	}
+	filter := body.Filter.normalize()
+	switch filter.Status {
+	case "", monitorStateActive, monitorStateCooldown, monitorStateError, monitorStateDisabled, monitorStateBilling, monitorStateEgress:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status filter"})
+		return
+	}
+
+	now := time.Now()
+	matched := 0
+	changed := h.authManager.UpdateMatching(c.Request.Context(), func(auth *coreauth.Auth) bool {
+		if !filter.matches(auth, now) {
+			return false
+		}
+		matched++
+		return true
+	}, func(auth *coreauth.Auth) bool {
+		return apply(auth, tags)
+	})
+
+	ids := make([]string, 0, len(changed))
+	for _, auth := range changed {
+		ids = append(ids, auth.ID)
+	}
+	c.JSON(http.StatusOK, gin.H{"matched": matched, "updated": len(changed), "ids": ids, "tags": tags})
+}
@@ -0,0 +1,60 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// DisableAccount takes a single account out of rotation until it is enabled again.
+func (h *Handler) DisableAccount(c *gin.Context) {
+	h.setAccountDisabled(c, true)
+}
+
+// EnableAccount returns a manually disabled account to rotation.
+func (h *Handler) EnableAccount(c *gin.Context) {
+	h.setAccountDisabled(c, false)
+}
+
+func (h *Handler) setAccountDisabled(c *gin.Context, disabled bool) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	auth, ok := h.authManager.GetByID(strings.TrimSpace(c.Param("id")))
+	if !ok || auth == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+	// Disabling or enabling takes over from a running drain.
+	if h.authManager.CancelDrain(auth.ID) && !disabled && !auth.Disabled {
+		c.JSON(http.StatusOK, h.accountStatus(auth))
+		return
+	}
+	if auth.Disabled == disabled {
+		state := "enabled"
+		if disabled {
+			state = "disabled"
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": "account already " + state})
+		return
+	}
+	auth.Disabled = disabled
+	if disabled {
+		auth.Status = coreauth.StatusDisabled
+		auth.StatusMessage = "disabled via management API"
+	} else if auth.Status == coreauth.StatusDisabled {
+		auth.Status = coreauth.StatusActive
+		auth.StatusMessage = ""
+	}
+	auth.UpdatedAt = time.Now()
+	updated, err := h.authManager.Update(c.Request.Context(), auth)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, h.accountStatus(updated))
+}
@@ -0,0 +1,53 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestDisableAndEnableAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := coreauth.NewManager(nil, nil, nil)
+	if _, err := manager.Register(context.Background(), &coreauth.Auth{ID: "a.json", Provider: "gemini", Status: coreauth.StatusActive}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	h := &Handler{authManager: manager}
+	router := gin.New()
+	router.POST("/accounts/:id/disable", h.DisableAccount)
+	router.POST("/accounts/:id/enable", h.EnableAccount)
+	call := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+		return rec
+	}
+
+	if rec := call("/accounts/a.json/enable"); rec.Code != http.StatusConflict {
+		t.Fatalf("enabling an active account: status %d", rec.Code)
+	}
+	rec := call("/accounts/a.json/disable")
+	var status AccountStatus
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &status) != nil || !status.Disabled {
+		t.Fatalf("disable: status %d body %s", rec.Code, rec.Body.String())
+	}
+	if auth, _ := manager.GetByID("a.json"); !auth.Disabled || auth.Status != coreauth.StatusDisabled {
+		t.Fatalf("disable not stored: %+v", auth)
+	}
+	if rec = call("/accounts/a.json/disable"); rec.Code != http.StatusConflict {
+		t.Fatalf("disabling twice: status %d", rec.Code)
+	}
+	if rec = call("/accounts/a.json/enable"); rec.Code != http.StatusOK {
+		t.Fatalf("enable: status %d", rec.Code)
+	}
+	if auth, _ := manager.GetByID("a.json"); auth.Disabled || auth.Status != coreauth.StatusActive {
+		t.Fatalf("enable not stored: %+v", auth)
+	}
+	if rec = call("/accounts/missing.json/disable"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown account: status %d", rec.Code)
+	}
+}
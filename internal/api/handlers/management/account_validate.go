@@ -0,0 +1,94 @@
+package management
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// ValidateAccount is a pre-flight for auth file uploads: it parses a credential (multipart "file"
+// or raw JSON body), checks it against the provider and reports the outcome without writing the
+// file or registering the account. When the check had to exchange the refresh token, the response
+// carries the refreshed credential under "credential": providers that rotate refresh tokens have
+// spent the uploaded one, so that is the copy to import.
+func (h *Handler) ValidateAccount(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	var data []byte
+	if file, err := c.FormFile("file"); err == nil && file != nil {
+		f, errOpen := file.Open()
+		if errOpen != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+			return
+		}
+		data, err = io.ReadAll(f)
+		_ = f.Close()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+			return
+		}
+	} else {
+		data, err = io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read body"})
+			return
+		}
+	}
+
+	metadata := make(map[string]any)
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid credential json", "message": err.Error()})
+		return
+	}
+	provider := strings.ToLower(strings.TrimSpace(valueAsString(metadata["type"])))
+	if provider == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot determine provider: credential has no type"})
+		return
+	}
+
+	now := time.Now()
+	candidate := &coreauth.Auth{
+		ID:         "validate:" + provider,
+		Provider:   provider,
+		Label:      provider,
+		Status:     coreauth.StatusActive,
+		Attributes: map[string]string{"runtime_only": "true"},
+		Metadata:   metadata,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	validated, err := h.authManager.ValidateAuth(c.Request.Context(), candidate)
+	if err != nil {
+		var authErr *coreauth.Error
+		if errors.As(err, &authErr) && authErr.Code == "validation_unsupported" {
+			// Nothing was checked, so the credential is neither known good nor bad.
+			c.JSON(http.StatusOK, gin.H{"valid": nil, "provider": provider, "error": authErr.Message})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"valid": false, "provider": provider, "error": err.Error()})
+		return
+	}
+	resp := gin.H{"valid": true, "provider": provider}
+	// Decoded afresh: the refresh may have written through nested objects shared with metadata.
+	var uploaded map[string]any
+	_ = json.Unmarshal(data, &uploaded)
+	if !reflect.DeepEqual(validated.Metadata, uploaded) {
+		resp["credential"] = validated.Metadata
+	}
+	if email := strings.TrimSpace(valueAsString(validated.Metadata["email"])); email != "" {
+		resp["email"] = email
+	}
+	if ts, ok := validated.ExpirationTime(); ok {
+		resp["expires_at"] = ts
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,322 @@
+package management
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	accountWebhookCheckInterval   = time.Second
+	defaultAccountWebhookDebounce = 30 * time.Second
+	defaultAccountWebhookRetries  = 3
+	accountWebhookTimeout         = 10 * time.Second
+	// accountWebhookMaxDeliveries bounds the delivery log reported by the management endpoint.
+	accountWebhookMaxDeliveries = 50
+)
+
+// accountWebhookBaseBackoff is the delay before the first redelivery; it doubles per attempt.
+var accountWebhookBaseBackoff = 2 * time.Second
+
+// AccountWebhookEvent is the JSON body posted for one account state transition.
+type AccountWebhookEvent struct {
+	Event         string        `json:"event"`
+	Timestamp     time.Time     `json:"timestamp"`
+	AccountID     string        `json:"account_id"`
+	Provider      string        `json:"provider"`
+	PreviousState string        `json:"previous_state"`
+	State         string        `json:"state"`
+	Reason        string        `json:"reason,omitempty"`
+	Before        AccountStatus `json:"before"`
+	After         AccountStatus `json:"after"`
+}
+
+// AccountWebhookDelivery records the outcome of posting one event.
+type AccountWebhookDelivery struct {
+	AccountID string    `json:"account_id"`
+	State     string    `json:"state"`
+	At        time.Time `json:"at"`
+	Attempts  int       `json:"attempts"`
+	Delivered bool      `json:"delivered"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// webhookAccountState is the last reported state of an account and the state it is moving to.
+type webhookAccountState struct {
+	state        string
+	status       AccountStatus
+	pending      string
+	pendingSince time.Time
+}
+
+// accountWebhook watches account states and posts debounced transitions to the configured URL.
+type accountWebhook struct {
+	mu         sync.Mutex
+	cfg        config.AccountWebhook
+	cancel     context.CancelFunc
+	accounts   map[string]*webhookAccountState
+	deliveries []AccountWebhookDelivery
+	client     *http.Client
+}
+
+func newAccountWebhook() *accountWebhook {
+	return &accountWebhook{client: &http.Client{Timeout: accountWebhookTimeout}}
+}
+
+// accountWebhookAlerting lists the states that trigger a notification.
+var accountWebhookAlerting = map[string]bool{
+	monitorStateCooldown: true,
+	monitorStateError:    true,
+	monitorStateDisabled: true,
+}
+
+// validateAccountWebhook checks that an enabled webhook has a usable URL.
+func validateAccountWebhook(cfg config.AccountWebhook) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	u, err := url.Parse(strings.TrimSpace(cfg.URL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http(s) URL")
+	}
+	return nil
+}
+
+// SetAccountWebhook applies the webhook config, (re)starting or stopping the watcher.
+func (h *Handler) SetAccountWebhook(cfg config.AccountWebhook) {
+	if h == nil || h.webhook == nil {
+		return
+	}
+	cfg.URL = strings.TrimSpace(cfg.URL)
+	if err := validateAccountWebhook(cfg); err != nil {
+		log.Warnf("account webhook disabled: %v", err)
+		cfg.Enabled = false
+	}
+	wh := h.webhook
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+	if wh.cancel != nil && reflect.DeepEqual(wh.cfg, cfg) {
+		return
+	}
+	if wh.cancel != nil {
+		wh.cancel()
+		wh.cancel = nil
+	}
+	wh.cfg = cfg
+	wh.accounts = nil
+	if !cfg.Enabled {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	wh.cancel = cancel
+	go h.runAccountWebhook(ctx)
+}
+
+// StopAccountWebhook stops the watcher; deliveries in flight are abandoned.
+func (h *Handler) StopAccountWebhook() {
+	if h == nil || h.webhook == nil {
+		return
+	}
+	h.webhook.mu.Lock()
+	if h.webhook.cancel != nil {
+		h.webhook.cancel()
+		h.webhook.cancel = nil
+	}
+	h.webhook.mu.Unlock()
+}
+
+func (h *Handler) runAccountWebhook(ctx context.Context) {
+	ticker := time.NewTicker(accountWebhookCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, event := range h.observeAccountStates(now) {
+				go h.webhook.deliver(ctx, event)
+			}
+		}
+	}
+}
+
+// observeAccountStates compares every account with its last reported state and returns the
+// transitions that held for the debounce period. The first observation only records a baseline.
+func (h *Handler) observeAccountStates(now time.Time) []AccountWebhookEvent {
+	if h.authManager == nil {
+		return nil
+	}
+	auths := h.authManager.List()
+	wh := h.webhook
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+	debounce := time.Duration(wh.cfg.DebounceSeconds) * time.Second
+	if debounce <= 0 {
+		debounce = defaultAccountWebhookDebounce
+	}
+	if wh.accounts == nil {
+		wh.accounts = make(map[string]*webhookAccountState, len(auths))
+	}
+	seen := make(map[string]struct{}, len(auths))
+	var events []AccountWebhookEvent
+	for _, auth := range auths {
+		if auth == nil {
+			continue
+		}
+		seen[auth.ID] = struct{}{}
+		state := accountMonitorState(auth, now)
+		tracked := wh.accounts[auth.ID]
+		if tracked == nil {
+			wh.accounts[auth.ID] = &webhookAccountState{state: state, status: h.accountStatus(auth)}
+			continue
+		}
+		if state == tracked.state {
+			tracked.pending = ""
+			tracked.status = h.accountStatus(auth)
+			continue
+		}
+		if tracked.pending != state {
+			tracked.pending, tracked.pendingSince = state, now
+			continue
+		}
+		if now.Sub(tracked.pendingSince) < debounce {
+			continue
+		}
+		after := h.accountStatus(auth)
+		if accountWebhookAlerting[state] || (wh.cfg.OnRecovery && state == monitorStateActive && accountWebhookAlerting[tracked.state]) {
+			events = append(events, AccountWebhookEvent{
+				Event:         "account.state_changed",
+				Timestamp:     now,
+				AccountID:     auth.ID,
+				Provider:      auth.Provider,
+				PreviousState: tracked.state,
+				State:         state,
+				Reason:        accountTransitionReason(after),
+				Before:        tracked.status,
+				After:         after,
+			})
+		}
+		tracked.state, tracked.status, tracked.pending = state, after, ""
+	}
+	for id := range wh.accounts {
+		if _, ok := seen[id]; !ok {
+			delete(wh.accounts, id)
+		}
+	}
+	return events
+}
+
+// accountTransitionReason picks the most specific explanation the account status carries.
+func accountTransitionReason(status AccountStatus) string {
+	for _, reason := range []string{status.LastUnavailableReason, status.QuotaReason, status.StatusMessage} {
+		if reason != "" {
+			return reason
+		}
+	}
+	if msg, ok := status.LastError["message"].(string); ok {
+		return msg
+	}
+	return ""
+}
+
+// deliver posts event, retrying with exponential backoff until it succeeds, the retries are used
+// up or ctx ends.
+func (wh *accountWebhook) deliver(ctx context.Context, event AccountWebhookEvent) {
+	wh.mu.Lock()
+	cfg := wh.cfg
+	wh.mu.Unlock()
+	retries := cfg.MaxRetries
+	if retries <= 0 {
+		retries = defaultAccountWebhookRetries
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	record := AccountWebhookDelivery{AccountID: event.AccountID, State: event.State}
+	backoff := accountWebhookBaseBackoff
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		record.Attempts = attempt + 1
+		if err = wh.post(ctx, cfg, body); err == nil {
+			record.Delivered = true
+			break
+		}
+		log.Warnf("account webhook for %s (attempt %d): %v", event.AccountID, record.Attempts, err)
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	record.At = time.Now()
+	wh.mu.Lock()
+	wh.deliveries = append(wh.deliveries, record)
+	if len(wh.deliveries) > accountWebhookMaxDeliveries {
+		wh.deliveries = wh.deliveries[len(wh.deliveries)-accountWebhookMaxDeliveries:]
+	}
+	wh.mu.Unlock()
+}
+
+func (wh *accountWebhook) post(ctx context.Context, cfg config.AccountWebhook, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range cfg.Headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := wh.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("endpoint answered %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// GetAccountWebhook reports the webhook config and the most recent deliveries.
+func (h *Handler) GetAccountWebhook(c *gin.Context) {
+	wh := h.webhook
+	wh.mu.Lock()
+	deliveries := append([]AccountWebhookDelivery(nil), wh.deliveries...)
+	running := wh.cancel != nil
+	wh.mu.Unlock()
+	c.JSON(http.StatusOK, gin.H{"account-webhook": h.cfg.AccountWebhook, "running": running, "deliveries": deliveries})
+}
+
+// PutAccountWebhook replaces the webhook config, applies it immediately and persists it.
+func (h *Handler) PutAccountWebhook(c *gin.Context) {
+	var body config.AccountWebhook
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	body.URL = strings.TrimSpace(body.URL)
+	if err := validateAccountWebhook(body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.cfg.AccountWebhook = body
+	h.SetAccountWebhook(body)
+	h.persist(c)
+}
@@ -0,0 +1,86 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestObserveAccountStatesDebouncesTransitions(t *testing.T) {
+	manager := coreauth.NewManager(nil, nil, nil)
+	auth := &coreauth.Auth{ID: "a.json", Provider: "codex", Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	h := &Handler{authManager: manager, webhook: newAccountWebhook()}
+	h.webhook.cfg = config.AccountWebhook{DebounceSeconds: 10, OnRecovery: true}
+	now := time.Now()
+
+	if events := h.observeAccountStates(now); len(events) != 0 {
+		t.Fatalf("baseline produced events: %+v", events)
+	}
+	errored := auth.Clone()
+	errored.Status, errored.StatusMessage, errored.Unavailable = coreauth.StatusError, "token revoked", true
+	if _, err := manager.Update(context.Background(), errored); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if events := h.observeAccountStates(now.Add(time.Second)); len(events) != 0 {
+		t.Fatalf("transition reported before the debounce elapsed: %+v", events)
+	}
+	events := h.observeAccountStates(now.Add(12 * time.Second))
+	if len(events) != 1 {
+		t.Fatalf("expected one event after the debounce, got %+v", events)
+	}
+	if e := events[0]; e.PreviousState != monitorStateActive || e.State != monitorStateError || e.Reason != "token revoked" || e.Before.Status != string(coreauth.StatusActive) {
+		t.Fatalf("unexpected event: %+v", e)
+	}
+
+	// A flap back and forth inside the debounce window stays quiet.
+	if _, err := manager.Update(context.Background(), auth.Clone()); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	h.observeAccountStates(now.Add(13 * time.Second))
+	if _, err := manager.Update(context.Background(), errored.Clone()); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if events := h.observeAccountStates(now.Add(30 * time.Second)); len(events) != 0 {
+		t.Fatalf("flapping account produced events: %+v", events)
+	}
+}
+
+func TestAccountWebhookDeliverRetries(t *testing.T) {
+	defer func(old time.Duration) { accountWebhookBaseBackoff = old }(accountWebhookBaseBackoff)
+	accountWebhookBaseBackoff = time.Millisecond
+	var calls atomic.Int32
+	var received AccountWebhookEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	wh := newAccountWebhook()
+	wh.cfg = config.AccountWebhook{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer secret"}, MaxRetries: 2}
+	wh.deliver(context.Background(), AccountWebhookEvent{AccountID: "a.json", State: monitorStateCooldown})
+
+	if len(wh.deliveries) != 1 || !wh.deliveries[0].Delivered || wh.deliveries[0].Attempts != 2 {
+		t.Fatalf("unexpected deliveries: %+v", wh.deliveries)
+	}
+	if received.AccountID != "a.json" || received.State != monitorStateCooldown {
+		t.Fatalf("unexpected payload: %+v", received)
+	}
+}
@@ -0,0 +1,91 @@
+package management
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// RecoveringAccount is a cooling-down account and how long it has left.
+type RecoveringAccount struct {
+	ID               string     `json:"id"`
+	Provider         string     `json:"provider"`
+	Label            string     `json:"label,omitempty"`
+	QuotaReason      string     `json:"quota_reason,omitempty"`
+	BackoffLevel     int        `json:"backoff_level"`
+	NextRecoverAt    *time.Time `json:"next_recover_at,omitempty"`
+	RemainingSeconds *int64     `json:"remaining_seconds,omitempty"`
+	Remaining        string     `json:"remaining,omitempty"`
+}
+
+// RecoveringAccountsResponse lists cooling-down accounts, soonest recovery first.
+type RecoveringAccountsResponse struct {
+	Timestamp time.Time           `json:"timestamp"`
+	Count     int                 `json:"count"`
+	Summary   string              `json:"summary"`
+	NextAt    *time.Time          `json:"next_available_at,omitempty"`
+	NextIn    *int64              `json:"next_available_in_seconds,omitempty"`
+	Accounts  []RecoveringAccount `json:"accounts"`
+}
+
+// GetRecoveringAccounts lists the accounts in cooldown sorted by recovery time, ascending, with the
+// remaining time computed server-side. Accounts without a known recovery time come last.
+func (h *Handler) GetRecoveringAccounts(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	c.JSON(http.StatusOK, recoveringAccounts(h.authManager.List(), time.Now()))
+}
+
+func recoveringAccounts(auths []*coreauth.Auth, now time.Time) RecoveringAccountsResponse {
+	resp := RecoveringAccountsResponse{Timestamp: now, Accounts: []RecoveringAccount{}}
+	for _, auth := range auths {
+		if auth == nil || accountMonitorState(auth, now) != monitorStateCooldown {
+			continue
+		}
+		account := RecoveringAccount{
+			ID:           auth.ID,
+			Provider:     auth.Provider,
+			Label:        auth.Label,
+			QuotaReason:  auth.Quota.Reason,
+			BackoffLevel: auth.Quota.BackoffLevel,
+		}
+		recoverAt := auth.Quota.NextRecoverAt
+		if recoverAt.IsZero() {
+			recoverAt = auth.NextRetryAfter
+		}
+		if !recoverAt.IsZero() {
+			remaining := max(recoverAt.Sub(now), 0).Truncate(time.Second)
+			seconds := int64(remaining / time.Second)
+			account.NextRecoverAt, account.RemainingSeconds, account.Remaining = &recoverAt, &seconds, remaining.String()
+		}
+		resp.Accounts = append(resp.Accounts, account)
+	}
+	sort.SliceStable(resp.Accounts, func(i, j int) bool {
+		a, b := resp.Accounts[i].NextRecoverAt, resp.Accounts[j].NextRecoverAt
+		switch {
+		case a == nil || b == nil:
+			return a != nil && b == nil
+		case !a.Equal(*b):
+			return a.Before(*b)
+		default:
+			return resp.Accounts[i].ID < resp.Accounts[j].ID
+		}
+	})
+	resp.Count = len(resp.Accounts)
+	switch {
+	case resp.Count == 0:
+		resp.Summary = "no accounts in cooldown"
+	case resp.Accounts[0].NextRecoverAt == nil:
+		resp.Summary = "no recovery time known"
+	default:
+		first := resp.Accounts[0]
+		resp.NextAt, resp.NextIn = first.NextRecoverAt, first.RemainingSeconds
+		resp.Summary = "next account available in " + first.Remaining
+	}
+	return resp
+}
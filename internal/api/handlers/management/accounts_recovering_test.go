@@ -0,0 +1,44 @@
+package management
+
+import (
+	"testing"
+	"time"
+
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestRecoveringAccountsSortedBySoonestRecovery(t *testing.T) {
+	now := time.Now()
+	cooling := func(id string, in time.Duration) *coreauth.Auth {
+		return &coreauth.Auth{ID: id, Provider: "gemini", Unavailable: true, Quota: coreauth.QuotaState{Exceeded: true, NextRecoverAt: now.Add(in)}}
+	}
+	auths := []*coreauth.Auth{
+		cooling("late.json", 10*time.Minute),
+		{ID: "active.json", Provider: "gemini", Status: coreauth.StatusActive},
+		{ID: "unknown.json", Provider: "claude", Quota: coreauth.QuotaState{Exceeded: true}},
+		cooling("soon.json", 90*time.Second),
+		{ID: "disabled.json", Provider: "gemini", Disabled: true, Quota: coreauth.QuotaState{Exceeded: true, NextRecoverAt: now.Add(time.Second)}},
+	}
+
+	resp := recoveringAccounts(auths, now)
+	var ids []string
+	for _, account := range resp.Accounts {
+		ids = append(ids, account.ID)
+	}
+	if resp.Count != 3 || len(ids) != 3 || ids[0] != "soon.json" || ids[1] != "late.json" || ids[2] != "unknown.json" {
+		t.Fatalf("accounts = %v", ids)
+	}
+	if first := resp.Accounts[0]; first.RemainingSeconds == nil || *first.RemainingSeconds != 90 || first.Remaining != "1m30s" {
+		t.Fatalf("soonest account = %+v", first)
+	}
+	if resp.Accounts[2].RemainingSeconds != nil {
+		t.Fatalf("unknown recovery reported a duration: %+v", resp.Accounts[2])
+	}
+	if resp.Summary != "next account available in 1m30s" || resp.NextIn == nil || *resp.NextIn != 90 {
+		t.Fatalf("summary = %q, next in %v", resp.Summary, resp.NextIn)
+	}
+
+	if empty := recoveringAccounts(auths[1:2], now); empty.Count != 0 || empty.Accounts == nil || empty.NextAt != nil {
+		t.Fatalf("no cooldown: %+v", empty)
+	}
+}
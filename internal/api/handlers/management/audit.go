@@ -0,0 +1,384 @@
+package management
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+const (
+	// auditLogMaxEvents bounds the events kept in memory for the audit endpoints.
+	auditLogMaxEvents    = 1000
+	auditStreamBuffer    = 64
+	auditStreamHeartbeat = 30 * time.Second
+
+	// auditActorContextKey is the gin context key under which the management middleware records
+	// which management key authenticated the request.
+	auditActorContextKey = "management.audit.actor"
+
+	auditKeyLocalPassword = "local-password"
+	auditKeyEnvSecret     = "env-secret"
+	auditKeySecretKey     = "secret-key"
+
+	// AuditCategoryPool groups events that change which accounts are in the pool or how they are
+	// organised.
+	AuditCategoryPool = "pool"
+)
+
+// Pool composition audit actions.
+const (
+	AuditAccountAdded       = "account_added"
+	AuditAccountImported    = "account_imported"
+	AuditAccountRemoved     = "account_removed"
+	AuditCredentialsRotated = "credentials_rotated"
+	AuditTagsChanged        = "tags_changed"
+	AuditPriorityChanged    = "priority_changed"
+	AuditWeightChanged      = "weight_changed"
+	AuditAccountDisabled    = "account_disabled"
+	AuditAccountEnabled     = "account_enabled"
+)
+
+// auditImportRoutes lists the management routes whose new accounts are reported as imported rather
+// than added.
+var auditImportRoutes = map[string]bool{
+	"/v0/management/auth-files":    true,
+	"/v0/management/vertex/import": true,
+}
+
+// auditCredentialKeys are the metadata and attribute keys whose values make up an account's
+// credentials; a change to any of them is reported as a rotation.
+var auditCredentialKeys = []string{"access_token", "refresh_token", "id_token", "token", "api_key", "cookie", "service_account"}
+
+// AuditActor identifies who made a management change.
+type AuditActor struct {
+	// Key names the management key used: local-password, env-secret or secret-key.
+	Key string `json:"key,omitempty"`
+	IP  string `json:"ip,omitempty"`
+}
+
+// AuditEvent records one change made through the management API.
+type AuditEvent struct {
+	ID        int64      `json:"id"`
+	Timestamp time.Time  `json:"timestamp"`
+	Category  string     `json:"category"`
+	Action    string     `json:"action"`
+	AccountID string     `json:"account_id"`
+	Provider  string     `json:"provider,omitempty"`
+	Actor     AuditActor `json:"actor"`
+	Route     string     `json:"route"`
+	Before    any        `json:"before,omitempty"`
+	After     any        `json:"after,omitempty"`
+}
+
+// auditLog keeps the most recent audit events and fans new ones out to stream subscribers.
+type auditLog struct {
+	mu          sync.Mutex
+	events      []AuditEvent
+	nextID      int64
+	subscribers map[chan AuditEvent]struct{}
+}
+
+func newAuditLog() *auditLog {
+	return &auditLog{subscribers: make(map[chan AuditEvent]struct{})}
+}
+
+func (l *auditLog) record(events ...AuditEvent) {
+	if l == nil || len(events) == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, event := range events {
+		l.nextID++
+		event.ID = l.nextID
+		l.events = append(l.events, event)
+		for ch := range l.subscribers {
+			// A subscriber that cannot keep up misses events rather than stalling management calls.
+			select {
+			case ch <- event:
+			default:
+			}
+		}
+	}
+	if excess := len(l.events) - auditLogMaxEvents; excess > 0 {
+		l.events = append([]AuditEvent(nil), l.events[excess:]...)
+	}
+}
+
+// since returns the kept events with an id above after, optionally restricted to one category.
+func (l *auditLog) since(after int64, category string) []AuditEvent {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]AuditEvent, 0)
+	for _, event := range l.events {
+		if event.ID > after && (category == "" || event.Category == category) {
+			out = append(out, event)
+		}
+	}
+	return out
+}
+
+// subscribe registers a channel for new events and returns the events already kept after the given
+// id, so a stream can replay them without gaps.
+func (l *auditLog) subscribe(after int64, category string) (chan AuditEvent, []AuditEvent) {
+	ch := make(chan AuditEvent, auditStreamBuffer)
+	backlog := l.since(after, category)
+	l.mu.Lock()
+	l.subscribers[ch] = struct{}{}
+	l.mu.Unlock()
+	return ch, backlog
+}
+
+func (l *auditLog) unsubscribe(ch chan AuditEvent) {
+	l.mu.Lock()
+	delete(l.subscribers, ch)
+	l.mu.Unlock()
+}
+
+func setAuditActor(c *gin.Context, key string) {
+	c.Set(auditActorContextKey, AuditActor{Key: key, IP: c.ClientIP()})
+}
+
+func auditActorFromContext(c *gin.Context) AuditActor {
+	if actor, ok := c.Get(auditActorContextKey); ok {
+		if value, okActor := actor.(AuditActor); okActor {
+			return value
+		}
+	}
+	return AuditActor{IP: c.ClientIP()}
+}
+
+// poolAccount is the part of an account that pool composition events track.
+type poolAccount struct {
+	Provider    string   `json:"provider"`
+	Label       string   `json:"label,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Priority    int      `json:"priority"`
+	Weight      int      `json:"weight"`
+	Disabled    bool     `json:"disabled"`
+	credentials string
+}
+
+// snapshotPool captures the accounts in the pool; accounts whose auth file was deleted are left out.
+func snapshotPool(manager *coreauth.Manager) map[string]poolAccount {
+	auths := manager.List()
+	pool := make(map[string]poolAccount, len(auths))
+	for _, auth := range auths {
+		if auth == nil || (auth.Disabled && auth.StatusMessage == authRemovedStatusMessage) {
+			continue
+		}
+		pool[auth.ID] = poolAccount{
+			Provider:    auth.Provider,
+			Label:       auth.Label,
+			Tags:        slices.Clone(auth.Tags),
+			Priority:    auth.Priority,
+			Weight:      auth.Weight(),
+			Disabled:    auth.Disabled,
+			credentials: credentialFingerprint(auth),
+		}
+	}
+	return pool
+}
+
+// credentialFingerprint hashes the credential fields of an account so rotations can be detected
+// without keeping the secrets themselves.
+func credentialFingerprint(auth *coreauth.Auth) string {
+	hash := sha256.New()
+	for _, key := range auditCredentialKeys {
+		if value, ok := auth.Metadata[key]; ok && value != nil {
+			encoded, _ := json.Marshal(value)
+			_, _ = io.WriteString(hash, key+"="+string(encoded)+"\n")
+		}
+		if value := auth.Attributes[key]; value != "" {
+			_, _ = io.WriteString(hash, "attr:"+key+"="+value+"\n")
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// diffPool turns the difference between two pool snapshots into audit events.
+func diffPool(before, after map[string]poolAccount, route string) []AuditEvent {
+	var events []AuditEvent
+	for _, id := range sortedKeys(after) {
+		next := after[id]
+		prev, existed := before[id]
+		if !existed {
+			action := AuditAccountAdded
+			if auditImportRoutes[route] {
+				action = AuditAccountImported
+			}
+			events = append(events, AuditEvent{Action: action, AccountID: id, Provider: next.Provider, After: next})
+			continue
+		}
+		event := func(action string, from, to any) {
+			events = append(events, AuditEvent{Action: action, AccountID: id, Provider: next.Provider, Before: from, After: to})
+		}
+		if prev.credentials != next.credentials {
+			event(AuditCredentialsRotated, nil, nil)
+		}
+		if !slices.Equal(prev.Tags, next.Tags) {
+			event(AuditTagsChanged, gin.H{"tags": prev.Tags}, gin.H{"tags": next.Tags})
+		}
+		if prev.Priority != next.Priority {
+			event(AuditPriorityChanged, gin.H{"priority": prev.Priority}, gin.H{"priority": next.Priority})
+		}
+		if prev.Weight != next.Weight {
+			event(AuditWeightChanged, gin.H{"weight": prev.Weight}, gin.H{"weight": next.Weight})
+		}
+		if prev.Disabled != next.Disabled {
+			action := AuditAccountEnabled
+			if next.Disabled {
+				action = AuditAccountDisabled
+			}
+			event(action, nil, nil)
+		}
+	}
+	for _, id := range sortedKeys(before) {
+		if _, ok := after[id]; !ok {
+			events = append(events, AuditEvent{Action: AuditAccountRemoved, AccountID: id, Provider: before[id].Provider, Before: before[id]})
+		}
+	}
+	return events
+}
+
+func sortedKeys(pool map[string]poolAccount) []string {
+	keys := make([]string, 0, len(pool))
+	for id := range pool {
+		keys = append(keys, id)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// AuditMiddleware records pool composition events for every mutating management request by
+// comparing the account pool before and after the handler runs, so each endpoint reports its
+// changes the same way without emitting events itself. It must run after Middleware, which
+// identifies the actor.
+func (h *Handler) AuditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := h.authManager
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if manager == nil || h.audit == nil {
+			c.Next()
+			return
+		}
+		before := snapshotPool(manager)
+		c.Next()
+		route := c.FullPath()
+		events := diffPool(before, snapshotPool(manager), route)
+		if len(events) == 0 {
+			return
+		}
+		now := time.Now().UTC()
+		actor := auditActorFromContext(c)
+		for i := range events {
+			events[i].Timestamp = now
+			events[i].Category = AuditCategoryPool
+			events[i].Actor = actor
+			events[i].Route = c.Request.Method + " " + route
+		}
+		h.audit.record(events...)
+	}
+}
+
+// parseAuditQuery reads the category and since query parameters shared by the audit endpoints.
+func parseAuditQuery(c *gin.Context) (string, int64, bool) {
+	category := strings.ToLower(strings.TrimSpace(c.Query("category")))
+	var since int64
+	if raw := strings.TrimSpace(c.Query("since")); raw != "" {
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || value < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since: must be a non-negative event id"})
+			return "", 0, false
+		}
+		since = value
+	}
+	return category, since, true
+}
+
+// GetAuditEvents returns the kept audit events, optionally filtered by category and limited to
+// those after the event id given in since.
+func (h *Handler) GetAuditEvents(c *gin.Context) {
+	if h.audit == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "audit log not available"})
+		return
+	}
+	category, since, ok := parseAuditQuery(c)
+	if !ok {
+		return
+	}
+	limit, err := parseLimit(c.Query("limit"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit: " + err.Error()})
+		return
+	}
+	events := h.audit.since(since, category)
+	if limit > 0 && len(events) > limit {
+		events = events[len(events)-limit:]
+	}
+	c.JSON(http.StatusOK, gin.H{"events": events, "count": len(events)})
+}
+
+// StreamAuditEvents replays the kept audit events after since and then pushes new ones as
+// server-sent "audit" events, with a heartbeat comment when nothing happened for a while.
+func (h *Handler) StreamAuditEvents(c *gin.Context) {
+	if h.audit == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "audit log not available"})
+		return
+	}
+	category, since, ok := parseAuditQuery(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	ch, backlog := h.audit.subscribe(since, category)
+	defer h.audit.unsubscribe(ch)
+	last := since
+	for _, event := range backlog {
+		last = event.ID
+		c.SSEvent("audit", event)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(auditStreamHeartbeat)
+	defer heartbeat.Stop()
+	ctx := c.Request.Context()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case event := <-ch:
+			// Events recorded between the backlog read and the subscription arrive twice.
+			if event.ID > last && (category == "" || event.Category == category) {
+				last = event.ID
+				c.SSEvent("audit", event)
+			}
+			return true
+		case <-heartbeat.C:
+			_, _ = io.WriteString(w, ": heartbeat\n\n")
+			return true
+		}
+	})
+}
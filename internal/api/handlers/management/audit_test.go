@@ -0,0 +1,81 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestAuditMiddlewareRecordsPoolChanges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := coreauth.NewManager(nil, nil, nil)
+	if _, err := manager.Register(context.Background(), &coreauth.Auth{
+		ID: "a.json", Provider: "gemini", Status: coreauth.StatusActive,
+		Metadata: map[string]any{"access_token": "old"},
+	}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	h := &Handler{authManager: manager, audit: newAuditLog()}
+	router := gin.New()
+	mgmt := router.Group("/v0/management")
+	mgmt.Use(func(c *gin.Context) { setAuditActor(c, auditKeySecretKey) }, h.AuditMiddleware())
+	mgmt.POST("/auth-files", func(c *gin.Context) {
+		_, _ = manager.Register(c.Request.Context(), &coreauth.Auth{ID: "b.json", Provider: "codex", Metadata: map[string]any{}})
+		c.Status(http.StatusOK)
+	})
+	mgmt.POST("/accounts/tag", h.TagAccounts)
+	mgmt.PUT("/accounts/:id/priority", h.SetAccountPriority)
+	mgmt.POST("/rotate", func(c *gin.Context) {
+		auth, _ := manager.GetByID("a.json")
+		auth.Metadata["access_token"] = "new"
+		_, _ = manager.Update(c.Request.Context(), auth)
+		c.Status(http.StatusOK)
+	})
+	mgmt.DELETE("/auth-files", func(c *gin.Context) {
+		h.disableAuth(c.Request.Context(), "b.json")
+		c.Status(http.StatusOK)
+	})
+	mgmt.GET("/audit", h.GetAuditEvents)
+	call := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	call(http.MethodPost, "/v0/management/auth-files", "")
+	call(http.MethodPost, "/v0/management/accounts/tag", `{"filter":{"id_prefix":"a.json"},"tags":["team-a"]}`)
+	call(http.MethodPut, "/v0/management/accounts/a.json/priority", `{"priority":3}`)
+	call(http.MethodPost, "/v0/management/rotate", "")
+	call(http.MethodDelete, "/v0/management/auth-files", "")
+
+	rec := call(http.MethodGet, "/v0/management/audit?category=pool", "")
+	var body struct {
+		Events []AuditEvent `json:"events"`
+	}
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &body) != nil {
+		t.Fatalf("audit: status %d body %s", rec.Code, rec.Body.String())
+	}
+	want := []string{AuditAccountImported, AuditTagsChanged, AuditPriorityChanged, AuditCredentialsRotated, AuditAccountRemoved}
+	if len(body.Events) != len(want) {
+		t.Fatalf("got %d events, want %d: %s", len(body.Events), len(want), rec.Body.String())
+	}
+	for i, event := range body.Events {
+		if event.Action != want[i] || event.Category != AuditCategoryPool || event.Actor.Key != auditKeySecretKey {
+			t.Fatalf("event %d = %+v, want action %s", i, event, want[i])
+		}
+	}
+	if before, _ := body.Events[2].Before.(map[string]any); before["priority"] != float64(0) {
+		t.Fatalf("priority before = %v", body.Events[2].Before)
+	}
+
+	rec = call(http.MethodGet, "/v0/management/audit?since=4", "")
+	if json.Unmarshal(rec.Body.Bytes(), &body) != nil || len(body.Events) != 1 || body.Events[0].AccountID != "b.json" {
+		t.Fatalf("since filter: %s", rec.Body.String())
+	}
+}
@@ -646,6 +646,10 @@ func (h *Handler) registerAuthFromFile(ctx context.Context, path string, data []
 	return err
 }
 
+// authRemovedStatusMessage marks accounts whose auth file was deleted; they stay registered but
+// disabled, and count as removed from the pool.
+const authRemovedStatusMessage = "removed via management API"
+
 func (h *Handler) disableAuth(ctx context.Context, id string) {
 	if h == nil || h.authManager == nil {
 		return
@@ -660,7 +664,7 @@ func (h *Handler) disableAuth(ctx context.Context, id string) {
 	if auth, ok := h.authManager.GetByID(authID); ok {
 		auth.Disabled = true
 		auth.Status = coreauth.StatusDisabled
-		auth.StatusMessage = "removed via management API"
+		auth.StatusMessage = authRemovedStatusMessage
 		auth.UpdatedAt = time.Now()
 		_, _ = h.authManager.Update(ctx, auth)
 	}
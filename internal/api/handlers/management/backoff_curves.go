@@ -0,0 +1,40 @@
+package management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// backoffCurveView renders a backoff curve with delays in seconds, matching the config keys.
+type backoffCurveView struct {
+	BaseSeconds float64 `json:"base_seconds"`
+	Multiplier  float64 `json:"multiplier"`
+	MaxSeconds  float64 `json:"max_seconds"`
+	Jitter      float64 `json:"jitter"`
+}
+
+func viewBackoffCurve(curve coreauth.BackoffCurve) backoffCurveView {
+	return backoffCurveView{
+		BaseSeconds: curve.Base.Seconds(),
+		Multiplier:  curve.Multiplier,
+		MaxSeconds:  curve.Max.Seconds(),
+		Jitter:      curve.Jitter,
+	}
+}
+
+// GetBackoffCurves reports the quota backoff curves in effect: the default and each provider override.
+func (h *Handler) GetBackoffCurves(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	def, byProvider := h.authManager.BackoffCurves()
+	providers := make(map[string]backoffCurveView, len(byProvider))
+	for provider, curve := range byProvider {
+		providers[provider] = viewBackoffCurve(curve)
+	}
+	cooldownDisabled := h.cfg != nil && h.cfg.DisableCooling
+	c.JSON(http.StatusOK, gin.H{"default": viewBackoffCurve(def), "providers": providers, "cooldown_disabled": cooldownDisabled})
+}
@@ -0,0 +1,20 @@
+package management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetBatching reports request batching efficiency per provider.
+func (h *Handler) GetBatching(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	enabled := h.cfg != nil && h.cfg.Batching.Enabled
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":  enabled,
+		"batching": h.authManager.BatchStats(),
+	})
+}
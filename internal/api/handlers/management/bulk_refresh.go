@@ -0,0 +1,77 @@
+package management
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// defaultBulkRefreshMinInterval skips accounts refreshed this recently unless the request overrides it.
+const defaultBulkRefreshMinInterval = 5 * time.Minute
+
+type bulkRefreshRequest struct {
+	// Provider is shorthand for filter.provider.
+	Provider string        `json:"provider"`
+	Filter   accountFilter `json:"filter"`
+	// MinIntervalSeconds skips accounts refreshed within this many seconds; nil uses the default.
+	MinIntervalSeconds *int `json:"min_interval_seconds"`
+	Concurrency        int  `json:"concurrency"`
+}
+
+// BulkRefresh refreshes the tokens of every account matching a provider or filter with a bounded
+// worker pool and returns the outcome per account.
+func (h *Handler) BulkRefresh(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	var body bulkRefreshRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	filter := body.Filter
+	if provider := strings.TrimSpace(body.Provider); provider != "" {
+		filter.Provider = provider
+	}
+	filter = filter.normalize()
+	if filter == (accountFilter{}) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "provider or filter is required"})
+		return
+	}
+	switch filter.Status {
+	case "", monitorStateActive, monitorStateCooldown, monitorStateError, monitorStateDisabled, monitorStateBilling, monitorStateEgress:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status filter"})
+		return
+	}
+	minInterval := defaultBulkRefreshMinInterval
+	if body.MinIntervalSeconds != nil {
+		minInterval = time.Duration(*body.MinIntervalSeconds) * time.Second
+	}
+
+	now := time.Now()
+	var ids []string
+	for _, auth := range h.authManager.List() {
+		if filter.matches(auth, now) {
+			ids = append(ids, auth.ID)
+		}
+	}
+	sort.Strings(ids)
+	results := h.authManager.RefreshTokens(c.Request.Context(), ids, minInterval, body.Concurrency)
+	counts := map[string]int{coreauth.BulkRefreshRefreshed: 0, coreauth.BulkRefreshFailed: 0, coreauth.BulkRefreshSkipped: 0}
+	for _, result := range results {
+		counts[result.Result]++
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"matched":   len(ids),
+		"refreshed": counts[coreauth.BulkRefreshRefreshed],
+		"failed":    counts[coreauth.BulkRefreshFailed],
+		"skipped":   counts[coreauth.BulkRefreshSkipped],
+		"results":   results,
+	})
+}
@@ -0,0 +1,396 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	defaultCanaryInterval       = time.Hour
+	defaultCanaryDriftThreshold = 0.5
+	canaryProbeTimeout          = 2 * time.Minute
+	// canaryMaxWords bounds the word-level comparison so long responses stay cheap to diff.
+	canaryMaxWords = 1000
+)
+
+// canaryBaseline is the reference response a canary is compared against.
+type canaryBaseline struct {
+	Response   string    `json:"response"`
+	Model      string    `json:"model,omitempty"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// CanaryResult reports the latest run of one canary prompt.
+type CanaryResult struct {
+	Name       string    `json:"name"`
+	Provider   string    `json:"provider"`
+	Model      string    `json:"model"`
+	CheckedAt  time.Time `json:"checked_at"`
+	DurationMs int64     `json:"duration_ms"`
+	Response   string    `json:"response,omitempty"`
+	// ResponseModel is the model the upstream reported, when it reports one.
+	ResponseModel string `json:"response_model,omitempty"`
+	Error         string `json:"error,omitempty"`
+	// Drift is the fraction of words differing from the baseline, between 0 and 1.
+	Drift        float64   `json:"drift"`
+	Drifted      bool      `json:"drifted"`
+	ModelChanged bool      `json:"model_changed,omitempty"`
+	BaselineAt   time.Time `json:"baseline_at,omitempty"`
+}
+
+// canaryMonitor runs the configured canary prompts and keeps their baselines and latest results.
+type canaryMonitor struct {
+	mu        sync.Mutex
+	cfg       config.Canary
+	cancel    context.CancelFunc
+	baselines map[string]canaryBaseline
+	results   map[string]CanaryResult
+	loaded    string
+}
+
+func newCanaryMonitor() *canaryMonitor {
+	return &canaryMonitor{baselines: make(map[string]canaryBaseline), results: make(map[string]CanaryResult)}
+}
+
+// canaryName returns the configured name or "provider/model".
+func canaryName(p config.CanaryPrompt) string {
+	if name := strings.TrimSpace(p.Name); name != "" {
+		return name
+	}
+	return p.Provider + "/" + p.Model
+}
+
+// record compares a canary response with its baseline and stores the result. The first successful
+// response becomes the baseline.
+func (cm *canaryMonitor) record(p config.CanaryPrompt, response, model string, runErr error, started, now time.Time) CanaryResult {
+	name := canaryName(p)
+	result := CanaryResult{
+		Name:          name,
+		Provider:      p.Provider,
+		Model:         p.Model,
+		CheckedAt:     now,
+		DurationMs:    now.Sub(started).Milliseconds(),
+		Response:      response,
+		ResponseModel: model,
+	}
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	threshold := cm.cfg.DriftThreshold
+	if threshold <= 0 {
+		threshold = defaultCanaryDriftThreshold
+	}
+	switch baseline, ok := cm.baselines[name]; {
+	case runErr != nil:
+		result.Error = runErr.Error()
+		result.BaselineAt = baseline.RecordedAt
+	case !ok:
+		cm.baselines[name] = canaryBaseline{Response: response, Model: model, RecordedAt: now}
+		result.BaselineAt = now
+	default:
+		result.BaselineAt = baseline.RecordedAt
+		result.Drift = wordDrift(baseline.Response, response)
+		result.ModelChanged = baseline.Model != "" && model != "" && baseline.Model != model
+		result.Drifted = result.Drift > threshold || result.ModelChanged
+	}
+	cm.results[name] = result
+	return result
+}
+
+// wordDrift returns 1 minus the LCS similarity of the two texts' lowercased words.
+func wordDrift(a, b string) float64 {
+	wa, wb := canaryWords(a), canaryWords(b)
+	if len(wa)+len(wb) == 0 {
+		return 0
+	}
+	prev := make([]int, len(wb)+1)
+	curr := make([]int, len(wb)+1)
+	for i := 1; i <= len(wa); i++ {
+		for j := 1; j <= len(wb); j++ {
+			switch {
+			case wa[i-1] == wb[j-1]:
+				curr[j] = prev[j-1] + 1
+			case prev[j] >= curr[j-1]:
+				curr[j] = prev[j]
+			default:
+				curr[j] = curr[j-1]
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return 1 - 2*float64(prev[len(wb)])/float64(len(wa)+len(wb))
+}
+
+func canaryWords(text string) []string {
+	words := strings.Fields(strings.ToLower(text))
+	if len(words) > canaryMaxWords {
+		words = words[:canaryMaxWords]
+	}
+	return words
+}
+
+// probeCanary streams the prompt through the auth manager and returns the concatenated text and the
+// model the upstream reported.
+func (h *Handler) probeCanary(ctx context.Context, p config.CanaryPrompt) (string, string, error) {
+	if h.authManager == nil {
+		return "", "", fmt.Errorf("auth manager not available")
+	}
+	payload, err := json.Marshal(map[string]any{
+		"model":       p.Model,
+		"messages":    []map[string]string{{"role": "user", "content": p.Prompt}},
+		"stream":      true,
+		"temperature": 0,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	req := cliproxyexecutor.Request{Model: p.Model, Payload: payload}
+	opts := cliproxyexecutor.Options{Stream: true, OriginalRequest: payload, SourceFormat: sdktranslator.FormatOpenAI}
+	chunks, err := h.authManager.ExecuteStream(ctx, []string{p.Provider}, req, opts)
+	if err != nil {
+		return "", "", err
+	}
+	var text strings.Builder
+	model := ""
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			return text.String(), model, chunk.Err
+		}
+		for _, line := range strings.Split(string(chunk.Payload), "\n") {
+			line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "data:"))
+			if line == "" || line == "[DONE]" || !gjson.Valid(line) {
+				continue
+			}
+			if m := gjson.Get(line, "model").String(); m != "" {
+				model = m
+			}
+			text.WriteString(gjson.Get(line, "choices.0.delta.content").String())
+		}
+	}
+	return text.String(), model, nil
+}
+
+func (h *Handler) runCanaries(ctx context.Context, prompts []config.CanaryPrompt) {
+	for _, p := range prompts {
+		if ctx.Err() != nil {
+			return
+		}
+		probeCtx, cancel := context.WithTimeout(ctx, canaryProbeTimeout)
+		started := time.Now()
+		response, model, err := h.probeCanary(probeCtx, p)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		result := h.canary.record(p, response, model, err, started, time.Now())
+		switch {
+		case result.Error != "":
+			log.Warnf("canary %s failed: %s", result.Name, result.Error)
+		case result.Drifted:
+			log.Warnf("canary %s drifted from its baseline (drift %.2f, model %q)", result.Name, result.Drift, result.ResponseModel)
+		}
+	}
+	h.canary.mu.Lock()
+	path := h.canary.cfg.Path
+	h.canary.mu.Unlock()
+	h.canary.flush(path)
+}
+
+func (h *Handler) runCanaryJob(ctx context.Context, interval time.Duration, prompts []config.CanaryPrompt) {
+	h.runCanaries(ctx, prompts)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.runCanaries(ctx, prompts)
+		}
+	}
+}
+
+// SetCanary applies the canary config, (re)starting or stopping the job.
+func (h *Handler) SetCanary(cfg config.Canary) {
+	if h == nil || h.canary == nil {
+		return
+	}
+	cm := h.canary
+	cfg.Path = strings.TrimSpace(cfg.Path)
+	prompts := make([]config.CanaryPrompt, 0, len(cfg.Prompts))
+	for _, p := range cfg.Prompts {
+		p.Provider = strings.ToLower(strings.TrimSpace(p.Provider))
+		p.Model = strings.TrimSpace(p.Model)
+		if p.Provider == "" || p.Model == "" || strings.TrimSpace(p.Prompt) == "" {
+			log.Warnf("canary %q skipped: provider, model and prompt are required", p.Name)
+			continue
+		}
+		prompts = append(prompts, p)
+	}
+	cm.mu.Lock()
+	if cm.cancel != nil && reflect.DeepEqual(cm.cfg, cfg) {
+		cm.mu.Unlock()
+		return
+	}
+	if cm.cancel != nil {
+		cm.cancel()
+		cm.cancel = nil
+	}
+	cm.cfg = cfg
+	if !cfg.Enabled || len(prompts) == 0 {
+		cm.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cm.cancel = cancel
+	load := cfg.Path != "" && cm.loaded != cfg.Path
+	if load {
+		cm.loaded = cfg.Path
+	}
+	cm.mu.Unlock()
+
+	if load {
+		if err := cm.load(cfg.Path); err != nil {
+			log.Warnf("failed to load canary baselines from %s: %v", cfg.Path, err)
+		}
+	}
+	interval := time.Duration(cfg.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = defaultCanaryInterval
+	}
+	go h.runCanaryJob(ctx, interval, prompts)
+}
+
+// StopCanary stops the canary job.
+func (h *Handler) StopCanary() {
+	if h == nil || h.canary == nil {
+		return
+	}
+	h.canary.mu.Lock()
+	if h.canary.cancel != nil {
+		h.canary.cancel()
+		h.canary.cancel = nil
+	}
+	h.canary.mu.Unlock()
+}
+
+// flush saves baselines when persistence is configured, logging failures.
+func (cm *canaryMonitor) flush(path string) {
+	if path == "" {
+		return
+	}
+	cm.mu.Lock()
+	data, err := json.Marshal(cm.baselines)
+	cm.mu.Unlock()
+	if err == nil {
+		err = writeFileAtomic(path, data)
+	}
+	if err != nil {
+		log.Warnf("failed to save canary baselines: %v", err)
+	}
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create dir: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("replace: %w", err)
+	}
+	return nil
+}
+
+// load restores baselines previously written by flush. A missing file is not an error.
+func (cm *canaryMonitor) load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("canary baselines: read: %w", err)
+	}
+	var baselines map[string]canaryBaseline
+	if err = json.Unmarshal(data, &baselines); err != nil {
+		return fmt.Errorf("canary baselines: decode: %w", err)
+	}
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	for name, baseline := range baselines {
+		cm.baselines[name] = baseline
+	}
+	return nil
+}
+
+// GetCanary reports the latest result of every canary prompt.
+func (h *Handler) GetCanary(c *gin.Context) {
+	cm := h.canary
+	cm.mu.Lock()
+	results := make([]CanaryResult, 0, len(cm.results))
+	drifted := 0
+	for _, result := range cm.results {
+		results = append(results, result)
+		if result.Drifted {
+			drifted++
+		}
+	}
+	enabled := cm.cfg.Enabled
+	cm.mu.Unlock()
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	c.JSON(http.StatusOK, gin.H{"enabled": enabled, "drifted": drifted, "results": results})
+}
+
+// ResetCanaryBaseline accepts the latest successful responses as new baselines. The optional JSON
+// body {"name": "..."} limits the reset to one canary.
+func (h *Handler) ResetCanaryBaseline(c *gin.Context) {
+	var body struct {
+		Name string `json:"name"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+	}
+	name := strings.TrimSpace(body.Name)
+	cm := h.canary
+	cm.mu.Lock()
+	reset := make([]string, 0)
+	for key, result := range cm.results {
+		if (name != "" && key != name) || result.Error != "" {
+			continue
+		}
+		cm.baselines[key] = canaryBaseline{Response: result.Response, Model: result.ResponseModel, RecordedAt: result.CheckedAt}
+		result.Drift, result.Drifted, result.ModelChanged, result.BaselineAt = 0, false, false, result.CheckedAt
+		cm.results[key] = result
+		reset = append(reset, key)
+	}
+	path := cm.cfg.Path
+	cm.mu.Unlock()
+	if name != "" && len(reset) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "canary not found or has no successful result"})
+		return
+	}
+	cm.flush(path)
+	sort.Strings(reset)
+	c.JSON(http.StatusOK, gin.H{"reset": reset})
+}
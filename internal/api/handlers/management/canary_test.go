@@ -0,0 +1,54 @@
+package management
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestCanaryRecordFlagsDriftAgainstBaseline(t *testing.T) {
+	cm := newCanaryMonitor()
+	cm.cfg = config.Canary{DriftThreshold: 0.3}
+	prompt := config.CanaryPrompt{Provider: "codex", Model: "gpt-5", Prompt: "capitals"}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	first := cm.record(prompt, "Paris\nTokyo\nBrasilia", "gpt-5-2025", nil, now, now)
+	if first.Name != "codex/gpt-5" || first.Drifted || !first.BaselineAt.Equal(now) {
+		t.Fatalf("first response should become the baseline, got %+v", first)
+	}
+	if same := cm.record(prompt, "paris tokyo brasilia", "gpt-5-2025", nil, now, now.Add(time.Hour)); same.Drift != 0 || same.Drifted {
+		t.Fatalf("identical words must not drift, got %+v", same)
+	}
+	if failed := cm.record(prompt, "", "", errors.New("boom"), now, now.Add(2*time.Hour)); failed.Drifted || failed.Error != "boom" {
+		t.Fatalf("failed runs are reported without drift, got %+v", failed)
+	}
+	changed := cm.record(prompt, "I cannot help with geography today", "gpt-5-2025", nil, now, now.Add(3*time.Hour))
+	if !changed.Drifted || changed.Drift != 1 {
+		t.Fatalf("unrelated response should drift fully, got %+v", changed)
+	}
+	if renamed := cm.record(prompt, "Paris Tokyo Brasilia", "gpt-5-2026", nil, now, now.Add(4*time.Hour)); !renamed.Drifted || !renamed.ModelChanged {
+		t.Fatalf("a different upstream model should be flagged, got %+v", renamed)
+	}
+
+	path := filepath.Join(t.TempDir(), "baselines.json")
+	cm.flush(path)
+	restored := newCanaryMonitor()
+	if err := restored.load(path); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := restored.baselines["codex/gpt-5"].Response; got != "Paris\nTokyo\nBrasilia" {
+		t.Fatalf("baseline not restored, got %q", got)
+	}
+}
+
+func TestWordDrift(t *testing.T) {
+	if d := wordDrift("a b c d", "a b x d"); d != 0.25 {
+		t.Fatalf("expected drift 0.25, got %v", d)
+	}
+	if d := wordDrift("", ""); d != 0 {
+		t.Fatalf("empty texts should not drift, got %v", d)
+	}
+}
@@ -0,0 +1,20 @@
+package management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCircuitBreakers reports the state and half-open probe progress of every provider circuit breaker.
+func (h *Handler) GetCircuitBreakers(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	enabled := h.cfg != nil && h.cfg.CircuitBreaker.Enabled
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":          enabled,
+		"circuit-breakers": h.authManager.CircuitBreakers(),
+	})
+}
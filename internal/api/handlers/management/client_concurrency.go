@@ -0,0 +1,20 @@
+package management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetClientConcurrency reports the in-flight and queued request counts per client API key.
+func (h *Handler) GetClientConcurrency(c *gin.Context) {
+	if h == nil || h.clientConcurrency == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "client concurrency limiter not available"})
+		return
+	}
+	enabled := h.cfg != nil && h.cfg.ClientConcurrency.Enabled
+	c.JSON(http.StatusOK, gin.H{
+		"enabled": enabled,
+		"clients": h.clientConcurrency.Snapshot(),
+	})
+}
@@ -0,0 +1,78 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+)
+
+// ListClientStrategies reports the global strategy and the per-client-key overrides with masked keys.
+func (h *Handler) ListClientStrategies(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	overrides := h.authManager.ClientStrategies()
+	for i := range overrides {
+		overrides[i].APIKey = util.HideAPIKey(overrides[i].APIKey)
+	}
+	c.JSON(http.StatusOK, gin.H{"global": h.authManager.GlobalStrategy(), "overrides": overrides})
+}
+
+// SetClientStrategy overrides the selection strategy for one client API key. The body is
+// {"strategy": "...", "ttl_seconds": N}; without a ttl the override stays until deleted.
+func (h *Handler) SetClientStrategy(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	key := strings.TrimSpace(c.Param("key"))
+	if !h.knownClientKey(key) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown client key"})
+		return
+	}
+	var body struct {
+		Strategy   string `json:"strategy"`
+		TTLSeconds int    `json:"ttl_seconds"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	override, err := h.authManager.SetClientStrategy(key, body.Strategy, time.Duration(body.TTLSeconds)*time.Second)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	override.APIKey = util.HideAPIKey(override.APIKey)
+	c.JSON(http.StatusOK, override)
+}
+
+// DeleteClientStrategy returns a client API key to the global strategy.
+func (h *Handler) DeleteClientStrategy(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	if !h.authManager.ClearClientStrategy(c.Param("key")) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no strategy override for client key"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// knownClientKey reports whether key is one of the configured client API keys.
+func (h *Handler) knownClientKey(key string) bool {
+	if key == "" || h.cfg == nil {
+		return false
+	}
+	for _, configured := range h.cfg.APIKeys {
+		if configured == key {
+			return true
+		}
+	}
+	return false
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"gopkg.in/yaml.v3"
 )
 
@@ -18,6 +19,15 @@ func (h *Handler) GetConfig(c *gin.Context) {
 	}
 	cfgCopy := *h.cfg
 	cfgCopy.GlAPIKey = geminiKeyStringsFromConfig(h.cfg)
+	cfgCopy.EffectiveRequestClamps = h.cfg.ResolveRequestClamps()
+	cfgCopy.EffectiveClientModelPolicies = h.cfg.ResolveClientModelPolicies()
+	cfgCopy.EffectiveContextLimits = util.ContextWindows(h.cfg.ContextLengthErrors.Limits)
+	cfgCopy.EffectiveSeedSupport = util.SeedSupport()
+	cfgCopy.EffectiveStopSequenceLimits = util.StopSequenceLimits(h.cfg.StopSequenceLimits.Limits)
+	cfgCopy.PrefixAffinity.EffectiveMode = h.cfg.PrefixAffinity.Mode()
+	if h.authManager != nil {
+		cfgCopy.RuntimeState.Active = h.authManager.RuntimeStateBackend()
+	}
 	c.JSON(200, &cfgCopy)
 }
 
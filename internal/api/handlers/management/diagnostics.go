@@ -0,0 +1,283 @@
+package management
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/buildinfo"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	diagnosticsLogLines = 500
+	// diagnosticsAuditEvents bounds how many of the most recent audit events the bundle carries.
+	diagnosticsAuditEvents = 200
+	redactedValue          = "[REDACTED]"
+)
+
+// sensitiveKeyFragments marks config fields whose string values are secrets.
+var sensitiveKeyFragments = []string{"key", "secret", "password", "token", "authorization", "cookie", "credential"}
+
+// bearerPattern catches bearer credentials that appear in free-form text such as log lines.
+var bearerPattern = regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=-]+`)
+
+// proxyCredentialPattern catches user:password pairs embedded in URLs.
+var proxyCredentialPattern = regexp.MustCompile(`(://)[^/@\s:]+:[^/@\s]+@`)
+
+// diagnosticsRedactor removes secrets from the bundle. It redacts sensitive config fields and
+// then scrubs every secret it saw from the remaining sections.
+type diagnosticsRedactor struct {
+	secrets map[string]struct{}
+}
+
+func isSensitiveKey(key string) bool {
+	lower := strings.ToLower(key)
+	if lower == "headers" {
+		return true
+	}
+	for _, fragment := range sensitiveKeyFragments {
+		if strings.Contains(lower, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactValue walks a decoded JSON value, replacing strings held under sensitive keys. Maps reached
+// through a sensitive key (such as headers) have all their values redacted.
+func (r *diagnosticsRedactor) redactValue(value interface{}, sensitive bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			v[key] = r.redactValue(child, sensitive || isSensitiveKey(key))
+		}
+		return v
+	case []interface{}:
+		// Sensitive lists redact their strings; objects inside them decide per field.
+		for i, child := range v {
+			_, isString := child.(string)
+			v[i] = r.redactValue(child, sensitive && isString)
+		}
+		return v
+	case string:
+		if sensitive && v != "" {
+			r.secrets[v] = struct{}{}
+			return redactedValue
+		}
+		return r.scrub(v)
+	default:
+		return v
+	}
+}
+
+// scrub removes known secrets and credential patterns from free-form text.
+func (r *diagnosticsRedactor) scrub(text string) string {
+	for secret := range r.secrets {
+		if len(secret) >= 4 {
+			text = strings.ReplaceAll(text, secret, redactedValue)
+		}
+	}
+	text = bearerPattern.ReplaceAllString(text, "${1}"+redactedValue)
+	return proxyCredentialPattern.ReplaceAllString(text, "${1}"+redactedValue+"@")
+}
+
+// redactJSON marshals v and redacts it generically so newly added config fields are covered too.
+func (r *diagnosticsRedactor) redactJSON(v interface{}, sensitive bool) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err = json.Unmarshal(raw, &decoded); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(r.redactValue(decoded, sensitive), "", "  ")
+}
+
+// diagnosticsErrorSummary counts the last errors currently recorded on accounts.
+type diagnosticsErrorSummary struct {
+	Provider   string `json:"provider"`
+	Code       string `json:"code,omitempty"`
+	HTTPStatus int    `json:"http_status,omitempty"`
+	Count      int    `json:"count"`
+	Sample     string `json:"sample,omitempty"`
+}
+
+func (h *Handler) diagnosticsErrorSummary(accounts []AccountStatus) []diagnosticsErrorSummary {
+	byKey := make(map[string]*diagnosticsErrorSummary)
+	for _, account := range accounts {
+		if account.LastError == nil {
+			continue
+		}
+		code, _ := account.LastError["code"].(string)
+		status, _ := account.LastError["http_status"].(int)
+		key := fmt.Sprintf("%s|%s|%d", account.Provider, code, status)
+		entry, ok := byKey[key]
+		if !ok {
+			message, _ := account.LastError["message"].(string)
+			entry = &diagnosticsErrorSummary{Provider: account.Provider, Code: code, HTTPStatus: status, Sample: message}
+			byKey[key] = entry
+		}
+		entry.Count++
+	}
+	out := make([]diagnosticsErrorSummary, 0, len(byKey))
+	for _, entry := range byKey {
+		out = append(out, *entry)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Provider < out[j].Provider
+	})
+	return out
+}
+
+// recentLogLines returns the tail of the main log when logging to file is enabled.
+func (h *Handler) recentLogLines() []string {
+	if h.cfg == nil || !h.cfg.LoggingToFile {
+		return nil
+	}
+	files, err := h.collectLogFiles(h.logDirectory())
+	if err != nil {
+		return nil
+	}
+	acc := newLogAccumulator(0, diagnosticsLogLines)
+	for _, file := range files {
+		if errConsume := acc.consumeFile(file); errConsume != nil {
+			log.Debugf("diagnostics: failed to read log file %s: %v", file, errConsume)
+		}
+	}
+	lines, _, _ := acc.result()
+	return lines
+}
+
+func (h *Handler) diagnosticsRuntimeInfo(now time.Time) map[string]interface{} {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	info := map[string]interface{}{
+		"generated_at":  now,
+		"version":       buildinfo.Version,
+		"commit":        buildinfo.Commit,
+		"build_date":    buildinfo.BuildDate,
+		"go_version":    runtime.Version(),
+		"os":            runtime.GOOS,
+		"arch":          runtime.GOARCH,
+		"num_cpu":       runtime.NumCPU(),
+		"goroutines":    runtime.NumGoroutine(),
+		"heap_alloc":    mem.HeapAlloc,
+		"runtime_state": "none",
+	}
+	if h.authManager != nil {
+		info["runtime_state"] = h.authManager.RuntimeStateBackend()
+		info["runtime_state_persistence"] = h.authManager.RuntimeStatePersistenceHealth()
+	}
+	return info
+}
+
+// GetDiagnostics streams a zip bundle with redacted config, account monitor snapshot, recent audit
+// events, error summary, circuit breaker states, recent log lines and runtime info for attaching to
+// bug reports.
+func (h *Handler) GetDiagnostics(c *gin.Context) {
+	if h == nil || h.cfg == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "configuration unavailable"})
+		return
+	}
+	now := time.Now()
+	redactor := &diagnosticsRedactor{secrets: make(map[string]struct{})}
+
+	cfgCopy := *h.cfg
+	cfgCopy.GlAPIKey = geminiKeyStringsFromConfig(h.cfg)
+	// Effective clamps are keyed by client API key; the resolved rules are derivable from request-clamps.
+	cfgCopy.EffectiveRequestClamps = nil
+	cfgCopy.EffectiveContextLimits = nil
+	configJSON, err := redactor.redactJSON(&cfgCopy, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to encode config: %v", err)})
+		return
+	}
+	// Client keys live in the sdk config and are always secret regardless of field naming.
+	for _, key := range h.cfg.APIKeys {
+		redactor.secrets[key] = struct{}{}
+	}
+
+	var accounts []AccountStatus
+	sections := map[string]interface{}{
+		"runtime.json": h.diagnosticsRuntimeInfo(now),
+	}
+	if h.authManager != nil {
+		for _, auth := range h.authManager.List() {
+			if auth != nil {
+				accounts = append(accounts, buildAccountStatus(auth))
+			}
+		}
+		sections["circuit-breakers.json"] = h.authManager.CircuitBreakers()
+		sections["providers.json"] = buildProvidersSnapshot(h.authManager, now)
+	}
+	sections["accounts.json"] = accounts
+	sections["errors.json"] = h.diagnosticsErrorSummary(accounts)
+	if h.audit != nil {
+		events := h.audit.since(0, "")
+		if len(events) > diagnosticsAuditEvents {
+			events = events[len(events)-diagnosticsAuditEvents:]
+		}
+		sections["audit-events.json"] = events
+	}
+
+	logLines := h.recentLogLines()
+	for i := range logLines {
+		logLines[i] = redactor.scrub(logLines[i])
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name string, data []byte) error {
+		w, errCreate := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: now})
+		if errCreate != nil {
+			return errCreate
+		}
+		_, errWrite := w.Write(data)
+		return errWrite
+	}
+	if err = write("config.json", configJSON); err == nil {
+		names := make([]string, 0, len(sections))
+		for name := range sections {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			data, errEncode := redactor.redactJSON(sections[name], false)
+			if errEncode != nil {
+				err = errEncode
+				break
+			}
+			if err = write(name, data); err != nil {
+				break
+			}
+		}
+	}
+	if err == nil {
+		err = write("recent-logs.txt", []byte(strings.Join(logLines, "\n")))
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to build diagnostics bundle: %v", err)})
+		return
+	}
+
+	filename := fmt.Sprintf("cliproxy-diagnostics-%s.zip", now.UTC().Format("20060102-150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
@@ -0,0 +1,75 @@
+package management
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+const defaultDrillMaxDuration = 10 * time.Minute
+
+type drillRequest struct {
+	Percent         float64 `json:"percent"`
+	DurationSeconds int     `json:"duration_seconds"`
+	Provider        string  `json:"provider"`
+}
+
+// RunDrill cordons a share of the accounts for a window, restores them afterwards and responds with a
+// report of how client requests fared meanwhile. The request stays open until the drill ends;
+// disconnecting aborts it early. Drills are refused unless drill.enabled is set.
+func (h *Handler) RunDrill(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	if h.cfg == nil || !h.cfg.Drill.Enabled {
+		c.JSON(http.StatusForbidden, gin.H{"error": "failover drills are disabled"})
+		return
+	}
+	var body drillRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	duration := time.Duration(body.DurationSeconds) * time.Second
+	maxDuration := defaultDrillMaxDuration
+	if h.cfg.Drill.MaxDurationSeconds > 0 {
+		maxDuration = time.Duration(h.cfg.Drill.MaxDurationSeconds) * time.Second
+	}
+	if duration > maxDuration {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "duration exceeds drill.max-duration-seconds"})
+		return
+	}
+	report, err := h.authManager.RunDrill(c.Request.Context(), coreauth.DrillSpec{
+		Percent:  body.Percent,
+		Duration: duration,
+		Provider: body.Provider,
+	})
+	if err != nil {
+		var authErr *coreauth.Error
+		if errors.As(err, &authErr) && authErr.HTTPStatus != 0 {
+			c.JSON(authErr.HTTPStatus, gin.H{"error": authErr.Message})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetDrill reports the running drill, or the last finished one.
+func (h *Handler) GetDrill(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	report, ok := h.authManager.Drill()
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no drill has run"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
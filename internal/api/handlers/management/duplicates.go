@@ -0,0 +1,16 @@
+package management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDuplicates lists accounts that were loaded with the same credential as another account.
+func (h *Handler) GetDuplicates(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"mode": h.authManager.DuplicateMode(), "duplicates": h.authManager.Duplicates()})
+}
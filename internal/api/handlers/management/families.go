@@ -0,0 +1,114 @@
+package management
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// FamilyStatus aggregates the accounts sharing one upstream quota. Selections counts recent
+// first-attempt picks of any member; SelectionShare relates them to all recent picks of the provider.
+type FamilyStatus struct {
+	Family         string          `json:"family"`
+	Provider       string          `json:"provider,omitempty"`
+	MemberCount    int             `json:"member_count"`
+	AvailableCount int             `json:"available_count"`
+	Constrained    bool            `json:"constrained"`
+	NextRecoverAt  *time.Time      `json:"next_recover_at,omitempty"`
+	Selections     int             `json:"selections"`
+	SelectionShare float64         `json:"selection_share"`
+	Members        []AccountStatus `json:"members"`
+}
+
+// FamiliesResponse is the response structure for the families endpoint.
+type FamiliesResponse struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Families  []FamilyStatus `json:"families"`
+}
+
+// familyRecoverAt returns when a cooling member becomes usable again, or zero if it is not cooling down.
+func familyRecoverAt(auth *coreauth.Auth, now time.Time) time.Time {
+	var next time.Time
+	consider := func(t time.Time) {
+		if t.After(now) && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+	if auth.Quota.Exceeded {
+		consider(auth.Quota.NextRecoverAt)
+	}
+	for _, state := range auth.ModelStates {
+		if state != nil && state.Quota.Exceeded {
+			consider(state.Quota.NextRecoverAt)
+		}
+	}
+	return next
+}
+
+// GetFamilies returns accounts grouped by family with the shared quota status of each family. It
+// also carries each family's share of recent selections, since there is no separate fairness endpoint.
+func (h *Handler) GetFamilies(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	now := time.Now()
+	byFamily := make(map[string]*FamilyStatus)
+	selections := h.authManager.SelectionCounts()
+	providerSelections := make(map[string]int)
+	for _, auth := range h.authManager.List() {
+		if auth == nil {
+			continue
+		}
+		providerSelections[auth.Provider] += selections[auth.ID]
+		name := auth.Family()
+		if name == "" {
+			continue
+		}
+		family, ok := byFamily[name]
+		if !ok {
+			family = &FamilyStatus{Family: name, Provider: auth.Provider}
+			byFamily[name] = family
+		} else if family.Provider != auth.Provider {
+			family.Provider = ""
+		}
+		family.MemberCount++
+		family.Selections += selections[auth.ID]
+		family.Members = append(family.Members, buildAccountStatus(auth))
+
+		state := accountMonitorState(auth, now)
+		if state == monitorStateActive {
+			family.AvailableCount++
+		}
+		if recoverAt := familyRecoverAt(auth, now); !recoverAt.IsZero() {
+			family.Constrained = true
+			if family.NextRecoverAt == nil || recoverAt.Before(*family.NextRecoverAt) {
+				t := recoverAt
+				family.NextRecoverAt = &t
+			}
+		} else if state == monitorStateCooldown {
+			family.Constrained = true
+		}
+	}
+
+	response := FamiliesResponse{Timestamp: now, Families: make([]FamilyStatus, 0, len(byFamily))}
+	for _, family := range byFamily {
+		total := providerSelections[family.Provider]
+		if family.Provider == "" {
+			total = 0
+			for _, n := range providerSelections {
+				total += n
+			}
+		}
+		if total > 0 {
+			family.SelectionShare = float64(family.Selections) / float64(total)
+		}
+		sort.Slice(family.Members, func(i, j int) bool { return family.Members[i].ID < family.Members[j].ID })
+		response.Families = append(response.Families, *family)
+	}
+	sort.Slice(response.Families, func(i, j int) bool { return response.Families[i].Family < response.Families[j].Family })
+	c.JSON(http.StatusOK, response)
+}
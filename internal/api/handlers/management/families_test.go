@@ -0,0 +1,59 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestGetFamilies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := coreauth.NewManager(nil, nil, nil)
+	soon := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+	later := soon.Add(time.Hour)
+	for _, auth := range []*coreauth.Auth{
+		{ID: "fam-a1", Provider: "gemini", Status: coreauth.StatusActive, Attributes: map[string]string{"family": "alpha"}},
+		{ID: "fam-a2", Provider: "gemini", Status: coreauth.StatusActive, Unavailable: true, Attributes: map[string]string{"family": "alpha"},
+			Quota: coreauth.QuotaState{Exceeded: true, NextRecoverAt: later}},
+		{ID: "fam-a3", Provider: "gemini", Status: coreauth.StatusActive, Metadata: map[string]any{"family": "alpha"},
+			ModelStates: map[string]*coreauth.ModelState{"gemini-2.5-pro": {Quota: coreauth.QuotaState{Exceeded: true, NextRecoverAt: soon}}}},
+		{ID: "fam-b1", Provider: "claude", Status: coreauth.StatusActive, Attributes: map[string]string{"family": "beta"}},
+		{ID: "loner", Provider: "claude", Status: coreauth.StatusActive},
+	} {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	h := &Handler{authManager: manager}
+	router := gin.New()
+	router.GET("/families", h.GetFamilies)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/families", nil))
+	var resp FamiliesResponse
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &resp) != nil {
+		t.Fatalf("status %d body %s", rec.Code, rec.Body.String())
+	}
+	if len(resp.Families) != 2 || resp.Families[0].Family != "alpha" || resp.Families[1].Family != "beta" {
+		t.Fatalf("families = %+v, want alpha and beta only", resp.Families)
+	}
+	alpha := resp.Families[0]
+	if alpha.Provider != "gemini" || alpha.MemberCount != 3 || alpha.AvailableCount != 2 || !alpha.Constrained {
+		t.Fatalf("alpha = %+v", alpha)
+	}
+	if alpha.NextRecoverAt == nil || !alpha.NextRecoverAt.Equal(soon) {
+		t.Fatalf("alpha recovers at %v, want the soonest member recovery %v", alpha.NextRecoverAt, soon)
+	}
+	if len(alpha.Members) != 3 || alpha.Members[0].ID != "fam-a1" || alpha.Members[0].Family != "alpha" {
+		t.Fatalf("alpha members = %+v", alpha.Members)
+	}
+	if beta := resp.Families[1]; beta.Constrained || beta.AvailableCount != 1 || beta.NextRecoverAt != nil {
+		t.Fatalf("beta = %+v", beta)
+	}
+}
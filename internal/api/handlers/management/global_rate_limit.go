@@ -0,0 +1,35 @@
+package management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetGlobalRateLimit reports the global requests-per-minute cap and the current rate.
+func (h *Handler) GetGlobalRateLimit(c *gin.Context) {
+	if h == nil || h.globalRateLimit == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "global rate limiter not available"})
+		return
+	}
+	c.JSON(http.StatusOK, h.globalRateLimit.Status())
+}
+
+// SetGlobalRateLimitCap changes the global requests-per-minute cap at runtime and persists it so
+// config reloads keep it. The body is {"rpm": N}; 0 removes the cap.
+func (h *Handler) SetGlobalRateLimitCap(c *gin.Context) {
+	if h == nil || h.globalRateLimit == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "global rate limiter not available"})
+		return
+	}
+	var body struct {
+		RPM *int `json:"rpm"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.RPM == nil || *body.RPM < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rpm must be a non-negative integer"})
+		return
+	}
+	h.globalRateLimit.SetCap(*body.RPM)
+	h.cfg.GlobalRateLimitRPM = *body.RPM
+	h.persist(c)
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/api/middleware"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/buildinfo"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
@@ -40,6 +41,12 @@ type Handler struct {
 	allowRemoteOverride bool
 	envSecret           string
 	logDir              string
+	clientConcurrency   *middleware.ClientConcurrencyLimiter
+	globalRateLimit     *middleware.GlobalRateLimiter
+	history             *healthHistory
+	canary              *canaryMonitor
+	webhook             *accountWebhook
+	audit               *auditLog
 }
 
 // NewHandler creates a new management handler instance.
@@ -56,6 +63,10 @@ func NewHandler(cfg *config.Config, configFilePath string, manager *coreauth.Man
 		tokenStore:          sdkAuth.GetTokenStore(),
 		allowRemoteOverride: envSecret != "",
 		envSecret:           envSecret,
+		history:             newHealthHistory(),
+		canary:              newCanaryMonitor(),
+		webhook:             newAccountWebhook(),
+		audit:               newAuditLog(),
 	}
 }
 
@@ -68,6 +79,16 @@ func (h *Handler) SetAuthManager(manager *coreauth.Manager) { h.authManager = ma
 // SetUsageStatistics allows replacing the usage statistics reference.
 func (h *Handler) SetUsageStatistics(stats *usage.RequestStatistics) { h.usageStats = stats }
 
+// SetClientConcurrency attaches the per-client concurrency limiter reported by management endpoints.
+func (h *Handler) SetClientConcurrency(limiter *middleware.ClientConcurrencyLimiter) {
+	h.clientConcurrency = limiter
+}
+
+// SetGlobalRateLimit attaches the global request rate limiter controlled by management endpoints.
+func (h *Handler) SetGlobalRateLimit(limiter *middleware.GlobalRateLimiter) {
+	h.globalRateLimit = limiter
+}
+
 // SetLocalPassword configures the runtime-local password accepted for localhost requests.
 func (h *Handler) SetLocalPassword(password string) { h.localPassword = password }
 
@@ -181,6 +202,7 @@ func (h *Handler) Middleware() gin.HandlerFunc {
 		if localClient {
 			if lp := h.localPassword; lp != "" {
 				if subtle.ConstantTimeCompare([]byte(provided), []byte(lp)) == 1 {
+					setAuditActor(c, auditKeyLocalPassword)
 					c.Next()
 					return
 				}
@@ -196,6 +218,7 @@ func (h *Handler) Middleware() gin.HandlerFunc {
 				}
 				h.attemptsMu.Unlock()
 			}
+			setAuditActor(c, auditKeyEnvSecret)
 			c.Next()
 			return
 		}
@@ -217,6 +240,7 @@ func (h *Handler) Middleware() gin.HandlerFunc {
 			h.attemptsMu.Unlock()
 		}
 
+		setAuditActor(c, auditKeySecretKey)
 		c.Next()
 	}
 }
@@ -0,0 +1,412 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultHealthSampleInterval = time.Minute
+	healthHistorySaveInterval   = 5 * time.Minute
+	defaultHealthHistoryRange   = 24 * time.Hour
+)
+
+// healthTiers defines resolution-based retention: fine buckets are kept briefly, coarse ones longer.
+var healthTiers = []struct {
+	Resolution time.Duration
+	Retention  time.Duration
+}{
+	{time.Minute, 24 * time.Hour},
+	{5 * time.Minute, 7 * 24 * time.Hour},
+	{time.Hour, 30 * 24 * time.Hour},
+	{24 * time.Hour, 365 * 24 * time.Hour},
+}
+
+// healthCounts holds per-provider account state counts; bucket values are sums over samples.
+type healthCounts struct {
+	Total    float64 `json:"total"`
+	Active   float64 `json:"active"`
+	Cooldown float64 `json:"cooldown"`
+	Error    float64 `json:"error"`
+	Disabled float64 `json:"disabled"`
+	Billing  float64 `json:"billing_suspended"`
+}
+
+func (c *healthCounts) add(o healthCounts) {
+	c.Total += o.Total
+	c.Active += o.Active
+	c.Cooldown += o.Cooldown
+	c.Error += o.Error
+	c.Disabled += o.Disabled
+	c.Billing += o.Billing
+}
+
+func (c healthCounts) scale(f float64) healthCounts {
+	return healthCounts{
+		Total:    c.Total * f,
+		Active:   c.Active * f,
+		Cooldown: c.Cooldown * f,
+		Error:    c.Error * f,
+		Disabled: c.Disabled * f,
+		Billing:  c.Billing * f,
+	}
+}
+
+// healthBucket aggregates all samples taken within one resolution interval.
+type healthBucket struct {
+	Start     time.Time               `json:"start"`
+	Samples   int                     `json:"samples"`
+	Providers map[string]healthCounts `json:"providers"`
+}
+
+// healthHistory samples pool health periodically into bounded, multi-resolution buckets.
+type healthHistory struct {
+	mu sync.Mutex
+	// saveMu serialises writes of the history file.
+	saveMu   sync.Mutex
+	tiers    [][]healthBucket
+	cfg      config.HealthHistory
+	cancel   context.CancelFunc
+	lastSave time.Time
+	loaded   string
+}
+
+func newHealthHistory() *healthHistory {
+	return &healthHistory{tiers: make([][]healthBucket, len(healthTiers))}
+}
+
+// record folds one sample into every tier and drops buckets past their tier's retention.
+func (hh *healthHistory) record(now time.Time, providers map[string]healthCounts) {
+	hh.mu.Lock()
+	defer hh.mu.Unlock()
+	for i, tier := range healthTiers {
+		start := now.Truncate(tier.Resolution)
+		buckets := hh.tiers[i]
+		if n := len(buckets); n == 0 || !buckets[n-1].Start.Equal(start) {
+			buckets = append(buckets, healthBucket{Start: start, Providers: make(map[string]healthCounts)})
+		}
+		bucket := &buckets[len(buckets)-1]
+		bucket.Samples++
+		for provider, counts := range providers {
+			sum := bucket.Providers[provider]
+			sum.add(counts)
+			bucket.Providers[provider] = sum
+		}
+		cutoff := now.Add(-tier.Retention)
+		drop := 0
+		for drop < len(buckets) && buckets[drop].Start.Before(cutoff) {
+			drop++
+		}
+		if drop > 0 {
+			buckets = append([]healthBucket(nil), buckets[drop:]...)
+		}
+		hh.tiers[i] = buckets
+	}
+}
+
+// HealthPoint is one averaged sample of a provider series.
+type HealthPoint struct {
+	Time time.Time `json:"t"`
+	healthCounts
+}
+
+// HealthSeries lists the points of one provider.
+type HealthSeries struct {
+	Provider string        `json:"provider"`
+	Points   []HealthPoint `json:"points"`
+}
+
+// query downsamples the finest tier that covers the range into buckets of the requested resolution.
+func (hh *healthHistory) query(now time.Time, rng, resolution time.Duration) (time.Duration, []HealthSeries) {
+	hh.mu.Lock()
+	defer hh.mu.Unlock()
+	// Use the finest tier that still retains the whole range; coarser requests are regrouped below.
+	tierIdx := len(healthTiers) - 1
+	for i, tier := range healthTiers {
+		if tier.Retention >= rng {
+			tierIdx = i
+			break
+		}
+	}
+	for tierIdx+1 < len(healthTiers) && healthTiers[tierIdx+1].Resolution <= resolution {
+		tierIdx++
+	}
+	if healthTiers[tierIdx].Resolution > resolution {
+		resolution = healthTiers[tierIdx].Resolution
+	}
+	from := now.Add(-rng)
+	type acc struct {
+		samples int
+		sums    map[string]healthCounts
+	}
+	grouped := make(map[time.Time]*acc)
+	for _, bucket := range hh.tiers[tierIdx] {
+		if bucket.Start.Before(from) {
+			continue
+		}
+		key := bucket.Start.Truncate(resolution)
+		entry, ok := grouped[key]
+		if !ok {
+			entry = &acc{sums: make(map[string]healthCounts)}
+			grouped[key] = entry
+		}
+		entry.samples += bucket.Samples
+		for provider, counts := range bucket.Providers {
+			sum := entry.sums[provider]
+			sum.add(counts)
+			entry.sums[provider] = sum
+		}
+	}
+	keys := make([]time.Time, 0, len(grouped))
+	for key := range grouped {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Before(keys[j]) })
+	byProvider := make(map[string]*HealthSeries)
+	for _, key := range keys {
+		entry := grouped[key]
+		if entry.samples == 0 {
+			continue
+		}
+		for provider, sum := range entry.sums {
+			series, ok := byProvider[provider]
+			if !ok {
+				series = &HealthSeries{Provider: provider}
+				byProvider[provider] = series
+			}
+			series.Points = append(series.Points, HealthPoint{Time: key, healthCounts: sum.scale(1 / float64(entry.samples))})
+		}
+	}
+	out := make([]HealthSeries, 0, len(byProvider))
+	for _, series := range byProvider {
+		out = append(out, *series)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Provider < out[j].Provider })
+	return resolution, out
+}
+
+// save writes all tiers to path atomically.
+func (hh *healthHistory) save(path string) error {
+	hh.saveMu.Lock()
+	defer hh.saveMu.Unlock()
+	hh.mu.Lock()
+	hh.lastSave = time.Now()
+	data, err := json.Marshal(hh.tiers)
+	hh.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("health history: encode: %w", err)
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("health history: create dir: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("health history: write: %w", err)
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("health history: replace: %w", err)
+	}
+	return nil
+}
+
+// flush saves the history when persistence is configured, logging failures.
+func (hh *healthHistory) flush(path string) {
+	if path == "" {
+		return
+	}
+	if err := hh.save(path); err != nil {
+		log.Warnf("failed to save health history: %v", err)
+	}
+}
+
+// load restores tiers previously written by save. A missing file is not an error.
+func (hh *healthHistory) load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("health history: read: %w", err)
+	}
+	var tiers [][]healthBucket
+	if err = json.Unmarshal(data, &tiers); err != nil {
+		return fmt.Errorf("health history: decode: %w", err)
+	}
+	hh.mu.Lock()
+	defer hh.mu.Unlock()
+	for i := range hh.tiers {
+		if i < len(tiers) {
+			hh.tiers[i] = tiers[i]
+		}
+	}
+	return nil
+}
+
+// sampleHealth captures the current per-provider account state counts.
+func (h *Handler) sampleHealth(now time.Time) map[string]healthCounts {
+	if h.authManager == nil {
+		return nil
+	}
+	snapshot := buildProvidersSnapshot(h.authManager, now)
+	out := make(map[string]healthCounts, len(snapshot.Providers))
+	for _, agg := range snapshot.Providers {
+		if agg.TotalCount == 0 {
+			continue
+		}
+		out[agg.Provider] = healthCounts{
+			Total:    float64(agg.TotalCount),
+			Active:   float64(agg.ActiveCount),
+			Cooldown: float64(agg.CooldownCount),
+			Error:    float64(agg.ErrorCount),
+			Disabled: float64(agg.DisabledCount),
+			Billing:  float64(agg.BillingCount),
+		}
+	}
+	return out
+}
+
+// SetHealthHistory applies the health history config, (re)starting or stopping the sampler.
+func (h *Handler) SetHealthHistory(cfg config.HealthHistory) {
+	if h == nil || h.history == nil {
+		return
+	}
+	hh := h.history
+	cfg.Path = strings.TrimSpace(cfg.Path)
+	hh.mu.Lock()
+	if hh.cancel != nil && hh.cfg == cfg {
+		hh.mu.Unlock()
+		return
+	}
+	previousPath := ""
+	if hh.cancel != nil {
+		hh.cancel()
+		hh.cancel = nil
+		previousPath = hh.cfg.Path
+	}
+	hh.cfg = cfg
+	if !cfg.Enabled {
+		hh.mu.Unlock()
+		hh.flush(previousPath)
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	hh.cancel = cancel
+	load := cfg.Path != "" && hh.loaded != cfg.Path
+	if load {
+		hh.loaded = cfg.Path
+	}
+	hh.mu.Unlock()
+
+	hh.flush(previousPath)
+	if load {
+		if err := hh.load(cfg.Path); err != nil {
+			log.Warnf("failed to load health history from %s: %v", cfg.Path, err)
+		}
+	}
+	interval := time.Duration(cfg.SampleIntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = defaultHealthSampleInterval
+	}
+	go h.runHealthSampler(ctx, interval, cfg.Path)
+}
+
+func (h *Handler) runHealthSampler(ctx context.Context, interval time.Duration, path string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now()
+			h.history.record(now, h.sampleHealth(now))
+			h.history.mu.Lock()
+			due := now.Sub(h.history.lastSave) >= healthHistorySaveInterval
+			h.history.mu.Unlock()
+			if due {
+				h.history.flush(path)
+			}
+		}
+	}
+}
+
+// StopHealthHistory stops the sampler and flushes history to disk when persistence is configured.
+func (h *Handler) StopHealthHistory() {
+	if h == nil || h.history == nil {
+		return
+	}
+	h.history.mu.Lock()
+	path := ""
+	if h.history.cancel != nil {
+		h.history.cancel()
+		h.history.cancel = nil
+		path = h.history.cfg.Path
+	}
+	h.history.mu.Unlock()
+	h.history.flush(path)
+}
+
+// parseHistoryDuration parses Go durations plus a "d" (day) suffix, e.g. "7d" or "90m".
+func parseHistoryDuration(raw string) (time.Duration, error) {
+	raw = strings.TrimSpace(raw)
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid duration %q", raw)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", raw)
+	}
+	return d, nil
+}
+
+// GetHealthTimeseries exports sampled pool health per provider as downsampled time series.
+func (h *Handler) GetHealthTimeseries(c *gin.Context) {
+	rng := defaultHealthHistoryRange
+	if raw := c.Query("range"); raw != "" {
+		d, err := parseHistoryDuration(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid range"})
+			return
+		}
+		rng = d
+	}
+	resolution := healthTiers[0].Resolution
+	if raw := c.Query("resolution"); raw != "" {
+		d, err := parseHistoryDuration(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid resolution"})
+			return
+		}
+		resolution = d
+	}
+	now := time.Now()
+	h.history.mu.Lock()
+	enabled := h.history.cfg.Enabled
+	h.history.mu.Unlock()
+	effective, series := h.history.query(now, rng, resolution)
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":    enabled,
+		"from":       now.Add(-rng),
+		"to":         now,
+		"resolution": effective.String(),
+		"series":     series,
+	})
+}
@@ -0,0 +1,74 @@
+package management
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHealthHistoryDownsamplesAndRetains(t *testing.T) {
+	hh := newHealthHistory()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		active := 4.0
+		if i%2 == 1 {
+			active = 2
+		}
+		hh.record(start.Add(time.Duration(i)*time.Minute), map[string]healthCounts{
+			"codex": {Total: 4, Active: active, Cooldown: 4 - active},
+		})
+	}
+	now := start.Add(10 * time.Minute)
+
+	resolution, series := hh.query(now, time.Hour, 5*time.Minute)
+	if resolution != 5*time.Minute {
+		t.Fatalf("expected 5m resolution, got %s", resolution)
+	}
+	if len(series) != 1 || len(series[0].Points) != 2 {
+		t.Fatalf("expected two 5m points for codex, got %+v", series)
+	}
+	if got := series[0].Points[0].Active; got != 3.2 {
+		t.Fatalf("expected averaged active count 3.2, got %v", got)
+	}
+
+	// Requests finer than a tier allows fall back to the finest tier covering the range.
+	if resolution, _ = hh.query(now, 7*24*time.Hour, time.Minute); resolution != 5*time.Minute {
+		t.Fatalf("expected 7d range to use the 5m tier, got %s", resolution)
+	}
+
+	hh.record(start.Add(48*time.Hour), map[string]healthCounts{"codex": {Total: 1, Active: 1}})
+	if n := len(hh.tiers[0]); n != 1 {
+		t.Fatalf("expected 1m tier to drop buckets older than 24h, kept %d", n)
+	}
+}
+
+func TestHealthHistorySaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	hh := newHealthHistory()
+	now := time.Now()
+	hh.record(now, map[string]healthCounts{"claude": {Total: 2, Active: 1, Error: 1}})
+	if err := hh.save(path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	restored := newHealthHistory()
+	if err := restored.load(path); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	_, series := restored.query(now.Add(time.Minute), time.Hour, time.Minute)
+	if len(series) != 1 || series[0].Provider != "claude" || series[0].Points[0].Error != 1 {
+		t.Fatalf("unexpected restored series %+v", series)
+	}
+}
+
+func TestParseHistoryDuration(t *testing.T) {
+	if d, err := parseHistoryDuration("7d"); err != nil || d != 7*24*time.Hour {
+		t.Fatalf("7d: got %s, %v", d, err)
+	}
+	if d, err := parseHistoryDuration("5m"); err != nil || d != 5*time.Minute {
+		t.Fatalf("5m: got %s, %v", d, err)
+	}
+	if _, err := parseHistoryDuration("-1d"); err == nil {
+		t.Fatalf("expected error for negative duration")
+	}
+}
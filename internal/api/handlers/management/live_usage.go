@@ -0,0 +1,45 @@
+package management
+
+import (
+	"sort"
+	"time"
+
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// LiveAccountUsage totals the running usage of one account's tracked streams.
+type LiveAccountUsage struct {
+	AuthID          string `json:"auth_id"`
+	Provider        string `json:"provider"`
+	Streams         int    `json:"streams"`
+	EstimatedTokens int64  `json:"estimated_output_tokens"`
+}
+
+// LiveUsageReport is the payload of "usage" events on the accounts monitor stream.
+type LiveUsageReport struct {
+	GeneratedAt time.Time                  `json:"generated_at"`
+	Streams     []coreauth.LiveStreamUsage `json:"streams"`
+	Accounts    []LiveAccountUsage         `json:"accounts"`
+}
+
+// buildLiveUsageReport groups stream usage per account, busiest account first.
+func buildLiveUsageReport(streams []coreauth.LiveStreamUsage, now time.Time) LiveUsageReport {
+	byAuth := make(map[string]*LiveAccountUsage)
+	accounts := make([]LiveAccountUsage, 0)
+	order := make([]string, 0)
+	for _, stream := range streams {
+		account := byAuth[stream.AuthID]
+		if account == nil {
+			account = &LiveAccountUsage{AuthID: stream.AuthID, Provider: stream.Provider}
+			byAuth[stream.AuthID] = account
+			order = append(order, stream.AuthID)
+		}
+		account.Streams++
+		account.EstimatedTokens += stream.EstimatedTokens
+	}
+	for _, id := range order {
+		accounts = append(accounts, *byAuth[id])
+	}
+	sort.SliceStable(accounts, func(i, j int) bool { return accounts[i].EstimatedTokens > accounts[j].EstimatedTokens })
+	return LiveUsageReport{GeneratedAt: now, Streams: streams, Accounts: accounts}
+}
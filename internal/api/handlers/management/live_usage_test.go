@@ -0,0 +1,25 @@
+package management
+
+import (
+	"testing"
+	"time"
+
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestBuildLiveUsageReportTotalsPerAccount(t *testing.T) {
+	report := buildLiveUsageReport([]coreauth.LiveStreamUsage{
+		{ID: 1, AuthID: "a", Provider: "gemini", EstimatedTokens: 10},
+		{ID: 2, AuthID: "b", Provider: "claude", EstimatedTokens: 40},
+		{ID: 3, AuthID: "a", Provider: "gemini", EstimatedTokens: 5, Done: true},
+	}, time.Now())
+	if len(report.Streams) != 3 || len(report.Accounts) != 2 {
+		t.Fatalf("report = %+v", report)
+	}
+	if got := report.Accounts[0]; got.AuthID != "b" || got.EstimatedTokens != 40 {
+		t.Fatalf("busiest account = %+v", got)
+	}
+	if got := report.Accounts[1]; got.AuthID != "a" || got.Streams != 2 || got.EstimatedTokens != 15 {
+		t.Fatalf("account a = %+v", got)
+	}
+}
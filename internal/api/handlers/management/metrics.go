@@ -0,0 +1,133 @@
+package management
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+const (
+	defaultMetricsPrefix = "cliproxy"
+	metricsContentType   = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+var metricsPrefixPattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// metricsPrefix returns the configured metric name prefix, falling back to the default when unset or
+// not a valid Prometheus name.
+func (h *Handler) metricsPrefix() string {
+	if h.cfg == nil {
+		return defaultMetricsPrefix
+	}
+	prefix := strings.TrimSuffix(strings.TrimSpace(h.cfg.MetricsPrefix), "_")
+	if prefix == "" || !metricsPrefixPattern.MatchString(prefix) {
+		return defaultMetricsPrefix
+	}
+	return prefix
+}
+
+// GetMetrics exports account monitor counts in the Prometheus text exposition format.
+func (h *Handler) GetMetrics(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	hashIDs := h.cfg != nil && h.cfg.MetricsHashAccountIDs
+	out := renderAccountMetrics(h.metricsPrefix(), h.authManager.List(), h.authManager.RateLimit, hashIDs, time.Now())
+	c.Data(http.StatusOK, metricsContentType, []byte(out))
+}
+
+// renderAccountMetrics writes per-provider account gauges, per-account backoff levels and the quota
+// headroom rateLimit last observed for each account. hashIDs labels accounts by a hash of their id.
+func renderAccountMetrics(prefix string, auths []*coreauth.Auth, rateLimit func(string) (coreauth.RateLimitObservation, bool), hashIDs bool, now time.Time) string {
+	byProvider := make(map[string]*accountCounts)
+	var accounts []*coreauth.Auth
+	for _, auth := range auths {
+		if auth == nil {
+			continue
+		}
+		counts := byProvider[auth.Provider]
+		if counts == nil {
+			counts = &accountCounts{}
+			byProvider[auth.Provider] = counts
+		}
+		counts.add(auth, now)
+		accounts = append(accounts, auth)
+	}
+	providers := make([]string, 0, len(byProvider))
+	for provider := range byProvider {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].ID < accounts[j].ID })
+
+	var b strings.Builder
+	gauges := []struct {
+		name  string
+		help  string
+		value func(*accountCounts) int
+	}{
+		{"accounts_total", "Registered accounts.", func(c *accountCounts) int { return c.total }},
+		{"accounts_active", "Accounts available for selection.", func(c *accountCounts) int { return c.active }},
+		{"accounts_cooldown", "Accounts cooling down after quota or rate limits.", func(c *accountCounts) int { return c.cooldown }},
+		{"accounts_error", "Accounts unavailable because of errors.", func(c *accountCounts) int { return c.errored }},
+	}
+	for _, g := range gauges {
+		name := prefix + "_" + g.name
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", name, g.help, name)
+		for _, provider := range providers {
+			fmt.Fprintf(&b, "%s{provider=\"%s\"} %d\n", name, escapeLabelValue(provider), g.value(byProvider[provider]))
+		}
+	}
+	name := prefix + "_account_backoff_level"
+	fmt.Fprintf(&b, "# HELP %s Quota backoff level of the account.\n# TYPE %s gauge\n", name, name)
+	for _, auth := range accounts {
+		fmt.Fprintf(&b, "%s{id=\"%s\",provider=\"%s\"} %d\n", name, accountLabel(auth.ID, hashIDs), escapeLabelValue(auth.Provider), auth.Quota.BackoffLevel)
+	}
+	name = prefix + "_account_quota_remaining"
+	fmt.Fprintf(&b, "# HELP %s Requests remaining in the account's current rate-limit window, as reported by the provider.\n# TYPE %s gauge\n", name, name)
+	for _, auth := range accounts {
+		remaining, ok := quotaHeadroom(rateLimit, auth.ID, now)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&b, "%s{id=\"%s\",provider=\"%s\"} %d\n", name, accountLabel(auth.ID, hashIDs), escapeLabelValue(auth.Provider), remaining)
+	}
+	return b.String()
+}
+
+// quotaHeadroom returns the remaining requests last reported for the account. A count whose
+// announced reset has passed is stale and treated as unknown.
+func quotaHeadroom(rateLimit func(string) (coreauth.RateLimitObservation, bool), id string, now time.Time) (int64, bool) {
+	if rateLimit == nil {
+		return 0, false
+	}
+	obs, ok := rateLimit(id)
+	if !ok || (obs.ResetAt != nil && !obs.ResetAt.After(now)) {
+		return 0, false
+	}
+	return obs.Remaining, true
+}
+
+// accountLabel returns the escaped account id, or the first 12 hex digits of its SHA-256 when hashed.
+func accountLabel(id string, hashed bool) string {
+	if !hashed {
+		return escapeLabelValue(id)
+	}
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(v string) string {
+	return labelValueEscaper.Replace(v)
+}
@@ -0,0 +1,78 @@
+package management
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestRenderAccountMetrics(t *testing.T) {
+	now := time.Now()
+	auths := []*coreauth.Auth{
+		{ID: "a.json", Provider: "gemini", Status: coreauth.StatusActive},
+		{ID: "b.json", Provider: "gemini", Quota: coreauth.QuotaState{Exceeded: true, BackoffLevel: 3}},
+		{ID: `c"1.json`, Provider: "claude", Unavailable: true, Status: coreauth.StatusError},
+	}
+	out := renderAccountMetrics("proxy", auths, nil, false, now)
+	for _, line := range []string{
+		"# TYPE proxy_accounts_total gauge",
+		`proxy_accounts_total{provider="gemini"} 2`,
+		`proxy_accounts_active{provider="gemini"} 1`,
+		`proxy_accounts_cooldown{provider="gemini"} 1`,
+		`proxy_accounts_error{provider="claude"} 1`,
+		`proxy_account_backoff_level{id="b.json",provider="gemini"} 3`,
+		`proxy_account_backoff_level{id="c\"1.json",provider="claude"} 0`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Fatalf("missing %q in:\n%s", line, out)
+		}
+	}
+}
+
+func TestRenderAccountQuotaRemaining(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+	auths := []*coreauth.Auth{
+		{ID: "known.json", Provider: "claude"},
+		{ID: "stale.json", Provider: "claude"},
+		{ID: "unknown.json", Provider: "gemini"},
+	}
+	observed := map[string]coreauth.RateLimitObservation{
+		"known.json": {Remaining: 42, ResetAt: &future},
+		"stale.json": {Remaining: 1, ResetAt: &past},
+	}
+	rateLimit := func(id string) (coreauth.RateLimitObservation, bool) {
+		obs, ok := observed[id]
+		return obs, ok
+	}
+
+	out := renderAccountMetrics("cliproxy", auths, rateLimit, false, now)
+	if !strings.Contains(out, `cliproxy_account_quota_remaining{id="known.json",provider="claude"} 42`+"\n") {
+		t.Fatalf("missing headroom gauge in:\n%s", out)
+	}
+	for _, id := range []string{"stale.json", "unknown.json"} {
+		if strings.Contains(out, `cliproxy_account_quota_remaining{id="`+id) {
+			t.Fatalf("headroom reported for %s:\n%s", id, out)
+		}
+	}
+
+	hashed := renderAccountMetrics("cliproxy", auths, rateLimit, true, now)
+	if strings.Contains(hashed, "known.json") {
+		t.Fatalf("account id disclosed with hashing on:\n%s", hashed)
+	}
+	if !strings.Contains(hashed, `cliproxy_account_quota_remaining{id="`+accountLabel("known.json", true)+`",provider="claude"} 42`) {
+		t.Fatalf("missing hashed headroom gauge in:\n%s", hashed)
+	}
+}
+
+func TestMetricsPrefixFallsBackOnInvalidNames(t *testing.T) {
+	for prefix, want := range map[string]string{"": "cliproxy", "team_proxy_": "team_proxy", "bad-name": "cliproxy", "9lives": "cliproxy"} {
+		h := &Handler{cfg: &config.Config{MetricsPrefix: prefix}}
+		if got := h.metricsPrefix(); got != want {
+			t.Fatalf("prefix %q: got %q, want %q", prefix, got, want)
+		}
+	}
+}
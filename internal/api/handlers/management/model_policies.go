@@ -0,0 +1,96 @@
+package management
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// clientModelPolicyView is the resolved model policy of one client key, reported with a masked key.
+type clientModelPolicyView struct {
+	APIKey       string   `json:"api-key"`
+	Models       []string `json:"models"`
+	DefaultModel string   `json:"default-model,omitempty"`
+}
+
+// GetModelPolicies returns the configured client model policies and the policy resolved for every
+// client key.
+func (h *Handler) GetModelPolicies(c *gin.Context) {
+	policies := h.cfg.ClientModelPolicies
+	if policies == nil {
+		policies = []sdkconfig.ClientModelPolicy{}
+	}
+	resolved := h.cfg.ResolveClientModelPolicies()
+	keys := make([]string, 0, len(resolved))
+	for key := range resolved {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	views := make([]clientModelPolicyView, 0, len(keys))
+	for _, key := range keys {
+		policy := resolved[key]
+		models := policy.Models
+		if len(models) == 0 {
+			models = []string{"*"}
+		}
+		views = append(views, clientModelPolicyView{APIKey: util.HideAPIKey(key), Models: models, DefaultModel: policy.DefaultModel})
+	}
+	c.JSON(http.StatusOK, gin.H{"client-model-policies": policies, "effective": views})
+}
+
+// PutModelPolicies replaces the client model policies. The body is the list of policies, bare or
+// wrapped in {"items": [...]}.
+func (h *Handler) PutModelPolicies(c *gin.Context) {
+	data, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read body"})
+		return
+	}
+	var policies []sdkconfig.ClientModelPolicy
+	if err = json.Unmarshal(data, &policies); err != nil {
+		var wrapper struct {
+			Items []sdkconfig.ClientModelPolicy `json:"items"`
+		}
+		if err2 := json.Unmarshal(data, &wrapper); err2 != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+		policies = wrapper.Items
+	}
+	for i := range policies {
+		if err = normalizeClientModelPolicy(&policies[i]); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("policy %d: %v", i, err)})
+			return
+		}
+	}
+	h.cfg.ClientModelPolicies = policies
+	h.persist(c)
+}
+
+// normalizeClientModelPolicy trims the policy's entries and checks its default model is allowed.
+func normalizeClientModelPolicy(policy *sdkconfig.ClientModelPolicy) error {
+	keys := make([]string, 0, len(policy.APIKeys))
+	for _, key := range policy.APIKeys {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	models := make([]string, 0, len(policy.Models))
+	for _, model := range policy.Models {
+		if model = strings.TrimSpace(model); model != "" {
+			models = append(models, model)
+		}
+	}
+	policy.APIKeys, policy.Models = keys, models
+	policy.DefaultModel = strings.TrimSpace(policy.DefaultModel)
+	if policy.DefaultModel != "" && !policy.Allows(policy.DefaultModel) {
+		return fmt.Errorf("default-model %s is not in models", policy.DefaultModel)
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetModelRoutes lists the effective model routing table in precedence order. With ?model=<name>
+// it also reports the route applied to that model, or null when default routing applies.
+func (h *Handler) GetModelRoutes(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	body := gin.H{"routes": h.authManager.ModelRoutes()}
+	if model := strings.TrimSpace(c.Query("model")); model != "" {
+		body["model"] = model
+		body["route"] = nil
+		if route, ok := h.authManager.RouteForModel(model); ok {
+			body["route"] = route
+		}
+	}
+	c.JSON(http.StatusOK, body)
+}
@@ -0,0 +1,45 @@
+package management
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/tidwall/gjson"
+)
+
+func TestGetModelRoutesResolvesModel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.SetModelRoutes([]coreauth.ModelRoute{
+		{Model: "claude-*", Provider: "claude", Fallback: true},
+		{Model: "claude-opus-4", Provider: "vertex"},
+	})
+	h := &Handler{authManager: manager}
+	router := gin.New()
+	router.GET("/model-routes", h.GetModelRoutes)
+	get := func(path string) gjson.Result {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d", path, rec.Code)
+		}
+		return gjson.ParseBytes(rec.Body.Bytes())
+	}
+
+	body := get("/model-routes")
+	if got := body.Get("routes.#.model").String(); got != `["claude-opus-4","claude-*"]` {
+		t.Fatalf("routes = %s", body.Get("routes").Raw)
+	}
+	if body.Get("route").Exists() {
+		t.Fatalf("route reported without a model: %s", body.Raw)
+	}
+	if body = get("/model-routes?model=claude-sonnet-4"); body.Get("route.provider").String() != "claude" || !body.Get("route.fallback").Bool() {
+		t.Fatalf("claude-sonnet-4: %s", body.Raw)
+	}
+	if body = get("/model-routes?model=gemini-pro"); body.Get("route").Type != gjson.Null || !body.Get("route").Exists() {
+		t.Fatalf("gemini-pro: %s", body.Raw)
+	}
+}
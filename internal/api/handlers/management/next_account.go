@@ -0,0 +1,36 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// nextAccountResponse pairs the dry-run result with the monitor view of the selected account.
+type nextAccountResponse struct {
+	coreauth.NextAccountPreview
+	Selected *AccountStatus `json:"selected"`
+}
+
+// GetNextAccount reports which account the next request for the provider and optional model would
+// use, and why the other accounts were skipped, without dispatching a request or touching counters.
+func (h *Handler) GetNextAccount(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	provider := strings.TrimSpace(c.Query("provider"))
+	if provider == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "provider is required"})
+		return
+	}
+	preview := h.authManager.PreviewNextAccount(c.Request.Context(), provider, c.Query("model"))
+	resp := nextAccountResponse{NextAccountPreview: preview}
+	if preview.Selected != nil {
+		status := h.accountStatus(preview.Selected)
+		resp.Selected = &status
+	}
+	c.JSON(http.StatusOK, resp)
+}
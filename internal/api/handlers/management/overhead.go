@@ -0,0 +1,50 @@
+package management
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OverheadResponse reports the proxy's own processing time next to upstream time, in milliseconds.
+type OverheadResponse struct {
+	Requests      int     `json:"requests"`
+	OverheadP50Ms float64 `json:"overhead_p50_ms"`
+	OverheadP95Ms float64 `json:"overhead_p95_ms"`
+	OverheadP99Ms float64 `json:"overhead_p99_ms"`
+	UpstreamP50Ms float64 `json:"upstream_p50_ms"`
+	UpstreamP95Ms float64 `json:"upstream_p95_ms"`
+	UpstreamP99Ms float64 `json:"upstream_p99_ms"`
+	// Wait* report time queued for a concurrency slot or a batch, kept out of the overhead.
+	WaitP50Ms float64 `json:"wait_p50_ms"`
+	WaitP95Ms float64 `json:"wait_p95_ms"`
+	WaitP99Ms float64 `json:"wait_p99_ms"`
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// GetOverhead returns percentiles of the non-upstream portion of recent requests: selection,
+// translation and response processing. Time queued for a concurrency slot or a batch is reported
+// separately.
+func (h *Handler) GetOverhead(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	stats := h.authManager.Overhead()
+	c.JSON(http.StatusOK, OverheadResponse{
+		Requests:      stats.Requests,
+		OverheadP50Ms: durationMs(stats.OverheadP50),
+		OverheadP95Ms: durationMs(stats.OverheadP95),
+		OverheadP99Ms: durationMs(stats.OverheadP99),
+		UpstreamP50Ms: durationMs(stats.UpstreamP50),
+		UpstreamP95Ms: durationMs(stats.UpstreamP95),
+		UpstreamP99Ms: durationMs(stats.UpstreamP99),
+		WaitP50Ms:     durationMs(stats.WaitP50),
+		WaitP95Ms:     durationMs(stats.WaitP95),
+		WaitP99Ms:     durationMs(stats.WaitP99),
+	})
+}
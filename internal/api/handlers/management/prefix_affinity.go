@@ -0,0 +1,22 @@
+package management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPrefixAffinity reports the affinity mode and how often prompt-prefix affinity reached each
+// prefix's preferred account.
+func (h *Handler) GetPrefixAffinity(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	enabled := h.cfg != nil && h.cfg.PrefixAffinity.Enabled
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":         enabled,
+		"mode":            h.authManager.PrefixAffinityMode(),
+		"prefix_affinity": h.authManager.PrefixAffinityStats(),
+	})
+}
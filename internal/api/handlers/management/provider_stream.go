@@ -0,0 +1,136 @@
+package management
+
+import (
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+const (
+	defaultProviderStreamInterval = 5 * time.Second
+	minProviderStreamInterval     = time.Second
+)
+
+// ProviderAggregate summarises account states and recent traffic for a single provider.
+type ProviderAggregate struct {
+	Provider      string  `json:"provider"`
+	TotalCount    int     `json:"total_count"`
+	ActiveCount   int     `json:"active_count"`
+	CooldownCount int     `json:"cooldown_count"`
+	ErrorCount    int     `json:"error_count"`
+	DisabledCount int     `json:"disabled_count"`
+	BillingCount  int     `json:"billing_suspended_count"`
+	EgressCount   int     `json:"egress_blocked_count"`
+	RPM           int     `json:"rpm"`
+	ErrorRate     float64 `json:"error_rate"`
+	LatencyP50Ms  int64   `json:"latency_p50_ms"`
+	LatencyP95Ms  int64   `json:"latency_p95_ms"`
+}
+
+// ProvidersSnapshot is a single emission of the provider aggregate stream.
+type ProvidersSnapshot struct {
+	Timestamp time.Time           `json:"timestamp"`
+	Providers []ProviderAggregate `json:"providers"`
+}
+
+// buildProvidersSnapshot combines account monitor states with the manager's recent result statistics.
+func buildProvidersSnapshot(manager *coreauth.Manager, now time.Time) ProvidersSnapshot {
+	byProvider := make(map[string]*ProviderAggregate)
+	get := func(provider string) *ProviderAggregate {
+		agg, ok := byProvider[provider]
+		if !ok {
+			agg = &ProviderAggregate{Provider: provider}
+			byProvider[provider] = agg
+		}
+		return agg
+	}
+	for _, auth := range manager.List() {
+		if auth == nil {
+			continue
+		}
+		agg := get(auth.Provider)
+		agg.TotalCount++
+		switch accountMonitorState(auth, now) {
+		case monitorStateActive:
+			agg.ActiveCount++
+		case monitorStateCooldown:
+			agg.CooldownCount++
+		case monitorStateError:
+			agg.ErrorCount++
+		case monitorStateDisabled:
+			agg.DisabledCount++
+		case monitorStateBilling:
+			agg.BillingCount++
+		case monitorStateEgress:
+			agg.EgressCount++
+		}
+	}
+	for provider, stats := range manager.ProviderStats() {
+		agg := get(provider)
+		agg.RPM = stats.RequestsPerMinute
+		agg.ErrorRate = stats.ErrorRate
+		agg.LatencyP50Ms = stats.LatencyP50.Milliseconds()
+		agg.LatencyP95Ms = stats.LatencyP95.Milliseconds()
+	}
+
+	snapshot := ProvidersSnapshot{Timestamp: now, Providers: make([]ProviderAggregate, 0, len(byProvider))}
+	for _, agg := range byProvider {
+		snapshot.Providers = append(snapshot.Providers, *agg)
+	}
+	sort.Slice(snapshot.Providers, func(i, j int) bool {
+		return snapshot.Providers[i].Provider < snapshot.Providers[j].Provider
+	})
+	return snapshot
+}
+
+// providerStreamInterval resolves the emission interval from the query string or configuration.
+func (h *Handler) providerStreamInterval(c *gin.Context) time.Duration {
+	interval := defaultProviderStreamInterval
+	if h.cfg != nil && h.cfg.ProviderStreamInterval > 0 {
+		interval = time.Duration(h.cfg.ProviderStreamInterval) * time.Second
+	}
+	if raw := c.Query("interval"); raw != "" {
+		if seconds, err := strconv.Atoi(raw); err == nil && seconds > 0 {
+			interval = time.Duration(seconds) * time.Second
+		}
+	}
+	if interval < minProviderStreamInterval {
+		interval = minProviderStreamInterval
+	}
+	return interval
+}
+
+// StreamProviders emits periodic per-provider aggregate snapshots as server-sent events.
+func (h *Handler) StreamProviders(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	interval := h.providerStreamInterval(c)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	ctx := c.Request.Context()
+
+	c.SSEvent("providers", buildProvidersSnapshot(h.authManager, time.Now()))
+	c.Writer.Flush()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case now := <-ticker.C:
+			c.SSEvent("providers", buildProvidersSnapshot(h.authManager, now))
+			return true
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package management
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestBuildProvidersSnapshot(t *testing.T) {
+	manager := coreauth.NewManager(nil, nil, nil)
+	now := time.Now()
+	for _, auth := range []*coreauth.Auth{
+		{ID: "snap-active", Provider: "gemini", Status: coreauth.StatusActive},
+		{ID: "snap-cooling", Provider: "gemini", Status: coreauth.StatusActive, Unavailable: true, Quota: coreauth.QuotaState{Exceeded: true, NextRecoverAt: now.Add(time.Minute)}},
+		{ID: "snap-disabled", Provider: "claude", Status: coreauth.StatusDisabled, Disabled: true},
+	} {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	manager.MarkResult(context.Background(), coreauth.Result{AuthID: "snap-active", Provider: "gemini", Success: false, Latency: 10 * time.Millisecond})
+	manager.MarkResult(context.Background(), coreauth.Result{AuthID: "snap-active", Provider: "gemini", Success: true, Latency: 30 * time.Millisecond})
+
+	snapshot := buildProvidersSnapshot(manager, now)
+	if len(snapshot.Providers) != 2 || snapshot.Providers[0].Provider != "claude" {
+		t.Fatalf("providers = %+v, want claude then gemini", snapshot.Providers)
+	}
+	if claude := snapshot.Providers[0]; claude.TotalCount != 1 || claude.DisabledCount != 1 || claude.RPM != 0 {
+		t.Fatalf("claude = %+v", claude)
+	}
+	gemini := snapshot.Providers[1]
+	if gemini.TotalCount != 2 || gemini.ActiveCount != 1 || gemini.CooldownCount != 1 {
+		t.Fatalf("gemini states = %+v", gemini)
+	}
+	if gemini.RPM != 2 || gemini.ErrorRate != 0.5 || gemini.LatencyP95Ms != 30 {
+		t.Fatalf("gemini traffic = %+v", gemini)
+	}
+}
@@ -0,0 +1,27 @@
+package management
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// Readiness answers load balancer readiness probes: 200 while at least one account is in the active
+// monitor state, 503 otherwise. It only counts accounts, so it stays cheap enough to poll.
+func (h *Handler) Readiness(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not_ready", "active": 0, "total": 0})
+		return
+	}
+	now := time.Now()
+	active, total := h.authManager.CountAuths(func(auth *coreauth.Auth) bool {
+		return accountMonitorState(auth, now) == monitorStateActive
+	})
+	if active == 0 {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not_ready", "active": active, "total": total})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready", "active": active, "total": total})
+}
@@ -0,0 +1,49 @@
+package management
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/tidwall/gjson"
+)
+
+func TestReadinessRequiresAnActiveAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := coreauth.NewManager(nil, nil, nil)
+	h := &Handler{authManager: manager}
+	probe := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+		h.Readiness(c)
+		return rec
+	}
+
+	if rec := probe(); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("empty pool: status %d", rec.Code)
+	}
+	for _, auth := range []*coreauth.Auth{
+		{ID: "disabled.json", Provider: "gemini", Disabled: true, Status: coreauth.StatusDisabled},
+		{ID: "broken.json", Provider: "claude", Unavailable: true, Status: coreauth.StatusError},
+	} {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	rec := probe()
+	if body := gjson.ParseBytes(rec.Body.Bytes()); rec.Code != http.StatusServiceUnavailable || body.Get("active").Int() != 0 || body.Get("total").Int() != 2 {
+		t.Fatalf("no active account: status %d body %s", rec.Code, rec.Body.String())
+	}
+
+	if _, err := manager.Register(context.Background(), &coreauth.Auth{ID: "ok.json", Provider: "gemini", Status: coreauth.StatusActive}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	rec = probe()
+	if body := gjson.ParseBytes(rec.Body.Bytes()); rec.Code != http.StatusOK || body.Get("status").String() != "ready" || body.Get("active").Int() != 1 || body.Get("total").Int() != 3 {
+		t.Fatalf("active account: status %d body %s", rec.Code, rec.Body.String())
+	}
+}
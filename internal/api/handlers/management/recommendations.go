@@ -0,0 +1,132 @@
+package management
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recommendationWindow is the span saturation, peak demand and availability are read over; it
+// matches the auth manager's capacity window.
+const recommendationWindow = 24 * time.Hour
+
+// RecommendationInputs are the observations a sizing recommendation is derived from.
+type RecommendationInputs struct {
+	// Accounts counts enabled accounts; ActiveNow those currently able to serve.
+	Accounts  int `json:"accounts"`
+	ActiveNow int `json:"active_now"`
+	// Availability is the average share of enabled accounts that were active over the window, from
+	// the health history when it is enabled, otherwise the current share.
+	Availability       float64    `json:"availability"`
+	AvailabilitySource string     `json:"availability_source"`
+	PeakDemandRPM      int        `json:"peak_demand_rpm"`
+	PeakServedRPM      int        `json:"peak_served_rpm"`
+	PeakAt             *time.Time `json:"peak_at,omitempty"`
+	SaturationEvents   int        `json:"saturation_events"`
+	SaturatedMinutes   int        `json:"saturated_minutes"`
+}
+
+// ProviderRecommendation is the sizing guidance for one provider. PerAccountRPM is the throughput one
+// available account delivered at peak; RequiredAccounts is the pool size that covers peak demand at
+// the observed availability.
+type ProviderRecommendation struct {
+	Provider         string               `json:"provider"`
+	AddAccounts      int                  `json:"add_accounts"`
+	RequiredAccounts int                  `json:"required_accounts,omitempty"`
+	PerAccountRPM    float64              `json:"per_account_rpm,omitempty"`
+	Recommendation   string               `json:"recommendation"`
+	Inputs           RecommendationInputs `json:"inputs"`
+}
+
+// GetRecommendations turns recent saturation, peak demand and account availability into a per-provider
+// count of accounts to add. Providers that served every request at peak get no addition.
+func (h *Handler) GetRecommendations(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	now := time.Now()
+	inputs := make(map[string]*RecommendationInputs)
+	get := func(provider string) *RecommendationInputs {
+		in, ok := inputs[provider]
+		if !ok {
+			in = &RecommendationInputs{}
+			inputs[provider] = in
+		}
+		return in
+	}
+	for _, agg := range buildProvidersSnapshot(h.authManager, now).Providers {
+		if agg.TotalCount == 0 {
+			continue
+		}
+		in := get(agg.Provider)
+		in.Accounts = agg.TotalCount - agg.DisabledCount
+		in.ActiveNow = agg.ActiveCount
+		if in.Accounts > 0 {
+			in.Availability = float64(agg.ActiveCount) / float64(in.Accounts)
+		}
+		in.AvailabilitySource = "current"
+	}
+	if h.history != nil {
+		_, series := h.history.query(now, recommendationWindow, recommendationWindow)
+		for _, s := range series {
+			in, ok := inputs[s.Provider]
+			if !ok {
+				continue
+			}
+			var active, enabled float64
+			for _, point := range s.Points {
+				active += point.Active
+				enabled += point.Total - point.Disabled
+			}
+			if enabled > 0 {
+				in.Availability = active / enabled
+				in.AvailabilitySource = "health_history"
+			}
+		}
+	}
+	for provider, capacity := range h.authManager.ProviderCapacity() {
+		in := get(provider)
+		in.PeakDemandRPM = capacity.PeakDemandRPM
+		in.PeakServedRPM = capacity.PeakServedRPM
+		in.PeakAt = capacity.PeakAt
+		in.SaturationEvents = capacity.SaturationEvents
+		in.SaturatedMinutes = capacity.SaturatedMinutes
+	}
+
+	out := make([]ProviderRecommendation, 0, len(inputs))
+	for provider, in := range inputs {
+		out = append(out, recommendAccounts(provider, *in))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Provider < out[j].Provider })
+	c.JSON(http.StatusOK, gin.H{"window": recommendationWindow.String(), "recommendations": out})
+}
+
+// recommendAccounts sizes a provider's pool: one available account is assumed to serve what the
+// available accounts served per head at peak, and the pool must hold enough accounts that, at the
+// observed availability, peak demand including rejected requests is covered.
+func recommendAccounts(provider string, in RecommendationInputs) ProviderRecommendation {
+	rec := ProviderRecommendation{Provider: provider, Inputs: in}
+	switch {
+	case in.SaturationEvents == 0:
+		rec.Recommendation = "no saturation observed; current pool covers peak demand"
+		return rec
+	case in.PeakServedRPM == 0 || in.Availability <= 0 || in.Accounts == 0:
+		rec.Recommendation = "requests were rejected but no account served any at peak; restore the existing accounts before sizing"
+		return rec
+	}
+	available := in.Availability * float64(in.Accounts)
+	rec.PerAccountRPM = float64(in.PeakServedRPM) / math.Max(available, 1)
+	rec.RequiredAccounts = int(math.Ceil(float64(in.PeakDemandRPM) / rec.PerAccountRPM / in.Availability))
+	rec.AddAccounts = max(rec.RequiredAccounts-in.Accounts, 0)
+	if rec.AddAccounts == 0 {
+		rec.Recommendation = fmt.Sprintf("saturated %d minute(s) but capacity covers the %d rpm peak; check cooldowns rather than pool size", in.SaturatedMinutes, in.PeakDemandRPM)
+		return rec
+	}
+	rec.Recommendation = fmt.Sprintf("add %d more %s account(s) to handle the %d rpm peak", rec.AddAccounts, provider, in.PeakDemandRPM)
+	return rec
+}
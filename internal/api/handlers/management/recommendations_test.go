@@ -0,0 +1,21 @@
+package management
+
+import "testing"
+
+func TestRecommendAccounts(t *testing.T) {
+	// Half of four accounts were available and served 60 rpm while 120 rpm arrived.
+	rec := recommendAccounts("gemini", RecommendationInputs{Accounts: 4, Availability: 0.5, PeakDemandRPM: 120, PeakServedRPM: 60, SaturationEvents: 60, SaturatedMinutes: 1})
+	if rec.PerAccountRPM != 30 || rec.RequiredAccounts != 8 || rec.AddAccounts != 4 {
+		t.Fatalf("unexpected sizing %+v", rec)
+	}
+	if rec.Recommendation != "add 4 more gemini account(s) to handle the 120 rpm peak" {
+		t.Fatalf("unexpected recommendation %q", rec.Recommendation)
+	}
+
+	if rec = recommendAccounts("claude", RecommendationInputs{Accounts: 2, Availability: 1, PeakDemandRPM: 50, PeakServedRPM: 50}); rec.AddAccounts != 0 {
+		t.Fatalf("no saturation should not recommend additions: %+v", rec)
+	}
+	if rec = recommendAccounts("codex", RecommendationInputs{Accounts: 2, PeakDemandRPM: 10, SaturationEvents: 10}); rec.AddAccounts != 0 || rec.RequiredAccounts != 0 {
+		t.Fatalf("a fully unavailable pool cannot be sized: %+v", rec)
+	}
+}
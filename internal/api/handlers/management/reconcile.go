@@ -0,0 +1,26 @@
+package management
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Reconcile runs the account reaper on demand and returns the actions it took, or would take with dry_run=true.
+func (h *Handler) Reconcile(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	dryRun := false
+	if raw := c.Query("dry_run"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run value"})
+			return
+		}
+		dryRun = parsed
+	}
+	c.JSON(http.StatusOK, h.authManager.Reconcile(c.Request.Context(), dryRun))
+}
@@ -0,0 +1,20 @@
+package management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRefreshConcurrency reports the per-provider token refresh limits and how many refreshes are
+// running or waiting for a slot right now.
+func (h *Handler) GetRefreshConcurrency(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"default-limit": h.authManager.DefaultRefreshConcurrency(),
+		"providers":     h.authManager.RefreshConcurrency(),
+	})
+}
@@ -0,0 +1,68 @@
+package management
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+type reservationRequest struct {
+	Name       string   `json:"name"`
+	Accounts   []string `json:"accounts"`
+	TTLSeconds int      `json:"ttl_seconds"`
+}
+
+// CreateReservation sets accounts aside for requests sending the matching X-Reservation header.
+func (h *Handler) CreateReservation(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	var body reservationRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	reservation, err := h.authManager.Reserve(body.Name, body.Accounts, time.Duration(body.TTLSeconds)*time.Second)
+	if err != nil {
+		status := http.StatusBadRequest
+		if authErr, ok := err.(*coreauth.Error); ok && (authErr.Code == "reservation_exists" || authErr.Code == "account_reserved") {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, reservation)
+}
+
+// ListReservations returns active reservations with their accounts, expiry and served request counts.
+func (h *Handler) ListReservations(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"reservations": h.authManager.Reservations()})
+}
+
+// DeleteReservation releases a reservation early, returning its accounts to the general pool.
+func (h *Handler) DeleteReservation(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	name := strings.TrimSpace(c.Param("name"))
+	reservation, ok := h.authManager.ReleaseReservation(name)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "reservation not found"})
+		return
+	}
+	resp := gin.H{"released": reservation.Name, "accounts": reservation.Accounts, "warnings": reservation.InFlight}
+	if reservation.InFlight > 0 {
+		resp["warning"] = fmt.Sprintf("%d reserved request(s) still in flight; they finish on their current accounts", reservation.InFlight)
+	}
+	c.JSON(http.StatusOK, resp)
+}
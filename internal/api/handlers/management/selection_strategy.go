@@ -0,0 +1,40 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// GetSelectionStrategy reports the active global selection strategy and the supported ones.
+func (h *Handler) GetSelectionStrategy(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"selection-strategy": h.authManager.GlobalStrategy(), "supported": coreauth.SupportedStrategies()})
+}
+
+// PutSelectionStrategy switches the global selection strategy at runtime and persists it so config
+// reloads keep it. The body is {"strategy": "..."}.
+func (h *Handler) PutSelectionStrategy(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	var body struct {
+		Strategy string `json:"strategy"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || strings.TrimSpace(body.Strategy) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "strategy is required"})
+		return
+	}
+	if err := h.authManager.SetSelectionStrategy(body.Strategy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.cfg.SelectionStrategy = h.authManager.GlobalStrategy()
+	h.persist(c)
+}
@@ -0,0 +1,17 @@
+package management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSelectorExclusions returns aggregate counts of accounts currently excluded from selection,
+// grouped by reason. Optional provider and model query parameters narrow the view.
+func (h *Handler) GetSelectorExclusions(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	c.JSON(http.StatusOK, h.authManager.SelectorExclusions(c.Query("provider"), c.Query("model")))
+}
@@ -0,0 +1,30 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PreviewStrategy replays recent selections with a proposed strategy and returns the projected
+// per-account load distribution without switching the active selector.
+func (h *Handler) PreviewStrategy(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	var body struct {
+		Strategy string `json:"strategy"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || strings.TrimSpace(body.Strategy) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "strategy is required"})
+		return
+	}
+	preview, err := h.authManager.StrategyPreview(body.Strategy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
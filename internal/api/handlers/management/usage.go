@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/api/middleware"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
 )
 
@@ -13,8 +14,13 @@ func (h *Handler) GetUsageStatistics(c *gin.Context) {
 	if h != nil && h.usageStats != nil {
 		snapshot = h.usageStats.Snapshot()
 	}
+	var globalRate middleware.GlobalRateLimitStatus
+	if h != nil {
+		globalRate = h.globalRateLimit.Status()
+	}
 	c.JSON(http.StatusOK, gin.H{
-		"usage":           snapshot,
-		"failed_requests": snapshot.FailureCount,
+		"usage":             snapshot,
+		"failed_requests":   snapshot.FailureCount,
+		"global_rate_limit": globalRate,
 	})
 }
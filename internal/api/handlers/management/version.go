@@ -0,0 +1,51 @@
+package management
+
+import (
+	"net/http"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/buildinfo"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// VersionInfo identifies the running build for support reports.
+type VersionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	// Providers lists the providers with a registered executor.
+	Providers []string `json:"providers"`
+	// FeatureFlags reports whether each known feature applies by default, i.e. is not gated.
+	FeatureFlags map[string]bool `json:"feature_flags"`
+}
+
+// GetVersion returns the build metadata baked in via ldflags along with the enabled providers and
+// feature flags, so deployments can be told apart when diagnosing behavior differences.
+func (h *Handler) GetVersion(c *gin.Context) {
+	info := VersionInfo{
+		Version:      buildinfo.Version,
+		Commit:       buildinfo.Commit,
+		BuildDate:    buildinfo.BuildDate,
+		GoVersion:    runtime.Version(),
+		OS:           runtime.GOOS,
+		Arch:         runtime.GOARCH,
+		Providers:    []string{},
+		FeatureFlags: make(map[string]bool, len(coreauth.KnownFeatureFlags)),
+	}
+	gated := make(map[string]struct{})
+	if h.authManager != nil {
+		info.Providers = h.authManager.Providers()
+		for _, feature := range h.authManager.GatedFeatures() {
+			gated[feature] = struct{}{}
+		}
+	}
+	for _, feature := range coreauth.KnownFeatureFlags {
+		_, off := gated[feature]
+		info.FeatureFlags[feature] = !off
+	}
+	c.JSON(http.StatusOK, info)
+}
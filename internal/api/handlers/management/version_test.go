@@ -0,0 +1,41 @@
+package management
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/buildinfo"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/runtime/executor"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestGetVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor.NewGeminiExecutor(nil))
+	manager.RegisterExecutor(executor.NewClaudeExecutor(nil))
+	manager.SetGatedFeatures([]string{coreauth.FeaturePrefixAffinity})
+	h := &Handler{authManager: manager}
+	router := gin.New()
+	router.GET("/version", h.GetVersion)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+	var info VersionInfo
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &info) != nil {
+		t.Fatalf("status %d body %s", rec.Code, rec.Body.String())
+	}
+	if info.Version != buildinfo.Version || info.Commit != buildinfo.Commit || info.BuildDate != buildinfo.BuildDate || info.GoVersion != runtime.Version() {
+		t.Fatalf("unexpected build info: %+v", info)
+	}
+	if len(info.Providers) != 2 || info.Providers[0] != "claude" || info.Providers[1] != "gemini" {
+		t.Fatalf("providers = %v, want [claude gemini]", info.Providers)
+	}
+	if !info.FeatureFlags[coreauth.FeatureEagerRefresh] || info.FeatureFlags[coreauth.FeaturePrefixAffinity] {
+		t.Fatalf("feature flags = %v, want prefix-affinity gated", info.FeatureFlags)
+	}
+}
@@ -0,0 +1,60 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// GetWeights lists the weighted-random selection weight of every account.
+func (h *Handler) GetWeights(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"weights": h.authManager.Weights()})
+}
+
+// PutWeights applies a list of {"id", "weight"} entries in one step. Unknown ids or weights below 1
+// reject the whole list. The response lists the resulting weights of every account.
+func (h *Handler) PutWeights(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	var body []coreauth.AccountWeight
+	if err := c.ShouldBindJSON(&body); err != nil || len(body) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a non-empty list of {id, weight} is required"})
+		return
+	}
+	for i := range body {
+		body[i].ID = strings.TrimSpace(body[i].ID)
+	}
+	weights, err := h.authManager.SetWeights(c.Request.Context(), body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"weights": weights})
+}
+
+// ResetWeights gives every account the same weight. The optional JSON body {"weight": n} sets it;
+// without one every weight returns to the default of 1.
+func (h *Handler) ResetWeights(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth manager not available"})
+		return
+	}
+	var body struct {
+		Weight int `json:"weight"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"weights": h.authManager.ResetWeights(c.Request.Context(), body.Weight)})
+}
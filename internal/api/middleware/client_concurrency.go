@@ -0,0 +1,190 @@
+// Package middleware provides HTTP middleware components for the CLI Proxy API server.
+// This file contains the per-client-key concurrency limiter that caps in-flight requests
+// for each authenticated API key.
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+)
+
+// Over-limit handling modes for client concurrency.
+const (
+	ClientConcurrencyModeReject = "reject"
+	ClientConcurrencyModeQueue  = "queue"
+)
+
+const defaultClientConcurrencyQueueTimeout = 30 * time.Second
+
+// clientSlots tracks the semaphore and counters of one client key.
+type clientSlots struct {
+	sem      chan struct{}
+	limit    int
+	inFlight atomic.Int64
+	queued   atomic.Int64
+}
+
+// ClientConcurrencyStatus reports current concurrency usage for one client key.
+type ClientConcurrencyStatus struct {
+	APIKey   string `json:"api_key"`
+	Limit    int    `json:"limit"`
+	InFlight int64  `json:"in_flight"`
+	Queued   int64  `json:"queued"`
+}
+
+// ClientConcurrencyLimiter caps simultaneous requests per client API key.
+type ClientConcurrencyLimiter struct {
+	mu    sync.Mutex
+	cfg   config.ClientConcurrency
+	slots map[string]*clientSlots
+}
+
+// NewClientConcurrencyLimiter creates a limiter using the given configuration.
+func NewClientConcurrencyLimiter(cfg config.ClientConcurrency) *ClientConcurrencyLimiter {
+	return &ClientConcurrencyLimiter{cfg: cfg, slots: make(map[string]*clientSlots)}
+}
+
+// SetConfig applies a new configuration. Requests already holding a slot keep it; new requests
+// observe the updated limits.
+func (l *ClientConcurrencyLimiter) SetConfig(cfg config.ClientConcurrency) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	l.cfg = cfg
+	l.mu.Unlock()
+}
+
+func (l *ClientConcurrencyLimiter) limitFor(apiKey string) int {
+	if !l.cfg.Enabled {
+		return 0
+	}
+	if limit, ok := l.cfg.Limits[apiKey]; ok {
+		return limit
+	}
+	return l.cfg.DefaultLimit
+}
+
+// slotsFor returns the key's slots, replacing the semaphore when its limit changed.
+func (l *ClientConcurrencyLimiter) slotsFor(apiKey string) (*clientSlots, chan struct{}, bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limit := l.limitFor(apiKey)
+	if limit <= 0 {
+		return nil, nil, false, 0
+	}
+	slots, ok := l.slots[apiKey]
+	if !ok {
+		slots = &clientSlots{}
+		l.slots[apiKey] = slots
+	}
+	if slots.limit != limit {
+		slots.limit = limit
+		slots.sem = make(chan struct{}, limit)
+	}
+	timeout := time.Duration(l.cfg.QueueTimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultClientConcurrencyQueueTimeout
+	}
+	return slots, slots.sem, strings.EqualFold(strings.TrimSpace(l.cfg.Mode), ClientConcurrencyModeQueue), timeout
+}
+
+// acquire reserves a slot for apiKey, queueing when configured. It returns a release function,
+// or nil when the request must be rejected.
+func (l *ClientConcurrencyLimiter) acquire(ctx context.Context, apiKey string) func() {
+	slots, sem, queue, timeout := l.slotsFor(apiKey)
+	if slots == nil {
+		return func() {}
+	}
+	select {
+	case sem <- struct{}{}:
+	default:
+		if !queue {
+			return nil
+		}
+		slots.queued.Add(1)
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case sem <- struct{}{}:
+			slots.queued.Add(-1)
+		case <-ctx.Done():
+			slots.queued.Add(-1)
+			return nil
+		case <-timer.C:
+			slots.queued.Add(-1)
+			return nil
+		}
+	}
+	slots.inFlight.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			slots.inFlight.Add(-1)
+			<-sem
+		})
+	}
+}
+
+// Middleware enforces the limits for requests authenticated by AuthMiddleware.
+func (l *ClientConcurrencyLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l == nil {
+			c.Next()
+			return
+		}
+		apiKey, _ := c.Get("apiKey")
+		key, _ := apiKey.(string)
+		if key == "" {
+			c.Next()
+			return
+		}
+		release := l.acquire(c.Request.Context(), key)
+		if release == nil {
+			if DeadlineExceeded(c) {
+				AbortDeadlineExceeded(c, "ran out while queued for a concurrency slot")
+				return
+			}
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": gin.H{
+					"message": "too many concurrent requests for this API key",
+					"type":    "rate_limit_error",
+					"code":    "client_concurrency_exceeded",
+				},
+			})
+			return
+		}
+		defer release()
+		c.Next()
+	}
+}
+
+// Snapshot lists in-flight and queued counts for every client key that has been limited, with keys masked.
+func (l *ClientConcurrencyLimiter) Snapshot() []ClientConcurrencyStatus {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]ClientConcurrencyStatus, 0, len(l.slots))
+	for key, slots := range l.slots {
+		out = append(out, ClientConcurrencyStatus{
+			APIKey:   util.HideAPIKey(key),
+			Limit:    l.limitFor(key),
+			InFlight: slots.inFlight.Load(),
+			Queued:   slots.queued.Load(),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].APIKey < out[j].APIKey })
+	return out
+}
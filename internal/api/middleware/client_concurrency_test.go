@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestClientConcurrencyRejectsOverLimit(t *testing.T) {
+	l := NewClientConcurrencyLimiter(config.ClientConcurrency{Enabled: true, DefaultLimit: 1})
+	release := l.acquire(context.Background(), "key-a")
+	if release == nil {
+		t.Fatal("first request rejected")
+	}
+	if l.acquire(context.Background(), "key-a") != nil {
+		t.Fatal("second concurrent request admitted")
+	}
+	if other := l.acquire(context.Background(), "key-b"); other == nil {
+		t.Fatal("limit leaked across client keys")
+	} else {
+		other()
+	}
+	release()
+	if again := l.acquire(context.Background(), "key-a"); again == nil {
+		t.Fatal("slot not released")
+	} else {
+		again()
+	}
+}
+
+func TestClientConcurrencyQueuesUntilRelease(t *testing.T) {
+	l := NewClientConcurrencyLimiter(config.ClientConcurrency{Enabled: true, DefaultLimit: 1, Mode: ClientConcurrencyModeQueue, QueueTimeoutSeconds: 5})
+	release := l.acquire(context.Background(), "key-a")
+
+	acquired := make(chan func(), 1)
+	go func() { acquired <- l.acquire(context.Background(), "key-a") }()
+	deadline := time.Now().Add(time.Second)
+	for l.Snapshot()[0].Queued != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("request was not queued")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	release()
+	select {
+	case queued := <-acquired:
+		if queued == nil {
+			t.Fatal("queued request rejected after release")
+		}
+		if st := l.Snapshot()[0]; st.InFlight != 1 || st.Queued != 0 {
+			t.Fatalf("unexpected snapshot %+v", st)
+		}
+		queued()
+	case <-time.After(time.Second):
+		t.Fatal("queued request did not acquire the released slot")
+	}
+}
@@ -0,0 +1,194 @@
+// Package middleware provides HTTP middleware components for the CLI Proxy API server.
+// This file contains the dead-letter log for requests that failed on every attempted account.
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// deadLetterPostTimeout bounds one delivery to the dead-letter endpoint.
+const deadLetterPostTimeout = 10 * time.Second
+
+// deadLetterClientStatuses are final statuses caused by the request itself; they are not
+// reliability failures and are never dead-lettered.
+var deadLetterClientStatuses = map[int]bool{
+	http.StatusBadRequest:            true,
+	http.StatusNotFound:              true,
+	http.StatusRequestEntityTooLarge: true,
+	http.StatusUnprocessableEntity:   true,
+}
+
+// DeadLetterRecord describes a request that failed after every attempted account failed.
+type DeadLetterRecord struct {
+	Time      time.Time         `json:"time"`
+	RequestID string            `json:"request_id"`
+	Method    string            `json:"method"`
+	Path      string            `json:"path"`
+	ClientKey string            `json:"client_key,omitempty"`
+	UserAgent string            `json:"user_agent,omitempty"`
+	Model     string            `json:"model,omitempty"`
+	Status    int               `json:"status"`
+	LatencyMs int64             `json:"latency_ms"`
+	Attempts  []UpstreamAttempt `json:"attempts"`
+}
+
+// DeadLetterLog sends fully failed requests to a file and/or an HTTP endpoint.
+type DeadLetterLog struct {
+	mu     sync.Mutex
+	cfg    config.DeadLetter
+	out    io.Writer
+	closer io.Closer
+	client *http.Client
+}
+
+// NewDeadLetterLog creates the dead-letter log using the given configuration.
+func NewDeadLetterLog(cfg config.DeadLetter) *DeadLetterLog {
+	l := &DeadLetterLog{client: &http.Client{Timeout: deadLetterPostTimeout}}
+	l.SetConfig(cfg)
+	return l
+}
+
+// SetConfig applies a new configuration, reopening the output file when it changed.
+func (l *DeadLetterLog) SetConfig(cfg config.DeadLetter) {
+	if l == nil {
+		return
+	}
+	cfg.Output = strings.TrimSpace(cfg.Output)
+	cfg.URL = strings.TrimSpace(cfg.URL)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if reflect.DeepEqual(l.cfg, cfg) && (l.out != nil || !cfg.Enabled || cfg.Output == "") {
+		return
+	}
+	if l.closer != nil {
+		_ = l.closer.Close()
+	}
+	l.cfg, l.out, l.closer = cfg, nil, nil
+	if !cfg.Enabled || cfg.Output == "" {
+		return
+	}
+	if strings.EqualFold(cfg.Output, "stdout") {
+		l.out = os.Stdout
+		return
+	}
+	if dir := filepath.Dir(cfg.Output); dir != "" {
+		_ = os.MkdirAll(dir, 0o755)
+	}
+	file, err := os.OpenFile(cfg.Output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		log.Warnf("dead-letter file output disabled: %v", err)
+		return
+	}
+	l.out, l.closer = file, file
+}
+
+func (l *DeadLetterLog) enabled() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.cfg.Enabled && (l.out != nil || l.cfg.URL != "")
+}
+
+// Middleware records the attempts of each request and dead-letters the request when it failed
+// without any attempt succeeding.
+func (l *DeadLetterLog) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l == nil || !l.enabled() {
+			c.Next()
+			return
+		}
+		started := time.Now()
+		entry := attachAuditEntry(c)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if status < http.StatusBadRequest || deadLetterClientStatuses[status] {
+			return
+		}
+		attempts := entry.snapshot()
+		if len(attempts) == 0 {
+			return
+		}
+		for _, attempt := range attempts {
+			if attempt.Success {
+				return
+			}
+		}
+		record := DeadLetterRecord{
+			Time:      started,
+			RequestID: c.Writer.Header().Get(RequestIDHeader),
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			UserAgent: c.Request.UserAgent(),
+			Model:     attempts[len(attempts)-1].Model,
+			Status:    status,
+			LatencyMs: time.Since(started).Milliseconds(),
+			Attempts:  attempts,
+		}
+		if record.RequestID == "" {
+			record.RequestID = requestID(c.GetHeader(RequestIDHeader))
+		}
+		if apiKey, ok := c.Get("apiKey"); ok {
+			if key, _ := apiKey.(string); key != "" {
+				record.ClientKey = util.HideAPIKey(key)
+			}
+		}
+		l.write(record)
+	}
+}
+
+func (l *DeadLetterLog) write(record DeadLetterRecord) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	l.mu.Lock()
+	cfg := l.cfg
+	if l.out != nil {
+		_, _ = l.out.Write(append(data, '\n'))
+	}
+	l.mu.Unlock()
+	if cfg.URL != "" {
+		go l.post(cfg, data)
+	}
+}
+
+func (l *DeadLetterLog) post(cfg config.DeadLetter, body []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), deadLetterPostTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		log.Warnf("dead-letter delivery failed: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range cfg.Headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := l.client.Do(req)
+	if err == nil {
+		_ = resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			err = fmt.Errorf("endpoint answered %d", resp.StatusCode)
+		}
+	}
+	if err != nil {
+		log.Warnf("dead-letter delivery failed: %v", err)
+	}
+}
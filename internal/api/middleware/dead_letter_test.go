@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func deadLetterRouter(l *DeadLetterLog, results []coreauth.Result, status int) *gin.Engine {
+	router := gin.New()
+	router.Use(l.Middleware())
+	router.POST("/v1/chat/completions", func(c *gin.Context) {
+		c.Set("apiKey", "sk-client-secret-key")
+		ctx := context.WithValue(context.Background(), "gin", c)
+		for _, result := range results {
+			ObserveResult(ctx, result, coreauth.ResultOutcome{})
+		}
+		c.Status(status)
+	})
+	return router
+}
+
+func TestDeadLetterRecordsRequestsFailedOnEveryAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	l := &DeadLetterLog{cfg: config.DeadLetter{Enabled: true}, out: &buf}
+	router := deadLetterRouter(l, []coreauth.Result{
+		{AuthID: "a.json", Provider: "codex", Model: "gpt-5", Error: &coreauth.Error{HTTPStatus: 429, Message: "quota exhausted"}},
+		{AuthID: "b.json", Provider: "codex", Model: "gpt-5", Error: &coreauth.Error{HTTPStatus: 503, Code: "overloaded"}},
+	}, http.StatusServiceUnavailable)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil))
+
+	var record DeadLetterRecord
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("dead-letter record is not JSON: %v (%s)", err, buf.String())
+	}
+	if record.Status != http.StatusServiceUnavailable || record.Model != "gpt-5" || record.RequestID == "" || len(record.Attempts) != 2 {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+	if record.Attempts[0].Error != "quota exhausted" || record.Attempts[1].Error != "overloaded" || record.Attempts[1].AccountID != "b.json" {
+		t.Fatalf("unexpected attempts: %+v", record.Attempts)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("sk-client-secret-key")) {
+		t.Fatal("client key leaked into the dead-letter log")
+	}
+}
+
+func TestDeadLetterSkipsRecoveredAndClientErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	l := &DeadLetterLog{cfg: config.DeadLetter{Enabled: true}, out: &buf}
+	failed := coreauth.Result{AuthID: "a.json", Provider: "codex", Error: &coreauth.Error{HTTPStatus: 500}}
+	cases := []struct {
+		results []coreauth.Result
+		status  int
+	}{
+		{[]coreauth.Result{failed, {AuthID: "b.json", Provider: "codex", Success: true}}, http.StatusOK},
+		{[]coreauth.Result{{AuthID: "a.json", Provider: "codex", Error: &coreauth.Error{HTTPStatus: 400}}}, http.StatusBadRequest},
+		{nil, http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		deadLetterRouter(l, tc.results, tc.status).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil))
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("unexpected dead-letter records: %s", buf.String())
+	}
+}
+
+func TestDeadLetterPostsToEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	received := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Authorization") == "Bearer t" {
+			received <- body
+		}
+	}))
+	defer srv.Close()
+	l := NewDeadLetterLog(config.DeadLetter{Enabled: true, URL: srv.URL, Headers: map[string]string{"Authorization": "Bearer t"}})
+	router := deadLetterRouter(l, []coreauth.Result{{AuthID: "a.json", Provider: "gemini", Error: &coreauth.Error{HTTPStatus: 502}}}, http.StatusBadGateway)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil))
+
+	select {
+	case body := <-received:
+		var record DeadLetterRecord
+		if err := json.Unmarshal(body, &record); err != nil || record.Status != http.StatusBadGateway {
+			t.Fatalf("unexpected delivery: %s", body)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("record not delivered")
+	}
+}
@@ -0,0 +1,122 @@
+// Package middleware provides HTTP middleware components for the CLI Proxy API server.
+// This file contains the global request rate cap shared by every client and provider.
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GlobalRateLimitStatus reports the global cap and the rate observed over the last minute.
+type GlobalRateLimitStatus struct {
+	// CapRPM is the configured cap in requests per minute; 0 means uncapped.
+	CapRPM int `json:"cap_rpm"`
+	// CurrentRPM counts requests admitted during the last minute.
+	CurrentRPM int64 `json:"current_rpm"`
+	// Rejected counts requests refused with 429 since the process started.
+	Rejected int64 `json:"rejected"`
+}
+
+// GlobalRateLimiter caps the proxy's overall throughput with one token bucket. The bucket holds
+// up to a minute's worth of requests and refills continuously at the cap.
+type GlobalRateLimiter struct {
+	mu       sync.Mutex
+	rpm      int
+	tokens   float64
+	last     time.Time
+	admitted [60]int64
+	seconds  [60]int64
+	rejected atomic.Int64
+}
+
+// NewGlobalRateLimiter creates a limiter capped at rpm requests per minute; 0 disables it.
+func NewGlobalRateLimiter(rpm int) *GlobalRateLimiter {
+	l := &GlobalRateLimiter{}
+	l.SetCap(rpm)
+	return l
+}
+
+// SetCap changes the cap at runtime. The bucket starts full at the new cap.
+func (l *GlobalRateLimiter) SetCap(rpm int) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rpm = max(rpm, 0)
+	if rpm == l.rpm {
+		return
+	}
+	l.rpm = rpm
+	l.tokens = float64(rpm)
+	l.last = time.Now()
+}
+
+// allow takes a token at now, returning how long to wait for one when the bucket is empty.
+func (l *GlobalRateLimiter) allow(now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rpm > 0 {
+		perSecond := float64(l.rpm) / 60
+		l.tokens = math.Min(float64(l.rpm), l.tokens+now.Sub(l.last).Seconds()*perSecond)
+		l.last = now
+		if l.tokens < 1 {
+			return false, time.Duration((1 - l.tokens) / perSecond * float64(time.Second))
+		}
+		l.tokens--
+	}
+	sec := now.Unix()
+	slot := sec % int64(len(l.seconds))
+	if l.seconds[slot] != sec {
+		l.seconds[slot], l.admitted[slot] = sec, 0
+	}
+	l.admitted[slot]++
+	return true, 0
+}
+
+// Status reports the cap, the admitted requests during the last minute and the rejections.
+func (l *GlobalRateLimiter) Status() GlobalRateLimitStatus {
+	if l == nil {
+		return GlobalRateLimitStatus{}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	status := GlobalRateLimitStatus{CapRPM: l.rpm, Rejected: l.rejected.Load()}
+	now := time.Now().Unix()
+	for i, sec := range l.seconds {
+		if now-sec < int64(len(l.seconds)) {
+			status.CurrentRPM += l.admitted[i]
+		}
+	}
+	return status
+}
+
+// Middleware rejects requests over the global cap with 429 and a Retry-After header.
+func (l *GlobalRateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l == nil {
+			c.Next()
+			return
+		}
+		ok, wait := l.allow(time.Now())
+		if !ok {
+			l.rejected.Add(1)
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": gin.H{
+					"message": "global request rate cap reached",
+					"type":    "rate_limit_error",
+					"code":    "global_rate_limit_exceeded",
+				},
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGlobalRateLimiterCapsAndRefills(t *testing.T) {
+	l := NewGlobalRateLimiter(60)
+	now := l.last
+	for i := 0; i < 60; i++ {
+		if ok, _ := l.allow(now); !ok {
+			t.Fatalf("request %d rejected within the bucket", i)
+		}
+	}
+	ok, wait := l.allow(now)
+	if ok || wait <= 0 || wait > time.Second {
+		t.Fatalf("expected rejection with a wait up to 1s, got ok=%v wait=%v", ok, wait)
+	}
+	if ok, _ := l.allow(now.Add(time.Second)); !ok {
+		t.Fatal("bucket did not refill at the cap rate")
+	}
+	if st := l.Status(); st.CapRPM != 60 || st.CurrentRPM != 61 {
+		t.Fatalf("unexpected status %+v", st)
+	}
+
+	l.SetCap(0)
+	for i := 0; i < 200; i++ {
+		if ok, _ := l.allow(now.Add(time.Second)); !ok {
+			t.Fatal("uncapped limiter rejected a request")
+		}
+	}
+}
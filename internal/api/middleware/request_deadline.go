@@ -0,0 +1,118 @@
+// Package middleware provides HTTP middleware components for the CLI Proxy API server.
+// This file contains the client deadline header handling that bounds a request's lifecycle.
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+const defaultRequestDeadlineHeader = "X-Deadline"
+
+// RequestDeadline applies client supplied deadlines to the request context.
+type RequestDeadline struct {
+	mu  sync.RWMutex
+	cfg config.RequestDeadline
+}
+
+// NewRequestDeadline creates the deadline middleware using the given configuration.
+func NewRequestDeadline(cfg config.RequestDeadline) *RequestDeadline {
+	return &RequestDeadline{cfg: cfg}
+}
+
+// SetConfig applies a new configuration to subsequent requests.
+func (d *RequestDeadline) SetConfig(cfg config.RequestDeadline) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.cfg = cfg
+	d.mu.Unlock()
+}
+
+// parseDeadline accepts an absolute RFC3339 timestamp, a Go duration or a number of seconds.
+func parseDeadline(raw string, now time.Time) (time.Time, bool) {
+	raw = strings.TrimSpace(raw)
+	if ts, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+		return ts, true
+	}
+	if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+		return now.Add(d), true
+	}
+	if secs, err := strconv.ParseFloat(raw, 64); err == nil && secs > 0 {
+		return now.Add(time.Duration(secs * float64(time.Second))), true
+	}
+	return time.Time{}, false
+}
+
+// Middleware bounds the request context by the client deadline. It must run before the concurrency
+// limiter so queueing observes the deadline.
+func (d *RequestDeadline) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if d == nil {
+			c.Next()
+			return
+		}
+		d.mu.RLock()
+		cfg := d.cfg
+		d.mu.RUnlock()
+		header := strings.TrimSpace(cfg.Header)
+		if header == "" {
+			header = defaultRequestDeadlineHeader
+		}
+		raw := c.GetHeader(header)
+		if !cfg.Enabled || raw == "" {
+			c.Next()
+			return
+		}
+		now := time.Now()
+		deadline, ok := parseDeadline(raw, now)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": gin.H{
+					"message": "invalid " + header + " header: expected an RFC3339 timestamp or a duration",
+					"type":    "invalid_request_error",
+					"code":    "invalid_deadline",
+				},
+			})
+			return
+		}
+		if cfg.MaxSeconds > 0 {
+			if limit := now.Add(time.Duration(cfg.MaxSeconds) * time.Second); deadline.After(limit) {
+				deadline = limit
+			}
+		}
+		if !deadline.After(now) {
+			AbortDeadlineExceeded(c, "deadline already passed on arrival")
+			return
+		}
+		ctx, cancel := context.WithDeadline(c.Request.Context(), deadline)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// DeadlineExceeded reports whether the request context ran out of its client deadline.
+func DeadlineExceeded(c *gin.Context) bool {
+	return c != nil && c.Request != nil && errors.Is(c.Request.Context().Err(), context.DeadlineExceeded)
+}
+
+// AbortDeadlineExceeded answers 504 with the stage at which the client deadline ran out.
+func AbortDeadlineExceeded(c *gin.Context, reason string) {
+	c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{
+		"error": gin.H{
+			"message": "request deadline exceeded: " + reason,
+			"type":    "timeout_error",
+			"code":    "deadline_exceeded",
+		},
+	})
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestParseDeadline(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		"2026-01-02T15:00:30Z": now.Add(30 * time.Second),
+		"2.5s":                 now.Add(2500 * time.Millisecond),
+		"30":                   now.Add(30 * time.Second),
+	}
+	for raw, want := range cases {
+		if got, ok := parseDeadline(raw, now); !ok || !got.Equal(want) {
+			t.Fatalf("parseDeadline(%q) = %v, %t; want %v", raw, got, ok, want)
+		}
+	}
+	if _, ok := parseDeadline("soon", now); ok {
+		t.Fatal("invalid deadline accepted")
+	}
+}
+
+func TestRequestDeadlineMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	d := NewRequestDeadline(config.RequestDeadline{Enabled: true, MaxSeconds: 10})
+	engine := gin.New()
+	var remaining time.Duration
+	engine.GET("/", d.Middleware(), func(c *gin.Context) {
+		if deadline, ok := c.Request.Context().Deadline(); ok {
+			remaining = time.Until(deadline)
+		}
+		c.Status(http.StatusOK)
+	})
+	serve := func(value string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Deadline", value)
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := serve("1h"); code != http.StatusOK || remaining <= 0 || remaining > 10*time.Second {
+		t.Fatalf("expected capped deadline on the request context, got code=%d remaining=%s", code, remaining)
+	}
+	if code := serve(time.Now().Add(-time.Second).Format(time.RFC3339)); code != http.StatusGatewayTimeout {
+		t.Fatalf("expected 504 for a passed deadline, got %d", code)
+	}
+	if code := serve("whenever"); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid deadline, got %d", code)
+	}
+}
@@ -0,0 +1,266 @@
+// Package middleware provides HTTP middleware components for the CLI Proxy API server.
+// This file contains the structured audit log that records which account served each request.
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// RequestIDHeader carries the request id on responses; a well-formed inbound value is reused.
+	RequestIDHeader = "X-Request-ID"
+	// auditEntryKey stores the request's audit entry in the Gin context.
+	auditEntryKey = "upstreamAuditEntry"
+	// maxRequestIDLength bounds inbound request ids that are reused.
+	maxRequestIDLength = 128
+	// maxAttemptErrorLength bounds the failure reason recorded per attempt.
+	maxAttemptErrorLength = 200
+)
+
+// UpstreamAttempt is one upstream call recorded in an audit line.
+type UpstreamAttempt struct {
+	AccountID      string     `json:"account_id"`
+	Provider       string     `json:"provider"`
+	Model          string     `json:"model,omitempty"`
+	LatencyMs      int64      `json:"latency_ms"`
+	UpstreamStatus int        `json:"upstream_status,omitempty"`
+	Success        bool       `json:"success"`
+	QuotaEvent     bool       `json:"quota_event"`
+	BackoffUntil   *time.Time `json:"backoff_until,omitempty"`
+	Error          string     `json:"error,omitempty"`
+}
+
+// UpstreamAuditLine is the JSON line written per request. The top-level account fields describe
+// the last attempt, i.e. the account that served or finally failed the request.
+type UpstreamAuditLine struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"request_id"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	ClientKey string    `json:"client_key,omitempty"`
+	Status    int       `json:"status"`
+	LatencyMs int64     `json:"latency_ms"`
+
+	AccountID         string     `json:"account_id,omitempty"`
+	Provider          string     `json:"provider,omitempty"`
+	Model             string     `json:"model,omitempty"`
+	UpstreamLatencyMs int64      `json:"upstream_latency_ms,omitempty"`
+	UpstreamStatus    int        `json:"upstream_status,omitempty"`
+	QuotaEvent        bool       `json:"quota_event"`
+	BackoffUntil      *time.Time `json:"backoff_until,omitempty"`
+
+	Attempts []UpstreamAttempt `json:"attempts,omitempty"`
+}
+
+// auditEntry collects the attempts of one request; results may arrive from stream goroutines.
+type auditEntry struct {
+	mu       sync.Mutex
+	attempts []UpstreamAttempt
+}
+
+// UpstreamAuditLog writes structured per-request audit lines.
+type UpstreamAuditLog struct {
+	mu     sync.Mutex
+	cfg    config.UpstreamAuditLog
+	out    io.Writer
+	closer io.Closer
+}
+
+// NewUpstreamAuditLog creates the audit log using the given configuration.
+func NewUpstreamAuditLog(cfg config.UpstreamAuditLog) *UpstreamAuditLog {
+	l := &UpstreamAuditLog{}
+	l.SetConfig(cfg)
+	return l
+}
+
+// SetConfig applies a new configuration, reopening the output when it changed.
+func (l *UpstreamAuditLog) SetConfig(cfg config.UpstreamAuditLog) {
+	if l == nil {
+		return
+	}
+	cfg.Output = strings.TrimSpace(cfg.Output)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.out != nil && l.cfg == cfg {
+		return
+	}
+	if l.closer != nil {
+		_ = l.closer.Close()
+	}
+	l.cfg, l.out, l.closer = cfg, nil, nil
+	if !cfg.Enabled {
+		return
+	}
+	if cfg.Output == "" || strings.EqualFold(cfg.Output, "stdout") {
+		l.out = os.Stdout
+		return
+	}
+	if dir := filepath.Dir(cfg.Output); dir != "" {
+		_ = os.MkdirAll(dir, 0o755)
+	}
+	file, err := os.OpenFile(cfg.Output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		log.Warnf("upstream audit log disabled: %v", err)
+		l.cfg.Enabled = false
+		return
+	}
+	l.out, l.closer = file, file
+}
+
+func (l *UpstreamAuditLog) enabled() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.cfg.Enabled && l.out != nil
+}
+
+func (l *UpstreamAuditLog) write(line UpstreamAuditLine) {
+	data, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.out != nil {
+		_, _ = l.out.Write(data)
+	}
+}
+
+// requestID reuses a well-formed inbound request id or generates one.
+func requestID(inbound string) string {
+	inbound = strings.TrimSpace(inbound)
+	if inbound != "" && len(inbound) <= maxRequestIDLength && !strings.ContainsFunc(inbound, func(r rune) bool {
+		return r < 0x21 || r > 0x7e
+	}) {
+		return inbound
+	}
+	var buf [16]byte
+	_, _ = rand.Read(buf[:])
+	return hex.EncodeToString(buf[:])
+}
+
+// Middleware tags the request with a request id and writes its audit line once it finished.
+func (l *UpstreamAuditLog) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l == nil || !l.enabled() {
+			c.Next()
+			return
+		}
+		started := time.Now()
+		id := requestID(c.GetHeader(RequestIDHeader))
+		c.Header(RequestIDHeader, id)
+		entry := attachAuditEntry(c)
+
+		c.Next()
+
+		line := UpstreamAuditLine{
+			Time:      started,
+			RequestID: id,
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			Status:    c.Writer.Status(),
+			LatencyMs: time.Since(started).Milliseconds(),
+		}
+		if apiKey, ok := c.Get("apiKey"); ok {
+			if key, _ := apiKey.(string); key != "" {
+				line.ClientKey = util.HideAPIKey(key)
+			}
+		}
+		line.Attempts = entry.snapshot()
+		if n := len(line.Attempts); n > 0 {
+			last := line.Attempts[n-1]
+			line.AccountID, line.Provider, line.Model = last.AccountID, last.Provider, last.Model
+			line.UpstreamLatencyMs, line.UpstreamStatus = last.LatencyMs, last.UpstreamStatus
+			line.QuotaEvent, line.BackoffUntil = last.QuotaEvent, last.BackoffUntil
+		}
+		l.write(line)
+	}
+}
+
+// attachAuditEntry returns the attempt recorder of the request, creating it when no earlier
+// middleware did.
+func attachAuditEntry(c *gin.Context) *auditEntry {
+	if value, ok := c.Get(auditEntryKey); ok {
+		if entry, ok := value.(*auditEntry); ok {
+			return entry
+		}
+	}
+	entry := &auditEntry{}
+	c.Set(auditEntryKey, entry)
+	return entry
+}
+
+func (e *auditEntry) snapshot() []UpstreamAttempt {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]UpstreamAttempt(nil), e.attempts...)
+}
+
+// ObserveResult records an upstream result against the audit entry of the request in ctx. It is
+// installed as the auth manager's result observer.
+func ObserveResult(ctx context.Context, result coreauth.Result, outcome coreauth.ResultOutcome) {
+	if ctx == nil {
+		return
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil {
+		return
+	}
+	value, ok := ginCtx.Get(auditEntryKey)
+	if !ok {
+		return
+	}
+	entry, ok := value.(*auditEntry)
+	if !ok {
+		return
+	}
+	attempt := UpstreamAttempt{
+		AccountID:  result.AuthID,
+		Provider:   result.Provider,
+		Model:      result.Model,
+		LatencyMs:  result.Latency.Milliseconds(),
+		Success:    result.Success,
+		QuotaEvent: outcome.Quota,
+	}
+	if result.Error != nil {
+		attempt.UpstreamStatus = result.Error.HTTPStatus
+		attempt.Error = attemptError(result.Error)
+	} else if result.Success {
+		attempt.UpstreamStatus = http.StatusOK
+	}
+	if !outcome.CooldownUntil.IsZero() {
+		until := outcome.CooldownUntil
+		attempt.BackoffUntil = &until
+	}
+	entry.mu.Lock()
+	entry.attempts = append(entry.attempts, attempt)
+	entry.mu.Unlock()
+}
+
+// attemptError condenses an upstream failure into a short reason: its code or a truncated message.
+func attemptError(err *coreauth.Error) string {
+	if code := strings.TrimSpace(err.Code); code != "" {
+		return code
+	}
+	msg := strings.TrimSpace(err.Message)
+	if len(msg) > maxAttemptErrorLength {
+		msg = msg[:maxAttemptErrorLength] + "..."
+	}
+	return msg
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestUpstreamAuditLogWritesAttempts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	l := &UpstreamAuditLog{cfg: config.UpstreamAuditLog{Enabled: true}, out: &buf}
+	router := gin.New()
+	router.Use(l.Middleware())
+	router.POST("/v1/chat/completions", func(c *gin.Context) {
+		c.Set("apiKey", "sk-client-secret-key")
+		ctx := context.WithValue(context.Background(), "gin", c)
+		until := time.Now().Add(time.Minute)
+		ObserveResult(ctx, coreauth.Result{AuthID: "a.json", Provider: "codex", Model: "gpt-5", Error: &coreauth.Error{HTTPStatus: 429}}, coreauth.ResultOutcome{Quota: true, CooldownUntil: until})
+		ObserveResult(ctx, coreauth.Result{AuthID: "b.json", Provider: "codex", Model: "gpt-5", Success: true, Latency: 40 * time.Millisecond}, coreauth.ResultOutcome{})
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.Header.Set(RequestIDHeader, "req-123")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get(RequestIDHeader); got != "req-123" {
+		t.Fatalf("expected the inbound request id to be echoed, got %q", got)
+	}
+	var line UpstreamAuditLine
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("audit line is not JSON: %v (%s)", err, buf.String())
+	}
+	if line.RequestID != "req-123" || line.AccountID != "b.json" || line.UpstreamStatus != http.StatusOK || line.UpstreamLatencyMs != 40 {
+		t.Fatalf("unexpected audit line: %+v", line)
+	}
+	if len(line.Attempts) != 2 || !line.Attempts[0].QuotaEvent || line.Attempts[0].BackoffUntil == nil || line.Attempts[0].UpstreamStatus != 429 {
+		t.Fatalf("unexpected attempts: %+v", line.Attempts)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("sk-client-secret-key")) {
+		t.Fatal("client key leaked into the audit log")
+	}
+}
+
+func TestRequestIDRejectsMalformedInbound(t *testing.T) {
+	if id := requestID("bad id\nwith newline"); id == "bad id\nwith newline" || len(id) != 32 {
+		t.Fatalf("expected a generated id, got %q", id)
+	}
+}
@@ -147,6 +147,20 @@ type Server struct {
 	wsAuthChanged func(bool, bool)
 	wsAuthEnabled atomic.Bool
 
+	// clientConcurrency caps in-flight requests per client API key.
+	clientConcurrency *middleware.ClientConcurrencyLimiter
+
+	// globalRateLimit caps the requests per minute across all clients and providers.
+	globalRateLimit *middleware.GlobalRateLimiter
+
+	// upstreamAudit writes one structured line per proxied request.
+	upstreamAudit *middleware.UpstreamAuditLog
+	// deadLetter records requests that failed on every attempted account.
+	deadLetter *middleware.DeadLetterLog
+
+	// requestDeadline applies client deadline headers to request contexts.
+	requestDeadline *middleware.RequestDeadline
+
 	// management handler
 	mgmt *managementHandlers.Handler
 
@@ -248,12 +262,23 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 	s.oldConfigYaml, _ = yaml.Marshal(cfg)
 	s.applyAccessConfig(nil, cfg)
 	if authManager != nil {
-		authManager.SetRetryConfig(cfg.RequestRetry, time.Duration(cfg.MaxRetryInterval)*time.Second)
+		authManager.SetResultObserver(middleware.ObserveResult)
 	}
 	managementasset.SetCurrentConfig(cfg)
 	auth.SetQuotaCooldownDisabled(cfg.DisableCooling)
+	util.SetImagePolicy(ImagePolicy(cfg))
 	// Initialize management handler
 	s.mgmt = managementHandlers.NewHandler(cfg, configFilePath, authManager)
+	s.clientConcurrency = middleware.NewClientConcurrencyLimiter(cfg.ClientConcurrency)
+	s.requestDeadline = middleware.NewRequestDeadline(cfg.RequestDeadline)
+	s.globalRateLimit = middleware.NewGlobalRateLimiter(cfg.GlobalRateLimitRPM)
+	s.upstreamAudit = middleware.NewUpstreamAuditLog(cfg.UpstreamAuditLog)
+	s.deadLetter = middleware.NewDeadLetterLog(cfg.DeadLetter)
+	s.mgmt.SetClientConcurrency(s.clientConcurrency)
+	s.mgmt.SetGlobalRateLimit(s.globalRateLimit)
+	s.mgmt.SetHealthHistory(cfg.HealthHistory)
+	s.mgmt.SetCanary(cfg.Canary)
+	s.mgmt.SetAccountWebhook(cfg.AccountWebhook)
 	if optionState.localPassword != "" {
 		s.mgmt.SetLocalPassword(optionState.localPassword)
 	}
@@ -317,7 +342,7 @@ func (s *Server) setupRoutes() {
 
 	// OpenAI compatible API routes
 	v1 := s.engine.Group("/v1")
-	v1.Use(AuthMiddleware(s.accessManager))
+	v1.Use(s.upstreamAudit.Middleware(), s.deadLetter.Middleware(), AuthMiddleware(s.accessManager), s.globalRateLimit.Middleware(), s.requestDeadline.Middleware(), s.clientConcurrency.Middleware())
 	{
 		v1.GET("/models", s.unifiedModelsHandler(openaiHandlers, claudeCodeHandlers))
 		v1.POST("/chat/completions", openaiHandlers.ChatCompletions)
@@ -329,7 +354,7 @@ func (s *Server) setupRoutes() {
 
 	// Gemini compatible API routes
 	v1beta := s.engine.Group("/v1beta")
-	v1beta.Use(AuthMiddleware(s.accessManager))
+	v1beta.Use(s.upstreamAudit.Middleware(), s.deadLetter.Middleware(), AuthMiddleware(s.accessManager), s.globalRateLimit.Middleware(), s.requestDeadline.Middleware(), s.clientConcurrency.Middleware())
 	{
 		v1beta.GET("/models", geminiHandlers.GeminiModels)
 		v1beta.POST("/models/:action", geminiHandlers.GeminiHandler)
@@ -361,15 +386,34 @@ func (s *Server) setupRoutes() {
 			log.Debugf("Health check from %s (User-Agent: %s)", clientIP, userAgent)
 		}
 
-		c.JSON(http.StatusOK, gin.H{
-			"status":    "healthy",
-			"service":   "CLIProxyAPI",
-			"timestamp": time.Now().UTC().Format(time.RFC3339),
-		})
+		// A failing persistence backend only degrades durability, so the proxy still reports healthy.
+		persistenceHealthy := true
+		if s.handlers != nil {
+			persistenceHealthy = s.handlers.AuthManager.RuntimeStatePersistenceHealth().Healthy
+		}
+		body := gin.H{
+			"status":              "healthy",
+			"service":             "CLIProxyAPI",
+			"timestamp":           time.Now().UTC().Format(time.RFC3339),
+			"persistence_healthy": persistenceHealthy,
+		}
+		if s.handlers != nil {
+			if shard := s.handlers.AuthManager.ShardAssignment(); shard.Enabled {
+				body["shard"] = shard
+			}
+		}
+		c.JSON(http.StatusOK, body)
 	}
 	s.engine.GET("/health", healthHandler)
 	s.engine.HEAD("/health", healthHandler)
 
+	// Load balancer probes: liveness only needs the process, readiness an active account.
+	livenessHandler := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"status": "ok"}) }
+	s.engine.GET("/healthz", livenessHandler)
+	s.engine.HEAD("/healthz", livenessHandler)
+	s.engine.GET("/readyz", s.mgmt.Readiness)
+	s.engine.HEAD("/readyz", s.mgmt.Readiness)
+
 	s.engine.POST("/v1internal:method", geminiCLIHandlers.CLIHandler)
 
 	// OAuth callback endpoints (reuse main server port)
@@ -487,9 +531,11 @@ func (s *Server) registerManagementRoutes() {
 	log.Info("management routes registered after secret key configuration")
 
 	mgmt := s.engine.Group("/v0/management")
-	mgmt.Use(s.managementAvailabilityMiddleware(), s.mgmt.Middleware())
+	mgmt.Use(s.managementAvailabilityMiddleware(), s.mgmt.Middleware(), s.mgmt.AuditMiddleware())
 	{
 		mgmt.GET("/usage", s.mgmt.GetUsageStatistics)
+		mgmt.GET("/global-rate-limit", s.mgmt.GetGlobalRateLimit)
+		mgmt.POST("/global-rate-limit", s.mgmt.SetGlobalRateLimitCap)
 		mgmt.GET("/config", s.mgmt.GetConfig)
 		mgmt.PUT("/config.yaml", s.mgmt.PutConfigYAML)
 		mgmt.GET("/config.yaml", s.mgmt.GetConfigFile)
@@ -587,6 +633,388 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.POST("/iflow-auth-url", s.mgmt.RequestIFlowCookieToken)
 		mgmt.GET("/get-auth-status", s.mgmt.GetAuthStatus)
 		mgmt.GET("/accounts-monitor", s.mgmt.GetAccountsMonitor)
+		mgmt.GET("/accounts-monitor/stream", s.mgmt.StreamAccountsMonitor)
+		mgmt.GET("/metrics", s.mgmt.GetMetrics)
+		mgmt.GET("/accounts/recovering", s.mgmt.GetRecoveringAccounts)
+		mgmt.POST("/accounts/tag", s.mgmt.TagAccounts)
+		mgmt.DELETE("/accounts/tag", s.mgmt.UntagAccounts)
+		mgmt.POST("/accounts/validate", s.mgmt.ValidateAccount)
+		mgmt.GET("/accounts/:id/activity", s.mgmt.GetAccountActivity)
+		mgmt.POST("/accounts/:id/disable", s.mgmt.DisableAccount)
+		mgmt.POST("/accounts/:id/drain", s.mgmt.DrainAccount)
+		mgmt.POST("/accounts/:id/enable", s.mgmt.EnableAccount)
+		mgmt.PUT("/accounts/:id/flags", s.mgmt.SetAccountFlags)
+		mgmt.GET("/accounts/:id/model-windows", s.mgmt.GetAccountModelWindows)
+		mgmt.PUT("/accounts/:id/model-windows", s.mgmt.PutAccountModelWindows)
+		mgmt.PUT("/accounts/:id/priority", s.mgmt.SetAccountPriority)
+		mgmt.PUT("/accounts/:id/max-concurrent", s.mgmt.SetAccountMaxConcurrent)
+		mgmt.POST("/accounts/:id/reset-quota", s.mgmt.ResetAccountQuota)
+		mgmt.POST("/accounts/:id/clear-billing", s.mgmt.ClearAccountBilling)
+		mgmt.POST("/accounts/:id/test", s.mgmt.TestAccount)
+		mgmt.POST("/accounts/counters/reset", s.mgmt.ResetAccountCounters)
+		mgmt.POST("/accounts/token-usage/reset", s.mgmt.ResetAccountTokenUsage)
+		mgmt.GET("/providers/stream", s.mgmt.StreamProviders)
+		mgmt.GET("/families", s.mgmt.GetFamilies)
+		mgmt.GET("/circuit-breakers", s.mgmt.GetCircuitBreakers)
+		mgmt.GET("/diagnostics", s.mgmt.GetDiagnostics)
+		mgmt.GET("/version", s.mgmt.GetVersion)
+		mgmt.GET("/client-concurrency", s.mgmt.GetClientConcurrency)
+		mgmt.POST("/reconcile", s.mgmt.Reconcile)
+		mgmt.GET("/history/timeseries", s.mgmt.GetHealthTimeseries)
+		mgmt.GET("/batching", s.mgmt.GetBatching)
+		mgmt.GET("/selector/exclusions", s.mgmt.GetSelectorExclusions)
+		mgmt.GET("/next-account", s.mgmt.GetNextAccount)
+		mgmt.GET("/recommendations", s.mgmt.GetRecommendations)
+		mgmt.GET("/prefix-affinity", s.mgmt.GetPrefixAffinity)
+		mgmt.POST("/strategy/preview", s.mgmt.PreviewStrategy)
+		mgmt.GET("/selection-strategy", s.mgmt.GetSelectionStrategy)
+		mgmt.PUT("/selection-strategy", s.mgmt.PutSelectionStrategy)
+		mgmt.GET("/weights", s.mgmt.GetWeights)
+		mgmt.PUT("/weights", s.mgmt.PutWeights)
+		mgmt.POST("/weights/reset", s.mgmt.ResetWeights)
+		mgmt.GET("/reservations", s.mgmt.ListReservations)
+		mgmt.POST("/reservations", s.mgmt.CreateReservation)
+		mgmt.DELETE("/reservations/:name", s.mgmt.DeleteReservation)
+		mgmt.GET("/overhead", s.mgmt.GetOverhead)
+		mgmt.POST("/refresh/bulk", s.mgmt.BulkRefresh)
+		mgmt.GET("/refresh/concurrency", s.mgmt.GetRefreshConcurrency)
+		mgmt.GET("/drill", s.mgmt.GetDrill)
+		mgmt.POST("/drill", s.mgmt.RunDrill)
+		mgmt.GET("/canary", s.mgmt.GetCanary)
+		mgmt.POST("/canary/baseline", s.mgmt.ResetCanaryBaseline)
+		mgmt.GET("/account-webhook", s.mgmt.GetAccountWebhook)
+		mgmt.PUT("/account-webhook", s.mgmt.PutAccountWebhook)
+		mgmt.GET("/model-policies", s.mgmt.GetModelPolicies)
+		mgmt.PUT("/model-policies", s.mgmt.PutModelPolicies)
+		mgmt.GET("/clients/strategies", s.mgmt.ListClientStrategies)
+		mgmt.PUT("/clients/:key/strategy", s.mgmt.SetClientStrategy)
+		mgmt.DELETE("/clients/:key/strategy", s.mgmt.DeleteClientStrategy)
+		mgmt.GET("/duplicates", s.mgmt.GetDuplicates)
+		mgmt.GET("/model-routes", s.mgmt.GetModelRoutes)
+		mgmt.GET("/backoff-curves", s.mgmt.GetBackoffCurves)
+		mgmt.GET("/audit", s.mgmt.GetAuditEvents)
+		mgmt.GET("/audit/stream", s.mgmt.StreamAuditEvents)
+	}
+}
+
+// ApplyManagerPolicies configures the auth manager from every config section it reads. It is called
+// once at startup and again on each config reload.
+func ApplyManagerPolicies(mgr *auth.Manager, cfg *config.Config) {
+	if mgr == nil || cfg == nil {
+		return
+	}
+	mgr.SetRetryConfig(cfg.RequestRetry, time.Duration(cfg.MaxRetryInterval)*time.Second)
+	mgr.SetBillingSuspensionPolicy(BillingSuspensionPolicy(cfg))
+	mgr.SetMaintenancePolicy(MaintenancePolicy(cfg))
+	mgr.SetEgressBlockPolicy(EgressBlockPolicy(cfg))
+	mgr.SetBatchPolicy(BatchPolicy(cfg))
+	mgr.SetPeakReservePolicy(PeakReservePolicy(cfg))
+	mgr.SetModelRoutes(ModelRoutes(cfg))
+	mgr.SetModelCapabilities(cfg.ModelCapabilities)
+	mgr.SetRequestCapPolicy(RequestCapPolicy(cfg))
+	mgr.SetStreamRetryBeforeFirstByte(!cfg.DisableStreamRetry)
+	mgr.SetClientErrorStatuses(cfg.ClientErrorStatuses)
+	mgr.SetEagerRefreshThreshold(time.Duration(cfg.EagerRefreshSeconds) * time.Second)
+	mgr.SetConcurrencyQueueTimeout(time.Duration(cfg.AccountConcurrencyQueueSeconds) * time.Second)
+	mgr.SetTokenExpiryPenaltyPolicy(TokenExpiryPenaltyPolicy(cfg))
+	mgr.SetGatedFeatures(cfg.GatedFeatures)
+	mgr.SetRefreshStartupStagger(time.Duration(cfg.RefreshStartupStaggerSeconds) * time.Second)
+	mgr.SetCircuitBreakerPolicy(CircuitBreakerPolicy(cfg))
+	mgr.SetSoftRotationPolicy(SoftRotationPolicy(cfg))
+	mgr.SetRateLimitPolicy(RateLimitPolicy(cfg))
+	mgr.SetPrefixAffinityPolicy(PrefixAffinityPolicy(cfg))
+	mgr.SetDuplicateMode(cfg.DuplicateCredentials.Mode)
+	mgr.SetEmptyResponsePolicy(cfg.EmptyResponse.Policy)
+	mgr.SetLiveUsagePolicy(LiveUsagePolicy(cfg))
+	mgr.SetRecoveryHistorySize(cfg.RecoveryHistorySize)
+	if err := mgr.SetBackoffCurves(BackoffCurves(cfg)); err != nil {
+		log.Warnf("quota-backoff: %v", err)
+	}
+	mgr.SetRefreshConcurrencyPolicy(RefreshConcurrencyPolicy(cfg))
+	if err := mgr.SetShardPolicy(ShardPolicy(cfg)); err != nil {
+		log.Warnf("sharding: %v", err)
+	}
+	if err := mgr.SetSelectionStrategy(cfg.SelectionStrategy); err != nil {
+		log.Warnf("selection-strategy: %v", err)
+	}
+	mgr.SetReaperPolicy(context.Background(), ReaperPolicy(cfg))
+}
+
+// BillingSuspensionPolicy converts the billing suspension config into the auth manager policy.
+func BillingSuspensionPolicy(cfg *config.Config) auth.BillingSuspensionPolicy {
+	if cfg == nil {
+		return auth.BillingSuspensionPolicy{}
+	}
+	return auth.BillingSuspensionPolicy{
+		Enabled:  cfg.BillingSuspension.Enabled,
+		Patterns: cfg.BillingSuspension.Patterns,
+	}
+}
+
+// MaintenancePolicy converts the maintenance config into the auth manager policy.
+func MaintenancePolicy(cfg *config.Config) auth.MaintenancePolicy {
+	if cfg == nil {
+		return auth.MaintenancePolicy{}
+	}
+	return auth.MaintenancePolicy{
+		Enabled:           cfg.Maintenance.Enabled,
+		Patterns:          cfg.Maintenance.Patterns,
+		Cooldown:          time.Duration(cfg.Maintenance.CooldownSeconds) * time.Second,
+		ProviderThreshold: cfg.Maintenance.ProviderThreshold,
+		ProviderWindow:    time.Duration(cfg.Maintenance.ProviderWindowSeconds) * time.Second,
+		ProviderOpen:      time.Duration(cfg.Maintenance.ProviderOpenSeconds) * time.Second,
+	}
+}
+
+// EgressBlockPolicy converts the egress block config into the auth manager policy.
+func EgressBlockPolicy(cfg *config.Config) auth.EgressBlockPolicy {
+	if cfg == nil {
+		return auth.EgressBlockPolicy{}
+	}
+	return auth.EgressBlockPolicy{
+		Enabled:  cfg.EgressBlock.Enabled,
+		Patterns: cfg.EgressBlock.Patterns,
+		Cooldown: time.Duration(cfg.EgressBlock.CooldownMinutes) * time.Minute,
+	}
+}
+
+// BatchPolicy converts the batching config into the auth manager policy.
+func BatchPolicy(cfg *config.Config) auth.BatchPolicy {
+	if cfg == nil {
+		return auth.BatchPolicy{}
+	}
+	return auth.BatchPolicy{
+		Enabled:      cfg.Batching.Enabled,
+		MaxBatchSize: cfg.Batching.MaxBatchSize,
+		MaxWait:      time.Duration(cfg.Batching.MaxWaitMs) * time.Millisecond,
+		Providers:    cfg.Batching.Providers,
+	}
+}
+
+// TokenExpiryPenaltyPolicy converts the token-expiry-penalty config into the auth manager's policy.
+func TokenExpiryPenaltyPolicy(cfg *config.Config) auth.TokenExpiryPenaltyPolicy {
+	if cfg == nil {
+		return auth.TokenExpiryPenaltyPolicy{}
+	}
+	return auth.TokenExpiryPenaltyPolicy{
+		Window:     time.Duration(cfg.TokenExpiryPenalty.WindowMinutes) * time.Minute,
+		MaxPenalty: cfg.TokenExpiryPenalty.MaxPenalty,
+	}
+}
+
+// LiveUsagePolicy converts the live-usage config into the auth manager's running usage policy.
+func LiveUsagePolicy(cfg *config.Config) auth.LiveUsagePolicy {
+	if cfg == nil {
+		return auth.LiveUsagePolicy{}
+	}
+	return auth.LiveUsagePolicy{
+		Enabled:  cfg.LiveUsage.Enabled,
+		Interval: time.Duration(cfg.LiveUsage.IntervalSeconds) * time.Second,
+		APIKeys:  cfg.LiveUsage.APIKeys,
+	}
+}
+
+// PeakReservePolicy converts the peak reserve config into the auth manager policy. Invalid timezones
+// fall back to UTC and invalid windows are skipped, each with a warning.
+func PeakReservePolicy(cfg *config.Config) auth.PeakReservePolicy {
+	if cfg == nil {
+		return auth.PeakReservePolicy{}
+	}
+	pr := cfg.PeakReserve
+	policy := auth.PeakReservePolicy{
+		Enabled: pr.Enabled,
+		Tag:     pr.Tag,
+		Lead:    time.Duration(pr.LeadMinutes) * time.Minute,
+	}
+	if tz := strings.TrimSpace(pr.Timezone); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			log.Warnf("peak-reserve: invalid timezone %q, using UTC: %v", tz, err)
+		} else {
+			policy.Location = loc
+		}
+	}
+	for i, w := range pr.Windows {
+		window, err := parsePeakWindow(w)
+		if err != nil {
+			log.Warnf("peak-reserve: skipping window %d: %v", i, err)
+			continue
+		}
+		policy.Windows = append(policy.Windows, window)
+	}
+	return policy
+}
+
+// BackoffCurves converts the quota backoff config into the default and per-provider manager curves.
+func BackoffCurves(cfg *config.Config) (auth.BackoffCurve, map[string]auth.BackoffCurve) {
+	if cfg == nil {
+		return auth.BackoffCurve{}, nil
+	}
+	convert := func(c config.BackoffCurve) auth.BackoffCurve {
+		return auth.BackoffCurve{
+			Base:       time.Duration(c.BaseSeconds * float64(time.Second)),
+			Multiplier: c.Multiplier,
+			Max:        time.Duration(c.MaxSeconds * float64(time.Second)),
+			Jitter:     c.Jitter,
+		}
+	}
+	var providers map[string]auth.BackoffCurve
+	if len(cfg.QuotaBackoff.Providers) > 0 {
+		providers = make(map[string]auth.BackoffCurve, len(cfg.QuotaBackoff.Providers))
+		for provider, curve := range cfg.QuotaBackoff.Providers {
+			providers[provider] = convert(curve)
+		}
+	}
+	return convert(cfg.QuotaBackoff.Default), providers
+}
+
+// ModelRoutes converts the model routing table into auth manager routes.
+func ModelRoutes(cfg *config.Config) []auth.ModelRoute {
+	if cfg == nil || len(cfg.ModelRoutes) == 0 {
+		return nil
+	}
+	routes := make([]auth.ModelRoute, 0, len(cfg.ModelRoutes))
+	for _, r := range cfg.ModelRoutes {
+		routes = append(routes, auth.ModelRoute{Model: r.Model, Regex: r.Regex, Provider: r.Provider, Tag: r.Tag, Fallback: r.Fallback})
+	}
+	return routes
+}
+
+// RequestCapPolicy converts the soft request cap config into the auth manager policy.
+func RequestCapPolicy(cfg *config.Config) auth.RequestCapPolicy {
+	if cfg == nil {
+		return auth.RequestCapPolicy{}
+	}
+	policy := auth.RequestCapPolicy{
+		Enabled: cfg.RequestCaps.Enabled,
+		Daily:   cfg.RequestCaps.Daily,
+		Monthly: cfg.RequestCaps.Monthly,
+	}
+	if tz := strings.TrimSpace(cfg.RequestCaps.Timezone); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			log.Warnf("request-caps: invalid timezone %q, using UTC: %v", tz, err)
+		} else {
+			policy.Location = loc
+		}
+	}
+	return policy
+}
+
+func parsePeakWindow(w config.PeakWindow) (auth.PeakWindow, error) {
+	return auth.ParsePeakWindow(w.Days, w.Start, w.End)
+}
+
+// CircuitBreakerPolicy converts the circuit breaker config into the auth manager policy.
+func CircuitBreakerPolicy(cfg *config.Config) auth.CircuitBreakerPolicy {
+	if cfg == nil {
+		return auth.CircuitBreakerPolicy{}
+	}
+	convert := func(s config.CircuitBreakerSettings) auth.CircuitBreakerSettings {
+		return auth.CircuitBreakerSettings{
+			FailureThreshold: s.FailureThreshold,
+			OpenDuration:     time.Duration(s.OpenSeconds) * time.Second,
+			ProbeRequests:    s.ProbeRequests,
+			SuccessThreshold: s.SuccessThreshold,
+			ProbeInterval:    time.Duration(s.ProbeIntervalSeconds) * time.Second,
+			Window:           time.Duration(s.WindowSeconds) * time.Second,
+		}
+	}
+	policy := auth.CircuitBreakerPolicy{
+		Enabled:  cfg.CircuitBreaker.Enabled,
+		Defaults: convert(cfg.CircuitBreaker.Default),
+	}
+	if len(cfg.CircuitBreaker.Providers) > 0 {
+		policy.Providers = make(map[string]auth.CircuitBreakerSettings, len(cfg.CircuitBreaker.Providers))
+		for provider, settings := range cfg.CircuitBreaker.Providers {
+			policy.Providers[provider] = convert(settings)
+		}
+	}
+	return policy
+}
+
+// SoftRotationPolicy converts the soft rotation config into the auth manager policy.
+func SoftRotationPolicy(cfg *config.Config) auth.SoftRotationPolicy {
+	if cfg == nil {
+		return auth.SoftRotationPolicy{}
+	}
+	return auth.SoftRotationPolicy{
+		Enabled:  cfg.SoftRotation.Enabled,
+		Requests: cfg.SoftRotation.Requests,
+		Rest:     time.Duration(cfg.SoftRotation.RestSeconds) * time.Second,
+	}
+}
+
+// PrefixAffinityPolicy converts the prefix affinity TTL config into the auth manager policy.
+func PrefixAffinityPolicy(cfg *config.Config) auth.PrefixAffinityPolicy {
+	if cfg == nil {
+		return auth.PrefixAffinityPolicy{}
+	}
+	return auth.PrefixAffinityPolicy{
+		Mode:        cfg.PrefixAffinity.Mode(),
+		TTL:         time.Duration(cfg.PrefixAffinity.TTLSeconds) * time.Second,
+		MaxLifetime: time.Duration(cfg.PrefixAffinity.MaxLifetimeSeconds) * time.Second,
+	}
+}
+
+// ImagePolicy converts the image translation config into the translators' image policy.
+func ImagePolicy(cfg *config.Config) util.ImagePolicy {
+	if cfg == nil {
+		return util.ImagePolicy{FetchURLs: true}
+	}
+	return util.ImagePolicy{
+		FetchURLs: !cfg.ImageTranslation.DisableURLFetch,
+		MaxBytes:  int64(cfg.ImageTranslation.MaxImageMB) << 20,
+	}
+}
+
+// RefreshConcurrencyPolicy converts the refresh concurrency config into the auth manager policy.
+func RefreshConcurrencyPolicy(cfg *config.Config) auth.RefreshConcurrencyPolicy {
+	if cfg == nil {
+		return auth.RefreshConcurrencyPolicy{}
+	}
+	return auth.RefreshConcurrencyPolicy{
+		Default:   cfg.RefreshConcurrency.DefaultLimit,
+		Providers: cfg.RefreshConcurrency.Limits,
+	}
+}
+
+// RateLimitPolicy converts the rate-limit header config into the auth manager policy.
+func RateLimitPolicy(cfg *config.Config) auth.RateLimitPolicy {
+	if cfg == nil {
+		return auth.RateLimitPolicy{}
+	}
+	return auth.RateLimitPolicy{
+		Enabled:         cfg.RateLimitHeaders.Enabled,
+		CordonThreshold: int64(cfg.RateLimitHeaders.CordonThreshold),
+		FallbackCordon:  time.Duration(cfg.RateLimitHeaders.FallbackCordonSeconds) * time.Second,
+		MaxCordon:       time.Duration(cfg.RateLimitHeaders.MaxCordonSeconds) * time.Second,
+	}
+}
+
+// ShardPolicy converts the sharding configuration into the auth manager's policy.
+func ShardPolicy(cfg *config.Config) auth.ShardPolicy {
+	if cfg == nil {
+		return auth.ShardPolicy{}
+	}
+	return auth.ShardPolicy{
+		Enabled:      cfg.Sharding.Enabled,
+		Count:        cfg.Sharding.Count,
+		Index:        cfg.Sharding.Index,
+		VirtualNodes: cfg.Sharding.VirtualNodes,
+	}
+}
+
+// ReaperPolicy converts the stale account reaper config into the auth manager policy.
+func ReaperPolicy(cfg *config.Config) auth.ReaperPolicy {
+	if cfg == nil {
+		return auth.ReaperPolicy{}
+	}
+	return auth.ReaperPolicy{
+		Enabled:    cfg.Reaper.Enabled,
+		Interval:   time.Duration(cfg.Reaper.IntervalSeconds) * time.Second,
+		StaleAfter: time.Duration(cfg.Reaper.StaleAfterHours) * time.Hour,
+		PruneAfter: time.Duration(cfg.Reaper.PruneAfterHours) * time.Hour,
 	}
 }
 
@@ -774,6 +1202,11 @@ func (s *Server) Stop(ctx context.Context) error {
 		}
 	}
 
+	// Stop sampling and flush persisted health history before the listener goes away.
+	s.mgmt.StopHealthHistory()
+	s.mgmt.StopCanary()
+	s.mgmt.StopAccountWebhook()
+
 	// Shutdown the HTTP server.
 	if err := s.server.Shutdown(ctx); err != nil {
 		return fmt.Errorf("failed to shutdown HTTP server: %v", err)
@@ -860,6 +1293,8 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 		}
 	}
 
+	util.SetImagePolicy(ImagePolicy(cfg))
+
 	if oldCfg == nil || oldCfg.DisableCooling != cfg.DisableCooling {
 		auth.SetQuotaCooldownDisabled(cfg.DisableCooling)
 		if oldCfg != nil {
@@ -868,9 +1303,11 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 			log.Debugf("disable_cooling toggled to %t", cfg.DisableCooling)
 		}
 	}
-	if s.handlers != nil && s.handlers.AuthManager != nil {
-		s.handlers.AuthManager.SetRetryConfig(cfg.RequestRetry, time.Duration(cfg.MaxRetryInterval)*time.Second)
-	}
+	s.clientConcurrency.SetConfig(cfg.ClientConcurrency)
+	s.globalRateLimit.SetCap(cfg.GlobalRateLimitRPM)
+	s.upstreamAudit.SetConfig(cfg.UpstreamAuditLog)
+	s.deadLetter.SetConfig(cfg.DeadLetter)
+	s.requestDeadline.SetConfig(cfg.RequestDeadline)
 
 	// Update log level dynamically when debug flag changes
 	if oldCfg == nil || oldCfg.Debug != cfg.Debug {
@@ -939,6 +1376,9 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 	if s.mgmt != nil {
 		s.mgmt.SetConfig(cfg)
 		s.mgmt.SetAuthManager(s.handlers.AuthManager)
+		s.mgmt.SetHealthHistory(cfg.HealthHistory)
+		s.mgmt.SetCanary(cfg.Canary)
+		s.mgmt.SetAccountWebhook(cfg.AccountWebhook)
 	}
 
 	// Count client sources from configuration and auth directory
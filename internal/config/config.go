@@ -52,9 +52,138 @@ type Config struct {
 	// DisableCooling disables quota cooldown scheduling when true.
 	DisableCooling bool `yaml:"disable-cooling" json:"disable-cooling"`
 
+	// DisableStreamRetry turns off failover for streams that fail before their first byte reached the client.
+	DisableStreamRetry bool `yaml:"disable-stream-retry" json:"disable-stream-retry"`
+
+	// ClientErrorStatuses lists upstream statuses caused by the client's request (default 400, 422).
+	// They are returned as-is without failover and never count against the account.
+	ClientErrorStatuses []int `yaml:"client-error-statuses,omitempty" json:"client-error-statuses,omitempty"`
+
+	// EagerRefreshSeconds refreshes a selected account's token before dispatch when it has less than
+	// this many seconds of validity left. Zero disables eager refresh.
+	EagerRefreshSeconds int `yaml:"eager-refresh-seconds,omitempty" json:"eager-refresh-seconds,omitempty"`
+
+	// AccountConcurrencyQueueSeconds is how long a request waits for a free account when every eligible
+	// account is at its max_concurrent limit (default 30). A negative value fails such requests at once.
+	AccountConcurrencyQueueSeconds int `yaml:"account-concurrency-queue-seconds,omitempty" json:"account-concurrency-queue-seconds,omitempty"`
+
+	// TokenExpiryPenalty makes selection mildly prefer accounts whose token is further from expiry.
+	TokenExpiryPenalty TokenExpiryPenalty `yaml:"token-expiry-penalty" json:"token-expiry-penalty"`
+
+	// GatedFeatures lists experimental features (eager-refresh, prefix-affinity) that only apply to
+	// accounts enabling them with a feature flag.
+	GatedFeatures []string `yaml:"gated-features,omitempty" json:"gated-features,omitempty"`
+
+	// RefreshStartupStaggerSeconds spreads the token refreshes due at startup over this many seconds
+	// instead of firing them all at once. 0 disables the stagger.
+	RefreshStartupStaggerSeconds int `yaml:"refresh-startup-stagger-seconds,omitempty" json:"refresh-startup-stagger-seconds,omitempty"`
+
+	// RefreshConcurrency caps concurrent token refreshes per provider.
+	RefreshConcurrency RefreshConcurrency `yaml:"refresh-concurrency" json:"refresh-concurrency"`
+
 	// QuotaExceeded defines the behavior when a quota is exceeded.
 	QuotaExceeded QuotaExceeded `yaml:"quota-exceeded" json:"quota-exceeded"`
 
+	// BillingSuspension configures automatic suspension of accounts rejected by upstream billing.
+	BillingSuspension BillingSuspension `yaml:"billing-suspension" json:"billing-suspension"`
+
+	// EgressBlock configures detection of accounts refused because of the egress IP or region.
+	EgressBlock EgressBlock `yaml:"egress-block" json:"egress-block"`
+
+	// Maintenance configures graceful handling of upstream maintenance responses.
+	Maintenance Maintenance `yaml:"maintenance" json:"maintenance"`
+
+	// CircuitBreaker configures per-provider circuit breaking and half-open probing.
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuit-breaker" json:"circuit-breaker"`
+
+	// SoftRotation rests healthy accounts after a number of served requests to spread load.
+	SoftRotation SoftRotation `yaml:"soft-rotation" json:"soft-rotation"`
+
+	// ClientConcurrency caps simultaneous in-flight requests per client API key.
+	ClientConcurrency ClientConcurrency `yaml:"client-concurrency" json:"client-concurrency"`
+
+	// UpstreamAuditLog writes one structured JSON line per proxied request for auditing.
+	UpstreamAuditLog UpstreamAuditLog `yaml:"upstream-audit-log" json:"upstream-audit-log"`
+
+	// DeadLetter captures requests that failed on every attempted account.
+	DeadLetter DeadLetter `yaml:"dead-letter" json:"dead-letter"`
+
+	// GlobalRateLimitRPM caps requests per minute across all clients and providers; 0 is uncapped.
+	GlobalRateLimitRPM int `yaml:"global-rate-limit-rpm,omitempty" json:"global-rate-limit-rpm,omitempty"`
+
+	// RequestDeadline lets clients bound a request's whole lifecycle with a deadline header.
+	RequestDeadline RequestDeadline `yaml:"request-deadline" json:"request-deadline"`
+
+	// Reaper configures the background reconciler for stale accounts and orphaned runtime state.
+	Reaper ReaperConfig `yaml:"reaper" json:"reaper"`
+
+	// Batching combines opted-in non-streaming requests into upstream batch calls.
+	Batching Batching `yaml:"batching" json:"batching"`
+
+	// PeakReserve holds tagged reserve accounts back until scheduled peak windows.
+	PeakReserve PeakReserve `yaml:"peak-reserve" json:"peak-reserve"`
+
+	// ModelRoutes sends requests for matching model names to a preferred provider or account pool.
+	ModelRoutes []ModelRoute `yaml:"model-routes,omitempty" json:"model-routes,omitempty"`
+
+	// DuplicateCredentials controls how accounts loaded with the same credential are handled.
+	DuplicateCredentials DuplicateCredentials `yaml:"duplicate-credentials" json:"duplicate-credentials"`
+
+	// EmptyResponse controls how successful upstream responses without any content are handled.
+	EmptyResponse EmptyResponse `yaml:"empty-response" json:"empty-response"`
+
+	// LiveUsage reports running token estimates of in-flight streams on the accounts monitor stream.
+	LiveUsage LiveUsage `yaml:"live-usage" json:"live-usage"`
+
+	// RecoveryHistorySize is how many retry attempts of backing-off accounts are kept per account
+	// for the accounts monitor (default 10).
+	RecoveryHistorySize int `yaml:"recovery-history-size,omitempty" json:"recovery-history-size,omitempty"`
+
+	// QuotaBackoff shapes the cooldown escalation after repeated quota errors, per provider.
+	QuotaBackoff QuotaBackoff `yaml:"quota-backoff" json:"quota-backoff"`
+
+	// ImageTranslation controls how image content is carried between provider formats.
+	ImageTranslation ImageTranslation `yaml:"image-translation" json:"image-translation"`
+
+	// MetricsPrefix prefixes the metric names exported at /v0/management/metrics (default "cliproxy").
+	MetricsPrefix string `yaml:"metrics-prefix,omitempty" json:"metrics-prefix,omitempty"`
+
+	// MetricsHashAccountIDs replaces account ids in metric labels with a short hash of the id.
+	MetricsHashAccountIDs bool `yaml:"metrics-hash-account-ids,omitempty" json:"metrics-hash-account-ids,omitempty"`
+
+	// Sharding splits the account pool across instances by consistent hashing.
+	Sharding Sharding `yaml:"sharding" json:"sharding"`
+
+	// SelectionStrategy picks the account selector: "round-robin" (default), "weighted-random",
+	// "family-balanced", "least-recently-used" or "least-loaded". Empty keeps the selector the service
+	// was built with.
+	SelectionStrategy string `yaml:"selection-strategy,omitempty" json:"selection-strategy,omitempty"`
+
+	// RequestCaps prefers accounts with the most headroom against soft daily and monthly caps.
+	RequestCaps RequestCaps `yaml:"request-caps" json:"request-caps"`
+
+	// RateLimitHeaders tracks remaining-quota headers on successful responses and cordons accounts
+	// about to run out before they are rate limited.
+	RateLimitHeaders RateLimitHeaders `yaml:"rate-limit-headers" json:"rate-limit-headers"`
+
+	// Drill allows failover drills at POST /v0/management/drill; meant for dev and staging deployments.
+	Drill Drill `yaml:"drill" json:"drill"`
+
+	// HealthHistory samples per-provider pool health into a bounded time-series store.
+	HealthHistory HealthHistory `yaml:"health-history" json:"health-history"`
+
+	// Canary periodically sends fixed prompts to providers and flags responses drifting from a baseline.
+	Canary Canary `yaml:"canary" json:"canary"`
+
+	// AccountWebhook posts account state transitions (cooldown, error, disabled) to an external URL.
+	AccountWebhook AccountWebhook `yaml:"account-webhook" json:"account-webhook"`
+
+	// ProviderStreamInterval sets the emission interval in seconds for the provider aggregate SSE stream (default 5).
+	ProviderStreamInterval int `yaml:"provider-stream-interval" json:"provider-stream-interval"`
+
+	// RuntimeState configures persistence of account runtime state (cooldowns, status) across restarts.
+	RuntimeState RuntimeStateConfig `yaml:"runtime-state" json:"runtime-state"`
+
 	// WebsocketAuth enables or disables authentication for the WebSocket API.
 	WebsocketAuth bool `yaml:"ws-auth" json:"ws-auth"`
 
@@ -118,12 +247,490 @@ type QuotaExceeded struct {
 	SwitchPreviewModel bool `yaml:"switch-preview-model" json:"switch-preview-model"`
 }
 
+// BillingSuspension configures detection of billing-level upstream errors such as inactive billing or suspended accounts.
+type BillingSuspension struct {
+	// Enabled moves matching accounts into the billing_suspended status instead of retrying them.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Patterns maps provider keys to case-insensitive error message fragments, extending the built-in defaults.
+	// The "*" key applies to every provider.
+	Patterns map[string][]string `yaml:"patterns,omitempty" json:"patterns,omitempty"`
+}
+
+// EgressBlock configures detection of region or IP block errors. Blocked accounts switch to the
+// "fallback_proxy_url" of their auth file when one is set, otherwise they are parked in the
+// egress_blocked status.
+type EgressBlock struct {
+	// Enabled turns detection on.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Patterns maps provider keys to case-insensitive error message fragments, extending the built-in defaults.
+	// The "*" key applies to every provider.
+	Patterns map[string][]string `yaml:"patterns,omitempty" json:"patterns,omitempty"`
+
+	// CooldownMinutes is how long a blocked account is skipped before it is tried again (default 30).
+	CooldownMinutes int `yaml:"cooldown-minutes" json:"cooldown-minutes"`
+}
+
+// Maintenance configures detection of upstream "under maintenance" 503 responses.
+type Maintenance struct {
+	// Enabled applies a fixed cooldown to matching accounts instead of the regular error handling.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Patterns maps provider keys to case-insensitive response body fragments, extending the built-in defaults.
+	// The "*" key applies to every provider.
+	Patterns map[string][]string `yaml:"patterns,omitempty" json:"patterns,omitempty"`
+
+	// CooldownSeconds is the fixed per-account cooldown (default 120).
+	CooldownSeconds int `yaml:"cooldown-seconds" json:"cooldown-seconds"`
+
+	// ProviderThreshold is how many distinct accounts must report maintenance within
+	// ProviderWindowSeconds to pause the whole provider (default 3).
+	ProviderThreshold int `yaml:"provider-threshold" json:"provider-threshold"`
+
+	// ProviderWindowSeconds is the window for counting maintenance reports (default 60).
+	ProviderWindowSeconds int `yaml:"provider-window-seconds" json:"provider-window-seconds"`
+
+	// ProviderOpenSeconds is how long the provider stays paused (default 60).
+	ProviderOpenSeconds int `yaml:"provider-open-seconds" json:"provider-open-seconds"`
+}
+
+// QuotaBackoff configures the quota cooldown curves. Zero fields of Default keep the built-in curve
+// (1s doubling up to 30m) and zero fields of a provider curve inherit Default.
+type QuotaBackoff struct {
+	// Default applies to providers without their own curve.
+	Default BackoffCurve `yaml:"default" json:"default"`
+
+	// Providers overrides the curve per provider, keyed by provider name (e.g. "gemini", "claude").
+	Providers map[string]BackoffCurve `yaml:"providers,omitempty" json:"providers,omitempty"`
+}
+
+// BackoffCurve describes one cooldown escalation: level n waits base*multiplier^n, capped at max.
+type BackoffCurve struct {
+	// BaseSeconds is the first cooldown.
+	BaseSeconds float64 `yaml:"base-seconds,omitempty" json:"base-seconds,omitempty"`
+
+	// Multiplier grows the cooldown per backoff level; it must be at least 1.
+	Multiplier float64 `yaml:"multiplier,omitempty" json:"multiplier,omitempty"`
+
+	// MaxSeconds caps the cooldown.
+	MaxSeconds float64 `yaml:"max-seconds,omitempty" json:"max-seconds,omitempty"`
+
+	// Jitter varies each cooldown randomly by up to this fraction, in [0, 1).
+	Jitter float64 `yaml:"jitter,omitempty" json:"jitter,omitempty"`
+}
+
+// Validate rejects curves that do not escalate or are out of range.
+func (c BackoffCurve) Validate() error {
+	switch {
+	case c.BaseSeconds < 0:
+		return fmt.Errorf("base-seconds %v is negative", c.BaseSeconds)
+	case c.MaxSeconds < 0:
+		return fmt.Errorf("max-seconds %v is negative", c.MaxSeconds)
+	case c.Multiplier != 0 && c.Multiplier < 1:
+		return fmt.Errorf("multiplier %v is below 1", c.Multiplier)
+	case c.Jitter < 0 || c.Jitter >= 1:
+		return fmt.Errorf("jitter %v is outside [0, 1)", c.Jitter)
+	case c.BaseSeconds > 0 && c.MaxSeconds > 0 && c.MaxSeconds < c.BaseSeconds:
+		return fmt.Errorf("max-seconds %v is below base-seconds %v", c.MaxSeconds, c.BaseSeconds)
+	}
+	return nil
+}
+
+// Validate checks the default and every provider curve.
+func (q QuotaBackoff) Validate() error {
+	if err := q.Default.Validate(); err != nil {
+		return fmt.Errorf("default: %w", err)
+	}
+	for provider, curve := range q.Providers {
+		if err := curve.Validate(); err != nil {
+			return fmt.Errorf("providers.%s: %w", provider, err)
+		}
+	}
+	return nil
+}
+
+// ModelRoute maps model names matching Model (a glob) or Regex to a preferred account pool, selected
+// by provider and/or account tag.
+type ModelRoute struct {
+	// Model is a glob where '*' matches any run of characters and '?' a single character.
+	Model string `yaml:"model,omitempty" json:"model,omitempty"`
+
+	// Regex is a regular expression matched against the model name; use instead of Model.
+	Regex string `yaml:"regex,omitempty" json:"regex,omitempty"`
+
+	// Provider restricts the pool to accounts of this provider.
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+
+	// Tag restricts the pool to accounts carrying this tag.
+	Tag string `yaml:"tag,omitempty" json:"tag,omitempty"`
+
+	// Fallback uses the default pool when no routed account is available instead of failing.
+	Fallback bool `yaml:"fallback" json:"fallback"`
+}
+
+// PeakReserve configures schedule-driven pre-scaling. Accounts carrying the reserve tag are kept out
+// of selection except during the configured windows; shortly before a window starts they are released
+// and their tokens refreshed.
+type PeakReserve struct {
+	// Enabled turns pre-scaling on.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Tag marks reserve accounts (default "peak-reserve").
+	Tag string `yaml:"tag,omitempty" json:"tag,omitempty"`
+
+	// Timezone is the IANA zone the windows are defined in, e.g. "Europe/Berlin" (default UTC).
+	Timezone string `yaml:"timezone,omitempty" json:"timezone,omitempty"`
+
+	// LeadMinutes releases and pre-refreshes reserves this many minutes before each window.
+	LeadMinutes int `yaml:"lead-minutes" json:"lead-minutes"`
+
+	// Windows lists the recurring peak windows.
+	Windows []PeakWindow `yaml:"windows,omitempty" json:"windows,omitempty"`
+}
+
+// RequestCaps configures soft per-account request caps. Accounts override the defaults with the
+// "daily_cap" and "monthly_cap" attributes; caps only steer selection and are never enforced.
+type RequestCaps struct {
+	// Enabled turns headroom-based selection preference on.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Daily is the default number of requests per account per day; zero leaves days uncapped.
+	Daily int64 `yaml:"daily,omitempty" json:"daily,omitempty"`
+
+	// Monthly is the default number of requests per account per month; zero leaves months uncapped.
+	Monthly int64 `yaml:"monthly,omitempty" json:"monthly,omitempty"`
+
+	// Timezone is the IANA zone in which days and months start (default UTC).
+	Timezone string `yaml:"timezone,omitempty" json:"timezone,omitempty"`
+}
+
+// PeakWindow is a recurring local time-of-day window. An end at or before start runs past midnight.
+type PeakWindow struct {
+	// Days limits the window to these weekdays ("mon".."sun"); empty means every day.
+	Days []string `yaml:"days,omitempty" json:"days,omitempty"`
+
+	// Start and End are "HH:MM" local times.
+	Start string `yaml:"start" json:"start"`
+	End   string `yaml:"end" json:"end"`
+}
+
+// Batching configures upstream request batching. Clients opt in per request with the
+// "X-Batch-Mode: true" header; only providers whose executor supports batch calls (currently gemini)
+// are batched, everything else is sent individually.
+type Batching struct {
+	// Enabled turns batching on.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// MaxBatchSize flushes a batch once it holds this many requests (default 16).
+	MaxBatchSize int `yaml:"max-batch-size" json:"max-batch-size"`
+
+	// MaxWaitMs flushes a batch this many milliseconds after its first request (default 5000).
+	MaxWaitMs int `yaml:"max-wait-ms" json:"max-wait-ms"`
+
+	// Providers restricts batching to the listed providers; empty allows all that support it.
+	Providers []string `yaml:"providers,omitempty" json:"providers,omitempty"`
+}
+
+// HealthHistory configures the periodic pool health sampler.
+type HealthHistory struct {
+	// Enabled starts the sampler.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// SampleIntervalSeconds is how often pool health is sampled (default 60).
+	SampleIntervalSeconds int `yaml:"sample-interval-seconds" json:"sample-interval-seconds"`
+
+	// Path, when set, persists the history to this file so it survives restarts.
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+}
+
+// DuplicateCredentials configures detection of accounts sharing one credential.
+type DuplicateCredentials struct {
+	// Mode is "warn" (default: log and flag the duplicate), "merge" (keep the duplicate out of
+	// selection) or "reject" (refuse to load it).
+	Mode string `yaml:"mode" json:"mode"`
+}
+
+// EmptyResponse configures handling of upstream responses that succeed without content.
+type EmptyResponse struct {
+	// Policy is "pass-through" (default: deliver the response as is), "retry" (try another account)
+	// or "error" (fail the request with 502).
+	Policy string `yaml:"policy" json:"policy"`
+}
+
+// TokenExpiryPenalty configures the selection penalty of accounts with near-expiry tokens.
+type TokenExpiryPenalty struct {
+	// WindowMinutes is how long before token expiry the penalty starts; zero disables it.
+	WindowMinutes int `yaml:"window-minutes,omitempty" json:"window-minutes,omitempty"`
+
+	// MaxPenalty is the probability, at expiry, that selection passes the account over in favour of a
+	// fresher one (0 to 0.9); it rises linearly from zero across the window.
+	MaxPenalty float64 `yaml:"max-penalty,omitempty" json:"max-penalty,omitempty"`
+}
+
+// LiveUsage configures running usage reports of in-flight streams.
+type LiveUsage struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// IntervalSeconds is how often running usage is pushed to the accounts monitor stream (default 5).
+	IntervalSeconds int `yaml:"interval-seconds,omitempty" json:"interval-seconds,omitempty"`
+
+	// APIKeys lists client keys whose streams are always reported; other clients opt in per request
+	// with the X-CLIProxy-Live-Usage: true header.
+	APIKeys []string `yaml:"api-keys,omitempty" json:"api-keys,omitempty"`
+}
+
+// ImageTranslation configures image content translation between provider formats.
+type ImageTranslation struct {
+	// DisableURLFetch drops URL-referenced images for providers that only accept inline data
+	// instead of downloading them.
+	DisableURLFetch bool `yaml:"disable-url-fetch" json:"disable-url-fetch"`
+
+	// MaxImageMB caps the size of a translated image in MiB; larger images are dropped (default 20).
+	MaxImageMB int `yaml:"max-image-mb,omitempty" json:"max-image-mb,omitempty"`
+}
+
+// Sharding configures consistent-hash account sharding across proxy instances.
+type Sharding struct {
+	// Enabled limits this instance to the accounts of its shard.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Count is the number of instances sharing the pool.
+	Count int `yaml:"count" json:"count"`
+
+	// Index is this instance's shard, from 0 to count-1.
+	Index int `yaml:"index" json:"index"`
+
+	// VirtualNodes is the number of ring points per shard (default 128).
+	VirtualNodes int `yaml:"virtual-nodes,omitempty" json:"virtual-nodes,omitempty"`
+}
+
+// Canary configures scheduled canary prompts used to detect silent upstream model changes.
+type Canary struct {
+	// Enabled starts the canary job.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// IntervalSeconds is how often every prompt is sent (default 3600).
+	IntervalSeconds int `yaml:"interval-seconds" json:"interval-seconds"`
+
+	// DriftThreshold flags a response once this fraction of its words differs from the baseline (default 0.5).
+	DriftThreshold float64 `yaml:"drift-threshold" json:"drift-threshold"`
+
+	// Path, when set, persists baselines to this file so they survive restarts.
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+
+	// Prompts lists the canary prompts; each is sent to one provider and model.
+	Prompts []CanaryPrompt `yaml:"prompts,omitempty" json:"prompts,omitempty"`
+}
+
+// CanaryPrompt is one fixed prompt sent by the canary job.
+type CanaryPrompt struct {
+	// Name identifies the canary; defaults to "provider/model".
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+
+	Provider string `yaml:"provider" json:"provider"`
+	Model    string `yaml:"model" json:"model"`
+	Prompt   string `yaml:"prompt" json:"prompt"`
+}
+
+// AccountWebhook configures notifications for accounts going down.
+type AccountWebhook struct {
+	// Enabled starts watching account states.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// URL receives a JSON POST per transition.
+	URL string `yaml:"url" json:"url"`
+
+	// Headers are added to every POST, e.g. an Authorization header.
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+
+	// OnRecovery also notifies when an account returns to active.
+	OnRecovery bool `yaml:"on-recovery" json:"on-recovery"`
+
+	// DebounceSeconds is how long a new state must hold before it is reported (default 30).
+	DebounceSeconds int `yaml:"debounce-seconds" json:"debounce-seconds"`
+
+	// MaxRetries bounds redelivery attempts when the endpoint is unreachable or fails (default 3).
+	MaxRetries int `yaml:"max-retries" json:"max-retries"`
+}
+
+// RefreshConcurrency limits how many token refreshes run at once against one provider's auth endpoint.
+type RefreshConcurrency struct {
+	// DefaultLimit applies to providers without an explicit entry; 0 leaves them unlimited.
+	DefaultLimit int `yaml:"default-limit" json:"default-limit"`
+
+	// Limits overrides the default per provider; 0 leaves that provider unlimited.
+	Limits map[string]int `yaml:"limits,omitempty" json:"limits,omitempty"`
+}
+
+// UpstreamAuditLog configures the structured per-request audit log.
+type UpstreamAuditLog struct {
+	// Enabled emits the log lines and the X-Request-ID response header.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Output is "stdout" (default) or a file the lines are appended to.
+	Output string `yaml:"output,omitempty" json:"output,omitempty"`
+}
+
+// DeadLetter configures the dead-letter sink for requests that exhausted every account.
+type DeadLetter struct {
+	// Enabled records fully failed requests.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Output is a file the records are appended to, or "stdout".
+	Output string `yaml:"output,omitempty" json:"output,omitempty"`
+
+	// URL receives each record as a JSON POST.
+	URL string `yaml:"url,omitempty" json:"url,omitempty"`
+
+	// Headers are added to every POST, e.g. an Authorization header.
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+}
+
+// ClientConcurrency limits how many requests one client API key may have in flight.
+type ClientConcurrency struct {
+	// Enabled turns the per-key limits on.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// DefaultLimit applies to keys without an explicit entry; 0 leaves them unlimited.
+	DefaultLimit int `yaml:"default-limit" json:"default-limit"`
+
+	// Limits overrides the default per client API key; 0 leaves that key unlimited.
+	Limits map[string]int `yaml:"limits,omitempty" json:"limits,omitempty"`
+
+	// Mode is "reject" (answer 429 immediately, default) or "queue" (wait for a free slot).
+	Mode string `yaml:"mode" json:"mode"`
+
+	// QueueTimeoutSeconds bounds how long a queued request waits before 429 (default 30).
+	QueueTimeoutSeconds int `yaml:"queue-timeout-seconds" json:"queue-timeout-seconds"`
+}
+
+// RequestDeadline configures the client deadline header. The deadline bounds queueing, account
+// selection, retries and the upstream call; requests that run out of time get 504.
+type RequestDeadline struct {
+	// Enabled turns the header on.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Header names the deadline header (default "X-Deadline"). Values are an absolute RFC3339
+	// timestamp or a relative duration such as "2.5s" or "30" (seconds).
+	Header string `yaml:"header,omitempty" json:"header,omitempty"`
+
+	// MaxSeconds caps how far in the future a client deadline may be; 0 leaves it uncapped.
+	MaxSeconds int `yaml:"max-seconds" json:"max-seconds"`
+}
+
+// ReaperConfig schedules the account reconciler.
+type ReaperConfig struct {
+	// Enabled runs the reconciler in the background; manual runs via the management API work regardless.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// IntervalSeconds is the time between background passes (default 600).
+	IntervalSeconds int `yaml:"interval-seconds" json:"interval-seconds"`
+
+	// StaleAfterHours disables accounts that kept failing for this long (default 24).
+	StaleAfterHours int `yaml:"stale-after-hours" json:"stale-after-hours"`
+
+	// PruneAfterHours drops runtime state of removed accounts untouched for this long (default 168).
+	PruneAfterHours int `yaml:"prune-after-hours" json:"prune-after-hours"`
+}
+
+// SoftRotation configures forced rotation off accounts that served many requests in a row.
+type SoftRotation struct {
+	// Enabled turns soft rotation on.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Requests is the number of requests an account serves before resting.
+	Requests int `yaml:"requests" json:"requests"`
+
+	// RestSeconds is how long a rotated account is deprioritized for selection.
+	RestSeconds int `yaml:"rest-seconds" json:"rest-seconds"`
+}
+
+// RateLimitHeaders configures proactive cordoning from x-ratelimit-remaining style headers.
+type RateLimitHeaders struct {
+	// Enabled turns header tracking and cordoning on.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// CordonThreshold cordons an account once its remaining requests drop to this value or below (default 1).
+	CordonThreshold int `yaml:"cordon-threshold" json:"cordon-threshold"`
+
+	// FallbackCordonSeconds is how long an account stays cordoned when no reset header is sent (default 60).
+	FallbackCordonSeconds int `yaml:"fallback-cordon-seconds" json:"fallback-cordon-seconds"`
+
+	// MaxCordonSeconds caps cordons timed by a reset header (default 3600).
+	MaxCordonSeconds int `yaml:"max-cordon-seconds" json:"max-cordon-seconds"`
+}
+
+// Drill gates the failover drill endpoint.
+type Drill struct {
+	// Enabled allows drills to run.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// MaxDurationSeconds bounds how long a single drill may cordon accounts (default 600).
+	MaxDurationSeconds int `yaml:"max-duration-seconds" json:"max-duration-seconds"`
+}
+
+// CircuitBreakerConfig enables provider circuit breakers with default and per-provider settings.
+type CircuitBreakerConfig struct {
+	// Enabled turns circuit breaking on for all providers.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Default applies to providers without an entry in Providers.
+	Default CircuitBreakerSettings `yaml:"default" json:"default"`
+
+	// Providers overrides the default settings per provider key (e.g. "claude", "gemini").
+	Providers map[string]CircuitBreakerSettings `yaml:"providers,omitempty" json:"providers,omitempty"`
+}
+
+// CircuitBreakerSettings tunes a single provider breaker. Zero values use the built-in defaults.
+type CircuitBreakerSettings struct {
+	// FailureThreshold is the number of consecutive upstream failures that opens the circuit (default 5).
+	FailureThreshold int `yaml:"failure-threshold" json:"failure-threshold"`
+
+	// OpenSeconds is how long the circuit stays open before probing starts (default 30).
+	OpenSeconds int `yaml:"open-seconds" json:"open-seconds"`
+
+	// ProbeRequests is the number of trial requests admitted while half-open (default 1).
+	ProbeRequests int `yaml:"probe-requests" json:"probe-requests"`
+
+	// SuccessThreshold is the number of successful probes needed to close the circuit (default: probe-requests).
+	SuccessThreshold int `yaml:"success-threshold" json:"success-threshold"`
+
+	// ProbeIntervalSeconds is the minimum spacing between probe requests (default 0).
+	ProbeIntervalSeconds int `yaml:"probe-interval-seconds" json:"probe-interval-seconds"`
+
+	// WindowSeconds restarts the failure count when the previous failure is older than this many
+	// seconds, so only a burst of failures opens the circuit (default 0: failures never expire).
+	WindowSeconds int `yaml:"window-seconds,omitempty" json:"window-seconds,omitempty"`
+}
+
+// RuntimeStateConfig selects the backend persisting account runtime state.
+type RuntimeStateConfig struct {
+	// Backend is "file", "sqlite", or empty to keep runtime state in memory only.
+	Backend string `yaml:"backend" json:"backend"`
+
+	// Path overrides the backend location; defaults to runtime-state.json or runtime-state.db inside auth-dir.
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+
+	// FlushIntervalSeconds debounces writes so state changes are persisted in batches (default 2).
+	FlushIntervalSeconds int `yaml:"flush-interval-seconds,omitempty" json:"flush-interval-seconds,omitempty"`
+
+	// PersistStats also persists each account's request and token counters.
+	PersistStats bool `yaml:"persist-stats,omitempty" json:"persist-stats,omitempty"`
+
+	// Active reports the backend in use at runtime; it is populated by the management API only.
+	Active string `yaml:"-" json:"active,omitempty"`
+}
+
 // PayloadConfig defines default and override parameter rules applied to provider payloads.
 type PayloadConfig struct {
 	// Default defines rules that only set parameters when they are missing in the payload.
 	Default []PayloadRule `yaml:"default" json:"default"`
 	// Override defines rules that always set parameters, overwriting any existing values.
 	Override []PayloadRule `yaml:"override" json:"override"`
+	// ProviderDefaults maps a provider identifier to parameters filled in when the client and the
+	// model default rules left them unset. Paths use the provider's native payload format.
+	ProviderDefaults map[string]map[string]any `yaml:"provider-defaults,omitempty" json:"provider-defaults,omitempty"`
 }
 
 // PayloadRule describes a single rule targeting a list of models with parameter updates.
@@ -161,6 +768,9 @@ type ClaudeKey struct {
 	// Headers optionally adds extra HTTP headers for requests sent with this key.
 	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
 
+	// BillingHeaders adds cost-center or project tags that the provider uses for billing attribution.
+	BillingHeaders map[string]string `yaml:"billing-headers,omitempty" json:"billing-headers,omitempty"`
+
 	// ExcludedModels lists model IDs that should be excluded for this provider.
 	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
 }
@@ -190,6 +800,9 @@ type CodexKey struct {
 	// Headers optionally adds extra HTTP headers for requests sent with this key.
 	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
 
+	// BillingHeaders adds cost-center or project tags that the provider uses for billing attribution.
+	BillingHeaders map[string]string `yaml:"billing-headers,omitempty" json:"billing-headers,omitempty"`
+
 	// ExcludedModels lists model IDs that should be excluded for this provider.
 	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
 }
@@ -209,6 +822,9 @@ type GeminiKey struct {
 	// Headers optionally adds extra HTTP headers for requests sent with this key.
 	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
 
+	// BillingHeaders adds cost-center or project tags that the provider uses for billing attribution.
+	BillingHeaders map[string]string `yaml:"billing-headers,omitempty" json:"billing-headers,omitempty"`
+
 	// ExcludedModels lists model IDs that should be excluded for this provider.
 	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
 }
@@ -234,6 +850,9 @@ type OpenAICompatibility struct {
 
 	// Headers optionally adds extra HTTP headers for requests sent to this provider.
 	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+
+	// BillingHeaders adds cost-center or project tags that the provider uses for billing attribution.
+	BillingHeaders map[string]string `yaml:"billing-headers,omitempty" json:"billing-headers,omitempty"`
 }
 
 // OpenAICompatibilityAPIKey represents an API key configuration with optional proxy setting.
@@ -297,6 +916,7 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 	cfg.UsageStatisticsEnabled = false
 	cfg.DisableCooling = false
 	cfg.AmpRestrictManagementToLocalhost = true // Default to secure: only localhost access
+	cfg.MaskPoolDetails = true                  // Default to safe: no pool internals in client errors
 	if err = yaml.Unmarshal(data, &cfg); err != nil {
 		if optional {
 			// In cloud deploy mode, if YAML parsing fails, return empty config instead of error.
@@ -319,6 +939,10 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 		_ = SaveConfigPreserveCommentsUpdateNestedScalar(configFile, []string{"remote-management", "secret-key"}, hashed)
 	}
 
+	if err = cfg.QuotaBackoff.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid quota-backoff: %w", err)
+	}
+
 	// Sync request authentication providers with inline API keys for backwards compatibility.
 	syncInlineAccessProvider(&cfg)
 
@@ -334,6 +958,9 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 	// Sanitize OpenAI compatibility providers: drop entries without base-url
 	cfg.SanitizeOpenAICompatibility()
 
+	// Normalize provider default payload keys.
+	cfg.Payload.ProviderDefaults = NormalizeProviderDefaults(cfg.Payload.ProviderDefaults)
+
 	// Normalize OAuth provider model exclusion map.
 	cfg.OAuthExcludedModels = NormalizeOAuthExcludedModels(cfg.OAuthExcludedModels)
 
@@ -364,6 +991,7 @@ func (cfg *Config) SanitizeOpenAICompatibility() {
 		e.Name = strings.TrimSpace(e.Name)
 		e.BaseURL = strings.TrimSpace(e.BaseURL)
 		e.Headers = NormalizeHeaders(e.Headers)
+		e.BillingHeaders = NormalizeHeaders(e.BillingHeaders)
 		if e.BaseURL == "" {
 			// Skip providers with no base-url; treated as removed
 			continue
@@ -384,6 +1012,7 @@ func (cfg *Config) SanitizeCodexKeys() {
 		e := cfg.CodexKey[i]
 		e.BaseURL = strings.TrimSpace(e.BaseURL)
 		e.Headers = NormalizeHeaders(e.Headers)
+		e.BillingHeaders = NormalizeHeaders(e.BillingHeaders)
 		e.ExcludedModels = NormalizeExcludedModels(e.ExcludedModels)
 		if e.BaseURL == "" {
 			continue
@@ -401,6 +1030,7 @@ func (cfg *Config) SanitizeClaudeKeys() {
 	for i := range cfg.ClaudeKey {
 		entry := &cfg.ClaudeKey[i]
 		entry.Headers = NormalizeHeaders(entry.Headers)
+		entry.BillingHeaders = NormalizeHeaders(entry.BillingHeaders)
 		entry.ExcludedModels = NormalizeExcludedModels(entry.ExcludedModels)
 	}
 }
@@ -422,6 +1052,7 @@ func (cfg *Config) SanitizeGeminiKeys() {
 		entry.BaseURL = strings.TrimSpace(entry.BaseURL)
 		entry.ProxyURL = strings.TrimSpace(entry.ProxyURL)
 		entry.Headers = NormalizeHeaders(entry.Headers)
+		entry.BillingHeaders = NormalizeHeaders(entry.BillingHeaders)
 		entry.ExcludedModels = NormalizeExcludedModels(entry.ExcludedModels)
 		if _, exists := seen[entry.APIKey]; exists {
 			continue
@@ -465,6 +1096,25 @@ func looksLikeBcrypt(s string) bool {
 	return len(s) > 4 && (s[:4] == "$2a$" || s[:4] == "$2b$" || s[:4] == "$2y$")
 }
 
+// NormalizeProviderDefaults lowercases provider keys and drops providers without parameters.
+func NormalizeProviderDefaults(defaults map[string]map[string]any) map[string]map[string]any {
+	if len(defaults) == 0 {
+		return nil
+	}
+	out := make(map[string]map[string]any, len(defaults))
+	for provider, params := range defaults {
+		key := strings.ToLower(strings.TrimSpace(provider))
+		if key == "" || len(params) == 0 {
+			continue
+		}
+		out[key] = params
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
 // NormalizeHeaders trims header keys and values and removes empty pairs.
 func NormalizeHeaders(headers map[string]string) map[string]string {
 	if len(headers) == 0 {
@@ -0,0 +1,280 @@
+// Package logging provides request logging functionality for the CLI Proxy API server.
+// This file contains the sampled request recorder producing replayable JSON Lines fixtures.
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// RecordingSampleKey is the Gin context key holding the client side of a sampled request.
+	RecordingSampleKey = "REQUEST_RECORDING_SAMPLE"
+
+	defaultRecordingPath       = "recordings/requests.jsonl"
+	defaultRecordingSampleRate = 0.01
+	defaultRecordingMaxFileMB  = 50
+	defaultRecordingMaxBackups = 3
+	redactedValue              = "[REDACTED]"
+)
+
+// alwaysRedactedFields are JSON body keys whose values never reach a recording.
+var alwaysRedactedFields = []string{"api_key", "apikey", "key", "access_token", "refresh_token", "id_token", "client_secret", "password", "authorization"}
+
+// RecordingSample is the client side of a request selected for recording.
+type RecordingSample struct {
+	Format string
+	Path   string
+	Model  string
+	APIKey string
+	Body   []byte
+}
+
+// UpstreamRecording describes one provider-native attempt of a sampled request.
+type UpstreamRecording struct {
+	Provider string
+	AuthID   string
+	Method   string
+	URL      string
+	Headers  http.Header
+	Body     []byte
+}
+
+// recordedRequest is one JSON line of the recording file. Replaying sends Client.Body to Client.Path
+// and comparing against Upstream.Body checks translation.
+type recordedRequest struct {
+	Timestamp time.Time               `json:"timestamp"`
+	Client    recordedClient          `json:"client"`
+	Upstream  recordedRequestUpstream `json:"upstream"`
+}
+
+type recordedClient struct {
+	Format string          `json:"format"`
+	Path   string          `json:"path,omitempty"`
+	Model  string          `json:"model,omitempty"`
+	APIKey string          `json:"api_key,omitempty"`
+	Body   json.RawMessage `json:"body"`
+}
+
+type recordedRequestUpstream struct {
+	Provider string              `json:"provider"`
+	AuthID   string              `json:"auth_id,omitempty"`
+	Method   string              `json:"method,omitempty"`
+	URL      string              `json:"url,omitempty"`
+	Headers  map[string][]string `json:"headers,omitempty"`
+	Body     json.RawMessage     `json:"body"`
+}
+
+// SampleRecording marks the request for recording when recording covers the client key and the
+// request falls within the sample rate.
+func SampleRecording(c *gin.Context, cfg sdkconfig.RequestRecording, sample RecordingSample) {
+	if c == nil || !cfg.RecordsKey(sample.APIKey) {
+		return
+	}
+	rate := cfg.SampleRate
+	if rate <= 0 {
+		rate = defaultRecordingSampleRate
+	}
+	if rate < 1 && rand.Float64() >= rate {
+		return
+	}
+	sample.Body = append([]byte(nil), sample.Body...)
+	if sample.Path == "" && c.Request != nil {
+		sample.Path = c.Request.URL.Path
+	}
+	c.Set(RecordingSampleKey, &sample)
+}
+
+// RecordUpstreamRequest writes the sampled request together with an upstream attempt. Requests not
+// selected by SampleRecording and accounts outside the configured scope are ignored.
+func RecordUpstreamRequest(c *gin.Context, cfg sdkconfig.RequestRecording, upstream UpstreamRecording) {
+	if c == nil || !cfg.Enabled || !cfg.RecordsAccount(upstream.AuthID) {
+		return
+	}
+	v, ok := c.Get(RecordingSampleKey)
+	if !ok {
+		return
+	}
+	sample, ok := v.(*RecordingSample)
+	if !ok || sample == nil {
+		return
+	}
+	redact := redactionSet(cfg.RedactFields)
+	rec := recordedRequest{
+		Timestamp: time.Now().UTC(),
+		Client: recordedClient{
+			Format: sample.Format,
+			Path:   sample.Path,
+			Model:  sample.Model,
+			APIKey: util.HideAPIKey(sample.APIKey),
+			Body:   redactBody(sample.Body, redact),
+		},
+		Upstream: recordedRequestUpstream{
+			Provider: upstream.Provider,
+			AuthID:   upstream.AuthID,
+			Method:   upstream.Method,
+			URL:      redactURL(upstream.URL),
+			Headers:  redactHeaders(upstream.Headers),
+			Body:     redactBody(upstream.Body, redact),
+		},
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		log.Debugf("request recording: marshal failed: %v", err)
+		return
+	}
+	if err = defaultRequestRecorder.write(cfg, append(line, '\n')); err != nil {
+		log.Warnf("request recording: %v", err)
+	}
+}
+
+func redactionSet(extra []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(alwaysRedactedFields)+len(extra))
+	for _, field := range alwaysRedactedFields {
+		set[field] = struct{}{}
+	}
+	for _, field := range extra {
+		if field = strings.ToLower(strings.TrimSpace(field)); field != "" {
+			set[field] = struct{}{}
+		}
+	}
+	return set
+}
+
+// redactBody replaces the values of redacted keys anywhere in a JSON body. Bodies that are not JSON
+// are kept as a JSON string.
+func redactBody(body []byte, redact map[string]struct{}) json.RawMessage {
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		quoted, _ := json.Marshal(string(body))
+		return quoted
+	}
+	out, err := json.Marshal(redactValue(doc, redact))
+	if err != nil {
+		return json.RawMessage(`null`)
+	}
+	return out
+}
+
+func redactValue(v any, redact map[string]struct{}) any {
+	switch typed := v.(type) {
+	case map[string]any:
+		for key, value := range typed {
+			if _, ok := redact[strings.ToLower(key)]; ok {
+				typed[key] = redactedValue
+				continue
+			}
+			typed[key] = redactValue(value, redact)
+		}
+	case []any:
+		for i, value := range typed {
+			typed[i] = redactValue(value, redact)
+		}
+	}
+	return v
+}
+
+func redactHeaders(headers http.Header) map[string][]string {
+	if len(headers) == 0 {
+		return nil
+	}
+	out := make(map[string][]string, len(headers))
+	for key, values := range headers {
+		masked := make([]string, len(values))
+		for i, value := range values {
+			masked[i] = util.MaskSensitiveHeaderValue(key, value)
+		}
+		out[key] = masked
+	}
+	return out
+}
+
+func redactURL(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	parsed.User = nil
+	parsed.RawQuery = util.MaskSensitiveQuery(parsed.RawQuery)
+	return parsed.String()
+}
+
+// requestRecorder appends recordings to a size-capped file with numbered backups.
+type requestRecorder struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	size int64
+}
+
+var defaultRequestRecorder = &requestRecorder{}
+
+func (r *requestRecorder) write(cfg sdkconfig.RequestRecording, line []byte) error {
+	path := strings.TrimSpace(cfg.Path)
+	if path == "" {
+		path = defaultRecordingPath
+	}
+	maxBytes := int64(cfg.MaxFileMB) << 20
+	if maxBytes <= 0 {
+		maxBytes = defaultRecordingMaxFileMB << 20
+	}
+	backups := cfg.MaxBackups
+	if backups <= 0 {
+		backups = defaultRecordingMaxBackups
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file != nil && r.path != path {
+		_ = r.file.Close()
+		r.file = nil
+	}
+	if r.file != nil && r.size > 0 && r.size+int64(len(line)) > maxBytes {
+		_ = r.file.Close()
+		r.file = nil
+		rotateRecordings(path, backups)
+	}
+	if r.file == nil {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("create recording directory: %w", err)
+		}
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return fmt.Errorf("open recording file: %w", err)
+		}
+		info, err := file.Stat()
+		if err != nil {
+			_ = file.Close()
+			return fmt.Errorf("stat recording file: %w", err)
+		}
+		r.file, r.path, r.size = file, path, info.Size()
+	}
+	n, err := r.file.Write(line)
+	r.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("write recording: %w", err)
+	}
+	return nil
+}
+
+// rotateRecordings shifts path.N-1 to path.N, dropping the oldest, and moves path to path.1.
+func rotateRecordings(path string, backups int) {
+	_ = os.Remove(fmt.Sprintf("%s.%d", path, backups))
+	for i := backups - 1; i >= 1; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+1))
+	}
+	_ = os.Rename(path, path+".1")
+}
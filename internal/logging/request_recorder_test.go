@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func TestRecordUpstreamRequestRedactsAndScopes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "requests.jsonl")
+	cfg := sdkconfig.RequestRecording{Enabled: true, Path: path, SampleRate: 1, APIKeys: []string{"team-a"}, Accounts: []string{"acct-1"}, RedactFields: []string{"user"}}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	SampleRecording(c, cfg, RecordingSample{Format: "openai", Model: "gpt-5", APIKey: "team-a", Body: []byte(`{"model":"gpt-5","user":"alice","messages":[{"role":"user","content":"hi"}]}`)})
+	RecordUpstreamRequest(c, cfg, UpstreamRecording{Provider: "codex", AuthID: "acct-2", Body: []byte(`{}`)})
+	RecordUpstreamRequest(c, cfg, UpstreamRecording{
+		Provider: "codex",
+		AuthID:   "acct-1",
+		Method:   http.MethodPost,
+		URL:      "https://upstream.example/v1/responses?key=secret-value",
+		Headers:  http.Header{"Authorization": {"Bearer sk-abcdefghijklmnop"}},
+		Body:     []byte(`{"input":"hi","access_token":"tok"}`),
+	})
+
+	other, _ := gin.CreateTestContext(httptest.NewRecorder())
+	SampleRecording(other, cfg, RecordingSample{Format: "openai", APIKey: "team-b", Body: []byte(`{}`)})
+	RecordUpstreamRequest(other, cfg, UpstreamRecording{Provider: "codex", AuthID: "acct-1", Body: []byte(`{}`)})
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open recording: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 1 {
+		t.Fatalf("expected exactly one recording, got %d", len(lines))
+	}
+	for _, secret := range []string{"alice", "secret-value", "sk-abcdefghijklmnop", `"tok"`, "team-a"} {
+		if strings.Contains(lines[0], secret) {
+			t.Fatalf("recording leaks %q: %s", secret, lines[0])
+		}
+	}
+	var rec recordedRequest
+	if err = json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if rec.Client.Path != "/v1/chat/completions" || rec.Client.Format != "openai" || rec.Upstream.AuthID != "acct-1" {
+		t.Fatalf("unexpected recording: %+v", rec)
+	}
+	if !strings.Contains(string(rec.Client.Body), `"content":"hi"`) {
+		t.Fatalf("client body must stay replayable: %s", rec.Client.Body)
+	}
+}
+
+func TestRequestRecorderRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "requests.jsonl")
+	cfg := sdkconfig.RequestRecording{Path: path, MaxFileMB: 1, MaxBackups: 2}
+	recorder := &requestRecorder{}
+	line := []byte(strings.Repeat("x", 600<<10) + "\n")
+	for i := 0; i < 4; i++ {
+		if err := recorder.write(cfg, line); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	_ = recorder.file.Close()
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		if _, err := os.Stat(name); err != nil {
+			t.Fatalf("expected %s to exist: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("expected backups beyond max-backups to be dropped")
+	}
+}
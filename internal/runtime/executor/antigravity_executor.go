@@ -126,7 +126,7 @@ func (e *AntigravityExecutor) Execute(ctx context.Context, auth *cliproxyauth.Au
 				log.Debugf("antigravity executor: rate limited on base url %s, retrying with fallback base url: %s", baseURL, baseURLs[idx+1])
 				continue
 			}
-			err = statusErr{code: httpResp.StatusCode, msg: string(bodyBytes)}
+			err = statusErr{code: httpResp.StatusCode, msg: string(bodyBytes), header: httpResp.Header}
 			return resp, err
 		}
 
@@ -223,7 +223,7 @@ func (e *AntigravityExecutor) ExecuteStream(ctx context.Context, auth *cliproxya
 				log.Debugf("antigravity executor: rate limited on base url %s, retrying with fallback base url: %s", baseURL, baseURLs[idx+1])
 				continue
 			}
-			err = statusErr{code: httpResp.StatusCode, msg: string(bodyBytes)}
+			err = statusErr{code: httpResp.StatusCode, msg: string(bodyBytes), header: httpResp.Header}
 			return nil, err
 		}
 
@@ -453,7 +453,7 @@ func (e *AntigravityExecutor) refreshToken(ctx context.Context, auth *cliproxyau
 	}
 
 	if httpResp.StatusCode < http.StatusOK || httpResp.StatusCode >= http.StatusMultipleChoices {
-		return auth, statusErr{code: httpResp.StatusCode, msg: string(bodyBytes)}
+		return auth, statusErr{code: httpResp.StatusCode, msg: string(bodyBytes), header: httpResp.Header}
 	}
 
 	var tokenResp struct {
@@ -55,7 +55,7 @@ func (e *CodexExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, re
 
 	body = e.setReasoningEffortByAlias(req.Model, body)
 
-	body = applyPayloadConfig(e.cfg, req.Model, body)
+	body = applyPayloadConfig(e.cfg, e.Identifier(), req.Model, body)
 
 	body, _ = sjson.SetBytes(body, "stream", true)
 	body, _ = sjson.DeleteBytes(body, "previous_response_id")
@@ -99,7 +99,7 @@ func (e *CodexExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, re
 		b, _ := io.ReadAll(httpResp.Body)
 		appendAPIResponseChunk(ctx, e.cfg, b)
 		log.Debugf("request error, error status: %d, error body: %s", httpResp.StatusCode, summarizeErrorBody(httpResp.Header.Get("Content-Type"), b))
-		err = statusErr{code: httpResp.StatusCode, msg: string(b)}
+		err = statusErr{code: httpResp.StatusCode, msg: string(b), header: httpResp.Header}
 		return resp, err
 	}
 	data, err := io.ReadAll(httpResp.Body)
@@ -115,7 +115,7 @@ func (e *CodexExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, re
 			continue
 		}
 
-		line = bytes.TrimSpace(line[5:])
+		line = codexIncompleteAsCompleted(bytes.TrimSpace(line[5:]))
 		if gjson.GetBytes(line, "type").String() != "response.completed" {
 			continue
 		}
@@ -147,7 +147,7 @@ func (e *CodexExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.Au
 	body := sdktranslator.TranslateRequest(from, to, req.Model, bytes.Clone(req.Payload), true)
 
 	body = e.setReasoningEffortByAlias(req.Model, body)
-	body = applyPayloadConfig(e.cfg, req.Model, body)
+	body = applyPayloadConfig(e.cfg, e.Identifier(), req.Model, body)
 	body, _ = sjson.DeleteBytes(body, "previous_response_id")
 
 	url := strings.TrimSuffix(baseURL, "/") + "/responses"
@@ -192,7 +192,7 @@ func (e *CodexExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.Au
 		}
 		appendAPIResponseChunk(ctx, e.cfg, data)
 		log.Debugf("request error, error status: %d, error body: %s", httpResp.StatusCode, summarizeErrorBody(httpResp.Header.Get("Content-Type"), data))
-		err = statusErr{code: httpResp.StatusCode, msg: string(data)}
+		err = statusErr{code: httpResp.StatusCode, msg: string(data), header: httpResp.Header}
 		return nil, err
 	}
 	out := make(chan cliproxyexecutor.StreamChunk)
@@ -213,6 +213,10 @@ func (e *CodexExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.Au
 
 			if bytes.HasPrefix(line, dataTag) {
 				data := bytes.TrimSpace(line[5:])
+				if gjson.GetBytes(data, "type").String() == "response.incomplete" {
+					data = codexIncompleteAsCompleted(data)
+					line = append([]byte("data: "), data...)
+				}
 				if gjson.GetBytes(data, "type").String() == "response.completed" {
 					if detail, ok := parseCodexUsage(data); ok {
 						reporter.publish(ctx, detail)
@@ -234,6 +238,20 @@ func (e *CodexExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.Au
 	return stream, nil
 }
 
+// codexIncompleteAsCompleted rewrites a response.incomplete event, sent when generation stops early
+// (for example at max_output_tokens), as response.completed so translators emit the partial output.
+// The response status and incomplete_details are kept for finish reason mapping.
+func codexIncompleteAsCompleted(data []byte) []byte {
+	if gjson.GetBytes(data, "type").String() != "response.incomplete" {
+		return data
+	}
+	out, err := sjson.SetBytes(bytes.Clone(data), "type", "response.completed")
+	if err != nil {
+		return data
+	}
+	return out
+}
+
 func (e *CodexExecutor) CountTokens(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
 	from := opts.SourceFormat
 	to := sdktranslator.FromString("codex")
@@ -567,6 +585,7 @@ func applyCodexHeaders(r *http.Request, auth *cliproxyauth.Auth, token string) {
 		attrs = auth.Attributes
 	}
 	util.ApplyCustomHeadersFromAttrs(r, attrs)
+	util.ApplyBillingHeaders(r, auth.BillingHeaders())
 }
 
 func codexCreds(a *cliproxyauth.Auth) (apiKey, baseURL string) {
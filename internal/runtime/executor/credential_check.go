@@ -0,0 +1,69 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// CheckCredential lists the models visible to an API key credential. The call consumes no quota and
+// changes nothing; OAuth credentials are left to the refresh check.
+func (e *ClaudeExecutor) CheckCredential(ctx context.Context, auth *cliproxyauth.Auth) error {
+	if auth == nil || auth.Attributes["api_key"] == "" {
+		return cliproxyauth.ErrCredentialCheckUnsupported
+	}
+	apiKey, baseURL := claudeCreds(auth)
+	if baseURL == "" {
+		baseURL = "https://api.anthropic.com"
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/v1/models", nil)
+	if err != nil {
+		return err
+	}
+	applyClaudeHeaders(httpReq, auth, apiKey, false, nil)
+	return doCredentialCheck(ctx, e.cfg, auth, httpReq)
+}
+
+// CheckCredential lists the models visible to an API key credential. The call consumes no quota and
+// changes nothing; OAuth credentials are left to the refresh check.
+func (e *GeminiExecutor) CheckCredential(ctx context.Context, auth *cliproxyauth.Auth) error {
+	apiKey, _ := geminiCreds(auth)
+	if apiKey == "" {
+		return cliproxyauth.ErrCredentialCheckUnsupported
+	}
+	url := fmt.Sprintf("%s/%s/models", resolveGeminiBaseURL(auth), glAPIVersion)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("x-goog-api-key", apiKey)
+	applyGeminiHeaders(httpReq, auth)
+	return doCredentialCheck(ctx, e.cfg, auth, httpReq)
+}
+
+// doCredentialCheck sends a read-only credential check and turns a non-2xx answer into a statusErr.
+func doCredentialCheck(ctx context.Context, cfg *config.Config, auth *cliproxyauth.Auth, httpReq *http.Request) error {
+	httpResp, err := newProxyAwareHTTPClient(ctx, cfg, auth, 0).Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("response body close error: %v", errClose)
+		}
+	}()
+	if httpResp.StatusCode >= 200 && httpResp.StatusCode < 300 {
+		return nil
+	}
+	body, err := decodeResponseBody(httpResp.Body, httpResp.Header.Get("Content-Encoding"))
+	if err != nil {
+		return statusErr{code: httpResp.StatusCode, header: httpResp.Header}
+	}
+	b, _ := io.ReadAll(body)
+	return statusErr{code: httpResp.StatusCode, msg: string(b), header: httpResp.Header}
+}
@@ -0,0 +1,54 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestCheckCredentialListsModels(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		if r.Header.Get("Authorization") == "Bearer good" || r.Header.Get("x-goog-api-key") == "good" {
+			_, _ = w.Write([]byte(`{"data":[]}`))
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"invalid api key"}`))
+	}))
+	defer srv.Close()
+
+	checks := []struct {
+		name  string
+		check func(context.Context, *cliproxyauth.Auth) error
+	}{
+		{"claude", NewClaudeExecutor(nil).CheckCredential},
+		{"gemini", NewGeminiExecutor(nil).CheckCredential},
+	}
+	for _, tc := range checks {
+		if err := tc.check(context.Background(), &cliproxyauth.Auth{Attributes: map[string]string{"api_key": "good", "base_url": srv.URL}}); err != nil {
+			t.Errorf("%s: good key rejected: %v", tc.name, err)
+		}
+		var se statusErr
+		if err := tc.check(context.Background(), &cliproxyauth.Auth{Attributes: map[string]string{"api_key": "bad", "base_url": srv.URL}}); !errors.As(err, &se) || se.code != http.StatusUnauthorized {
+			t.Errorf("%s: bad key = %v, want 401", tc.name, err)
+		}
+		if err := tc.check(context.Background(), &cliproxyauth.Auth{Metadata: map[string]any{"refresh_token": "r"}}); !errors.Is(err, cliproxyauth.ErrCredentialCheckUnsupported) {
+			t.Errorf("%s: oauth credential = %v, want unsupported", tc.name, err)
+		}
+	}
+	want := []string{"GET /v1/models", "GET /v1/models", "GET /v1beta/models", "GET /v1beta/models"}
+	if len(paths) != len(want) {
+		t.Fatalf("upstream calls = %v, want %v", paths, want)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Fatalf("upstream calls = %v, want %v", paths, want)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+var (
+	// geminiBatchPollInterval is how often a pending Gemini batch job is polled.
+	geminiBatchPollInterval = 10 * time.Second
+	// geminiBatchMaxPoll bounds how long a batch job is polled before it is cancelled as timed out.
+	geminiBatchMaxPoll = 30 * time.Minute
+)
+
+// ExecuteBatch implements cliproxyauth.BatchExecutor using the Gemini Batch API with inline requests.
+// The job is created with batchGenerateContent and polled until it finishes, for at most
+// geminiBatchMaxPoll; each inlined response is translated back to its caller's format. The job is
+// cancelled upstream when ctx is done or polling gives up.
+func (e *GeminiExecutor) ExecuteBatch(ctx context.Context, auth *cliproxyauth.Auth, items []cliproxyauth.BatchItem) ([]cliproxyauth.BatchResult, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	model := items[0].Request.Model
+	to := sdktranslator.FromString("gemini")
+	bodies := make([][]byte, len(items))
+	requests := []byte(`[]`)
+	for i, item := range items {
+		body := sdktranslator.TranslateRequest(item.Options.SourceFormat, to, item.Request.Model, bytes.Clone(item.Request.Payload), false)
+		body = applyThinkingMetadata(body, item.Request.Metadata, item.Request.Model)
+		body = util.StripThinkingConfigIfUnsupported(item.Request.Model, body)
+		body = fixGeminiImageAspectRatio(item.Request.Model, body)
+		body = applyPayloadConfig(e.cfg, e.Identifier(), item.Request.Model, body)
+		body, _ = sjson.DeleteBytes(body, "session_id")
+		bodies[i] = body
+		entry := []byte(`{"request":{},"metadata":{"key":""}}`)
+		entry, _ = sjson.SetRawBytes(entry, "request", body)
+		entry, _ = sjson.SetBytes(entry, "metadata.key", strconv.Itoa(i))
+		requests, _ = sjson.SetRawBytes(requests, "-1", entry)
+	}
+	payload := []byte(`{"batch":{"display_name":"cliproxy-batch","input_config":{"requests":{"requests":[]}}}}`)
+	payload, _ = sjson.SetRawBytes(payload, "batch.input_config.requests.requests", requests)
+
+	baseURL := resolveGeminiBaseURL(auth)
+	url := fmt.Sprintf("%s/%s/models/%s:batchGenerateContent", baseURL, glAPIVersion, model)
+	operation, err := e.batchCall(ctx, auth, http.MethodPost, url, payload)
+	if err != nil {
+		return nil, err
+	}
+	name := gjson.GetBytes(operation, "name").String()
+	giveUp := time.NewTimer(geminiBatchMaxPoll)
+	defer giveUp.Stop()
+	for !gjson.GetBytes(operation, "done").Bool() {
+		if name == "" {
+			return nil, statusErr{code: http.StatusBadGateway, msg: "gemini batch: operation name missing"}
+		}
+		select {
+		case <-ctx.Done():
+			e.cancelBatch(ctx, auth, baseURL, name)
+			return nil, ctx.Err()
+		case <-giveUp.C:
+			e.cancelBatch(ctx, auth, baseURL, name)
+			return nil, statusErr{code: http.StatusGatewayTimeout, msg: fmt.Sprintf("gemini batch %s still pending after %s", name, geminiBatchMaxPoll)}
+		case <-time.After(geminiBatchPollInterval):
+		}
+		operation, err = e.batchCall(ctx, auth, http.MethodGet, fmt.Sprintf("%s/%s/%s", baseURL, glAPIVersion, name), nil)
+		if err != nil {
+			if ctx.Err() != nil {
+				e.cancelBatch(ctx, auth, baseURL, name)
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+		log.Debugf("gemini batch %s state: %s", name, batchState(operation))
+	}
+	if errMsg := gjson.GetBytes(operation, "error"); errMsg.Exists() {
+		return nil, statusErr{code: http.StatusBadGateway, msg: "gemini batch failed: " + errMsg.Raw}
+	}
+
+	responses := gjson.GetBytes(operation, "response.inlinedResponses.inlinedResponses")
+	if !responses.Exists() {
+		responses = gjson.GetBytes(operation, "metadata.output.inlinedResponses.inlinedResponses")
+	}
+	results := make([]cliproxyauth.BatchResult, len(items))
+	found := make([]bool, len(items))
+	for idx, entry := range responses.Array() {
+		i := idx
+		if key := entry.Get("metadata.key"); key.Exists() {
+			if parsed, errKey := strconv.Atoi(key.String()); errKey == nil {
+				i = parsed
+			}
+		}
+		if i < 0 || i >= len(items) {
+			continue
+		}
+		found[i] = true
+		if itemErr := entry.Get("error"); itemErr.Exists() {
+			code := int(itemErr.Get("code").Int())
+			if code == 0 {
+				code = http.StatusBadGateway
+			}
+			results[i].Err = statusErr{code: geminiRPCStatusToHTTP(code), msg: itemErr.Raw}
+			continue
+		}
+		data := []byte(entry.Get("response").Raw)
+		reporter := newUsageReporter(ctx, e.Identifier(), items[i].Request.Model, auth)
+		reporter.publish(ctx, parseGeminiUsage(data))
+		var param any
+		out := sdktranslator.TranslateNonStream(ctx, to, items[i].Options.SourceFormat, items[i].Request.Model, bytes.Clone(items[i].Options.OriginalRequest), bodies[i], data, &param)
+		results[i].Response.Payload = []byte(out)
+	}
+	for i := range results {
+		if !found[i] {
+			results[i].Err = statusErr{code: http.StatusBadGateway, msg: "gemini batch: response missing for request " + strconv.Itoa(i)}
+		}
+	}
+	return results, nil
+}
+
+// cancelBatch asks Gemini to stop a batch job nobody waits for anymore. It is best effort and runs
+// on a short context of its own since ctx may already be done.
+func (e *GeminiExecutor) cancelBatch(ctx context.Context, auth *cliproxyauth.Auth, baseURL, name string) {
+	cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if _, err := e.batchCall(cancelCtx, auth, http.MethodPost, fmt.Sprintf("%s/%s/%s:cancel", baseURL, glAPIVersion, name), []byte(`{}`)); err != nil {
+		log.Debugf("gemini batch %s: cancel failed: %v", name, err)
+	}
+}
+
+// batchCall sends one Batch API request and returns the operation body.
+func (e *GeminiExecutor) batchCall(ctx context.Context, auth *cliproxyauth.Auth, method, url string, body []byte) ([]byte, error) {
+	apiKey, bearer := geminiCreds(auth)
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if apiKey != "" {
+		httpReq.Header.Set("x-goog-api-key", apiKey)
+	} else if bearer != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	applyGeminiHeaders(httpReq, auth)
+	httpResp, err := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0).Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("gemini executor: close batch response body error: %v", errClose)
+		}
+	}()
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		log.Debugf("gemini batch request error, status: %d, body: %s", httpResp.StatusCode, summarizeErrorBody(httpResp.Header.Get("Content-Type"), data))
+		return nil, statusErr{code: httpResp.StatusCode, msg: string(data), header: httpResp.Header}
+	}
+	return data, nil
+}
+
+// geminiRPCStatusToHTTP maps google.rpc.Code values reported per batch item to HTTP statuses.
+func geminiRPCStatusToHTTP(code int) int {
+	if code >= 100 {
+		return code
+	}
+	switch code {
+	case 3:
+		return http.StatusBadRequest
+	case 5:
+		return http.StatusNotFound
+	case 7:
+		return http.StatusForbidden
+	case 8:
+		return http.StatusTooManyRequests
+	case 16:
+		return http.StatusUnauthorized
+	case 4:
+		return http.StatusGatewayTimeout
+	case 14:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// batchState extracts the job state reported while a batch is pending.
+func batchState(operation []byte) string {
+	return strings.TrimSpace(gjson.GetBytes(operation, "metadata.state").String())
+}
@@ -0,0 +1,66 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+func TestGeminiBatchPollingIsBoundedAndCancelled(t *testing.T) {
+	interval, maxPoll := geminiBatchPollInterval, geminiBatchMaxPoll
+	geminiBatchPollInterval, geminiBatchMaxPoll = 5*time.Millisecond, 30*time.Millisecond
+	defer func() { geminiBatchPollInterval, geminiBatchMaxPoll = interval, maxPoll }()
+
+	var mu sync.Mutex
+	var cancels int
+	polled := make(chan struct{}, 16)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1beta/batches/1:cancel":
+			mu.Lock()
+			cancels++
+			mu.Unlock()
+		case r.Method == http.MethodGet:
+			polled <- struct{}{}
+		}
+		_, _ = w.Write([]byte(`{"name":"batches/1","done":false}`))
+	}))
+	defer srv.Close()
+
+	auth := &cliproxyauth.Auth{Attributes: map[string]string{"api_key": "k", "base_url": srv.URL}}
+	items := []cliproxyauth.BatchItem{{
+		Request: cliproxyexecutor.Request{Model: "gemini-2.5-flash", Payload: []byte(`{"contents":[]}`)},
+		Options: cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("gemini")},
+	}}
+	_, err := NewGeminiExecutor(nil).ExecuteBatch(context.Background(), auth, items)
+	var se statusErr
+	if !errors.As(err, &se) || se.code != http.StatusGatewayTimeout {
+		t.Fatalf("pending batch = %v, want 504 once polling gives up", err)
+	}
+
+	for len(polled) > 0 {
+		<-polled
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-polled
+		cancel()
+	}()
+	if _, err = NewGeminiExecutor(nil).ExecuteBatch(ctx, auth, items); !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled batch = %v, want the context error", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if cancels != 2 {
+		t.Fatalf("upstream cancels = %d, want one per abandoned job", cancels)
+	}
+}
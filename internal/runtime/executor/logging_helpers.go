@@ -12,6 +12,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 )
 
@@ -48,13 +49,24 @@ type upstreamAttempt struct {
 
 // recordAPIRequest stores the upstream request metadata in Gin context for request logging.
 func recordAPIRequest(ctx context.Context, cfg *config.Config, info upstreamRequestLog) {
-	if cfg == nil || !cfg.RequestLog {
+	if cfg == nil {
 		return
 	}
 	ginCtx := ginContextFrom(ctx)
 	if ginCtx == nil {
 		return
 	}
+	logging.RecordUpstreamRequest(ginCtx, cfg.RequestRecording, logging.UpstreamRecording{
+		Provider: info.Provider,
+		AuthID:   info.AuthID,
+		Method:   info.Method,
+		URL:      info.URL,
+		Headers:  info.Headers,
+		Body:     info.Body,
+	})
+	if !cfg.RequestLog {
+		return
+	}
 
 	attempts := getAttempts(ginCtx)
 	index := len(attempts) + 1
@@ -41,21 +41,23 @@ func applyThinkingMetadataCLI(payload []byte, metadata map[string]any, model str
 }
 
 // applyPayloadConfig applies payload default and override rules from configuration
-// to the given JSON payload for the specified model.
+// to the given JSON payload for the specified provider and model.
 // Defaults only fill missing fields, while overrides always overwrite existing values.
-func applyPayloadConfig(cfg *config.Config, model string, payload []byte) []byte {
-	return applyPayloadConfigWithRoot(cfg, model, "", "", payload)
+func applyPayloadConfig(cfg *config.Config, provider, model string, payload []byte) []byte {
+	return applyPayloadConfigWithRoot(cfg, provider, model, "", "", payload)
 }
 
 // applyPayloadConfigWithRoot behaves like applyPayloadConfig but treats all parameter
 // paths as relative to the provided root path (for example, "request" for Gemini CLI)
 // and restricts matches to the given protocol when supplied.
-func applyPayloadConfigWithRoot(cfg *config.Config, model, protocol, root string, payload []byte) []byte {
+// Precedence is client values, then model default rules, then provider defaults; overrides win over all.
+func applyPayloadConfigWithRoot(cfg *config.Config, provider, model, protocol, root string, payload []byte) []byte {
 	if cfg == nil || len(payload) == 0 {
 		return payload
 	}
 	rules := cfg.Payload
-	if len(rules.Default) == 0 && len(rules.Override) == 0 {
+	providerDefaults := rules.ProviderDefaults[strings.ToLower(strings.TrimSpace(provider))]
+	if len(rules.Default) == 0 && len(rules.Override) == 0 && len(providerDefaults) == 0 {
 		return payload
 	}
 	model = strings.TrimSpace(model)
@@ -69,21 +71,10 @@ func applyPayloadConfigWithRoot(cfg *config.Config, model, protocol, root string
 		if !payloadRuleMatchesModel(rule, model, protocol) {
 			continue
 		}
-		for path, value := range rule.Params {
-			fullPath := buildPayloadPath(root, path)
-			if fullPath == "" {
-				continue
-			}
-			if gjson.GetBytes(out, fullPath).Exists() {
-				continue
-			}
-			updated, errSet := sjson.SetBytes(out, fullPath, value)
-			if errSet != nil {
-				continue
-			}
-			out = updated
-		}
+		out = setMissingPayloadParams(out, root, rule.Params)
 	}
+	// Provider defaults fill whatever the client and model rules left unset.
+	out = setMissingPayloadParams(out, root, providerDefaults)
 	// Apply override rules: last write wins per field across all matching rules.
 	for i := range rules.Override {
 		rule := &rules.Override[i]
@@ -105,6 +96,26 @@ func applyPayloadConfigWithRoot(cfg *config.Config, model, protocol, root string
 	return out
 }
 
+// setMissingPayloadParams writes params under root only where the payload has no value yet.
+func setMissingPayloadParams(payload []byte, root string, params map[string]any) []byte {
+	out := payload
+	for path, value := range params {
+		fullPath := buildPayloadPath(root, path)
+		if fullPath == "" {
+			continue
+		}
+		if gjson.GetBytes(out, fullPath).Exists() {
+			continue
+		}
+		updated, errSet := sjson.SetBytes(out, fullPath, value)
+		if errSet != nil {
+			continue
+		}
+		out = updated
+	}
+	return out
+}
+
 func payloadRuleMatchesModel(rule *config.PayloadRule, model, protocol string) bool {
 	if rule == nil {
 		return false
@@ -0,0 +1,35 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestApplyPayloadConfigProviderDefaults(t *testing.T) {
+	cfg := &config.Config{Payload: config.PayloadConfig{
+		Default: []config.PayloadRule{{
+			Models: []config.PayloadModelRule{{Name: "claude-*"}},
+			Params: map[string]any{"max_tokens": 1024},
+		}},
+		ProviderDefaults: map[string]map[string]any{
+			"claude": {"system": "org default", "max_tokens": 64, "temperature": 0.2},
+		},
+	}}
+
+	out := applyPayloadConfig(cfg, "claude", "claude-sonnet", []byte(`{"temperature":0.9}`))
+	if got := gjson.GetBytes(out, "temperature").Float(); got != 0.9 {
+		t.Fatalf("client value must win, got temperature %v", got)
+	}
+	if got := gjson.GetBytes(out, "max_tokens").Int(); got != 1024 {
+		t.Fatalf("model default rule must win over provider default, got %d", got)
+	}
+	if got := gjson.GetBytes(out, "system").String(); got != "org default" {
+		t.Fatalf("expected provider default system prompt, got %q", got)
+	}
+
+	if other := applyPayloadConfig(cfg, "gemini", "gemini-2.5-pro", []byte(`{}`)); gjson.GetBytes(other, "system").Exists() {
+		t.Fatalf("provider defaults leaked to another provider: %s", other)
+	}
+}
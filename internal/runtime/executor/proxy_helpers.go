@@ -48,7 +48,7 @@ func newProxyAwareHTTPClient(ctx context.Context, cfg *config.Config, auth *clip
 	if proxyURL != "" {
 		transport := buildProxyTransport(proxyURL)
 		if transport != nil {
-			httpClient.Transport = transport
+			httpClient.Transport = cliproxyauth.ObserveRateLimits(ctx, cliproxyauth.TimeUpstream(ctx, transport))
 			return httpClient
 		}
 		// If proxy setup failed, log and fall through to context RoundTripper
@@ -59,6 +59,7 @@ func newProxyAwareHTTPClient(ctx context.Context, cfg *config.Config, auth *clip
 	if rt, ok := ctx.Value("cliproxy.roundtripper").(http.RoundTripper); ok && rt != nil {
 		httpClient.Transport = rt
 	}
+	httpClient.Transport = cliproxyauth.ObserveRateLimits(ctx, cliproxyauth.TimeUpstream(ctx, httpClient.Transport))
 
 	return httpClient
 }
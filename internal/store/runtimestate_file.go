@@ -0,0 +1,122 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// FileRuntimeStateStore keeps runtime state for all auths in a single JSON document.
+// Each batch rewrites the document through a temporary file and rename so readers never see partial data.
+type FileRuntimeStateStore struct {
+	path   string
+	mu     sync.Mutex
+	states map[string]cliproxyauth.RuntimeState
+}
+
+// NewFileRuntimeStateStore creates a file-backed runtime state store at path.
+func NewFileRuntimeStateStore(path string) (*FileRuntimeStateStore, error) {
+	if path == "" {
+		return nil, fmt.Errorf("runtime state file store: path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("runtime state file store: create directory: %w", err)
+	}
+	return &FileRuntimeStateStore{path: path}, nil
+}
+
+// Backend implements cliproxyauth.RuntimeStateStore.
+func (s *FileRuntimeStateStore) Backend() string { return "file" }
+
+// LoadRuntimeStates implements cliproxyauth.RuntimeStateStore.
+func (s *FileRuntimeStateStore) LoadRuntimeStates(_ context.Context) (map[string]cliproxyauth.RuntimeState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	states := make(map[string]cliproxyauth.RuntimeState)
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			s.states = states
+			return cloneRuntimeStates(states), nil
+		}
+		return nil, fmt.Errorf("runtime state file store: read: %w", err)
+	}
+	if len(data) > 0 {
+		if err = json.Unmarshal(data, &states); err != nil {
+			return nil, fmt.Errorf("runtime state file store: decode: %w", err)
+		}
+	}
+	s.states = states
+	return cloneRuntimeStates(states), nil
+}
+
+// SaveRuntimeStates implements cliproxyauth.RuntimeStateStore.
+func (s *FileRuntimeStateStore) SaveRuntimeStates(_ context.Context, states map[string]cliproxyauth.RuntimeState) error {
+	if len(states) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := cloneRuntimeStates(s.states)
+	for id, state := range states {
+		next[id] = state
+	}
+	if err := s.writeLocked(next); err != nil {
+		return err
+	}
+	s.states = next
+	return nil
+}
+
+// writeLocked atomically replaces the state file with states. Callers must hold s.mu.
+func (s *FileRuntimeStateStore) writeLocked(states map[string]cliproxyauth.RuntimeState) error {
+	data, err := json.Marshal(states)
+	if err != nil {
+		return fmt.Errorf("runtime state file store: encode: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("runtime state file store: write: %w", err)
+	}
+	if err = os.Rename(tmp, s.path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("runtime state file store: replace: %w", err)
+	}
+	return nil
+}
+
+// DeleteRuntimeStates implements cliproxyauth.RuntimeStatePruner.
+func (s *FileRuntimeStateStore) DeleteRuntimeStates(_ context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := cloneRuntimeStates(s.states)
+	for _, id := range ids {
+		delete(next, id)
+	}
+	if err := s.writeLocked(next); err != nil {
+		return err
+	}
+	s.states = next
+	return nil
+}
+
+// Close implements cliproxyauth.RuntimeStateStore.
+func (s *FileRuntimeStateStore) Close() error { return nil }
+
+func cloneRuntimeStates(src map[string]cliproxyauth.RuntimeState) map[string]cliproxyauth.RuntimeState {
+	dst := make(map[string]cliproxyauth.RuntimeState, len(src))
+	for id, state := range src {
+		dst[id] = state
+	}
+	return dst
+}
@@ -0,0 +1,162 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
+	_ "modernc.org/sqlite"
+)
+
+const sqliteRuntimeStateTable = "runtime_state"
+
+// SQLiteRuntimeStateStore persists runtime state rows in a SQLite database, writing each batch in one transaction.
+type SQLiteRuntimeStateStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteRuntimeStateStore opens (or creates) the SQLite database at path and ensures the schema exists.
+func NewSQLiteRuntimeStateStore(ctx context.Context, path string) (*SQLiteRuntimeStateStore, error) {
+	if path == "" {
+		return nil, fmt.Errorf("runtime state sqlite store: path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("runtime state sqlite store: create directory: %w", err)
+	}
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("runtime state sqlite store: open: %w", err)
+	}
+	// SQLite allows a single writer; serialising connections avoids SQLITE_BUSY under concurrent flushes.
+	db.SetMaxOpenConns(1)
+	if err = db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("runtime state sqlite store: ping: %w", err)
+	}
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id TEXT PRIMARY KEY,
+		state TEXT NOT NULL,
+		updated_at INTEGER NOT NULL
+	)`, sqliteRuntimeStateTable)
+	if _, err = db.ExecContext(ctx, query); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("runtime state sqlite store: create table: %w", err)
+	}
+	return &SQLiteRuntimeStateStore{db: db}, nil
+}
+
+// Backend implements cliproxyauth.RuntimeStateStore.
+func (s *SQLiteRuntimeStateStore) Backend() string { return "sqlite" }
+
+// LoadRuntimeStates implements cliproxyauth.RuntimeStateStore.
+func (s *SQLiteRuntimeStateStore) LoadRuntimeStates(ctx context.Context) (map[string]cliproxyauth.RuntimeState, error) {
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf("SELECT id, state FROM %s", sqliteRuntimeStateTable))
+	if err != nil {
+		return nil, fmt.Errorf("runtime state sqlite store: query: %w", err)
+	}
+	defer func() {
+		if errClose := rows.Close(); errClose != nil {
+			log.Errorf("runtime state sqlite store: close rows: %v", errClose)
+		}
+	}()
+	states := make(map[string]cliproxyauth.RuntimeState)
+	for rows.Next() {
+		var id, payload string
+		if err = rows.Scan(&id, &payload); err != nil {
+			return nil, fmt.Errorf("runtime state sqlite store: scan: %w", err)
+		}
+		var state cliproxyauth.RuntimeState
+		if err = json.Unmarshal([]byte(payload), &state); err != nil {
+			log.Warnf("runtime state sqlite store: skipping %s: %v", id, err)
+			continue
+		}
+		states[id] = state
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("runtime state sqlite store: iterate: %w", err)
+	}
+	return states, nil
+}
+
+// SaveRuntimeStates implements cliproxyauth.RuntimeStateStore.
+func (s *SQLiteRuntimeStateStore) SaveRuntimeStates(ctx context.Context, states map[string]cliproxyauth.RuntimeState) (err error) {
+	if len(states) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("runtime state sqlite store: begin: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Errorf("runtime state sqlite store: rollback: %v", errRollback)
+			}
+		}
+	}()
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf(`INSERT INTO %s (id, state, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET state = excluded.state, updated_at = excluded.updated_at`, sqliteRuntimeStateTable))
+	if err != nil {
+		return fmt.Errorf("runtime state sqlite store: prepare: %w", err)
+	}
+	defer func() {
+		_ = stmt.Close()
+	}()
+	now := time.Now().UnixMilli()
+	for id, state := range states {
+		payload, errMarshal := json.Marshal(state)
+		if errMarshal != nil {
+			err = fmt.Errorf("runtime state sqlite store: encode %s: %w", id, errMarshal)
+			return err
+		}
+		if _, err = stmt.ExecContext(ctx, id, string(payload), now); err != nil {
+			return fmt.Errorf("runtime state sqlite store: upsert %s: %w", id, err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("runtime state sqlite store: commit: %w", err)
+	}
+	return nil
+}
+
+// DeleteRuntimeStates implements cliproxyauth.RuntimeStatePruner.
+func (s *SQLiteRuntimeStateStore) DeleteRuntimeStates(ctx context.Context, ids []string) (err error) {
+	if len(ids) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("runtime state sqlite store: begin: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Errorf("runtime state sqlite store: rollback: %v", errRollback)
+			}
+		}
+	}()
+	for _, id := range ids {
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE id = ?", sqliteRuntimeStateTable), id); err != nil {
+			return fmt.Errorf("runtime state sqlite store: delete %s: %w", id, err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("runtime state sqlite store: commit: %w", err)
+	}
+	return nil
+}
+
+// Close implements cliproxyauth.RuntimeStateStore.
+func (s *SQLiteRuntimeStateStore) Close() error {
+	if s == nil || s.db == nil {
+		return nil
+	}
+	return s.db.Close()
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+type runtimeStateBackend interface {
+	cliproxyauth.RuntimeStateStore
+	cliproxyauth.RuntimeStatePruner
+}
+
+func TestRuntimeStateStoresRoundTrip(t *testing.T) {
+	backends := map[string]func(t *testing.T, path string) runtimeStateBackend{
+		"file": func(t *testing.T, path string) runtimeStateBackend {
+			s, err := NewFileRuntimeStateStore(path)
+			if err != nil {
+				t.Fatalf("open file store: %v", err)
+			}
+			return s
+		},
+		"sqlite": func(t *testing.T, path string) runtimeStateBackend {
+			s, err := NewSQLiteRuntimeStateStore(context.Background(), path)
+			if err != nil {
+				t.Fatalf("open sqlite store: %v", err)
+			}
+			return s
+		},
+	}
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			path := filepath.Join(t.TempDir(), "state", "runtime."+name)
+			retryAt := time.Unix(1_700_000_000, 0).UTC()
+			cooling := cliproxyauth.RuntimeState{
+				Status:         cliproxyauth.StatusError,
+				StatusMessage:  "rate limited",
+				Unavailable:    true,
+				Quota:          cliproxyauth.QuotaState{Exceeded: true, Reason: "quota", NextRecoverAt: retryAt, BackoffLevel: 2},
+				NextRetryAfter: retryAt,
+				LastError:      &cliproxyauth.Error{Code: "rate_limited", Message: "slow down", HTTPStatus: 429},
+				ModelStates: map[string]*cliproxyauth.ModelState{
+					"gemini-2.5-pro": {Status: cliproxyauth.StatusError, Unavailable: true, NextRetryAfter: retryAt},
+				},
+				UpdatedAt: retryAt,
+				Requests:  &cliproxyauth.RequestCounts{},
+			}
+			healthy := cliproxyauth.RuntimeState{Status: cliproxyauth.StatusActive, UpdatedAt: retryAt}
+
+			s := open(t, path)
+			if states, err := s.LoadRuntimeStates(ctx); err != nil || len(states) != 0 {
+				t.Fatalf("empty store load = %v, %v", states, err)
+			}
+			if err := s.SaveRuntimeStates(ctx, map[string]cliproxyauth.RuntimeState{"a": healthy, "b": healthy}); err != nil {
+				t.Fatalf("save: %v", err)
+			}
+			if err := s.SaveRuntimeStates(ctx, map[string]cliproxyauth.RuntimeState{"a": cooling}); err != nil {
+				t.Fatalf("save update: %v", err)
+			}
+			if err := s.Close(); err != nil {
+				t.Fatalf("close: %v", err)
+			}
+
+			s = open(t, path)
+			defer func() { _ = s.Close() }()
+			states, err := s.LoadRuntimeStates(ctx)
+			if err != nil {
+				t.Fatalf("reload: %v", err)
+			}
+			assertRuntimeStates(t, states, map[string]cliproxyauth.RuntimeState{"a": cooling, "b": healthy})
+
+			if err = s.DeleteRuntimeStates(ctx, []string{"b"}); err != nil {
+				t.Fatalf("delete: %v", err)
+			}
+			if states, err = s.LoadRuntimeStates(ctx); err != nil {
+				t.Fatalf("load after delete: %v", err)
+			}
+			assertRuntimeStates(t, states, map[string]cliproxyauth.RuntimeState{"a": cooling})
+		})
+	}
+}
+
+// assertRuntimeStates compares states through their JSON encoding, the form every backend stores.
+func assertRuntimeStates(t *testing.T, got, want map[string]cliproxyauth.RuntimeState) {
+	t.Helper()
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if string(gotJSON) != string(wantJSON) {
+		t.Fatalf("states = %s\nwant %s", gotJSON, wantJSON)
+	}
+}
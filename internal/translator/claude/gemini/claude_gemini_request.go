@@ -227,14 +227,24 @@ func ConvertGeminiRequestToClaude(modelName string, inputRawJSON []byte, stream
 					}
 
 					// Image content (inline_data) conversion to Claude Code format
-					if inlineData := part.Get("inline_data"); inlineData.Exists() {
+					inlineData := part.Get("inline_data")
+					if !inlineData.Exists() {
+						inlineData = part.Get("inlineData")
+					}
+					if inlineData.Exists() {
+						data := inlineData.Get("data").String()
+						if !util.ImageWithinLimit(data) {
+							return true
+						}
+						mimeType := inlineData.Get("mime_type")
+						if !mimeType.Exists() {
+							mimeType = inlineData.Get("mimeType")
+						}
 						imageContent := `{"type":"image","source":{"type":"base64","media_type":"","data":""}}`
-						if mimeType := inlineData.Get("mime_type"); mimeType.Exists() {
+						if mimeType.Exists() {
 							imageContent, _ = sjson.Set(imageContent, "source.media_type", mimeType.String())
 						}
-						if data := inlineData.Get("data"); data.Exists() {
-							imageContent, _ = sjson.Set(imageContent, "source.data", data.String())
-						}
+						imageContent, _ = sjson.Set(imageContent, "source.data", data)
 						msg, _ = sjson.SetRaw(msg, "content.-1", imageContent)
 						return true
 					}
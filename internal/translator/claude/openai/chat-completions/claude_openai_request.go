@@ -16,6 +16,7 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -177,14 +178,8 @@ func ConvertOpenAIRequestToClaude(modelName string, inputRawJSON []byte, stream
 						case "image_url":
 							// Convert OpenAI image format to Claude Code format
 							imageURL := part.Get("image_url.url").String()
-							if strings.HasPrefix(imageURL, "data:") {
-								// Extract base64 data and media type from data URL
-								parts := strings.Split(imageURL, ",")
-								if len(parts) == 2 {
-									mediaTypePart := strings.Split(parts[0], ";")[0]
-									mediaType := strings.TrimPrefix(mediaTypePart, "data:")
-									data := parts[1]
-
+							if mediaType, data, ok := util.ParseDataURL(imageURL); ok {
+								if util.ImageWithinLimit(data) {
 									contentParts = append(contentParts, map[string]interface{}{
 										"type": "image",
 										"source": map[string]interface{}{
@@ -194,6 +189,15 @@ func ConvertOpenAIRequestToClaude(modelName string, inputRawJSON []byte, stream
 										},
 									})
 								}
+							} else if util.IsRemoteImageURL(imageURL) {
+								// Claude fetches URL sources itself
+								contentParts = append(contentParts, map[string]interface{}{
+									"type": "image",
+									"source": map[string]interface{}{
+										"type": "url",
+										"url":  strings.TrimSpace(imageURL),
+									},
+								})
 							}
 						}
 						return true
@@ -205,6 +205,7 @@ func ConvertClaudeResponseToOpenAI(_ context.Context, modelName string, original
 			if stopReason := delta.Get("stop_reason"); stopReason.Exists() {
 				(*param).(*ConvertAnthropicResponseToOpenAIParams).FinishReason = mapAnthropicStopReasonToOpenAI(stopReason.String())
 				template, _ = sjson.Set(template, "choices.0.finish_reason", (*param).(*ConvertAnthropicResponseToOpenAIParams).FinishReason)
+				template, _ = sjson.Set(template, "choices.0.native_finish_reason", stopReason.String())
 			}
 		}
 
@@ -442,6 +443,9 @@ func ConvertClaudeResponseToOpenAINonStream(_ context.Context, _ string, origina
 	} else {
 		out, _ = sjson.Set(out, "choices.0.finish_reason", mapAnthropicStopReasonToOpenAI(stopReason))
 	}
+	if stopReason != "" {
+		out, _ = sjson.Set(out, "choices.0.native_finish_reason", stopReason)
+	}
 
 	// Set usage information including prompt tokens, completion tokens, and total tokens
 	totalTokens := inputTokens + outputTokens
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -173,17 +174,7 @@ func ConvertOpenAIResponsesRequestToClaude(modelName string, inputRawJSON []byte
 							if url != "" {
 								var contentPart string
 								if strings.HasPrefix(url, "data:") {
-									trimmed := strings.TrimPrefix(url, "data:")
-									mediaAndData := strings.SplitN(trimmed, ";base64,", 2)
-									mediaType := "application/octet-stream"
-									data := ""
-									if len(mediaAndData) == 2 {
-										if mediaAndData[0] != "" {
-											mediaType = mediaAndData[0]
-										}
-										data = mediaAndData[1]
-									}
-									if data != "" {
+									if mediaType, data, ok := util.ParseDataURL(url); ok && util.ImageWithinLimit(data) {
 										contentPart = `{"type":"image","source":{"type":"base64","media_type":"","data":""}}`
 										contentPart, _ = sjson.Set(contentPart, "source.media_type", mediaType)
 										contentPart, _ = sjson.Set(contentPart, "source.data", data)
@@ -109,12 +109,15 @@ func ConvertCodexResponseToOpenAI(_ context.Context, modelName string, originalR
 			template, _ = sjson.Set(template, "choices.0.delta.content", deltaResult.String())
 		}
 	} else if dataType == "response.completed" {
-		finishReason := "stop"
+		finishReason, nativeFinishReason := "stop", "completed"
 		if (*param).(*ConvertCliToOpenAIParams).FunctionCallIndex != -1 {
 			finishReason = "tool_calls"
 		}
+		if reason := incompleteReason(rootResult.Get("response")); reason != "" {
+			finishReason, nativeFinishReason = "length", reason
+		}
 		template, _ = sjson.Set(template, "choices.0.finish_reason", finishReason)
-		template, _ = sjson.Set(template, "choices.0.native_finish_reason", finishReason)
+		template, _ = sjson.Set(template, "choices.0.native_finish_reason", nativeFinishReason)
 	} else if dataType == "response.output_item.done" {
 		functionCallItemTemplate := `{"index":0,"id":"","type":"function","function":{"name":"","arguments":""}}`
 		itemResult := rootResult.Get("item")
@@ -295,13 +298,28 @@ func ConvertCodexResponseToOpenAINonStream(_ context.Context, _ string, original
 		status := statusResult.String()
 		if status == "completed" {
 			template, _ = sjson.Set(template, "choices.0.finish_reason", "stop")
-			template, _ = sjson.Set(template, "choices.0.native_finish_reason", "stop")
+			template, _ = sjson.Set(template, "choices.0.native_finish_reason", status)
+		} else if reason := incompleteReason(responseResult); reason != "" {
+			template, _ = sjson.Set(template, "choices.0.finish_reason", "length")
+			template, _ = sjson.Set(template, "choices.0.native_finish_reason", reason)
 		}
 	}
 
 	return template
 }
 
+// incompleteReason returns the incomplete_details reason of a response stopped by its output token
+// limit, or an empty string.
+func incompleteReason(response gjson.Result) string {
+	if response.Get("status").String() != "incomplete" {
+		return ""
+	}
+	if reason := response.Get("incomplete_details.reason").String(); reason == "max_output_tokens" {
+		return reason
+	}
+	return ""
+}
+
 // buildReverseMapFromOriginalOpenAI builds a map of shortened tool name -> original tool name
 // from the original OpenAI-style request JSON using the same shortening logic.
 func buildReverseMapFromOriginalOpenAI(original []byte) map[string]string {
@@ -187,7 +187,9 @@ func ConvertCliResponseToOpenAI(_ context.Context, _ string, originalRequestRawJ
 
 	if hasFunctionCall {
 		template, _ = sjson.Set(template, "choices.0.finish_reason", "tool_calls")
-		template, _ = sjson.Set(template, "choices.0.native_finish_reason", "tool_calls")
+		if gjson.Get(template, "choices.0.native_finish_reason").Type != gjson.String {
+			template, _ = sjson.Set(template, "choices.0.native_finish_reason", "tool_calls")
+		}
 	}
 
 	return []string{template}
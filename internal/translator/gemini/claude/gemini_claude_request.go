@@ -79,6 +79,10 @@ func ConvertClaudeRequestToGemini(modelName string, inputRawJSON []byte, _ bool)
 					if contentTypeResult.Type == gjson.String && contentTypeResult.String() == "text" {
 						prompt := contentResult.Get("text").String()
 						clientContent.Parts = append(clientContent.Parts, client.Part{Text: prompt})
+					} else if contentTypeResult.Type == gjson.String && contentTypeResult.String() == "image" {
+						if mimeType, data, ok := util.InlineClaudeImage(contentResult.Get("source")); ok {
+							clientContent.Parts = append(clientContent.Parts, client.Part{InlineData: &client.InlineData{MimeType: mimeType, Data: data}})
+						}
 					} else if contentTypeResult.Type == gjson.String && contentTypeResult.String() == "tool_use" {
 						functionName := contentResult.Get("name").String()
 						functionArgs := contentResult.Get("input").String()
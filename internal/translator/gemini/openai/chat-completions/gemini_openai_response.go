@@ -189,7 +189,9 @@ func ConvertGeminiResponseToOpenAI(_ context.Context, _ string, originalRequestR
 
 	if hasFunctionCall {
 		template, _ = sjson.Set(template, "choices.0.finish_reason", "tool_calls")
-		template, _ = sjson.Set(template, "choices.0.native_finish_reason", "tool_calls")
+		if gjson.Get(template, "choices.0.native_finish_reason").Type != gjson.String {
+			template, _ = sjson.Set(template, "choices.0.native_finish_reason", "tool_calls")
+		}
 	}
 
 	return []string{template}
@@ -321,7 +323,9 @@ func ConvertGeminiResponseToOpenAINonStream(_ context.Context, _ string, origina
 
 	if hasFunctionCall {
 		template, _ = sjson.Set(template, "choices.0.finish_reason", "tool_calls")
-		template, _ = sjson.Set(template, "choices.0.native_finish_reason", "tool_calls")
+		if gjson.Get(template, "choices.0.native_finish_reason").Type != gjson.String {
+			template, _ = sjson.Set(template, "choices.0.native_finish_reason", "tool_calls")
+		}
 	}
 
 	return template
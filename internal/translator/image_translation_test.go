@@ -0,0 +1,166 @@
+package translator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+// pngHeader is served by the image server; its base64 form matches the fixtures' inline data.
+const (
+	pngHeader       = "\x89PNG\r\n\x1a\n"
+	pngHeaderBase64 = "iVBORw0KGgo="
+)
+
+type imagePart struct {
+	kind string // "inline", "base64" or "url"
+	mime string
+	data string
+}
+
+func newImageServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte(pngHeader))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func loadImageFixture(t *testing.T, name, imageURL string) []byte {
+	t.Helper()
+	raw, err := os.ReadFile("testdata/images/" + name)
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return []byte(strings.ReplaceAll(string(raw), "{{IMAGE_URL}}", imageURL))
+}
+
+func setImagePolicy(t *testing.T, policy util.ImagePolicy) {
+	t.Helper()
+	util.SetImagePolicy(policy)
+	t.Cleanup(func() { util.SetImagePolicy(util.ImagePolicy{FetchURLs: true}) })
+}
+
+// collectImages returns the image parts of a translated Gemini or Claude request, in order.
+func collectImages(out []byte) []imagePart {
+	var images []imagePart
+	var walk func(gjson.Result)
+	walk = func(node gjson.Result) {
+		if node.IsArray() {
+			for _, item := range node.Array() {
+				walk(item)
+			}
+			return
+		}
+		if !node.IsObject() {
+			return
+		}
+		for _, key := range []string{"inlineData", "inline_data"} {
+			if inline := node.Get(key); inline.Exists() {
+				mime := inline.Get("mime_type").String()
+				if mime == "" {
+					mime = inline.Get("mimeType").String()
+				}
+				images = append(images, imagePart{kind: "inline", mime: mime, data: inline.Get("data").String()})
+				return
+			}
+		}
+		if node.Get("type").String() == "image" {
+			source := node.Get("source")
+			if source.Get("type").String() == "url" {
+				images = append(images, imagePart{kind: "url", data: source.Get("url").String()})
+			} else {
+				images = append(images, imagePart{kind: "base64", mime: source.Get("media_type").String(), data: source.Get("data").String()})
+			}
+			return
+		}
+		node.ForEach(func(_, value gjson.Result) bool {
+			walk(value)
+			return true
+		})
+	}
+	walk(gjson.ParseBytes(out))
+	return images
+}
+
+func TestImageTranslationFixtures(t *testing.T) {
+	srv := newImageServer(t)
+	imageURL := srv.URL + "/cat.png"
+	inline := imagePart{kind: "inline", mime: "image/png", data: pngHeaderBase64}
+	base64Part := imagePart{kind: "base64", mime: "image/png", data: pngHeaderBase64}
+	urlPart := imagePart{kind: "url", data: imageURL}
+
+	cases := []struct {
+		fixture  string
+		from, to sdktranslator.Format
+		want     []imagePart
+	}{
+		{"openai_chat.json", sdktranslator.FormatOpenAI, sdktranslator.FormatGemini, []imagePart{inline, inline}},
+		{"openai_chat.json", sdktranslator.FormatOpenAI, sdktranslator.FormatGeminiCLI, []imagePart{inline, inline}},
+		{"openai_chat.json", sdktranslator.FormatOpenAI, sdktranslator.FormatAntigravity, []imagePart{inline, inline}},
+		{"openai_chat.json", sdktranslator.FormatOpenAI, sdktranslator.FormatClaude, []imagePart{base64Part, urlPart}},
+		{"openai_responses.json", sdktranslator.FormatOpenAIResponse, sdktranslator.FormatGemini, []imagePart{inline, inline}},
+		{"openai_responses.json", sdktranslator.FormatOpenAIResponse, sdktranslator.FormatClaude, []imagePart{base64Part, urlPart}},
+		{"claude.json", sdktranslator.FormatClaude, sdktranslator.FormatGemini, []imagePart{inline, inline}},
+		{"claude.json", sdktranslator.FormatClaude, sdktranslator.FormatGeminiCLI, []imagePart{inline, inline}},
+		{"claude.json", sdktranslator.FormatClaude, sdktranslator.FormatAntigravity, []imagePart{inline, inline}},
+		{"gemini.json", sdktranslator.FormatGemini, sdktranslator.FormatClaude, []imagePart{base64Part, {kind: "base64", mime: "image/jpeg", data: "/9j/4AAQ"}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.fixture+"->"+tc.to.String(), func(t *testing.T) {
+			out := sdktranslator.TranslateRequestByFormatName(tc.from, tc.to, "test-model", loadImageFixture(t, tc.fixture, imageURL), false)
+			got := collectImages(out)
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %d images %+v, want %+v\n%s", len(got), got, tc.want, out)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("image %d = %+v, want %+v", i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestImageTranslationDropsURLsWhenFetchDisabled(t *testing.T) {
+	srv := newImageServer(t)
+	setImagePolicy(t, util.ImagePolicy{FetchURLs: false})
+
+	out := sdktranslator.TranslateRequestByFormatName(sdktranslator.FormatOpenAI, sdktranslator.FormatGemini, "test-model", loadImageFixture(t, "openai_chat.json", srv.URL), false)
+	if got := collectImages(out); len(got) != 1 || got[0].data != pngHeaderBase64 {
+		t.Fatalf("images = %+v, want only the inline image", got)
+	}
+
+	// Claude takes URLs natively, so nothing is fetched or dropped.
+	out = sdktranslator.TranslateRequestByFormatName(sdktranslator.FormatOpenAI, sdktranslator.FormatClaude, "test-model", loadImageFixture(t, "openai_chat.json", srv.URL), false)
+	if got := collectImages(out); len(got) != 2 {
+		t.Fatalf("images = %+v, want base64 and url", got)
+	}
+}
+
+func TestImageTranslationDropsOversizedImages(t *testing.T) {
+	srv := newImageServer(t)
+	setImagePolicy(t, util.ImagePolicy{FetchURLs: true, MaxBytes: 4})
+
+	for _, tc := range []struct {
+		fixture  string
+		from, to sdktranslator.Format
+	}{
+		{"openai_chat.json", sdktranslator.FormatOpenAI, sdktranslator.FormatGemini},
+		{"claude.json", sdktranslator.FormatClaude, sdktranslator.FormatGemini},
+		{"gemini.json", sdktranslator.FormatGemini, sdktranslator.FormatClaude},
+	} {
+		out := sdktranslator.TranslateRequestByFormatName(tc.from, tc.to, "test-model", loadImageFixture(t, tc.fixture, srv.URL), false)
+		if got := collectImages(out); len(got) != 0 {
+			t.Fatalf("%s -> %s: images = %+v, want all dropped", tc.from, tc.to, got)
+		}
+	}
+}
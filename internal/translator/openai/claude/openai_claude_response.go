@@ -344,21 +344,14 @@ func convertOpenAIStreamingChunkToAnthropic(rawJSON []byte, param *ConvertOpenAI
 		// Don't send message_delta here - wait for usage info or [DONE]
 	}
 
-	// Handle usage information separately (this comes in a later chunk)
-	// Only process if usage has actual values (not null)
-	if param.FinishReason != "" {
-		usage := root.Get("usage")
-		var inputTokens, outputTokens int64
-		if usage.Exists() && usage.Type != gjson.Null {
-			// Check if usage has actual token counts
-			promptTokens := usage.Get("prompt_tokens")
-			completionTokens := usage.Get("completion_tokens")
-
-			if promptTokens.Exists() && completionTokens.Exists() {
-				inputTokens = promptTokens.Int()
-				outputTokens = completionTokens.Int()
-			}
-		}
+	// Handle usage information separately (this may come in a later chunk)
+	// Only process if usage has actual values (not null); otherwise [DONE] sends message_delta.
+	usage := root.Get("usage")
+	promptTokens := usage.Get("prompt_tokens")
+	completionTokens := usage.Get("completion_tokens")
+	if param.FinishReason != "" && !param.MessageDeltaSent && promptTokens.Exists() && completionTokens.Exists() {
+		inputTokens := promptTokens.Int()
+		outputTokens := completionTokens.Int()
 		// Send message_delta with usage
 		messageDelta := map[string]interface{}{
 			"type": "message_delta",
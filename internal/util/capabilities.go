@@ -0,0 +1,110 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+)
+
+// CapabilityModelPrefix marks a model name that requests capabilities instead of a concrete model,
+// e.g. "capabilities:vision,tools".
+const CapabilityModelPrefix = "capabilities:"
+
+// Capability taxonomy understood by capability-based routing.
+const (
+	CapabilityVision      = "vision"
+	CapabilityTools       = "tools"
+	CapabilityLongContext = "long-context"
+	CapabilityReasoning   = "reasoning"
+	CapabilityJSONMode    = "json-mode"
+	CapabilityAudio       = "audio"
+)
+
+// longContextThreshold is the context window from which registry models are treated as long-context.
+const longContextThreshold = 200000
+
+var knownCapabilities = map[string]struct{}{
+	CapabilityVision:      {},
+	CapabilityTools:       {},
+	CapabilityLongContext: {},
+	CapabilityReasoning:   {},
+	CapabilityJSONMode:    {},
+	CapabilityAudio:       {},
+}
+
+// IsKnownCapability reports whether name belongs to the capability taxonomy.
+func IsKnownCapability(name string) bool {
+	_, ok := knownCapabilities[strings.ToLower(strings.TrimSpace(name))]
+	return ok
+}
+
+// ParseCapabilityRequest extracts the required capabilities from a capability model name.
+func ParseCapabilityRequest(modelName string) ([]string, bool) {
+	if !strings.HasPrefix(strings.ToLower(modelName), CapabilityModelPrefix) {
+		return nil, false
+	}
+	raw := modelName[len(CapabilityModelPrefix):]
+	parts := strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == '+' })
+	required := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if capability := strings.ToLower(strings.TrimSpace(part)); capability != "" {
+			required = append(required, capability)
+		}
+	}
+	return required, true
+}
+
+// CapabilityModels returns the available models whose capabilities cover every required capability.
+// Capabilities come from the configured catalog, with long-context inferred from registry context windows.
+// Models with more available clients come first; ties break by name.
+func CapabilityModels(required []string, catalog map[string][]string) ([]string, error) {
+	if len(required) == 0 {
+		return nil, fmt.Errorf("no capabilities requested")
+	}
+	for _, capability := range required {
+		if !IsKnownCapability(capability) {
+			return nil, fmt.Errorf("unknown capability %q", capability)
+		}
+	}
+
+	reg := registry.GetGlobalRegistry()
+	counts := make(map[string]int, len(catalog))
+	models := make([]string, 0, len(catalog))
+	for model, declared := range catalog {
+		if !modelHasCapabilities(declared, required, reg.GetModelInfo(model)) {
+			continue
+		}
+		if count := reg.GetModelCount(model); count > 0 {
+			counts[model] = count
+			models = append(models, model)
+		}
+	}
+	if len(models) == 0 {
+		return nil, fmt.Errorf("no available model supports capabilities %s", strings.Join(required, ","))
+	}
+	sort.Slice(models, func(i, j int) bool {
+		if counts[models[i]] != counts[models[j]] {
+			return counts[models[i]] > counts[models[j]]
+		}
+		return models[i] < models[j]
+	})
+	return models, nil
+}
+
+func modelHasCapabilities(declared, required []string, info *registry.ModelInfo) bool {
+	available := make(map[string]struct{}, len(declared)+1)
+	for _, capability := range declared {
+		available[strings.ToLower(strings.TrimSpace(capability))] = struct{}{}
+	}
+	if info != nil && (info.ContextLength >= longContextThreshold || info.InputTokenLimit >= longContextThreshold) {
+		available[CapabilityLongContext] = struct{}{}
+	}
+	for _, capability := range required {
+		if _, ok := available[capability]; !ok {
+			return false
+		}
+	}
+	return true
+}
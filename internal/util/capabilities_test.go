@@ -0,0 +1,46 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+)
+
+func TestCapabilityModels(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("capability-a", "gemini", []*registry.ModelInfo{{ID: "cap-test-wide", ContextLength: 1000000}, {ID: "cap-test-vision"}})
+	reg.RegisterClient("capability-b", "gemini", []*registry.ModelInfo{{ID: "cap-test-vision"}})
+	t.Cleanup(func() {
+		reg.UnregisterClient("capability-a")
+		reg.UnregisterClient("capability-b")
+	})
+	catalog := map[string][]string{
+		"cap-test-wide":    {"vision", "tools"},
+		"cap-test-vision":  {"Vision"},
+		"cap-test-offline": {"vision"},
+	}
+
+	models, err := CapabilityModels([]string{"vision"}, catalog)
+	if err != nil || !reflect.DeepEqual(models, []string{"cap-test-vision", "cap-test-wide"}) {
+		t.Fatalf("vision models = %v, %v; want the model with more clients first and no unavailable model", models, err)
+	}
+	if models, err = CapabilityModels([]string{"vision", "long-context"}, catalog); err != nil || !reflect.DeepEqual(models, []string{"cap-test-wide"}) {
+		t.Fatalf("long-context models = %v, %v; want the inferred long-context model", models, err)
+	}
+	for _, required := range [][]string{nil, {"teleport"}, {"audio"}} {
+		if models, err = CapabilityModels(required, catalog); err == nil {
+			t.Errorf("%v matched %v", required, models)
+		}
+	}
+}
+
+func TestParseCapabilityRequest(t *testing.T) {
+	required, ok := ParseCapabilityRequest("Capabilities:vision+ Tools,,")
+	if !ok || !reflect.DeepEqual(required, []string{"vision", "tools"}) {
+		t.Fatalf("parse = %v, %t", required, ok)
+	}
+	if _, ok = ParseCapabilityRequest("gemini-2.5-pro"); ok {
+		t.Fatal("plain model parsed as a capability request")
+	}
+}
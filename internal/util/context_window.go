@@ -0,0 +1,64 @@
+package util
+
+import (
+	"sort"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+)
+
+// ModelContextWindow returns the context window of model in tokens: the configured override when set,
+// otherwise the registry's context length or input token limit. Zero means unknown.
+func ModelContextWindow(model string, overrides map[string]int) int {
+	if limit := overrides[model]; limit > 0 {
+		return limit
+	}
+	return registryContextWindow(registry.GetGlobalRegistry().GetModelInfo(model))
+}
+
+func registryContextWindow(info *registry.ModelInfo) int {
+	if info == nil {
+		return 0
+	}
+	if info.ContextLength > 0 {
+		return info.ContextLength
+	}
+	return info.InputTokenLimit
+}
+
+// ContextWindows lists the known context window of every model currently available in the pool.
+func ContextWindows(overrides map[string]int) map[string]int {
+	windows := make(map[string]int)
+	for _, model := range registry.GetGlobalRegistry().GetAvailableModels("openai") {
+		id, _ := model["id"].(string)
+		if id == "" {
+			continue
+		}
+		if limit := ModelContextWindow(id, overrides); limit > 0 {
+			windows[id] = limit
+		}
+	}
+	return windows
+}
+
+// LargerContextModel returns the available model with the smallest context window above minTokens,
+// so the suggestion is the closest fit rather than the largest model. It returns "" when no model
+// qualifies.
+func LargerContextModel(minTokens int, exclude string, overrides map[string]int) (string, int) {
+	windows := ContextWindows(overrides)
+	models := make([]string, 0, len(windows))
+	for model := range windows {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	best, bestWindow := "", 0
+	for _, model := range models {
+		window := windows[model]
+		if model == exclude || window <= minTokens {
+			continue
+		}
+		if best == "" || window < bestWindow {
+			best, bestWindow = model, window
+		}
+	}
+	return best, bestWindow
+}
@@ -14,6 +14,15 @@ func ApplyCustomHeadersFromAttrs(r *http.Request, attrs map[string]string) {
 	applyCustomHeaders(r, extractCustomHeaders(attrs))
 }
 
+// ApplyBillingHeaders sets billing attribution headers. Call it after custom headers so
+// billing tags cannot be overridden by generic per-key headers.
+func ApplyBillingHeaders(r *http.Request, headers map[string]string) {
+	if r == nil {
+		return
+	}
+	applyCustomHeaders(r, headers)
+}
+
 func extractCustomHeaders(attrs map[string]string) map[string]string {
 	if len(attrs) == 0 {
 		return nil
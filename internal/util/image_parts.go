@@ -0,0 +1,173 @@
+package util
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	defaultMaxImageBytes     = 20 << 20
+	defaultImageFetchTimeout = 15 * time.Second
+)
+
+// ImagePolicy controls how image content parts are carried between provider formats.
+type ImagePolicy struct {
+	// FetchURLs downloads URL-referenced images for providers that only accept inline data.
+	FetchURLs bool
+	// MaxBytes caps the decoded size of an image; larger images are dropped. 0 uses 20 MiB.
+	MaxBytes int64
+	// FetchTimeout bounds one download. 0 uses 15s.
+	FetchTimeout time.Duration
+}
+
+var imagePolicy atomic.Pointer[ImagePolicy]
+
+func init() {
+	imagePolicy.Store(&ImagePolicy{FetchURLs: true})
+}
+
+// SetImagePolicy replaces the image translation policy used by the request translators.
+func SetImagePolicy(policy ImagePolicy) {
+	if policy.MaxBytes <= 0 {
+		policy.MaxBytes = defaultMaxImageBytes
+	}
+	if policy.FetchTimeout <= 0 {
+		policy.FetchTimeout = defaultImageFetchTimeout
+	}
+	imagePolicy.Store(&policy)
+}
+
+func currentImagePolicy() ImagePolicy {
+	policy := *imagePolicy.Load()
+	if policy.MaxBytes <= 0 {
+		policy.MaxBytes = defaultMaxImageBytes
+	}
+	if policy.FetchTimeout <= 0 {
+		policy.FetchTimeout = defaultImageFetchTimeout
+	}
+	return policy
+}
+
+// ParseDataURL splits a "data:<mime>;base64,<data>" URL. ok is false for anything else.
+func ParseDataURL(raw string) (mimeType, data string, ok bool) {
+	rest, found := strings.CutPrefix(strings.TrimSpace(raw), "data:")
+	if !found {
+		return "", "", false
+	}
+	meta, data, found := strings.Cut(rest, ",")
+	if !found || data == "" || !strings.HasSuffix(meta, ";base64") {
+		return "", "", false
+	}
+	mimeType = strings.TrimSuffix(meta, ";base64")
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	return mimeType, data, true
+}
+
+// ImageDataURL builds a base64 data URL.
+func ImageDataURL(mimeType, data string) string {
+	return "data:" + mimeType + ";base64," + data
+}
+
+// IsRemoteImageURL reports whether ref is an http(s) image reference.
+func IsRemoteImageURL(ref string) bool {
+	ref = strings.ToLower(strings.TrimSpace(ref))
+	return strings.HasPrefix(ref, "https://") || strings.HasPrefix(ref, "http://")
+}
+
+// ImageWithinLimit reports whether base64 data decodes to no more than the configured maximum,
+// logging when it does not.
+func ImageWithinLimit(data string) bool {
+	limit := currentImagePolicy().MaxBytes
+	if size := int64(base64.StdEncoding.DecodedLen(len(data))); size > limit {
+		log.Warnf("image of %d bytes exceeds the %d byte limit, dropped", size, limit)
+		return false
+	}
+	return true
+}
+
+// InlineImage resolves an image reference - a data URL or, when fetching is enabled, an http(s)
+// URL - into a mime type and base64 data within the size limit. ok is false when the image has to
+// be dropped.
+func InlineImage(ref string) (mimeType, data string, ok bool) {
+	if mimeType, data, ok = ParseDataURL(ref); ok {
+		return mimeType, data, ImageWithinLimit(data)
+	}
+	if !IsRemoteImageURL(ref) {
+		return "", "", false
+	}
+	policy := currentImagePolicy()
+	if !policy.FetchURLs {
+		log.Warnf("image URL dropped: the target provider needs inline data and fetching is disabled")
+		return "", "", false
+	}
+	mimeType, raw, err := fetchImage(strings.TrimSpace(ref), policy)
+	if err != nil {
+		log.Warnf("image URL dropped: %v", err)
+		return "", "", false
+	}
+	return mimeType, base64.StdEncoding.EncodeToString(raw), true
+}
+
+// InlineClaudeImage resolves the source of a Claude image block - base64 or url - into a mime
+// type and base64 data for providers that only accept inline data.
+func InlineClaudeImage(source gjson.Result) (mimeType, data string, ok bool) {
+	switch source.Get("type").String() {
+	case "base64":
+		data = source.Get("data").String()
+		if data == "" || !ImageWithinLimit(data) {
+			return "", "", false
+		}
+		mimeType = source.Get("media_type").String()
+		if mimeType == "" {
+			mimeType = "application/octet-stream"
+		}
+		return mimeType, data, true
+	case "url":
+		return InlineImage(source.Get("url").String())
+	}
+	return "", "", false
+}
+
+// fetchImage downloads an image, refusing responses that are not images or exceed the limit.
+func fetchImage(ref string, policy ImagePolicy) (string, []byte, error) {
+	client := &http.Client{Timeout: policy.FetchTimeout}
+	resp, err := client.Get(ref)
+	if err != nil {
+		return "", nil, fmt.Errorf("fetch failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("fetch answered %d", resp.StatusCode)
+	}
+	if resp.ContentLength > policy.MaxBytes {
+		return "", nil, fmt.Errorf("image of %d bytes exceeds the %d byte limit", resp.ContentLength, policy.MaxBytes)
+	}
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, policy.MaxBytes+1))
+	if err != nil {
+		return "", nil, fmt.Errorf("fetch failed: %w", err)
+	}
+	if int64(len(raw)) > policy.MaxBytes {
+		return "", nil, fmt.Errorf("image exceeds the %d byte limit", policy.MaxBytes)
+	}
+	mimeType := strings.TrimSpace(strings.Split(resp.Header.Get("Content-Type"), ";")[0])
+	if !strings.HasPrefix(mimeType, "image/") {
+		mimeType = http.DetectContentType(raw)
+	}
+	if !strings.HasPrefix(mimeType, "image/") {
+		return "", nil, fmt.Errorf("URL did not return an image (%s)", mimeType)
+	}
+	return mimeType, raw, nil
+}
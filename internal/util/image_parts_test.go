@@ -0,0 +1,72 @@
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestParseDataURL(t *testing.T) {
+	mime, data, ok := ParseDataURL("data:image/jpeg;base64,/9j/4AAQ")
+	if !ok || mime != "image/jpeg" || data != "/9j/4AAQ" {
+		t.Fatalf("ParseDataURL = %q %q %t", mime, data, ok)
+	}
+	for _, raw := range []string{"https://example.com/a.png", "data:image/png,plain", "data:image/png;base64,"} {
+		if _, _, ok := ParseDataURL(raw); ok {
+			t.Fatalf("ParseDataURL(%q) accepted", raw)
+		}
+	}
+}
+
+func TestInlineImageFetchesURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/img":
+			w.Header().Set("Content-Type", "image/gif")
+			_, _ = w.Write([]byte("GIF89a"))
+		case "/text":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	SetImagePolicy(ImagePolicy{FetchURLs: true})
+	defer SetImagePolicy(ImagePolicy{FetchURLs: true})
+
+	mime, data, ok := InlineImage(srv.URL + "/img")
+	if !ok || mime != "image/gif" || data != "R0lGODlh" {
+		t.Fatalf("InlineImage = %q %q %t", mime, data, ok)
+	}
+	if _, _, ok = InlineImage(srv.URL + "/text"); ok {
+		t.Fatal("non-image response accepted")
+	}
+	if _, _, ok = InlineImage(srv.URL + "/missing"); ok {
+		t.Fatal("404 accepted")
+	}
+
+	SetImagePolicy(ImagePolicy{FetchURLs: true, MaxBytes: 3})
+	if _, _, ok = InlineImage(srv.URL + "/img"); ok {
+		t.Fatal("oversized download accepted")
+	}
+	SetImagePolicy(ImagePolicy{FetchURLs: false})
+	if _, _, ok = InlineImage(srv.URL + "/img"); ok {
+		t.Fatal("URL fetched with fetching disabled")
+	}
+	if _, _, ok = InlineImage("data:image/gif;base64,R0lGODlh"); !ok {
+		t.Fatal("data URL rejected with fetching disabled")
+	}
+}
+
+func TestInlineClaudeImage(t *testing.T) {
+	mime, data, ok := InlineClaudeImage(gjson.Parse(`{"type":"base64","media_type":"image/webp","data":"UklGRg=="}`))
+	if !ok || mime != "image/webp" || data != "UklGRg==" {
+		t.Fatalf("InlineClaudeImage = %q %q %t", mime, data, ok)
+	}
+	if _, _, ok = InlineClaudeImage(gjson.Parse(`{"type":"file","file_id":"f1"}`)); ok {
+		t.Fatal("unsupported source accepted")
+	}
+}
@@ -0,0 +1,34 @@
+package util
+
+import "strings"
+
+// seedSupport records whether each built-in provider forwards a client-supplied sampling seed.
+// Gemini-family providers receive it as generationConfig.seed and OpenAI-compatible providers as
+// seed; Claude and Codex have no seed parameter, so their translators drop it.
+var seedSupport = map[string]bool{
+	"gemini":      true,
+	"gemini-cli":  true,
+	"vertex":      true,
+	"aistudio":    true,
+	"antigravity": true,
+	"qwen":        true,
+	"iflow":       true,
+	"claude":      false,
+	"codex":       false,
+}
+
+// SupportsSeed reports whether requests served by provider honour a sampling seed. Providers not in
+// the built-in table are OpenAI-compatible upstreams that receive the seed unchanged.
+func SupportsSeed(provider string) bool {
+	supported, ok := seedSupport[strings.ToLower(strings.TrimSpace(provider))]
+	return !ok || supported
+}
+
+// SeedSupport returns the seed support of the built-in providers.
+func SeedSupport() map[string]bool {
+	out := make(map[string]bool, len(seedSupport))
+	for provider, supported := range seedSupport {
+		out[provider] = supported
+	}
+	return out
+}
@@ -0,0 +1,49 @@
+package util
+
+import "strings"
+
+// defaultStopSequenceLimit is the OpenAI limit, applied to OpenAI-compatible upstreams not in the
+// built-in table.
+const defaultStopSequenceLimit = 4
+
+// stopSequenceLimits records how many stop sequences each built-in provider accepts. Zero means the
+// provider has no small fixed limit; Codex drops stop sequences altogether.
+var stopSequenceLimits = map[string]int{
+	"gemini":      5,
+	"gemini-cli":  5,
+	"vertex":      5,
+	"aistudio":    5,
+	"antigravity": 5,
+	"qwen":        4,
+	"iflow":       4,
+	"claude":      0,
+	"codex":       0,
+}
+
+// StopSequenceLimit returns how many stop sequences provider accepts, or 0 when it has no limit.
+// overrides, keyed by provider, take precedence over the built-in table.
+func StopSequenceLimit(provider string, overrides map[string]int) int {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	for name, limit := range overrides {
+		if strings.EqualFold(strings.TrimSpace(name), provider) {
+			return max(limit, 0)
+		}
+	}
+	if limit, ok := stopSequenceLimits[provider]; ok {
+		return limit
+	}
+	return defaultStopSequenceLimit
+}
+
+// StopSequenceLimits returns the stop sequence limits of the built-in providers and of every
+// provider named in overrides.
+func StopSequenceLimits(overrides map[string]int) map[string]int {
+	out := make(map[string]int, len(stopSequenceLimits)+len(overrides))
+	for provider := range stopSequenceLimits {
+		out[provider] = StopSequenceLimit(provider, overrides)
+	}
+	for provider := range overrides {
+		out[strings.ToLower(strings.TrimSpace(provider))] = StopSequenceLimit(provider, overrides)
+	}
+	return out
+}
@@ -1130,6 +1130,7 @@ func (w *Watcher) SnapshotCoreAuths() []*coreauth.Auth {
 				attrs["base_url"] = base
 			}
 			addConfigHeadersToAttrs(entry.Headers, attrs)
+			addConfigBillingHeadersToAttrs(entry.BillingHeaders, attrs)
 			a := &coreauth.Auth{
 				ID:         id,
 				Provider:   "gemini",
@@ -1163,6 +1164,7 @@ func (w *Watcher) SnapshotCoreAuths() []*coreauth.Auth {
 				attrs["models_hash"] = hash
 			}
 			addConfigHeadersToAttrs(ck.Headers, attrs)
+			addConfigBillingHeadersToAttrs(ck.BillingHeaders, attrs)
 			proxyURL := strings.TrimSpace(ck.ProxyURL)
 			a := &coreauth.Auth{
 				ID:         id,
@@ -1193,6 +1195,7 @@ func (w *Watcher) SnapshotCoreAuths() []*coreauth.Auth {
 				attrs["base_url"] = ck.BaseURL
 			}
 			addConfigHeadersToAttrs(ck.Headers, attrs)
+			addConfigBillingHeadersToAttrs(ck.BillingHeaders, attrs)
 			proxyURL := strings.TrimSpace(ck.ProxyURL)
 			a := &coreauth.Auth{
 				ID:         id,
@@ -1237,6 +1240,7 @@ func (w *Watcher) SnapshotCoreAuths() []*coreauth.Auth {
 						attrs["models_hash"] = hash
 					}
 					addConfigHeadersToAttrs(compat.Headers, attrs)
+					addConfigBillingHeadersToAttrs(compat.BillingHeaders, attrs)
 					a := &coreauth.Auth{
 						ID:         id,
 						Provider:   providerName,
@@ -1270,6 +1274,7 @@ func (w *Watcher) SnapshotCoreAuths() []*coreauth.Auth {
 						attrs["models_hash"] = hash
 					}
 					addConfigHeadersToAttrs(compat.Headers, attrs)
+					addConfigBillingHeadersToAttrs(compat.BillingHeaders, attrs)
 					a := &coreauth.Auth{
 						ID:         id,
 						Provider:   providerName,
@@ -1296,6 +1301,7 @@ func (w *Watcher) SnapshotCoreAuths() []*coreauth.Auth {
 					attrs["models_hash"] = hash
 				}
 				addConfigHeadersToAttrs(compat.Headers, attrs)
+				addConfigBillingHeadersToAttrs(compat.BillingHeaders, attrs)
 				a := &coreauth.Auth{
 					ID:         id,
 					Provider:   providerName,
@@ -1619,6 +1625,9 @@ func describeOpenAICompatibilityUpdate(oldEntry, newEntry config.OpenAICompatibi
 	if !equalStringMap(oldEntry.Headers, newEntry.Headers) {
 		details = append(details, "headers updated")
 	}
+	if !equalStringMap(oldEntry.BillingHeaders, newEntry.BillingHeaders) {
+		details = append(details, "billing-headers updated")
+	}
 	if len(details) == 0 {
 		return ""
 	}
@@ -1711,9 +1720,102 @@ func buildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 	if oldCfg.DisableCooling != newCfg.DisableCooling {
 		changes = append(changes, fmt.Sprintf("disable-cooling: %t -> %t", oldCfg.DisableCooling, newCfg.DisableCooling))
 	}
+	if oldCfg.DisableStreamRetry != newCfg.DisableStreamRetry {
+		changes = append(changes, fmt.Sprintf("disable-stream-retry: %t -> %t", oldCfg.DisableStreamRetry, newCfg.DisableStreamRetry))
+	}
+	if !reflect.DeepEqual(oldCfg.ClientErrorStatuses, newCfg.ClientErrorStatuses) {
+		changes = append(changes, fmt.Sprintf("client-error-statuses: %v -> %v", oldCfg.ClientErrorStatuses, newCfg.ClientErrorStatuses))
+	}
+	if oldCfg.EagerRefreshSeconds != newCfg.EagerRefreshSeconds {
+		changes = append(changes, fmt.Sprintf("eager-refresh-seconds: %d -> %d", oldCfg.EagerRefreshSeconds, newCfg.EagerRefreshSeconds))
+	}
+	if oldCfg.AccountConcurrencyQueueSeconds != newCfg.AccountConcurrencyQueueSeconds {
+		changes = append(changes, fmt.Sprintf("account-concurrency-queue-seconds: %d -> %d", oldCfg.AccountConcurrencyQueueSeconds, newCfg.AccountConcurrencyQueueSeconds))
+	}
+	if oldCfg.TokenExpiryPenalty != newCfg.TokenExpiryPenalty {
+		changes = append(changes, fmt.Sprintf("token-expiry-penalty: %d min/%.2f -> %d min/%.2f", oldCfg.TokenExpiryPenalty.WindowMinutes, oldCfg.TokenExpiryPenalty.MaxPenalty, newCfg.TokenExpiryPenalty.WindowMinutes, newCfg.TokenExpiryPenalty.MaxPenalty))
+	}
+	if !reflect.DeepEqual(oldCfg.GatedFeatures, newCfg.GatedFeatures) {
+		changes = append(changes, fmt.Sprintf("gated-features: %v -> %v", oldCfg.GatedFeatures, newCfg.GatedFeatures))
+	}
 	if oldCfg.RequestLog != newCfg.RequestLog {
 		changes = append(changes, fmt.Sprintf("request-log: %t -> %t", oldCfg.RequestLog, newCfg.RequestLog))
 	}
+	if oldCfg.DuplicateCredentials.Mode != newCfg.DuplicateCredentials.Mode {
+		changes = append(changes, fmt.Sprintf("duplicate-credentials.mode: %s -> %s", oldCfg.DuplicateCredentials.Mode, newCfg.DuplicateCredentials.Mode))
+	}
+	if oldCfg.RefreshConcurrency.DefaultLimit != newCfg.RefreshConcurrency.DefaultLimit || !reflect.DeepEqual(oldCfg.RefreshConcurrency.Limits, newCfg.RefreshConcurrency.Limits) {
+		changes = append(changes, fmt.Sprintf("refresh-concurrency: default %d -> %d, %d -> %d provider limits", oldCfg.RefreshConcurrency.DefaultLimit, newCfg.RefreshConcurrency.DefaultLimit, len(oldCfg.RefreshConcurrency.Limits), len(newCfg.RefreshConcurrency.Limits)))
+	}
+	if !reflect.DeepEqual(oldCfg.ModelRoutes, newCfg.ModelRoutes) {
+		changes = append(changes, fmt.Sprintf("model-routes: %d -> %d routes", len(oldCfg.ModelRoutes), len(newCfg.ModelRoutes)))
+	}
+	if oldCfg.EmptyResponse.Policy != newCfg.EmptyResponse.Policy {
+		changes = append(changes, fmt.Sprintf("empty-response.policy: %s -> %s", oldCfg.EmptyResponse.Policy, newCfg.EmptyResponse.Policy))
+	}
+	if !reflect.DeepEqual(oldCfg.QuotaBackoff, newCfg.QuotaBackoff) {
+		changes = append(changes, "quota-backoff: curves changed")
+	}
+	if oldCfg.LiveUsage.Enabled != newCfg.LiveUsage.Enabled {
+		changes = append(changes, fmt.Sprintf("live-usage.enabled: %t -> %t", oldCfg.LiveUsage.Enabled, newCfg.LiveUsage.Enabled))
+	}
+	if oldCfg.LiveUsage.IntervalSeconds != newCfg.LiveUsage.IntervalSeconds {
+		changes = append(changes, fmt.Sprintf("live-usage.interval-seconds: %d -> %d", oldCfg.LiveUsage.IntervalSeconds, newCfg.LiveUsage.IntervalSeconds))
+	}
+	if !reflect.DeepEqual(oldCfg.LiveUsage.APIKeys, newCfg.LiveUsage.APIKeys) {
+		changes = append(changes, fmt.Sprintf("live-usage.api-keys: %d -> %d keys", len(oldCfg.LiveUsage.APIKeys), len(newCfg.LiveUsage.APIKeys)))
+	}
+	if oldCfg.RecoveryHistorySize != newCfg.RecoveryHistorySize {
+		changes = append(changes, fmt.Sprintf("recovery-history-size: %d -> %d", oldCfg.RecoveryHistorySize, newCfg.RecoveryHistorySize))
+	}
+	if oldCfg.ImageTranslation != newCfg.ImageTranslation {
+		changes = append(changes, fmt.Sprintf("image-translation: url fetch disabled %t, max %d MB -> url fetch disabled %t, max %d MB", oldCfg.ImageTranslation.DisableURLFetch, oldCfg.ImageTranslation.MaxImageMB, newCfg.ImageTranslation.DisableURLFetch, newCfg.ImageTranslation.MaxImageMB))
+	}
+	if oldCfg.Sharding != newCfg.Sharding {
+		changes = append(changes, fmt.Sprintf("sharding: shard %d/%d (enabled %t) -> shard %d/%d (enabled %t)", oldCfg.Sharding.Index, oldCfg.Sharding.Count, oldCfg.Sharding.Enabled, newCfg.Sharding.Index, newCfg.Sharding.Count, newCfg.Sharding.Enabled))
+	}
+	if oldCfg.RateLimitHeaders != newCfg.RateLimitHeaders {
+		changes = append(changes, fmt.Sprintf("rate-limit-headers: enabled %t threshold %d -> enabled %t threshold %d", oldCfg.RateLimitHeaders.Enabled, oldCfg.RateLimitHeaders.CordonThreshold, newCfg.RateLimitHeaders.Enabled, newCfg.RateLimitHeaders.CordonThreshold))
+	}
+	if oldCfg.Drill != newCfg.Drill {
+		changes = append(changes, fmt.Sprintf("drill.enabled: %t -> %t", oldCfg.Drill.Enabled, newCfg.Drill.Enabled))
+	}
+	if oldCfg.SelectionStrategy != newCfg.SelectionStrategy {
+		changes = append(changes, fmt.Sprintf("selection-strategy: %s -> %s", oldCfg.SelectionStrategy, newCfg.SelectionStrategy))
+	}
+	if oldCfg.GlobalRateLimitRPM != newCfg.GlobalRateLimitRPM {
+		changes = append(changes, fmt.Sprintf("global-rate-limit-rpm: %d -> %d", oldCfg.GlobalRateLimitRPM, newCfg.GlobalRateLimitRPM))
+	}
+	if oldCfg.NormalizeRoles != newCfg.NormalizeRoles {
+		changes = append(changes, fmt.Sprintf("normalize-roles: %t -> %t", oldCfg.NormalizeRoles, newCfg.NormalizeRoles))
+	}
+	if oldCfg.SeedPolicy != newCfg.SeedPolicy {
+		changes = append(changes, fmt.Sprintf("seed-policy: %s -> %s", oldCfg.SeedPolicy, newCfg.SeedPolicy))
+	}
+	if !reflect.DeepEqual(oldCfg.StopSequenceLimits, newCfg.StopSequenceLimits) {
+		changes = append(changes, fmt.Sprintf("stop-sequence-limits: enabled %t -> %t, mode %q -> %q", oldCfg.StopSequenceLimits.Enabled, newCfg.StopSequenceLimits.Enabled, oldCfg.StopSequenceLimits.Mode, newCfg.StopSequenceLimits.Mode))
+	}
+	if oldCfg.MetricsPrefix != newCfg.MetricsPrefix {
+		changes = append(changes, fmt.Sprintf("metrics-prefix: %s -> %s", oldCfg.MetricsPrefix, newCfg.MetricsPrefix))
+	}
+	if oldCfg.MetricsHashAccountIDs != newCfg.MetricsHashAccountIDs {
+		changes = append(changes, fmt.Sprintf("metrics-hash-account-ids: %t -> %t", oldCfg.MetricsHashAccountIDs, newCfg.MetricsHashAccountIDs))
+	}
+	if oldCfg.MaskPoolDetails != newCfg.MaskPoolDetails {
+		changes = append(changes, fmt.Sprintf("mask-pool-details: %t -> %t", oldCfg.MaskPoolDetails, newCfg.MaskPoolDetails))
+	}
+	if oldCfg.ContextLengthErrors.Enabled != newCfg.ContextLengthErrors.Enabled {
+		changes = append(changes, fmt.Sprintf("context-length-errors.enabled: %t -> %t", oldCfg.ContextLengthErrors.Enabled, newCfg.ContextLengthErrors.Enabled))
+	}
+	if oldCfg.ContextLengthErrors.SuggestModel != newCfg.ContextLengthErrors.SuggestModel {
+		changes = append(changes, fmt.Sprintf("context-length-errors.suggest-model: %t -> %t", oldCfg.ContextLengthErrors.SuggestModel, newCfg.ContextLengthErrors.SuggestModel))
+	}
+	if oldCfg.ContextLengthErrors.AutoRoute != newCfg.ContextLengthErrors.AutoRoute {
+		changes = append(changes, fmt.Sprintf("context-length-errors.auto-route: %t -> %t", oldCfg.ContextLengthErrors.AutoRoute, newCfg.ContextLengthErrors.AutoRoute))
+	}
+	if !reflect.DeepEqual(oldCfg.ContextLengthErrors.Limits, newCfg.ContextLengthErrors.Limits) {
+		changes = append(changes, fmt.Sprintf("context-length-errors.limits: %d -> %d entries", len(oldCfg.ContextLengthErrors.Limits), len(newCfg.ContextLengthErrors.Limits)))
+	}
 	if oldCfg.RequestRetry != newCfg.RequestRetry {
 		changes = append(changes, fmt.Sprintf("request-retry: %d -> %d", oldCfg.RequestRetry, newCfg.RequestRetry))
 	}
@@ -1762,6 +1864,9 @@ func buildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 			if !equalStringMap(o.Headers, n.Headers) {
 				changes = append(changes, fmt.Sprintf("gemini[%d].headers: updated", i))
 			}
+			if !equalStringMap(o.BillingHeaders, n.BillingHeaders) {
+				changes = append(changes, fmt.Sprintf("gemini[%d].billing-headers: updated", i))
+			}
 			oldExcluded := summarizeExcludedModels(o.ExcludedModels)
 			newExcluded := summarizeExcludedModels(n.ExcludedModels)
 			if oldExcluded.hash != newExcluded.hash {
@@ -1795,6 +1900,9 @@ func buildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 			if !equalStringMap(o.Headers, n.Headers) {
 				changes = append(changes, fmt.Sprintf("claude[%d].headers: updated", i))
 			}
+			if !equalStringMap(o.BillingHeaders, n.BillingHeaders) {
+				changes = append(changes, fmt.Sprintf("claude[%d].billing-headers: updated", i))
+			}
 			oldExcluded := summarizeExcludedModels(o.ExcludedModels)
 			newExcluded := summarizeExcludedModels(n.ExcludedModels)
 			if oldExcluded.hash != newExcluded.hash {
@@ -1825,6 +1933,9 @@ func buildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 			if !equalStringMap(o.Headers, n.Headers) {
 				changes = append(changes, fmt.Sprintf("codex[%d].headers: updated", i))
 			}
+			if !equalStringMap(o.BillingHeaders, n.BillingHeaders) {
+				changes = append(changes, fmt.Sprintf("codex[%d].billing-headers: updated", i))
+			}
 			oldExcluded := summarizeExcludedModels(o.ExcludedModels)
 			newExcluded := summarizeExcludedModels(n.ExcludedModels)
 			if oldExcluded.hash != newExcluded.hash {
@@ -1880,6 +1991,22 @@ func addConfigHeadersToAttrs(headers map[string]string, attrs map[string]string)
 	}
 }
 
+// addConfigBillingHeadersToAttrs stores billing attribution headers under the
+// coreauth.BillingHeaderAttrPrefix so executors can tell them apart from custom headers.
+func addConfigBillingHeadersToAttrs(headers map[string]string, attrs map[string]string) {
+	if len(headers) == 0 || attrs == nil {
+		return
+	}
+	for hk, hv := range headers {
+		key := strings.TrimSpace(hk)
+		val := strings.TrimSpace(hv)
+		if key == "" || val == "" {
+			continue
+		}
+		attrs[coreauth.BillingHeaderAttrPrefix+key] = val
+	}
+}
+
 func trimStrings(in []string) []string {
 	out := make([]string, len(in))
 	for i := range in {
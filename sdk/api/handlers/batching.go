@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// batchModeHeader lets clients mark non-latency-sensitive requests as eligible for upstream batching.
+const batchModeHeader = "X-Batch-Mode"
+
+// batchRequested reports whether the inbound request opted into batching.
+func batchRequested(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil || ginCtx.Request == nil {
+		return false
+	}
+	raw := strings.TrimSpace(ginCtx.GetHeader(batchModeHeader))
+	if raw == "" {
+		return false
+	}
+	enabled, err := strconv.ParseBool(raw)
+	return err == nil && enabled
+}
+
+// markBatchRequested flags opts for the auth manager's batcher when the client opted in.
+func markBatchRequested(ctx context.Context, opts *coreexecutor.Options) {
+	if !batchRequested(ctx) {
+		return
+	}
+	if opts.Metadata == nil {
+		opts.Metadata = make(map[string]any)
+	}
+	opts.Metadata[coreexecutor.BatchMetadataKey] = true
+}
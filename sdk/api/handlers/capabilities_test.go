@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func TestCapabilityRequestKeepsModelForSelection(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("capability-gemini", "gemini", []*registry.ModelInfo{{ID: "cap-handler-gemini"}})
+	reg.RegisterClient("capability-claude", "claude", []*registry.ModelInfo{{ID: "cap-handler-claude"}})
+	t.Cleanup(func() {
+		reg.UnregisterClient("capability-gemini")
+		reg.UnregisterClient("capability-claude")
+	})
+	h := &BaseAPIHandler{Cfg: &sdkconfig.SDKConfig{ModelCapabilities: map[string][]string{
+		"cap-handler-gemini": {"vision", "tools"},
+		"cap-handler-claude": {"vision"},
+	}}}
+
+	providers, model, _, errMsg := h.getRequestDetails("capabilities:vision")
+	sort.Strings(providers)
+	if errMsg != nil || model != "capabilities:vision" || !reflect.DeepEqual(providers, []string{"claude", "gemini"}) {
+		t.Fatalf("details = %v, %q, %+v; want every provider serving a match and the capability name kept", providers, model, errMsg)
+	}
+	if providers, _, _, errMsg = h.getRequestDetails("capabilities:vision,tools"); errMsg != nil || !reflect.DeepEqual(providers, []string{"gemini"}) {
+		t.Fatalf("vision+tools providers = %v, %+v", providers, errMsg)
+	}
+	if _, _, _, errMsg = h.getRequestDetails("capabilities:audio"); errMsg == nil || errMsg.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unmatched capabilities = %+v, want 400", errMsg)
+	}
+}
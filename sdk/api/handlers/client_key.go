@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"context"
+
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// markClientKey passes the authenticated client API key to selection for per-client overrides.
+func markClientKey(ctx context.Context, opts *coreexecutor.Options) {
+	key := requestAPIKey(ctx)
+	if key == "" {
+		return
+	}
+	if opts.Metadata == nil {
+		opts.Metadata = make(map[string]any)
+	}
+	opts.Metadata[coreexecutor.ClientKeyMetadataKey] = key
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// contextLengthMarkers are lowercase fragments of the context-length errors each provider returns:
+// OpenAI and compatible APIs, Claude, Gemini and Qwen.
+var contextLengthMarkers = []string{
+	"context_length_exceeded",
+	"maximum context length",
+	"prompt is too long",
+	"exceed context limit",
+	"exceeds the maximum number of tokens",
+	"range of input length",
+	"input length should be",
+}
+
+var tokenCountPattern = regexp.MustCompile(`\d{3,}`)
+
+// contextRerouteKey marks a request already rerouted to a larger-context model so it is rerouted once.
+type contextRerouteKey struct{}
+
+// isContextLengthError reports whether the upstream error says the request exceeds the context window.
+func isContextLengthError(status int, message string) bool {
+	if status != http.StatusBadRequest && status != http.StatusRequestEntityTooLarge && status != http.StatusUnprocessableEntity {
+		return false
+	}
+	lower := strings.ToLower(message)
+	for _, marker := range contextLengthMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestedTokens extracts the prompt size from a context-length error. Every provider states the
+// requested size alongside a smaller limit, so the largest number is the requested one.
+func requestedTokens(message string) int {
+	var best int
+	for _, match := range tokenCountPattern.FindAllString(message, -1) {
+		if n, err := strconv.Atoi(match); err == nil && n > best {
+			best = n
+		}
+	}
+	return best
+}
+
+// contextLengthError converts a context-length failure into a normalised OpenAI-style error stating the
+// model's context window, and returns the larger-context model suggested to the client. It returns nil
+// when err is not a context-length error or the feature is off.
+func (h *BaseAPIHandler) contextLengthError(model string, err error) (*interfaces.ErrorMessage, string) {
+	if h.Cfg == nil || !h.Cfg.ContextLengthErrors.Enabled || err == nil {
+		return nil, ""
+	}
+	status := http.StatusBadRequest
+	if se, ok := err.(interface{ StatusCode() int }); ok && se != nil && se.StatusCode() > 0 {
+		status = se.StatusCode()
+	}
+	if !isContextLengthError(status, err.Error()) {
+		return nil, ""
+	}
+	settings := h.Cfg.ContextLengthErrors
+	limit := util.ModelContextWindow(model, settings.Limits)
+	requested := requestedTokens(err.Error())
+
+	detail := map[string]any{
+		"type": "invalid_request_error",
+		"code": "context_length_exceeded",
+	}
+	var message strings.Builder
+	if limit > 0 {
+		detail["context_limit"] = limit
+		fmt.Fprintf(&message, "the request exceeds the %d-token context window of %s", limit, model)
+	} else {
+		fmt.Fprintf(&message, "the request exceeds the context window of %s", model)
+	}
+	if requested > limit {
+		detail["requested_tokens"] = requested
+		fmt.Fprintf(&message, " (%d tokens requested)", requested)
+	}
+	var suggested string
+	if settings.SuggestModel || settings.AutoRoute {
+		var window int
+		if suggested, window = util.LargerContextModel(max(requested, limit), model, settings.Limits); suggested != "" {
+			detail["suggested_model"] = suggested
+			detail["suggested_model_context_limit"] = window
+			fmt.Fprintf(&message, "; retry with %s, which has a %d-token context window", suggested, window)
+		}
+	}
+	detail["message"] = message.String()
+	body, _ := json.Marshal(map[string]any{"error": detail})
+	addon := make(http.Header)
+	addon.Set("Content-Type", "application/json")
+	return &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: errors.New(string(body)), Addon: addon}, suggested
+}
+
+// upstreamErrorMessage returns the normalised context-length error for err when it is one, otherwise
+// the regular manager error message.
+func (h *BaseAPIHandler) upstreamErrorMessage(model string, err error) *interfaces.ErrorMessage {
+	if msg, _ := h.contextLengthError(model, err); msg != nil {
+		return msg
+	}
+	return h.managerErrorMessage(err)
+}
+
+// contextReroute returns the model a context-length failure is retried on, with the request rewritten
+// for it, or "" when auto-routing is off, no larger model is available or the request was already
+// rerouted.
+func (h *BaseAPIHandler) contextReroute(ctx context.Context, model string, rawJSON []byte, err error) (context.Context, string, []byte) {
+	if h.Cfg == nil || !h.Cfg.ContextLengthErrors.AutoRoute || ctx.Value(contextRerouteKey{}) != nil {
+		return ctx, "", rawJSON
+	}
+	msg, suggested := h.contextLengthError(model, err)
+	if msg == nil || suggested == "" {
+		return ctx, "", rawJSON
+	}
+	// Rerouting never moves a client key onto a model outside its allowlist.
+	if !h.Cfg.ClientModelPolicyFor(requestAPIKey(ctx)).Allows(suggested) {
+		return ctx, "", rawJSON
+	}
+	if gjson.GetBytes(rawJSON, "model").Exists() {
+		if updated, errSet := sjson.SetBytes(rawJSON, "model", suggested); errSet == nil {
+			rawJSON = updated
+		}
+	}
+	if ginCtx, ok := ctx.Value("gin").(*gin.Context); ok && ginCtx != nil {
+		ginCtx.Header("X-Context-Rerouted-Model", suggested)
+	}
+	return context.WithValue(ctx, contextRerouteKey{}, model), suggested, rawJSON
+}
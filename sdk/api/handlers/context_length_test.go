@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func registerContextModels(t *testing.T) {
+	t.Helper()
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("context-length-test", "openai", []*registry.ModelInfo{
+		{ID: "ctx-small", Object: "model", ContextLength: 8000},
+		{ID: "ctx-mid", Object: "model", ContextLength: 32000},
+		{ID: "ctx-huge", Object: "model", InputTokenLimit: 1000000},
+	})
+	t.Cleanup(func() { reg.UnregisterClient("context-length-test") })
+}
+
+func TestContextLengthErrorSuggestsClosestLargerModel(t *testing.T) {
+	registerContextModels(t)
+	h := &BaseAPIHandler{Cfg: &sdkconfig.SDKConfig{ContextLengthErrors: sdkconfig.ContextLengthErrors{Enabled: true, SuggestModel: true}}}
+	upstream := &coreauth.Error{HTTPStatus: http.StatusBadRequest, Message: `{"error":{"message":"This model's maximum context length is 8000 tokens. However, your messages resulted in 12000 tokens.","code":"context_length_exceeded"}}`}
+
+	msg, suggested := h.contextLengthError("ctx-small", upstream)
+	if msg == nil || suggested != "ctx-mid" {
+		t.Fatalf("suggested = %q, msg = %v", suggested, msg)
+	}
+	body := msg.Error.Error()
+	if gjson.Get(body, "error.code").String() != "context_length_exceeded" || gjson.Get(body, "error.context_limit").Int() != 8000 ||
+		gjson.Get(body, "error.requested_tokens").Int() != 12000 || gjson.Get(body, "error.suggested_model").String() != "ctx-mid" {
+		t.Fatalf("unexpected body: %s", body)
+	}
+
+	h.Cfg.ContextLengthErrors.Limits = map[string]int{"ctx-mid": 10000}
+	if _, suggested = h.contextLengthError("ctx-small", upstream); suggested != "ctx-huge" {
+		t.Fatalf("override ignored, suggested %q", suggested)
+	}
+
+	claude := &coreauth.Error{HTTPStatus: http.StatusBadRequest, Message: "prompt is too long: 2000000 tokens > 1000000 maximum"}
+	if msg, suggested = h.contextLengthError("ctx-huge", claude); msg == nil || suggested != "" {
+		t.Fatalf("no model is larger, got %q", suggested)
+	}
+	if msg = h.upstreamErrorMessage("ctx-small", errors.New("rate limited")); gjson.Get(msg.Error.Error(), "error.code").Exists() {
+		t.Fatalf("unrelated errors must pass through: %s", msg.Error.Error())
+	}
+
+	h.Cfg.ContextLengthErrors.Enabled = false
+	if msg, _ = h.contextLengthError("ctx-small", upstream); msg != nil {
+		t.Fatal("disabled feature must not rewrite errors")
+	}
+}
+
+func TestContextRerouteRewritesModelOnce(t *testing.T) {
+	registerContextModels(t)
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	ginCtx, _ := gin.CreateTestContext(rec)
+	ctx := context.WithValue(context.Background(), "gin", ginCtx)
+	h := &BaseAPIHandler{Cfg: &sdkconfig.SDKConfig{ContextLengthErrors: sdkconfig.ContextLengthErrors{Enabled: true, AutoRoute: true}}}
+	upstream := &coreauth.Error{HTTPStatus: http.StatusBadRequest, Message: "The input token count (9000) exceeds the maximum number of tokens allowed (8000)."}
+
+	rerouteCtx, larger, rawJSON := h.contextReroute(ctx, "ctx-small", []byte(`{"model":"ctx-small","messages":[]}`), upstream)
+	if larger != "ctx-mid" || gjson.GetBytes(rawJSON, "model").String() != "ctx-mid" {
+		t.Fatalf("reroute = %q, body %s", larger, rawJSON)
+	}
+	if rec.Header().Get("X-Context-Rerouted-Model") != "ctx-mid" {
+		t.Fatalf("missing reroute header: %v", rec.Header())
+	}
+	if _, again, _ := h.contextReroute(rerouteCtx, "ctx-mid", rawJSON, upstream); again != "" {
+		t.Fatalf("request rerouted twice to %q", again)
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// openAIFinishReasons maps lowercased provider finish reasons to the OpenAI vocabulary.
+// Reasons not listed here are reported as "stop".
+var openAIFinishReasons = map[string]string{
+	// OpenAI and compatible backends.
+	"stop":           "stop",
+	"length":         "length",
+	"tool_calls":     "tool_calls",
+	"function_call":  "tool_calls",
+	"content_filter": "content_filter",
+	// Claude.
+	"end_turn":      "stop",
+	"stop_sequence": "stop",
+	"pause_turn":    "stop",
+	"max_tokens":    "length",
+	"tool_use":      "tool_calls",
+	"refusal":       "content_filter",
+	// Gemini, Gemini CLI and Antigravity.
+	"max_tokens_reached":      "length",
+	"safety":                  "content_filter",
+	"recitation":              "content_filter",
+	"blocklist":               "content_filter",
+	"prohibited_content":      "content_filter",
+	"spii":                    "content_filter",
+	"image_safety":            "content_filter",
+	"malformed_function_call": "tool_calls",
+	// Codex (Responses API).
+	"completed":         "stop",
+	"max_output_tokens": "length",
+}
+
+// normalizedFinishReason maps a provider finish reason to stop, length, tool_calls or content_filter.
+func normalizedFinishReason(native string) string {
+	if mapped, ok := openAIFinishReasons[strings.ToLower(strings.TrimSpace(native))]; ok {
+		return mapped
+	}
+	return "stop"
+}
+
+// normalizesFinishReason reports whether finish reasons are normalised for this request.
+func (h *BaseAPIHandler) normalizesFinishReason(ctx context.Context, handlerType string) bool {
+	return handlerType == constant.OpenAI && h.Cfg != nil && h.Cfg.PostProcessing.NormalizeFinishReasonFor(requestAPIKey(ctx))
+}
+
+// normalizeFinishReasons rewrites every choice's finish_reason of a chat completion or chunk to the
+// OpenAI vocabulary. Translators record the provider's value in native_finish_reason before mapping
+// it; when they did not, as for OpenAI-compatible passthrough, the untranslated finish_reason is kept.
+func normalizeFinishReasons(payload []byte) []byte {
+	if !gjson.ValidBytes(payload) {
+		return payload
+	}
+	for i, choice := range gjson.GetBytes(payload, "choices").Array() {
+		native := choice.Get("finish_reason")
+		if native.Type != gjson.String || native.String() == "" {
+			continue
+		}
+		if choice.Get("native_finish_reason").Type != gjson.String {
+			payload, _ = sjson.SetBytes(payload, fmt.Sprintf("choices.%d.native_finish_reason", i), native.String())
+		}
+		if mapped := normalizedFinishReason(native.String()); mapped != native.String() {
+			payload, _ = sjson.SetBytes(payload, fmt.Sprintf("choices.%d.finish_reason", i), mapped)
+		}
+	}
+	return payload
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	_ "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator/builtin"
+	"github.com/tidwall/gjson"
+)
+
+func TestNormalizeFinishReasons(t *testing.T) {
+	cases := []struct {
+		provider string
+		native   string
+		want     string
+	}{
+		{"openai", "stop", "stop"},
+		{"openai", "length", "length"},
+		{"openai", "tool_calls", "tool_calls"},
+		{"openai", "content_filter", "content_filter"},
+		{"claude", "end_turn", "stop"},
+		{"claude", "stop_sequence", "stop"},
+		{"claude", "max_tokens", "length"},
+		{"claude", "tool_use", "tool_calls"},
+		{"claude", "refusal", "content_filter"},
+		{"gemini", "STOP", "stop"},
+		{"gemini", "MAX_TOKENS", "length"},
+		{"gemini", "SAFETY", "content_filter"},
+		{"gemini", "RECITATION", "content_filter"},
+		{"gemini", "PROHIBITED_CONTENT", "content_filter"},
+		{"gemini", "MALFORMED_FUNCTION_CALL", "tool_calls"},
+		{"gemini", "OTHER", "stop"},
+		{"codex", "completed", "stop"},
+		{"codex", "max_output_tokens", "length"},
+	}
+	for _, tc := range cases {
+		payload := []byte(`{"choices":[{"index":0,"delta":{},"finish_reason":"` + tc.native + `"}]}`)
+		got := normalizeFinishReasons(payload)
+		if reason := gjson.GetBytes(got, "choices.0.finish_reason").String(); reason != tc.want {
+			t.Fatalf("%s %q: finish_reason = %q, want %q", tc.provider, tc.native, reason, tc.want)
+		}
+		if native := gjson.GetBytes(got, "choices.0.native_finish_reason").String(); native != tc.native {
+			t.Fatalf("%s %q: native_finish_reason = %q", tc.provider, tc.native, native)
+		}
+	}
+}
+
+func TestNormalizeFinishReasonsKeepsExistingNative(t *testing.T) {
+	payload := []byte(`{"choices":[{"finish_reason":"STOP","native_finish_reason":"STOP"},{"finish_reason":null}]}`)
+	got := normalizeFinishReasons(payload)
+	if gjson.GetBytes(got, "choices.0.finish_reason").String() != "stop" || gjson.GetBytes(got, "choices.0.native_finish_reason").String() != "STOP" {
+		t.Fatalf("unexpected first choice: %s", got)
+	}
+	if gjson.GetBytes(got, "choices.1.finish_reason").Type != gjson.Null || gjson.GetBytes(got, "choices.1.native_finish_reason").Exists() {
+		t.Fatalf("in-progress chunks must be left alone: %s", got)
+	}
+}
+
+func TestNormalizeFinishReasonsKeepsProviderValueThroughTranslation(t *testing.T) {
+	cases := []struct {
+		provider string
+		raw      string
+		want     string
+		native   string
+	}{
+		{constant.Claude, "data: {\"type\":\"message_start\",\"message\":{\"id\":\"msg\"}}\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\"}}", "stop", "end_turn"},
+		{constant.Claude, "data: {\"type\":\"message_start\",\"message\":{\"id\":\"msg\"}}\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"max_tokens\"}}", "length", "max_tokens"},
+		{constant.Gemini, `{"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"f","args":{}}}]},"finishReason":"STOP"}]}`, "tool_calls", "STOP"},
+		{constant.Codex, `{"type":"response.completed","response":{"id":"r","status":"completed","output":[{"type":"message","content":[{"type":"output_text","text":"hi"}]}]}}`, "stop", "completed"},
+	}
+	request := []byte(`{"model":"m","messages":[{"role":"user","content":"hi"}]}`)
+	for _, tc := range cases {
+		var param any
+		out := sdktranslator.TranslateNonStream(context.Background(), sdktranslator.FromString(tc.provider), sdktranslator.FromString(constant.OpenAI), "m", request, request, []byte(tc.raw), &param)
+		got := normalizeFinishReasons([]byte(out))
+		if reason := gjson.GetBytes(got, "choices.0.finish_reason").String(); reason != tc.want {
+			t.Fatalf("%s: finish_reason = %q, want %q in %s", tc.provider, reason, tc.want, got)
+		}
+		if native := gjson.GetBytes(got, "choices.0.native_finish_reason").String(); native != tc.native {
+			t.Fatalf("%s: native_finish_reason = %q, want %q", tc.provider, native, tc.native)
+		}
+	}
+}
+
+func TestNormalizeFinishReasonsKeepsClaudeStopReasonWhenStreaming(t *testing.T) {
+	request := []byte(`{"model":"m","stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+	var param any
+	var chunk string
+	for _, line := range []string{
+		`data: {"type":"message_start","message":{"id":"msg","model":"m","usage":{"input_tokens":1,"output_tokens":0}}}`,
+		`data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":1}}`,
+	} {
+		for _, out := range sdktranslator.TranslateStream(context.Background(), sdktranslator.FromString(constant.Claude), sdktranslator.FromString(constant.OpenAI), "m", request, request, []byte(line), &param) {
+			chunk = out
+		}
+	}
+	got := normalizeFinishReasons([]byte(chunk))
+	if gjson.GetBytes(got, "choices.0.finish_reason").String() != "tool_calls" || gjson.GetBytes(got, "choices.0.native_finish_reason").String() != "tool_use" {
+		t.Fatalf("final chunk = %s, want tool_calls with native tool_use", got)
+	}
+}
@@ -49,7 +49,7 @@ func (h *GeminiAPIHandler) Models() []map[string]any {
 // It returns a JSON response containing available Gemini models and their specifications.
 func (h *GeminiAPIHandler) GeminiModels(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
-		"models": h.Models(),
+		"models": h.WithModelAvailability(h.Models(), "name"),
 	})
 }
 
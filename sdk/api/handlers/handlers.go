@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
@@ -112,6 +113,13 @@ func (h *BaseAPIHandler) GetAlt(c *gin.Context) string {
 //   - context.Context: The new context with cancellation and embedded values.
 //   - APIHandlerCancelFunc: A function to cancel the context and log the response.
 func (h *BaseAPIHandler) GetContextWithCancel(handler interfaces.APIHandler, c *gin.Context, ctx context.Context) (context.Context, APIHandlerCancelFunc) {
+	// Carry a client deadline set on the inbound request into selection, retries and the upstream call.
+	cancelDeadline := context.CancelFunc(func() {})
+	if c != nil && c.Request != nil {
+		if deadline, ok := c.Request.Context().Deadline(); ok {
+			ctx, cancelDeadline = context.WithDeadline(ctx, deadline)
+		}
+	}
 	newCtx, cancel := context.WithCancel(ctx)
 	newCtx = context.WithValue(newCtx, "gin", c)
 	newCtx = context.WithValue(newCtx, "handler", handler)
@@ -133,6 +141,7 @@ func (h *BaseAPIHandler) GetContextWithCancel(handler interfaces.APIHandler, c *
 		}
 
 		cancel()
+		cancelDeadline()
 	}
 }
 
@@ -161,10 +170,37 @@ func appendAPIResponse(c *gin.Context, data []byte) {
 // ExecuteWithAuthManager executes a non-streaming request via the core auth manager.
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
+	ctx, finishOverhead := h.trackOverhead(ctx)
+	defer finishOverhead(0)
+	return h.execute(ctx, handlerType, modelName, rawJSON, alt)
+}
+
+// execute runs one non-streaming request; a context-length failure may rerun it once on a larger model.
+func (h *BaseAPIHandler) execute(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
+	modelName, rawJSON = h.applyDefaultModel(ctx, handlerType, modelName, rawJSON)
 	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
+	if errMsg == nil {
+		errMsg = h.checkModelPolicy(ctx, normalizedModel)
+	}
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	if h.normalizesRoles() {
+		rawJSON = normalizeRequestRoles(handlerType, rawJSON)
+	}
+	rawJSON, errMsg = h.applyRequestClamps(ctx, handlerType, rawJSON)
 	if errMsg != nil {
 		return nil, errMsg
 	}
+	providers, errMsg = h.applySeedPolicy(handlerType, normalizedModel, providers, rawJSON)
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	providers, rawJSON, errMsg = h.applyStopSequenceLimits(handlerType, normalizedModel, providers, rawJSON)
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	ctx, served := trackSeed(ctx, handlerType, rawJSON)
 	req := coreexecutor.Request{
 		Model:   normalizedModel,
 		Payload: cloneBytes(rawJSON),
@@ -181,32 +217,56 @@ func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType
 	if cloned := cloneMetadata(metadata); cloned != nil {
 		opts.Metadata = cloned
 	}
+	markBatchRequested(ctx, &opts)
+	markReservation(ctx, &opts)
+	markPinnedAccount(ctx, &opts)
+	markClientKey(ctx, &opts)
+	h.markRecording(ctx, handlerType, normalizedModel, rawJSON)
+	h.markPrefixAffinity(ctx, rawJSON, &opts)
+	stops := h.stopTrimmingStops(ctx, handlerType, rawJSON)
 	resp, err := h.AuthManager.Execute(ctx, providers, req, opts)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
-			if code := se.StatusCode(); code > 0 {
-				status = code
-			}
+		if rerouteCtx, larger, reroutedJSON := h.contextReroute(ctx, normalizedModel, rawJSON, err); larger != "" {
+			return h.execute(rerouteCtx, handlerType, larger, reroutedJSON, alt)
 		}
-		var addon http.Header
-		if he, ok := err.(interface{ Headers() http.Header }); ok && he != nil {
-			if hdr := he.Headers(); hdr != nil {
-				addon = hdr.Clone()
-			}
+		return nil, h.upstreamErrorMessage(normalizedModel, err)
+	}
+	setSeedHeader(ctx, served)
+	payload := cloneBytes(resp.Payload)
+	if len(stops) > 0 {
+		payload = trimCompletionAtStops(handlerType, payload, stops)
+	}
+	if h.normalizesFinishReason(ctx, handlerType) {
+		payload = normalizeFinishReasons(payload)
+	}
+	if marker := h.truncationMarkerFor(ctx, handlerType, rawJSON); marker != nil {
+		var truncated bool
+		if payload, truncated = marker.mark(payload); truncated {
+			setTruncationHeaders(ctx, payload)
 		}
-		return nil, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
 	}
-	return cloneBytes(resp.Payload), nil
+	if h.stripsProviderMetadata(ctx, handlerType) {
+		payload = stripProviderMetadata(payload, normalizedModel)
+	}
+	return payload, nil
 }
 
 // ExecuteCountWithAuthManager executes a non-streaming request via the core auth manager.
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
+	ctx, finishOverhead := h.trackOverhead(ctx)
+	defer finishOverhead(0)
+	modelName, rawJSON = h.applyDefaultModel(ctx, handlerType, modelName, rawJSON)
 	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
+	if errMsg == nil {
+		errMsg = h.checkModelPolicy(ctx, normalizedModel)
+	}
 	if errMsg != nil {
 		return nil, errMsg
 	}
+	if h.normalizesRoles() {
+		rawJSON = normalizeRequestRoles(handlerType, rawJSON)
+	}
 	req := coreexecutor.Request{
 		Model:   normalizedModel,
 		Payload: cloneBytes(rawJSON),
@@ -223,21 +283,12 @@ func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handle
 	if cloned := cloneMetadata(metadata); cloned != nil {
 		opts.Metadata = cloned
 	}
+	markReservation(ctx, &opts)
+	markPinnedAccount(ctx, &opts)
+	markClientKey(ctx, &opts)
 	resp, err := h.AuthManager.ExecuteCount(ctx, providers, req, opts)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
-			if code := se.StatusCode(); code > 0 {
-				status = code
-			}
-		}
-		var addon http.Header
-		if he, ok := err.(interface{ Headers() http.Header }); ok && he != nil {
-			if hdr := he.Headers(); hdr != nil {
-				addon = hdr.Clone()
-			}
-		}
-		return nil, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+		return nil, h.managerErrorMessage(err)
 	}
 	return cloneBytes(resp.Payload), nil
 }
@@ -245,13 +296,43 @@ func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handle
 // ExecuteStreamWithAuthManager executes a streaming request via the core auth manager.
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
+	ctx, finishOverhead := h.trackOverhead(ctx)
+	return h.executeStream(ctx, finishOverhead, handlerType, modelName, rawJSON, alt)
+}
+
+// executeStream starts one streaming request; a context-length failure before the first chunk may
+// restart it once on a larger model. finishOverhead is called when the request ends.
+func (h *BaseAPIHandler) executeStream(ctx context.Context, finishOverhead func(time.Duration), handlerType, modelName string, rawJSON []byte, alt string) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
+	modelName, rawJSON = h.applyDefaultModel(ctx, handlerType, modelName, rawJSON)
 	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
+	if errMsg == nil {
+		errMsg = h.checkModelPolicy(ctx, normalizedModel)
+	}
+	if errMsg != nil {
+		errChan := make(chan *interfaces.ErrorMessage, 1)
+		errChan <- errMsg
+		close(errChan)
+		finishOverhead(0)
+		return nil, errChan
+	}
+	if h.normalizesRoles() {
+		rawJSON = normalizeRequestRoles(handlerType, rawJSON)
+	}
+	rawJSON, errMsg = h.applyRequestClamps(ctx, handlerType, rawJSON)
+	if errMsg == nil {
+		providers, errMsg = h.applySeedPolicy(handlerType, normalizedModel, providers, rawJSON)
+	}
+	if errMsg == nil {
+		providers, rawJSON, errMsg = h.applyStopSequenceLimits(handlerType, normalizedModel, providers, rawJSON)
+	}
 	if errMsg != nil {
 		errChan := make(chan *interfaces.ErrorMessage, 1)
 		errChan <- errMsg
 		close(errChan)
+		finishOverhead(0)
 		return nil, errChan
 	}
+	ctx, served := trackSeed(ctx, handlerType, rawJSON)
 	req := coreexecutor.Request{
 		Model:   normalizedModel,
 		Payload: cloneBytes(rawJSON),
@@ -268,49 +349,75 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 	if cloned := cloneMetadata(metadata); cloned != nil {
 		opts.Metadata = cloned
 	}
+	markReservation(ctx, &opts)
+	markPinnedAccount(ctx, &opts)
+	markClientKey(ctx, &opts)
+	markLiveUsage(ctx, &opts)
+	h.markRecording(ctx, handlerType, normalizedModel, rawJSON)
+	h.markPrefixAffinity(ctx, rawJSON, &opts)
+	var stopTrim *streamStopTrimmer
+	// Only SSE framing delivers one complete payload per chunk; raw Gemini JSON streams are left untouched.
+	if alt == "" {
+		if stops := h.stopTrimmingStops(ctx, handlerType, rawJSON); len(stops) > 0 {
+			stopTrim = newStreamStopTrimmer(handlerType, stops)
+		}
+	}
+	normalizeFinish := alt == "" && h.normalizesFinishReason(ctx, handlerType)
+	stripMetadata := alt == "" && h.stripsProviderMetadata(ctx, handlerType)
+	var truncation *truncationMarker
+	if alt == "" {
+		truncation = h.truncationMarkerFor(ctx, handlerType, rawJSON)
+	}
 	chunks, err := h.AuthManager.ExecuteStream(ctx, providers, req, opts)
 	if err != nil {
-		errChan := make(chan *interfaces.ErrorMessage, 1)
-		status := http.StatusInternalServerError
-		if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
-			if code := se.StatusCode(); code > 0 {
-				status = code
-			}
-		}
-		var addon http.Header
-		if he, ok := err.(interface{ Headers() http.Header }); ok && he != nil {
-			if hdr := he.Headers(); hdr != nil {
-				addon = hdr.Clone()
-			}
+		if rerouteCtx, larger, reroutedJSON := h.contextReroute(ctx, normalizedModel, rawJSON, err); larger != "" {
+			return h.executeStream(rerouteCtx, finishOverhead, handlerType, larger, reroutedJSON, alt)
 		}
-		errChan <- &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+		errChan := make(chan *interfaces.ErrorMessage, 1)
+		errChan <- h.upstreamErrorMessage(normalizedModel, err)
 		close(errChan)
+		finishOverhead(0)
 		return nil, errChan
 	}
+	setSeedHeader(ctx, served)
 	dataChan := make(chan []byte)
 	errChan := make(chan *interfaces.ErrorMessage, 1)
 	go func() {
+		var clientWait time.Duration
+		defer func() { finishOverhead(clientWait) }()
 		defer close(dataChan)
 		defer close(errChan)
+		send := func(payload []byte) {
+			if normalizeFinish {
+				payload = normalizeFinishReasons(payload)
+			}
+			payload, _ = truncation.mark(payload)
+			if stripMetadata {
+				payload = stripProviderMetadata(payload, normalizedModel)
+			}
+			sendStarted := time.Now()
+			dataChan <- payload
+			clientWait += time.Since(sendStarted)
+		}
 		for chunk := range chunks {
 			if chunk.Err != nil {
-				status := http.StatusInternalServerError
-				if se, ok := chunk.Err.(interface{ StatusCode() int }); ok && se != nil {
-					if code := se.StatusCode(); code > 0 {
-						status = code
-					}
-				}
-				var addon http.Header
-				if he, ok := chunk.Err.(interface{ Headers() http.Header }); ok && he != nil {
-					if hdr := he.Headers(); hdr != nil {
-						addon = hdr.Clone()
-					}
-				}
-				errChan <- &interfaces.ErrorMessage{StatusCode: status, Error: chunk.Err, Addon: addon}
+				errChan <- h.upstreamErrorMessage(normalizedModel, chunk.Err)
 				return
 			}
 			if len(chunk.Payload) > 0 {
-				dataChan <- cloneBytes(chunk.Payload)
+				payload := cloneBytes(chunk.Payload)
+				if stopTrim != nil {
+					if payload = stopTrim.process(payload); len(payload) == 0 {
+						continue
+					}
+				}
+				send(payload)
+			}
+		}
+		// A stream that ends without a finish reason would otherwise drop the held-back tail.
+		if stopTrim != nil {
+			if payload := stopTrim.finish(); len(payload) > 0 {
+				send(payload)
 			}
 		}
 	}()
@@ -321,6 +428,29 @@ func (h *BaseAPIHandler) getRequestDetails(modelName string) (providers []string
 	// Resolve "auto" model to an actual available model first
 	resolvedModelName := util.ResolveAutoModel(modelName)
 
+	// Capability requests keep their model name; selection matches each account against the
+	// capabilities, so they go to every provider serving a matching model.
+	if required, ok := util.ParseCapabilityRequest(resolvedModelName); ok {
+		var catalog map[string][]string
+		if h.Cfg != nil {
+			catalog = h.Cfg.ModelCapabilities
+		}
+		models, errMatch := util.CapabilityModels(required, catalog)
+		if errMatch != nil {
+			return nil, "", nil, &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: errMatch}
+		}
+		seen := make(map[string]struct{})
+		for _, model := range models {
+			for _, provider := range util.GetProviderName(model) {
+				if _, dup := seen[provider]; !dup {
+					seen[provider] = struct{}{}
+					providers = append(providers, provider)
+				}
+			}
+		}
+		return providers, resolvedModelName, nil, nil
+	}
+
 	providerName, extractedModelName, isDynamic := h.parseDynamicModel(resolvedModelName)
 
 	// First, normalize the model name to handle suffixes like "-thinking-128"
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// liveUsageHeader opts a streaming request into running usage reports on the accounts monitor.
+const liveUsageHeader = "X-CLIProxy-Live-Usage"
+
+// markLiveUsage flags the stream for running usage reports when the client asked for them.
+func markLiveUsage(ctx context.Context, opts *coreexecutor.Options) {
+	if ctx == nil {
+		return
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil || ginCtx.Request == nil {
+		return
+	}
+	if !strings.EqualFold(strings.TrimSpace(ginCtx.GetHeader(liveUsageHeader)), "true") {
+		return
+	}
+	if opts.Metadata == nil {
+		opts.Metadata = make(map[string]any)
+	}
+	opts.Metadata[coreexecutor.LiveUsageMetadataKey] = true
+}
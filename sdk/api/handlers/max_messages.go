@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// messageListPath returns where the conversation of a request in handlerType's format is stored.
+func messageListPath(handlerType string) string {
+	switch handlerType {
+	case constant.Gemini:
+		return "contents"
+	case constant.GeminiCLI:
+		return "request.contents"
+	case constant.OpenaiResponse:
+		return "input"
+	default:
+		return "messages"
+	}
+}
+
+// isSystemMessage reports whether message is a system prompt kept by truncation. Claude and Gemini
+// carry the system prompt outside the message list.
+func isSystemMessage(handlerType string, message gjson.Result) bool {
+	switch handlerType {
+	case constant.OpenAI, constant.OpenaiResponse:
+		role := message.Get("role").String()
+		return role == "system" || role == "developer"
+	default:
+		return false
+	}
+}
+
+// startsTurn reports whether a truncated conversation may begin with message: a user message that is
+// not the result of a tool call the truncation removed.
+func startsTurn(handlerType string, message gjson.Result) bool {
+	if message.Get("role").String() != "user" {
+		return false
+	}
+	switch handlerType {
+	case constant.Claude:
+		for _, part := range message.Get("content").Array() {
+			if part.Get("type").String() == "tool_result" {
+				return false
+			}
+		}
+	case constant.Gemini, constant.GeminiCLI:
+		for _, part := range message.Get("parts").Array() {
+			if part.Get("functionResponse").Exists() {
+				return false
+			}
+		}
+	case constant.OpenaiResponse:
+		if kind := message.Get("type").String(); kind != "" && kind != "message" {
+			return false
+		}
+	}
+	return true
+}
+
+// countMessages returns the number of messages in the request, or -1 when it has no message list.
+func countMessages(handlerType string, rawJSON []byte) int {
+	messages := gjson.GetBytes(rawJSON, messageListPath(handlerType))
+	if !messages.IsArray() {
+		return -1
+	}
+	return len(messages.Array())
+}
+
+// truncateMessages keeps the system messages, in place, and the most recent turns of the request so
+// that at most limit messages remain; the system messages are kept even when they alone exceed it.
+// The kept history starts at a user turn, so no tool result loses its call. It returns the request
+// and the number of dropped messages.
+func truncateMessages(handlerType string, rawJSON []byte, limit int) ([]byte, int) {
+	path := messageListPath(handlerType)
+	messages := gjson.GetBytes(rawJSON, path).Array()
+	if limit <= 0 || len(messages) <= limit {
+		return rawJSON, 0
+	}
+	var system, history []gjson.Result
+	for _, message := range messages {
+		if isSystemMessage(handlerType, message) {
+			system = append(system, message)
+		} else {
+			history = append(history, message)
+		}
+	}
+	keep := max(limit-len(system), 1)
+	start := max(len(history)-keep, 0)
+	for start < len(history)-1 && !startsTurn(handlerType, history[start]) {
+		start++
+	}
+
+	raw := []byte("[]")
+	turn := 0
+	for _, message := range messages {
+		if !isSystemMessage(handlerType, message) {
+			turn++
+			if turn <= start {
+				continue
+			}
+		}
+		raw, _ = sjson.SetRawBytes(raw, "-1", []byte(message.Raw))
+	}
+	out, err := sjson.SetRawBytes(rawJSON, path, raw)
+	if err != nil {
+		return rawJSON, 0
+	}
+	return out, start
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestTruncateMessagesKeepsSystemAndRecentTurns(t *testing.T) {
+	cases := []struct {
+		name        string
+		handlerType string
+		path        string
+		in          string
+		limit       int
+		want        string
+		dropped     int
+	}{
+		{
+			name:        "openai keeps system in place",
+			handlerType: constant.OpenAI,
+			path:        "messages",
+			in:          `{"messages":[{"role":"system","content":"s"},{"role":"user","content":"1"},{"role":"assistant","content":"2"},{"role":"user","content":"3"},{"role":"assistant","content":"4"},{"role":"user","content":"5"}]}`,
+			limit:       4,
+			want:        "s,3,4,5",
+			dropped:     2,
+		},
+		{
+			name:        "openai skips an orphaned tool result",
+			handlerType: constant.OpenAI,
+			path:        "messages",
+			in:          `{"messages":[{"role":"user","content":"1"},{"role":"assistant","content":"2"},{"role":"tool","content":"3"},{"role":"assistant","content":"4"},{"role":"user","content":"5"}]}`,
+			limit:       3,
+			want:        "5",
+			dropped:     4,
+		},
+		{
+			name:        "claude starts at a user turn without tool results",
+			handlerType: constant.Claude,
+			path:        "messages",
+			in:          `{"system":"s","messages":[{"role":"user","content":"1"},{"role":"assistant","content":"2"},{"role":"user","content":[{"type":"tool_result","content":"3"}]},{"role":"assistant","content":"4"},{"role":"user","content":"5"},{"role":"assistant","content":"6"}]}`,
+			limit:       4,
+			want:        "5,6",
+			dropped:     4,
+		},
+		{
+			name:        "gemini contents",
+			handlerType: constant.Gemini,
+			path:        "contents",
+			in:          `{"contents":[{"role":"user","content":"1"},{"role":"model","content":"2"},{"role":"user","content":"3"}]}`,
+			limit:       2,
+			want:        "3",
+			dropped:     2,
+		},
+		{
+			name:        "within the limit",
+			handlerType: constant.OpenAI,
+			path:        "messages",
+			in:          `{"messages":[{"role":"user","content":"1"}]}`,
+			limit:       1,
+			want:        "1",
+		},
+	}
+	for _, tc := range cases {
+		out, dropped := truncateMessages(tc.handlerType, []byte(tc.in), tc.limit)
+		var got []string
+		for _, message := range gjson.GetBytes(out, tc.path).Array() {
+			content := message.Get("content")
+			if content.IsArray() {
+				content = content.Get("0.content")
+			}
+			got = append(got, content.String())
+		}
+		if joined := strings.Join(got, ","); joined != tc.want || dropped != tc.dropped {
+			t.Errorf("%s: kept %s, dropped %d; want %s, %d", tc.name, joined, dropped, tc.want, tc.dropped)
+		}
+	}
+}
+
+func TestRequestClampMaxMessagesRejectsOrTruncates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginCtx.Set("apiKey", "strict")
+	ctx := context.WithValue(context.Background(), "gin", ginCtx)
+	limit := 2
+	h := &BaseAPIHandler{Cfg: &sdkconfig.SDKConfig{RequestClamps: []sdkconfig.RequestClamp{
+		{MaxMessages: &limit},
+		{APIKeys: []string{"strict"}, MaxMessages: &limit, Reject: true},
+	}}}
+	body := []byte(`{"messages":[{"role":"user","content":"1"},{"role":"assistant","content":"2"},{"role":"user","content":"3"}]}`)
+
+	if _, errMsg := h.applyRequestClamps(ctx, constant.OpenAI, body); errMsg == nil || errMsg.StatusCode != http.StatusBadRequest {
+		t.Fatalf("strict key: %+v", errMsg)
+	}
+	ginCtx.Set("apiKey", "relaxed")
+	out, errMsg := h.applyRequestClamps(ctx, constant.OpenAI, body)
+	if errMsg != nil || len(gjson.GetBytes(out, "messages").Array()) != 1 {
+		t.Fatalf("relaxed key: %s, %+v", out, errMsg)
+	}
+}
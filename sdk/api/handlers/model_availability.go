@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+)
+
+// WithModelAvailability adds an "availability" entry to the listed models that accounts restrict to
+// time windows, so clients can tell a windowed preview model that is currently out of its window.
+// idKey names the field holding the model id; a "models/" prefix is ignored. With mask-pool-details
+// on, only whether the model is available is reported.
+func (h *BaseAPIHandler) WithModelAvailability(models []map[string]any, idKey string) []map[string]any {
+	if h == nil || h.AuthManager == nil {
+		return models
+	}
+	statuses := h.AuthManager.ModelWindowStatuses(time.Now())
+	if len(statuses) == 0 {
+		return models
+	}
+	masked := h.Cfg != nil && h.Cfg.MaskPoolDetails
+	for _, model := range models {
+		id, _ := model[idKey].(string)
+		status, ok := statuses[strings.TrimPrefix(id, "models/")]
+		if !ok {
+			continue
+		}
+		if masked {
+			model["availability"] = map[string]any{"windowed": true, "available": status.Available}
+			continue
+		}
+		model["availability"] = map[string]any{
+			"windowed":          true,
+			"available":         status.Available,
+			"accounts":          status.Accounts,
+			"windowed_accounts": status.Windowed,
+			"in_window":         status.InWindow,
+		}
+	}
+	return models
+}
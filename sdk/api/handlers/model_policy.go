@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"golang.org/x/net/context"
+)
+
+// applyDefaultModel substitutes the client key's default model when the request does not name one.
+// Formats that name the model in the body rather than the URL get it set there too.
+func (h *BaseAPIHandler) applyDefaultModel(ctx context.Context, handlerType, modelName string, rawJSON []byte) (string, []byte) {
+	if strings.TrimSpace(modelName) != "" || h.Cfg == nil || len(h.Cfg.ClientModelPolicies) == 0 {
+		return modelName, rawJSON
+	}
+	policy := h.Cfg.ClientModelPolicyFor(requestAPIKey(ctx))
+	if policy == nil || strings.TrimSpace(policy.DefaultModel) == "" {
+		return modelName, rawJSON
+	}
+	modelName = strings.TrimSpace(policy.DefaultModel)
+	if handlerType != constant.Gemini && handlerType != constant.GeminiCLI && gjson.ValidBytes(rawJSON) {
+		if updated, err := sjson.SetBytes(rawJSON, "model", modelName); err == nil {
+			rawJSON = updated
+		}
+	}
+	return modelName, rawJSON
+}
+
+// checkModelPolicy rejects requests for models outside the client key's allowlist with 403.
+func (h *BaseAPIHandler) checkModelPolicy(ctx context.Context, model string) *interfaces.ErrorMessage {
+	if h.Cfg == nil || len(h.Cfg.ClientModelPolicies) == 0 {
+		return nil
+	}
+	apiKey := requestAPIKey(ctx)
+	if h.Cfg.ClientModelPolicyFor(apiKey).Allows(model) {
+		return nil
+	}
+	log.Infof("rejected model %s for client key %s: not in its model allowlist", model, util.HideAPIKey(apiKey))
+	return &interfaces.ErrorMessage{StatusCode: http.StatusForbidden, Error: fmt.Errorf("model %s is not allowed for this API key", model)}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestClientModelPolicy(t *testing.T) {
+	h := &BaseAPIHandler{Cfg: &sdkconfig.SDKConfig{ClientModelPolicies: []sdkconfig.ClientModelPolicy{
+		{Models: []string{"gemini-2.5-flash*"}, DefaultModel: "gemini-2.5-flash"},
+		{APIKeys: []string{"premium"}, DefaultModel: "gemini-2.5-pro"},
+	}}}
+	ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginCtx.Set("apiKey", "basic")
+	ctx := context.WithValue(context.Background(), "gin", ginCtx)
+
+	if errMsg := h.checkModelPolicy(ctx, "gemini-2.5-flash-lite"); errMsg != nil {
+		t.Fatalf("wildcard match rejected: %+v", errMsg)
+	}
+	if errMsg := h.checkModelPolicy(ctx, "gemini-2.5-pro"); errMsg == nil || errMsg.StatusCode != http.StatusForbidden {
+		t.Fatalf("disallowed model = %+v, want 403", errMsg)
+	}
+	model, out := h.applyDefaultModel(ctx, constant.OpenAI, "", []byte(`{"messages":[]}`))
+	if model != "gemini-2.5-flash" || gjson.GetBytes(out, "model").String() != model {
+		t.Fatalf("default model = %q, body %s", model, out)
+	}
+	if model, _ = h.applyDefaultModel(ctx, constant.OpenAI, "gemini-2.5-flash-lite", nil); model != "gemini-2.5-flash-lite" {
+		t.Fatalf("explicit model replaced by %q", model)
+	}
+
+	ginCtx.Set("apiKey", "premium")
+	if errMsg := h.checkModelPolicy(ctx, "gemini-2.5-pro"); errMsg != nil {
+		t.Fatalf("unrestricted key rejected: %+v", errMsg)
+	}
+	if model, _ = h.applyDefaultModel(ctx, constant.Claude, "", []byte(`{}`)); model != "gemini-2.5-pro" {
+		t.Fatalf("premium default model = %q", model)
+	}
+}
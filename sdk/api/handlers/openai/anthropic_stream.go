@@ -0,0 +1,151 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	// responseDialectHeader lets chat completion clients pick the wire format of the response.
+	responseDialectHeader = "X-Response-Dialect"
+	// responseDialectAnthropic selects Anthropic's native SSE events.
+	responseDialectAnthropic = "anthropic"
+	// anthropicPingInterval is how long an idle stream waits before emitting a ping event.
+	anthropicPingInterval = 15 * time.Second
+)
+
+const anthropicPingEvent = "event: ping\ndata: {\"type\":\"ping\"}\n\n"
+
+// responseDialect returns the response dialect requested via header or the response_dialect query parameter.
+func responseDialect(c *gin.Context) string {
+	dialect := c.GetHeader(responseDialectHeader)
+	if dialect == "" {
+		dialect = c.Query("response_dialect")
+	}
+	switch strings.ToLower(strings.TrimSpace(dialect)) {
+	case "anthropic", "claude":
+		return responseDialectAnthropic
+	}
+	return ""
+}
+
+// anthropicStreamTranslator converts OpenAI chat completion chunks into Anthropic stream events.
+type anthropicStreamTranslator struct {
+	ctx     context.Context
+	model   string
+	request []byte
+	param   any
+	pinged  bool
+	started bool
+}
+
+func newAnthropicStreamTranslator(ctx context.Context, model string, rawJSON []byte) *anthropicStreamTranslator {
+	request, _ := sjson.SetBytes(rawJSON, "stream", true)
+	return &anthropicStreamTranslator{ctx: ctx, model: model, request: request}
+}
+
+// translate converts one OpenAI chunk; a ping follows message_start as Anthropic's API does.
+func (t *anthropicStreamTranslator) translate(chunk []byte) []string {
+	events := sdktranslator.TranslateStream(t.ctx, sdktranslator.FormatOpenAI, sdktranslator.FormatClaude, t.model, t.request, t.request, append([]byte("data: "), chunk...), &t.param)
+	if len(events) > 0 {
+		t.started = true
+	}
+	if t.pinged {
+		return events
+	}
+	for i, event := range events {
+		if strings.HasPrefix(event, "event: message_start") {
+			t.pinged = true
+			out := make([]string, 0, len(events)+1)
+			out = append(out, events[:i+1]...)
+			out = append(out, anthropicPingEvent)
+			return append(out, events[i+1:]...)
+		}
+	}
+	return events
+}
+
+// done flushes the closing content_block_stop, message_delta and message_stop events.
+func (t *anthropicStreamTranslator) done() []string {
+	return sdktranslator.TranslateStream(t.ctx, sdktranslator.FormatOpenAI, sdktranslator.FormatClaude, t.model, t.request, t.request, []byte("data: [DONE]"), &t.param)
+}
+
+// anthropicErrorEvent renders an upstream failure as an Anthropic stream error event.
+func anthropicErrorEvent(errMsg *interfaces.ErrorMessage) string {
+	payload := []byte(`{"type":"error","error":{"type":"api_error","message":""}}`)
+	message := "upstream error"
+	if errMsg != nil && errMsg.Error != nil {
+		message = errMsg.Error.Error()
+	}
+	if errMsg != nil && errMsg.StatusCode == http.StatusTooManyRequests {
+		payload, _ = sjson.SetBytes(payload, "error.type", "rate_limit_error")
+	}
+	payload, _ = sjson.SetBytes(payload, "error.message", message)
+	return "event: error\ndata: " + string(payload) + "\n\n"
+}
+
+// translateAnthropicNonStream converts a complete OpenAI chat completion into an Anthropic message.
+func translateAnthropicNonStream(ctx context.Context, model string, rawJSON, resp []byte) []byte {
+	var param any
+	return []byte(sdktranslator.TranslateNonStream(ctx, sdktranslator.FormatOpenAI, sdktranslator.FormatClaude, model, rawJSON, rawJSON, resp, &param))
+}
+
+// handleAnthropicStreamResult forwards the stream as Anthropic SSE events instead of OpenAI chunks.
+func (h *OpenAIAPIHandler) handleAnthropicStreamResult(c *gin.Context, flusher http.Flusher, cancel func(error), translator *anthropicStreamTranslator, data <-chan []byte, errs <-chan *interfaces.ErrorMessage) {
+	write := func(events []string) {
+		for _, event := range events {
+			_, _ = fmt.Fprint(c.Writer, event)
+		}
+		if len(events) > 0 {
+			flusher.Flush()
+		}
+	}
+	lastWrite := time.Now()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			cancel(c.Request.Context().Err())
+			return
+		case chunk, ok := <-data:
+			if !ok {
+				write(translator.done())
+				cancel(nil)
+				return
+			}
+			write(translator.translate(chunk))
+			lastWrite = time.Now()
+		case errMsg, ok := <-errs:
+			if !ok {
+				continue
+			}
+			if errMsg != nil {
+				if !translator.started {
+					// Nothing has been streamed yet, so the regular error response still applies.
+					h.WriteErrorResponse(c, errMsg)
+				} else {
+					write([]string{anthropicErrorEvent(errMsg)})
+				}
+				flusher.Flush()
+			}
+			var execErr error
+			if errMsg != nil {
+				execErr = errMsg.Error
+			}
+			cancel(execErr)
+			return
+		case <-time.After(500 * time.Millisecond):
+			if translator.started && time.Since(lastWrite) >= anthropicPingInterval {
+				write([]string{anthropicPingEvent})
+				lastWrite = time.Now()
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package openai
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	_ "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator/builtin"
+	"github.com/tidwall/gjson"
+)
+
+// replayAnthropicFixture translates an OpenAI chunk fixture and returns the emitted event payloads.
+func replayAnthropicFixture(t *testing.T, name string) []gjson.Result {
+	t.Helper()
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatalf("open fixture: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	translator := newAnthropicStreamTranslator(context.Background(), "gpt-4o", []byte(`{"model":"gpt-4o","stream":true}`))
+	var events []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			events = append(events, translator.translate([]byte(line))...)
+		}
+	}
+	events = append(events, translator.done()...)
+
+	var payloads []gjson.Result
+	for _, event := range events {
+		for _, line := range strings.Split(event, "\n") {
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				payloads = append(payloads, gjson.Parse(data))
+			}
+		}
+	}
+	return payloads
+}
+
+func eventTypes(payloads []gjson.Result) []string {
+	types := make([]string, 0, len(payloads))
+	for _, p := range payloads {
+		types = append(types, p.Get("type").String())
+	}
+	return types
+}
+
+func TestAnthropicStreamToolUse(t *testing.T) {
+	payloads := replayAnthropicFixture(t, "anthropic_stream_tool_use.jsonl")
+	types := eventTypes(payloads)
+	if len(types) < 3 || types[0] != "message_start" || types[1] != "ping" || types[len(types)-1] != "message_stop" {
+		t.Fatalf("unexpected event order: %v", types)
+	}
+
+	var toolStart, inputDelta, messageDelta gjson.Result
+	for _, p := range payloads {
+		switch {
+		case p.Get("type").String() == "content_block_start" && p.Get("content_block.type").String() == "tool_use":
+			toolStart = p
+		case p.Get("delta.type").String() == "input_json_delta":
+			inputDelta = p
+		case p.Get("type").String() == "message_delta":
+			messageDelta = p
+		}
+	}
+	if toolStart.Get("content_block.name").String() != "get_weather" || toolStart.Get("content_block.id").String() != "call_1" {
+		t.Fatalf("missing tool_use block start: %v", types)
+	}
+	if gjson.Parse(inputDelta.Get("delta.partial_json").String()).Get("city").String() != "Paris" {
+		t.Fatalf("unexpected tool input delta: %s", inputDelta.Raw)
+	}
+	if messageDelta.Get("delta.stop_reason").String() != "tool_use" {
+		t.Fatalf("message_delta stop_reason = %q, want tool_use", messageDelta.Get("delta.stop_reason").String())
+	}
+	if messageDelta.Get("usage.output_tokens").Int() != 8 {
+		t.Fatalf("message_delta usage = %s, want trailing usage chunk", messageDelta.Get("usage").Raw)
+	}
+}
+
+func TestAnthropicStreamTextMessageDelta(t *testing.T) {
+	payloads := replayAnthropicFixture(t, "anthropic_stream_text.jsonl")
+	var text strings.Builder
+	var stopReason string
+	pings := 0
+	for _, p := range payloads {
+		switch p.Get("type").String() {
+		case "content_block_delta":
+			text.WriteString(p.Get("delta.text").String())
+		case "message_delta":
+			stopReason = p.Get("delta.stop_reason").String()
+		case "ping":
+			pings++
+		}
+	}
+	if text.String() != "Hello there" {
+		t.Fatalf("text = %q, want %q", text.String(), "Hello there")
+	}
+	if stopReason != "end_turn" {
+		t.Fatalf("stop_reason = %q, want end_turn", stopReason)
+	}
+	if pings != 1 {
+		t.Fatalf("pings = %d, want exactly one after message_start", pings)
+	}
+}
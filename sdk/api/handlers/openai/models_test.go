@@ -0,0 +1,69 @@
+package openai
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func listModels(t *testing.T, cfg *config.SDKConfig) gjson.Result {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	h := NewOpenAIAPIHandler(handlers.NewBaseAPIHandlers(cfg, nil, nil))
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	h.OpenAIModels(c)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	return gjson.ParseBytes(rec.Body.Bytes())
+}
+
+func findModel(list gjson.Result, id string) gjson.Result {
+	return list.Get(`data.#(id=="` + id + `")`)
+}
+
+func TestOpenAIModelsAggregatesProviders(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("models-list-gemini", "gemini", []*registry.ModelInfo{{ID: "list-shared", Object: "model"}, {ID: "list-cooling", Object: "model"}})
+	t.Cleanup(func() { reg.UnregisterClient("models-list-gemini") })
+	reg.RegisterClient("models-list-vertex", "vertex", []*registry.ModelInfo{{ID: "list-shared", Object: "model"}})
+	t.Cleanup(func() { reg.UnregisterClient("models-list-vertex") })
+	reg.SetModelQuotaExceeded("models-list-gemini", "list-cooling")
+
+	list := listModels(t, &config.SDKConfig{})
+	if list.Get("object").String() != "list" {
+		t.Fatalf("object = %s", list.Get("object").String())
+	}
+	if got := list.Get(`data.#(id=="list-shared")#`).Array(); len(got) != 1 {
+		t.Fatalf("shared model listed %d times", len(got))
+	}
+	shared := findModel(list, "list-shared")
+	if providers := shared.Get("providers").String(); providers != `["gemini","vertex"]` {
+		t.Fatalf("providers = %s", providers)
+	}
+	if shared.Get("cooldown").Exists() {
+		t.Fatal("healthy model flagged as cooling down")
+	}
+	if !findModel(list, "list-cooling").Get("cooldown").Bool() {
+		t.Fatal("cooling model not flagged")
+	}
+	ids := list.Get("data.#.id").Array()
+	for i := 1; i < len(ids); i++ {
+		if ids[i-1].String() > ids[i].String() {
+			t.Fatalf("models not sorted: %s before %s", ids[i-1].String(), ids[i].String())
+		}
+	}
+
+	masked := listModels(t, &config.SDKConfig{MaskPoolDetails: true})
+	if findModel(masked, "list-shared").Get("providers").Exists() {
+		t.Fatal("providers listed with mask-pool-details on")
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -55,11 +56,14 @@ func (h *OpenAIAPIHandler) Models() []map[string]any {
 }
 
 // OpenAIModels handles the /v1/models endpoint.
-// It returns a list of available AI models with their capabilities
-// and specifications in OpenAI-compatible format.
+// It returns the models of every registered provider as one OpenAI-compatible list, sorted by id.
+// Each model is tagged with the providers serving it, and models whose accounts are all cooling
+// down carry "cooldown": true. With mask-pool-details on, provider names are left out.
 func (h *OpenAIAPIHandler) OpenAIModels(c *gin.Context) {
 	// Get all available models
 	allModels := h.Models()
+	modelRegistry := registry.GetGlobalRegistry()
+	masked := h.Cfg != nil && h.Cfg.MaskPoolDetails
 
 	// Filter to only include the 4 required fields: id, object, created, owned_by
 	filteredModels := make([]map[string]any, len(allModels))
@@ -79,8 +83,24 @@ func (h *OpenAIAPIHandler) OpenAIModels(c *gin.Context) {
 			filteredModel["owned_by"] = ownedBy
 		}
 
+		if id, ok := model["id"].(string); ok {
+			if providers := modelRegistry.GetModelProviders(id); len(providers) > 0 && !masked {
+				sort.Strings(providers)
+				filteredModel["providers"] = providers
+			}
+			if modelRegistry.GetModelCount(id) == 0 {
+				filteredModel["cooldown"] = true
+			}
+		}
+
 		filteredModels[i] = filteredModel
 	}
+	sort.SliceStable(filteredModels, func(i, j int) bool {
+		left, _ := filteredModels[i]["id"].(string)
+		right, _ := filteredModels[j]["id"].(string)
+		return left < right
+	})
+	filteredModels = h.WithModelAvailability(filteredModels, "id")
 
 	c.JSON(http.StatusOK, gin.H{
 		"object": "list",
@@ -405,6 +425,9 @@ func (h *OpenAIAPIHandler) handleNonStreamingResponse(c *gin.Context, rawJSON []
 		cliCancel(errMsg.Error)
 		return
 	}
+	if responseDialect(c) == responseDialectAnthropic {
+		resp = translateAnthropicNonStream(cliCtx, modelName, rawJSON, resp)
+	}
 	_, _ = c.Writer.Write(resp)
 	cliCancel()
 }
@@ -437,6 +460,11 @@ func (h *OpenAIAPIHandler) handleStreamingResponse(c *gin.Context, rawJSON []byt
 	modelName := gjson.GetBytes(rawJSON, "model").String()
 	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
 	dataChan, errChan := h.ExecuteStreamWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, h.GetAlt(c))
+	if responseDialect(c) == responseDialectAnthropic {
+		translator := newAnthropicStreamTranslator(cliCtx, modelName, rawJSON)
+		h.handleAnthropicStreamResult(c, flusher, func(err error) { cliCancel(err) }, translator, dataChan, errChan)
+		return
+	}
 	h.handleStreamResult(c, flusher, func(err error) { cliCancel(err) }, dataChan, errChan)
 }
 
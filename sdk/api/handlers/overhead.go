@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// trackOverhead starts timing a request for the proxy overhead report. The returned function records
+// the request once it has finished; clientWait is time spent blocked on a streaming client.
+func (h *BaseAPIHandler) trackOverhead(ctx context.Context) (context.Context, func(clientWait time.Duration)) {
+	started := time.Now()
+	ctx, clock := coreauth.WithUpstreamClock(ctx)
+	return ctx, func(clientWait time.Duration) {
+		h.AuthManager.RecordOverhead(time.Since(started), clock.Elapsed(), clock.Waited(), clientWait)
+	}
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+const (
+	// pinnedAccountHeader names the account a request must be served by instead of the selector's pick.
+	pinnedAccountHeader = "X-CLIProxy-Account-Id"
+	// pinFallbackHeader lets a pinned request use normal selection when its account is unavailable.
+	pinFallbackHeader = "X-CLIProxy-Allow-Fallback"
+)
+
+// markPinnedAccount pins the request to the account named by the client, if any.
+func markPinnedAccount(ctx context.Context, opts *coreexecutor.Options) {
+	if ctx == nil {
+		return
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil || ginCtx.Request == nil {
+		return
+	}
+	id := strings.TrimSpace(ginCtx.GetHeader(pinnedAccountHeader))
+	if id == "" {
+		return
+	}
+	if opts.Metadata == nil {
+		opts.Metadata = make(map[string]any)
+	}
+	opts.Metadata[coreexecutor.PinnedAuthMetadataKey] = id
+	if strings.EqualFold(strings.TrimSpace(ginCtx.GetHeader(pinFallbackHeader)), "true") {
+		opts.Metadata[coreexecutor.PinFallbackMetadataKey] = true
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// managerErrorMessage converts an auth manager error into the message returned to the client. With
+// mask-pool-details on, pool errors (account ids, counts, provider names and account-specific
+// upstream bodies) are replaced by a generic reason code and the full error is only logged.
+// Client-caused upstream errors, such as 400 validation failures, describe the request and always
+// pass through unchanged.
+func (h *BaseAPIHandler) managerErrorMessage(err error) *interfaces.ErrorMessage {
+	status := http.StatusInternalServerError
+	if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
+		if code := se.StatusCode(); code > 0 {
+			status = code
+		}
+	}
+	var addon http.Header
+	if he, ok := err.(interface{ Headers() http.Header }); ok && he != nil {
+		if hdr := he.Headers(); hdr != nil {
+			addon = hdr.Clone()
+		}
+	}
+	if h.Cfg == nil || !h.Cfg.MaskPoolDetails || coreauth.IsClientError(err) {
+		return &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+	}
+
+	code, message := genericErrorReason(status)
+	log.Warnf("request failed with status %d (%s returned to client): %v", status, code, err)
+	masked := make(http.Header)
+	masked.Set("Content-Type", "application/json")
+	if retryAfter := addon.Get("Retry-After"); retryAfter != "" {
+		masked.Set("Retry-After", retryAfter)
+	}
+	body, _ := json.Marshal(map[string]any{"error": map[string]string{"code": code, "message": message}})
+	return &interfaces.ErrorMessage{StatusCode: status, Error: errors.New(string(body)), Addon: masked}
+}
+
+// genericErrorReason maps a status code to a reason code and message that reveal nothing about the pool.
+func genericErrorReason(status int) (code, message string) {
+	switch {
+	case status == http.StatusBadRequest || status == http.StatusUnprocessableEntity:
+		return "invalid_request", "the request was rejected by the upstream provider"
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return "upstream_unauthorized", "the upstream provider refused the request"
+	case status == http.StatusNotFound:
+		return "not_found", "the requested model or resource is not available"
+	case status == http.StatusRequestEntityTooLarge:
+		return "request_too_large", "the request is too large"
+	case status == http.StatusTooManyRequests:
+		return "rate_limited", "capacity is temporarily exhausted; retry later"
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		return "timeout", "the request timed out"
+	case status == http.StatusServiceUnavailable:
+		return "unavailable", "the service is temporarily unavailable; retry later"
+	default:
+		return "upstream_error", "the request could not be completed"
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+type poolError struct{}
+
+func (poolError) Error() string {
+	return `{"error":{"message":"All 3 credentials for gemini-2.5-pro via provider gemini-cli are cooling down (user@example.com.json)"}}`
+}
+func (poolError) StatusCode() int { return http.StatusTooManyRequests }
+func (poolError) Headers() http.Header {
+	return http.Header{"Retry-After": {"30"}, "X-Account-Id": {"user@example.com.json"}}
+}
+
+func TestManagerErrorMessageMasksPoolDetails(t *testing.T) {
+	h := &BaseAPIHandler{Cfg: &sdkconfig.SDKConfig{MaskPoolDetails: true}}
+	msg := h.managerErrorMessage(poolError{})
+	body := msg.Error.Error()
+	for _, leak := range []string{"user@example.com", "gemini-cli", "3 credentials"} {
+		if strings.Contains(body, leak) {
+			t.Fatalf("masked error leaks %q: %s", leak, body)
+		}
+	}
+	if msg.StatusCode != http.StatusTooManyRequests || gjson.Get(body, "error.code").String() != "rate_limited" {
+		t.Fatalf("unexpected masked error: %d %s", msg.StatusCode, body)
+	}
+	if msg.Addon.Get("Retry-After") != "30" || msg.Addon.Get("X-Account-Id") != "" {
+		t.Fatalf("only Retry-After may pass through: %v", msg.Addon)
+	}
+
+	h.Cfg.MaskPoolDetails = false
+	if full := h.managerErrorMessage(&coreauth.Error{Code: "auth_not_found", Message: "no auth available"}); full.Error.Error() != "auth_not_found: no auth available" {
+		t.Fatalf("unmasked errors must pass through, got %v", full.Error)
+	}
+}
+
+type validationError struct{}
+
+func (validationError) Error() string {
+	return `{"error":{"type":"invalid_request_error","message":"messages.0.content: field required"}}`
+}
+func (validationError) StatusCode() int { return http.StatusBadRequest }
+
+func TestManagerErrorMessagePassesClientErrorsThrough(t *testing.T) {
+	h := &BaseAPIHandler{Cfg: &sdkconfig.SDKConfig{MaskPoolDetails: true}}
+	msg := h.managerErrorMessage(&coreauth.ClientError{Err: validationError{}})
+	if msg.StatusCode != http.StatusBadRequest || msg.Error.Error() != (validationError{}).Error() {
+		t.Fatalf("client-caused error must pass through unchanged, got %d %v", msg.StatusCode, msg.Error)
+	}
+	if masked := h.managerErrorMessage(validationError{}); gjson.Get(masked.Error.Error(), "error.code").String() != "invalid_request" {
+		t.Fatalf("account-caused 400 must stay masked, got %v", masked.Error)
+	}
+}
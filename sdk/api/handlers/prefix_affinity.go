@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	defaultPrefixAffinityBytes    = 4096
+	defaultPrefixAffinityMessages = 2
+)
+
+// prefixAffinityFields lists the fixed prompt fields across client formats in the order providers
+// cache them; they are hashed in full.
+var prefixAffinityFields = []string{"tools", "system", "systemInstruction", "instructions"}
+
+// prefixAffinityTurns lists the conversation fields across client formats; only their first
+// messages are hashed so the prefix stays the same as the conversation grows.
+var prefixAffinityTurns = []string{"messages", "contents", "input"}
+
+// promptPrefixHash hashes the stable prefix of the request's prompt: tools and system instructions
+// plus the first messages messages, capped at limit bytes. It returns "" when the payload carries no
+// recognizable prompt.
+func promptPrefixHash(rawJSON []byte, limit, messages int) string {
+	if limit <= 0 {
+		limit = defaultPrefixAffinityBytes
+	}
+	if messages <= 0 {
+		messages = defaultPrefixAffinityMessages
+	}
+	prefix := make([]byte, 0, limit)
+	for _, field := range prefixAffinityFields {
+		if value := gjson.GetBytes(rawJSON, field); value.Exists() {
+			prefix = append(prefix, field...)
+			prefix = append(prefix, value.Raw...)
+		}
+	}
+	for _, field := range prefixAffinityTurns {
+		value := gjson.GetBytes(rawJSON, field)
+		if !value.Exists() {
+			continue
+		}
+		prefix = append(prefix, field...)
+		if !value.IsArray() {
+			prefix = append(prefix, value.Raw...)
+			continue
+		}
+		for i, turn := range value.Array() {
+			if i >= messages {
+				break
+			}
+			prefix = append(prefix, turn.Raw...)
+		}
+	}
+	if len(prefix) == 0 {
+		return ""
+	}
+	if len(prefix) > limit {
+		prefix = prefix[:limit]
+	}
+	h := fnv.New64a()
+	_, _ = h.Write(prefix)
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// markPrefixAffinity tags opts with the prompt prefix hash when the client key opted into affinity.
+func (h *BaseAPIHandler) markPrefixAffinity(ctx context.Context, rawJSON []byte, opts *coreexecutor.Options) {
+	if h.Cfg == nil || !h.Cfg.PrefixAffinity.EnabledFor(requestAPIKey(ctx)) {
+		return
+	}
+	hash := promptPrefixHash(rawJSON, h.Cfg.PrefixAffinity.PrefixBytes, h.Cfg.PrefixAffinity.PrefixMessages)
+	if hash == "" {
+		return
+	}
+	if opts.Metadata == nil {
+		opts.Metadata = make(map[string]any)
+	}
+	opts.Metadata[coreexecutor.PrefixAffinityMetadataKey] = hash
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import "testing"
+
+func TestPromptPrefixHash(t *testing.T) {
+	base := `{"model":"m","system":"You are helpful.","messages":[{"role":"user","content":"first question"}]}`
+	longer := `{"model":"other","system":"You are helpful.","messages":[{"role":"user","content":"first question and follow up"}]}`
+	other := `{"system":"Different system prompt.","messages":[{"role":"user","content":"first question"}]}`
+
+	if promptPrefixHash([]byte(base), 40, 0) != promptPrefixHash([]byte(longer), 40, 0) {
+		t.Fatalf("requests sharing the hashed prefix must map to the same bucket")
+	}
+	if promptPrefixHash([]byte(base), 0, 0) == promptPrefixHash([]byte(other), 0, 0) {
+		t.Fatalf("different prefixes must not share a bucket")
+	}
+	if promptPrefixHash([]byte(`{"model":"m"}`), 0, 0) != "" {
+		t.Fatalf("payload without prompt must not produce a hash")
+	}
+}
+
+func TestPromptPrefixHashIsStableAcrossTurns(t *testing.T) {
+	first := `{"system":"s","messages":[{"role":"user","content":"hi"},{"role":"assistant","content":"hello"}]}`
+	later := `{"system":"s","messages":[{"role":"user","content":"hi"},{"role":"assistant","content":"hello"},{"role":"user","content":"next"},{"role":"assistant","content":"sure"}]}`
+	if promptPrefixHash([]byte(first), 0, 2) != promptPrefixHash([]byte(later), 0, 2) {
+		t.Fatal("later turns must not change the prefix")
+	}
+	if promptPrefixHash([]byte(first), 0, 1) == promptPrefixHash([]byte(`{"system":"s","messages":[{"role":"user","content":"bye"}]}`), 0, 1) {
+		t.Fatal("conversations with different openings must not share a bucket")
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// providerMetadataFields are response-level fields that reveal the serving backend and are not part of the
+// OpenAI chat completion schema.
+var providerMetadataFields = []string{
+	"provider",
+	"model_version", "modelVersion",
+	"safety_ratings", "safetyRatings",
+	"citation_metadata", "citationMetadata",
+	"prompt_feedback", "promptFeedback",
+	"prompt_filter_results",
+}
+
+// providerChoiceMetadataFields are the per-choice counterparts of providerMetadataFields.
+var providerChoiceMetadataFields = []string{
+	"native_finish_reason",
+	"safety_ratings", "safetyRatings",
+	"citation_metadata", "citationMetadata",
+	"grounding_metadata", "groundingMetadata",
+	"content_filter_results",
+}
+
+// stripsProviderMetadata reports whether provider metadata is removed from this request's responses.
+// Only OpenAI chat completions have a strict schema to normalise towards.
+func (h *BaseAPIHandler) stripsProviderMetadata(ctx context.Context, handlerType string) bool {
+	return handlerType == constant.OpenAI && h.Cfg != nil && h.Cfg.PostProcessing.StripProviderMetadataFor(requestAPIKey(ctx))
+}
+
+// stripProviderMetadata removes provider-specific fields from a chat completion or chunk and reports
+// model as the requested model instead of the backend's version string.
+func stripProviderMetadata(payload []byte, model string) []byte {
+	if !gjson.ValidBytes(payload) {
+		return payload
+	}
+	for _, field := range providerMetadataFields {
+		if gjson.GetBytes(payload, field).Exists() {
+			payload, _ = sjson.DeleteBytes(payload, field)
+		}
+	}
+	for i := range gjson.GetBytes(payload, "choices").Array() {
+		for _, field := range providerChoiceMetadataFields {
+			path := fmt.Sprintf("choices.%d.%s", i, field)
+			if gjson.GetBytes(payload, path).Exists() {
+				payload, _ = sjson.DeleteBytes(payload, path)
+			}
+		}
+	}
+	if model != "" && gjson.GetBytes(payload, "model").Exists() {
+		payload, _ = sjson.SetBytes(payload, "model", model)
+	}
+	return payload
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"testing"
+
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestStripProviderMetadata(t *testing.T) {
+	payload := []byte(`{"id":"x","model":"gemini-2.5-pro-preview-06-05","provider":"google","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop","native_finish_reason":"STOP","safety_ratings":[{"category":"HARM"}]}],"usage":{"total_tokens":3}}`)
+	got := stripProviderMetadata(payload, "gemini-2.5-pro")
+
+	for _, path := range []string{"provider", "choices.0.native_finish_reason", "choices.0.safety_ratings"} {
+		if gjson.GetBytes(got, path).Exists() {
+			t.Fatalf("expected %s to be stripped: %s", path, got)
+		}
+	}
+	if gjson.GetBytes(got, "model").String() != "gemini-2.5-pro" || gjson.GetBytes(got, "choices.0.message.content").String() != "hi" || gjson.GetBytes(got, "usage.total_tokens").Int() != 3 {
+		t.Fatalf("standard fields must be kept: %s", got)
+	}
+}
+
+func TestStripProviderMetadataFor(t *testing.T) {
+	p := sdkconfig.PostProcessing{StripProviderMetadata: true, KeepProviderMetadataKeys: []string{"keeper"}}
+	if !p.StripProviderMetadataFor("strict") || p.StripProviderMetadataFor("keeper") {
+		t.Fatalf("keep list must opt individual keys out of stripping")
+	}
+	p.APIKeys = []string{"other"}
+	if p.StripProviderMetadataFor("strict") {
+		t.Fatalf("api-keys must scope stripping")
+	}
+}
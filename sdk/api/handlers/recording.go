@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+)
+
+// markRecording samples the request for the replay recording; executors append the provider-native
+// attempts when they build the upstream request.
+func (h *BaseAPIHandler) markRecording(ctx context.Context, handlerType, model string, rawJSON []byte) {
+	if ctx == nil || h.Cfg == nil || !h.Cfg.RequestRecording.Enabled {
+		return
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil {
+		return
+	}
+	logging.SampleRecording(ginCtx, h.Cfg.RequestRecording, logging.RecordingSample{
+		Format: handlerType,
+		Model:  model,
+		APIKey: requestAPIKey(ctx),
+		Body:   rawJSON,
+	})
+}
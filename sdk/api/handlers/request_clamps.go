@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"golang.org/x/net/context"
+)
+
+// clampPaths locates the sampling parameters inside a request payload of a given source format.
+type clampPaths struct {
+	temperature string
+	topP        string
+	maxTokens   []string
+}
+
+func clampPathsFor(handlerType string) clampPaths {
+	switch handlerType {
+	case constant.Gemini:
+		return clampPaths{
+			temperature: "generationConfig.temperature",
+			topP:        "generationConfig.topP",
+			maxTokens:   []string{"generationConfig.maxOutputTokens"},
+		}
+	case constant.GeminiCLI:
+		return clampPaths{
+			temperature: "request.generationConfig.temperature",
+			topP:        "request.generationConfig.topP",
+			maxTokens:   []string{"request.generationConfig.maxOutputTokens"},
+		}
+	case constant.OpenaiResponse:
+		return clampPaths{temperature: "temperature", topP: "top_p", maxTokens: []string{"max_output_tokens"}}
+	default:
+		return clampPaths{temperature: "temperature", topP: "top_p", maxTokens: []string{"max_tokens", "max_completion_tokens"}}
+	}
+}
+
+// requestAPIKey returns the authenticated client key stored on the gin context, if any.
+func requestAPIKey(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil {
+		return ""
+	}
+	if v, exists := ginCtx.Get("apiKey"); exists {
+		if key, okKey := v.(string); okKey {
+			return key
+		}
+	}
+	return ""
+}
+
+// applyRequestClamps enforces the client key's clamp rule on rawJSON, clamping or rejecting out-of-range values.
+func (h *BaseAPIHandler) applyRequestClamps(ctx context.Context, handlerType string, rawJSON []byte) ([]byte, *interfaces.ErrorMessage) {
+	if h.Cfg == nil || len(h.Cfg.RequestClamps) == 0 || len(rawJSON) == 0 {
+		return rawJSON, nil
+	}
+	apiKey := requestAPIKey(ctx)
+	rule := h.Cfg.RequestClampFor(apiKey)
+	if rule == nil {
+		return rawJSON, nil
+	}
+	paths := clampPathsFor(handlerType)
+	out := rawJSON
+	var errMsg *interfaces.ErrorMessage
+	clampNumber := func(path, name string, bounds *config.NumberClamp) {
+		if errMsg != nil || bounds == nil || path == "" {
+			return
+		}
+		value := gjson.GetBytes(out, path)
+		if !value.Exists() || value.Type != gjson.Number {
+			return
+		}
+		original := value.Float()
+		clamped := original
+		if bounds.Min != nil && clamped < *bounds.Min {
+			clamped = *bounds.Min
+		}
+		if bounds.Max != nil && clamped > *bounds.Max {
+			clamped = *bounds.Max
+		}
+		if clamped == original {
+			return
+		}
+		if rule.Reject {
+			errMsg = &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: fmt.Errorf("%s %v is outside the allowed range", name, original)}
+			return
+		}
+		if updated, err := sjson.SetBytes(out, path, clamped); err == nil {
+			log.Infof("clamped %s from %v to %v for client key %s", name, original, clamped, util.HideAPIKey(apiKey))
+			out = updated
+		}
+	}
+	clampNumber(paths.temperature, "temperature", rule.Temperature)
+	clampNumber(paths.topP, "top_p", rule.TopP)
+	if rule.MaxTokens != nil && *rule.MaxTokens > 0 {
+		limit := float64(*rule.MaxTokens)
+		for _, path := range paths.maxTokens {
+			clampNumber(path, "max_tokens", &config.NumberClamp{Max: &limit})
+		}
+	}
+	if errMsg == nil && rule.MaxMessages != nil && *rule.MaxMessages > 0 {
+		limit := *rule.MaxMessages
+		if count := countMessages(handlerType, out); count > limit {
+			if rule.Reject {
+				errMsg = &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: fmt.Errorf("request has %d messages, more than the allowed %d", count, limit)}
+			} else {
+				var dropped int
+				out, dropped = truncateMessages(handlerType, out, limit)
+				log.Infof("truncated conversation from %d messages, dropping the %d oldest, for client key %s", count, dropped, util.HideAPIKey(apiKey))
+			}
+		}
+	}
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	return out, nil
+}
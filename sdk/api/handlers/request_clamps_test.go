@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestRequestClampsClampSamplingParameters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginCtx.Set("apiKey", "default-key")
+	ctx := context.WithValue(context.Background(), "gin", ginCtx)
+	low, high, topP, maxTokens := 0.2, 1.0, 0.9, 1000
+	h := &BaseAPIHandler{Cfg: &sdkconfig.SDKConfig{RequestClamps: []sdkconfig.RequestClamp{
+		{Temperature: &sdkconfig.NumberClamp{Min: &low, Max: &high}, TopP: &sdkconfig.NumberClamp{Max: &topP}, MaxTokens: &maxTokens},
+		{APIKeys: []string{"strict"}, Temperature: &sdkconfig.NumberClamp{Max: &high}, Reject: true},
+	}}}
+
+	out, errMsg := h.applyRequestClamps(ctx, constant.OpenAI, []byte(`{"temperature":1.7,"top_p":0.95,"max_tokens":4096,"max_completion_tokens":500}`))
+	if errMsg != nil {
+		t.Fatalf("openai clamp: %+v", errMsg)
+	}
+	if gjson.GetBytes(out, "temperature").Float() != 1.0 || gjson.GetBytes(out, "top_p").Float() != 0.9 ||
+		gjson.GetBytes(out, "max_tokens").Int() != 1000 || gjson.GetBytes(out, "max_completion_tokens").Int() != 500 {
+		t.Fatalf("openai clamped body = %s", out)
+	}
+
+	out, errMsg = h.applyRequestClamps(ctx, constant.Gemini, []byte(`{"generationConfig":{"temperature":0.0,"maxOutputTokens":8192}}`))
+	if errMsg != nil || gjson.GetBytes(out, "generationConfig.temperature").Float() != 0.2 || gjson.GetBytes(out, "generationConfig.maxOutputTokens").Int() != 1000 {
+		t.Fatalf("gemini clamped body = %s, %+v", out, errMsg)
+	}
+
+	body := []byte(`{"temperature":0.5}`)
+	if out, errMsg = h.applyRequestClamps(ctx, constant.Claude, body); errMsg != nil || string(out) != string(body) {
+		t.Fatalf("in-range body changed: %s, %+v", out, errMsg)
+	}
+
+	ginCtx.Set("apiKey", "strict")
+	if _, errMsg = h.applyRequestClamps(ctx, constant.OpenAI, []byte(`{"temperature":1.5}`)); errMsg == nil || errMsg.StatusCode != http.StatusBadRequest {
+		t.Fatalf("strict key accepted an out-of-range temperature: %+v", errMsg)
+	}
+	if out, errMsg = h.applyRequestClamps(ctx, constant.OpenAI, []byte(`{"top_p":0.99}`)); errMsg != nil || gjson.GetBytes(out, "top_p").Float() != 0.99 {
+		t.Fatalf("strict key inherited the default rule: %s, %+v", out, errMsg)
+	}
+}
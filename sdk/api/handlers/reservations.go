@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// reservationHeader names the account reservation a request should be served from.
+const reservationHeader = "X-Reservation"
+
+// markReservation routes the request to the accounts of the reservation named by the client, if any.
+func markReservation(ctx context.Context, opts *coreexecutor.Options) {
+	if ctx == nil {
+		return
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil || ginCtx.Request == nil {
+		return
+	}
+	name := strings.TrimSpace(ginCtx.GetHeader(reservationHeader))
+	if name == "" {
+		return
+	}
+	if opts.Metadata == nil {
+		opts.Metadata = make(map[string]any)
+	}
+	opts.Metadata[coreexecutor.ReservationMetadataKey] = name
+}
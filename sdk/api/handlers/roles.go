@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Role aliases clients send, mapped to the names each source format defines. Roles not listed are
+// only lowercased.
+var (
+	openAIRoleAliases = map[string]string{"developer": "system", "human": "user", "model": "assistant", "ai": "assistant"}
+	claudeRoleAliases = map[string]string{"developer": "system", "human": "user", "model": "assistant", "ai": "assistant"}
+	geminiRoleAliases = map[string]string{"developer": "system", "human": "user", "assistant": "model", "ai": "model", "": "user"}
+)
+
+// normalizesRoles reports whether request roles are normalised before translation.
+func (h *BaseAPIHandler) normalizesRoles() bool {
+	return h.Cfg != nil && h.Cfg.NormalizeRoles
+}
+
+// normalizeRequestRoles canonicalises the message roles of a request in its source format so every
+// translator receives the shapes it handles: role aliases are mapped, system messages are merged into
+// the single system prompt each provider supports, legacy OpenAI function messages become tool
+// messages and consecutive plain messages of the same role are merged. Requests that cannot be parsed
+// are returned unchanged.
+func normalizeRequestRoles(handlerType string, rawJSON []byte) []byte {
+	switch handlerType {
+	case constant.OpenAI:
+		return normalizeOpenAIRoles(rawJSON)
+	case constant.Claude:
+		return normalizeClaudeRoles(rawJSON)
+	case constant.Gemini:
+		return normalizeGeminiRoles(rawJSON, "")
+	case constant.GeminiCLI:
+		return normalizeGeminiRoles(rawJSON, "request.")
+	default:
+		return rawJSON
+	}
+}
+
+func canonicalRole(aliases map[string]string, role any) string {
+	name, _ := role.(string)
+	name = strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := aliases[name]; ok {
+		return alias
+	}
+	return name
+}
+
+// decodeMessages decodes the JSON array at path, keeping numbers verbatim.
+func decodeMessages(rawJSON []byte, path string) ([]map[string]any, bool) {
+	value := gjson.GetBytes(rawJSON, path)
+	if !value.IsArray() {
+		return nil, false
+	}
+	dec := json.NewDecoder(bytes.NewReader([]byte(value.Raw)))
+	dec.UseNumber()
+	var messages []map[string]any
+	if err := dec.Decode(&messages); err != nil {
+		return nil, false
+	}
+	return messages, true
+}
+
+func setJSON(rawJSON []byte, path string, value any) []byte {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return rawJSON
+	}
+	out, err := sjson.SetRawBytes(rawJSON, path, encoded)
+	if err != nil {
+		return rawJSON
+	}
+	return out
+}
+
+// contentParts converts message content to a list of typed parts; strings become one text part.
+func contentParts(content any) []any {
+	switch typed := content.(type) {
+	case string:
+		if typed == "" {
+			return nil
+		}
+		return []any{map[string]any{"type": "text", "text": typed}}
+	case []any:
+		return typed
+	case map[string]any:
+		return []any{typed}
+	default:
+		return nil
+	}
+}
+
+// mergeContent joins two message contents, keeping plain strings as strings.
+func mergeContent(a, b any) any {
+	as, aString := a.(string)
+	bs, bString := b.(string)
+	if aString && bString {
+		switch {
+		case as == "":
+			return bs
+		case bs == "":
+			return as
+		}
+		return as + "\n\n" + bs
+	}
+	return append(append([]any{}, contentParts(a)...), contentParts(b)...)
+}
+
+// hasPartType reports whether content holds a part of one of the given types.
+func hasPartType(content any, types ...string) bool {
+	parts, _ := content.([]any)
+	for _, part := range parts {
+		block, _ := part.(map[string]any)
+		for _, typ := range types {
+			if block["type"] == typ {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func normalizeOpenAIRoles(rawJSON []byte) []byte {
+	messages, ok := decodeMessages(rawJSON, "messages")
+	if !ok {
+		return rawJSON
+	}
+	var system []any
+	out := make([]map[string]any, 0, len(messages))
+	// pending holds call ids synthesised for legacy function_call messages, by function name.
+	pending := make(map[string][]string)
+	for i, msg := range messages {
+		role := canonicalRole(openAIRoleAliases, msg["role"])
+		msg["role"] = role
+		switch role {
+		case "system":
+			system = append(system, msg["content"])
+			continue
+		case "assistant":
+			if call, isCall := msg["function_call"].(map[string]any); isCall && msg["tool_calls"] == nil {
+				id := fmt.Sprintf("call_%d", i)
+				name, _ := call["name"].(string)
+				msg["tool_calls"] = []any{map[string]any{"id": id, "type": "function", "function": call}}
+				delete(msg, "function_call")
+				pending[name] = append(pending[name], id)
+			}
+		case "function":
+			msg["role"] = "tool"
+			if _, hasID := msg["tool_call_id"]; !hasID {
+				name, _ := msg["name"].(string)
+				if ids := pending[name]; len(ids) > 0 {
+					msg["tool_call_id"], pending[name] = ids[0], ids[1:]
+				} else {
+					msg["tool_call_id"] = name
+				}
+			}
+		}
+		if n := len(out); n > 0 && mergeableOpenAI(out[n-1], msg) {
+			out[n-1]["content"] = mergeContent(out[n-1]["content"], msg["content"])
+			continue
+		}
+		out = append(out, msg)
+	}
+	if len(system) > 0 {
+		merged := system[0]
+		for _, content := range system[1:] {
+			merged = mergeContent(merged, content)
+		}
+		out = append([]map[string]any{{"role": "system", "content": merged}}, out...)
+	}
+	return setJSON(rawJSON, "messages", out)
+}
+
+// mergeableOpenAI reports whether msg can be folded into prev: plain user or assistant messages of the
+// same role without tool calls.
+func mergeableOpenAI(prev, msg map[string]any) bool {
+	role := msg["role"]
+	if prev["role"] != role || (role != "user" && role != "assistant") {
+		return false
+	}
+	return prev["tool_calls"] == nil && msg["tool_calls"] == nil && prev["content"] != nil && msg["content"] != nil
+}
+
+func normalizeClaudeRoles(rawJSON []byte) []byte {
+	messages, ok := decodeMessages(rawJSON, "messages")
+	if !ok {
+		return rawJSON
+	}
+	var system []any
+	out := make([]map[string]any, 0, len(messages))
+	for _, msg := range messages {
+		role := canonicalRole(claudeRoleAliases, msg["role"])
+		msg["role"] = role
+		if role == "system" {
+			system = append(system, contentParts(msg["content"])...)
+			continue
+		}
+		if n := len(out); n > 0 && out[n-1]["role"] == role &&
+			!hasPartType(out[n-1]["content"], "tool_use", "tool_result") && !hasPartType(msg["content"], "tool_use", "tool_result") {
+			out[n-1]["content"] = mergeContent(out[n-1]["content"], msg["content"])
+			continue
+		}
+		out = append(out, msg)
+	}
+	rawJSON = setJSON(rawJSON, "messages", out)
+	if len(system) == 0 {
+		return rawJSON
+	}
+	var existing any
+	if current := gjson.GetBytes(rawJSON, "system"); current.Exists() {
+		_ = json.Unmarshal([]byte(current.Raw), &existing)
+	}
+	return setJSON(rawJSON, "system", append(contentParts(existing), system...))
+}
+
+func normalizeGeminiRoles(rawJSON []byte, prefix string) []byte {
+	contents, ok := decodeMessages(rawJSON, prefix+"contents")
+	if !ok {
+		return rawJSON
+	}
+	var system []any
+	out := make([]map[string]any, 0, len(contents))
+	for _, content := range contents {
+		role := canonicalRole(geminiRoleAliases, content["role"])
+		content["role"] = role
+		parts, _ := content["parts"].([]any)
+		if role == "system" {
+			system = append(system, parts...)
+			continue
+		}
+		if n := len(out); n > 0 && out[n-1]["role"] == role && (role == "user" || role == "model") &&
+			!hasFunctionParts(out[n-1]["parts"]) && !hasFunctionParts(parts) {
+			prevParts, _ := out[n-1]["parts"].([]any)
+			out[n-1]["parts"] = append(prevParts, parts...)
+			continue
+		}
+		out = append(out, content)
+	}
+	rawJSON = setJSON(rawJSON, prefix+"contents", out)
+	if len(system) == 0 {
+		return rawJSON
+	}
+	key := prefix + "systemInstruction"
+	if gjson.GetBytes(rawJSON, prefix+"system_instruction").Exists() {
+		key = prefix + "system_instruction"
+	}
+	var existing []any
+	if current := gjson.GetBytes(rawJSON, key+".parts"); current.IsArray() {
+		_ = json.Unmarshal([]byte(current.Raw), &existing)
+	}
+	return setJSON(rawJSON, key, map[string]any{"role": "user", "parts": append(existing, system...)})
+}
+
+func hasFunctionParts(parts any) bool {
+	list, _ := parts.([]any)
+	for _, part := range list {
+		p, _ := part.(map[string]any)
+		if p["functionCall"] != nil || p["functionResponse"] != nil {
+			return true
+		}
+	}
+	return false
+}
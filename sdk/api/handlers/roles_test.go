@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	_ "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator/builtin"
+	"github.com/tidwall/gjson"
+)
+
+func roles(payload []byte, path string) string {
+	var out []string
+	for _, item := range gjson.GetBytes(payload, path).Array() {
+		out = append(out, item.Get("role").String())
+	}
+	return strings.Join(out, ",")
+}
+
+func TestNormalizeOpenAIRoleMatrix(t *testing.T) {
+	cases := []struct {
+		name  string
+		in    string
+		roles string
+		// geminiRoles and claudeRoles are the translated conversation roles.
+		geminiRoles string
+		claudeRoles string
+	}{
+		{
+			name:        "system and developer merged",
+			in:          `[{"role":"system","content":"a"},{"role":"developer","content":"b"},{"role":"user","content":"hi"}]`,
+			roles:       "system,user",
+			geminiRoles: "user",
+			claudeRoles: "user,user",
+		},
+		{
+			name:        "consecutive same role",
+			in:          `[{"role":"user","content":"a"},{"role":"User","content":"b"},{"role":"assistant","content":"c"},{"role":"model","content":"d"},{"role":"user","content":"e"}]`,
+			roles:       "user,assistant,user",
+			geminiRoles: "user,model,user",
+			claudeRoles: "user,assistant,user",
+		},
+		{
+			name:        "mid conversation system",
+			in:          `[{"role":"user","content":"a"},{"role":"system","content":"s"},{"role":"user","content":"b"}]`,
+			roles:       "system,user",
+			geminiRoles: "user",
+			claudeRoles: "user,user",
+		},
+		{
+			name: "legacy function call",
+			in: `[{"role":"user","content":"weather?"},{"role":"assistant","content":null,"function_call":{"name":"get_weather","arguments":"{}"}},` +
+				`{"role":"function","name":"get_weather","content":"sunny"},{"role":"user","content":"thanks"}]`,
+			roles:       "user,assistant,tool,user",
+			geminiRoles: "user,model,tool,user",
+			claudeRoles: "user,assistant,user,user",
+		},
+		{
+			name:        "tool results are kept apart",
+			in:          `[{"role":"user","content":"q"},{"role":"assistant","content":null,"tool_calls":[{"id":"t1","type":"function","function":{"name":"f","arguments":"{}"}}]},{"role":"tool","tool_call_id":"t1","content":"r"},{"role":"assistant","content":"done"},{"role":"assistant","content":"really"}]`,
+			roles:       "user,assistant,tool,assistant",
+			geminiRoles: "user,model,tool,model",
+			claudeRoles: "user,assistant,user,assistant",
+		},
+	}
+	for _, tc := range cases {
+		raw := []byte(`{"model":"m","messages":` + tc.in + `}`)
+		got := normalizeRequestRoles(constant.OpenAI, raw)
+		if r := roles(got, "messages"); r != tc.roles {
+			t.Fatalf("%s: roles = %s, want %s (%s)", tc.name, r, tc.roles, got)
+		}
+		gemini := sdktranslator.TranslateRequest(sdktranslator.FromString(constant.OpenAI), sdktranslator.FromString(constant.Gemini), "m", got, false)
+		if r := roles(gemini, "contents"); r != tc.geminiRoles {
+			t.Fatalf("%s: gemini roles = %s, want %s (%s)", tc.name, r, tc.geminiRoles, gemini)
+		}
+		claude := sdktranslator.TranslateRequest(sdktranslator.FromString(constant.OpenAI), sdktranslator.FromString(constant.Claude), "m", got, false)
+		if r := roles(claude, "messages"); r != tc.claudeRoles {
+			t.Fatalf("%s: claude roles = %s, want %s (%s)", tc.name, r, tc.claudeRoles, claude)
+		}
+	}
+
+	got := normalizeRequestRoles(constant.OpenAI, []byte(`{"messages":[{"role":"system","content":"a"},{"role":"user","content":"hi"},{"role":"function","name":"get_weather","content":"x"}]}`))
+	if gjson.GetBytes(got, "messages.0.content").String() != "a" || gjson.GetBytes(got, "messages.2.tool_call_id").String() != "get_weather" {
+		t.Fatalf("unexpected normalisation: %s", got)
+	}
+}
+
+func TestNormalizeOpenAIRolesMergesSystemPrompts(t *testing.T) {
+	raw := []byte(`{"messages":[{"role":"system","content":"first"},{"role":"system","content":[{"type":"text","text":"second"}]},{"role":"user","content":"hi"}]}`)
+	got := normalizeRequestRoles(constant.OpenAI, raw)
+	gemini := sdktranslator.TranslateRequest(sdktranslator.FromString(constant.OpenAI), sdktranslator.FromString(constant.Gemini), "m", got, false)
+	var texts []string
+	for _, part := range gjson.GetBytes(gemini, "system_instruction.parts").Array() {
+		texts = append(texts, part.Get("text").String())
+	}
+	if strings.Join(texts, "|") != "first|second" {
+		t.Fatalf("system prompts not merged in order: %s", gemini)
+	}
+}
+
+func TestNormalizeClaudeRoles(t *testing.T) {
+	raw := []byte(`{"system":"base","messages":[{"role":"system","content":"extra"},{"role":"human","content":"a"},{"role":"user","content":[{"type":"text","text":"b"}]},` +
+		`{"role":"assistant","content":[{"type":"tool_use","id":"t","name":"f","input":{}}]},{"role":"user","content":[{"type":"tool_result","tool_use_id":"t","content":"r"}]},{"role":"user","content":"c"}]}`)
+	got := normalizeRequestRoles(constant.Claude, raw)
+	if r := roles(got, "messages"); r != "user,assistant,user,user" {
+		t.Fatalf("roles = %s (%s)", r, got)
+	}
+	if gjson.GetBytes(got, "system.0.text").String() != "base" || gjson.GetBytes(got, "system.1.text").String() != "extra" {
+		t.Fatalf("system prompt not merged: %s", got)
+	}
+	if gjson.GetBytes(got, "messages.0.content.#").Int() != 2 {
+		t.Fatalf("consecutive user messages not merged: %s", got)
+	}
+}
+
+func TestNormalizeGeminiRoles(t *testing.T) {
+	raw := []byte(`{"systemInstruction":{"parts":[{"text":"base"}]},"contents":[{"parts":[{"text":"a"}]},{"role":"user","parts":[{"text":"b"}]},` +
+		`{"role":"system","parts":[{"text":"extra"}]},{"role":"assistant","parts":[{"functionCall":{"name":"f","args":{}}}]},{"role":"function","parts":[{"functionResponse":{"name":"f","response":{}}}]},{"role":"model","parts":[{"text":"c"}]}]}`)
+	got := normalizeRequestRoles(constant.Gemini, raw)
+	if r := roles(got, "contents"); r != "user,model,function,model" {
+		t.Fatalf("roles = %s (%s)", r, got)
+	}
+	if gjson.GetBytes(got, "systemInstruction.parts.#").Int() != 2 || gjson.GetBytes(got, "contents.0.parts.#").Int() != 2 {
+		t.Fatalf("unexpected merge: %s", got)
+	}
+
+	cli := normalizeRequestRoles(constant.GeminiCLI, []byte(`{"request":{"contents":[{"role":"assistant","parts":[{"text":"a"}]}]}}`))
+	if roles(cli, "request.contents") != "model" {
+		t.Fatalf("gemini-cli roles not normalised: %s", cli)
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/tidwall/gjson"
+)
+
+// seedPath returns where a request in handlerType's format carries its sampling seed, or "" when the
+// format has none.
+func seedPath(handlerType string) string {
+	switch handlerType {
+	case constant.OpenAI:
+		return "seed"
+	case constant.Gemini:
+		return "generationConfig.seed"
+	case constant.GeminiCLI:
+		return "request.generationConfig.seed"
+	default:
+		return ""
+	}
+}
+
+// hasSeed reports whether the request asks for a sampling seed.
+func hasSeed(handlerType string, rawJSON []byte) bool {
+	path := seedPath(handlerType)
+	if path == "" {
+		return false
+	}
+	seed := gjson.GetBytes(rawJSON, path)
+	return seed.Exists() && seed.Type != gjson.Null
+}
+
+// rejectsUnseededProviders reports whether seed-policy is "reject".
+func (h *BaseAPIHandler) rejectsUnseededProviders() bool {
+	return h.Cfg != nil && strings.EqualFold(strings.TrimSpace(h.Cfg.SeedPolicy), "reject")
+}
+
+// applySeedPolicy narrows providers to those honouring the seed when seed-policy is "reject", failing
+// the request when none remain. Under the default "drop" policy providers are left unchanged and
+// providers without seed support ignore it.
+func (h *BaseAPIHandler) applySeedPolicy(handlerType, model string, providers []string, rawJSON []byte) ([]string, *interfaces.ErrorMessage) {
+	if !hasSeed(handlerType, rawJSON) || !h.rejectsUnseededProviders() {
+		return providers, nil
+	}
+	seeded := make([]string, 0, len(providers))
+	for _, provider := range providers {
+		if util.SupportsSeed(provider) {
+			seeded = append(seeded, provider)
+		}
+	}
+	if len(seeded) == 0 {
+		return nil, &interfaces.ErrorMessage{
+			StatusCode: http.StatusBadRequest,
+			Error:      fmt.Errorf("seed is not supported by the providers serving model %s", model),
+		}
+	}
+	return seeded, nil
+}
+
+// trackSeed attaches a served-by record to ctx for seeded requests so the response can report
+// whether the seed was honoured. It returns nil for requests without a seed.
+func trackSeed(ctx context.Context, handlerType string, rawJSON []byte) (context.Context, *coreauth.ServedBy) {
+	if !hasSeed(handlerType, rawJSON) {
+		return ctx, nil
+	}
+	return coreauth.WithServedBy(ctx)
+}
+
+// setSeedHeader reports through X-Seed-Honored whether the provider that served a seeded request
+// honours the seed.
+func setSeedHeader(ctx context.Context, served *coreauth.ServedBy) {
+	provider := served.Provider()
+	if provider == "" {
+		return
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil {
+		return
+	}
+	ginCtx.Header("X-Seed-Honored", strconv.FormatBool(util.SupportsSeed(provider)))
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func TestApplySeedPolicy(t *testing.T) {
+	providers := []string{"claude", "gemini", "codex"}
+	seeded := []byte(`{"seed":42,"messages":[]}`)
+
+	drop := &BaseAPIHandler{Cfg: &sdkconfig.SDKConfig{}}
+	if got, errMsg := drop.applySeedPolicy(constant.OpenAI, "m", providers, seeded); errMsg != nil || len(got) != 3 {
+		t.Fatalf("drop policy = %v, %+v", got, errMsg)
+	}
+
+	reject := &BaseAPIHandler{Cfg: &sdkconfig.SDKConfig{SeedPolicy: "reject"}}
+	if got, errMsg := reject.applySeedPolicy(constant.OpenAI, "m", providers, seeded); errMsg != nil || len(got) != 1 || got[0] != "gemini" {
+		t.Fatalf("reject policy = %v, %+v", got, errMsg)
+	}
+	if _, errMsg := reject.applySeedPolicy(constant.OpenAI, "m", []string{"claude"}, seeded); errMsg == nil || errMsg.StatusCode != http.StatusBadRequest {
+		t.Fatalf("reject without seeded providers: %+v", errMsg)
+	}
+	if got, errMsg := reject.applySeedPolicy(constant.OpenAI, "m", []string{"claude"}, []byte(`{"seed":null}`)); errMsg != nil || len(got) != 1 {
+		t.Fatalf("null seed = %v, %+v", got, errMsg)
+	}
+	gemini := []byte(`{"request":{"generationConfig":{"seed":7}}}`)
+	if _, errMsg := reject.applySeedPolicy(constant.GeminiCLI, "m", []string{"codex"}, gemini); errMsg == nil {
+		t.Fatal("gemini cli seed was not detected")
+	}
+	if _, errMsg := reject.applySeedPolicy(constant.Claude, "m", []string{"codex"}, seeded); errMsg != nil {
+		t.Fatalf("claude requests carry no seed: %+v", errMsg)
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// applyStopSequenceLimits enforces the stop sequence limits of the providers serving model when
+// stop-sequence-limits is enabled. A single stop string is rewritten as an array. In "trim" mode the
+// sequences beyond the strictest provider limit are dropped; in "reject" mode providers whose limit
+// is exceeded are dropped and the request fails when none remain.
+func (h *BaseAPIHandler) applyStopSequenceLimits(handlerType, model string, providers []string, rawJSON []byte) ([]string, []byte, *interfaces.ErrorMessage) {
+	if h.Cfg == nil || !h.Cfg.StopSequenceLimits.Enabled {
+		return providers, rawJSON, nil
+	}
+	path := stopSequencePath(handlerType)
+	if path == "" {
+		return providers, rawJSON, nil
+	}
+	value := gjson.GetBytes(rawJSON, path)
+	if value.Type != gjson.String && !value.IsArray() {
+		return providers, rawJSON, nil
+	}
+	stops := stopSequencesFromRequest(handlerType, rawJSON)
+	if len(stops) == 0 {
+		return providers, rawJSON, nil
+	}
+	overrides := h.Cfg.StopSequenceLimits.Limits
+	kept := stops
+	if h.Cfg.StopSequenceLimits.Rejects() {
+		accepted := make([]string, 0, len(providers))
+		for _, provider := range providers {
+			if limit := util.StopSequenceLimit(provider, overrides); limit == 0 || len(stops) <= limit {
+				accepted = append(accepted, provider)
+			}
+		}
+		if len(accepted) == 0 {
+			return nil, rawJSON, &interfaces.ErrorMessage{
+				StatusCode: http.StatusBadRequest,
+				Error:      fmt.Errorf("request has %d stop sequences, more than the providers serving model %s accept", len(stops), model),
+			}
+		}
+		providers = accepted
+	} else {
+		strictest := 0
+		for _, provider := range providers {
+			if limit := util.StopSequenceLimit(provider, overrides); limit > 0 && (strictest == 0 || limit < strictest) {
+				strictest = limit
+			}
+		}
+		if strictest > 0 && len(stops) > strictest {
+			kept = stops[:strictest]
+			log.Infof("trimmed stop sequences from %d to %d for model %s", len(stops), strictest, model)
+		}
+	}
+	if value.IsArray() && len(kept) == len(value.Array()) {
+		return providers, rawJSON, nil
+	}
+	if updated, err := sjson.SetBytes(rawJSON, path, kept); err == nil {
+		rawJSON = updated
+	}
+	return providers, rawJSON, nil
+}
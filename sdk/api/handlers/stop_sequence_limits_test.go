@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestApplyStopSequenceLimits(t *testing.T) {
+	providers := []string{"claude", "gemini", "openai-compat"}
+	six := []byte(`{"stop":["a","b","c","d","e","f"]}`)
+
+	off := &BaseAPIHandler{Cfg: &sdkconfig.SDKConfig{}}
+	if _, out, _ := off.applyStopSequenceLimits(constant.OpenAI, "m", providers, six); string(out) != string(six) {
+		t.Fatalf("disabled enforcement rewrote the request: %s", out)
+	}
+
+	trim := &BaseAPIHandler{Cfg: &sdkconfig.SDKConfig{StopSequenceLimits: sdkconfig.StopSequenceLimits{Enabled: true}}}
+	got, out, errMsg := trim.applyStopSequenceLimits(constant.OpenAI, "m", providers, six)
+	if errMsg != nil || len(got) != 3 || len(gjson.GetBytes(out, "stop").Array()) != 4 {
+		t.Fatalf("trim = %v, %s, %+v", got, out, errMsg)
+	}
+	if _, out, _ = trim.applyStopSequenceLimits(constant.OpenAI, "m", []string{"claude"}, []byte(`{"stop":"END"}`)); gjson.GetBytes(out, "stop").Raw != `["END"]` {
+		t.Fatalf("single stop string not normalised: %s", out)
+	}
+	gemini := []byte(`{"generationConfig":{"stopSequences":["a","b","c","d","e","f"]}}`)
+	if _, out, _ = trim.applyStopSequenceLimits(constant.Gemini, "m", []string{"gemini"}, gemini); len(gjson.GetBytes(out, "generationConfig.stopSequences").Array()) != 5 {
+		t.Fatalf("gemini trim: %s", out)
+	}
+
+	reject := &BaseAPIHandler{Cfg: &sdkconfig.SDKConfig{StopSequenceLimits: sdkconfig.StopSequenceLimits{
+		Enabled: true, Mode: "reject", Limits: map[string]int{"claude": 2},
+	}}}
+	if _, _, errMsg = reject.applyStopSequenceLimits(constant.OpenAI, "m", providers, six); errMsg == nil || errMsg.StatusCode != http.StatusBadRequest {
+		t.Fatalf("reject without accepting providers: %+v", errMsg)
+	}
+	two := []byte(`{"stop":["a","b"]}`)
+	if got, _, errMsg = reject.applyStopSequenceLimits(constant.OpenAI, "m", providers, two); errMsg != nil || len(got) != 3 {
+		t.Fatalf("reject within limits = %v, %+v", got, errMsg)
+	}
+	five := []byte(`{"stop":["a","b","c","d","e"]}`)
+	if got, _, errMsg = reject.applyStopSequenceLimits(constant.OpenAI, "m", providers, five); errMsg != nil || len(got) != 1 || got[0] != "gemini" {
+		t.Fatalf("reject narrowing = %v, %+v", got, errMsg)
+	}
+}
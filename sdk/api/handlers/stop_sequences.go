@@ -0,0 +1,356 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"golang.org/x/net/context"
+)
+
+// stopSequencePath returns where a request in handlerType's format carries its stop sequences, or ""
+// when the format has none.
+func stopSequencePath(handlerType string) string {
+	switch handlerType {
+	case constant.OpenAI:
+		return "stop"
+	case constant.Claude:
+		return "stop_sequences"
+	case constant.Gemini:
+		return "generationConfig.stopSequences"
+	case constant.GeminiCLI:
+		return "request.generationConfig.stopSequences"
+	default:
+		return ""
+	}
+}
+
+// stopSequencesFromRequest returns the stop sequences requested by the client in its source format.
+func stopSequencesFromRequest(handlerType string, rawJSON []byte) []string {
+	path := stopSequencePath(handlerType)
+	if path == "" {
+		return nil
+	}
+	value := gjson.GetBytes(rawJSON, path)
+	var stops []string
+	if value.Type == gjson.String {
+		if s := value.String(); s != "" {
+			stops = append(stops, s)
+		}
+	} else if value.IsArray() {
+		for _, item := range value.Array() {
+			if s := item.String(); s != "" {
+				stops = append(stops, s)
+			}
+		}
+	}
+	return stops
+}
+
+// stopTrimmingStops returns the stop sequences to enforce for this request, or nil when trimming is off.
+func (h *BaseAPIHandler) stopTrimmingStops(ctx context.Context, handlerType string, rawJSON []byte) []string {
+	if h.Cfg == nil || !h.Cfg.PostProcessing.TrimStopSequencesFor(requestAPIKey(ctx)) {
+		return nil
+	}
+	return stopSequencesFromRequest(handlerType, rawJSON)
+}
+
+// cutAtStop returns text truncated before the earliest stop sequence and whether one was found.
+func cutAtStop(text string, stops []string) (string, string, bool) {
+	cut := -1
+	matched := ""
+	for _, stop := range stops {
+		if idx := strings.Index(text, stop); idx >= 0 && (cut < 0 || idx < cut) {
+			cut = idx
+			matched = stop
+		}
+	}
+	if cut < 0 {
+		return text, "", false
+	}
+	return text[:cut], matched, true
+}
+
+// stopTrimmer applies stop sequences to incrementally streamed text. It holds back the shortest tail
+// that could still begin a stop sequence so matches spanning chunk boundaries are detected.
+type stopTrimmer struct {
+	stops   []string
+	held    string
+	stopped bool
+	matched string
+}
+
+// feed consumes a text delta and returns the text safe to emit now.
+func (t *stopTrimmer) feed(delta string) string {
+	if t.stopped {
+		return ""
+	}
+	text := t.held + delta
+	if prefix, matched, found := cutAtStop(text, t.stops); found {
+		t.stopped = true
+		t.matched = matched
+		t.held = ""
+		return prefix
+	}
+	keep := 0
+	for _, stop := range t.stops {
+		for n := len(stop) - 1; n > keep; n-- {
+			if strings.HasSuffix(text, stop[:n]) {
+				keep = n
+				break
+			}
+		}
+	}
+	t.held = text[len(text)-keep:]
+	return text[:len(text)-keep]
+}
+
+// flush releases any held-back text at the end of the output.
+func (t *stopTrimmer) flush() string {
+	held := t.held
+	t.held = ""
+	if t.stopped {
+		return ""
+	}
+	return held
+}
+
+// trimCompletionAtStops truncates a non-streaming response payload at the earliest stop sequence.
+func trimCompletionAtStops(handlerType string, payload []byte, stops []string) []byte {
+	switch handlerType {
+	case constant.OpenAI:
+		choices := gjson.GetBytes(payload, "choices")
+		for i := range choices.Array() {
+			path := fmt.Sprintf("choices.%d.message.content", i)
+			if text, _, found := cutAtStop(gjson.GetBytes(payload, path).String(), stops); found {
+				payload, _ = sjson.SetBytes(payload, path, text)
+				payload, _ = sjson.SetBytes(payload, fmt.Sprintf("choices.%d.finish_reason", i), "stop")
+			}
+		}
+	case constant.Claude:
+		blocks := gjson.GetBytes(payload, "content").Array()
+		for i, block := range blocks {
+			if block.Get("type").String() != "text" {
+				continue
+			}
+			text, matched, found := cutAtStop(block.Get("text").String(), stops)
+			if !found {
+				continue
+			}
+			payload, _ = sjson.SetBytes(payload, fmt.Sprintf("content.%d.text", i), text)
+			for j := len(blocks) - 1; j > i; j-- {
+				payload, _ = sjson.DeleteBytes(payload, fmt.Sprintf("content.%d", j))
+			}
+			payload, _ = sjson.SetBytes(payload, "stop_reason", "stop_sequence")
+			payload, _ = sjson.SetBytes(payload, "stop_sequence", matched)
+			break
+		}
+	case constant.Gemini, constant.GeminiCLI:
+		root := ""
+		if handlerType == constant.GeminiCLI && gjson.GetBytes(payload, "response").Exists() {
+			root = "response."
+		}
+		candidates := gjson.GetBytes(payload, root+"candidates").Array()
+		for c := range candidates {
+			partsPath := fmt.Sprintf("%scandidates.%d.content.parts", root, c)
+			parts := gjson.GetBytes(payload, partsPath).Array()
+			for p, part := range parts {
+				if !part.Get("text").Exists() || part.Get("thought").Bool() {
+					continue
+				}
+				text, _, found := cutAtStop(part.Get("text").String(), stops)
+				if !found {
+					continue
+				}
+				payload, _ = sjson.SetBytes(payload, fmt.Sprintf("%s.%d.text", partsPath, p), text)
+				for j := len(parts) - 1; j > p; j-- {
+					payload, _ = sjson.DeleteBytes(payload, fmt.Sprintf("%s.%d", partsPath, j))
+				}
+				payload, _ = sjson.SetBytes(payload, fmt.Sprintf("%scandidates.%d.finishReason", root, c), "STOP")
+				break
+			}
+		}
+	}
+	return payload
+}
+
+// streamStopTrimmer rewrites streamed chunks of one response so text stops at the requested stop sequences.
+type streamStopTrimmer struct {
+	handlerType string
+	trimmer     stopTrimmer
+	// claudeIndex remembers the last Claude text block index so held text can be re-emitted into it.
+	claudeIndex int64
+	// lastOpenAI is the last OpenAI chunk seen, the template for text flushed when the stream ends.
+	lastOpenAI []byte
+}
+
+func newStreamStopTrimmer(handlerType string, stops []string) *streamStopTrimmer {
+	return &streamStopTrimmer{handlerType: handlerType, trimmer: stopTrimmer{stops: stops}}
+}
+
+// process rewrites a chunk, returning nil when nothing remains to forward.
+func (s *streamStopTrimmer) process(chunk []byte) []byte {
+	switch s.handlerType {
+	case constant.OpenAI:
+		return s.processOpenAI(chunk)
+	case constant.Claude:
+		return s.processClaude(chunk)
+	case constant.Gemini, constant.GeminiCLI:
+		return s.processGemini(chunk)
+	}
+	return chunk
+}
+
+// finish returns a chunk carrying text still held back when the stream ends without a finish
+// reason, or nil when nothing is left.
+func (s *streamStopTrimmer) finish() []byte {
+	held := s.trimmer.flush()
+	if held == "" || s.handlerType != constant.OpenAI || s.lastOpenAI == nil {
+		return nil
+	}
+	chunk, _ := sjson.SetRawBytes(s.lastOpenAI, "choices.0.delta", []byte(`{}`))
+	chunk, _ = sjson.SetBytes(chunk, "choices.0.delta.content", held)
+	chunk, _ = sjson.SetRawBytes(chunk, "choices.0.finish_reason", []byte("null"))
+	chunk, _ = sjson.DeleteBytes(chunk, "usage")
+	return chunk
+}
+
+func (s *streamStopTrimmer) processOpenAI(chunk []byte) []byte {
+	if !gjson.ValidBytes(chunk) || len(gjson.GetBytes(chunk, "choices").Array()) != 1 {
+		return chunk
+	}
+	s.lastOpenAI = chunk
+	if s.trimmer.stopped {
+		// The chunk that hit the stop sequence already carried the finish reason.
+		return nil
+	}
+	const contentPath = "choices.0.delta.content"
+	finish := gjson.GetBytes(chunk, "choices.0.finish_reason").String()
+	content := gjson.GetBytes(chunk, contentPath)
+	if !content.Exists() && finish == "" {
+		return chunk
+	}
+	text := s.trimmer.feed(content.String())
+	if finish != "" {
+		text += s.trimmer.flush()
+	}
+	if content.Exists() || text != "" {
+		chunk, _ = sjson.SetBytes(chunk, contentPath, text)
+	}
+	if s.trimmer.stopped {
+		chunk, _ = sjson.SetBytes(chunk, "choices.0.finish_reason", "stop")
+	}
+	return chunk
+}
+
+func (s *streamStopTrimmer) processGemini(chunk []byte) []byte {
+	if !gjson.ValidBytes(chunk) {
+		return chunk
+	}
+	root := ""
+	if s.handlerType == constant.GeminiCLI && gjson.GetBytes(chunk, "response").Exists() {
+		root = "response."
+	}
+	partsPath := root + "candidates.0.content.parts"
+	finishPath := root + "candidates.0.finishReason"
+	finish := gjson.GetBytes(chunk, finishPath).String()
+	parts := gjson.GetBytes(chunk, partsPath).Array()
+	wasStopped := s.trimmer.stopped
+	lastText := -1
+	for p, part := range parts {
+		if !part.Get("text").Exists() || part.Get("thought").Bool() {
+			continue
+		}
+		lastText = p
+		chunk, _ = sjson.SetBytes(chunk, fmt.Sprintf("%s.%d.text", partsPath, p), s.trimmer.feed(part.Get("text").String()))
+	}
+	if finish != "" {
+		if held := s.trimmer.flush(); held != "" {
+			if lastText >= 0 {
+				path := fmt.Sprintf("%s.%d.text", partsPath, lastText)
+				chunk, _ = sjson.SetBytes(chunk, path, gjson.GetBytes(chunk, path).String()+held)
+			} else {
+				chunk, _ = sjson.SetBytes(chunk, partsPath+".-1", map[string]string{"text": held})
+			}
+		}
+	}
+	if wasStopped && finish == "" && lastText >= 0 {
+		return nil
+	}
+	if s.trimmer.stopped && !wasStopped {
+		chunk, _ = sjson.SetBytes(chunk, finishPath, "STOP")
+	}
+	return chunk
+}
+
+// processClaude rewrites the SSE event blocks contained in a Claude stream chunk.
+func (s *streamStopTrimmer) processClaude(chunk []byte) []byte {
+	var out bytes.Buffer
+	for _, block := range bytes.SplitAfter(chunk, []byte("\n\n")) {
+		if len(bytes.TrimSpace(block)) == 0 {
+			out.Write(block)
+			continue
+		}
+		out.Write(s.processClaudeEvent(block))
+	}
+	if out.Len() == 0 {
+		return nil
+	}
+	return out.Bytes()
+}
+
+func (s *streamStopTrimmer) processClaudeEvent(block []byte) []byte {
+	lines := bytes.Split(block, []byte("\n"))
+	dataIdx := -1
+	for i, line := range lines {
+		if bytes.HasPrefix(line, []byte("data:")) {
+			dataIdx = i
+			break
+		}
+	}
+	if dataIdx < 0 {
+		return block
+	}
+	data := bytes.TrimSpace(bytes.TrimPrefix(lines[dataIdx], []byte("data:")))
+	if !gjson.ValidBytes(data) {
+		return block
+	}
+	rewrite := func(payload []byte) []byte {
+		lines[dataIdx] = append([]byte("data: "), payload...)
+		return bytes.Join(lines, []byte("\n"))
+	}
+	switch gjson.GetBytes(data, "type").String() {
+	case "content_block_delta":
+		if gjson.GetBytes(data, "delta.type").String() != "text_delta" {
+			return block
+		}
+		s.claudeIndex = gjson.GetBytes(data, "index").Int()
+		if s.trimmer.stopped {
+			return nil
+		}
+		text := s.trimmer.feed(gjson.GetBytes(data, "delta.text").String())
+		if text == "" {
+			return nil
+		}
+		data, _ = sjson.SetBytes(data, "delta.text", text)
+		return rewrite(data)
+	case "content_block_stop", "message_delta":
+		var prefix []byte
+		if held := s.trimmer.flush(); held != "" {
+			delta := []byte(`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":""}}`)
+			delta, _ = sjson.SetBytes(delta, "index", s.claudeIndex)
+			delta, _ = sjson.SetBytes(delta, "delta.text", held)
+			prefix = append(append([]byte("event: content_block_delta\ndata: "), delta...), '\n', '\n')
+		}
+		if s.trimmer.stopped && gjson.GetBytes(data, "type").String() == "message_delta" {
+			data, _ = sjson.SetBytes(data, "delta.stop_reason", "stop_sequence")
+			data, _ = sjson.SetBytes(data, "delta.stop_sequence", s.trimmer.matched)
+			return append(prefix, rewrite(data)...)
+		}
+		return append(prefix, block...)
+	}
+	return block
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/tidwall/gjson"
+)
+
+func TestStopTrimmer_MatchAcrossChunks(t *testing.T) {
+	trimmer := stopTrimmer{stops: []string{"END"}}
+	got := trimmer.feed("hello E") + trimmer.feed("N") + trimmer.feed("D tail") + trimmer.flush()
+	if got != "hello " {
+		t.Fatalf("unexpected output %q", got)
+	}
+}
+
+func TestStopTrimmer_ReleasesPartialPrefix(t *testing.T) {
+	trimmer := stopTrimmer{stops: []string{"END"}}
+	held := trimmer.feed("hello EN")
+	if held != "hello " {
+		t.Fatalf("expected possible stop prefix to be held back, got %q", held)
+	}
+	got := held + trimmer.feed("x") + trimmer.flush()
+	if got != "hello ENx" {
+		t.Fatalf("unexpected output %q", got)
+	}
+}
+
+func TestStreamStopTrimmer_OpenAIChunks(t *testing.T) {
+	s := newStreamStopTrimmer(constant.OpenAI, []string{"###"})
+	first := s.process([]byte(`{"choices":[{"index":0,"delta":{"content":"abc#"}}]}`))
+	second := s.process([]byte(`{"choices":[{"index":0,"delta":{"content":"##def"}}]}`))
+	third := s.process([]byte(`{"choices":[{"index":0,"delta":{},"finish_reason":"length"}]}`))
+
+	if got := gjson.GetBytes(first, "choices.0.delta.content").String(); got != "abc" {
+		t.Fatalf("first chunk content %q", got)
+	}
+	if got := gjson.GetBytes(second, "choices.0.delta.content").String(); got != "" {
+		t.Fatalf("second chunk content %q", got)
+	}
+	if got := gjson.GetBytes(second, "choices.0.finish_reason").String(); got != "stop" {
+		t.Fatalf("second chunk finish reason %q", got)
+	}
+	if third != nil {
+		t.Fatalf("expected chunks after stop to be dropped, got %s", third)
+	}
+}
+
+func TestStreamStopTrimmer_OpenAIFlushesHeldTextAtEOF(t *testing.T) {
+	s := newStreamStopTrimmer(constant.OpenAI, []string{"###"})
+	first := s.process([]byte(`{"id":"c1","model":"m","choices":[{"index":0,"delta":{"content":"abc#"},"finish_reason":null}]}`))
+	if got := gjson.GetBytes(first, "choices.0.delta.content").String(); got != "abc" {
+		t.Fatalf("first chunk content %q", got)
+	}
+	tail := s.finish()
+	if got := gjson.GetBytes(tail, "choices.0.delta.content").String(); got != "#" || gjson.GetBytes(tail, "id").String() != "c1" {
+		t.Fatalf("tail chunk = %s, want the held # in chunk c1", tail)
+	}
+	if gjson.GetBytes(tail, "choices.0.finish_reason").Type != gjson.Null {
+		t.Fatalf("tail chunk must not invent a finish reason: %s", tail)
+	}
+	if again := s.finish(); again != nil {
+		t.Fatalf("held text flushed twice: %s", again)
+	}
+}
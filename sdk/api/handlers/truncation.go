@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const truncationWarningMessage = "output was truncated by the max_tokens limit; request a continuation for the rest"
+
+// truncationMarker flags OpenAI chat completions cut short by the output token limit. It keeps state
+// across the chunks of one stream so the warning is added once.
+type truncationMarker struct {
+	// limit is the client's requested max_tokens, or zero when unset.
+	limit int64
+	// stopped records a plain stop finish, which a later usage chunk may reveal as truncation.
+	stopped bool
+	warned  bool
+}
+
+// truncationMarkerFor returns a marker for this request, or nil when truncation warnings are off.
+func (h *BaseAPIHandler) truncationMarkerFor(ctx context.Context, handlerType string, rawJSON []byte) *truncationMarker {
+	if handlerType != constant.OpenAI || h.Cfg == nil || !h.Cfg.PostProcessing.TruncationWarningFor(requestAPIKey(ctx)) {
+		return nil
+	}
+	limit := gjson.GetBytes(rawJSON, "max_completion_tokens").Int()
+	if limit <= 0 {
+		limit = gjson.GetBytes(rawJSON, "max_tokens").Int()
+	}
+	return &truncationMarker{limit: max(limit, 0)}
+}
+
+// mark reports finish_reason "length" on truncated choices and adds a warning to the payload the first
+// time truncation is seen. It reports whether the payload carries the warning.
+//
+// Providers signal the limit differently: OpenAI uses "length", Claude "max_tokens", Gemini
+// "MAX_TOKENS" and Codex an incomplete response with "max_output_tokens". Some OpenAI-compatible
+// backends report a plain "stop"; that is treated as truncation when the completion used the whole
+// requested max_tokens.
+func (m *truncationMarker) mark(payload []byte) ([]byte, bool) {
+	if m == nil || m.warned || !gjson.ValidBytes(payload) {
+		return payload, false
+	}
+	completion := gjson.GetBytes(payload, "usage.completion_tokens")
+	exhausted := m.limit > 0 && completion.Exists() && completion.Int() >= m.limit
+	truncated := false
+	for i, choice := range gjson.GetBytes(payload, "choices").Array() {
+		finish := choice.Get("finish_reason")
+		if finish.Type != gjson.String || finish.String() == "" {
+			continue
+		}
+		native := choice.Get("native_finish_reason").String()
+		length := normalizedFinishReason(finish.String()) == "length" || (native != "" && normalizedFinishReason(native) == "length")
+		if !length && normalizedFinishReason(finish.String()) == "stop" {
+			m.stopped = true
+			length = exhausted
+		}
+		if !length {
+			continue
+		}
+		truncated = true
+		if finish.String() != "length" {
+			if native == "" {
+				payload, _ = sjson.SetBytes(payload, fmt.Sprintf("choices.%d.native_finish_reason", i), finish.String())
+			}
+			payload, _ = sjson.SetBytes(payload, fmt.Sprintf("choices.%d.finish_reason", i), "length")
+		}
+	}
+	// Usage may arrive in a trailing chunk after the finish reason.
+	if !truncated && m.stopped && exhausted {
+		truncated = true
+	}
+	if !truncated {
+		return payload, false
+	}
+	m.warned = true
+	warning := map[string]any{"type": "truncated", "message": truncationWarningMessage}
+	if completion.Exists() {
+		warning["completion_tokens"] = completion.Int()
+	}
+	if m.limit > 0 {
+		warning["max_tokens"] = m.limit
+	}
+	payload, _ = sjson.SetBytes(payload, "warning", warning)
+	return payload, true
+}
+
+// setTruncationHeaders reports a truncated non-streaming completion in the response headers.
+func setTruncationHeaders(ctx context.Context, payload []byte) {
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil {
+		return
+	}
+	ginCtx.Header("X-Truncated", "true")
+	if tokens := gjson.GetBytes(payload, "warning.completion_tokens"); tokens.Exists() {
+		ginCtx.Header("X-Truncated-Completion-Tokens", strconv.FormatInt(tokens.Int(), 10))
+	}
+}
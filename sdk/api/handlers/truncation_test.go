@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestTruncationMarkerProviderReasons(t *testing.T) {
+	for _, native := range []string{"length", "max_tokens", "MAX_TOKENS", "max_output_tokens"} {
+		m := &truncationMarker{}
+		payload := []byte(`{"choices":[{"index":0,"message":{"content":"partial"},"finish_reason":"` + native + `"}],"usage":{"completion_tokens":64}}`)
+		got, truncated := m.mark(payload)
+		if !truncated {
+			t.Fatalf("%s: expected truncation", native)
+		}
+		if reason := gjson.GetBytes(got, "choices.0.finish_reason").String(); reason != "length" {
+			t.Fatalf("%s: finish_reason = %q", native, reason)
+		}
+		if gjson.GetBytes(got, "warning.type").String() != "truncated" || gjson.GetBytes(got, "warning.completion_tokens").Int() != 64 {
+			t.Fatalf("%s: unexpected warning: %s", native, got)
+		}
+	}
+}
+
+func TestTruncationMarkerAmbiguousStop(t *testing.T) {
+	m := &truncationMarker{limit: 32}
+	got, truncated := m.mark([]byte(`{"choices":[{"finish_reason":"stop"}],"usage":{"completion_tokens":32}}`))
+	if !truncated || gjson.GetBytes(got, "choices.0.finish_reason").String() != "length" {
+		t.Fatalf("expected exhausted budget to count as truncation: %s", got)
+	}
+	if gjson.GetBytes(got, "choices.0.native_finish_reason").String() != "stop" || gjson.GetBytes(got, "warning.max_tokens").Int() != 32 {
+		t.Fatalf("unexpected payload: %s", got)
+	}
+
+	m = &truncationMarker{limit: 32}
+	if _, truncated = m.mark([]byte(`{"choices":[{"finish_reason":"stop"}],"usage":{"completion_tokens":12}}`)); truncated {
+		t.Fatal("completion under the limit must not count as truncation")
+	}
+	m = &truncationMarker{limit: 32}
+	if _, truncated = m.mark([]byte(`{"choices":[{"finish_reason":"tool_calls"}],"usage":{"completion_tokens":32}}`)); truncated {
+		t.Fatal("tool calls must not count as truncation")
+	}
+}
+
+func TestTruncationMarkerStreamWarnsOnce(t *testing.T) {
+	m := &truncationMarker{limit: 16}
+	if _, truncated := m.mark([]byte(`{"choices":[{"delta":{"content":"a"},"finish_reason":null}]}`)); truncated {
+		t.Fatal("unfinished chunk reported as truncated")
+	}
+	if _, truncated := m.mark([]byte(`{"choices":[{"delta":{},"finish_reason":"stop"}]}`)); truncated {
+		t.Fatal("finish without usage reported as truncated")
+	}
+	got, truncated := m.mark([]byte(`{"choices":[],"usage":{"completion_tokens":16}}`))
+	if !truncated || gjson.GetBytes(got, "warning.completion_tokens").Int() != 16 {
+		t.Fatalf("expected warning on trailing usage chunk: %s", got)
+	}
+	if _, truncated = m.mark([]byte(`{"choices":[{"finish_reason":"length"}]}`)); truncated {
+		t.Fatal("warning added twice")
+	}
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// AccountActivity reports the work currently running against an account: a token refresh and the
+// requests dispatched to it that have not finished yet.
+type AccountActivity struct {
+	ID                      string     `json:"id"`
+	Refreshing              bool       `json:"refreshing"`
+	RefreshingSince         *time.Time `json:"refreshing_since,omitempty"`
+	RefreshAgeSeconds       float64    `json:"refresh_age_seconds,omitempty"`
+	InFlightRequests        int        `json:"in_flight_requests"`
+	OldestRequestSince      *time.Time `json:"oldest_request_since,omitempty"`
+	OldestRequestAgeSeconds float64    `json:"oldest_request_age_seconds,omitempty"`
+}
+
+// accountActivity tracks the start time of every in-flight request per account.
+type accountActivity struct {
+	mu       sync.Mutex
+	next     uint64
+	requests map[string]map[uint64]time.Time
+}
+
+// begin records a request dispatched to authID and returns the function that marks it finished.
+func (a *accountActivity) begin(authID string, now time.Time) func() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.requests == nil {
+		a.requests = make(map[string]map[uint64]time.Time)
+	}
+	if a.requests[authID] == nil {
+		a.requests[authID] = make(map[uint64]time.Time)
+	}
+	a.next++
+	token := a.next
+	a.requests[authID][token] = now
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			a.mu.Lock()
+			defer a.mu.Unlock()
+			delete(a.requests[authID], token)
+			if len(a.requests[authID]) == 0 {
+				delete(a.requests, authID)
+			}
+		})
+	}
+}
+
+// inFlight returns the number of requests currently running against authID.
+func (a *accountActivity) inFlight(authID string) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.requests[authID])
+}
+
+// beginRequest counts a request dispatched to authID against its reservation and its in-flight
+// activity, returning the function that marks it finished and gives back the concurrency slot taken
+// when the account was selected.
+func (m *Manager) beginRequest(authID string) func() {
+	finishReserved := m.reservations.begin(authID)
+	finishActivity := m.activity.begin(authID, time.Now())
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			finishActivity()
+			finishReserved()
+			m.concurrency.release(authID)
+		})
+	}
+}
+
+// Activity reports the in-progress refresh and in-flight requests of the account, or false when the
+// account is not registered.
+func (m *Manager) Activity(id string) (AccountActivity, bool) {
+	if m == nil {
+		return AccountActivity{}, false
+	}
+	m.mu.RLock()
+	_, ok := m.auths[id]
+	m.mu.RUnlock()
+	if !ok {
+		return AccountActivity{}, false
+	}
+	now := time.Now()
+	out := AccountActivity{ID: id}
+
+	m.refreshes.mu.Lock()
+	started, refreshing := m.refreshes.started[id]
+	m.refreshes.mu.Unlock()
+	if refreshing {
+		out.Refreshing = true
+		out.RefreshingSince = &started
+		out.RefreshAgeSeconds = now.Sub(started).Seconds()
+	}
+
+	m.activity.mu.Lock()
+	var oldest time.Time
+	for _, since := range m.activity.requests[id] {
+		out.InFlightRequests++
+		if oldest.IsZero() || since.Before(oldest) {
+			oldest = since
+		}
+	}
+	m.activity.mu.Unlock()
+	if !oldest.IsZero() {
+		out.OldestRequestSince = &oldest
+		out.OldestRequestAgeSeconds = now.Sub(oldest).Seconds()
+	}
+	return out, true
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestActivityReportsRefreshAndInFlightRequests(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&slowRefreshExecutor{})
+	if _, err := m.Register(context.Background(), &Auth{ID: "a", Provider: "eager", Status: StatusActive, Metadata: map[string]any{"access_token": "at"}}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if _, ok := m.Activity("missing"); ok {
+		t.Fatal("unknown account reported activity")
+	}
+
+	first := m.beginRequest("a")
+	time.Sleep(5 * time.Millisecond)
+	second := m.beginRequest("a")
+	activity, ok := m.Activity("a")
+	if !ok || activity.InFlightRequests != 2 || activity.OldestRequestSince == nil || activity.OldestRequestAgeSeconds < 0.005 {
+		t.Fatalf("unexpected activity with two requests: %+v", activity)
+	}
+	first()
+	first()
+	second()
+	if activity, _ = m.Activity("a"); activity.InFlightRequests != 0 || activity.OldestRequestSince != nil {
+		t.Fatalf("finished requests still in flight: %+v", activity)
+	}
+
+	done := m.refreshCoalesced(context.Background(), "a")
+	if activity, _ = m.Activity("a"); !activity.Refreshing || activity.RefreshingSince == nil {
+		t.Fatalf("refresh not reported: %+v", activity)
+	}
+	<-done
+	if activity, _ = m.Activity("a"); activity.Refreshing {
+		t.Fatalf("finished refresh still reported: %+v", activity)
+	}
+}
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BackoffCurve shapes the cooldown escalation after repeated quota errors that name no reset time:
+// backoff level n waits Base*Multiplier^n, capped at Max, varied by up to ±Jitter of the delay.
+type BackoffCurve struct {
+	Base       time.Duration
+	Multiplier float64
+	Max        time.Duration
+	// Jitter is the fraction in [0, 1) the delay may vary by, spreading accounts that failed together.
+	Jitter float64
+}
+
+// DefaultBackoffCurve is the curve used for providers without their own: 1s doubling up to 30m.
+var DefaultBackoffCurve = BackoffCurve{Base: quotaBackoffBase, Multiplier: 2, Max: quotaBackoffMax}
+
+// Validate rejects curves that do not escalate or are out of range. Zero fields are allowed; they
+// inherit the default curve.
+func (c BackoffCurve) Validate() error {
+	switch {
+	case c.Base < 0:
+		return fmt.Errorf("base delay %s is negative", c.Base)
+	case c.Max < 0:
+		return fmt.Errorf("max delay %s is negative", c.Max)
+	case c.Multiplier != 0 && c.Multiplier < 1:
+		return fmt.Errorf("multiplier %v is below 1", c.Multiplier)
+	case c.Jitter < 0 || c.Jitter >= 1:
+		return fmt.Errorf("jitter %v is outside [0, 1)", c.Jitter)
+	case c.Base > 0 && c.Max > 0 && c.Max < c.Base:
+		return fmt.Errorf("max delay %s is below the base delay %s", c.Max, c.Base)
+	}
+	return nil
+}
+
+// withDefaults fills the zero fields of c from def.
+func (c BackoffCurve) withDefaults(def BackoffCurve) BackoffCurve {
+	if c.Base <= 0 {
+		c.Base = def.Base
+	}
+	if c.Multiplier <= 0 {
+		c.Multiplier = def.Multiplier
+	}
+	if c.Max <= 0 {
+		c.Max = def.Max
+	}
+	if c.Max < c.Base {
+		c.Max = c.Base
+	}
+	return c
+}
+
+// next returns the cooldown for the given backoff level and the level to store. The level stops
+// growing once the cap is reached.
+func (c BackoffCurve) next(prevLevel int) (time.Duration, int) {
+	if prevLevel < 0 {
+		prevLevel = 0
+	}
+	if quotaCooldownDisabled.Load() {
+		return 0, prevLevel
+	}
+	delay := float64(c.Base) * math.Pow(c.Multiplier, float64(prevLevel))
+	nextLevel := prevLevel + 1
+	if delay >= float64(c.Max) {
+		delay, nextLevel = float64(c.Max), prevLevel
+	}
+	if c.Jitter > 0 {
+		delay *= 1 + c.Jitter*(2*rand.Float64()-1)
+	}
+	cooldown := time.Duration(math.Min(delay, float64(c.Max)))
+	return max(cooldown, c.Base), nextLevel
+}
+
+// backoffCurves holds the default and per-provider backoff curves.
+type backoffCurves struct {
+	mu         sync.RWMutex
+	def        BackoffCurve
+	byProvider map[string]BackoffCurve
+}
+
+// SetBackoffCurves replaces the quota backoff curves. Zero fields of def inherit DefaultBackoffCurve
+// and zero fields of a provider curve inherit def. Invalid curves are rejected as a whole.
+func (m *Manager) SetBackoffCurves(def BackoffCurve, providers map[string]BackoffCurve) error {
+	if m == nil {
+		return nil
+	}
+	if err := def.Validate(); err != nil {
+		return fmt.Errorf("default backoff curve: %w", err)
+	}
+	def = def.withDefaults(DefaultBackoffCurve)
+	byProvider := make(map[string]BackoffCurve, len(providers))
+	for provider, curve := range providers {
+		if err := curve.Validate(); err != nil {
+			return fmt.Errorf("backoff curve of %s: %w", provider, err)
+		}
+		if curve.Jitter == 0 {
+			curve.Jitter = def.Jitter
+		}
+		if key := strings.ToLower(strings.TrimSpace(provider)); key != "" {
+			byProvider[key] = curve.withDefaults(def)
+		}
+	}
+	m.backoff.mu.Lock()
+	m.backoff.def, m.backoff.byProvider = def, byProvider
+	m.backoff.mu.Unlock()
+	return nil
+}
+
+// BackoffCurves returns the effective default curve and the curves of providers that have their own.
+func (m *Manager) BackoffCurves() (BackoffCurve, map[string]BackoffCurve) {
+	if m == nil {
+		return DefaultBackoffCurve, nil
+	}
+	m.backoff.mu.RLock()
+	defer m.backoff.mu.RUnlock()
+	providers := make(map[string]BackoffCurve, len(m.backoff.byProvider))
+	for provider, curve := range m.backoff.byProvider {
+		providers[provider] = curve
+	}
+	return m.backoff.curve(""), providers
+}
+
+// curve returns the backoff curve in effect for provider. Callers must hold b.mu.
+func (b *backoffCurves) curve(provider string) BackoffCurve {
+	if curve, ok := b.byProvider[strings.ToLower(provider)]; ok {
+		return curve
+	}
+	if b.def.Base <= 0 {
+		return DefaultBackoffCurve
+	}
+	return b.def
+}
+
+// backoffCurveFor returns the backoff curve in effect for provider.
+func (m *Manager) backoffCurveFor(provider string) BackoffCurve {
+	m.backoff.mu.RLock()
+	defer m.backoff.mu.RUnlock()
+	return m.backoff.curve(provider)
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBackoffCurveEscalatesAndCaps(t *testing.T) {
+	curve := BackoffCurve{Base: 10 * time.Second, Multiplier: 3, Max: time.Minute}
+	want := []struct {
+		cooldown time.Duration
+		level    int
+	}{{10 * time.Second, 1}, {30 * time.Second, 2}, {time.Minute, 2}}
+	level := 0
+	for i, step := range want {
+		cooldown, next := curve.next(level)
+		if cooldown != step.cooldown || next != step.level {
+			t.Fatalf("step %d: cooldown %s level %d, want %s level %d", i, cooldown, next, step.cooldown, step.level)
+		}
+		level = next
+	}
+
+	jittered := BackoffCurve{Base: 10 * time.Second, Multiplier: 2, Max: time.Hour, Jitter: 0.5}
+	for i := 0; i < 50; i++ {
+		if cooldown, _ := jittered.next(2); cooldown < 20*time.Second || cooldown > 60*time.Second {
+			t.Fatalf("jittered cooldown %s outside 40s +/- 50%%", cooldown)
+		}
+	}
+}
+
+func TestSetBackoffCurvesValidatesAndAppliesPerProvider(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	if err := m.SetBackoffCurves(BackoffCurve{Multiplier: 0.5}, nil); err == nil {
+		t.Fatal("multiplier below 1 accepted")
+	}
+	if err := m.SetBackoffCurves(BackoffCurve{}, map[string]BackoffCurve{"claude": {Jitter: 1}}); err == nil {
+		t.Fatal("jitter of 1 accepted")
+	}
+	if err := m.SetBackoffCurves(BackoffCurve{Max: 10 * time.Minute}, map[string]BackoffCurve{"Claude": {Base: time.Minute}}); err != nil {
+		t.Fatalf("SetBackoffCurves: %v", err)
+	}
+	def, providers := m.BackoffCurves()
+	if def != (BackoffCurve{Base: time.Second, Multiplier: 2, Max: 10 * time.Minute}) {
+		t.Fatalf("default curve = %+v", def)
+	}
+	if providers["claude"] != (BackoffCurve{Base: time.Minute, Multiplier: 2, Max: 10 * time.Minute}) {
+		t.Fatalf("claude curve = %+v", providers["claude"])
+	}
+
+	ctx := context.Background()
+	for _, auth := range []*Auth{{ID: "c", Provider: "claude"}, {ID: "g", Provider: "gemini"}} {
+		if _, err := m.Register(ctx, auth); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+		before := time.Now()
+		m.MarkResult(ctx, Result{AuthID: auth.ID, Provider: auth.Provider, Error: &Error{HTTPStatus: http.StatusTooManyRequests}})
+		got, _ := m.GetByID(auth.ID)
+		wait := got.Quota.NextRecoverAt.Sub(before)
+		want := time.Second
+		if auth.Provider == "claude" {
+			want = time.Minute
+		}
+		if wait < want || wait > want+time.Second || got.Quota.BackoffLevel != 1 {
+			t.Fatalf("%s: cooldown %s level %d, want %s", auth.Provider, wait, got.Quota.BackoffLevel, want)
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultBatchMaxSize = 16
+	defaultBatchMaxWait = 5 * time.Second
+)
+
+// BatchItem is one request dispatched as part of an upstream batch call.
+type BatchItem struct {
+	Request cliproxyexecutor.Request
+	Options cliproxyexecutor.Options
+}
+
+// BatchResult is the outcome of one batch item, in the same order as the submitted items.
+type BatchResult struct {
+	Response cliproxyexecutor.Response
+	Err      error
+}
+
+// BatchExecutor is an optional interface for provider executors that can send several
+// non-streaming requests in one upstream batch call. A returned error fails the whole batch;
+// per-item failures are reported through BatchResult.Err.
+type BatchExecutor interface {
+	ExecuteBatch(ctx context.Context, auth *Auth, items []BatchItem) ([]BatchResult, error)
+}
+
+// BatchPolicy configures request batching.
+type BatchPolicy struct {
+	Enabled bool
+	// MaxBatchSize flushes a batch as soon as it holds this many requests.
+	MaxBatchSize int
+	// MaxWait flushes a batch this long after its first request was queued.
+	MaxWait time.Duration
+	// Providers restricts batching to these providers; empty allows every provider whose executor supports it.
+	Providers []string
+}
+
+// BatchStats reports batching efficiency for one provider.
+type BatchStats struct {
+	Provider        string  `json:"provider"`
+	Batches         int64   `json:"batches"`
+	Requests        int64   `json:"requests"`
+	AvgBatchSize    float64 `json:"avg_batch_size"`
+	FailedBatches   int64   `json:"failed_batches"`
+	PartialFailures int64   `json:"partial_failures"`
+	FailedRequests  int64   `json:"failed_requests"`
+}
+
+// batchKey groups requests that can share an upstream call. Batches are keyed before account
+// selection, so scope separates requests whose selection differs (pin, reservation, client key).
+type batchKey struct {
+	provider string
+	model    string
+	scope    string
+}
+
+type batchWaiter struct {
+	item BatchItem
+	done chan BatchResult
+}
+
+// batchRunner selects one account for a flushed batch, sends it upstream and records the outcome.
+type batchRunner func(ctx context.Context, key batchKey, items []BatchItem) ([]BatchResult, error)
+
+// pendingBatch collects requests for one provider and model until it is flushed.
+type pendingBatch struct {
+	key     batchKey
+	run     batchRunner
+	ctx     context.Context
+	cancel  context.CancelFunc
+	waiters []*batchWaiter
+	timer   *time.Timer
+	// left counts waiters that gave up; the upstream call is cancelled once all of them did.
+	left    int
+	flushed bool
+}
+
+// requestBatcher groups batch-eligible requests per provider and model.
+type requestBatcher struct {
+	mu      sync.Mutex
+	policy  BatchPolicy
+	pending map[batchKey]*pendingBatch
+	stats   map[string]*BatchStats
+}
+
+// SetBatchPolicy replaces the request batching policy.
+func (m *Manager) SetBatchPolicy(policy BatchPolicy) {
+	if m == nil {
+		return
+	}
+	if policy.MaxBatchSize <= 0 {
+		policy.MaxBatchSize = defaultBatchMaxSize
+	}
+	if policy.MaxWait <= 0 {
+		policy.MaxWait = defaultBatchMaxWait
+	}
+	providers := make([]string, 0, len(policy.Providers))
+	for _, provider := range policy.Providers {
+		if p := strings.ToLower(strings.TrimSpace(provider)); p != "" {
+			providers = append(providers, p)
+		}
+	}
+	policy.Providers = providers
+	m.batcher.mu.Lock()
+	m.batcher.policy = policy
+	m.batcher.mu.Unlock()
+}
+
+// eligible reports whether the request opted into batching and the policy allows it for provider.
+func (b *requestBatcher) eligible(provider string, opts cliproxyexecutor.Options) bool {
+	if requested, _ := opts.Metadata[cliproxyexecutor.BatchMetadataKey].(bool); !requested || opts.Stream {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.policy.Enabled {
+		return false
+	}
+	if len(b.policy.Providers) == 0 {
+		return true
+	}
+	for _, p := range b.policy.Providers {
+		if p == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// submit queues the item and blocks until its batch completes or ctx is done.
+func (b *requestBatcher) submit(ctx context.Context, key batchKey, run batchRunner, item BatchItem) (cliproxyexecutor.Response, error) {
+	waiter := &batchWaiter{item: item, done: make(chan BatchResult, 1)}
+	b.mu.Lock()
+	if b.pending == nil {
+		b.pending = make(map[batchKey]*pendingBatch)
+	}
+	batch, ok := b.pending[key]
+	if !ok {
+		// The upstream call outlives any single client; it is cancelled only when every waiter left.
+		// Each waiter times the whole batch as queued, so the call does not report to the opener's clock.
+		runCtx, cancel := context.WithCancel(context.WithValue(context.WithoutCancel(ctx), upstreamClockKey{}, (*UpstreamClock)(nil)))
+		batch = &pendingBatch{key: key, run: run, ctx: runCtx, cancel: cancel}
+		b.pending[key] = batch
+		batch.timer = time.AfterFunc(b.policy.MaxWait, func() { b.flush(batch) })
+	}
+	batch.waiters = append(batch.waiters, waiter)
+	full := len(batch.waiters) >= b.policy.MaxBatchSize
+	b.mu.Unlock()
+	if full {
+		go b.flush(batch)
+	}
+
+	select {
+	case result := <-waiter.done:
+		return result.Response, result.Err
+	case <-ctx.Done():
+		b.mu.Lock()
+		batch.left++
+		if batch.left == len(batch.waiters) && batch.flushed {
+			batch.cancel()
+		}
+		b.mu.Unlock()
+		return cliproxyexecutor.Response{}, ctx.Err()
+	}
+}
+
+// flush detaches the batch and sends it upstream. It is safe to call more than once.
+func (b *requestBatcher) flush(batch *pendingBatch) {
+	b.mu.Lock()
+	if batch.flushed {
+		b.mu.Unlock()
+		return
+	}
+	batch.flushed = true
+	batch.timer.Stop()
+	if b.pending[batch.key] == batch {
+		delete(b.pending, batch.key)
+	}
+	waiters := batch.waiters
+	abandoned := batch.left == len(waiters)
+	b.mu.Unlock()
+	defer batch.cancel()
+	if abandoned {
+		return
+	}
+
+	items := make([]BatchItem, len(waiters))
+	for i, w := range waiters {
+		items[i] = w.item
+	}
+	results, err := batch.run(batch.ctx, batch.key, items)
+	if err == nil && len(results) != len(items) {
+		err = &Error{Code: "batch_mismatch", Message: "upstream batch returned an unexpected number of results", Retryable: true, HTTPStatus: http.StatusBadGateway}
+	}
+	failedItems := 0
+	for i, w := range waiters {
+		result := BatchResult{Err: err}
+		if err == nil {
+			result = results[i]
+		}
+		if result.Err != nil {
+			failedItems++
+		}
+		w.done <- result
+	}
+	b.recordStats(batch.key.provider, len(items), failedItems, err)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Warnf("batch of %d %s request(s) for model %s failed: %v", len(items), batch.key.provider, batch.key.model, err)
+	}
+}
+
+func (b *requestBatcher) recordStats(provider string, size, failedItems int, batchErr error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stats == nil {
+		b.stats = make(map[string]*BatchStats)
+	}
+	st, ok := b.stats[provider]
+	if !ok {
+		st = &BatchStats{Provider: provider}
+		b.stats[provider] = st
+	}
+	st.Batches++
+	st.Requests += int64(size)
+	st.FailedRequests += int64(failedItems)
+	switch {
+	case batchErr != nil:
+		st.FailedBatches++
+	case failedItems > 0:
+		st.PartialFailures++
+	}
+}
+
+// BatchStats returns batching efficiency counters per provider ordered by provider.
+func (m *Manager) BatchStats() []BatchStats {
+	if m == nil {
+		return nil
+	}
+	m.batcher.mu.Lock()
+	defer m.batcher.mu.Unlock()
+	out := make([]BatchStats, 0, len(m.batcher.stats))
+	for _, st := range m.batcher.stats {
+		entry := *st
+		if entry.Batches > 0 {
+			entry.AvgBatchSize = float64(entry.Requests) / float64(entry.Batches)
+		}
+		out = append(out, entry)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Provider < out[j].Provider })
+	return out
+}
+
+// executeBatched queues an eligible request into a batch for its provider and model. The account is
+// selected once per batch when it flushes, so concurrent requests share one upstream call. batched
+// is false when the request is not eligible and must be executed directly.
+func (m *Manager) executeBatched(ctx context.Context, provider string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (_ cliproxyexecutor.Response, _ error, batched bool) {
+	if _, ok := m.executorFor(provider).(BatchExecutor); !ok || !m.batcher.eligible(provider, opts) {
+		return cliproxyexecutor.Response{}, nil, false
+	}
+	key := batchKey{provider: provider, model: req.Model, scope: batchScope(opts)}
+	started := time.Now()
+	resp, err := m.batcher.submit(ctx, key, m.runBatch, BatchItem{Request: req, Options: opts})
+	UpstreamClockFrom(ctx).AddWait(time.Since(started))
+	return resp, err, true
+}
+
+// batchScope identifies the request options that change account selection.
+func batchScope(opts cliproxyexecutor.Options) string {
+	pinned, _ := pinnedAuth(opts)
+	clientKey, _ := opts.Metadata[cliproxyexecutor.ClientKeyMetadataKey].(string)
+	return pinned + "\x00" + reservationName(opts) + "\x00" + clientKey
+}
+
+// runBatch selects an account for the batch and sends it in one upstream call, recording a single
+// result for the call. Per-item failures are left to the callers, which retry them on their own.
+func (m *Manager) runBatch(ctx context.Context, key batchKey, items []BatchItem) ([]BatchResult, error) {
+	auth, executor, errPick := m.pickNext(ctx, key.provider, key.model, items[0].Options, make(map[string]struct{}))
+	if errPick != nil {
+		return nil, errPick
+	}
+	batchExec, ok := executor.(BatchExecutor)
+	if !ok {
+		// Give back the concurrency slot taken when the account was selected.
+		m.beginRequest(auth.ID)()
+		return nil, &Error{Code: "executor_not_found", Message: "executor does not support batching", HTTPStatus: http.StatusBadGateway}
+	}
+	auth = m.ensureFreshToken(ctx, auth)
+	execCtx := ctx
+	if rt := m.roundTripperFor(auth); rt != nil {
+		execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
+		execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
+	}
+	execCtx = m.withRateLimitObserver(execCtx, auth.ID, key.provider)
+	remapped := make([]BatchItem, len(items))
+	for i, item := range items {
+		remapped[i] = BatchItem{Request: remapRequestModel(auth, m.capabilities.request(auth, item.Request)), Options: item.Options}
+	}
+	model := m.capabilities.request(auth, cliproxyexecutor.Request{Model: key.model}).Model
+
+	started := time.Now()
+	finishReserved := m.beginRequest(auth.ID)
+	results, errExec := batchExec.ExecuteBatch(execCtx, auth, remapped)
+	finishReserved()
+	result := Result{AuthID: auth.ID, Provider: key.provider, Model: model, Success: errExec == nil, Latency: time.Since(started)}
+	if errExec != nil {
+		result.Error = resultErrorFrom(errExec)
+		result.RetryAfter = retryAfterFromError(errExec)
+		result.QuotaResetAt = quotaResetFromError(key.provider, errExec, time.Now())
+		result.RetryAfterAt = retryAfterHeaderFromError(errExec, time.Now())
+	}
+	m.MarkResult(execCtx, result)
+	for _, r := range results {
+		if r.Err == nil {
+			m.tokenCounts.record(auth.ID, payloadTokenUsage(r.Response.Payload))
+		}
+	}
+	return results, errExec
+}
+
+// withoutBatching returns opts with the batching opt-in removed.
+func withoutBatching(opts cliproxyexecutor.Options) cliproxyexecutor.Options {
+	if _, ok := opts.Metadata[cliproxyexecutor.BatchMetadataKey]; !ok {
+		return opts
+	}
+	metadata := make(map[string]any, len(opts.Metadata))
+	for k, v := range opts.Metadata {
+		if k != cliproxyexecutor.BatchMetadataKey {
+			metadata[k] = v
+		}
+	}
+	opts.Metadata = metadata
+	return opts
+}
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// batchingExecutor fails the second item of every batch and records batch sizes and direct calls.
+type batchingExecutor struct {
+	mu      sync.Mutex
+	batches []int
+	direct  int
+}
+
+func (e *batchingExecutor) Identifier() string { return "batchy" }
+
+func (e *batchingExecutor) Execute(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	e.mu.Lock()
+	e.direct++
+	e.mu.Unlock()
+	return cliproxyexecutor.Response{Payload: []byte("direct")}, nil
+}
+
+func (e *batchingExecutor) ExecuteStream(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (<-chan cliproxyexecutor.StreamChunk, error) {
+	return nil, errors.New("not supported")
+}
+
+func (e *batchingExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) { return auth, nil }
+
+func (e *batchingExecutor) CountTokens(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{}, nil
+}
+
+func (e *batchingExecutor) ExecuteBatch(_ context.Context, _ *Auth, items []BatchItem) ([]BatchResult, error) {
+	e.mu.Lock()
+	e.batches = append(e.batches, len(items))
+	e.mu.Unlock()
+	results := make([]BatchResult, len(items))
+	for i, item := range items {
+		results[i].Response.Payload = item.Request.Payload
+	}
+	if len(results) > 1 {
+		results[1].Err = &Error{HTTPStatus: 500, Message: "item failed"}
+	}
+	return results, nil
+}
+
+func TestExecuteBatchesOptedInRequests(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	exec := &batchingExecutor{}
+	m.RegisterExecutor(exec)
+	m.SetBatchPolicy(BatchPolicy{Enabled: true, MaxBatchSize: 3, MaxWait: time.Minute})
+	for _, id := range []string{"a", "b"} {
+		if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: "batchy"}); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+
+	// Requests are batched before account selection, so they share a batch across accounts.
+	opts := cliproxyexecutor.Options{Metadata: map[string]any{cliproxyexecutor.BatchMetadataKey: true}}
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	payloads := make([]string, 3)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := m.Execute(context.Background(), []string{"batchy"}, cliproxyexecutor.Request{Payload: []byte{byte('0' + i)}}, opts)
+			errs[i], payloads[i] = err, string(resp.Payload)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range errs {
+		if errs[i] != nil || payloads[i] == "" {
+			t.Fatalf("request %d = %q, %v; the failed item should be retried on its own", i, payloads[i], errs[i])
+		}
+	}
+	if len(exec.batches) != 1 || exec.batches[0] != 3 || exec.direct != 1 {
+		t.Fatalf("expected one batch of 3 and one direct retry, got batches=%v direct=%d", exec.batches, exec.direct)
+	}
+	if requests := m.RequestCounts("a").Requests + m.RequestCounts("b").Requests; requests != 2 {
+		t.Fatalf("recorded %d results, want one for the batch and one for the retry", requests)
+	}
+	stats := m.BatchStats()
+	if len(stats) != 1 || stats[0].Requests != 3 || stats[0].PartialFailures != 1 || stats[0].AvgBatchSize != 3 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	// A single opted-in request is flushed by the max wait; non-opted requests go direct.
+	m.SetBatchPolicy(BatchPolicy{Enabled: true, MaxBatchSize: 3, MaxWait: 10 * time.Millisecond})
+	ctx, clock := WithUpstreamClock(context.Background())
+	resp, err := m.Execute(ctx, []string{"batchy"}, cliproxyexecutor.Request{Payload: []byte("x")}, opts)
+	if err != nil || string(resp.Payload) != "x" {
+		t.Fatalf("expected batched response, got %q, %v", resp.Payload, err)
+	}
+	if clock.Waited() < 10*time.Millisecond {
+		t.Fatalf("batch wait of %s not reported apart from the overhead", clock.Waited())
+	}
+	if _, err = m.Execute(context.Background(), []string{"batchy"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{}); err != nil || exec.direct != 2 {
+		t.Fatalf("expected direct execution for non-batched request, direct=%d err=%v", exec.direct, err)
+	}
+}
+
+func TestBatchScopeSeparatesPinnedRequests(t *testing.T) {
+	plain := cliproxyexecutor.Options{}
+	pinned := cliproxyexecutor.Options{Metadata: map[string]any{cliproxyexecutor.PinnedAuthMetadataKey: "a"}}
+	reserved := cliproxyexecutor.Options{Metadata: map[string]any{cliproxyexecutor.ReservationMetadataKey: "a"}}
+	if batchScope(plain) == batchScope(pinned) || batchScope(pinned) == batchScope(reserved) {
+		t.Fatal("requests with different account selection must not share a batch")
+	}
+}
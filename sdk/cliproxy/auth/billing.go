@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// billingWildcardProvider keys patterns that apply to every provider.
+const billingWildcardProvider = "*"
+
+// defaultBillingSuspensionPatterns lists upstream error fragments known to signal billing-level account problems.
+var defaultBillingSuspensionPatterns = map[string][]string{
+	billingWildcardProvider: {
+		"billing_not_active",
+		"billing is not active",
+		"account_deactivated",
+		"account has been deactivated",
+		"account has been suspended",
+		"account is suspended",
+	},
+	"claude": {
+		"credit balance is too low",
+	},
+	"codex": {
+		"billing_hard_limit_reached",
+	},
+	"gemini": {
+		"billing_disabled",
+	},
+	"gemini-cli": {
+		"billing_disabled",
+	},
+}
+
+// BillingSuspensionPolicy controls automatic suspension of accounts whose upstream reports inactive billing.
+type BillingSuspensionPolicy struct {
+	// Enabled toggles detection; when false billing errors follow the regular failure handling.
+	Enabled bool
+	// Patterns maps provider keys to case-insensitive message fragments, extending the built-in defaults.
+	// The "*" key applies to all providers.
+	Patterns map[string][]string
+}
+
+// SetBillingSuspensionPolicy replaces the billing suspension policy used by MarkResult.
+func (m *Manager) SetBillingSuspensionPolicy(policy BillingSuspensionPolicy) {
+	if m == nil {
+		return
+	}
+	patterns := make(map[string][]string)
+	if policy.Enabled {
+		for provider, list := range defaultBillingSuspensionPatterns {
+			patterns[provider] = append(patterns[provider], list...)
+		}
+		for provider, list := range policy.Patterns {
+			key := strings.ToLower(strings.TrimSpace(provider))
+			if key == "" {
+				continue
+			}
+			for _, pattern := range list {
+				if p := strings.ToLower(strings.TrimSpace(pattern)); p != "" {
+					patterns[key] = append(patterns[key], p)
+				}
+			}
+		}
+	}
+	m.mu.Lock()
+	m.billingPatterns = patterns
+	m.mu.Unlock()
+}
+
+// isBillingSuspension reports whether err matches a billing pattern for provider. Callers must hold m.mu.
+func (m *Manager) isBillingSuspension(provider string, err *Error) bool {
+	if err == nil || len(m.billingPatterns) == 0 {
+		return false
+	}
+	text := strings.ToLower(err.Code + " " + err.Message)
+	if strings.TrimSpace(text) == "" {
+		return false
+	}
+	for _, key := range []string{strings.ToLower(provider), billingWildcardProvider} {
+		for _, pattern := range m.billingPatterns[key] {
+			if strings.Contains(text, pattern) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// applyBillingSuspension moves the auth into the billing_suspended state and reports whether it transitioned.
+func applyBillingSuspension(auth *Auth, resultErr *Error, now time.Time) bool {
+	if auth == nil {
+		return false
+	}
+	transitioned := auth.Status != StatusBillingSuspended
+	auth.Status = StatusBillingSuspended
+	auth.Unavailable = true
+	auth.NextRetryAfter = time.Time{}
+	auth.UpdatedAt = now
+	if resultErr != nil {
+		auth.LastError = cloneError(resultErr)
+		auth.StatusMessage = resultErr.Message
+	}
+	if auth.StatusMessage == "" {
+		auth.StatusMessage = "billing suspended"
+	}
+	return transitioned
+}
+
+// ClearBillingSuspension returns a billing suspended account to service once the operator fixed its
+// billing. It reports whether the account exists and whether it was suspended; accounts that were not
+// are returned unchanged.
+func (m *Manager) ClearBillingSuspension(ctx context.Context, id string) (*Auth, bool, bool) {
+	if m == nil {
+		return nil, false, false
+	}
+	now := time.Now()
+	m.mu.Lock()
+	auth, ok := m.auths[id]
+	if !ok || auth == nil {
+		m.mu.Unlock()
+		return nil, false, false
+	}
+	if auth.Status != StatusBillingSuspended {
+		out := auth.Clone()
+		m.mu.Unlock()
+		return out, true, false
+	}
+	auth.Status = StatusActive
+	auth.StatusMessage = ""
+	auth.LastError = nil
+	auth.Unavailable = false
+	auth.NextRetryAfter = time.Time{}
+	// Model cooldowns that predate the suspension still apply.
+	updateAggregatedAvailability(auth, now)
+	if hasModelError(auth, now) {
+		auth.Status = StatusError
+	}
+	auth.UpdatedAt = now
+	m.recordRuntimeStateLocked(auth)
+	_ = m.persist(ctx, auth)
+	out := auth.Clone()
+	m.mu.Unlock()
+
+	log.Infof("auth %s (%s): billing suspension cleared manually", out.ID, out.Provider)
+	m.hook.OnAuthUpdated(ctx, out.Clone())
+	return out, true, true
+}
@@ -0,0 +1,21 @@
+package auth
+
+import "testing"
+
+func TestAuthBillingHeaders(t *testing.T) {
+	auth := &Auth{
+		Attributes: map[string]string{
+			BillingHeaderAttrPrefix + "X-Cost-Center": "team-a",
+			"header:X-Custom":                         "custom",
+			BillingHeaderAttrPrefix + "X-Empty":       " ",
+		},
+		Metadata: map[string]any{"billing_headers": map[string]any{"X-Project": "proj-1", "X-Bad": 1}},
+	}
+	got := auth.BillingHeaders()
+	if len(got) != 2 || got["X-Cost-Center"] != "team-a" || got["X-Project"] != "proj-1" {
+		t.Fatalf("unexpected billing headers %v", got)
+	}
+	if (&Auth{Attributes: map[string]string{"header:X-Custom": "custom"}}).BillingHeaders() != nil {
+		t.Fatalf("custom headers must not count as billing headers")
+	}
+}
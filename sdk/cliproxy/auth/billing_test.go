@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMarkResult_BillingErrorSuspendsAuth(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetBillingSuspensionPolicy(BillingSuspensionPolicy{
+		Enabled:  true,
+		Patterns: map[string][]string{"claude": {"Payment Method Declined"}},
+	})
+	if _, err := m.Register(context.Background(), &Auth{ID: "a", Provider: "claude"}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	m.MarkResult(context.Background(), Result{
+		AuthID:   "a",
+		Provider: "claude",
+		Model:    "claude-sonnet",
+		Error:    &Error{HTTPStatus: 400, Message: "payment method declined for organization"},
+	})
+
+	auth, ok := m.GetByID("a")
+	if !ok {
+		t.Fatalf("auth not found")
+	}
+	if auth.Status != StatusBillingSuspended {
+		t.Fatalf("expected status %s, got %s", StatusBillingSuspended, auth.Status)
+	}
+	if blocked, reason, _ := isAuthBlockedForModel(auth, "claude-sonnet", auth.UpdatedAt); !blocked || reason != blockReasonDisabled {
+		t.Fatalf("expected suspended auth to be blocked, got blocked=%v reason=%v", blocked, reason)
+	}
+}
+
+func TestMarkResult_BillingDetectionDisabled(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	if _, err := m.Register(context.Background(), &Auth{ID: "a", Provider: "codex"}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	m.MarkResult(context.Background(), Result{
+		AuthID:   "a",
+		Provider: "codex",
+		Error:    &Error{HTTPStatus: 403, Message: "account_deactivated"},
+	})
+
+	auth, _ := m.GetByID("a")
+	if auth.Status != StatusError {
+		t.Fatalf("expected status %s without policy, got %s", StatusError, auth.Status)
+	}
+}
+
+func TestClearBillingSuspensionRestoresAuth(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetBillingSuspensionPolicy(BillingSuspensionPolicy{Enabled: true})
+	ctx := context.Background()
+	if _, err := m.Register(ctx, &Auth{ID: "a", Provider: "claude", Status: StatusActive}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if _, ok, changed := m.ClearBillingSuspension(ctx, "a"); !ok || changed {
+		t.Fatalf("active account: ok=%t changed=%t", ok, changed)
+	}
+	if _, ok, _ := m.ClearBillingSuspension(ctx, "missing"); ok {
+		t.Fatal("unknown account reported")
+	}
+
+	m.MarkResult(ctx, Result{AuthID: "a", Provider: "claude", Model: "claude-sonnet", Error: &Error{HTTPStatus: 400, Message: "Your credit balance is too low"}})
+	if auth, _ := m.GetByID("a"); auth.Status != StatusBillingSuspended {
+		t.Fatalf("status = %s, want billing suspended", auth.Status)
+	}
+	auth, ok, changed := m.ClearBillingSuspension(ctx, "a")
+	if !ok || !changed || auth.Status != StatusActive || auth.Unavailable || auth.LastError != nil || auth.StatusMessage != "" {
+		t.Fatalf("suspension not cleared: %+v", auth)
+	}
+	if blocked, _, _ := isAuthBlockedForModel(auth, "claude-sonnet", auth.UpdatedAt); blocked {
+		t.Fatal("cleared account is still blocked")
+	}
+}
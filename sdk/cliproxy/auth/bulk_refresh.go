@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultBulkRefreshWorkers bounds concurrent token refreshes of a bulk refresh.
+	defaultBulkRefreshWorkers = 4
+	maxBulkRefreshWorkers     = 16
+)
+
+// Bulk refresh outcomes.
+const (
+	BulkRefreshRefreshed = "refreshed"
+	BulkRefreshFailed    = "failed"
+	BulkRefreshSkipped   = "skipped"
+)
+
+// BulkRefreshResult is the outcome of refreshing one account as part of a bulk refresh.
+type BulkRefreshResult struct {
+	ID       string     `json:"id"`
+	Provider string     `json:"provider"`
+	Result   string     `json:"result"`
+	Reason   string     `json:"reason,omitempty"`
+	Expires  *time.Time `json:"expires_at,omitempty"`
+}
+
+// RefreshTokens refreshes the token of every listed account with at most workers refreshes in flight.
+// Accounts refreshed within minInterval, or inside a refresh failure backoff, are skipped so the
+// token endpoint is not hammered. Refreshes already running are joined instead of repeated. Results
+// follow the order of ids.
+func (m *Manager) RefreshTokens(ctx context.Context, ids []string, minInterval time.Duration, workers int) []BulkRefreshResult {
+	if m == nil {
+		return nil
+	}
+	if workers <= 0 {
+		workers = defaultBulkRefreshWorkers
+	}
+	workers = min(workers, maxBulkRefreshWorkers)
+	results := make([]BulkRefreshResult, len(ids))
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				results[i] = BulkRefreshResult{ID: id, Result: BulkRefreshSkipped, Reason: "request cancelled"}
+				return
+			}
+			defer func() { <-sem }()
+			results[i] = m.refreshOne(ctx, id, minInterval)
+		}(i, id)
+	}
+	wg.Wait()
+	return results
+}
+
+func (m *Manager) refreshOne(ctx context.Context, id string, minInterval time.Duration) BulkRefreshResult {
+	auth, ok := m.GetByID(id)
+	if !ok {
+		return BulkRefreshResult{ID: id, Result: BulkRefreshFailed, Reason: "account not found"}
+	}
+	result := BulkRefreshResult{ID: id, Provider: auth.Provider, Result: BulkRefreshSkipped}
+	now := time.Now()
+	switch typ, _ := auth.AccountInfo(); {
+	case auth.Disabled:
+		result.Reason = "account disabled"
+		return result
+	case typ == "api_key":
+		result.Reason = "api key accounts have no token to refresh"
+		return result
+	case m.executorFor(auth.Provider) == nil:
+		result.Result, result.Reason = BulkRefreshFailed, "no executor registered for provider"
+		return result
+	case minInterval > 0 && !auth.LastRefreshedAt.IsZero() && now.Sub(auth.LastRefreshedAt) < minInterval:
+		result.Reason = "refreshed " + now.Sub(auth.LastRefreshedAt).Round(time.Second).String() + " ago"
+		return result
+	}
+
+	done, running := m.refreshInFlight(id)
+	if !running {
+		if !m.markRefreshPending(id, now) {
+			result.Reason = "refresh backoff active"
+			return result
+		}
+		done = m.refreshCoalesced(context.WithoutCancel(ctx), id)
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+		result.Reason = "request cancelled; refresh continues in the background"
+		return result
+	}
+
+	current, ok := m.GetByID(id)
+	if !ok {
+		result.Result, result.Reason = BulkRefreshFailed, "account removed during refresh"
+		return result
+	}
+	if current.LastRefreshedAt.Before(now) {
+		result.Result, result.Reason = BulkRefreshFailed, "refresh did not succeed"
+		if current.LastError != nil && current.LastError.Message != "" {
+			result.Reason = current.LastError.Message
+		}
+		return result
+	}
+	result.Result = BulkRefreshRefreshed
+	if expiry, hasExpiry := current.ExpirationTime(); hasExpiry {
+		result.Expires = &expiry
+	}
+	return result
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRefreshTokensBoundedAndRespectsMinInterval(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	exec := &slowRefreshExecutor{}
+	m.RegisterExecutor(exec)
+	ids := []string{"a", "b", "c", "recent", "key"}
+	for _, id := range ids {
+		auth := &Auth{ID: id, Provider: "eager", Status: StatusActive, Metadata: map[string]any{"access_token": "stale"}}
+		switch id {
+		case "recent":
+			auth.LastRefreshedAt = time.Now().Add(-time.Minute)
+		case "key":
+			auth.Attributes = map[string]string{"api_key": "sk-test"}
+		}
+		if _, err := m.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+
+	started := time.Now()
+	results := m.RefreshTokens(context.Background(), ids, 5*time.Minute, 1)
+	want := []string{BulkRefreshRefreshed, BulkRefreshRefreshed, BulkRefreshRefreshed, BulkRefreshSkipped, BulkRefreshSkipped}
+	for i, result := range results {
+		if result.ID != ids[i] || result.Result != want[i] {
+			t.Fatalf("result %d = %+v, want %s", i, result, want[i])
+		}
+	}
+	if got := exec.refreshes.Load(); got != 3 {
+		t.Fatalf("expected 3 refreshes, got %d", got)
+	}
+	// A single worker runs the three 20ms refreshes one after another.
+	if elapsed := time.Since(started); elapsed < 60*time.Millisecond {
+		t.Fatalf("expected refreshes to be serialised by the worker pool, took %s", elapsed)
+	}
+	if results[0].Expires == nil {
+		t.Fatalf("expected refreshed accounts to report their new expiry")
+	}
+
+	// Immediately repeating the bulk refresh skips everything within the min interval.
+	for _, result := range m.RefreshTokens(context.Background(), ids[:3], 5*time.Minute, 4) {
+		if result.Result != BulkRefreshSkipped {
+			t.Fatalf("expected skip within min interval, got %+v", result)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// capacityWindow bounds how far back peak demand and saturation events are tracked.
+const capacityWindow = 24 * time.Hour
+
+// ProviderCapacity summarises the demand a provider saw over the capacity window. A saturation event
+// is a request that found no account able to serve it.
+type ProviderCapacity struct {
+	Provider string `json:"provider"`
+	// PeakDemandRPM is the highest number of requests, served or rejected, within one minute.
+	PeakDemandRPM int `json:"peak_demand_rpm"`
+	// PeakServedRPM is how many requests of the peak minute found an account.
+	PeakServedRPM    int        `json:"peak_served_rpm"`
+	PeakAt           *time.Time `json:"peak_at,omitempty"`
+	SaturationEvents int        `json:"saturation_events"`
+	SaturatedMinutes int        `json:"saturated_minutes"`
+}
+
+type capacityMinute struct {
+	start    time.Time
+	served   int
+	rejected int
+}
+
+// capacityTracker keeps per-minute request counts per provider for the capacity window.
+type capacityTracker struct {
+	mu      sync.Mutex
+	minutes map[string][]capacityMinute
+}
+
+func (t *capacityTracker) record(provider string, rejected bool, now time.Time) {
+	if provider == "" {
+		return
+	}
+	start := now.Truncate(time.Minute)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.minutes == nil {
+		t.minutes = make(map[string][]capacityMinute)
+	}
+	list := pruneCapacityMinutes(t.minutes[provider], now)
+	if n := len(list); n == 0 || !list[n-1].start.Equal(start) {
+		list = append(list, capacityMinute{start: start})
+	}
+	if rejected {
+		list[len(list)-1].rejected++
+	} else {
+		list[len(list)-1].served++
+	}
+	t.minutes[provider] = list
+}
+
+func pruneCapacityMinutes(list []capacityMinute, now time.Time) []capacityMinute {
+	cutoff := now.Add(-capacityWindow)
+	idx := sort.Search(len(list), func(i int) bool { return list[i].start.After(cutoff) })
+	if idx == 0 {
+		return list
+	}
+	return append(list[:0], list[idx:]...)
+}
+
+// isSaturationError reports whether err means no account could take the request.
+func isSaturationError(err error) bool {
+	var cooldown *modelCooldownError
+	if errors.As(err, &cooldown) {
+		return true
+	}
+	var authErr *Error
+	return errors.As(err, &authErr) && (authErr.Code == "auth_not_found" || authErr.Code == "auth_unavailable" || authErr.Code == "account_concurrency_limit")
+}
+
+// ProviderCapacity returns the peak demand and saturation events per provider over the last day.
+func (m *Manager) ProviderCapacity() map[string]ProviderCapacity {
+	if m == nil {
+		return nil
+	}
+	now := time.Now()
+	t := &m.capacity
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make(map[string]ProviderCapacity, len(t.minutes))
+	for provider, list := range t.minutes {
+		list = pruneCapacityMinutes(list, now)
+		t.minutes[provider] = list
+		if len(list) == 0 {
+			delete(t.minutes, provider)
+			continue
+		}
+		capacity := ProviderCapacity{Provider: provider}
+		for _, minute := range list {
+			if demand := minute.served + minute.rejected; demand > capacity.PeakDemandRPM {
+				start := minute.start
+				capacity.PeakDemandRPM, capacity.PeakServedRPM, capacity.PeakAt = demand, minute.served, &start
+			}
+			if minute.rejected > 0 {
+				capacity.SaturationEvents += minute.rejected
+				capacity.SaturatedMinutes++
+			}
+		}
+		out[provider] = capacity
+	}
+	return out
+}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestPickNextRecordsProviderCapacity(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&batchingExecutor{})
+	if _, err := m.Register(context.Background(), &Auth{ID: "a", Provider: "batchy"}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, _, err := m.pickNext(context.Background(), "batchy", "", cliproxyexecutor.Options{}, map[string]struct{}{}); err != nil {
+			t.Fatalf("pickNext: %v", err)
+		}
+	}
+	// Retries are not new demand.
+	_, _, _ = m.pickNext(context.Background(), "batchy", "", cliproxyexecutor.Options{}, map[string]struct{}{"a": {}})
+
+	m.mu.Lock()
+	m.auths["a"].Unavailable = true
+	m.auths["a"].Quota.Exceeded = true
+	m.auths["a"].NextRetryAfter = time.Now().Add(time.Minute)
+	m.mu.Unlock()
+	if _, _, err := m.pickNext(context.Background(), "batchy", "", cliproxyexecutor.Options{}, map[string]struct{}{}); err == nil {
+		t.Fatalf("expected the cooling account to reject the request")
+	}
+
+	capacity := m.ProviderCapacity()["batchy"]
+	if capacity.SaturationEvents != 1 || capacity.SaturatedMinutes != 1 {
+		t.Fatalf("unexpected saturation %+v", capacity)
+	}
+	if capacity.PeakDemandRPM == 0 || capacity.PeakAt == nil {
+		t.Fatalf("expected a recorded peak, got %+v", capacity)
+	}
+}
+
+func TestCapacityTrackerPeakMinute(t *testing.T) {
+	m := &Manager{}
+	tracker := &m.capacity
+	base := time.Now().Truncate(time.Minute).Add(-10 * time.Minute)
+	// Minutes older than the window are pruned.
+	for i := 0; i < 9; i++ {
+		tracker.record("p", false, base.Add(-2*capacityWindow))
+	}
+	for i := 0; i < 2; i++ {
+		tracker.record("p", false, base)
+	}
+	for i := 0; i < 3; i++ {
+		tracker.record("p", false, base.Add(time.Minute))
+	}
+	tracker.record("p", true, base.Add(time.Minute+time.Second))
+
+	capacity := m.ProviderCapacity()["p"]
+	if capacity.PeakDemandRPM != 4 || capacity.PeakServedRPM != 3 || !capacity.PeakAt.Equal(base.Add(time.Minute)) {
+		t.Fatalf("unexpected peak %+v", capacity)
+	}
+}
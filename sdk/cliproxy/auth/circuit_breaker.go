@@ -0,0 +1,373 @@
+package auth
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a provider circuit breaker.
+type CircuitState string
+
+const (
+	// CircuitClosed lets all requests through.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen rejects requests until the open duration elapses.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen admits a limited number of probe requests to test recovery.
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// CircuitBreakerSettings tunes a provider circuit breaker. Zero values fall back to defaults.
+type CircuitBreakerSettings struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit.
+	FailureThreshold int
+	// OpenDuration is how long the circuit stays open before probing.
+	OpenDuration time.Duration
+	// ProbeRequests is the number of trial requests admitted while half-open.
+	ProbeRequests int
+	// SuccessThreshold is the number of successful probes required to close the circuit.
+	SuccessThreshold int
+	// ProbeInterval is the minimum spacing between probe requests.
+	ProbeInterval time.Duration
+	// Window restarts the failure count when the previous failure is older than this; zero keeps
+	// failures counting until a success.
+	Window time.Duration
+}
+
+// CircuitBreakerPolicy configures circuit breaking for all providers.
+type CircuitBreakerPolicy struct {
+	Enabled   bool
+	Defaults  CircuitBreakerSettings
+	Providers map[string]CircuitBreakerSettings
+}
+
+func (s CircuitBreakerSettings) withDefaults() CircuitBreakerSettings {
+	if s.FailureThreshold <= 0 {
+		s.FailureThreshold = 5
+	}
+	if s.OpenDuration <= 0 {
+		s.OpenDuration = 30 * time.Second
+	}
+	if s.ProbeRequests <= 0 {
+		s.ProbeRequests = 1
+	}
+	if s.SuccessThreshold <= 0 || s.SuccessThreshold > s.ProbeRequests {
+		s.SuccessThreshold = s.ProbeRequests
+	}
+	if s.ProbeInterval < 0 {
+		s.ProbeInterval = 0
+	}
+	if s.Window < 0 {
+		s.Window = 0
+	}
+	return s
+}
+
+// CircuitBreakerStatus is a point-in-time view of a provider breaker.
+type CircuitBreakerStatus struct {
+	Provider            string       `json:"provider"`
+	State               CircuitState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	OpenedAt            *time.Time   `json:"opened_at,omitempty"`
+	NextProbeAt         *time.Time   `json:"next_probe_at,omitempty"`
+	Probe               *ProbeStatus `json:"probe,omitempty"`
+	// Reason is set when the circuit was opened for a cause other than consecutive failures.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ProbeStatus reports half-open probe progress.
+type ProbeStatus struct {
+	Sent             int `json:"sent"`
+	Successes        int `json:"successes"`
+	Failures         int `json:"failures"`
+	Requests         int `json:"requests"`
+	SuccessThreshold int `json:"success_threshold"`
+}
+
+// circuitBreaker implements the closed -> open -> half-open state machine for one provider.
+type circuitBreaker struct {
+	settings            CircuitBreakerSettings
+	state               CircuitState
+	consecutiveFailures int
+	lastFailureAt       time.Time
+	openedAt            time.Time
+	lastProbeAt         time.Time
+	probesSent          int
+	probeSuccesses      int
+	probeFailures       int
+}
+
+func newCircuitBreaker(settings CircuitBreakerSettings) *circuitBreaker {
+	return &circuitBreaker{settings: settings.withDefaults(), state: CircuitClosed}
+}
+
+// allow reports whether a request may be sent now, reserving a probe slot when half-open.
+func (b *circuitBreaker) allow(now time.Time) bool {
+	switch b.state {
+	case CircuitOpen:
+		if now.Before(b.openedAt.Add(b.settings.OpenDuration)) {
+			return false
+		}
+		b.state = CircuitHalfOpen
+		b.probesSent, b.probeSuccesses, b.probeFailures = 0, 0, 0
+		b.lastProbeAt = time.Time{}
+		fallthrough
+	case CircuitHalfOpen:
+		if b.probesSent >= b.settings.ProbeRequests {
+			// Probes that never reported an outcome must not wedge the breaker half-open.
+			if now.Before(b.lastProbeAt.Add(b.settings.OpenDuration)) {
+				return false
+			}
+			b.probesSent, b.probeSuccesses, b.probeFailures = 0, 0, 0
+		}
+		if !b.lastProbeAt.IsZero() && now.Before(b.lastProbeAt.Add(b.settings.ProbeInterval)) {
+			return false
+		}
+		b.probesSent++
+		b.lastProbeAt = now
+		return true
+	default:
+		return true
+	}
+}
+
+// record feeds a request outcome into the state machine.
+func (b *circuitBreaker) record(success bool, now time.Time) {
+	switch b.state {
+	case CircuitHalfOpen:
+		if success {
+			b.probeSuccesses++
+			if b.probeSuccesses >= b.settings.SuccessThreshold {
+				b.close()
+			}
+			return
+		}
+		b.probeFailures++
+		if b.settings.ProbeRequests-b.probeFailures < b.settings.SuccessThreshold {
+			b.open(now)
+		}
+	case CircuitOpen:
+		// Late results from requests admitted before the circuit opened do not change state.
+	default:
+		if success {
+			b.consecutiveFailures = 0
+			return
+		}
+		if b.settings.Window > 0 && now.Sub(b.lastFailureAt) > b.settings.Window {
+			b.consecutiveFailures = 0
+		}
+		b.lastFailureAt = now
+		b.consecutiveFailures++
+		if b.consecutiveFailures >= b.settings.FailureThreshold {
+			b.open(now)
+		}
+	}
+}
+
+func (b *circuitBreaker) open(now time.Time) {
+	b.state = CircuitOpen
+	b.openedAt = now
+	b.probesSent, b.probeSuccesses, b.probeFailures = 0, 0, 0
+}
+
+func (b *circuitBreaker) close() {
+	b.state = CircuitClosed
+	b.consecutiveFailures = 0
+	b.openedAt = time.Time{}
+	b.probesSent, b.probeSuccesses, b.probeFailures = 0, 0, 0
+}
+
+func (b *circuitBreaker) status(provider string) CircuitBreakerStatus {
+	st := CircuitBreakerStatus{Provider: provider, State: b.state, ConsecutiveFailures: b.consecutiveFailures}
+	if !b.openedAt.IsZero() {
+		opened := b.openedAt
+		st.OpenedAt = &opened
+	}
+	switch b.state {
+	case CircuitOpen:
+		next := b.openedAt.Add(b.settings.OpenDuration)
+		st.NextProbeAt = &next
+	case CircuitHalfOpen:
+		if b.probesSent < b.settings.ProbeRequests {
+			next := b.lastProbeAt.Add(b.settings.ProbeInterval)
+			if b.lastProbeAt.IsZero() {
+				next = b.openedAt.Add(b.settings.OpenDuration)
+			}
+			st.NextProbeAt = &next
+		}
+		st.Probe = &ProbeStatus{
+			Sent:             b.probesSent,
+			Successes:        b.probeSuccesses,
+			Failures:         b.probeFailures,
+			Requests:         b.settings.ProbeRequests,
+			SuccessThreshold: b.settings.SuccessThreshold,
+		}
+	}
+	return st
+}
+
+// circuitBreakers holds the breaker of every provider seen since the policy was applied.
+type circuitBreakers struct {
+	mu       sync.Mutex
+	policy   CircuitBreakerPolicy
+	breakers map[string]*circuitBreaker
+	// holds keeps providers closed to traffic until the given time, independent of the policy.
+	// Provider-wide maintenance uses it.
+	holds map[string]time.Time
+}
+
+func (c *circuitBreakers) settingsFor(provider string) CircuitBreakerSettings {
+	if s, ok := c.policy.Providers[provider]; ok {
+		return s
+	}
+	return c.policy.Defaults
+}
+
+func (c *circuitBreakers) breaker(provider string) *circuitBreaker {
+	if c.breakers == nil {
+		c.breakers = make(map[string]*circuitBreaker)
+	}
+	b, ok := c.breakers[provider]
+	if !ok {
+		b = newCircuitBreaker(c.settingsFor(provider))
+		c.breakers[provider] = b
+	}
+	return b
+}
+
+func (c *circuitBreakers) allow(provider string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if until, ok := c.holds[provider]; ok {
+		if now.Before(until) {
+			return false
+		}
+		delete(c.holds, provider)
+	}
+	if !c.policy.Enabled {
+		return true
+	}
+	return c.breaker(provider).allow(now)
+}
+
+// hold opens the provider circuit until now+d regardless of the breaker policy.
+func (c *circuitBreakers) hold(provider string, d time.Duration, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if provider == "" || d <= 0 {
+		return
+	}
+	if c.holds == nil {
+		c.holds = make(map[string]time.Time)
+	}
+	c.holds[provider] = now.Add(d)
+}
+
+func (c *circuitBreakers) record(provider string, result Result, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.policy.Enabled || provider == "" {
+		return
+	}
+	c.breaker(provider).record(!isBreakerFailure(result), now)
+}
+
+// isBreakerFailure reports whether the result signals provider-side trouble rather than a client or account error.
+func isBreakerFailure(result Result) bool {
+	if result.Success {
+		return false
+	}
+	switch code := statusCodeFromResult(result.Error); {
+	case code == 0, code == http.StatusRequestTimeout:
+		return true
+	case code >= http.StatusInternalServerError:
+		return true
+	default:
+		return false
+	}
+}
+
+// SetCircuitBreakerPolicy replaces the circuit breaker configuration. Existing breakers keep their
+// state and pick up the new settings; disabling the policy discards them.
+func (m *Manager) SetCircuitBreakerPolicy(policy CircuitBreakerPolicy) {
+	if m == nil {
+		return
+	}
+	normalized := CircuitBreakerPolicy{Enabled: policy.Enabled, Defaults: policy.Defaults}
+	if len(policy.Providers) > 0 {
+		normalized.Providers = make(map[string]CircuitBreakerSettings, len(policy.Providers))
+		for provider, settings := range policy.Providers {
+			normalized.Providers[strings.ToLower(strings.TrimSpace(provider))] = settings
+		}
+	}
+	m.breakers.mu.Lock()
+	m.breakers.policy = normalized
+	if !normalized.Enabled {
+		m.breakers.breakers = nil
+	}
+	for provider, breaker := range m.breakers.breakers {
+		breaker.settings = m.breakers.settingsFor(provider).withDefaults()
+	}
+	m.breakers.mu.Unlock()
+}
+
+// CircuitBreakers returns the state of every provider breaker ordered by provider.
+func (m *Manager) CircuitBreakers() []CircuitBreakerStatus {
+	if m == nil {
+		return nil
+	}
+	m.breakers.mu.Lock()
+	defer m.breakers.mu.Unlock()
+	now := time.Now()
+	// Expired holds are dropped here as in allow, so they never hide the real breaker status.
+	for provider, until := range m.breakers.holds {
+		if !now.Before(until) {
+			delete(m.breakers.holds, provider)
+		}
+	}
+	out := make([]CircuitBreakerStatus, 0, len(m.breakers.breakers))
+	for provider, b := range m.breakers.breakers {
+		if _, held := m.breakers.holds[provider]; held {
+			continue
+		}
+		out = append(out, b.status(provider))
+	}
+	for provider, until := range m.breakers.holds {
+		next := until
+		st := CircuitBreakerStatus{Provider: provider, State: CircuitOpen, NextProbeAt: &next, Reason: maintenanceQuotaReason}
+		if b, ok := m.breakers.breakers[provider]; ok {
+			st.ConsecutiveFailures = b.consecutiveFailures
+		}
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Provider < out[j].Provider })
+	return out
+}
+
+// ProviderCircuitState returns the breaker state of one provider. Providers without a breaker, or
+// with circuit breaking disabled and no maintenance hold, report closed.
+func (m *Manager) ProviderCircuitState(provider string) CircuitState {
+	if m == nil {
+		return CircuitClosed
+	}
+	m.breakers.mu.Lock()
+	defer m.breakers.mu.Unlock()
+	if until, held := m.breakers.holds[provider]; held && time.Now().Before(until) {
+		return CircuitOpen
+	}
+	if b, ok := m.breakers.breakers[provider]; ok {
+		if b.state == CircuitOpen && !time.Now().Before(b.openedAt.Add(b.settings.OpenDuration)) {
+			// The next request is admitted as a probe.
+			return CircuitHalfOpen
+		}
+		return b.state
+	}
+	return CircuitClosed
+}
+
+func circuitOpenError(provider string) *Error {
+	return &Error{Code: "circuit_open", Message: "circuit breaker open for provider " + provider, Retryable: true, HTTPStatus: http.StatusServiceUnavailable}
+}
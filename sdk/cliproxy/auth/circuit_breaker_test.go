@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func tripBreaker(b *circuitBreaker, now time.Time) {
+	for i := 0; i < b.settings.FailureThreshold; i++ {
+		b.record(false, now)
+	}
+}
+
+func TestCircuitBreakerOpensAndProbes(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	b := newCircuitBreaker(CircuitBreakerSettings{FailureThreshold: 2, OpenDuration: 10 * time.Second, ProbeRequests: 3, SuccessThreshold: 2, ProbeInterval: time.Second})
+
+	b.record(false, now)
+	if b.state != CircuitClosed {
+		t.Fatalf("state after one failure = %s, want closed", b.state)
+	}
+	b.record(false, now)
+	if b.state != CircuitOpen {
+		t.Fatalf("state after threshold = %s, want open", b.state)
+	}
+	if b.allow(now.Add(5 * time.Second)) {
+		t.Fatal("open breaker admitted a request before open duration elapsed")
+	}
+
+	probeAt := now.Add(10 * time.Second)
+	if !b.allow(probeAt) {
+		t.Fatal("first probe rejected after open duration")
+	}
+	if b.state != CircuitHalfOpen {
+		t.Fatalf("state = %s, want half_open", b.state)
+	}
+	if b.allow(probeAt.Add(500 * time.Millisecond)) {
+		t.Fatal("probe admitted before probe interval elapsed")
+	}
+	b.record(true, probeAt)
+	if b.state != CircuitHalfOpen {
+		t.Fatalf("state after one probe success = %s, want half_open", b.state)
+	}
+	st := b.status("claude")
+	if st.Probe == nil || st.Probe.Sent != 1 || st.Probe.Successes != 1 || st.Probe.SuccessThreshold != 2 {
+		t.Fatalf("unexpected probe status %+v", st.Probe)
+	}
+
+	if !b.allow(probeAt.Add(time.Second)) {
+		t.Fatal("second probe rejected after probe interval")
+	}
+	b.record(true, probeAt.Add(time.Second))
+	if b.state != CircuitClosed {
+		t.Fatalf("state after success threshold = %s, want closed", b.state)
+	}
+}
+
+func TestCircuitBreakerReopensWhenThresholdUnreachable(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	b := newCircuitBreaker(CircuitBreakerSettings{FailureThreshold: 1, OpenDuration: time.Second, ProbeRequests: 3, SuccessThreshold: 2})
+	tripBreaker(b, now)
+
+	probeAt := now.Add(time.Second)
+	if !b.allow(probeAt) {
+		t.Fatal("probe rejected")
+	}
+	b.record(false, probeAt)
+	if b.state != CircuitHalfOpen {
+		t.Fatalf("state after one probe failure = %s, want half_open", b.state)
+	}
+	if !b.allow(probeAt) {
+		t.Fatal("second probe rejected")
+	}
+	b.record(false, probeAt)
+	if b.state != CircuitOpen {
+		t.Fatalf("state after unreachable threshold = %s, want open", b.state)
+	}
+	if !b.openedAt.Equal(probeAt) {
+		t.Fatalf("openedAt = %v, want %v", b.openedAt, probeAt)
+	}
+}
+
+func TestCircuitBreakerLimitsProbeCount(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	b := newCircuitBreaker(CircuitBreakerSettings{FailureThreshold: 1, OpenDuration: time.Second, ProbeRequests: 2})
+	tripBreaker(b, now)
+
+	probeAt := now.Add(time.Second)
+	if !b.allow(probeAt) || !b.allow(probeAt) {
+		t.Fatal("configured probes rejected")
+	}
+	if b.allow(probeAt) {
+		t.Fatal("more probes admitted than configured")
+	}
+}
+
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	if isBreakerFailure(Result{Error: &Error{HTTPStatus: 429}}) {
+		t.Fatal("429 counted as provider failure")
+	}
+	if !isBreakerFailure(Result{Error: &Error{HTTPStatus: 502}}) {
+		t.Fatal("502 not counted as provider failure")
+	}
+}
+
+func TestCircuitBreakerWindowExpiresOldFailures(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	b := newCircuitBreaker(CircuitBreakerSettings{FailureThreshold: 3, OpenDuration: time.Minute, Window: 10 * time.Second})
+
+	b.record(false, now)
+	b.record(false, now.Add(5*time.Second))
+	b.record(false, now.Add(30*time.Second))
+	if b.state != CircuitClosed || b.consecutiveFailures != 1 {
+		t.Fatalf("spread-out failures: state %s, failures %d", b.state, b.consecutiveFailures)
+	}
+	b.record(false, now.Add(35*time.Second))
+	b.record(false, now.Add(40*time.Second))
+	if b.state != CircuitOpen {
+		t.Fatalf("burst of failures left the breaker %s", b.state)
+	}
+}
+
+func TestProviderCircuitState(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	if got := m.ProviderCircuitState("claude"); got != CircuitClosed {
+		t.Fatalf("unknown provider = %s, want closed", got)
+	}
+	m.SetCircuitBreakerPolicy(CircuitBreakerPolicy{Enabled: true, Defaults: CircuitBreakerSettings{FailureThreshold: 1, OpenDuration: time.Hour}})
+	m.breakers.record("claude", Result{Provider: "claude"}, time.Now())
+	if got := m.ProviderCircuitState("claude"); got != CircuitOpen {
+		t.Fatalf("tripped provider = %s, want open", got)
+	}
+	m.breakers.breakers["claude"].openedAt = time.Now().Add(-2 * time.Hour)
+	if got := m.ProviderCircuitState("claude"); got != CircuitHalfOpen {
+		t.Fatalf("provider past its open duration = %s, want half_open", got)
+	}
+}
+
+func TestCircuitBreakersReportBreakerAfterHoldExpires(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetCircuitBreakerPolicy(CircuitBreakerPolicy{Enabled: true, Defaults: CircuitBreakerSettings{FailureThreshold: 1, OpenDuration: time.Hour}})
+	m.breakers.record("claude", Result{Provider: "claude"}, time.Now())
+	m.breakers.hold("claude", time.Minute, time.Now().Add(-2*time.Minute))
+
+	statuses := m.CircuitBreakers()
+	if len(statuses) != 1 || statuses[0].State != CircuitOpen || statuses[0].Reason == maintenanceQuotaReason {
+		t.Fatalf("statuses = %+v, want the tripped breaker once the hold expired", statuses)
+	}
+	if _, held := m.breakers.holds["claude"]; held {
+		t.Fatal("expired hold was not dropped")
+	}
+}
@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultClientErrorStatuses are upstream statuses caused by the client's request rather than the account.
+var defaultClientErrorStatuses = []int{http.StatusBadRequest, http.StatusUnprocessableEntity}
+
+// accountErrorPatterns lists upstream error fragments that describe the credential or account even
+// when they arrive with a client-error status such as 400. Keys are providers; "*" applies to all.
+var accountErrorPatterns = map[string][]string{
+	billingWildcardProvider: {
+		"api key not valid",
+		"api_key_invalid",
+		"api key expired",
+		"invalid api key",
+		"invalid_api_key",
+		"organization has been disabled",
+	},
+	"claude": {
+		"credit balance is too low",
+		"invalid x-api-key",
+	},
+	"codex": {
+		"account is not active",
+		"workspace is deactivated",
+	},
+	"gemini": {
+		"api key not found",
+		"consumer_suspended",
+	},
+	"gemini-cli": {
+		"consumer_suspended",
+	},
+}
+
+// describesAccount reports whether err matches a known credential or account problem of provider.
+func describesAccount(provider string, err *Error) bool {
+	if err == nil {
+		return false
+	}
+	text := strings.ToLower(err.Code + " " + err.Message)
+	for _, key := range []string{strings.ToLower(provider), billingWildcardProvider} {
+		for _, pattern := range accountErrorPatterns[key] {
+			if strings.Contains(text, pattern) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// clientErrorStatuses holds the statuses that are returned to the client without touching the account.
+type clientErrorStatuses struct {
+	mu       sync.RWMutex
+	statuses map[int]struct{}
+}
+
+// SetClientErrorStatuses replaces the statuses treated as client-caused; empty restores the defaults (400, 422).
+// Only 4xx statuses other than 401, 402, 403, 404, 408 and 429 are accepted since those describe the account.
+func (m *Manager) SetClientErrorStatuses(statuses []int) {
+	if m == nil {
+		return
+	}
+	if len(statuses) == 0 {
+		statuses = defaultClientErrorStatuses
+	}
+	set := make(map[int]struct{}, len(statuses))
+	for _, status := range statuses {
+		switch {
+		case status < 400 || status > 499:
+		case status == http.StatusUnauthorized, status == http.StatusPaymentRequired, status == http.StatusForbidden,
+			status == http.StatusNotFound, status == http.StatusRequestTimeout, status == http.StatusTooManyRequests:
+		default:
+			set[status] = struct{}{}
+		}
+	}
+	m.clientErrors.mu.Lock()
+	m.clientErrors.statuses = set
+	m.clientErrors.mu.Unlock()
+}
+
+// has reports whether status is client-caused. Before any policy is set the defaults apply.
+func (c *clientErrorStatuses) has(status int) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.statuses == nil {
+		for _, s := range defaultClientErrorStatuses {
+			if s == status {
+				return true
+			}
+		}
+		return false
+	}
+	_, ok := c.statuses[status]
+	return ok
+}
+
+// isClientCaused reports whether err was caused by the client's request and must be returned as-is,
+// without failover or account penalties. Errors matching known credential problems (e.g. an invalid
+// API key) or billing, egress or maintenance detection describe the account even when they arrive
+// with a client-error status.
+func (m *Manager) isClientCaused(provider string, err *Error) bool {
+	if err == nil || !m.clientErrors.has(statusCodeFromResult(err)) || describesAccount(provider, err) {
+		return false
+	}
+	m.mu.RLock()
+	billing := m.isBillingSuspension(provider, err)
+	m.mu.RUnlock()
+	if billing {
+		return false
+	}
+	if _, ok := m.egress.match(provider, err); ok {
+		return false
+	}
+	_, maintenance := m.maintenance.match(provider, err)
+	return !maintenance
+}
+
+// ClientError wraps an upstream error that the manager returned as-is because the client's request
+// caused it. The body describes the request rather than the pool, so callers may pass it through.
+type ClientError struct {
+	Err error
+}
+
+func (e *ClientError) Error() string { return e.Err.Error() }
+
+func (e *ClientError) Unwrap() error { return e.Err }
+
+// StatusCode returns the upstream status of the wrapped error.
+func (e *ClientError) StatusCode() int { return statusCodeFromError(e.Err) }
+
+// Headers returns the upstream headers of the wrapped error, if any.
+func (e *ClientError) Headers() http.Header {
+	var he interface{ Headers() http.Header }
+	if errors.As(e.Err, &he) && he != nil {
+		return he.Headers()
+	}
+	return nil
+}
+
+// IsClientError reports whether err was returned as-is because the client's request caused it.
+func IsClientError(err error) bool {
+	var ce *ClientError
+	return errors.As(err, &ce)
+}
+
+// isClientErrorStatus reports whether an execution error carries a client-error status; such
+// errors are not worth waiting for cooldowns to retry.
+func (m *Manager) isClientErrorStatus(err error) bool {
+	return err != nil && m.clientErrors.has(statusCodeFromError(err))
+}
+
+// recordClientErrorLocked counts a client-caused failure on auth without changing its health.
+// Callers must hold m.mu.
+func recordClientErrorLocked(auth *Auth, now time.Time) {
+	auth.ClientErrors++
+	auth.LastClientErrorAt = now
+}
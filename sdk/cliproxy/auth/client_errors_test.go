@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestMarkResultClientErrorsLeaveAccountHealthy(t *testing.T) {
+	cases := []struct {
+		status       int
+		clientCaused bool
+	}{
+		{400, true},
+		{422, true},
+		{401, false},
+		{403, false},
+		{404, false},
+		{408, false},
+		{429, false},
+		{500, false},
+		{503, false},
+	}
+	for _, tc := range cases {
+		for _, model := range []string{"", "gpt-5"} {
+			m := NewManager(nil, nil, nil)
+			if _, err := m.Register(context.Background(), &Auth{ID: "a", Provider: "codex", Status: StatusActive}); err != nil {
+				t.Fatalf("register: %v", err)
+			}
+			resultErr := &Error{HTTPStatus: tc.status, Message: "upstream error"}
+			m.MarkResult(context.Background(), Result{AuthID: "a", Provider: "codex", Model: model, Error: resultErr})
+			auth, _ := m.GetByID("a")
+
+			if got := m.isClientCaused("codex", resultErr); got != tc.clientCaused {
+				t.Fatalf("status %d: isClientCaused = %v, want %v", tc.status, got, tc.clientCaused)
+			}
+			if tc.clientCaused {
+				if auth.ClientErrors != 1 || auth.Status != StatusActive || auth.Unavailable || auth.LastError != nil || len(auth.ModelStates) != 0 {
+					t.Fatalf("status %d model %q: account must be untouched, got %+v", tc.status, model, auth)
+				}
+				continue
+			}
+			if auth.ClientErrors != 0 || auth.Status != StatusError {
+				t.Fatalf("status %d model %q: expected account failure, got status=%s client_errors=%d", tc.status, model, auth.Status, auth.ClientErrors)
+			}
+		}
+	}
+}
+
+func TestClientErrorStatusesConfigurable(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetClientErrorStatuses([]int{413, 429, 500})
+	if !m.clientErrors.has(413) || m.clientErrors.has(400) {
+		t.Fatalf("expected configured statuses to replace the defaults")
+	}
+	if m.clientErrors.has(429) || m.clientErrors.has(500) {
+		t.Fatalf("account and server statuses must never be treated as client errors")
+	}
+	m.SetBillingSuspensionPolicy(BillingSuspensionPolicy{Enabled: true})
+	m.SetClientErrorStatuses(nil)
+	if m.isClientCaused("claude", &Error{HTTPStatus: 400, Message: "Your credit balance is too low"}) {
+		t.Fatalf("billing errors must not be treated as client errors")
+	}
+}
+
+func TestExecuteProvidersOnceMarksClientErrors(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	upstream := &Error{HTTPStatus: 400, Message: "messages: field required"}
+	calls := 0
+	_, err := m.executeProvidersOnce(context.Background(), []string{"claude", "gemini"}, func(context.Context, string) (cliproxyexecutor.Response, error) {
+		calls++
+		return cliproxyexecutor.Response{}, upstream
+	})
+	if calls != 1 || !IsClientError(err) || !errors.Is(err, upstream) || statusCodeFromError(err) != 400 {
+		t.Fatalf("calls=%d err=%v; want the upstream 400 marked as client-caused without failover", calls, err)
+	}
+
+	_, err = m.executeProvidersOnce(context.Background(), []string{"claude"}, func(context.Context, string) (cliproxyexecutor.Response, error) {
+		return cliproxyexecutor.Response{}, &Error{HTTPStatus: 429, Message: "rate limited"}
+	})
+	if IsClientError(err) {
+		t.Fatalf("account errors must not be marked client-caused: %v", err)
+	}
+}
+
+func TestAccountErrorsWithClientStatusAreNotClientCaused(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	cases := []struct {
+		provider string
+		message  string
+	}{
+		{"gemini", "API key not valid. Please pass a valid API key."},
+		{"gemini-cli", `{"error":{"code":400,"status":"INVALID_ARGUMENT","details":[{"reason":"API_KEY_INVALID"}]}}`},
+		{"claude", "Your credit balance is too low to access the Anthropic API."},
+		{"claude", "invalid x-api-key"},
+		{"codex", "Your workspace is deactivated."},
+	}
+	for _, tc := range cases {
+		if m.isClientCaused(tc.provider, &Error{HTTPStatus: 400, Message: tc.message}) {
+			t.Fatalf("%s %q: account error treated as client-caused", tc.provider, tc.message)
+		}
+	}
+	if !m.isClientCaused("gemini", &Error{HTTPStatus: 400, Message: "contents: field required"}) {
+		t.Fatal("validation error must stay client-caused")
+	}
+}
+
+func TestMarkResultPenalisesAccountErrorWithClientStatus(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	if _, err := m.Register(context.Background(), &Auth{ID: "a", Provider: "gemini", Status: StatusActive}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	m.MarkResult(context.Background(), Result{AuthID: "a", Provider: "gemini", Error: &Error{HTTPStatus: 400, Message: "API key not valid"}})
+	if auth, _ := m.GetByID("a"); auth.ClientErrors != 0 || auth.Status != StatusError {
+		t.Fatalf("invalid key must count against the account, got status=%s client_errors=%d", auth.Status, auth.ClientErrors)
+	}
+}
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// ClientStrategy is a per-client-key selection strategy override.
+type ClientStrategy struct {
+	APIKey   string    `json:"api_key"`
+	Strategy string    `json:"strategy"`
+	SetAt    time.Time `json:"set_at"`
+	// ExpiresAt is zero for overrides that stay until cleared.
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+type clientStrategyEntry struct {
+	ClientStrategy
+	selector Selector
+}
+
+// clientStrategyBook holds per-client selectors; each override keeps its own rotation state.
+type clientStrategyBook struct {
+	mu      sync.Mutex
+	entries map[string]*clientStrategyEntry
+}
+
+// selectorFor returns the override selector for the request's client key, or fallback.
+func (b *clientStrategyBook) selectorFor(opts cliproxyexecutor.Options, fallback Selector, now time.Time) Selector {
+	key, _ := opts.Metadata[cliproxyexecutor.ClientKeyMetadataKey].(string)
+	if key == "" {
+		return fallback
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entry, ok := b.entries[key]
+	if !ok {
+		return fallback
+	}
+	if !entry.ExpiresAt.IsZero() && !now.Before(entry.ExpiresAt) {
+		delete(b.entries, key)
+		return fallback
+	}
+	return entry.selector
+}
+
+// SetClientStrategy makes requests from the client API key use the named strategy instead of the
+// global selector. A positive ttl lets the override lapse on its own.
+func (m *Manager) SetClientStrategy(apiKey, strategy string, ttl time.Duration) (ClientStrategy, error) {
+	apiKey = strings.TrimSpace(apiKey)
+	strategy = normalizeStrategy(strategy)
+	if apiKey == "" {
+		return ClientStrategy{}, &Error{Code: "invalid_client_key", Message: "client key is required"}
+	}
+	selector, ok := selectorForStrategy(strategy, m.activity.inFlight)
+	if !ok {
+		return ClientStrategy{}, &Error{Code: "unknown_strategy", Message: "unknown strategy " + strategy + "; supported: " + strings.Join(supportedStrategies, ", ")}
+	}
+	now := time.Now()
+	entry := &clientStrategyEntry{ClientStrategy: ClientStrategy{APIKey: apiKey, Strategy: strategy, SetAt: now}, selector: selector}
+	if ttl > 0 {
+		entry.ExpiresAt = now.Add(ttl)
+	}
+	b := &m.clientStrategies
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.entries == nil {
+		b.entries = make(map[string]*clientStrategyEntry)
+	}
+	if existing, ok := b.entries[apiKey]; ok && existing.Strategy == strategy {
+		// Keep the rotation state of an unchanged strategy.
+		entry.selector = existing.selector
+	}
+	b.entries[apiKey] = entry
+	return entry.ClientStrategy, nil
+}
+
+// ClearClientStrategy removes the override of the client API key and reports whether one existed.
+func (m *Manager) ClearClientStrategy(apiKey string) bool {
+	b := &m.clientStrategies
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.entries[strings.TrimSpace(apiKey)]
+	delete(b.entries, strings.TrimSpace(apiKey))
+	return ok
+}
+
+// ClientStrategies lists the active per-client overrides ordered by key.
+func (m *Manager) ClientStrategies() []ClientStrategy {
+	if m == nil {
+		return nil
+	}
+	now := time.Now()
+	b := &m.clientStrategies
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]ClientStrategy, 0, len(b.entries))
+	for key, entry := range b.entries {
+		if !entry.ExpiresAt.IsZero() && !now.Before(entry.ExpiresAt) {
+			delete(b.entries, key)
+			continue
+		}
+		out = append(out, entry.ClientStrategy)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].APIKey < out[j].APIKey })
+	return out
+}
+
+// GlobalStrategy reports the strategy of the global selector, or "custom" for SDK-provided selectors.
+func (m *Manager) GlobalStrategy() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return strategyName(m.selector)
+}
+
+// SetSelectionStrategy replaces the selector with the named strategy. An empty strategy keeps the
+// current selector, as does naming the strategy already in use, so rotation state survives reloads.
+func (m *Manager) SetSelectionStrategy(strategy string) error {
+	if m == nil {
+		return nil
+	}
+	strategy = normalizeStrategy(strategy)
+	if strategy == "" {
+		return nil
+	}
+	selector, ok := selectorForStrategy(strategy, m.activity.inFlight)
+	if !ok {
+		return &Error{Code: "unknown_strategy", Message: "unknown strategy " + strategy + "; supported: " + strings.Join(supportedStrategies, ", ")}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if strategyName(m.selector) != strategy {
+		m.selector = selector
+	}
+	return nil
+}
+
+// SelectionCounts returns how often each account was picked first over the recent selection history.
+func (m *Manager) SelectionCounts() map[string]int {
+	if m == nil {
+		return nil
+	}
+	counts := make(map[string]int)
+	for _, rec := range m.history.snapshot() {
+		counts[rec.authID]++
+	}
+	return counts
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestClientStrategyOverridesGlobalSelector(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	now := time.Now()
+	clientOpts := cliproxyexecutor.Options{Metadata: map[string]any{cliproxyexecutor.ClientKeyMetadataKey: "sk-latency"}}
+	otherOpts := cliproxyexecutor.Options{Metadata: map[string]any{cliproxyexecutor.ClientKeyMetadataKey: "sk-other"}}
+
+	if _, err := m.SetClientStrategy("sk-latency", "family-balanced", 0); err != nil {
+		t.Fatalf("set client strategy: %v", err)
+	}
+	if _, ok := m.clientStrategies.selectorFor(clientOpts, m.selector, now).(*FamilyBalancedSelector); !ok {
+		t.Fatalf("override should be used for its client key")
+	}
+	if got := m.clientStrategies.selectorFor(otherOpts, m.selector, now); got != m.selector {
+		t.Fatalf("other clients must fall back to the global selector")
+	}
+	if _, err := m.SetClientStrategy("sk-latency", "fastest", 0); err == nil {
+		t.Fatalf("unknown strategies should be rejected")
+	}
+
+	if _, err := m.SetClientStrategy("sk-other", "weighted-random", time.Minute); err != nil {
+		t.Fatalf("set client strategy: %v", err)
+	}
+	if got := m.clientStrategies.selectorFor(otherOpts, m.selector, now.Add(2*time.Minute)); got != m.selector {
+		t.Fatalf("expired overrides must fall back to the global selector")
+	}
+	if list := m.ClientStrategies(); len(list) != 1 || list[0].APIKey != "sk-latency" {
+		t.Fatalf("expected only the permanent override to remain, got %+v", list)
+	}
+	if !m.ClearClientStrategy("sk-latency") || len(m.ClientStrategies()) != 0 {
+		t.Fatalf("clearing should remove the override")
+	}
+}
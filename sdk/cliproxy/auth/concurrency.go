@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxConcurrentMetadataKey mirrors the "max_concurrent" attribute in metadata so file-backed accounts
+// keep a limit set at runtime across reloads.
+const maxConcurrentMetadataKey = "max_concurrent"
+
+// defaultConcurrencyQueueTimeout is how long a request waits for a free account when every eligible
+// account is at its concurrency limit and no timeout is configured.
+const defaultConcurrencyQueueTimeout = 30 * time.Second
+
+// MaxConcurrent returns how many requests the auth may serve at once, 0 when unlimited. The
+// "max_concurrent" attribute wins over the metadata entry of the same name.
+func (a *Auth) MaxConcurrent() int {
+	if a == nil {
+		return 0
+	}
+	if raw := strings.TrimSpace(a.Attributes[maxConcurrentMetadataKey]); raw != "" {
+		if v, err := strconv.Atoi(raw); err == nil && v > 0 {
+			return v
+		}
+	}
+	switch raw := a.Metadata[maxConcurrentMetadataKey].(type) {
+	case int:
+		return max(raw, 0)
+	case float64:
+		return max(int(raw), 0)
+	case string:
+		if v, err := strconv.Atoi(strings.TrimSpace(raw)); err == nil && v > 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// SetMaxConcurrent replaces the auth's concurrency limit, mirroring it into metadata. A limit below 1
+// removes it.
+func (a *Auth) SetMaxConcurrent(limit int) {
+	if a == nil {
+		return
+	}
+	if limit < 1 {
+		delete(a.Attributes, maxConcurrentMetadataKey)
+		delete(a.Metadata, maxConcurrentMetadataKey)
+		return
+	}
+	if a.Attributes == nil {
+		a.Attributes = make(map[string]string)
+	}
+	a.Attributes[maxConcurrentMetadataKey] = strconv.Itoa(limit)
+	if a.Metadata != nil {
+		a.Metadata[maxConcurrentMetadataKey] = limit
+	}
+}
+
+// accountConcurrency counts the requests holding a slot on each account. A slot is taken when the
+// account is selected and given back when the request finishes, so bursts cannot overshoot a limit
+// before the requests are dispatched.
+type accountConcurrency struct {
+	mu    sync.Mutex
+	inUse map[string]int
+	// released is closed and replaced whenever a slot is given back, waking queued requests.
+	released chan struct{}
+	// queueTimeout bounds the wait for a free account; negative fails at once.
+	queueTimeout time.Duration
+}
+
+// full reports whether authID has no free slot under limit.
+func (c *accountConcurrency) full(authID string, limit int) bool {
+	if limit <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.inUse[authID] >= limit
+}
+
+// acquire takes a slot on authID unless it is at limit; a limit of 0 always admits.
+func (c *accountConcurrency) acquire(authID string, limit int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if limit > 0 && c.inUse[authID] >= limit {
+		return false
+	}
+	if c.inUse == nil {
+		c.inUse = make(map[string]int)
+	}
+	c.inUse[authID]++
+	return true
+}
+
+func (c *accountConcurrency) release(authID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.inUse[authID] <= 0 {
+		return
+	}
+	c.inUse[authID]--
+	if c.inUse[authID] == 0 {
+		delete(c.inUse, authID)
+	}
+	if c.released != nil {
+		close(c.released)
+		c.released = nil
+	}
+}
+
+// wake returns a channel closed on the next release. Take it before trying to acquire so a release
+// in between is not missed.
+func (c *accountConcurrency) wake() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.released == nil {
+		c.released = make(chan struct{})
+	}
+	return c.released
+}
+
+func (c *accountConcurrency) count(authID string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.inUse[authID]
+}
+
+func (c *accountConcurrency) timeout() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.queueTimeout == 0 {
+		return defaultConcurrencyQueueTimeout
+	}
+	return c.queueTimeout
+}
+
+// SetConcurrencyQueueTimeout sets how long a request waits for a free account when every eligible
+// account is at its concurrency limit. Zero restores the default of 30s; a negative value fails such
+// requests at once.
+func (m *Manager) SetConcurrencyQueueTimeout(timeout time.Duration) {
+	if m == nil {
+		return
+	}
+	m.concurrency.mu.Lock()
+	m.concurrency.queueTimeout = timeout
+	m.concurrency.mu.Unlock()
+}
+
+// InFlight returns the number of requests currently holding a concurrency slot on the account.
+func (m *Manager) InFlight(id string) int {
+	if m == nil {
+		return 0
+	}
+	return m.concurrency.count(id)
+}
+
+// waitForSlot blocks until a slot may have been released, the queue deadline passes or ctx ends.
+// deadline is set on the first wait so retries share one timeout.
+func (m *Manager) waitForSlot(ctx context.Context, provider string, wake <-chan struct{}, deadline *time.Time) error {
+	if deadline.IsZero() {
+		timeout := m.concurrency.timeout()
+		if timeout < 0 {
+			return concurrencyLimitError(provider)
+		}
+		*deadline = time.Now().Add(timeout)
+	}
+	remaining := time.Until(*deadline)
+	if remaining <= 0 {
+		return concurrencyLimitError(provider)
+	}
+	timer := time.NewTimer(remaining)
+	defer timer.Stop()
+	select {
+	case <-wake:
+		return nil
+	case <-timer.C:
+		return concurrencyLimitError(provider)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func concurrencyLimitError(provider string) *Error {
+	return &Error{
+		Code:       "account_concurrency_limit",
+		Message:    "all " + provider + " accounts are at their concurrency limit",
+		Retryable:  true,
+		HTTPStatus: http.StatusTooManyRequests,
+	}
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func newConcurrencyManager(t *testing.T, ids ...string) *Manager {
+	t.Helper()
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&streamingExecutor{})
+	for _, id := range ids {
+		if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: "streamy", Status: StatusActive, Metadata: map[string]any{maxConcurrentMetadataKey: float64(1)}}); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	return m
+}
+
+func TestSelectionSkipsAccountsAtConcurrencyLimit(t *testing.T) {
+	m := newConcurrencyManager(t, "a", "b")
+	first, _, err := m.pickNext(context.Background(), "streamy", "", cliproxyexecutor.Options{}, nil)
+	if err != nil {
+		t.Fatalf("first pick: %v", err)
+	}
+	second, _, err := m.pickNext(context.Background(), "streamy", "", cliproxyexecutor.Options{}, nil)
+	if err != nil || second.ID == first.ID {
+		t.Fatalf("second pick = %v, %v; want the other account than %s", second, err, first.ID)
+	}
+	if m.InFlight(first.ID) != 1 || m.InFlight(second.ID) != 1 {
+		t.Fatalf("in flight = %d/%d, want 1/1", m.InFlight(first.ID), m.InFlight(second.ID))
+	}
+	finish := m.beginRequest(first.ID)
+	finish()
+	finish()
+	if m.InFlight(first.ID) != 0 {
+		t.Fatalf("finished request still holds a slot: %d", m.InFlight(first.ID))
+	}
+}
+
+func TestSelectionQueueTimesOutWhenEveryAccountIsFull(t *testing.T) {
+	m := newConcurrencyManager(t, "a")
+	m.SetConcurrencyQueueTimeout(30 * time.Millisecond)
+	if _, _, err := m.pickNext(context.Background(), "streamy", "", cliproxyexecutor.Options{}, nil); err != nil {
+		t.Fatalf("first pick: %v", err)
+	}
+
+	started := time.Now()
+	_, _, err := m.pickNext(context.Background(), "streamy", "", cliproxyexecutor.Options{}, nil)
+	var authErr *Error
+	if !errors.As(err, &authErr) || authErr.Code != "account_concurrency_limit" || authErr.HTTPStatus != 429 {
+		t.Fatalf("queued pick error = %v, want account_concurrency_limit", err)
+	}
+	if waited := time.Since(started); waited < 30*time.Millisecond {
+		t.Fatalf("gave up after %s, before the queue timeout", waited)
+	}
+	if m.InFlight("a") != 1 {
+		t.Fatalf("timed out request took a slot: %d", m.InFlight("a"))
+	}
+
+	m.SetConcurrencyQueueTimeout(-1)
+	started = time.Now()
+	if _, _, err = m.pickNext(context.Background(), "streamy", "", cliproxyexecutor.Options{}, nil); !errors.As(err, &authErr) || time.Since(started) > 20*time.Millisecond {
+		t.Fatalf("negative timeout should fail at once: %v after %s", err, time.Since(started))
+	}
+}
+
+func TestSelectionQueueAdmitsWhenASlotIsReleased(t *testing.T) {
+	m := newConcurrencyManager(t, "a")
+	m.SetConcurrencyQueueTimeout(time.Second)
+	if _, _, err := m.pickNext(context.Background(), "streamy", "", cliproxyexecutor.Options{}, nil); err != nil {
+		t.Fatalf("first pick: %v", err)
+	}
+	finish := m.beginRequest("a")
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		finish()
+	}()
+	auth, _, err := m.pickNext(context.Background(), "streamy", "", cliproxyexecutor.Options{}, nil)
+	if err != nil || auth.ID != "a" {
+		t.Fatalf("queued pick = %v, %v; want a once released", auth, err)
+	}
+	if m.InFlight("a") != 1 {
+		t.Fatalf("in flight after handover = %d, want 1", m.InFlight("a"))
+	}
+}
+
+func TestSelectionQueuesForSaturatedAccountWhenOthersCoolDown(t *testing.T) {
+	m := newConcurrencyManager(t, "full")
+	cooling := &Auth{ID: "cooling", Provider: "streamy", Status: StatusActive, Unavailable: true, NextRetryAfter: time.Now().Add(time.Minute), Quota: QuotaState{Exceeded: true}}
+	if _, err := m.Register(context.Background(), cooling); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	m.SetConcurrencyQueueTimeout(time.Second)
+	m.concurrency.acquire("full", 1)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		m.concurrency.release("full")
+	}()
+	auth, _, err := m.pickNext(context.Background(), "streamy", "", cliproxyexecutor.Options{}, nil)
+	if err != nil || auth.ID != "full" {
+		t.Fatalf("pick = %v, %v; want to queue for the saturated account", auth, err)
+	}
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Stages at which a request deadline can run out inside the manager.
+const (
+	DeadlineStageRetryWait = "retry_wait"
+	DeadlineStageUpstream  = "upstream"
+)
+
+// DeadlineExceededError reports that the caller's context deadline ran out or would run out before
+// the request could complete. It is never recorded against the account that was serving it.
+type DeadlineExceededError struct {
+	Stage string
+}
+
+func (e *DeadlineExceededError) Error() string {
+	message := "request deadline exceeded during upstream call"
+	if e.Stage == DeadlineStageRetryWait {
+		message = "request deadline would be exceeded waiting for a cooled-down account"
+	}
+	data, _ := json.Marshal(map[string]any{"error": map[string]any{
+		"code":    "deadline_exceeded",
+		"message": message,
+		"stage":   e.Stage,
+	}})
+	return string(data)
+}
+
+// StatusCode maps deadline failures to 504 Gateway Timeout.
+func (e *DeadlineExceededError) StatusCode() int { return http.StatusGatewayTimeout }
+
+// deadlineExceeded returns a DeadlineExceededError when ctx ran out of its deadline.
+func deadlineExceeded(ctx context.Context, stage string) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &DeadlineExceededError{Stage: stage}
+	}
+	return nil
+}
+
+// isDeadlineExceeded reports whether err is a DeadlineExceededError.
+func isDeadlineExceeded(err error) bool {
+	var deadlineErr *DeadlineExceededError
+	return errors.As(err, &deadlineErr)
+}
+
+// waitExceedsDeadline reports whether waiting for wait would overrun ctx's deadline.
+func waitExceedsDeadline(ctx context.Context, wait time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return ok && time.Now().Add(wait).After(deadline)
+}
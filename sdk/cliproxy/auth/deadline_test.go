@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// hangingExecutor blocks until the request context ends.
+type hangingExecutor struct{ streamingExecutor }
+
+func (e *hangingExecutor) Identifier() string { return "hangy" }
+
+func (e *hangingExecutor) Execute(ctx context.Context, _ *Auth, _ cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	<-ctx.Done()
+	return cliproxyexecutor.Response{}, ctx.Err()
+}
+
+func TestExecuteDeadlineReturnsTimeoutWithoutPenalizingAccount(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&hangingExecutor{})
+	for _, id := range []string{"a", "b"} {
+		if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: "hangy", Status: StatusActive}); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := m.Execute(ctx, []string{"hangy"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{})
+	var deadlineErr *DeadlineExceededError
+	if !errors.As(err, &deadlineErr) || deadlineErr.StatusCode() != 504 || deadlineErr.Stage != DeadlineStageUpstream {
+		t.Fatalf("expected upstream deadline error, got %v", err)
+	}
+	for _, id := range []string{"a", "b"} {
+		auth, _ := m.GetByID(id)
+		if auth.Unavailable || auth.LastError != nil || auth.Status != StatusActive {
+			t.Fatalf("deadline must not be recorded against account %s: %+v", id, auth)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultDrainTimeout bounds how long a drain waits for in-flight requests before disabling anyway.
+const DefaultDrainTimeout = 5 * time.Minute
+
+// drainPollInterval is how often a drain checks the account's in-flight requests.
+var drainPollInterval = 200 * time.Millisecond
+
+// DrainStatus reports an account drain: the account takes no new requests and is disabled once its
+// in-flight requests finished or the deadline passed.
+type DrainStatus struct {
+	ID        string     `json:"id"`
+	Draining  bool       `json:"draining"`
+	Disabled  bool       `json:"disabled"`
+	InFlight  int        `json:"in_flight"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	Deadline  *time.Time `json:"deadline,omitempty"`
+}
+
+type drainState struct {
+	started  time.Time
+	deadline time.Time
+	cancel   context.CancelFunc
+}
+
+// accountDrains holds the drains in progress.
+type accountDrains struct {
+	mu     sync.Mutex
+	byAuth map[string]*drainState
+}
+
+func (d *accountDrains) draining(authID string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, ok := d.byAuth[authID]
+	return ok
+}
+
+// DrainAccount takes the account out of selection and disables it once its in-flight requests
+// finished or timeout (<= 0 uses DefaultDrainTimeout) elapsed. An account without in-flight requests
+// is disabled right away; draining an account that is already draining reports the running drain.
+func (m *Manager) DrainAccount(id string, timeout time.Duration) (DrainStatus, error) {
+	if m == nil {
+		return DrainStatus{}, &Error{Code: "drain_unavailable", Message: "auth manager not available"}
+	}
+	auth, ok := m.GetByID(id)
+	if !ok || auth == nil {
+		return DrainStatus{}, &Error{Code: "auth_not_found", Message: "account not found", HTTPStatus: http.StatusNotFound}
+	}
+	if auth.Disabled {
+		return DrainStatus{}, &Error{Code: "account_disabled", Message: "account already disabled", HTTPStatus: http.StatusConflict}
+	}
+	if timeout <= 0 {
+		timeout = DefaultDrainTimeout
+	}
+	now := time.Now()
+	m.drains.mu.Lock()
+	if m.drains.byAuth[id] == nil {
+		if m.drains.byAuth == nil {
+			m.drains.byAuth = make(map[string]*drainState)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		m.drains.byAuth[id] = &drainState{started: now, deadline: now.Add(timeout), cancel: cancel}
+		go m.runDrain(ctx, id, drainPollInterval)
+		log.Infof("draining auth %s (%s): %d requests in flight", id, auth.Provider, m.activity.inFlight(id))
+	}
+	m.drains.mu.Unlock()
+	if m.activity.inFlight(id) == 0 {
+		m.finishDrain(id, false)
+	}
+	status, _ := m.DrainStatus(id)
+	return status, nil
+}
+
+// CancelDrain stops a drain in progress without disabling the account and reports whether one ran.
+func (m *Manager) CancelDrain(id string) bool {
+	if m == nil {
+		return false
+	}
+	m.drains.mu.Lock()
+	defer m.drains.mu.Unlock()
+	state, ok := m.drains.byAuth[id]
+	if !ok {
+		return false
+	}
+	state.cancel()
+	delete(m.drains.byAuth, id)
+	return true
+}
+
+// DrainStatus reports the drain state of the account, or false when it is not registered.
+func (m *Manager) DrainStatus(id string) (DrainStatus, bool) {
+	if m == nil {
+		return DrainStatus{}, false
+	}
+	auth, ok := m.GetByID(id)
+	if !ok || auth == nil {
+		return DrainStatus{}, false
+	}
+	status := DrainStatus{ID: id, Disabled: auth.Disabled, InFlight: m.activity.inFlight(id)}
+	m.drains.mu.Lock()
+	if state, ok := m.drains.byAuth[id]; ok {
+		started, deadline := state.started, state.deadline
+		status.Draining, status.StartedAt, status.Deadline = true, &started, &deadline
+	}
+	m.drains.mu.Unlock()
+	return status, true
+}
+
+// IsDraining reports whether the account is being drained.
+func (m *Manager) IsDraining(id string) bool {
+	if m == nil {
+		return false
+	}
+	return m.drains.draining(id)
+}
+
+func (m *Manager) runDrain(ctx context.Context, id string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.drains.mu.Lock()
+			state, ok := m.drains.byAuth[id]
+			m.drains.mu.Unlock()
+			if !ok {
+				return
+			}
+			if m.activity.inFlight(id) == 0 {
+				m.finishDrain(id, false)
+				return
+			}
+			if !now.Before(state.deadline) {
+				m.finishDrain(id, true)
+				return
+			}
+		}
+	}
+}
+
+// finishDrain ends the drain of the account and disables it.
+func (m *Manager) finishDrain(id string, timedOut bool) {
+	m.drains.mu.Lock()
+	state, ok := m.drains.byAuth[id]
+	if ok {
+		state.cancel()
+		delete(m.drains.byAuth, id)
+	}
+	m.drains.mu.Unlock()
+	if !ok {
+		return
+	}
+	auth, ok := m.GetByID(id)
+	if !ok || auth == nil || auth.Disabled {
+		return
+	}
+	auth.Disabled = true
+	auth.Status = StatusDisabled
+	auth.StatusMessage = "disabled via management API after draining"
+	auth.UpdatedAt = time.Now()
+	if _, err := m.Update(context.Background(), auth); err != nil {
+		log.Warnf("drain of auth %s: disable failed: %v", id, err)
+		return
+	}
+	if timedOut {
+		log.Warnf("drain of auth %s timed out with %d requests in flight; disabled", id, m.activity.inFlight(id))
+	} else {
+		log.Infof("drain of auth %s finished; disabled", id)
+	}
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newDrainManager(t *testing.T, ids ...string) *Manager {
+	t.Helper()
+	prev := drainPollInterval
+	drainPollInterval = time.Millisecond
+	t.Cleanup(func() { drainPollInterval = prev })
+	m := NewManager(nil, nil, nil)
+	for _, id := range ids {
+		if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: "gemini", Status: StatusActive}); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	return m
+}
+
+func TestDrainAccountWaitsForInFlightRequests(t *testing.T) {
+	m := newDrainManager(t, "a", "b")
+	finish := m.beginRequest("a")
+
+	status, err := m.DrainAccount("a", time.Minute)
+	if err != nil || !status.Draining || status.InFlight != 1 || status.Disabled || status.Deadline == nil {
+		t.Fatalf("DrainAccount = %+v, %v", status, err)
+	}
+	m.mu.Lock()
+	candidates := m.selectionCandidatesLocked([]*Auth{m.auths["a"], m.auths["b"]}, "", "", nil, time.Now())
+	m.mu.Unlock()
+	if len(candidates) != 1 || candidates[0].ID != "b" {
+		t.Fatalf("draining account still selectable: %v", candidates)
+	}
+	if again, _ := m.DrainAccount("a", time.Minute); !again.Draining || !again.Deadline.Equal(*status.Deadline) {
+		t.Fatalf("second drain restarted the first: %+v", again)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if auth, _ := m.GetByID("a"); auth.Disabled {
+		t.Fatal("account disabled while a request was in flight")
+	}
+	finish()
+	waitFor(t, func() bool {
+		auth, _ := m.GetByID("a")
+		return auth.Disabled && auth.Status == StatusDisabled && !m.IsDraining("a")
+	})
+}
+
+func TestDrainAccountDisablesIdleAccountImmediately(t *testing.T) {
+	m := newDrainManager(t, "a")
+	status, err := m.DrainAccount("a", 0)
+	if err != nil || status.Draining || !status.Disabled {
+		t.Fatalf("DrainAccount = %+v, %v", status, err)
+	}
+	var authErr *Error
+	if _, err = m.DrainAccount("a", 0); !errors.As(err, &authErr) || authErr.HTTPStatus != http.StatusConflict {
+		t.Fatalf("draining a disabled account: %v", err)
+	}
+	if _, err = m.DrainAccount("missing", 0); !errors.As(err, &authErr) || authErr.HTTPStatus != http.StatusNotFound {
+		t.Fatalf("draining an unknown account: %v", err)
+	}
+}
+
+func TestDrainAccountTimesOut(t *testing.T) {
+	m := newDrainManager(t, "a")
+	finish := m.beginRequest("a")
+	defer finish()
+	if _, err := m.DrainAccount("a", 20*time.Millisecond); err != nil {
+		t.Fatalf("DrainAccount: %v", err)
+	}
+	waitFor(t, func() bool {
+		auth, _ := m.GetByID("a")
+		return auth.Disabled
+	})
+}
+
+func TestCancelDrainKeepsAccountEnabled(t *testing.T) {
+	m := newDrainManager(t, "a")
+	finish := m.beginRequest("a")
+	if _, err := m.DrainAccount("a", time.Minute); err != nil {
+		t.Fatalf("DrainAccount: %v", err)
+	}
+	if !m.CancelDrain("a") || m.IsDraining("a") {
+		t.Fatal("drain not cancelled")
+	}
+	finish()
+	time.Sleep(10 * time.Millisecond)
+	if auth, _ := m.GetByID("a"); auth.Disabled {
+		t.Fatal("cancelled drain disabled the account")
+	}
+}
@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DrillSpec describes a failover drill: the share of accounts taken out of selection and for how long.
+type DrillSpec struct {
+	// Percent of the eligible accounts to cordon, between 0 and 100.
+	Percent float64
+	// Duration is how long the accounts stay cordoned before they are restored.
+	Duration time.Duration
+	// Provider restricts the drill to one provider; empty drills every provider.
+	Provider string
+}
+
+// DrillReport summarises how the pool and client requests held up while a drill ran.
+type DrillReport struct {
+	Provider        string     `json:"provider,omitempty"`
+	Percent         float64    `json:"percent"`
+	StartedAt       time.Time  `json:"started_at"`
+	EndsAt          time.Time  `json:"ends_at"`
+	EndedAt         *time.Time `json:"ended_at,omitempty"`
+	Running         bool       `json:"running"`
+	Aborted         bool       `json:"aborted,omitempty"`
+	PoolSize        int        `json:"pool_size"`
+	Cordoned        []string   `json:"cordoned"`
+	Requests        int64      `json:"requests"`
+	Succeeded       int64      `json:"succeeded"`
+	Failed          int64      `json:"failed"`
+	SuccessRate     float64    `json:"success_rate"`
+	Attempts        int64      `json:"attempts"`
+	AttemptFailures int64      `json:"attempt_failures"`
+}
+
+// failoverDrill holds the running drill and the report of the last one.
+type failoverDrill struct {
+	running  atomic.Bool
+	mu       sync.Mutex
+	report   *DrillReport
+	cordoned map[string]struct{}
+}
+
+func (d *failoverDrill) cordons(authID string) bool {
+	if !d.running.Load() {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, ok := d.cordoned[authID]
+	return ok
+}
+
+// recordRequest counts the outcome a client saw for one request, after failover and retries.
+func (d *failoverDrill) recordRequest(success bool) {
+	if !d.running.Load() {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.report == nil || !d.report.Running {
+		return
+	}
+	d.report.Requests++
+	if success {
+		d.report.Succeeded++
+	} else {
+		d.report.Failed++
+	}
+}
+
+// recordAttempt counts one upstream attempt; failed attempts include those recovered by failover.
+func (d *failoverDrill) recordAttempt(success bool) {
+	if !d.running.Load() {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.report == nil || !d.report.Running {
+		return
+	}
+	d.report.Attempts++
+	if !success {
+		d.report.AttemptFailures++
+	}
+}
+
+// RunDrill cordons a random share of the accounts, waits for the drill to end and restores them. The
+// drill ends early, marked aborted, when ctx is cancelled. Only one drill runs at a time.
+func (m *Manager) RunDrill(ctx context.Context, spec DrillSpec) (DrillReport, error) {
+	if m == nil {
+		return DrillReport{}, &Error{Code: "drill_unavailable", Message: "auth manager not available"}
+	}
+	if spec.Percent <= 0 || spec.Percent > 100 {
+		return DrillReport{}, &Error{Code: "invalid_drill", Message: "percent must be between 0 and 100", HTTPStatus: http.StatusBadRequest}
+	}
+	if spec.Duration <= 0 {
+		return DrillReport{}, &Error{Code: "invalid_drill", Message: "duration must be positive", HTTPStatus: http.StatusBadRequest}
+	}
+	provider := strings.ToLower(strings.TrimSpace(spec.Provider))
+	var eligible []string
+	for _, auth := range m.List() {
+		if auth == nil || auth.Disabled || (provider != "" && !strings.EqualFold(auth.Provider, provider)) || !m.OwnsAccount(auth.ID) {
+			continue
+		}
+		eligible = append(eligible, auth.ID)
+	}
+	if len(eligible) == 0 {
+		return DrillReport{}, &Error{Code: "invalid_drill", Message: "no accounts to drill", HTTPStatus: http.StatusBadRequest}
+	}
+	count := int(math.Ceil(float64(len(eligible)) * spec.Percent / 100))
+	rand.Shuffle(len(eligible), func(i, j int) { eligible[i], eligible[j] = eligible[j], eligible[i] })
+	cordoned := eligible[:count]
+	sort.Strings(cordoned)
+
+	now := time.Now()
+	m.drill.mu.Lock()
+	if m.drill.report != nil && m.drill.report.Running {
+		m.drill.mu.Unlock()
+		return DrillReport{}, &Error{Code: "drill_running", Message: "a drill is already running", HTTPStatus: http.StatusConflict}
+	}
+	m.drill.report = &DrillReport{
+		Provider:  provider,
+		Percent:   spec.Percent,
+		StartedAt: now,
+		EndsAt:    now.Add(spec.Duration),
+		Running:   true,
+		PoolSize:  len(eligible),
+		Cordoned:  cordoned,
+	}
+	m.drill.cordoned = make(map[string]struct{}, len(cordoned))
+	for _, id := range cordoned {
+		m.drill.cordoned[id] = struct{}{}
+	}
+	m.drill.running.Store(true)
+	m.drill.mu.Unlock()
+	log.Warnf("failover drill: cordoned %d of %d accounts for %s", len(cordoned), len(eligible), spec.Duration)
+
+	timer := time.NewTimer(spec.Duration)
+	defer timer.Stop()
+	aborted := false
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		aborted = true
+	}
+
+	m.drill.mu.Lock()
+	defer m.drill.mu.Unlock()
+	m.drill.running.Store(false)
+	m.drill.cordoned = nil
+	ended := time.Now()
+	report := m.drill.report
+	report.Running = false
+	report.Aborted = aborted
+	report.EndedAt = &ended
+	if report.Requests > 0 {
+		report.SuccessRate = float64(report.Succeeded) / float64(report.Requests)
+	}
+	log.Infof("failover drill: restored %d accounts, %d/%d requests succeeded", len(cordoned), report.Succeeded, report.Requests)
+	return report.copy(), nil
+}
+
+// Drill returns the running drill, or the last finished one, and false when none ran yet.
+func (m *Manager) Drill() (DrillReport, bool) {
+	if m == nil {
+		return DrillReport{}, false
+	}
+	m.drill.mu.Lock()
+	defer m.drill.mu.Unlock()
+	if m.drill.report == nil {
+		return DrillReport{}, false
+	}
+	report := m.drill.report.copy()
+	if report.Running && report.Requests > 0 {
+		report.SuccessRate = float64(report.Succeeded) / float64(report.Requests)
+	}
+	return report, true
+}
+
+func (r *DrillReport) copy() DrillReport {
+	out := *r
+	out.Cordoned = append([]string(nil), r.Cordoned...)
+	return out
+}
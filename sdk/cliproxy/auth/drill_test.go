@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestRunDrillCordonsAndRestoresAccounts(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&slowRefreshExecutor{})
+	for _, id := range []string{"a", "b", "c", "d"} {
+		if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: "eager", Status: StatusActive, Metadata: map[string]any{"access_token": id}}); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	if _, err := m.RunDrill(context.Background(), DrillSpec{Percent: 150, Duration: time.Second}); err == nil {
+		t.Fatal("percent above 100 accepted")
+	}
+
+	done := make(chan DrillReport)
+	go func() {
+		report, err := m.RunDrill(context.Background(), DrillSpec{Percent: 50, Duration: 100 * time.Millisecond, Provider: "eager"})
+		if err != nil {
+			t.Errorf("drill: %v", err)
+		}
+		done <- report
+	}()
+	var running DrillReport
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if report, ok := m.Drill(); ok && report.Running {
+			running = report
+			break
+		}
+	}
+	if len(running.Cordoned) != 2 || running.PoolSize != 4 {
+		t.Fatalf("unexpected running drill: %+v", running)
+	}
+	var authErr *Error
+	if _, err := m.RunDrill(context.Background(), DrillSpec{Percent: 10, Duration: time.Second}); !errors.As(err, &authErr) || authErr.Code != "drill_running" {
+		t.Fatalf("concurrent drill: %v", err)
+	}
+	cordoned := map[string]bool{running.Cordoned[0]: true, running.Cordoned[1]: true}
+	for i := 0; i < 6; i++ {
+		resp, err := m.Execute(context.Background(), []string{"eager"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{})
+		if err != nil || cordoned[string(resp.Payload)] {
+			t.Fatalf("request %d served by %q during drill: %v", i, resp.Payload, err)
+		}
+	}
+
+	report := <-done
+	if report.Running || report.EndedAt == nil || report.Requests != 6 || report.Succeeded != 6 || report.SuccessRate != 1 || report.Attempts != 6 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	served := map[string]bool{}
+	for i := 0; i < 8; i++ {
+		resp, _ := m.Execute(context.Background(), []string{"eager"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{})
+		served[string(resp.Payload)] = true
+	}
+	if len(served) != 4 {
+		t.Fatalf("accounts not restored after the drill: %v", served)
+	}
+	if last, _ := m.Drill(); last.Requests != 6 {
+		t.Fatalf("requests after the drill were counted: %+v", last)
+	}
+}
+
+func TestRunDrillAbortsWithContext(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	if _, err := m.Register(context.Background(), &Auth{ID: "a", Provider: "eager", Status: StatusActive}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	report, err := m.RunDrill(ctx, DrillSpec{Percent: 100, Duration: time.Hour})
+	if err != nil || !report.Aborted || m.drill.cordons("a") {
+		t.Fatalf("drill not aborted: %+v %v", report, err)
+	}
+}
@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Duplicate credential handling modes.
+const (
+	// DuplicateModeWarn keeps serving both accounts and flags the duplicate.
+	DuplicateModeWarn = "warn"
+	// DuplicateModeMerge keeps the duplicate registered but out of selection, so capacity is counted once.
+	DuplicateModeMerge = "merge"
+	// DuplicateModeReject refuses to register a duplicate.
+	DuplicateModeReject = "reject"
+)
+
+// DuplicateGroup lists accounts loaded with the same credential.
+type DuplicateGroup struct {
+	Fingerprint string   `json:"fingerprint"`
+	Provider    string   `json:"provider"`
+	Primary     string   `json:"primary"`
+	Duplicates  []string `json:"duplicates"`
+}
+
+// SetDuplicateMode selects how accounts sharing a credential are handled; unknown or empty modes
+// fall back to warn.
+func (m *Manager) SetDuplicateMode(mode string) {
+	if m == nil {
+		return
+	}
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode != DuplicateModeMerge && mode != DuplicateModeReject {
+		mode = DuplicateModeWarn
+	}
+	m.mu.Lock()
+	m.duplicateMode = mode
+	m.reindexDuplicatesLocked()
+	m.mu.Unlock()
+}
+
+// credentialFingerprint identifies the secret behind an auth: its API key (with base URL), refresh
+// token, access token or cookie. Empty means the auth carries no recognisable credential.
+func credentialFingerprint(a *Auth) string {
+	secret := ""
+	if a.Attributes != nil {
+		if key := strings.TrimSpace(a.Attributes["api_key"]); key != "" {
+			secret = "api_key:" + key + "@" + strings.TrimSpace(a.Attributes["base_url"])
+		}
+	}
+	if secret == "" && a.Metadata != nil {
+		token, _ := a.Metadata["token"].(map[string]any)
+		for _, field := range []string{"refresh_token", "access_token", "cookie", "api_key"} {
+			if v := metadataString(a.Metadata, field); v != "" {
+				secret = field + ":" + v
+				break
+			}
+			if v := metadataString(token, field); v != "" {
+				secret = field + ":" + v
+				break
+			}
+		}
+	}
+	if secret == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(strings.ToLower(a.Provider) + "\x00" + secret))
+	return hex.EncodeToString(sum[:8])
+}
+
+func metadataString(meta map[string]any, key string) string {
+	if meta == nil {
+		return ""
+	}
+	v, _ := meta[key].(string)
+	return strings.TrimSpace(v)
+}
+
+// duplicateOfLocked returns the ID of an enabled auth other than auth sharing its credential.
+// Callers must hold m.mu.
+func (m *Manager) duplicateOfLocked(auth *Auth) string {
+	fp := credentialFingerprint(auth)
+	if fp == "" || auth.Disabled {
+		return ""
+	}
+	for id, existing := range m.auths {
+		if id != auth.ID && !existing.Disabled && credentialFingerprint(existing) == fp {
+			return id
+		}
+	}
+	return ""
+}
+
+// rejectsDuplicateLocked reports whether auth must not be registered because reject mode is on and
+// another account already holds its credential. Callers must hold m.mu.
+func (m *Manager) rejectsDuplicateLocked(auth *Auth) (string, bool) {
+	if m.duplicateMode != DuplicateModeReject {
+		return "", false
+	}
+	if _, exists := m.auths[auth.ID]; exists {
+		return "", false
+	}
+	primary := m.duplicateOfLocked(auth)
+	return primary, primary != ""
+}
+
+// reindexDuplicatesLocked recomputes DuplicateOf for every auth. The earliest created enabled auth
+// of each credential is the primary. Callers must hold m.mu.
+func (m *Manager) reindexDuplicatesLocked() {
+	groups := make(map[string][]*Auth)
+	for _, a := range m.auths {
+		a.DuplicateOf = ""
+		if a.Disabled {
+			continue
+		}
+		if fp := credentialFingerprint(a); fp != "" {
+			groups[fp] = append(groups[fp], a)
+		}
+	}
+	for _, members := range groups {
+		if len(members) < 2 {
+			continue
+		}
+		sort.Slice(members, func(i, j int) bool {
+			if !members[i].CreatedAt.Equal(members[j].CreatedAt) {
+				return members[i].CreatedAt.Before(members[j].CreatedAt)
+			}
+			return members[i].ID < members[j].ID
+		})
+		for _, dup := range members[1:] {
+			dup.DuplicateOf = members[0].ID
+		}
+	}
+}
+
+// warnDuplicate logs a newly detected duplicate credential.
+func (m *Manager) warnDuplicate(auth *Auth) {
+	if auth == nil || auth.DuplicateOf == "" {
+		return
+	}
+	m.mu.RLock()
+	mode := m.duplicateMode
+	m.mu.RUnlock()
+	action := "both accounts keep serving"
+	if mode == DuplicateModeMerge {
+		action = "the duplicate is kept out of selection"
+	}
+	log.Warnf("auth %s (%s) uses the same credential as %s; %s", auth.ID, auth.Provider, auth.DuplicateOf, action)
+}
+
+// mergesDuplicate reports whether auth is kept out of selection as a merged duplicate.
+// Callers must hold m.mu.
+func (m *Manager) mergesDuplicate(auth *Auth) bool {
+	return m.duplicateMode == DuplicateModeMerge && auth.DuplicateOf != ""
+}
+
+// Duplicates lists the credentials shared by more than one enabled account.
+func (m *Manager) Duplicates() []DuplicateGroup {
+	if m == nil {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	byPrimary := make(map[string]*DuplicateGroup)
+	for _, a := range m.auths {
+		if a.DuplicateOf == "" {
+			continue
+		}
+		group, ok := byPrimary[a.DuplicateOf]
+		if !ok {
+			group = &DuplicateGroup{Fingerprint: credentialFingerprint(a), Provider: a.Provider, Primary: a.DuplicateOf}
+			byPrimary[a.DuplicateOf] = group
+		}
+		group.Duplicates = append(group.Duplicates, a.ID)
+	}
+	out := make([]DuplicateGroup, 0, len(byPrimary))
+	for _, group := range byPrimary {
+		sort.Strings(group.Duplicates)
+		out = append(out, *group)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Primary < out[j].Primary })
+	return out
+}
+
+// DuplicateMode reports the active duplicate handling mode.
+func (m *Manager) DuplicateMode() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.duplicateMode == "" {
+		return DuplicateModeWarn
+	}
+	return m.duplicateMode
+}
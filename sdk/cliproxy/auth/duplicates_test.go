@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func duplicateAuths() (*Auth, *Auth) {
+	created := time.Now()
+	first := &Auth{ID: "a.json", Provider: "eager", Status: StatusActive, CreatedAt: created,
+		Metadata: map[string]any{"refresh_token": "rt-1", "access_token": "at-1"}}
+	second := &Auth{ID: "imported.json", Provider: "eager", Status: StatusActive, CreatedAt: created.Add(time.Second),
+		Metadata: map[string]any{"token": map[string]any{"refresh_token": "rt-1"}}}
+	return first, second
+}
+
+func TestDuplicateCredentialsMergeKeepsDuplicateOutOfSelection(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&slowRefreshExecutor{})
+	m.SetDuplicateMode(DuplicateModeMerge)
+	first, second := duplicateAuths()
+	for _, a := range []*Auth{first, second} {
+		if _, err := m.Register(context.Background(), a); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	groups := m.Duplicates()
+	if len(groups) != 1 || groups[0].Primary != "a.json" || len(groups[0].Duplicates) != 1 || groups[0].Duplicates[0] != "imported.json" {
+		t.Fatalf("unexpected duplicate groups: %+v", groups)
+	}
+	for i := 0; i < 4; i++ {
+		resp, err := m.Execute(context.Background(), []string{"eager"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{})
+		if err != nil || string(resp.Payload) != "at-1" {
+			t.Fatalf("merged duplicate must not be selected, got %q, %v", resp.Payload, err)
+		}
+	}
+
+	// Disabling the primary promotes the remaining account.
+	first.Disabled = true
+	if _, err := m.Update(context.Background(), first); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if groups = m.Duplicates(); len(groups) != 0 {
+		t.Fatalf("no duplicates should remain, got %+v", groups)
+	}
+}
+
+func TestDuplicateCredentialsRejectAndWarn(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetDuplicateMode(DuplicateModeReject)
+	first, second := duplicateAuths()
+	if _, err := m.Register(context.Background(), first); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if _, err := m.Register(context.Background(), second); err == nil {
+		t.Fatalf("reject mode should refuse the duplicate")
+	}
+	if _, ok := m.GetByID("imported.json"); ok {
+		t.Fatalf("rejected duplicate must not be registered")
+	}
+
+	m.SetDuplicateMode("")
+	registered, err := m.Register(context.Background(), second)
+	if err != nil || registered.DuplicateOf != "a.json" {
+		t.Fatalf("warn mode should register and flag the duplicate, got %+v, %v", registered, err)
+	}
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// refreshFlights coalesces concurrent refreshes of the same auth into one upstream call.
+type refreshFlights struct {
+	// threshold is the remaining token validity below which a selected auth is refreshed before dispatch.
+	threshold atomic.Int64
+	// startupStagger is the window initial refreshes are spread over; staggered marks it applied.
+	startupStagger atomic.Int64
+	staggered      atomic.Bool
+	mu             sync.Mutex
+	inflight       map[string]chan struct{}
+	// started records when each in-flight refresh began.
+	started map[string]time.Time
+}
+
+// SetEagerRefreshThreshold refreshes a selected account's token before dispatch when it has less than
+// threshold validity left. Zero or negative disables eager refresh.
+func (m *Manager) SetEagerRefreshThreshold(threshold time.Duration) {
+	if m == nil {
+		return
+	}
+	if threshold < 0 {
+		threshold = 0
+	}
+	m.refreshes.threshold.Store(int64(threshold))
+}
+
+// refreshCoalesced starts a refresh for id unless one is already running and returns a channel
+// closed when the running refresh finishes.
+func (m *Manager) refreshCoalesced(ctx context.Context, id string) <-chan struct{} {
+	m.refreshes.mu.Lock()
+	defer m.refreshes.mu.Unlock()
+	if done, ok := m.refreshes.inflight[id]; ok {
+		return done
+	}
+	if m.refreshes.inflight == nil {
+		m.refreshes.inflight = make(map[string]chan struct{})
+		m.refreshes.started = make(map[string]time.Time)
+	}
+	done := make(chan struct{})
+	m.refreshes.inflight[id] = done
+	m.refreshes.started[id] = time.Now()
+	go func() {
+		defer func() {
+			m.refreshes.mu.Lock()
+			delete(m.refreshes.inflight, id)
+			delete(m.refreshes.started, id)
+			m.refreshes.mu.Unlock()
+			close(done)
+		}()
+		m.refreshAuth(ctx, id)
+	}()
+	return done
+}
+
+// refreshInFlight returns the completion channel of a running refresh for id, if any.
+func (m *Manager) refreshInFlight(id string) (<-chan struct{}, bool) {
+	m.refreshes.mu.Lock()
+	defer m.refreshes.mu.Unlock()
+	done, ok := m.refreshes.inflight[id]
+	return done, ok
+}
+
+// ensureFreshToken refreshes auth before dispatch when its token expires within the eager refresh
+// threshold and the eager-refresh feature applies to it. Concurrent callers share one refresh; on failure or cancellation the current auth is
+// returned unchanged so the request can still try the remaining validity.
+func (m *Manager) ensureFreshToken(ctx context.Context, auth *Auth) *Auth {
+	threshold := time.Duration(m.refreshes.threshold.Load())
+	if threshold <= 0 || auth == nil || !m.featureEnabled(auth, FeatureEagerRefresh) {
+		return auth
+	}
+	if typ, _ := auth.AccountInfo(); typ == "api_key" {
+		return auth
+	}
+	expiry, ok := auth.ExpirationTime()
+	now := time.Now()
+	if !ok || expiry.Sub(now) > threshold {
+		return auth
+	}
+	if m.executorFor(auth.Provider) == nil {
+		return auth
+	}
+	done, running := m.refreshInFlight(auth.ID)
+	if !running {
+		// markRefreshPending honours the failure backoff, so a broken credential is not retried per request.
+		if !m.markRefreshPending(auth.ID, now) {
+			return auth
+		}
+		done = m.refreshCoalesced(context.WithoutCancel(ctx), auth.ID)
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return auth
+	}
+
+	m.mu.Lock()
+	current := m.auths[auth.ID]
+	if current == nil {
+		m.mu.Unlock()
+		return auth
+	}
+	current.LastEagerRefreshAt = time.Now()
+	refreshed := !current.LastRefreshedAt.Before(now)
+	updated := current.Clone()
+	m.mu.Unlock()
+	if !refreshed {
+		log.Debugf("eager refresh for %s %s did not succeed; dispatching with current token", auth.Provider, auth.ID)
+		return auth
+	}
+	log.Debugf("eager refreshed %s %s (token expires in %s)", auth.Provider, auth.ID, expiry.Sub(now).Round(time.Second))
+	return updated
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// slowRefreshExecutor issues a fresh token after a short delay and echoes the token it executed with.
+type slowRefreshExecutor struct {
+	streamingExecutor
+	refreshes atomic.Int32
+}
+
+func (e *slowRefreshExecutor) Identifier() string { return "eager" }
+
+func (e *slowRefreshExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) {
+	e.refreshes.Add(1)
+	time.Sleep(20 * time.Millisecond)
+	auth.Metadata["access_token"] = "fresh"
+	auth.Metadata["expired"] = time.Now().Add(time.Hour).Format(time.RFC3339)
+	return auth, nil
+}
+
+func (e *slowRefreshExecutor) Execute(_ context.Context, auth *Auth, _ cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	token, _ := auth.Metadata["access_token"].(string)
+	return cliproxyexecutor.Response{Payload: []byte(token)}, nil
+}
+
+func TestExecuteEagerlyRefreshesExpiringToken(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	exec := &slowRefreshExecutor{}
+	m.RegisterExecutor(exec)
+	if _, err := m.Register(context.Background(), &Auth{ID: "a", Provider: "eager", Status: StatusActive, Metadata: map[string]any{
+		"access_token": "stale",
+		"expired":      time.Now().Add(time.Minute).Format(time.RFC3339),
+	}}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	// Disabled by default: the stale token is used as-is.
+	resp, err := m.Execute(context.Background(), []string{"eager"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{})
+	if err != nil || string(resp.Payload) != "stale" || exec.refreshes.Load() != 0 {
+		t.Fatalf("expected no eager refresh when disabled, got %q, %v, refreshes=%d", resp.Payload, err, exec.refreshes.Load())
+	}
+
+	m.SetEagerRefreshThreshold(5 * time.Minute)
+	var wg sync.WaitGroup
+	payloads := make([]string, 5)
+	for i := range payloads {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, errExec := m.Execute(context.Background(), []string{"eager"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{})
+			if errExec != nil {
+				t.Errorf("execute: %v", errExec)
+			}
+			payloads[i] = string(resp.Payload)
+		}(i)
+	}
+	wg.Wait()
+	for i, p := range payloads {
+		if p != "fresh" {
+			t.Fatalf("request %d dispatched with %q, want fresh token", i, p)
+		}
+	}
+	if got := exec.refreshes.Load(); got != 1 {
+		t.Fatalf("expected one coalesced refresh, got %d", got)
+	}
+	if auth, _ := m.GetByID("a"); auth.LastEagerRefreshAt.IsZero() {
+		t.Fatalf("expected last eager refresh to be recorded")
+	}
+}
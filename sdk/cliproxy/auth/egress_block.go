@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultEgressBlockCooldown = 30 * time.Minute
+
+// egressFallbackProxyKey is the metadata key of the proxy an account switches to when its current
+// egress is blocked.
+const egressFallbackProxyKey = "fallback_proxy_url"
+
+// defaultEgressBlockPatterns lists upstream error fragments known to mean the request's source IP or
+// region is refused rather than the credential being bad.
+var defaultEgressBlockPatterns = map[string][]string{
+	billingWildcardProvider: {
+		"unsupported_country_region_territory",
+		"country, region, or territory not supported",
+		"not available in your country",
+		"not available in your region",
+		"user location is not supported",
+		"unsupported location",
+		"request originated from an unsupported region",
+		"access from your ip address is blocked",
+	},
+}
+
+// EgressBlockPolicy controls detection of accounts refused because of the proxy's egress IP or region.
+type EgressBlockPolicy struct {
+	// Enabled toggles detection; when false these errors follow the regular failure handling.
+	Enabled bool
+	// Patterns maps provider keys to case-insensitive message fragments, extending the built-in defaults.
+	// The "*" key applies to all providers.
+	Patterns map[string][]string
+	// Cooldown is how long a blocked account is skipped before it is tried again from the same egress.
+	Cooldown time.Duration
+}
+
+// egressBlocks holds the egress block policy and the accounts that just switched to their fallback proxy.
+type egressBlocks struct {
+	mu       sync.Mutex
+	policy   EgressBlockPolicy
+	patterns map[string][]string
+	rerouted map[string]struct{}
+}
+
+// SetEgressBlockPolicy replaces the egress block detection policy used by MarkResult.
+func (m *Manager) SetEgressBlockPolicy(policy EgressBlockPolicy) {
+	if m == nil {
+		return
+	}
+	if policy.Cooldown <= 0 {
+		policy.Cooldown = defaultEgressBlockCooldown
+	}
+	patterns := make(map[string][]string)
+	if policy.Enabled {
+		for provider, list := range defaultEgressBlockPatterns {
+			patterns[provider] = append(patterns[provider], list...)
+		}
+		for provider, list := range policy.Patterns {
+			key := strings.ToLower(strings.TrimSpace(provider))
+			if key == "" {
+				continue
+			}
+			for _, pattern := range list {
+				if p := strings.ToLower(strings.TrimSpace(pattern)); p != "" {
+					patterns[key] = append(patterns[key], p)
+				}
+			}
+		}
+	}
+	m.egress.mu.Lock()
+	m.egress.policy = policy
+	m.egress.patterns = patterns
+	m.egress.mu.Unlock()
+}
+
+// match reports whether err signals an egress block for provider and returns the cooldown to apply.
+func (e *egressBlocks) match(provider string, err *Error) (time.Duration, bool) {
+	if err == nil {
+		return 0, false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.policy.Enabled || len(e.patterns) == 0 {
+		return 0, false
+	}
+	text := strings.ToLower(err.Code + " " + err.Message)
+	for _, key := range []string{strings.ToLower(provider), billingWildcardProvider} {
+		for _, pattern := range e.patterns[key] {
+			if strings.Contains(text, pattern) {
+				return e.policy.Cooldown, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func (e *egressBlocks) markRerouted(authID string) {
+	e.mu.Lock()
+	if e.rerouted == nil {
+		e.rerouted = make(map[string]struct{})
+	}
+	e.rerouted[authID] = struct{}{}
+	e.mu.Unlock()
+}
+
+// consumeEgressReroute reports whether the auth switched to its fallback proxy on its last result, so
+// the caller may retry it once through the new egress.
+func (m *Manager) consumeEgressReroute(authID string) bool {
+	m.egress.mu.Lock()
+	defer m.egress.mu.Unlock()
+	if _, ok := m.egress.rerouted[authID]; !ok {
+		return false
+	}
+	delete(m.egress.rerouted, authID)
+	return true
+}
+
+// EgressFallbackProxy returns the proxy the account should use when its current egress is blocked.
+func (a *Auth) EgressFallbackProxy() string {
+	if a == nil {
+		return ""
+	}
+	if a.Attributes != nil {
+		if v := strings.TrimSpace(a.Attributes[egressFallbackProxyKey]); v != "" {
+			return v
+		}
+	}
+	if a.Metadata != nil {
+		if v, ok := a.Metadata[egressFallbackProxyKey].(string); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// applyEgressBlock handles an egress block. An account with an unused fallback proxy switches to it
+// and stays selectable; otherwise it moves into the egress_blocked status until the cooldown passes.
+// It reports whether the account was rerouted.
+func applyEgressBlock(auth *Auth, resultErr *Error, cooldown time.Duration, now time.Time) bool {
+	if auth == nil {
+		return false
+	}
+	auth.UpdatedAt = now
+	if resultErr != nil {
+		auth.LastError = cloneError(resultErr)
+	}
+	if fallback := auth.EgressFallbackProxy(); fallback != "" && fallback != strings.TrimSpace(auth.ProxyURL) {
+		auth.ProxyURL = fallback
+		auth.StatusMessage = "egress blocked; switched to fallback proxy"
+		return true
+	}
+	auth.Status = StatusEgressBlocked
+	auth.Unavailable = true
+	auth.NextRetryAfter = now.Add(cooldown)
+	auth.StatusMessage = "blocked from current egress IP or region"
+	return false
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMarkResult_EgressBlockSwitchesToFallbackProxy(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetEgressBlockPolicy(EgressBlockPolicy{Enabled: true})
+	if _, err := m.Register(context.Background(), &Auth{
+		ID:       "a",
+		Provider: "codex",
+		Metadata: map[string]any{"fallback_proxy_url": "socks5://127.0.0.1:1080"},
+	}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	blocked := Result{
+		AuthID:   "a",
+		Provider: "codex",
+		Model:    "gpt-5",
+		Error:    &Error{HTTPStatus: 403, Message: `{"error":{"code":"unsupported_country_region_territory"}}`},
+	}
+
+	m.MarkResult(context.Background(), blocked)
+	auth, _ := m.GetByID("a")
+	if auth.ProxyURL != "socks5://127.0.0.1:1080" || auth.Status == StatusEgressBlocked {
+		t.Fatalf("expected switch to fallback proxy, got proxy=%q status=%s", auth.ProxyURL, auth.Status)
+	}
+	if !m.consumeEgressReroute("a") || m.consumeEgressReroute("a") {
+		t.Fatalf("expected exactly one reroute retry")
+	}
+
+	// Blocked again through the fallback: nothing left to switch to.
+	m.MarkResult(context.Background(), blocked)
+	auth, _ = m.GetByID("a")
+	if auth.Status != StatusEgressBlocked {
+		t.Fatalf("expected status %s, got %s", StatusEgressBlocked, auth.Status)
+	}
+	if blocked, _, next := isAuthBlockedForModel(auth, "gpt-5", time.Now()); !blocked || next.IsZero() {
+		t.Fatalf("expected egress blocked auth to be skipped until cooldown ends")
+	}
+
+	m.MarkResult(context.Background(), Result{AuthID: "a", Provider: "codex", Model: "gpt-5", Success: true})
+	auth, _ = m.GetByID("a")
+	if auth.Status != StatusActive {
+		t.Fatalf("expected success to clear egress block, got %s", auth.Status)
+	}
+}
@@ -0,0 +1,250 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+// Empty response policies: pass the empty response through, retry it on another account, or fail
+// the request.
+const (
+	EmptyResponsePassThrough = "pass-through"
+	EmptyResponseRetry       = "retry"
+	EmptyResponseError       = "error"
+)
+
+// SetEmptyResponsePolicy selects how successful upstream responses without any content are handled.
+// Unknown policies fall back to pass-through.
+func (m *Manager) SetEmptyResponsePolicy(policy string) {
+	if m == nil {
+		return
+	}
+	policy = strings.ToLower(strings.TrimSpace(policy))
+	if policy != EmptyResponseRetry && policy != EmptyResponseError {
+		policy = EmptyResponsePassThrough
+	}
+	m.mu.Lock()
+	m.emptyResponsePolicy = policy
+	m.mu.Unlock()
+}
+
+// emptyPolicy returns the active empty response policy.
+func (m *Manager) emptyPolicy() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.emptyResponsePolicy == "" {
+		return EmptyResponsePassThrough
+	}
+	return m.emptyResponsePolicy
+}
+
+// emptyResponseError is returned to clients, or recorded against the account, when an upstream
+// response carried no content.
+func emptyResponseError() *Error {
+	return &Error{Code: "empty_response", Message: "upstream returned an empty response", Retryable: true, HTTPStatus: http.StatusBadGateway}
+}
+
+// handleEmptyResponse counts an empty response against the account and records the result the
+// policy implies. err is nil when the response passes through; otherwise retry reports whether
+// another account should be tried.
+func (m *Manager) handleEmptyResponse(ctx context.Context, result Result) (retry bool, err error) {
+	m.requestCounts.recordEmpty(result.AuthID)
+	policy := m.emptyPolicy()
+	if policy == EmptyResponsePassThrough {
+		m.MarkResult(ctx, result)
+		return false, nil
+	}
+	rerr := emptyResponseError()
+	result.Success = false
+	result.Error = rerr
+	m.MarkResult(ctx, result)
+	log.Infof("auth %s returned an empty response for model %s (policy %s)", result.AuthID, result.Model, policy)
+	return policy == EmptyResponseRetry, rerr
+}
+
+// isEmptyResponse reports whether a non-streaming payload in any client format carries no content:
+// no choices, no message content, tool calls or output items. Unrecognised payloads are not empty.
+func isEmptyResponse(payload []byte) bool {
+	payload = bytes.TrimSpace(payload)
+	if len(payload) == 0 {
+		return true
+	}
+	if !gjson.ValidBytes(payload) {
+		return false
+	}
+	root := gjson.ParseBytes(payload)
+	if inner := root.Get("response"); inner.IsObject() {
+		root = inner
+	}
+	switch {
+	case root.Get("choices").Exists():
+		for _, choice := range root.Get("choices").Array() {
+			message := choice.Get("message")
+			if !message.Exists() {
+				message = choice.Get("delta")
+			}
+			if hasText(message.Get("content")) || hasText(message.Get("reasoning_content")) || hasText(message.Get("refusal")) ||
+				len(message.Get("tool_calls").Array()) > 0 || message.Get("function_call").Exists() || hasText(choice.Get("text")) {
+				return false
+			}
+		}
+		return true
+	case root.Get("candidates").Exists():
+		for _, candidate := range root.Get("candidates").Array() {
+			if partsHaveContent(candidate.Get("content.parts")) {
+				return false
+			}
+		}
+		return true
+	case root.Get("type").String() == "message" && root.Get("content").Exists():
+		for _, block := range root.Get("content").Array() {
+			if blockHasContent(block) {
+				return false
+			}
+		}
+		return true
+	case root.Get("output").IsArray():
+		for _, item := range root.Get("output").Array() {
+			switch item.Get("type").String() {
+			case "message":
+				for _, part := range item.Get("content").Array() {
+					if hasText(part.Get("text")) || hasText(part.Get("refusal")) {
+						return false
+					}
+				}
+			case "reasoning":
+			default:
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// chunkHasContent reports whether a streamed payload carries content. Payloads may hold several SSE
+// lines; event framing, role-only deltas, pings, usage and lifecycle events carry none. Unrecognised
+// JSON counts as content so unknown formats are never held back.
+func chunkHasContent(payload []byte) bool {
+	for _, line := range bytes.Split(payload, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || bytes.HasPrefix(line, []byte("event:")) || bytes.HasPrefix(line, []byte(":")) {
+			continue
+		}
+		if data, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			line = bytes.TrimSpace(data)
+		}
+		if len(line) == 0 || bytes.Equal(line, []byte("[DONE]")) {
+			continue
+		}
+		if !gjson.ValidBytes(line) {
+			return true
+		}
+		event := gjson.ParseBytes(line)
+		if inner := event.Get("response"); inner.IsObject() && inner.Get("candidates").Exists() {
+			event = inner
+		}
+		switch {
+		case event.Get("choices").Exists():
+			if !isEmptyResponse(line) {
+				return true
+			}
+		case event.Get("candidates").Exists():
+			for _, candidate := range event.Get("candidates").Array() {
+				if partsHaveContent(candidate.Get("content.parts")) {
+					return true
+				}
+			}
+		case event.Get("type").Exists():
+			switch event.Get("type").String() {
+			case "content_block_delta":
+				delta := event.Get("delta")
+				if hasText(delta.Get("text")) || hasText(delta.Get("partial_json")) || hasText(delta.Get("thinking")) {
+					return true
+				}
+			case "content_block_start":
+				if blockHasContent(event.Get("content_block")) || event.Get("content_block.type").String() == "tool_use" {
+					return true
+				}
+			case "response.output_text.delta", "response.function_call_arguments.delta", "response.refusal.delta":
+				if hasText(event.Get("delta")) {
+					return true
+				}
+			case "response.output_item.added", "response.output_item.done":
+				if itemType := event.Get("item.type").String(); itemType != "message" && itemType != "reasoning" {
+					return true
+				}
+			case "error":
+				return true
+			}
+		case event.Get("usageMetadata").Exists() || event.Get("usage").Exists():
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+func hasText(v gjson.Result) bool {
+	switch {
+	case v.Type == gjson.String:
+		return v.String() != ""
+	case v.IsArray():
+		for _, part := range v.Array() {
+			if part.Type == gjson.String && part.String() != "" || hasText(part.Get("text")) || part.Get("type").String() == "image_url" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func partsHaveContent(parts gjson.Result) bool {
+	for _, part := range parts.Array() {
+		if hasText(part.Get("text")) || part.Get("functionCall").Exists() || part.Get("inlineData").Exists() || part.Get("executableCode").Exists() {
+			return true
+		}
+	}
+	return false
+}
+
+func blockHasContent(block gjson.Result) bool {
+	switch block.Get("type").String() {
+	case "text":
+		return hasText(block.Get("text"))
+	case "thinking":
+		return hasText(block.Get("thinking"))
+	case "tool_use", "server_tool_use", "image":
+		return true
+	}
+	return false
+}
+
+// awaitStreamContent reads the stream until a chunk carries content, an error arrives or the stream
+// closes. Chunks read are returned as prefix so they can be replayed. empty is set when the stream
+// closed without any content; failure as in awaitFirstStreamChunk.
+func awaitStreamContent(ctx context.Context, chunks <-chan cliproxyexecutor.StreamChunk) (prefix []cliproxyexecutor.StreamChunk, failure error, empty bool) {
+	for {
+		select {
+		case <-ctx.Done():
+			return prefix, ctx.Err(), false
+		case chunk, ok := <-chunks:
+			if !ok {
+				return prefix, nil, true
+			}
+			if chunk.Err != nil && len(chunk.Payload) == 0 {
+				return prefix, chunk.Err, false
+			}
+			prefix = append(prefix, chunk)
+			if chunk.Err != nil || chunkHasContent(chunk.Payload) {
+				return prefix, nil, false
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestIsEmptyResponse(t *testing.T) {
+	cases := map[string]bool{
+		``:   true,
+		`  `: true,
+		`{"choices":[{"message":{"role":"assistant","content":""}}]}`:                    true,
+		`{"choices":[{"message":{"content":"hi"}}]}`:                                     false,
+		`{"choices":[{"message":{"content":null,"tool_calls":[{"id":"x"}]}}]}`:           false,
+		`{"type":"message","content":[]}`:                                                true,
+		`{"type":"message","content":[{"type":"tool_use","id":"x"}]}`:                    false,
+		`{"candidates":[{"content":{"parts":[{"text":""}]}}]}`:                           true,
+		`{"response":{"candidates":[{"content":{"parts":[{"text":"ok"}]}}]}}`:            false,
+		`{"output":[{"type":"reasoning"}]}`:                                              true,
+		`{"output":[{"type":"message","content":[{"type":"output_text","text":"ok"}]}]}`: false,
+		`{"data":[{"embedding":[0.1]}]}`:                                                 false,
+	}
+	for payload, want := range cases {
+		if got := isEmptyResponse([]byte(payload)); got != want {
+			t.Errorf("isEmptyResponse(%s) = %v, want %v", payload, got, want)
+		}
+	}
+}
+
+func TestChunkHasContent(t *testing.T) {
+	cases := map[string]bool{
+		"data: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n": false,
+		"data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\n":     true,
+		"data: [DONE]\n\n": false,
+		"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{}}\n\n":                                             false,
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"x\"}}": true,
+		"data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"\"}]}}]}":                                                    false,
+		"data: {\"type\":\"response.output_text.delta\",\"delta\":\"x\"}":                                                         true,
+		"plain text": true,
+	}
+	for payload, want := range cases {
+		if got := chunkHasContent([]byte(payload)); got != want {
+			t.Errorf("chunkHasContent(%q) = %v, want %v", payload, got, want)
+		}
+	}
+}
+
+// payloadExecutor returns a fixed non-streaming payload per auth.
+type payloadExecutor struct {
+	streamingExecutor
+	payloads map[string]string
+}
+
+func (e *payloadExecutor) Execute(_ context.Context, auth *Auth, _ cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	e.mu.Lock()
+	e.calls = append(e.calls, auth.ID)
+	e.mu.Unlock()
+	return cliproxyexecutor.Response{Payload: []byte(e.payloads[auth.ID])}, nil
+}
+
+func TestEmptyResponsePolicies(t *testing.T) {
+	empty := `{"choices":[{"message":{"content":""}}]}`
+	full := `{"choices":[{"message":{"content":"hi"}}]}`
+	for _, tc := range []struct {
+		policy    string
+		wantErr   bool
+		wantCalls int
+	}{
+		{EmptyResponsePassThrough, false, 1},
+		{EmptyResponseRetry, false, 2},
+		{EmptyResponseError, true, 1},
+	} {
+		exec := &payloadExecutor{payloads: map[string]string{"a": empty, "b": full}}
+		m := NewManager(nil, nil, nil)
+		m.RegisterExecutor(exec)
+		m.SetEmptyResponsePolicy(tc.policy)
+		for _, id := range []string{"a", "b"} {
+			if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: "streamy"}); err != nil {
+				t.Fatalf("register: %v", err)
+			}
+		}
+		resp, err := m.Execute(context.Background(), []string{"streamy"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{})
+		var rerr *Error
+		if tc.wantErr != (err != nil) || (err != nil && (!errors.As(err, &rerr) || rerr.Code != "empty_response")) {
+			t.Fatalf("%s: unexpected error %v", tc.policy, err)
+		}
+		if tc.policy == EmptyResponseRetry && string(resp.Payload) != full {
+			t.Fatalf("retry: expected payload from b, got %s", resp.Payload)
+		}
+		if len(exec.calls) != tc.wantCalls || exec.calls[0] != "a" {
+			t.Fatalf("%s: unexpected calls %v", tc.policy, exec.calls)
+		}
+		if got := m.RequestCounts("a").EmptyResponses; got != 1 {
+			t.Fatalf("%s: expected one empty response on a, got %d", tc.policy, got)
+		}
+	}
+}
+
+func TestEmptyStreamRetriesOnAnotherAccount(t *testing.T) {
+	exec := &streamingExecutor{scripts: map[string][]cliproxyexecutor.StreamChunk{
+		"a": {{Payload: []byte("data: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n")}, {Payload: []byte("data: [DONE]\n\n")}},
+		"b": {{Payload: []byte("data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\n")}},
+	}}
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(exec)
+	m.SetEmptyResponsePolicy(EmptyResponseRetry)
+	for _, id := range []string{"a", "b"} {
+		if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: "streamy"}); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+
+	payload, streamErr := collectStream(t, m)
+	if streamErr != nil || payload != "data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\n" {
+		t.Fatalf("expected stream from b, got payload=%q err=%v", payload, streamErr)
+	}
+	if len(exec.calls) != 2 || exec.calls[0] != "a" {
+		t.Fatalf("expected failover from a to b, got %v", exec.calls)
+	}
+	if counts := m.RequestCounts("a"); counts.EmptyResponses != 1 || counts.Failures != 1 {
+		t.Fatalf("expected a to record one empty failure, got %+v", counts)
+	}
+}
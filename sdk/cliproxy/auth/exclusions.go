@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+)
+
+// Selector exclusion reasons. Each maps to a predicate pickNext or the selector already applies,
+// so the counts explain exactly why the usable pool is smaller than the registered one.
+const (
+	ExclusionCircuitOpen   = "circuit_open"
+	ExclusionDisabled      = "disabled"
+	ExclusionModelMismatch = "model_mismatch"
+	ExclusionCooldown      = "cooldown"
+	ExclusionUnavailable   = "unavailable"
+	// ExclusionResting accounts are only used when nothing else can serve.
+	ExclusionResting = "resting"
+	// ExclusionPeakReserve accounts are held back until their scheduled peak window.
+	ExclusionPeakReserve = "peak_reserve"
+	// ExclusionReserved accounts only serve requests naming their reservation.
+	ExclusionReserved = "reserved"
+	// ExclusionDuplicate accounts share a credential with another account and are merged into it.
+	ExclusionDuplicate = "duplicate"
+	// ExclusionDrillCordoned accounts are taken out by a running failover drill.
+	ExclusionDrillCordoned = "drill_cordoned"
+	// ExclusionDraining accounts finish their in-flight requests before being disabled.
+	ExclusionDraining = "draining"
+	// ExclusionModelWindow accounts serve the model only inside scheduled windows.
+	ExclusionModelWindow = "model_window"
+	// ExclusionRateLimitCordoned accounts are close to their rate limit and only used when nothing
+	// else can serve.
+	ExclusionRateLimitCordoned = "rate_limit_cordoned"
+	// ExclusionSaturated accounts are at their concurrency limit; requests queue for them when
+	// nothing else can serve.
+	ExclusionSaturated = "saturated"
+)
+
+// selectionTier ranks how selection treats an account. selectionCandidatesLocked only falls back to a
+// tier when every earlier one is empty.
+type selectionTier int
+
+const (
+	tierAvailable selectionTier = iota
+	tierResting
+	tierCordoned
+	tierSaturated
+	// tierBlocked accounts are cooling down or otherwise unavailable. They are only handed to the
+	// selector when no account can serve, so it reports the cooldown and when it ends.
+	tierBlocked
+	// tierExcluded accounts never serve the request.
+	tierExcluded
+)
+
+// SelectorExclusions aggregates how many accounts are currently excluded from selection, per reason.
+type SelectorExclusions struct {
+	Total     int            `json:"total"`
+	Available int            `json:"available"`
+	Excluded  int            `json:"excluded"`
+	Reasons   map[string]int `json:"reasons"`
+}
+
+// SelectorExclusionReport is the pool-wide exclusion summary plus a per-provider breakdown.
+type SelectorExclusionReport struct {
+	Model     string                        `json:"model,omitempty"`
+	Overall   SelectorExclusions            `json:"overall"`
+	Providers map[string]SelectorExclusions `json:"providers"`
+}
+
+func newSelectorExclusions() SelectorExclusions {
+	return SelectorExclusions{Reasons: map[string]int{
+		ExclusionCircuitOpen:       0,
+		ExclusionDisabled:          0,
+		ExclusionModelMismatch:     0,
+		ExclusionCooldown:          0,
+		ExclusionUnavailable:       0,
+		ExclusionResting:           0,
+		ExclusionPeakReserve:       0,
+		ExclusionReserved:          0,
+		ExclusionDuplicate:         0,
+		ExclusionDrillCordoned:     0,
+		ExclusionDraining:          0,
+		ExclusionModelWindow:       0,
+		ExclusionSaturated:         0,
+		ExclusionRateLimitCordoned: 0,
+	}}
+}
+
+func (s *SelectorExclusions) add(reason string) {
+	s.Total++
+	if reason == "" {
+		s.Available++
+		return
+	}
+	s.Excluded++
+	s.Reasons[reason]++
+}
+
+// SelectorExclusions reports why accounts are skipped right now. provider and model are optional
+// filters; without a model, per-model cooldowns and registry support are not considered, matching
+// how the selector treats model-less requests.
+func (m *Manager) SelectorExclusions(provider, model string) SelectorExclusionReport {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	model = strings.TrimSpace(model)
+	report := SelectorExclusionReport{Model: model, Overall: newSelectorExclusions(), Providers: make(map[string]SelectorExclusions)}
+	if m == nil {
+		return report
+	}
+	now := time.Now()
+	open := make(map[string]struct{})
+	for _, st := range m.CircuitBreakers() {
+		if st.State == CircuitOpen && (st.NextProbeAt == nil || now.Before(*st.NextProbeAt)) {
+			open[st.Provider] = struct{}{}
+		}
+	}
+	registryRef := registry.GetGlobalRegistry()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, auth := range m.auths {
+		if auth == nil || (provider != "" && auth.Provider != provider) {
+			continue
+		}
+		reason := m.exclusionReasonLocked(auth, model, open, registryRef, now)
+		report.Overall.add(reason)
+		entry, ok := report.Providers[auth.Provider]
+		if !ok {
+			entry = newSelectorExclusions()
+		}
+		entry.add(reason)
+		report.Providers[auth.Provider] = entry
+	}
+	return report
+}
+
+// exclusionReasonLocked returns the first selection predicate auth fails, or "" when the account is
+// selectable. Callers must hold m.mu.
+func (m *Manager) exclusionReasonLocked(auth *Auth, model string, open map[string]struct{}, registryRef *registry.ModelRegistry, now time.Time) string {
+	if _, ok := open[auth.Provider]; ok {
+		return ExclusionCircuitOpen
+	}
+	_, reason := m.selectionTierLocked(auth, model, "", registryRef, now)
+	return reason
+}
+
+// selectionTierLocked applies the selection predicates to auth for a request for model naming
+// reservation. It returns the account's tier and, for every tier but tierAvailable, the reason.
+// The provider circuit breaker and already tried accounts are left to the callers.
+// Callers must hold m.mu.
+func (m *Manager) selectionTierLocked(auth *Auth, model, reservation string, registryRef *registry.ModelRegistry, now time.Time) (selectionTier, string) {
+	model, served := m.capabilities.resolve(auth, model, registryRef, now)
+	switch {
+	case auth.Disabled:
+		return tierExcluded, ExclusionDisabled
+	case m.mergesDuplicate(auth):
+		return tierExcluded, ExclusionDuplicate
+	case m.drill.cordons(auth.ID):
+		return tierExcluded, ExclusionDrillCordoned
+	case m.drains.draining(auth.ID):
+		return tierExcluded, ExclusionDraining
+	case !served:
+		return tierExcluded, ExclusionModelMismatch
+	case model != "" && registryRef != nil && !registryRef.ClientSupportsModel(auth.ID, model):
+		return tierExcluded, ExclusionModelMismatch
+	case model != "" && !auth.modelWindowOpen(model, now):
+		return tierExcluded, ExclusionModelWindow
+	case m.peakReserve.holdsBack(auth, now):
+		return tierExcluded, ExclusionPeakReserve
+	case !m.reservations.admits(auth, reservation, now):
+		return tierExcluded, ExclusionReserved
+	}
+	if blocked, reason, _ := isAuthBlockedForModel(auth, model, now); blocked {
+		switch reason {
+		case blockReasonCooldown:
+			return tierBlocked, ExclusionCooldown
+		case blockReasonDisabled:
+			return tierBlocked, ExclusionDisabled
+		default:
+			return tierBlocked, ExclusionUnavailable
+		}
+	}
+	switch {
+	case m.concurrency.full(auth.ID, auth.MaxConcurrent()):
+		return tierSaturated, ExclusionSaturated
+	case m.rateLimits.cordoned(auth.ID, now):
+		return tierCordoned, ExclusionRateLimitCordoned
+	case m.softRotation.Enabled && auth.isResting(now):
+		return tierResting, ExclusionResting
+	}
+	return tierAvailable, ""
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSelectorExclusionsCountsReasons(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	now := time.Now()
+	auths := []*Auth{
+		{ID: "ok", Provider: "codex"},
+		{ID: "off", Provider: "codex", Disabled: true, Status: StatusDisabled},
+		{ID: "cool", Provider: "codex", Unavailable: true, NextRetryAfter: now.Add(time.Minute), Quota: QuotaState{Exceeded: true}},
+		{ID: "err", Provider: "codex", Unavailable: true, NextRetryAfter: now.Add(time.Minute)},
+		{ID: "other", Provider: "claude"},
+	}
+	for _, a := range auths {
+		if _, err := m.Register(context.Background(), a); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+
+	report := m.SelectorExclusions("codex", "")
+	got := report.Overall
+	if got.Total != 4 || got.Available != 1 || got.Excluded != 3 {
+		t.Fatalf("unexpected totals %+v", got)
+	}
+	if got.Reasons[ExclusionDisabled] != 1 || got.Reasons[ExclusionCooldown] != 1 || got.Reasons[ExclusionUnavailable] != 1 {
+		t.Fatalf("unexpected reasons %v", got.Reasons)
+	}
+	if _, ok := report.Providers["claude"]; ok {
+		t.Fatalf("provider filter not applied")
+	}
+	if all := m.SelectorExclusions("", ""); all.Overall.Total != 5 || all.Providers["claude"].Available != 1 {
+		t.Fatalf("unexpected unfiltered report %+v", all)
+	}
+}
+
+func TestSelectorExclusionsAgreeWithSelection(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetDuplicateMode(DuplicateModeMerge)
+	m.SetSoftRotationPolicy(SoftRotationPolicy{Enabled: true, Requests: 1, Rest: time.Minute})
+	m.SetRateLimitPolicy(RateLimitPolicy{Enabled: true})
+	now := time.Now()
+	auths := []*Auth{
+		{ID: "ok", Provider: "codex"},
+		{ID: "off", Provider: "codex", Disabled: true, Status: StatusDisabled},
+		{ID: "dup", Provider: "codex"},
+		{ID: "drain", Provider: "codex"},
+		{ID: "held", Provider: "codex"},
+		{ID: "cool", Provider: "codex", Unavailable: true, NextRetryAfter: now.Add(time.Minute), Quota: QuotaState{Exceeded: true}},
+		{ID: "full", Provider: "codex", Metadata: map[string]any{maxConcurrentMetadataKey: 1}},
+		{ID: "cordon", Provider: "codex"},
+		{ID: "rest", Provider: "codex", RotationRestUntil: now.Add(time.Minute)},
+	}
+	for _, a := range auths {
+		if _, err := m.Register(context.Background(), a); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	if _, err := m.Reserve("batch", []string{"held"}, time.Hour); err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+	m.auths["dup"].DuplicateOf = "ok"
+	m.drains.byAuth = map[string]*drainState{"drain": {started: now}}
+	m.concurrency.acquire("full", 1)
+	until := now.Add(time.Minute)
+	m.rateLimits.observed = map[string]*RateLimitObservation{"cordon": {CordonedUntil: &until}}
+
+	report := m.SelectorExclusions("codex", "")
+	for _, reason := range []string{ExclusionDisabled, ExclusionDuplicate, ExclusionDraining, ExclusionReserved, ExclusionCooldown, ExclusionSaturated, ExclusionRateLimitCordoned, ExclusionResting} {
+		if report.Overall.Reasons[reason] != 1 {
+			t.Errorf("reason %s counted %d times, want 1: %v", reason, report.Overall.Reasons[reason], report.Overall.Reasons)
+		}
+	}
+	if report.Overall.Available != 1 {
+		t.Fatalf("available = %d, want 1", report.Overall.Available)
+	}
+
+	m.mu.RLock()
+	candidates := m.selectionCandidatesLocked(m.providerPoolLocked("codex"), "", "", nil, now)
+	m.mu.RUnlock()
+	selectable, err := availableAuths("codex", "", candidates, now)
+	if err != nil || len(selectable) != 1 || selectable[0].ID != "ok" {
+		t.Fatalf("selectable = %v, %v; want only the account the report counts as available", selectable, err)
+	}
+}
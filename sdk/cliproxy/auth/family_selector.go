@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// StrategyFamilyBalanced rotates across account families first and across a family's accounts second.
+const StrategyFamilyBalanced = "family-balanced"
+
+// FamilyBalancedSelector balances load across account families so quotas shared by a family deplete
+// evenly, then round-robins within the chosen family. Accounts without a family count as a family of
+// their own.
+type FamilyBalancedSelector struct {
+	mu       sync.Mutex
+	families map[string]int
+	members  map[string]int
+}
+
+// familyKey groups an auth by family; accounts outside a family get a key of their own.
+func familyKey(a *Auth) string {
+	if family := a.Family(); family != "" {
+		return "family:" + family
+	}
+	return "auth:" + a.ID
+}
+
+// Pick selects the next family in rotation and the next available account within it.
+func (s *FamilyBalancedSelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	_ = ctx
+	_ = opts
+	return s.next(provider, model, auths, true)
+}
+
+// Peek reports the auth Pick would return without advancing the rotation.
+func (s *FamilyBalancedSelector) Peek(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	_ = ctx
+	_ = opts
+	return s.next(provider, model, auths, false)
+}
+
+func (s *FamilyBalancedSelector) next(provider, model string, auths []*Auth, advance bool) (*Auth, error) {
+	available, err := availableAuths(provider, model, auths, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	grouped := make(map[string][]*Auth)
+	keys := make([]string, 0, len(available))
+	for _, candidate := range available {
+		key := familyKey(candidate)
+		if _, ok := grouped[key]; !ok {
+			keys = append(keys, key)
+		}
+		grouped[key] = append(grouped[key], candidate)
+	}
+	sort.Strings(keys)
+
+	scope := provider + ":" + model
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.families == nil {
+		s.families = make(map[string]int)
+		s.members = make(map[string]int)
+	}
+	familyIndex := s.families[scope]
+	key := keys[familyIndex%len(keys)]
+	members := grouped[key]
+	memberScope := scope + ":" + key
+	memberIndex := s.members[memberScope]
+	if advance {
+		s.families[scope] = (familyIndex + 1) % 2_147_483_640
+		s.members[memberScope] = (memberIndex + 1) % 2_147_483_640
+	}
+	return members[memberIndex%len(members)], nil
+}
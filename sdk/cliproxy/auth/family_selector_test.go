@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestFamilyBalancedSelectorSpreadsAcrossFamilies(t *testing.T) {
+	// Family "big" has three accounts, family "small" one, and "solo" has no family.
+	auths := []*Auth{
+		{ID: "b1", Attributes: map[string]string{"family": "big"}},
+		{ID: "b2", Attributes: map[string]string{"family": "big"}},
+		{ID: "b3", Attributes: map[string]string{"family": "big"}},
+		{ID: "s1", Attributes: map[string]string{"family": "small"}},
+		{ID: "solo"},
+	}
+	selector := &FamilyBalancedSelector{}
+	picks := make(map[string]int)
+	for i := 0; i < 36; i++ {
+		picked, err := selector.Pick(context.Background(), "codex", "gpt-5", cliproxyexecutor.Options{}, auths)
+		if err != nil {
+			t.Fatalf("pick: %v", err)
+		}
+		picks[picked.ID]++
+	}
+	big := picks["b1"] + picks["b2"] + picks["b3"]
+	if big != 12 || picks["s1"] != 12 || picks["solo"] != 12 {
+		t.Fatalf("families should share load evenly, got %v", picks)
+	}
+	if picks["b1"] != picks["b2"] || picks["b2"] != picks["b3"] {
+		t.Fatalf("accounts within a family should rotate, got %v", picks)
+	}
+}
+
+func TestSetSelectionStrategy(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	if err := m.SetSelectionStrategy("family-balanced"); err != nil {
+		t.Fatalf("set strategy: %v", err)
+	}
+	selector := m.selector
+	if _, ok := selector.(*FamilyBalancedSelector); !ok {
+		t.Fatalf("expected family balanced selector, got %T", selector)
+	}
+	if err := m.SetSelectionStrategy("family-balanced"); err != nil || m.selector != selector {
+		t.Fatalf("re-applying the same strategy must keep the selector")
+	}
+	if err := m.SetSelectionStrategy(""); err != nil || m.selector != selector {
+		t.Fatalf("an empty strategy must keep the selector")
+	}
+	if err := m.SetSelectionStrategy("least-busy"); err == nil {
+		t.Fatalf("unknown strategies should be rejected")
+	}
+}
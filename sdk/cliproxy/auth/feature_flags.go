@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Feature flags consulted by experimental subsystems. An account's flag overrides the default, which is
+// on unless the feature is gated.
+const (
+	// FeatureEagerRefresh refreshes the account's token before dispatch when it is about to expire.
+	FeatureEagerRefresh = "eager-refresh"
+	// FeaturePrefixAffinity lets prompt-prefix affinity route requests to the account.
+	FeaturePrefixAffinity = "prefix-affinity"
+)
+
+// featureFlagsMetadataKey mirrors Auth.FeatureFlags in metadata so file-backed accounts keep them across reloads.
+const featureFlagsMetadataKey = "feature_flags"
+
+// KnownFeatureFlags lists the flag names accounts may carry.
+var KnownFeatureFlags = []string{FeatureEagerRefresh, FeaturePrefixAffinity}
+
+// featureGates holds the features that are off for accounts without an explicit flag.
+type featureGates struct {
+	mu    sync.RWMutex
+	gated map[string]struct{}
+}
+
+// NormalizeFeatureFlags lowercases flag names and rejects names no subsystem consults.
+func NormalizeFeatureFlags(flags map[string]bool) (map[string]bool, error) {
+	if len(flags) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]bool, len(flags))
+	for name, enabled := range flags {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if !isKnownFeature(key) {
+			return nil, fmt.Errorf("unknown feature flag %q", name)
+		}
+		out[key] = enabled
+	}
+	return out, nil
+}
+
+func isKnownFeature(name string) bool {
+	for _, known := range KnownFeatureFlags {
+		if known == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SetGatedFeatures turns the listed features off for every account that does not enable them with a
+// flag, so they can be rolled out account by account. Unknown names are ignored.
+func (m *Manager) SetGatedFeatures(features []string) {
+	if m == nil {
+		return
+	}
+	gated := make(map[string]struct{}, len(features))
+	for _, feature := range features {
+		if key := strings.ToLower(strings.TrimSpace(feature)); isKnownFeature(key) {
+			gated[key] = struct{}{}
+		}
+	}
+	m.features.mu.Lock()
+	m.features.gated = gated
+	m.features.mu.Unlock()
+}
+
+// GatedFeatures returns the gated feature names in order.
+func (m *Manager) GatedFeatures() []string {
+	if m == nil {
+		return nil
+	}
+	m.features.mu.RLock()
+	defer m.features.mu.RUnlock()
+	out := make([]string, 0, len(m.features.gated))
+	for feature := range m.features.gated {
+		out = append(out, feature)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// featureEnabled reports whether feature applies to auth: its flag when set, otherwise on unless gated.
+func (m *Manager) featureEnabled(auth *Auth, feature string) bool {
+	if auth != nil {
+		if enabled, ok := auth.FeatureFlags[feature]; ok {
+			return enabled
+		}
+	}
+	m.features.mu.RLock()
+	_, gated := m.features.gated[feature]
+	m.features.mu.RUnlock()
+	return !gated
+}
+
+// ActiveFeatures lists the known features that apply to auth after gates and flags, in order.
+func (m *Manager) ActiveFeatures(auth *Auth) []string {
+	if m == nil || auth == nil {
+		return nil
+	}
+	var out []string
+	for _, feature := range KnownFeatureFlags {
+		if m.featureEnabled(auth, feature) {
+			out = append(out, feature)
+		}
+	}
+	return out
+}
+
+// withFeature returns the candidates feature applies to.
+func (m *Manager) withFeature(candidates []*Auth, feature string) []*Auth {
+	out := make([]*Auth, 0, len(candidates))
+	for _, candidate := range candidates {
+		if m.featureEnabled(candidate, feature) {
+			out = append(out, candidate)
+		}
+	}
+	return out
+}
+
+// SetFeatureFlags replaces the auth's feature flags, mirroring them into metadata.
+func (a *Auth) SetFeatureFlags(flags map[string]bool) {
+	if a == nil {
+		return
+	}
+	if len(flags) == 0 {
+		a.FeatureFlags = nil
+		if a.Metadata != nil {
+			delete(a.Metadata, featureFlagsMetadataKey)
+		}
+		return
+	}
+	a.FeatureFlags = cloneFeatureFlags(flags)
+	if a.Metadata != nil {
+		mirrored := make(map[string]any, len(flags))
+		for name, enabled := range flags {
+			mirrored[name] = enabled
+		}
+		a.Metadata[featureFlagsMetadataKey] = mirrored
+	}
+}
+
+// syncFeatureFlagsFromMetadata hydrates Auth.FeatureFlags from persisted metadata when the field is unset.
+func syncFeatureFlagsFromMetadata(a *Auth) {
+	if a == nil || len(a.FeatureFlags) > 0 || a.Metadata == nil {
+		return
+	}
+	var flags map[string]bool
+	switch raw := a.Metadata[featureFlagsMetadataKey].(type) {
+	case map[string]bool:
+		flags = raw
+	case map[string]any:
+		flags = make(map[string]bool, len(raw))
+		for name, value := range raw {
+			if enabled, ok := value.(bool); ok {
+				flags[name] = enabled
+			}
+		}
+	}
+	if normalized, err := NormalizeFeatureFlags(flags); err == nil {
+		a.FeatureFlags = normalized
+	}
+}
+
+func cloneFeatureFlags(flags map[string]bool) map[string]bool {
+	if len(flags) == 0 {
+		return nil
+	}
+	out := make(map[string]bool, len(flags))
+	for name, enabled := range flags {
+		out[name] = enabled
+	}
+	return out
+}
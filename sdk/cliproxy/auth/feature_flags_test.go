@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestGatedEagerRefreshOnlyForFlaggedAccounts(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	exec := &slowRefreshExecutor{}
+	m.RegisterExecutor(exec)
+	expiring := func() map[string]any {
+		return map[string]any{"access_token": "stale", "expired": time.Now().Add(time.Minute).Format(time.RFC3339)}
+	}
+	if _, err := m.Register(context.Background(), &Auth{ID: "a", Provider: "eager", Status: StatusActive, Metadata: expiring()}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	m.SetEagerRefreshThreshold(5 * time.Minute)
+	m.SetGatedFeatures([]string{"Eager-Refresh", "unknown"})
+	if got := m.GatedFeatures(); len(got) != 1 || got[0] != FeatureEagerRefresh {
+		t.Fatalf("gated features = %v", got)
+	}
+
+	resp, err := m.Execute(context.Background(), []string{"eager"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{})
+	if err != nil || string(resp.Payload) != "stale" || exec.refreshes.Load() != 0 {
+		t.Fatalf("gated feature ran for an unflagged account: %q, %v, refreshes=%d", resp.Payload, err, exec.refreshes.Load())
+	}
+
+	auth, _ := m.GetByID("a")
+	auth.SetFeatureFlags(map[string]bool{FeatureEagerRefresh: true})
+	if _, err = m.Update(context.Background(), auth); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	resp, err = m.Execute(context.Background(), []string{"eager"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{})
+	if err != nil || string(resp.Payload) != "fresh" || exec.refreshes.Load() != 1 {
+		t.Fatalf("flagged account not refreshed: %q, %v, refreshes=%d", resp.Payload, err, exec.refreshes.Load())
+	}
+	if active := m.ActiveFeatures(auth); len(active) != 2 {
+		t.Fatalf("active features = %v", active)
+	}
+}
+
+func TestFeatureFlagsSurviveMetadataReload(t *testing.T) {
+	if _, err := NormalizeFeatureFlags(map[string]bool{"warp-drive": true}); err == nil {
+		t.Fatal("unknown flag accepted")
+	}
+	auth := &Auth{ID: "a", Metadata: map[string]any{}}
+	auth.SetFeatureFlags(map[string]bool{FeaturePrefixAffinity: false})
+
+	reloaded := &Auth{ID: "a", Metadata: map[string]any{featureFlagsMetadataKey: map[string]any{"PREFIX-AFFINITY": false}}}
+	syncFeatureFlagsFromMetadata(reloaded)
+	if enabled, ok := reloaded.FeatureFlags[FeaturePrefixAffinity]; !ok || enabled {
+		t.Fatalf("flags not restored: %v", reloaded.FeatureFlags)
+	}
+
+	m := NewManager(nil, nil, nil)
+	if m.featureEnabled(reloaded, FeaturePrefixAffinity) || !m.featureEnabled(reloaded, FeatureEagerRefresh) {
+		t.Fatal("an account flag overrides the default, unflagged features stay on")
+	}
+	auth.SetFeatureFlags(nil)
+	if _, ok := auth.Metadata[featureFlagsMetadataKey]; ok || auth.FeatureFlags != nil {
+		t.Fatalf("clearing flags left %v", auth.Metadata)
+	}
+}
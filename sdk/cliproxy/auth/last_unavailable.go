@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// maxUnavailableReasonLen bounds reasons taken from free-form upstream messages.
+const maxUnavailableReasonLen = 120
+
+// recordUnavailableLocked remembers why the auth failed. The reason survives recovery until the
+// next failure replaces it. Callers must hold m.mu.
+func recordUnavailableLocked(auth *Auth, err *Error, now time.Time) {
+	auth.LastUnavailableReason = unavailableReason(err)
+	auth.LastUnavailableAt = now
+}
+
+// unavailableReason condenses a failure into a short reason: the error code, the provider's error
+// status or type (e.g. RESOURCE_EXHAUSTED), the HTTP status, or a truncated message.
+func unavailableReason(err *Error) string {
+	if err == nil {
+		return "unknown"
+	}
+	if code := strings.TrimSpace(err.Code); code != "" {
+		return code
+	}
+	msg := strings.TrimSpace(err.Message)
+	if gjson.Valid(msg) {
+		for _, path := range []string{"error.status", "error.type", "error.code", "0.error.status"} {
+			if v := gjson.Get(msg, path); v.Exists() && v.String() != "" {
+				return v.String()
+			}
+		}
+	}
+	if status := err.StatusCode(); status > 0 {
+		if text := http.StatusText(status); text != "" {
+			return strconv.Itoa(status) + " " + text
+		}
+		return strconv.Itoa(status)
+	}
+	if msg == "" {
+		return "unknown"
+	}
+	if len(msg) > maxUnavailableReasonLen {
+		msg = msg[:maxUnavailableReasonLen] + "..."
+	}
+	return msg
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestLastUnavailableReasonSurvivesRecovery(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	if _, err := m.Register(context.Background(), &Auth{ID: "a", Provider: "gemini", Status: StatusActive}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	m.MarkResult(context.Background(), Result{AuthID: "a", Provider: "gemini", Model: "m", Error: &Error{
+		HTTPStatus: http.StatusTooManyRequests,
+		Message:    `{"error":{"code":429,"message":"Quota exceeded","status":"RESOURCE_EXHAUSTED"}}`,
+	}})
+	m.MarkResult(context.Background(), Result{AuthID: "a", Provider: "gemini", Model: "m", Success: true})
+
+	auth, _ := m.GetByID("a")
+	if auth.Unavailable || auth.LastError != nil {
+		t.Fatalf("auth should have recovered, got %+v", auth)
+	}
+	if auth.LastUnavailableReason != "RESOURCE_EXHAUSTED" || auth.LastUnavailableAt.IsZero() {
+		t.Fatalf("last unavailable reason lost after recovery: %q at %v", auth.LastUnavailableReason, auth.LastUnavailableAt)
+	}
+
+	// Re-syncing the auth from disk keeps the summary.
+	if _, err := m.Update(context.Background(), &Auth{ID: "a", Provider: "gemini", Status: StatusActive}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if auth, _ = m.GetByID("a"); auth.LastUnavailableReason != "RESOURCE_EXHAUSTED" {
+		t.Fatalf("update dropped the last unavailable reason: %q", auth.LastUnavailableReason)
+	}
+}
+
+func TestUnavailableReason(t *testing.T) {
+	cases := map[string]*Error{
+		"auth_not_found":           {Code: "auth_not_found", Message: "x"},
+		"overloaded_error":         {Message: `{"type":"error","error":{"type":"overloaded_error"}}`, HTTPStatus: 529},
+		"503 Service Unavailable":  {Message: "upstream busy", HTTPStatus: http.StatusServiceUnavailable},
+		"connection reset by peer": {Message: "connection reset by peer"},
+		"unknown":                  nil,
+	}
+	for want, err := range cases {
+		if got := unavailableReason(err); got != want {
+			t.Errorf("unavailableReason(%+v) = %q, want %q", err, got, want)
+		}
+	}
+}
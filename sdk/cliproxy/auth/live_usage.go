@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/tidwall/gjson"
+)
+
+// defaultLiveUsageInterval is how often running stream usage is reported when no interval is set.
+const defaultLiveUsageInterval = 5 * time.Second
+
+// LiveUsagePolicy configures running usage reports of in-flight streams.
+type LiveUsagePolicy struct {
+	Enabled bool
+	// Interval is how often running usage is reported (default 5s).
+	Interval time.Duration
+	// APIKeys lists client keys whose streams are always tracked; other requests opt in per request.
+	APIKeys []string
+}
+
+// LiveStreamUsage is the running usage estimate of one stream.
+type LiveStreamUsage struct {
+	ID              uint64    `json:"id"`
+	AuthID          string    `json:"auth_id"`
+	Provider        string    `json:"provider"`
+	Model           string    `json:"model"`
+	StartedAt       time.Time `json:"started_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	EstimatedTokens int64     `json:"estimated_output_tokens"`
+	// Done marks a finished stream; it stays in reports for two intervals after it ends so every
+	// subscriber sees the final estimate.
+	Done bool `json:"done"`
+}
+
+// liveStream accumulates the output characters of one tracked stream.
+type liveStream struct {
+	usage LiveStreamUsage
+	chars int64
+}
+
+// liveUsage tracks the streams that report running usage.
+type liveUsage struct {
+	mu      sync.Mutex
+	policy  LiveUsagePolicy
+	keys    map[string]struct{}
+	nextID  uint64
+	streams map[uint64]*liveStream
+}
+
+// SetLiveUsagePolicy configures running usage reports of in-flight streams.
+func (m *Manager) SetLiveUsagePolicy(policy LiveUsagePolicy) {
+	if m == nil {
+		return
+	}
+	if policy.Interval <= 0 {
+		policy.Interval = defaultLiveUsageInterval
+	}
+	keys := make(map[string]struct{}, len(policy.APIKeys))
+	for _, key := range policy.APIKeys {
+		if key = strings.TrimSpace(key); key != "" {
+			keys[key] = struct{}{}
+		}
+	}
+	m.liveUsage.mu.Lock()
+	m.liveUsage.policy, m.liveUsage.keys = policy, keys
+	m.liveUsage.mu.Unlock()
+}
+
+// LiveUsageInterval returns how often running stream usage should be reported, or 0 when reporting
+// is off.
+func (m *Manager) LiveUsageInterval() time.Duration {
+	if m == nil {
+		return 0
+	}
+	m.liveUsage.mu.Lock()
+	defer m.liveUsage.mu.Unlock()
+	if !m.liveUsage.policy.Enabled {
+		return 0
+	}
+	return m.liveUsage.policy.Interval
+}
+
+// begin starts tracking a stream when reporting is on and the request opted in, either per request
+// or through its client key. It returns nil for untracked streams.
+func (u *liveUsage) begin(opts cliproxyexecutor.Options, auth *Auth, provider, model string, now time.Time) *liveStream {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if !u.policy.Enabled {
+		return nil
+	}
+	requested, _ := opts.Metadata[cliproxyexecutor.LiveUsageMetadataKey].(bool)
+	if !requested {
+		key, _ := opts.Metadata[cliproxyexecutor.ClientKeyMetadataKey].(string)
+		if _, ok := u.keys[key]; !ok || key == "" {
+			return nil
+		}
+	}
+	if u.streams == nil {
+		u.streams = make(map[uint64]*liveStream)
+	}
+	u.pruneLocked(now)
+	u.nextID++
+	stream := &liveStream{usage: LiveStreamUsage{ID: u.nextID, AuthID: auth.ID, Provider: provider, Model: model, StartedAt: now, UpdatedAt: now}}
+	u.streams[stream.usage.ID] = stream
+	return stream
+}
+
+// add counts the output text of a stream chunk.
+func (u *liveUsage) add(stream *liveStream, payload []byte, now time.Time) {
+	if stream == nil || len(payload) == 0 {
+		return
+	}
+	chars := int64(streamOutputChars(payload))
+	if chars == 0 {
+		return
+	}
+	u.mu.Lock()
+	stream.chars += chars
+	stream.usage.EstimatedTokens = (stream.chars + 3) / 4
+	stream.usage.UpdatedAt = now
+	u.mu.Unlock()
+}
+
+// finish marks a stream done; it is dropped two intervals later.
+func (u *liveUsage) finish(stream *liveStream, now time.Time) {
+	if stream == nil {
+		return
+	}
+	u.mu.Lock()
+	stream.usage.Done = true
+	stream.usage.UpdatedAt = now
+	u.mu.Unlock()
+}
+
+// pruneLocked drops streams that finished more than two intervals ago. Callers must hold u.mu.
+func (u *liveUsage) pruneLocked(now time.Time) {
+	for id, stream := range u.streams {
+		if stream.usage.Done && now.Sub(stream.usage.UpdatedAt) > 2*u.policy.Interval {
+			delete(u.streams, id)
+		}
+	}
+}
+
+// LiveStreamUsage returns the running usage of tracked streams, oldest first, including recently
+// finished ones.
+func (m *Manager) LiveStreamUsage() []LiveStreamUsage {
+	if m == nil {
+		return nil
+	}
+	u := &m.liveUsage
+	u.mu.Lock()
+	u.pruneLocked(time.Now())
+	out := make([]LiveStreamUsage, 0, len(u.streams))
+	for _, stream := range u.streams {
+		out = append(out, stream.usage)
+	}
+	u.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// liveUsageTextKeys name the fields carrying generated text in the stream formats of every provider.
+var liveUsageTextKeys = map[string]struct{}{
+	"content":           {},
+	"text":              {},
+	"delta":             {},
+	"thinking":          {},
+	"reasoning_content": {},
+	"arguments":         {},
+	"partial_json":      {},
+}
+
+// streamOutputChars counts the generated characters in a provider stream chunk, which may hold raw
+// JSON or SSE "data:" lines. Token counts are estimated from it at four characters per token.
+// Events that repeat text already streamed, such as Responses API "*.done" events, are skipped.
+func streamOutputChars(payload []byte) int {
+	total := 0
+	for _, line := range bytes.Split(payload, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if rest, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			line = bytes.TrimSpace(rest)
+		}
+		if len(line) == 0 || !gjson.ValidBytes(line) {
+			continue
+		}
+		event := gjson.ParseBytes(line)
+		if kind := event.Get("type").String(); strings.HasSuffix(kind, ".done") || strings.HasSuffix(kind, ".completed") {
+			continue
+		}
+		total += textChars(event)
+	}
+	return total
+}
+
+// textChars counts the characters of the string fields named by liveUsageTextKeys in value.
+func textChars(value gjson.Result) int {
+	total := 0
+	value.ForEach(func(key, child gjson.Result) bool {
+		if child.IsObject() || child.IsArray() {
+			total += textChars(child)
+		} else if _, ok := liveUsageTextKeys[key.String()]; ok && child.Type == gjson.String {
+			total += len([]rune(child.String()))
+		}
+		return true
+	})
+	return total
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestStreamOutputChars(t *testing.T) {
+	cases := map[string]int{
+		`{"choices":[{"delta":{"role":"assistant","content":"hello"}}]}`:                                                                 5,
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"héllo!\"}}\n": 6,
+		`data: {"candidates":[{"content":{"parts":[{"text":"abc"},{"text":"de"}]}}]}`:                                                    5,
+		`data: {"type":"response.output_text.delta","delta":"four"}`:                                                                     4,
+		`data: {"type":"response.output_text.done","text":"repeated text"}`:                                                              0,
+		`data: [DONE]`: 0,
+	}
+	for payload, want := range cases {
+		if got := streamOutputChars([]byte(payload)); got != want {
+			t.Errorf("streamOutputChars(%s) = %d; want %d", payload, got, want)
+		}
+	}
+}
+
+func TestLiveUsageTracksOptedInStreams(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	auth := &Auth{ID: "live-a", Provider: "gemini"}
+	now := time.Now()
+	optIn := cliproxyexecutor.Options{Metadata: map[string]any{cliproxyexecutor.LiveUsageMetadataKey: true}}
+	keyed := cliproxyexecutor.Options{Metadata: map[string]any{cliproxyexecutor.ClientKeyMetadataKey: "dash"}}
+
+	if m.liveUsage.begin(optIn, auth, "gemini", "m", now) != nil {
+		t.Fatal("streams must not be tracked while live usage is off")
+	}
+	m.SetLiveUsagePolicy(LiveUsagePolicy{Enabled: true, Interval: time.Second, APIKeys: []string{"dash"}})
+	if m.liveUsage.begin(cliproxyexecutor.Options{}, auth, "gemini", "m", now) != nil {
+		t.Fatal("a stream that did not opt in was tracked")
+	}
+	stream := m.liveUsage.begin(optIn, auth, "gemini", "m", now)
+	m.liveUsage.add(stream, []byte(`{"candidates":[{"content":{"parts":[{"text":"12345678"}]}}]}`), now)
+	keyedStream := m.liveUsage.begin(keyed, auth, "gemini", "m", now)
+	if keyedStream == nil {
+		t.Fatal("the client key's stream was not tracked")
+	}
+
+	usage := m.LiveStreamUsage()
+	if len(usage) != 2 || usage[0].EstimatedTokens != 2 || usage[0].AuthID != "live-a" || usage[0].Done {
+		t.Fatalf("usage = %+v", usage)
+	}
+	m.liveUsage.finish(stream, now)
+	if usage = m.LiveStreamUsage(); len(usage) != 2 || !usage[0].Done {
+		t.Fatalf("finished stream should be reported, got %+v", usage)
+	}
+	m.liveUsage.finish(keyedStream, now.Add(-3*time.Second))
+	if usage = m.LiveStreamUsage(); len(usage) != 1 || usage[0].ID != stream.usage.ID {
+		t.Fatalf("long finished stream should be dropped, got %+v", usage)
+	}
+}
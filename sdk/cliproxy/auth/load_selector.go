@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// Load-aware selection strategies.
+const (
+	StrategyLeastRecentlyUsed = "least-recently-used"
+	StrategyLeastLoaded       = "least-loaded"
+)
+
+// LeastRecentlyUsedSelector picks the available account that was picked longest ago. Accounts never
+// picked come first, in ID order.
+type LeastRecentlyUsedSelector struct {
+	mu       sync.Mutex
+	seq      uint64
+	lastUsed map[string]uint64
+}
+
+// Pick selects the available auth with the oldest previous pick.
+func (s *LeastRecentlyUsedSelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	_ = ctx
+	_ = opts
+	available, err := availableAuths(provider, model, auths, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pickLocked(available, nil, true), nil
+}
+
+// Peek reports the auth Pick would return without marking it used.
+func (s *LeastRecentlyUsedSelector) Peek(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	_ = ctx
+	_ = opts
+	available, err := availableAuths(provider, model, auths, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pickLocked(available, nil, false), nil
+}
+
+// pickLocked returns the least recently used candidate among those accepted by keep (all when nil),
+// marking it used when mark is set. Callers hold s.mu; candidates must be non-empty and sorted by ID.
+func (s *LeastRecentlyUsedSelector) pickLocked(candidates []*Auth, keep func(*Auth) bool, mark bool) *Auth {
+	var picked *Auth
+	for _, candidate := range candidates {
+		if keep != nil && !keep(candidate) {
+			continue
+		}
+		if picked == nil || s.lastUsed[candidate.ID] < s.lastUsed[picked.ID] {
+			picked = candidate
+		}
+	}
+	if picked == nil {
+		picked = candidates[0]
+	}
+	if !mark {
+		return picked
+	}
+	if s.lastUsed == nil {
+		s.lastUsed = make(map[string]uint64)
+	}
+	s.seq++
+	s.lastUsed[picked.ID] = s.seq
+	return picked
+}
+
+// LeastLoadedSelector picks the available account with the fewest in-flight requests, breaking ties
+// by least recent use so idle accounts still rotate.
+type LeastLoadedSelector struct {
+	lru LeastRecentlyUsedSelector
+	// inFlight reports the running requests of an account; nil treats every account as idle.
+	inFlight func(authID string) int
+}
+
+// NewLeastLoadedSelector constructs a least-loaded selector reading in-flight counts from inFlight.
+func NewLeastLoadedSelector(inFlight func(authID string) int) *LeastLoadedSelector {
+	return &LeastLoadedSelector{inFlight: inFlight}
+}
+
+// Pick selects the available auth with the fewest in-flight requests.
+func (s *LeastLoadedSelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	_ = ctx
+	_ = opts
+	return s.next(provider, model, auths, true)
+}
+
+// Peek reports the auth Pick would return without marking it used.
+func (s *LeastLoadedSelector) Peek(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	_ = ctx
+	_ = opts
+	return s.next(provider, model, auths, false)
+}
+
+func (s *LeastLoadedSelector) next(provider, model string, auths []*Auth, mark bool) (*Auth, error) {
+	available, err := availableAuths(provider, model, auths, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	loads := make(map[string]int, len(available))
+	lowest := -1
+	for _, candidate := range available {
+		load := 0
+		if s.inFlight != nil {
+			load = s.inFlight(candidate.ID)
+		}
+		loads[candidate.ID] = load
+		if lowest < 0 || load < lowest {
+			lowest = load
+		}
+	}
+	s.lru.mu.Lock()
+	defer s.lru.mu.Unlock()
+	return s.lru.pickLocked(available, func(a *Auth) bool { return loads[a.ID] == lowest }, mark), nil
+}
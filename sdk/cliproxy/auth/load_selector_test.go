@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestLeastRecentlyUsedSelectorPrefersOldestPick(t *testing.T) {
+	auths := []*Auth{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	selector := &LeastRecentlyUsedSelector{}
+	var order []string
+	for i := 0; i < 3; i++ {
+		picked, err := selector.Pick(context.Background(), "codex", "gpt-5", cliproxyexecutor.Options{}, auths)
+		if err != nil {
+			t.Fatalf("pick: %v", err)
+		}
+		order = append(order, picked.ID)
+	}
+	if order[0] != "a" || order[1] != "b" || order[2] != "c" {
+		t.Fatalf("never-used accounts should be picked in ID order, got %v", order)
+	}
+	// A newly added account has never been used and goes first.
+	auths = append(auths, &Auth{ID: "d"})
+	picked, _ := selector.Pick(context.Background(), "codex", "gpt-5", cliproxyexecutor.Options{}, auths)
+	if picked.ID != "d" {
+		t.Fatalf("expected unused account d, got %s", picked.ID)
+	}
+	picked, _ = selector.Pick(context.Background(), "codex", "gpt-5", cliproxyexecutor.Options{}, auths)
+	if picked.ID != "a" {
+		t.Fatalf("expected least recently used account a, got %s", picked.ID)
+	}
+}
+
+func TestLeastLoadedSelectorPrefersFewestInFlight(t *testing.T) {
+	auths := []*Auth{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	loads := map[string]int{"a": 2, "b": 0, "c": 0}
+	selector := NewLeastLoadedSelector(func(id string) int { return loads[id] })
+	first, _ := selector.Pick(context.Background(), "codex", "gpt-5", cliproxyexecutor.Options{}, auths)
+	second, _ := selector.Pick(context.Background(), "codex", "gpt-5", cliproxyexecutor.Options{}, auths)
+	if first.ID != "b" || second.ID != "c" {
+		t.Fatalf("idle accounts should rotate, got %s then %s", first.ID, second.ID)
+	}
+	loads["b"], loads["c"] = 3, 1
+	picked, _ := selector.Pick(context.Background(), "codex", "gpt-5", cliproxyexecutor.Options{}, auths)
+	if picked.ID != "c" {
+		t.Fatalf("expected least loaded account c, got %s", picked.ID)
+	}
+}
+
+func TestLeastLoadedStrategyTracksInFlightRequests(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	if err := m.SetSelectionStrategy("least_loaded"); err != nil {
+		t.Fatalf("set strategy: %v", err)
+	}
+	if got := m.GlobalStrategy(); got != StrategyLeastLoaded {
+		t.Fatalf("expected least-loaded, got %s", got)
+	}
+	auths := []*Auth{{ID: "a"}, {ID: "b"}}
+	finishA := m.beginRequest("a")
+	picked, err := m.selector.Pick(context.Background(), "codex", "gpt-5", cliproxyexecutor.Options{}, auths)
+	if err != nil || picked.ID != "b" {
+		t.Fatalf("expected idle account b, got %v (%v)", picked, err)
+	}
+	finishB := m.beginRequest("b")
+	finishB2 := m.beginRequest("b")
+	picked, _ = m.selector.Pick(context.Background(), "codex", "gpt-5", cliproxyexecutor.Options{}, auths)
+	if picked.ID != "a" {
+		t.Fatalf("expected less loaded account a, got %s", picked.ID)
+	}
+	finishB()
+	finishB2()
+	finishB2()
+	if n := m.activity.inFlight("b"); n != 0 {
+		t.Fatalf("finished requests must release their slot once, got %d in flight", n)
+	}
+	finishA()
+	if n := m.activity.inFlight("a"); n != 0 {
+		t.Fatalf("expected no requests in flight for a, got %d", n)
+	}
+}
@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaintenanceCooldown          = 2 * time.Minute
+	defaultMaintenanceProviderThreshold = 3
+	defaultMaintenanceProviderWindow    = time.Minute
+	defaultMaintenanceProviderOpen      = time.Minute
+)
+
+// maintenanceQuotaReason marks cooldowns caused by upstream maintenance.
+const maintenanceQuotaReason = "maintenance"
+
+// defaultMaintenancePatterns lists 503 body fragments known to mean planned or temporary maintenance.
+var defaultMaintenancePatterns = map[string][]string{
+	billingWildcardProvider: {
+		"under maintenance",
+		"undergoing maintenance",
+		"scheduled maintenance",
+		"maintenance mode",
+		"temporarily down for maintenance",
+	},
+}
+
+// MaintenancePolicy controls how upstream maintenance responses are handled.
+type MaintenancePolicy struct {
+	// Enabled toggles detection; when false maintenance responses follow the regular 503 handling.
+	Enabled bool
+	// Patterns maps provider keys to case-insensitive message fragments, extending the built-in defaults.
+	// The "*" key applies to all providers.
+	Patterns map[string][]string
+	// Cooldown is the fixed per-account cooldown applied on a maintenance response.
+	Cooldown time.Duration
+	// ProviderThreshold is the number of distinct accounts that must report maintenance within
+	// ProviderWindow before the whole provider is treated as under maintenance.
+	ProviderThreshold int
+	ProviderWindow    time.Duration
+	// ProviderOpen is how long the provider circuit stays open during provider-wide maintenance.
+	ProviderOpen time.Duration
+}
+
+func (p MaintenancePolicy) withDefaults() MaintenancePolicy {
+	if p.Cooldown <= 0 {
+		p.Cooldown = defaultMaintenanceCooldown
+	}
+	if p.ProviderThreshold <= 0 {
+		p.ProviderThreshold = defaultMaintenanceProviderThreshold
+	}
+	if p.ProviderWindow <= 0 {
+		p.ProviderWindow = defaultMaintenanceProviderWindow
+	}
+	if p.ProviderOpen <= 0 {
+		p.ProviderOpen = defaultMaintenanceProviderOpen
+	}
+	return p
+}
+
+// providerMaintenance holds the maintenance policy and recent per-provider maintenance hits.
+type providerMaintenance struct {
+	mu       sync.Mutex
+	policy   MaintenancePolicy
+	patterns map[string][]string
+	// hits records, per provider, when each account last reported maintenance.
+	hits map[string]map[string]time.Time
+}
+
+// SetMaintenancePolicy replaces the maintenance detection policy used by MarkResult.
+func (m *Manager) SetMaintenancePolicy(policy MaintenancePolicy) {
+	if m == nil {
+		return
+	}
+	policy = policy.withDefaults()
+	patterns := make(map[string][]string)
+	if policy.Enabled {
+		for provider, list := range defaultMaintenancePatterns {
+			patterns[provider] = append(patterns[provider], list...)
+		}
+		for provider, list := range policy.Patterns {
+			key := strings.ToLower(strings.TrimSpace(provider))
+			if key == "" {
+				continue
+			}
+			for _, pattern := range list {
+				if p := strings.ToLower(strings.TrimSpace(pattern)); p != "" {
+					patterns[key] = append(patterns[key], p)
+				}
+			}
+		}
+	}
+	m.maintenance.mu.Lock()
+	m.maintenance.policy = policy
+	m.maintenance.patterns = patterns
+	if !policy.Enabled {
+		m.maintenance.hits = nil
+	}
+	m.maintenance.mu.Unlock()
+}
+
+// match reports whether err is a maintenance response for provider and returns the cooldown to apply.
+func (p *providerMaintenance) match(provider string, err *Error) (time.Duration, bool) {
+	if err == nil || statusCodeFromResult(err) != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.policy.Enabled || len(p.patterns) == 0 {
+		return 0, false
+	}
+	text := strings.ToLower(err.Code + " " + err.Message)
+	for _, key := range []string{strings.ToLower(provider), billingWildcardProvider} {
+		for _, pattern := range p.patterns[key] {
+			if strings.Contains(text, pattern) {
+				return p.policy.Cooldown, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// recordHit notes a maintenance response from authID and reports how long the provider circuit
+// should open when enough distinct accounts hit maintenance within the window.
+func (p *providerMaintenance) recordHit(provider, authID string, now time.Time) (time.Duration, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.policy.Enabled || provider == "" {
+		return 0, false
+	}
+	if p.hits == nil {
+		p.hits = make(map[string]map[string]time.Time)
+	}
+	hits := p.hits[provider]
+	if hits == nil {
+		hits = make(map[string]time.Time)
+		p.hits[provider] = hits
+	}
+	hits[authID] = now
+	for id, at := range hits {
+		if now.Sub(at) > p.policy.ProviderWindow {
+			delete(hits, id)
+		}
+	}
+	if len(hits) < p.policy.ProviderThreshold {
+		return 0, false
+	}
+	delete(p.hits, provider)
+	return p.policy.ProviderOpen, true
+}
+
+// applyMaintenanceCooldown parks the auth (or one of its models) for a fixed duration without
+// touching the quota backoff level.
+func applyMaintenanceCooldown(auth *Auth, model string, resultErr *Error, cooldown time.Duration, now time.Time) {
+	if auth == nil {
+		return
+	}
+	next := now.Add(cooldown)
+	if model != "" {
+		state := ensureModelState(auth, model)
+		state.Unavailable = true
+		state.Status = StatusError
+		state.StatusMessage = "upstream maintenance"
+		state.UpdatedAt = now
+		state.NextRetryAfter = next
+		state.Quota = QuotaState{
+			Exceeded:      true,
+			Reason:        maintenanceQuotaReason,
+			NextRecoverAt: next,
+			BackoffLevel:  state.Quota.BackoffLevel,
+		}
+		if resultErr != nil {
+			state.LastError = cloneError(resultErr)
+		}
+		updateAggregatedAvailability(auth, now)
+		auth.Quota.Reason = maintenanceQuotaReason
+	} else {
+		auth.Unavailable = true
+		auth.NextRetryAfter = next
+		auth.Quota.Exceeded = true
+		auth.Quota.Reason = maintenanceQuotaReason
+		auth.Quota.NextRecoverAt = next
+	}
+	auth.Status = StatusError
+	auth.StatusMessage = "upstream maintenance"
+	auth.UpdatedAt = now
+	if resultErr != nil {
+		auth.LastError = cloneError(resultErr)
+	}
+}
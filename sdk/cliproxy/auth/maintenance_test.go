@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMarkResult_MaintenanceAppliesFixedCooldown(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetMaintenancePolicy(MaintenancePolicy{Enabled: true, Cooldown: time.Minute})
+	if _, err := m.Register(context.Background(), &Auth{ID: "a", Provider: "gemini"}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		m.MarkResult(context.Background(), Result{
+			AuthID:   "a",
+			Provider: "gemini",
+			Model:    "gemini-2.5-pro",
+			Error:    &Error{HTTPStatus: 503, Message: `{"error":"The service is under maintenance"}`},
+		})
+	}
+
+	auth, _ := m.GetByID("a")
+	state := auth.ModelStates["gemini-2.5-pro"]
+	if state == nil || state.Quota.Reason != maintenanceQuotaReason {
+		t.Fatalf("expected maintenance cooldown, got %+v", state)
+	}
+	if state.Quota.BackoffLevel != 0 {
+		t.Fatalf("maintenance must not escalate backoff, got level %d", state.Quota.BackoffLevel)
+	}
+	if d := state.NextRetryAfter.Sub(state.UpdatedAt); d != time.Minute {
+		t.Fatalf("expected fixed 1m cooldown, got %s", d)
+	}
+	if blocked, reason, _ := isAuthBlockedForModel(auth, "gemini-2.5-pro", state.UpdatedAt); !blocked || reason != blockReasonCooldown {
+		t.Fatalf("expected cooldown block, got blocked=%v reason=%v", blocked, reason)
+	}
+}
+
+func TestMarkResult_ProviderWideMaintenanceOpensCircuit(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetMaintenancePolicy(MaintenancePolicy{
+		Enabled:           true,
+		Patterns:          map[string][]string{"codex": {"upgrade in progress"}},
+		ProviderThreshold: 2,
+	})
+	for _, id := range []string{"a", "b"} {
+		if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: "codex"}); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	mark := func(id string) {
+		m.MarkResult(context.Background(), Result{
+			AuthID:   id,
+			Provider: "codex",
+			Model:    "gpt-5",
+			Error:    &Error{HTTPStatus: 503, Message: "Upgrade in progress, retry shortly"},
+		})
+	}
+
+	mark("a")
+	if !m.breakers.allow("codex", time.Now()) {
+		t.Fatalf("a single maintenance report must not pause the provider")
+	}
+	mark("b")
+	if m.breakers.allow("codex", time.Now()) {
+		t.Fatalf("expected provider to be paused after provider-wide maintenance")
+	}
+	if !m.breakers.allow("codex", time.Now().Add(2*time.Minute)) {
+		t.Fatalf("expected provider pause to expire")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -64,8 +65,17 @@ type Result struct {
 	Success bool
 	// RetryAfter carries a provider supplied retry hint (e.g. 429 retryDelay).
 	RetryAfter *time.Duration
+	// QuotaResetAt is when the provider's quota window resets, taken from response headers.
+	QuotaResetAt *time.Time
+	// RetryAfterAt is when the upstream asked to be retried, taken from the Retry-After header of a
+	// 429 or 503 response.
+	RetryAfterAt *time.Time
 	// Error describes the failure when Success is false.
 	Error *Error
+	// Latency is the wall time spent on the upstream call; zero when unknown.
+	Latency time.Duration
+	// FirstChunkLatency is, for streams, the time until the first payload arrived; zero otherwise.
+	FirstChunkLatency time.Duration
 }
 
 // Selector chooses an auth candidate for execution.
@@ -109,6 +119,113 @@ type Manager struct {
 	// Retry controls request retry behavior.
 	requestRetry     atomic.Int32
 	maxRetryInterval atomic.Int64
+	// streamRetryDisabled stops failover for streams that fail before their first byte.
+	streamRetryDisabled atomic.Bool
+
+	// billingPatterns holds lowercased billing error fragments keyed by provider; empty disables detection.
+	billingPatterns map[string][]string
+
+	// breakers gates providers that keep failing.
+	breakers circuitBreakers
+
+	// softRotation rests accounts after a number of served requests.
+	softRotation SoftRotationPolicy
+	// activity tracks in-flight requests per account for diagnostics.
+	activity accountActivity
+	// concurrency enforces per-account concurrency limits at selection time.
+	concurrency accountConcurrency
+	// capacity tracks per-minute served and rejected requests per provider for sizing recommendations.
+	capacity capacityTracker
+	// requestCounts counts recorded results per account.
+	requestCounts requestCounters
+	// tokenCounts totals the token usage upstream responses reported per account.
+	tokenCounts tokenCounters
+	// recovery keeps the latest retry attempts of backing-off accounts.
+	recovery recoveryHistory
+	// resultObserver is notified of every recorded result; guarded by mu.
+	resultObserver ResultObserver
+	// rateLimits tracks remaining-quota headers and proactive cordons per account.
+	rateLimits rateLimitTracker
+	// drill cordons accounts while a failover drill runs and records how requests fared.
+	drill failoverDrill
+	// drains keeps accounts being drained out of selection until they are disabled.
+	drains accountDrains
+	// shards limits selection and refreshes to the accounts this instance owns; guarded by mu.
+	shards accountShards
+	// duplicateMode selects how accounts sharing a credential are handled; guarded by mu.
+	duplicateMode string
+	// emptyResponsePolicy selects how upstream responses without content are handled; guarded by mu.
+	emptyResponsePolicy string
+
+	// reaper disables long-failing accounts and prunes orphaned runtime state.
+	reaper reaper
+
+	// maintenance detects upstream maintenance responses and tracks provider-wide outages.
+	maintenance providerMaintenance
+
+	// egress detects accounts blocked from the current egress IP or region.
+	egress egressBlocks
+
+	// batcher combines opted-in requests into upstream batch calls.
+	batcher requestBatcher
+
+	// clientErrors lists upstream statuses blamed on the client request instead of the account.
+	clientErrors clientErrorStatuses
+
+	// affinity records how often prompt-prefix affinity reached its preferred account.
+	affinity prefixAffinity
+
+	// features gates experimental subsystems behind per-account feature flags.
+	features featureGates
+
+	// refreshes coalesces token refreshes and holds the eager refresh threshold.
+	refreshes refreshFlights
+	// refreshLimit caps concurrent token refreshes per provider.
+	refreshLimit refreshLimiter
+
+	// history keeps recent first-attempt selections for strategy previews.
+	history selectionHistory
+
+	// peakReserve holds reserve accounts back outside scheduled peak windows.
+	peakReserve peakReserve
+
+	// routes sends requests for matching models to a preferred account pool.
+	routes modelRoutes
+
+	// capabilities matches "capabilities:" requests to the catalog models each account serves.
+	capabilities modelCapabilities
+
+	// backoff shapes the quota cooldown escalation per provider.
+	backoff backoffCurves
+
+	// liveUsage tracks running usage of in-flight streams for the accounts monitor.
+	liveUsage liveUsage
+
+	// tokenPenalty makes selection mildly prefer accounts whose token is further from expiry.
+	tokenPenalty tokenPenalty
+
+	// reservations sets accounts aside for requests naming a reservation.
+	reservations reservationBook
+
+	// overhead keeps recent proxy overhead and upstream timings.
+	overhead overheadHistory
+
+	// caps counts selections against soft daily and monthly request caps.
+	caps requestCaps
+
+	// clientStrategies overrides the selection strategy for individual client API keys.
+	clientStrategies clientStrategyBook
+
+	// Runtime state persistence; all fields are guarded by mu.
+	stateStore    RuntimeStateStore
+	runtimeStates map[string]RuntimeState
+	stateDirty    map[string]struct{}
+	stateStop     chan struct{}
+	stateDone     chan struct{}
+	// stateFailure holds the latest persistence failure while the backend is unavailable.
+	stateFailure *persistenceFailure
+	// persistStats adds request and token counters to the persisted runtime state.
+	persistStats bool
 
 	// Optional HTTP RoundTripper provider injected by host.
 	rtProvider RoundTripperProvider
@@ -132,6 +249,7 @@ func NewManager(store Store, selector Selector, hook Hook) *Manager {
 		hook:            hook,
 		auths:           make(map[string]*Auth),
 		providerOffsets: make(map[string]int),
+		shards:          accountShards{pools: make(map[string][]*Auth)},
 	}
 }
 
@@ -194,9 +312,22 @@ func (m *Manager) Register(ctx context.Context, auth *Auth) (*Auth, error) {
 	if auth.ID == "" {
 		auth.ID = uuid.NewString()
 	}
+	syncTagsFromMetadata(auth)
+	syncFeatureFlagsFromMetadata(auth)
+	syncPriorityFromMetadata(auth)
+	syncWeightFromMetadata(auth)
 	m.mu.Lock()
+	if primary, rejected := m.rejectsDuplicateLocked(auth); rejected {
+		m.mu.Unlock()
+		return nil, &Error{Code: "duplicate_credential", Message: "auth " + auth.ID + " uses the same credential as " + primary}
+	}
+	m.restoreRuntimeStateLocked(auth)
 	m.auths[auth.ID] = auth.Clone()
+	m.reindexDuplicatesLocked()
+	m.reindexShardsLocked()
+	auth.DuplicateOf = m.auths[auth.ID].DuplicateOf
 	m.mu.Unlock()
+	m.warnDuplicate(auth)
 	_ = m.persist(ctx, auth)
 	m.hook.OnAuthRegistered(ctx, auth.Clone())
 	return auth.Clone(), nil
@@ -212,9 +343,29 @@ func (m *Manager) Update(ctx context.Context, auth *Auth) (*Auth, error) {
 		auth.Index = existing.Index
 		auth.indexAssigned = existing.indexAssigned
 	}
+	if existing, ok := m.auths[auth.ID]; ok && existing != nil && auth.LastUnavailableAt.IsZero() {
+		auth.LastUnavailableReason = existing.LastUnavailableReason
+		auth.LastUnavailableAt = existing.LastUnavailableAt
+	}
 	auth.EnsureIndex()
+	syncTagsFromMetadata(auth)
+	syncFeatureFlagsFromMetadata(auth)
+	syncPriorityFromMetadata(auth)
+	syncWeightFromMetadata(auth)
+	m.restoreRuntimeStateLocked(auth)
+	m.recordRuntimeStateLocked(auth)
+	wasDuplicate := ""
+	if existing, ok := m.auths[auth.ID]; ok && existing != nil {
+		wasDuplicate = existing.DuplicateOf
+	}
 	m.auths[auth.ID] = auth.Clone()
+	m.reindexDuplicatesLocked()
+	m.reindexShardsLocked()
+	auth.DuplicateOf = m.auths[auth.ID].DuplicateOf
 	m.mu.Unlock()
+	if auth.DuplicateOf != wasDuplicate {
+		m.warnDuplicate(auth)
+	}
 	_ = m.persist(ctx, auth)
 	m.hook.OnAuthUpdated(ctx, auth.Clone())
 	return auth.Clone(), nil
@@ -237,19 +388,36 @@ func (m *Manager) Load(ctx context.Context) error {
 			continue
 		}
 		auth.EnsureIndex()
+		syncTagsFromMetadata(auth)
+		syncFeatureFlagsFromMetadata(auth)
+		syncPriorityFromMetadata(auth)
+		syncWeightFromMetadata(auth)
+		if primary, rejected := m.rejectsDuplicateLocked(auth); rejected {
+			log.Warnf("auth %s (%s) skipped: same credential as %s", auth.ID, auth.Provider, primary)
+			continue
+		}
+		m.restoreRuntimeStateLocked(auth)
 		m.auths[auth.ID] = auth.Clone()
 	}
+	m.reindexDuplicatesLocked()
+	m.reindexShardsLocked()
+	for _, auth := range m.auths {
+		if auth.DuplicateOf != "" {
+			log.Warnf("auth %s (%s) uses the same credential as %s", auth.ID, auth.Provider, auth.DuplicateOf)
+		}
+	}
 	return nil
 }
 
 // Execute performs a non-streaming execution using the configured selector and executor.
 // It supports multiple providers for the same model and round-robins the starting provider per model.
-func (m *Manager) Execute(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+func (m *Manager) Execute(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (_ cliproxyexecutor.Response, errOut error) {
+	defer func() { m.drill.recordRequest(errOut == nil) }()
 	normalized := m.normalizeProviders(providers)
-	if len(normalized) == 0 {
+	rotated := m.pinProviders(opts, m.routeProviders(req.Model, m.rotateProviders(req.Model, normalized)))
+	if len(rotated) == 0 {
 		return cliproxyexecutor.Response{}, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
-	rotated := m.rotateProviders(req.Model, normalized)
 	defer m.advanceProviderCursor(req.Model, normalized)
 
 	retryTimes, maxWait := m.retrySettings()
@@ -271,6 +439,9 @@ func (m *Manager) Execute(ctx context.Context, providers []string, req cliproxye
 		if !shouldRetry {
 			break
 		}
+		if waitExceedsDeadline(ctx, wait) {
+			return cliproxyexecutor.Response{}, &DeadlineExceededError{Stage: DeadlineStageRetryWait}
+		}
 		if errWait := waitForCooldown(ctx, wait); errWait != nil {
 			return cliproxyexecutor.Response{}, errWait
 		}
@@ -285,10 +456,10 @@ func (m *Manager) Execute(ctx context.Context, providers []string, req cliproxye
 // It supports multiple providers for the same model and round-robins the starting provider per model.
 func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
 	normalized := m.normalizeProviders(providers)
-	if len(normalized) == 0 {
+	rotated := m.pinProviders(opts, m.routeProviders(req.Model, m.rotateProviders(req.Model, normalized)))
+	if len(rotated) == 0 {
 		return cliproxyexecutor.Response{}, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
-	rotated := m.rotateProviders(req.Model, normalized)
 	defer m.advanceProviderCursor(req.Model, normalized)
 
 	retryTimes, maxWait := m.retrySettings()
@@ -310,6 +481,9 @@ func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req clip
 		if !shouldRetry {
 			break
 		}
+		if waitExceedsDeadline(ctx, wait) {
+			return cliproxyexecutor.Response{}, &DeadlineExceededError{Stage: DeadlineStageRetryWait}
+		}
 		if errWait := waitForCooldown(ctx, wait); errWait != nil {
 			return cliproxyexecutor.Response{}, errWait
 		}
@@ -322,12 +496,13 @@ func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req clip
 
 // ExecuteStream performs a streaming execution using the configured selector and executor.
 // It supports multiple providers for the same model and round-robins the starting provider per model.
-func (m *Manager) ExecuteStream(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (<-chan cliproxyexecutor.StreamChunk, error) {
+func (m *Manager) ExecuteStream(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (_ <-chan cliproxyexecutor.StreamChunk, errOut error) {
+	defer func() { m.drill.recordRequest(errOut == nil) }()
 	normalized := m.normalizeProviders(providers)
-	if len(normalized) == 0 {
+	rotated := m.pinProviders(opts, m.routeProviders(req.Model, m.rotateProviders(req.Model, normalized)))
+	if len(rotated) == 0 {
 		return nil, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
-	rotated := m.rotateProviders(req.Model, normalized)
 	defer m.advanceProviderCursor(req.Model, normalized)
 
 	retryTimes, maxWait := m.retrySettings()
@@ -349,6 +524,9 @@ func (m *Manager) ExecuteStream(ctx context.Context, providers []string, req cli
 		if !shouldRetry {
 			break
 		}
+		if waitExceedsDeadline(ctx, wait) {
+			return nil, &DeadlineExceededError{Stage: DeadlineStageRetryWait}
+		}
 		if errWait := waitForCooldown(ctx, wait); errWait != nil {
 			return nil, errWait
 		}
@@ -363,8 +541,22 @@ func (m *Manager) executeWithProvider(ctx context.Context, provider string, req
 	if provider == "" {
 		return cliproxyexecutor.Response{}, &Error{Code: "provider_not_found", Message: "provider identifier is empty"}
 	}
-	tried := make(map[string]struct{})
 	var lastErr error
+	if resp, errBatch, batched := m.executeBatched(ctx, provider, req, opts); batched {
+		if errBatch == nil {
+			return resp, nil
+		}
+		if errDeadline := deadlineExceeded(ctx, DeadlineStageUpstream); errDeadline != nil {
+			return cliproxyexecutor.Response{}, errDeadline
+		}
+		if ctx.Err() != nil || m.isClientCaused(provider, resultErrorFrom(errBatch)) {
+			return cliproxyexecutor.Response{}, errBatch
+		}
+		// Requests that failed inside a batch are retried on their own.
+		lastErr = errBatch
+		opts = withoutBatching(opts)
+	}
+	tried := make(map[string]struct{})
 	for {
 		auth, executor, errPick := m.pickNext(ctx, provider, req.Model, opts, tried)
 		if errPick != nil {
@@ -373,6 +565,9 @@ func (m *Manager) executeWithProvider(ctx context.Context, provider string, req
 			}
 			return cliproxyexecutor.Response{}, errPick
 		}
+		auth = m.ensureFreshToken(ctx, auth)
+		// Capability requests run on the catalog model the picked account serves.
+		req := m.capabilities.request(auth, req)
 
 		accountType, accountInfo := auth.AccountInfo()
 		if accountType == "api_key" {
@@ -387,9 +582,16 @@ func (m *Manager) executeWithProvider(ctx context.Context, provider string, req
 			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
 			execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
 		}
-		resp, errExec := executor.Execute(execCtx, auth, req, opts)
-		result := Result{AuthID: auth.ID, Provider: provider, Model: req.Model, Success: errExec == nil}
+		execCtx = m.withRateLimitObserver(execCtx, auth.ID, provider)
+		started := time.Now()
+		finishReserved := m.beginRequest(auth.ID)
+		resp, errExec := executor.Execute(execCtx, auth, remapRequestModel(auth, req), opts)
+		finishReserved()
+		result := Result{AuthID: auth.ID, Provider: provider, Model: req.Model, Success: errExec == nil, Latency: time.Since(started)}
 		if errExec != nil {
+			if errDeadline := deadlineExceeded(ctx, DeadlineStageUpstream); errDeadline != nil {
+				return cliproxyexecutor.Response{}, errDeadline
+			}
 			result.Error = &Error{Message: errExec.Error()}
 			var se cliproxyexecutor.StatusError
 			if errors.As(errExec, &se) && se != nil {
@@ -398,10 +600,30 @@ func (m *Manager) executeWithProvider(ctx context.Context, provider string, req
 			if ra := retryAfterFromError(errExec); ra != nil {
 				result.RetryAfter = ra
 			}
+			result.QuotaResetAt = quotaResetFromError(provider, errExec, time.Now())
+			result.RetryAfterAt = retryAfterHeaderFromError(errExec, time.Now())
 			m.MarkResult(execCtx, result)
+			if m.isClientCaused(provider, result.Error) {
+				return cliproxyexecutor.Response{}, errExec
+			}
+			if m.consumeEgressReroute(auth.ID) {
+				delete(tried, auth.ID)
+			}
 			lastErr = errExec
 			continue
 		}
+		m.tokenCounts.record(auth.ID, payloadTokenUsage(resp.Payload))
+		if isEmptyResponse(resp.Payload) {
+			retry, errEmpty := m.handleEmptyResponse(execCtx, result)
+			if retry {
+				lastErr = errEmpty
+				continue
+			}
+			if errEmpty != nil {
+				return cliproxyexecutor.Response{}, errEmpty
+			}
+			return resp, nil
+		}
 		m.MarkResult(execCtx, result)
 		return resp, nil
 	}
@@ -421,6 +643,9 @@ func (m *Manager) executeCountWithProvider(ctx context.Context, provider string,
 			}
 			return cliproxyexecutor.Response{}, errPick
 		}
+		auth = m.ensureFreshToken(ctx, auth)
+		// Capability requests run on the catalog model the picked account serves.
+		req := m.capabilities.request(auth, req)
 
 		accountType, accountInfo := auth.AccountInfo()
 		if accountType == "api_key" {
@@ -435,9 +660,16 @@ func (m *Manager) executeCountWithProvider(ctx context.Context, provider string,
 			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
 			execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
 		}
-		resp, errExec := executor.CountTokens(execCtx, auth, req, opts)
-		result := Result{AuthID: auth.ID, Provider: provider, Model: req.Model, Success: errExec == nil}
+		execCtx = m.withRateLimitObserver(execCtx, auth.ID, provider)
+		started := time.Now()
+		finishReserved := m.beginRequest(auth.ID)
+		resp, errExec := executor.CountTokens(execCtx, auth, remapRequestModel(auth, req), opts)
+		finishReserved()
+		result := Result{AuthID: auth.ID, Provider: provider, Model: req.Model, Success: errExec == nil, Latency: time.Since(started)}
 		if errExec != nil {
+			if errDeadline := deadlineExceeded(ctx, DeadlineStageUpstream); errDeadline != nil {
+				return cliproxyexecutor.Response{}, errDeadline
+			}
 			result.Error = &Error{Message: errExec.Error()}
 			var se cliproxyexecutor.StatusError
 			if errors.As(errExec, &se) && se != nil {
@@ -446,7 +678,15 @@ func (m *Manager) executeCountWithProvider(ctx context.Context, provider string,
 			if ra := retryAfterFromError(errExec); ra != nil {
 				result.RetryAfter = ra
 			}
+			result.QuotaResetAt = quotaResetFromError(provider, errExec, time.Now())
+			result.RetryAfterAt = retryAfterHeaderFromError(errExec, time.Now())
 			m.MarkResult(execCtx, result)
+			if m.isClientCaused(provider, result.Error) {
+				return cliproxyexecutor.Response{}, errExec
+			}
+			if m.consumeEgressReroute(auth.ID) {
+				delete(tried, auth.ID)
+			}
 			lastErr = errExec
 			continue
 		}
@@ -469,6 +709,9 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 			}
 			return nil, errPick
 		}
+		auth = m.ensureFreshToken(ctx, auth)
+		// Capability requests run on the catalog model the picked account serves.
+		req := m.capabilities.request(auth, req)
 
 		accountType, accountInfo := auth.AccountInfo()
 		if accountType == "api_key" {
@@ -483,43 +726,147 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
 			execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
 		}
-		chunks, errStream := executor.ExecuteStream(execCtx, auth, req, opts)
+		execCtx = m.withRateLimitObserver(execCtx, auth.ID, provider)
+		started := time.Now()
+		finishReserved := m.beginRequest(auth.ID)
+		chunks, errStream := executor.ExecuteStream(execCtx, auth, remapRequestModel(auth, req), opts)
 		if errStream != nil {
+			finishReserved()
+			if errDeadline := deadlineExceeded(ctx, DeadlineStageUpstream); errDeadline != nil {
+				return nil, errDeadline
+			}
 			rerr := &Error{Message: errStream.Error()}
 			var se cliproxyexecutor.StatusError
 			if errors.As(errStream, &se) && se != nil {
 				rerr.HTTPStatus = se.StatusCode()
 			}
-			result := Result{AuthID: auth.ID, Provider: provider, Model: req.Model, Success: false, Error: rerr}
+			result := Result{AuthID: auth.ID, Provider: provider, Model: req.Model, Success: false, Error: rerr, Latency: time.Since(started)}
 			result.RetryAfter = retryAfterFromError(errStream)
+			result.QuotaResetAt = quotaResetFromError(provider, errStream, time.Now())
+			result.RetryAfterAt = retryAfterHeaderFromError(errStream, time.Now())
 			m.MarkResult(execCtx, result)
+			if m.isClientCaused(provider, rerr) {
+				return nil, errStream
+			}
+			if m.consumeEgressReroute(auth.ID) {
+				delete(tried, auth.ID)
+			}
 			lastErr = errStream
 			continue
 		}
+		var prefix []cliproxyexecutor.StreamChunk
+		retryFirst := !m.streamRetryDisabled.Load()
+		holdEmpty := m.emptyPolicy() != EmptyResponsePassThrough
+		if retryFirst || holdEmpty {
+			var errFirst error
+			var empty bool
+			if holdEmpty {
+				prefix, errFirst, empty = awaitStreamContent(ctx, chunks)
+			} else {
+				prefix, errFirst = awaitFirstStreamChunk(ctx, chunks)
+			}
+			if errFirst != nil && !retryFirst && ctx.Err() == nil {
+				// Retry before the first byte is off: surface the failure through the stream as usual.
+				prefix = append(prefix, cliproxyexecutor.StreamChunk{Err: errFirst})
+				errFirst = nil
+			}
+			if empty {
+				finishReserved()
+				result := Result{AuthID: auth.ID, Provider: provider, Model: req.Model, Success: true, Latency: time.Since(started)}
+				retry, errEmpty := m.handleEmptyResponse(execCtx, result)
+				if retry {
+					lastErr = errEmpty
+					continue
+				}
+				return nil, errEmpty
+			}
+			if errFirst != nil {
+				drainStream(chunks)
+				finishReserved()
+				if errDeadline := deadlineExceeded(ctx, DeadlineStageUpstream); errDeadline != nil {
+					return nil, errDeadline
+				}
+				if ctx.Err() != nil {
+					return nil, errFirst
+				}
+				result := Result{AuthID: auth.ID, Provider: provider, Model: req.Model, Success: false, Error: resultErrorFrom(errFirst), Latency: time.Since(started)}
+				result.RetryAfter = retryAfterFromError(errFirst)
+				result.QuotaResetAt = quotaResetFromError(provider, errFirst, time.Now())
+				result.RetryAfterAt = retryAfterHeaderFromError(errFirst, time.Now())
+				m.MarkResult(execCtx, result)
+				if m.isClientCaused(provider, result.Error) {
+					return nil, errFirst
+				}
+				if m.consumeEgressReroute(auth.ID) {
+					delete(tried, auth.ID)
+				}
+				log.Infof("stream via auth %s failed before first byte, retrying on another account: %v", auth.ID, errFirst)
+				lastErr = errFirst
+				continue
+			}
+		}
 		out := make(chan cliproxyexecutor.StreamChunk)
-		go func(streamCtx context.Context, streamAuth *Auth, streamProvider string, streamChunks <-chan cliproxyexecutor.StreamChunk) {
+		go func(streamCtx context.Context, streamAuth *Auth, streamProvider string, streamChunks <-chan cliproxyexecutor.StreamChunk, prefix []cliproxyexecutor.StreamChunk) {
 			defer close(out)
-			var failed bool
-			for chunk := range streamChunks {
+			defer finishReserved()
+			live := m.liveUsage.begin(opts, streamAuth, streamProvider, req.Model, time.Now())
+			defer func() { m.liveUsage.finish(live, time.Now()) }()
+			var failed, delivered, hasContent bool
+			var firstChunk time.Duration
+			var usage TokenUsage
+			defer func() { m.tokenCounts.record(streamAuth.ID, usage) }()
+			forward := func(chunk cliproxyexecutor.StreamChunk) {
+				if chunk.Err == nil {
+					m.liveUsage.add(live, chunk.Payload, time.Now())
+					usage = usage.merge(payloadTokenUsage(chunk.Payload))
+				}
+				if !hasContent && chunk.Err == nil && chunkHasContent(chunk.Payload) {
+					hasContent = true
+				}
+				if firstChunk == 0 && chunk.Err == nil && len(chunk.Payload) > 0 {
+					firstChunk = time.Since(started)
+				}
 				if chunk.Err != nil && !failed {
 					failed = true
-					rerr := &Error{Message: chunk.Err.Error()}
-					var se cliproxyexecutor.StatusError
-					if errors.As(chunk.Err, &se) && se != nil {
-						rerr.HTTPStatus = se.StatusCode()
+					m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: req.Model, Success: false, Error: resultErrorFrom(chunk.Err), Latency: time.Since(started), FirstChunkLatency: firstChunk})
+					if delivered {
+						log.Warnf("stream via auth %s failed after first byte, retry blocked; surfacing truncated stream: %v", streamAuth.ID, chunk.Err)
 					}
-					m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: req.Model, Success: false, Error: rerr})
+				}
+				if len(chunk.Payload) > 0 {
+					delivered = true
 				}
 				out <- chunk
 			}
+			for _, chunk := range prefix {
+				forward(chunk)
+			}
+			for chunk := range streamChunks {
+				forward(chunk)
+			}
 			if !failed {
-				m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: req.Model, Success: true})
+				if !hasContent {
+					m.requestCounts.recordEmpty(streamAuth.ID)
+				}
+				m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: req.Model, Success: true, Latency: time.Since(started), FirstChunkLatency: firstChunk})
 			}
-		}(execCtx, auth.Clone(), provider, chunks)
+		}(execCtx, auth.Clone(), provider, chunks, prefix)
 		return out, nil
 	}
 }
 
+// remapRequestModel swaps the canonical model for the account-specific upstream id. Selection,
+// cooldowns and results keep using the canonical name.
+func remapRequestModel(auth *Auth, req cliproxyexecutor.Request) cliproxyexecutor.Request {
+	upstream := auth.UpstreamModel(req.Model)
+	if upstream == req.Model {
+		return req
+	}
+	log.Debugf("auth %s remaps model %s to %s", auth.ID, req.Model, upstream)
+	req.Model = upstream
+	return req
+}
+
 func (m *Manager) normalizeProviders(providers []string) []string {
 	if len(providers) == 0 {
 		return nil
@@ -635,6 +982,12 @@ func (m *Manager) shouldRetryAfterError(err error, attempt, maxAttempts int, pro
 	if status := statusCodeFromError(err); status == http.StatusOK {
 		return 0, false
 	}
+	if m.isClientErrorStatus(err) || isPinnedAccountUnavailable(err) {
+		return 0, false
+	}
+	if isDeadlineExceeded(err) {
+		return 0, false
+	}
 	wait, found := m.closestCooldownWait(providers, model)
 	if !found || wait > maxWait {
 		return 0, false
@@ -646,6 +999,9 @@ func waitForCooldown(ctx context.Context, wait time.Duration) error {
 	if wait <= 0 {
 		return nil
 	}
+	// Cooldowns are caused by upstream failures, so the wait is not proxy overhead.
+	started := time.Now()
+	defer func() { UpstreamClockFrom(ctx).Add(time.Since(started)) }()
 	timer := time.NewTimer(wait)
 	defer timer.Stop()
 	select {
@@ -662,10 +1018,21 @@ func (m *Manager) executeProvidersOnce(ctx context.Context, providers []string,
 	}
 	var lastErr error
 	for _, provider := range providers {
+		if !m.breakers.allow(provider, time.Now()) {
+			lastErr = circuitOpenError(provider)
+			continue
+		}
 		resp, errExec := fn(ctx, provider)
 		if errExec == nil {
+			servedByFrom(ctx).set(provider)
 			return resp, nil
 		}
+		if m.isClientCaused(provider, resultErrorFrom(errExec)) {
+			return cliproxyexecutor.Response{}, &ClientError{Err: errExec}
+		}
+		if isDeadlineExceeded(errExec) {
+			return cliproxyexecutor.Response{}, errExec
+		}
 		lastErr = errExec
 	}
 	if lastErr != nil {
@@ -680,10 +1047,21 @@ func (m *Manager) executeStreamProvidersOnce(ctx context.Context, providers []st
 	}
 	var lastErr error
 	for _, provider := range providers {
+		if !m.breakers.allow(provider, time.Now()) {
+			lastErr = circuitOpenError(provider)
+			continue
+		}
 		chunks, errExec := fn(ctx, provider)
 		if errExec == nil {
+			servedByFrom(ctx).set(provider)
 			return chunks, nil
 		}
+		if m.isClientCaused(provider, resultErrorFrom(errExec)) {
+			return nil, &ClientError{Err: errExec}
+		}
+		if isDeadlineExceeded(errExec) {
+			return nil, errExec
+		}
 		lastErr = errExec
 	}
 	if lastErr != nil {
@@ -697,18 +1075,44 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 	if result.AuthID == "" {
 		return
 	}
+	m.requestCounts.record(result, time.Now())
+	m.drill.recordAttempt(result.Success)
 
 	shouldResumeModel := false
 	shouldSuspendModel := false
 	suspendReason := ""
 	clearModelQuota := false
 	setModelQuota := false
+	var billingSuspended *Auth
+	maintenance := false
+	clientCaused := false
+	var outcome ResultOutcome
 
 	m.mu.Lock()
+	observer := m.resultObserver
 	if auth, ok := m.auths[result.AuthID]; ok && auth != nil {
 		now := time.Now()
+		recovering := isRecoveryAttemptLocked(auth, result.Model)
 
-		if result.Success {
+		if !result.Success && m.isBillingSuspension(auth.Provider, result.Error) {
+			if applyBillingSuspension(auth, result.Error, now) {
+				billingSuspended = auth.Clone()
+			}
+		} else if cooldown, ok := m.egress.match(auth.Provider, result.Error); !result.Success && ok {
+			if applyEgressBlock(auth, result.Error, cooldown, now) {
+				log.Warnf("auth %s (%s) blocked from current egress; retrying through fallback proxy", auth.ID, auth.Provider)
+				m.egress.markRerouted(auth.ID)
+			} else {
+				log.Warnf("auth %s (%s) blocked from current egress IP or region: %s", auth.ID, auth.Provider, result.Error.Message)
+			}
+		} else if cooldown, ok := m.maintenance.match(auth.Provider, result.Error); !result.Success && ok {
+			applyMaintenanceCooldown(auth, result.Model, result.Error, cooldown, now)
+			maintenance = true
+		} else if !result.Success && m.clientErrors.has(statusCodeFromResult(result.Error)) && !describesAccount(auth.Provider, result.Error) {
+			// The request itself was bad: the account keeps its state and backoff.
+			recordClientErrorLocked(auth, now)
+			clientCaused = true
+		} else if result.Success {
 			if result.Model != "" {
 				state := ensureModelState(auth, result.Model)
 				resetModelState(state, now)
@@ -757,19 +1161,26 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 				case 429:
 					var next time.Time
 					backoffLevel := state.Quota.BackoffLevel
-					if result.RetryAfter != nil {
+					quotaReason := "quota"
+					if result.QuotaResetAt != nil && result.QuotaResetAt.After(now) {
+						next = *result.QuotaResetAt
+						quotaReason = quotaResetHeaderReason
+					} else if result.RetryAfter != nil {
 						next = now.Add(*result.RetryAfter)
 					} else {
-						cooldown, nextLevel := nextQuotaCooldown(backoffLevel)
+						cooldown, nextLevel := m.backoffCurveFor(auth.Provider).next(backoffLevel)
 						if cooldown > 0 {
 							next = now.Add(cooldown)
 						}
 						backoffLevel = nextLevel
 					}
+					if later, ok := laterRetryAfter(next, result.RetryAfterAt); ok {
+						next, quotaReason = later, retryAfterHeaderReason
+					}
 					state.NextRetryAfter = next
 					state.Quota = QuotaState{
 						Exceeded:      true,
-						Reason:        "quota",
+						Reason:        quotaReason,
 						NextRecoverAt: next,
 						BackoffLevel:  backoffLevel,
 					}
@@ -778,6 +1189,12 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 					setModelQuota = true
 				case 408, 500, 502, 503, 504:
 					next := now.Add(1 * time.Minute)
+					if statusCode == 503 {
+						// Overload is not a quota: only the retry time follows Retry-After.
+						if later, ok := laterRetryAfter(next, result.RetryAfterAt); ok {
+							next = later
+						}
+					}
 					state.NextRetryAfter = next
 				default:
 					state.NextRetryAfter = time.Time{}
@@ -787,14 +1204,26 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 				auth.UpdatedAt = now
 				updateAggregatedAvailability(auth, now)
 			} else {
-				applyAuthFailureState(auth, result.Error, result.RetryAfter, now)
+				applyAuthFailureState(auth, result.Error, result.RetryAfter, result.QuotaResetAt, result.RetryAfterAt, m.backoffCurveFor(auth.Provider), now)
 			}
 		}
 
+		if !result.Success && !clientCaused {
+			outcome = resultOutcomeLocked(auth, result, now)
+			recordUnavailableLocked(auth, result.Error, now)
+		}
+		if recovering && !clientCaused {
+			m.recovery.record(auth.ID, newRecoveryAttempt(result, now))
+		}
+		m.recordRuntimeStateLocked(auth)
 		_ = m.persist(ctx, auth)
 	}
 	m.mu.Unlock()
 
+	if billingSuspended != nil {
+		log.Warnf("auth %s (%s) suspended due to upstream billing error: %s; manual intervention required", billingSuspended.ID, billingSuspended.Provider, billingSuspended.StatusMessage)
+		m.hook.OnAuthUpdated(ctx, billingSuspended)
+	}
 	if clearModelQuota && result.Model != "" {
 		registry.GetGlobalRegistry().ClearModelQuotaExceeded(result.AuthID, result.Model)
 	}
@@ -807,7 +1236,22 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 		registry.GetGlobalRegistry().SuspendClientModel(result.AuthID, result.Model, suspendReason)
 	}
 
+	if clientCaused {
+		// Client-caused failures say nothing about provider health.
+	} else if maintenance {
+		// Maintenance is expected downtime: it must not count towards the failure threshold, but
+		// many accounts reporting it at once parks the whole provider briefly.
+		if open, ok := m.maintenance.recordHit(result.Provider, result.AuthID, time.Now()); ok {
+			log.Warnf("provider %s appears to be under maintenance; pausing requests for %s", result.Provider, open)
+			m.breakers.hold(result.Provider, open, time.Now())
+		}
+	} else {
+		m.breakers.record(result.Provider, result, time.Now())
+	}
 	m.hook.OnResult(ctx, result)
+	if observer != nil {
+		observer(ctx, result, outcome)
+	}
 }
 
 func ensureModelState(auth *Auth, model string) *ModelState {
@@ -846,6 +1290,7 @@ func updateAggregatedAvailability(auth *Auth, now time.Time) {
 	earliestRetry := time.Time{}
 	quotaExceeded := false
 	quotaRecover := time.Time{}
+	quotaReason := "quota"
 	maxBackoffLevel := 0
 	for _, state := range auth.ModelStates {
 		if state == nil {
@@ -874,6 +1319,9 @@ func updateAggregatedAvailability(auth *Auth, now time.Time) {
 			quotaExceeded = true
 			if quotaRecover.IsZero() || (!state.Quota.NextRecoverAt.IsZero() && state.Quota.NextRecoverAt.Before(quotaRecover)) {
 				quotaRecover = state.Quota.NextRecoverAt
+				if state.Quota.Reason != "" {
+					quotaReason = state.Quota.Reason
+				}
 			}
 			if state.Quota.BackoffLevel > maxBackoffLevel {
 				maxBackoffLevel = state.Quota.BackoffLevel
@@ -888,7 +1336,7 @@ func updateAggregatedAvailability(auth *Auth, now time.Time) {
 	}
 	if quotaExceeded {
 		auth.Quota.Exceeded = true
-		auth.Quota.Reason = "quota"
+		auth.Quota.Reason = quotaReason
 		auth.Quota.NextRecoverAt = quotaRecover
 		auth.Quota.BackoffLevel = maxBackoffLevel
 	} else {
@@ -987,7 +1435,7 @@ func statusCodeFromResult(err *Error) int {
 	return err.StatusCode()
 }
 
-func applyAuthFailureState(auth *Auth, resultErr *Error, retryAfter *time.Duration, now time.Time) {
+func applyAuthFailureState(auth *Auth, resultErr *Error, retryAfter *time.Duration, resetAt, retryAt *time.Time, curve BackoffCurve, now time.Time) {
 	if auth == nil {
 		return
 	}
@@ -1016,20 +1464,33 @@ func applyAuthFailureState(auth *Auth, resultErr *Error, retryAfter *time.Durati
 		auth.Quota.Exceeded = true
 		auth.Quota.Reason = "quota"
 		var next time.Time
-		if retryAfter != nil {
+		if resetAt != nil && resetAt.After(now) {
+			next = *resetAt
+			auth.Quota.Reason = quotaResetHeaderReason
+		} else if retryAfter != nil {
 			next = now.Add(*retryAfter)
 		} else {
-			cooldown, nextLevel := nextQuotaCooldown(auth.Quota.BackoffLevel)
+			cooldown, nextLevel := curve.next(auth.Quota.BackoffLevel)
 			if cooldown > 0 {
 				next = now.Add(cooldown)
 			}
 			auth.Quota.BackoffLevel = nextLevel
 		}
+		if later, ok := laterRetryAfter(next, retryAt); ok {
+			next, auth.Quota.Reason = later, retryAfterHeaderReason
+		}
 		auth.Quota.NextRecoverAt = next
 		auth.NextRetryAfter = next
 	case 408, 500, 502, 503, 504:
 		auth.StatusMessage = "transient upstream error"
-		auth.NextRetryAfter = now.Add(1 * time.Minute)
+		next := now.Add(1 * time.Minute)
+		if statusCode == 503 {
+			// Overload is not a quota: only the retry time follows Retry-After.
+			if later, ok := laterRetryAfter(next, retryAt); ok {
+				next = later
+			}
+		}
+		auth.NextRetryAfter = next
 	default:
 		if auth.StatusMessage == "" {
 			auth.StatusMessage = "request failed"
@@ -1037,24 +1498,6 @@ func applyAuthFailureState(auth *Auth, resultErr *Error, retryAfter *time.Durati
 	}
 }
 
-// nextQuotaCooldown returns the next cooldown duration and updated backoff level for repeated quota errors.
-func nextQuotaCooldown(prevLevel int) (time.Duration, int) {
-	if prevLevel < 0 {
-		prevLevel = 0
-	}
-	if quotaCooldownDisabled.Load() {
-		return 0, prevLevel
-	}
-	cooldown := quotaBackoffBase * time.Duration(1<<prevLevel)
-	if cooldown < quotaBackoffBase {
-		cooldown = quotaBackoffBase
-	}
-	if cooldown >= quotaBackoffMax {
-		return quotaBackoffMax, prevLevel
-	}
-	return cooldown, prevLevel + 1
-}
-
 // List returns all auth entries currently known by the manager.
 func (m *Manager) List() []*Auth {
 	m.mu.RLock()
@@ -1066,6 +1509,26 @@ func (m *Manager) List() []*Auth {
 	return list
 }
 
+// CountAuths reports how many auth entries satisfy match and how many are registered, without
+// cloning them. match runs under the manager's read lock and must neither modify nor retain the auth.
+func (m *Manager) CountAuths(match func(*Auth) bool) (matched, total int) {
+	if m == nil {
+		return 0, 0
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, auth := range m.auths {
+		if auth == nil {
+			continue
+		}
+		total++
+		if match == nil || match(auth) {
+			matched++
+		}
+	}
+	return matched, total
+}
+
 // GetByID retrieves an auth entry by its ID.
 
 func (m *Manager) GetByID(id string) (*Auth, bool) {
@@ -1081,47 +1544,120 @@ func (m *Manager) GetByID(id string) (*Auth, bool) {
 	return auth.Clone(), true
 }
 
-func (m *Manager) pickNext(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, tried map[string]struct{}) (*Auth, ProviderExecutor, error) {
-	m.mu.RLock()
-	executor, okExecutor := m.executors[provider]
-	if !okExecutor {
-		m.mu.RUnlock()
-		return nil, nil, &Error{Code: "executor_not_found", Message: "executor not registered"}
+// UpdateMatching applies mutate to every auth accepted by match while holding the manager lock,
+// so readers never observe a partially applied bulk change. mutate reports whether it modified
+// the auth; changed entries are persisted and announced to hooks. It returns clones of the changed auths.
+func (m *Manager) UpdateMatching(ctx context.Context, match func(*Auth) bool, mutate func(*Auth) bool) []*Auth {
+	if m == nil || match == nil || mutate == nil {
+		return nil
 	}
-	candidates := make([]*Auth, 0, len(m.auths))
-	modelKey := strings.TrimSpace(model)
-	registryRef := registry.GetGlobalRegistry()
-	for _, candidate := range m.auths {
-		if candidate.Provider != provider || candidate.Disabled {
+	now := time.Now()
+	changed := make([]*Auth, 0)
+	m.mu.Lock()
+	for _, auth := range m.auths {
+		if auth == nil || !match(auth) {
 			continue
 		}
+		if !mutate(auth) {
+			continue
+		}
+		auth.UpdatedAt = now
+		changed = append(changed, auth.Clone())
+	}
+	m.mu.Unlock()
+	for _, auth := range changed {
+		_ = m.persist(ctx, auth)
+		m.hook.OnAuthUpdated(ctx, auth.Clone())
+	}
+	return changed
+}
+
+// selectionCandidatesLocked filters pool down to the accounts a request for model may be routed to.
+// Resting, then rate-limit cordoned, then concurrency-saturated accounts are only returned when no
+// account that can serve right away is left. Cooling-down and otherwise blocked accounts do not
+// count when deciding the fallback.
+// Callers must hold m.mu.
+func (m *Manager) selectionCandidatesLocked(pool []*Auth, model, reservation string, tried map[string]struct{}, now time.Time) []*Auth {
+	var tiers [tierExcluded][]*Auth
+	modelKey := strings.TrimSpace(model)
+	registryRef := registry.GetGlobalRegistry()
+	for _, candidate := range pool {
 		if _, used := tried[candidate.ID]; used {
 			continue
 		}
-		if modelKey != "" && registryRef != nil && !registryRef.ClientSupportsModel(candidate.ID, modelKey) {
+		tier, _ := m.selectionTierLocked(candidate, modelKey, reservation, registryRef, now)
+		if tier == tierExcluded {
 			continue
 		}
-		candidates = append(candidates, candidate)
+		tiers[tier] = append(tiers[tier], candidate)
 	}
-	if len(candidates) == 0 {
-		m.mu.RUnlock()
-		return nil, nil, &Error{Code: "auth_not_found", Message: "no auth available"}
+	blocked := tiers[tierBlocked]
+	for _, candidates := range tiers[:tierBlocked] {
+		if len(candidates) > 0 {
+			// Blocked accounts ride along so the selector and prefix affinity weigh the same pool as
+			// before the fallback; both skip them.
+			return append(candidates, blocked...)
+		}
 	}
-	selected, errPick := m.selector.Pick(ctx, provider, model, opts, candidates)
-	if errPick != nil {
-		m.mu.RUnlock()
-		return nil, nil, errPick
+	return append([]*Auth{}, blocked...)
+}
+
+// pickNext selects the account for the next attempt and, for first attempts, records whether the
+// provider could serve the request for capacity planning.
+func (m *Manager) pickNext(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, tried map[string]struct{}) (*Auth, ProviderExecutor, error) {
+	auth, executor, err := m.selectNext(ctx, provider, model, opts, tried)
+	if len(tried) == 0 {
+		if err == nil {
+			m.capacity.record(provider, false, time.Now())
+		} else if isSaturationError(err) {
+			m.capacity.record(provider, true, time.Now())
+		}
 	}
-	if selected == nil {
+	return auth, executor, err
+}
+
+// selectNext picks the account for the next attempt and takes a concurrency slot on it, which the
+// request gives back through beginRequest. When the pick is at its concurrency limit, every eligible
+// account is, and the request queues for a released slot up to the queue timeout.
+func (m *Manager) selectNext(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, tried map[string]struct{}) (*Auth, ProviderExecutor, error) {
+	var (
+		executor ProviderExecutor
+		selected *Auth
+		now      time.Time
+		deadline time.Time
+	)
+	for {
+		wake := m.concurrency.wake()
+		var errSelect error
+		executor, selected, now, errSelect = m.selectLocked(ctx, provider, model, opts, tried)
+		if errSelect != nil {
+			return nil, nil, errSelect
+		}
+		if m.concurrency.acquire(selected.ID, selected.MaxConcurrent()) {
+			break
+		}
 		m.mu.RUnlock()
-		return nil, nil, &Error{Code: "auth_not_found", Message: "selector returned no auth"}
+		waitStarted := time.Now()
+		errWait := m.waitForSlot(ctx, provider, wake, &deadline)
+		UpstreamClockFrom(ctx).AddWait(time.Since(waitStarted))
+		if errWait != nil {
+			return nil, nil, errWait
+		}
+	}
+	if len(tried) == 0 {
+		m.history.record(provider, model, selected.ID)
 	}
 	authCopy := selected.Clone()
+	rotate := m.softRotation.Enabled
 	m.mu.RUnlock()
-	if !selected.indexAssigned {
+	m.caps.record(authCopy.ID, now)
+	if !selected.indexAssigned || rotate {
 		m.mu.Lock()
-		if current := m.auths[authCopy.ID]; current != nil && !current.indexAssigned {
-			current.EnsureIndex()
+		if current := m.auths[authCopy.ID]; current != nil {
+			if !current.indexAssigned {
+				current.EnsureIndex()
+			}
+			m.recordSoftRotationLocked(current, now)
 			authCopy = current.Clone()
 		}
 		m.mu.Unlock()
@@ -1129,6 +1665,92 @@ func (m *Manager) pickNext(ctx context.Context, provider, model string, opts cli
 	return authCopy, executor, nil
 }
 
+// selectLocked chooses an account of provider through pinning or the selector. On success it returns
+// with m.mu read-locked; on error the lock is released.
+func (m *Manager) selectLocked(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, tried map[string]struct{}) (ProviderExecutor, *Auth, time.Time, error) {
+	m.mu.RLock()
+	executor, okExecutor := m.executors[provider]
+	if !okExecutor {
+		m.mu.RUnlock()
+		return nil, nil, time.Time{}, &Error{Code: "executor_not_found", Message: "executor not registered"}
+	}
+	now := time.Now()
+	var selected *Auth
+	if id, fallback := pinnedAuth(opts); id != "" {
+		var errPin *Error
+		selected, errPin = m.pickPinnedLocked(id, provider, model, reservationName(opts), tried, now)
+		if errPin != nil && !fallback {
+			m.mu.RUnlock()
+			return nil, nil, now, errPin
+		}
+		if errPin != nil && len(tried) == 0 && strings.EqualFold(m.pinnedProviderLocked(id), provider) {
+			log.Infof("%s; falling back to account selection", errPin.Message)
+		}
+	}
+	if selected == nil {
+		var errSelect error
+		selected, errSelect = m.selectCandidateLocked(ctx, provider, model, opts, tried, now)
+		if errSelect != nil {
+			m.mu.RUnlock()
+			return nil, nil, now, errSelect
+		}
+	}
+	return executor, selected, now, nil
+}
+
+// selectCandidateLocked chooses an account of provider through routing, prefix affinity and the
+// selector. Callers must hold m.mu.
+func (m *Manager) selectCandidateLocked(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, tried map[string]struct{}, now time.Time) (*Auth, error) {
+	candidates, route := m.routedCandidatesLocked(m.providerPoolLocked(provider), model, reservationName(opts), tried, now)
+	if len(candidates) == 0 {
+		if route != nil && !route.Fallback {
+			return nil, routePoolUnavailable(model, *route)
+		}
+		return nil, &Error{Code: "auth_not_found", Message: "no auth available"}
+	}
+	var selected *Auth
+	affinityKey := prefixAffinityKey(opts)
+	var affinityCandidates []*Auth
+	if affinityKey != "" {
+		affinityCandidates = m.withFeature(candidates, FeaturePrefixAffinity)
+		// Candidates already exclude resting accounts while others are available; accounts that
+		// ran out of cap headroom are skipped here so affinity never overrides the caps.
+		hasHeadroom := func(auth *Auth) bool { return m.caps.hasHeadroom(auth, now) }
+		var hit bool
+		selected, hit = m.affinity.pick(affinityKey, provider, model, affinityCandidates, hasHeadroom, now)
+		if len(tried) == 0 && (selected != nil || m.affinity.pinning()) {
+			// Only first attempts count; retries exclude the accounts that already failed.
+			m.affinity.record(provider, hit)
+		}
+	}
+	if selected == nil {
+		var errPick error
+		strategy := m.clientStrategies.selectorFor(opts, m.selector, now)
+		selected, errPick = strategy.Pick(ctx, provider, model, opts, m.narrowCandidates(model, candidates, now))
+		if errPick != nil {
+			return nil, errPick
+		}
+		if affinityKey != "" {
+			m.affinity.pin(affinityKey, selected, affinityCandidates, now)
+		}
+	}
+	if selected == nil {
+		return nil, &Error{Code: "auth_not_found", Message: "selector returned no auth"}
+	}
+	return selected, nil
+}
+
+// narrowCandidates applies the narrowing steps, in order, to the candidates the selection strategy
+// picks from. Every step keeps the candidates unchanged when no available account would remain.
+func (m *Manager) narrowCandidates(model string, candidates []*Auth, now time.Time) []*Auth {
+	// Priority tier: lower priorities only serve while every higher one is unavailable.
+	candidates = preferPriority(model, candidates, now)
+	// Cap headroom: within the tier, the accounts furthest from their request caps.
+	candidates = m.caps.preferHeadroom(model, candidates, now)
+	// Expiry penalty: accounts whose token is about to expire are dropped at random.
+	return m.tokenPenalty.thin(model, candidates, now)
+}
+
 func (m *Manager) persist(ctx context.Context, auth *Auth) error {
 	if m.store == nil || auth == nil {
 		return nil
@@ -1164,6 +1786,7 @@ func (m *Manager) StartAutoRefresh(parent context.Context, interval time.Duratio
 	go func() {
 		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
+		m.staggerInitialRefreshes(time.Now())
 		m.checkRefreshes(ctx)
 		for {
 			select {
@@ -1187,8 +1810,13 @@ func (m *Manager) StopAutoRefresh() {
 func (m *Manager) checkRefreshes(ctx context.Context) {
 	// log.Debugf("checking refreshes")
 	now := time.Now()
+	m.checkPeakReserve(ctx, now)
 	snapshot := m.snapshotAuths()
 	for _, a := range snapshot {
+		if !m.OwnsAccount(a.ID) {
+			// Another instance refreshes accounts outside this shard.
+			continue
+		}
 		typ, _ := a.AccountInfo()
 		if typ != "api_key" {
 			if !m.shouldRefresh(a, now) {
@@ -1202,7 +1830,7 @@ func (m *Manager) checkRefreshes(ctx context.Context) {
 			if !m.markRefreshPending(a.ID, now) {
 				continue
 			}
-			go m.refreshAuth(ctx, a.ID)
+			m.refreshCoalesced(ctx, a.ID)
 		}
 	}
 }
@@ -1448,8 +2076,13 @@ func (m *Manager) refreshAuth(ctx context.Context, id string) {
 	if auth == nil || exec == nil {
 		return
 	}
+	release, errWait := m.refreshLimit.acquire(ctx, auth.Provider)
+	if errWait != nil {
+		return
+	}
 	cloned := auth.Clone()
 	updated, err := exec.Refresh(ctx, cloned)
+	release()
 	log.Debugf("refreshed %s, %s, %v", auth.Provider, auth.ID, err)
 	now := time.Now()
 	if err != nil {
@@ -1477,12 +2110,88 @@ func (m *Manager) refreshAuth(ctx context.Context, id string) {
 	_, _ = m.Update(ctx, updated)
 }
 
+// CredentialChecker is an optional interface for executors that can verify a credential with a
+// read-only upstream call, such as listing models, without refreshing or rotating its tokens.
+type CredentialChecker interface {
+	// CheckCredential returns nil when the upstream accepts the credential, or
+	// ErrCredentialCheckUnsupported when it cannot check this kind of credential.
+	CheckCredential(ctx context.Context, auth *Auth) error
+}
+
+// ErrCredentialCheckUnsupported is returned by CredentialChecker for credentials it cannot check.
+var ErrCredentialCheckUnsupported = errors.New("credential check not supported")
+
+// ValidateAuth checks an unregistered credential against its provider and returns the credential
+// to import. A read-only check is preferred; otherwise the refresh token is exchanged, which may
+// rotate it, and the returned copy carries the new tokens. Credentials that can be neither checked
+// nor refreshed fail with code "validation_unsupported". The pool is left untouched.
+func (m *Manager) ValidateAuth(ctx context.Context, auth *Auth) (*Auth, error) {
+	if m == nil || auth == nil {
+		return nil, &Error{Code: "invalid_auth", Message: "auth is nil"}
+	}
+	exec := m.executorFor(auth.Provider)
+	if exec == nil {
+		return nil, &Error{Code: "executor_not_found", Message: "no executor registered for provider " + auth.Provider}
+	}
+	candidate := auth.Clone()
+	if rt := m.roundTripperFor(candidate); rt != nil {
+		ctx = context.WithValue(ctx, roundTripperContextKey{}, rt)
+		ctx = context.WithValue(ctx, "cliproxy.roundtripper", rt)
+	}
+	if checker, ok := exec.(CredentialChecker); ok {
+		if err := checker.CheckCredential(ctx, candidate); !errors.Is(err, ErrCredentialCheckUnsupported) {
+			if err != nil {
+				return nil, err
+			}
+			return candidate, nil
+		}
+	}
+	if !hasRefreshToken(candidate.Metadata) {
+		// Refresh has nothing to exchange and would report success without contacting the provider.
+		return nil, &Error{Code: "validation_unsupported", Message: "provider " + auth.Provider + " cannot verify this credential without using it"}
+	}
+	updated, err := exec.Refresh(ctx, candidate)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		updated = candidate
+	}
+	return updated, nil
+}
+
+// hasRefreshToken reports whether metadata carries a refresh token, at the top level or in the
+// nested "token" object OAuth libraries persist.
+func hasRefreshToken(metadata map[string]any) bool {
+	if token, _ := metadata["refresh_token"].(string); strings.TrimSpace(token) != "" {
+		return true
+	}
+	nested, _ := metadata["token"].(map[string]any)
+	token, _ := nested["refresh_token"].(string)
+	return strings.TrimSpace(token) != ""
+}
+
 func (m *Manager) executorFor(provider string) ProviderExecutor {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	return m.executors[provider]
 }
 
+// Providers returns the providers with a registered executor, in order.
+func (m *Manager) Providers() []string {
+	if m == nil {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]string, 0, len(m.executors))
+	for provider := range m.executors {
+		out = append(out, provider)
+	}
+	sort.Strings(out)
+	return out
+}
+
 // roundTripperContextKey is an unexported context key type to avoid collisions.
 type roundTripperContextKey struct{}
 
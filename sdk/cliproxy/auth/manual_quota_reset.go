@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	log "github.com/sirupsen/logrus"
+)
+
+// ResetQuota clears the quota cooldown and backoff of the account and of its models, making it
+// selectable again. It reports whether the account exists and whether it was cooling down; accounts
+// that were not are returned unchanged.
+func (m *Manager) ResetQuota(ctx context.Context, id string) (*Auth, bool, bool) {
+	if m == nil {
+		return nil, false, false
+	}
+	now := time.Now()
+	m.mu.Lock()
+	auth, ok := m.auths[id]
+	if !ok || auth == nil {
+		m.mu.Unlock()
+		return nil, false, false
+	}
+	var models []string
+	for model, state := range auth.ModelStates {
+		if state != nil && quotaCooling(state.Quota) {
+			models = append(models, model)
+		}
+	}
+	if !quotaCooling(auth.Quota) && len(models) == 0 {
+		out := auth.Clone()
+		m.mu.Unlock()
+		return out, true, false
+	}
+	previous := auth.Quota
+	for _, model := range models {
+		resetModelState(auth.ModelStates[model], now)
+	}
+	auth.Quota = QuotaState{}
+	updateAggregatedAvailability(auth, now)
+	if !hasModelError(auth, now) && auth.Status != StatusDisabled && auth.Status != StatusBillingSuspended && auth.Status != StatusEgressBlocked {
+		auth.Unavailable = false
+		auth.NextRetryAfter = time.Time{}
+		auth.Status = StatusActive
+		auth.StatusMessage = ""
+		auth.LastError = nil
+	}
+	auth.UpdatedAt = now
+	m.recordRuntimeStateLocked(auth)
+	_ = m.persist(ctx, auth)
+	out := auth.Clone()
+	m.mu.Unlock()
+
+	for _, model := range models {
+		registry.GetGlobalRegistry().ClearModelQuotaExceeded(id, model)
+		registry.GetGlobalRegistry().ResumeClientModel(id, model)
+	}
+	log.Infof("auth %s (%s): quota cooldown cleared manually (reason %q, backoff level %d, %d model(s))", out.ID, out.Provider, previous.Reason, previous.BackoffLevel, len(models))
+	m.hook.OnAuthUpdated(ctx, out.Clone())
+	return out, true, true
+}
+
+func quotaCooling(q QuotaState) bool {
+	return q.Exceeded || q.BackoffLevel > 0 || !q.NextRecoverAt.IsZero()
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestResetQuotaClearsCooldown(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&slowRefreshExecutor{})
+	ctx := context.Background()
+	if _, err := m.Register(ctx, &Auth{ID: "a", Provider: "eager", Status: StatusActive}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if _, ok, changed := m.ResetQuota(ctx, "a"); !ok || changed {
+		t.Fatalf("healthy account: ok=%t changed=%t", ok, changed)
+	}
+	if _, ok, _ := m.ResetQuota(ctx, "missing"); ok {
+		t.Fatal("unknown account reported")
+	}
+
+	m.MarkResult(ctx, Result{AuthID: "a", Provider: "eager", Model: "m", Error: &Error{HTTPStatus: 429, Message: "quota"}})
+	m.MarkResult(ctx, Result{AuthID: "a", Provider: "eager", Error: &Error{HTTPStatus: 429, Message: "quota"}})
+	if auth, _ := m.GetByID("a"); !auth.Quota.Exceeded || auth.Quota.NextRecoverAt.IsZero() {
+		t.Fatalf("account not cooling down: %+v", auth.Quota)
+	}
+	if _, _, err := m.pickNext(ctx, "eager", "", cliproxyexecutor.Options{}, nil); err == nil {
+		t.Fatal("cooling account selected")
+	}
+
+	auth, ok, changed := m.ResetQuota(ctx, "a")
+	if !ok || !changed || auth.Quota != (QuotaState{}) || auth.Unavailable || auth.Status != StatusActive {
+		t.Fatalf("quota not reset: %+v", auth)
+	}
+	if state := auth.ModelStates["m"]; state == nil || state.Quota.Exceeded || state.Unavailable {
+		t.Fatalf("model quota not reset: %+v", state)
+	}
+	if blocked, _, _ := isAuthBlockedForModel(auth, "m", time.Now()); blocked {
+		t.Fatal("model still blocked after reset")
+	}
+	if picked, _, err := m.pickNext(ctx, "eager", "", cliproxyexecutor.Options{}, nil); err != nil || picked.ID != "a" {
+		t.Fatalf("account not selectable after reset: %v", err)
+	}
+}
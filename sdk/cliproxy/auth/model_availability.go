@@ -0,0 +1,218 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+)
+
+// modelWindowsMetadataKey stores an account's model availability windows in its metadata.
+const modelWindowsMetadataKey = "model_windows"
+
+var peakWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParsePeakWindow parses a recurring window from weekday names ("mon".."sun", empty for every day)
+// and "HH:MM" start and end times.
+func ParsePeakWindow(days []string, start, end string) (PeakWindow, error) {
+	var window PeakWindow
+	for _, raw := range days {
+		day := strings.ToLower(strings.TrimSpace(raw))
+		if len(day) > 3 {
+			day = day[:3]
+		}
+		weekday, ok := peakWeekdays[day]
+		if !ok {
+			return window, fmt.Errorf("unknown day %q", raw)
+		}
+		window.Days = append(window.Days, weekday)
+	}
+	startAt, err := time.Parse("15:04", strings.TrimSpace(start))
+	if err != nil {
+		return window, fmt.Errorf("invalid start %q", start)
+	}
+	endAt, err := time.Parse("15:04", strings.TrimSpace(end))
+	if err != nil {
+		return window, fmt.Errorf("invalid end %q", end)
+	}
+	window.Start = time.Duration(startAt.Hour())*time.Hour + time.Duration(startAt.Minute())*time.Minute
+	window.End = time.Duration(endAt.Hour())*time.Hour + time.Duration(endAt.Minute())*time.Minute
+	return window, nil
+}
+
+// ModelWindowSchedule restricts a model on one account to recurring availability windows, e.g. a
+// preview model the provider only serves during certain hours.
+type ModelWindowSchedule struct {
+	// Timezone is the IANA zone the windows are defined in (default UTC).
+	Timezone string            `json:"timezone,omitempty"`
+	Windows  []ModelWindowSpec `json:"windows"`
+}
+
+// ModelWindowSpec is one recurring window; an end at or before start runs past midnight.
+type ModelWindowSpec struct {
+	Days  []string `json:"days,omitempty"`
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+}
+
+// schedule converts the spec into the window check shared with peak reserves.
+func (s ModelWindowSchedule) schedule() (PeakReservePolicy, error) {
+	policy := PeakReservePolicy{Location: time.UTC}
+	if tz := strings.TrimSpace(s.Timezone); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return policy, fmt.Errorf("invalid timezone %q", tz)
+		}
+		policy.Location = loc
+	}
+	if len(s.Windows) == 0 {
+		return policy, fmt.Errorf("at least one window is required")
+	}
+	for i, w := range s.Windows {
+		window, err := ParsePeakWindow(w.Days, w.Start, w.End)
+		if err != nil {
+			return policy, fmt.Errorf("window %d: %w", i, err)
+		}
+		policy.Windows = append(policy.Windows, window)
+	}
+	return policy, nil
+}
+
+// Validate reports whether the schedule has a known timezone and well-formed windows.
+func (s ModelWindowSchedule) Validate() error {
+	_, err := s.schedule()
+	return err
+}
+
+// ModelWindows returns the availability windows configured per model on the account.
+func (a *Auth) ModelWindows() map[string]ModelWindowSchedule {
+	if a == nil || a.Metadata == nil {
+		return nil
+	}
+	raw, ok := a.Metadata[modelWindowsMetadataKey]
+	if !ok || raw == nil {
+		return nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var out map[string]ModelWindowSchedule
+	if err = json.Unmarshal(data, &out); err != nil || len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// SetModelWindows replaces the account's model availability windows; an empty map removes them.
+func (a *Auth) SetModelWindows(windows map[string]ModelWindowSchedule) {
+	if a == nil {
+		return
+	}
+	if len(windows) == 0 {
+		if a.Metadata != nil {
+			delete(a.Metadata, modelWindowsMetadataKey)
+		}
+		return
+	}
+	if a.Metadata == nil {
+		a.Metadata = make(map[string]any)
+	}
+	// Store the generic JSON form so the value matches what is read back from disk.
+	data, _ := json.Marshal(windows)
+	var mirrored map[string]any
+	_ = json.Unmarshal(data, &mirrored)
+	a.Metadata[modelWindowsMetadataKey] = mirrored
+}
+
+// modelWindowOpen reports whether the account may serve model at now. Models without a schedule are
+// always open; a schedule that does not parse is ignored rather than taking the account out.
+func (a *Auth) modelWindowOpen(model string, now time.Time) bool {
+	windows := a.ModelWindows()
+	if len(windows) == 0 {
+		return true
+	}
+	spec, ok := windows[model]
+	if !ok {
+		return true
+	}
+	schedule, err := spec.schedule()
+	if err != nil {
+		return true
+	}
+	return schedule.active(now)
+}
+
+// ModelWindowStatus summarises the availability of a time-windowed model across the accounts that
+// serve it.
+type ModelWindowStatus struct {
+	// Available is true when at least one account may serve the model right now.
+	Available bool `json:"available"`
+	// Accounts is the number of enabled accounts serving the model.
+	Accounts int `json:"accounts"`
+	// Windowed is the number of those accounts that restrict the model to windows.
+	Windowed int `json:"windowed"`
+	// InWindow is the number of windowed accounts currently inside a window.
+	InWindow int `json:"in_window"`
+}
+
+// ModelWindowStatuses reports every model that at least one account restricts to availability
+// windows, keyed by model name.
+func (m *Manager) ModelWindowStatuses(now time.Time) map[string]ModelWindowStatus {
+	if m == nil {
+		return nil
+	}
+	auths := m.List()
+	windowed := make(map[string]map[string]bool)
+	for _, auth := range auths {
+		if auth == nil || auth.Disabled {
+			continue
+		}
+		for model := range auth.ModelWindows() {
+			if windowed[model] == nil {
+				windowed[model] = make(map[string]bool)
+			}
+			windowed[model][auth.ID] = auth.modelWindowOpen(model, now)
+		}
+	}
+	if len(windowed) == 0 {
+		return nil
+	}
+	reg := registry.GetGlobalRegistry()
+	out := make(map[string]ModelWindowStatus, len(windowed))
+	models := make([]string, 0, len(windowed))
+	for model := range windowed {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	for _, model := range models {
+		var status ModelWindowStatus
+		for _, auth := range auths {
+			if auth == nil || auth.Disabled {
+				continue
+			}
+			open, isWindowed := windowed[model][auth.ID]
+			if !isWindowed {
+				if reg == nil || !reg.ClientSupportsModel(auth.ID, model) {
+					continue
+				}
+				open = true
+			} else {
+				status.Windowed++
+				if open {
+					status.InWindow++
+				}
+			}
+			status.Accounts++
+			status.Available = status.Available || open
+		}
+		out[model] = status
+	}
+	return out
+}
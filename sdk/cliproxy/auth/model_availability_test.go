@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+)
+
+func TestModelWindowOpenRespectsTimezone(t *testing.T) {
+	auth := &Auth{ID: "a"}
+	auth.SetModelWindows(map[string]ModelWindowSchedule{
+		"preview": {Timezone: "America/New_York", Windows: []ModelWindowSpec{{Days: []string{"mon"}, Start: "09:00", End: "17:00"}}},
+	})
+	// Monday 2026-10-12 14:00 UTC is 10:00 in New York.
+	monday := time.Date(2026, 10, 12, 14, 0, 0, 0, time.UTC)
+	if !auth.modelWindowOpen("preview", monday) {
+		t.Fatal("window closed at 10:00 New York time")
+	}
+	if auth.modelWindowOpen("preview", monday.Add(-2*time.Hour)) {
+		t.Fatal("window open at 08:00 New York time")
+	}
+	if auth.modelWindowOpen("preview", monday.Add(24*time.Hour)) {
+		t.Fatal("window open on Tuesday")
+	}
+	if !auth.modelWindowOpen("stable", monday.Add(-2*time.Hour)) {
+		t.Fatal("model without a schedule restricted")
+	}
+}
+
+func TestModelWindowScheduleValidate(t *testing.T) {
+	cases := []ModelWindowSchedule{
+		{Timezone: "Mars/Olympus", Windows: []ModelWindowSpec{{Start: "09:00", End: "10:00"}}},
+		{Windows: nil},
+		{Windows: []ModelWindowSpec{{Start: "9am", End: "10:00"}}},
+		{Windows: []ModelWindowSpec{{Days: []string{"someday"}, Start: "09:00", End: "10:00"}}},
+	}
+	for _, schedule := range cases {
+		if schedule.Validate() == nil {
+			t.Errorf("schedule %+v accepted", schedule)
+		}
+	}
+	if err := (ModelWindowSchedule{Windows: []ModelWindowSpec{{Start: "22:00", End: "02:00"}}}).Validate(); err != nil {
+		t.Fatalf("overnight window rejected: %v", err)
+	}
+}
+
+func TestSelectionSkipsModelsOutsideTheirWindow(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	windowed := &Auth{ID: "windowed", Provider: "gemini", Status: StatusActive}
+	windowed.SetModelWindows(map[string]ModelWindowSchedule{
+		"preview": {Windows: []ModelWindowSpec{{Start: "09:00", End: "17:00"}}},
+	})
+	for _, auth := range []*Auth{windowed, {ID: "plain", Provider: "gemini", Status: StatusActive}} {
+		if _, err := m.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("plain", "gemini", []*registry.ModelInfo{{ID: "preview"}})
+	t.Cleanup(func() { reg.UnregisterClient("plain") })
+	reg.RegisterClient("windowed", "gemini", []*registry.ModelInfo{{ID: "preview"}})
+	t.Cleanup(func() { reg.UnregisterClient("windowed") })
+
+	inside := time.Date(2026, 10, 12, 12, 0, 0, 0, time.UTC)
+	outside := time.Date(2026, 10, 12, 20, 0, 0, 0, time.UTC)
+	candidates := func(now time.Time) []string {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		var ids []string
+		for _, auth := range m.selectionCandidatesLocked([]*Auth{m.auths["windowed"], m.auths["plain"]}, "preview", "", nil, now) {
+			ids = append(ids, auth.ID)
+		}
+		return ids
+	}
+	if got := candidates(inside); len(got) != 2 {
+		t.Fatalf("inside the window: %v", got)
+	}
+	if got := candidates(outside); len(got) != 1 || got[0] != "plain" {
+		t.Fatalf("outside the window: %v", got)
+	}
+
+	status := m.ModelWindowStatuses(outside)["preview"]
+	if status != (ModelWindowStatus{Available: true, Accounts: 2, Windowed: 1}) {
+		t.Fatalf("status outside the window = %+v", status)
+	}
+	if status = m.ModelWindowStatuses(inside)["preview"]; status.InWindow != 1 {
+		t.Fatalf("status inside the window = %+v", status)
+	}
+}
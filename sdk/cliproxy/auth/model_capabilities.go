@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// modelCapabilities holds the catalog of capabilities per model used for capability requests.
+type modelCapabilities struct {
+	mu      sync.RWMutex
+	catalog map[string][]string
+}
+
+// SetModelCapabilities replaces the capability catalog. A request for a model named
+// "capabilities:<cap>[,<cap>...]" may be served by any account serving a catalog model that covers
+// every requested capability.
+func (m *Manager) SetModelCapabilities(catalog map[string][]string) {
+	if m == nil {
+		return
+	}
+	copied := make(map[string][]string, len(catalog))
+	for model, capabilities := range catalog {
+		copied[model] = append([]string(nil), capabilities...)
+	}
+	m.capabilities.mu.Lock()
+	m.capabilities.catalog = copied
+	m.capabilities.mu.Unlock()
+}
+
+// resolve returns the model auth serves a request for model with. Capability requests resolve to the
+// first matching catalog model the account serves, preferring one it can serve right away; served is
+// false when it serves none. Other models are returned unchanged.
+func (c *modelCapabilities) resolve(auth *Auth, model string, registryRef *registry.ModelRegistry, now time.Time) (_ string, served bool) {
+	required, ok := util.ParseCapabilityRequest(model)
+	if !ok {
+		return model, true
+	}
+	c.mu.RLock()
+	models, err := util.CapabilityModels(required, c.catalog)
+	c.mu.RUnlock()
+	if err != nil {
+		return "", false
+	}
+	fallback := ""
+	for _, candidate := range models {
+		if registryRef != nil && !registryRef.ClientSupportsModel(auth.ID, candidate) {
+			continue
+		}
+		if blocked, _, _ := isAuthBlockedForModel(auth, candidate, now); !blocked && auth.modelWindowOpen(candidate, now) {
+			return candidate, true
+		}
+		if fallback == "" {
+			fallback = candidate
+		}
+	}
+	return fallback, fallback != ""
+}
+
+// request returns req with a capability request resolved to the model auth serves it with, so the
+// executor, cooldowns and results see the concrete model.
+func (c *modelCapabilities) request(auth *Auth, req cliproxyexecutor.Request) cliproxyexecutor.Request {
+	if model, served := c.resolve(auth, req.Model, registry.GetGlobalRegistry(), time.Now()); served {
+		req.Model = model
+	}
+	return req
+}
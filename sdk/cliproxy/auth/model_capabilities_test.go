@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// modelEchoExecutor answers every request with the model it was sent.
+type modelEchoExecutor struct{}
+
+func (modelEchoExecutor) Identifier() string { return "capable" }
+
+func (modelEchoExecutor) Execute(_ context.Context, _ *Auth, req cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{Payload: []byte(req.Model)}, nil
+}
+
+func (modelEchoExecutor) ExecuteStream(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (<-chan cliproxyexecutor.StreamChunk, error) {
+	return nil, errors.New("not supported")
+}
+
+func (modelEchoExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) { return auth, nil }
+
+func (modelEchoExecutor) CountTokens(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{}, nil
+}
+
+func newCapabilityManager(t *testing.T, served map[string][]string) *Manager {
+	t.Helper()
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(modelEchoExecutor{})
+	m.SetModelCapabilities(map[string][]string{
+		"cap-vision-model": {"vision", "tools"},
+		"cap-vision-lite":  {"vision"},
+		"cap-plain-model":  {"tools"},
+	})
+	reg := registry.GetGlobalRegistry()
+	for id, models := range served {
+		if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: "capable", Status: StatusActive}); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+		infos := make([]*registry.ModelInfo, 0, len(models))
+		for _, model := range models {
+			infos = append(infos, &registry.ModelInfo{ID: model})
+		}
+		reg.RegisterClient(id, "capable", infos)
+		clientID := id
+		t.Cleanup(func() { reg.UnregisterClient(clientID) })
+	}
+	return m
+}
+
+func TestCapabilityRequestSelectsMatchingAccounts(t *testing.T) {
+	m := newCapabilityManager(t, map[string][]string{
+		"cap-plain":  {"cap-plain-model"},
+		"cap-vision": {"cap-vision-model"},
+	})
+
+	for i := 0; i < 3; i++ {
+		auth, _, err := m.pickNext(context.Background(), "capable", "capabilities:vision,tools", cliproxyexecutor.Options{}, nil)
+		if err != nil || auth.ID != "cap-vision" {
+			t.Fatalf("pick %d = %v, %v; want cap-vision", i, auth, err)
+		}
+		m.beginRequest(auth.ID)()
+	}
+	resp, err := m.Execute(context.Background(), []string{"capable"}, cliproxyexecutor.Request{Model: "capabilities:vision"}, cliproxyexecutor.Options{})
+	if err != nil || string(resp.Payload) != "cap-vision-model" {
+		t.Fatalf("execute = %q, %v; want the request sent as cap-vision-model", resp.Payload, err)
+	}
+
+	for _, model := range []string{"capabilities:audio", "capabilities:teleport"} {
+		if _, _, err = m.pickNext(context.Background(), "capable", model, cliproxyexecutor.Options{}, nil); err == nil {
+			t.Fatalf("%s matched an account", model)
+		}
+	}
+}
+
+func TestCapabilityRequestPrefersModelTheAccountCanServe(t *testing.T) {
+	m := newCapabilityManager(t, map[string][]string{
+		"cap-both": {"cap-vision-model", "cap-vision-lite"},
+	})
+	m.mu.Lock()
+	m.auths["cap-both"].ModelStates = map[string]*ModelState{
+		"cap-vision-lite": {Status: StatusError, Unavailable: true, NextRetryAfter: time.Now().Add(time.Minute)},
+	}
+	m.mu.Unlock()
+
+	resp, err := m.Execute(context.Background(), []string{"capable"}, cliproxyexecutor.Request{Model: "capabilities:vision"}, cliproxyexecutor.Options{})
+	if err != nil || string(resp.Payload) != "cap-vision-model" {
+		t.Fatalf("execute = %q, %v; want the model that is not cooling down", resp.Payload, err)
+	}
+	m.mu.RLock()
+	_, tracked := m.auths["cap-both"].ModelStates["capabilities:vision"]
+	m.mu.RUnlock()
+	if tracked {
+		t.Fatal("result was recorded under the capability name instead of the concrete model")
+	}
+}
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"testing"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestRemapRequestModel(t *testing.T) {
+	auth := &Auth{ID: "vertex-1", Metadata: map[string]any{
+		"model_remap": map[string]any{
+			"gemini-2.5-pro": "projects/p/locations/us-central1/publishers/google/models/gemini-2.5-pro",
+			"ignored":        42,
+		},
+	}}
+	if got := auth.ModelRemap(); len(got) != 1 {
+		t.Fatalf("expected one valid remap entry, got %v", got)
+	}
+
+	req := cliproxyexecutor.Request{Model: "gemini-2.5-pro"}
+	remapped := remapRequestModel(auth, req)
+	if remapped.Model != "projects/p/locations/us-central1/publishers/google/models/gemini-2.5-pro" {
+		t.Fatalf("unexpected upstream model %q", remapped.Model)
+	}
+	if req.Model != "gemini-2.5-pro" {
+		t.Fatalf("canonical request model must be left untouched, got %q", req.Model)
+	}
+	if got := remapRequestModel(auth, cliproxyexecutor.Request{Model: "gemini-2.5-flash"}); got.Model != "gemini-2.5-flash" {
+		t.Fatalf("unmapped model should pass through, got %q", got.Model)
+	}
+}
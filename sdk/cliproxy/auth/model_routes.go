@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ModelRoute sends requests for matching model names to a preferred account pool. Exactly one of
+// Model (a glob where '*' matches any run of characters and '?' one character) or Regex is set.
+type ModelRoute struct {
+	Model string `json:"model,omitempty"`
+	Regex string `json:"regex,omitempty"`
+	// Provider limits the pool to accounts of this provider; empty keeps every provider.
+	Provider string `json:"provider,omitempty"`
+	// Tag limits the pool to accounts carrying this tag; empty keeps every account.
+	Tag string `json:"tag,omitempty"`
+	// Fallback uses the default pool when no account of the routed pool is available; otherwise the
+	// request fails with route_pool_unavailable.
+	Fallback bool `json:"fallback"`
+}
+
+type compiledModelRoute struct {
+	ModelRoute
+	pattern *regexp.Regexp
+}
+
+// modelRoutes holds the routing table in precedence order.
+type modelRoutes struct {
+	mu     sync.RWMutex
+	routes []compiledModelRoute
+}
+
+// SetModelRoutes replaces the model routing table. Routes naming an exact model take precedence over
+// patterns; otherwise the first matching route in the given order wins. Invalid routes are skipped.
+func (m *Manager) SetModelRoutes(routes []ModelRoute) {
+	if m == nil {
+		return
+	}
+	var exact, patterns []compiledModelRoute
+	for i, route := range routes {
+		compiled, err := compileModelRoute(route)
+		if err != nil {
+			log.Warnf("model-routes[%d] ignored: %v", i, err)
+			continue
+		}
+		if compiled.Regex == "" && !strings.ContainsAny(compiled.Model, "*?") {
+			exact = append(exact, compiled)
+		} else {
+			patterns = append(patterns, compiled)
+		}
+	}
+	m.routes.mu.Lock()
+	m.routes.routes = append(exact, patterns...)
+	m.routes.mu.Unlock()
+}
+
+func compileModelRoute(route ModelRoute) (compiledModelRoute, error) {
+	route.Model = strings.TrimSpace(route.Model)
+	route.Regex = strings.TrimSpace(route.Regex)
+	route.Provider = strings.ToLower(strings.TrimSpace(route.Provider))
+	route.Tag = strings.ToLower(strings.TrimSpace(route.Tag))
+	if (route.Model == "") == (route.Regex == "") {
+		return compiledModelRoute{}, fmt.Errorf("exactly one of model or regex is required")
+	}
+	if route.Provider == "" && route.Tag == "" {
+		return compiledModelRoute{}, fmt.Errorf("route for %q names neither a provider nor a tag", route.Model+route.Regex)
+	}
+	expr := route.Regex
+	if expr == "" {
+		expr = globExpression(route.Model)
+	}
+	pattern, err := regexp.Compile("(?i)" + expr)
+	if err != nil {
+		return compiledModelRoute{}, fmt.Errorf("invalid regex %q: %w", route.Regex, err)
+	}
+	return compiledModelRoute{ModelRoute: route, pattern: pattern}, nil
+}
+
+// globExpression anchors glob as a regular expression.
+func globExpression(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+func (r *modelRoutes) match(model string) (ModelRoute, bool) {
+	model = strings.TrimSpace(model)
+	if model == "" {
+		return ModelRoute{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, route := range r.routes {
+		if route.pattern.MatchString(model) {
+			return route.ModelRoute, true
+		}
+	}
+	return ModelRoute{}, false
+}
+
+// admits reports whether auth belongs to the routed pool.
+func (r ModelRoute) admits(auth *Auth) bool {
+	if r.Provider != "" && !strings.EqualFold(auth.Provider, r.Provider) {
+		return false
+	}
+	return r.Tag == "" || auth.HasTag(r.Tag)
+}
+
+// ModelRoutes returns the effective routing table in precedence order.
+func (m *Manager) ModelRoutes() []ModelRoute {
+	if m == nil {
+		return nil
+	}
+	m.routes.mu.RLock()
+	defer m.routes.mu.RUnlock()
+	out := make([]ModelRoute, 0, len(m.routes.routes))
+	for _, route := range m.routes.routes {
+		out = append(out, route.ModelRoute)
+	}
+	return out
+}
+
+// RouteForModel returns the route applied to requests for model.
+func (m *Manager) RouteForModel(model string) (ModelRoute, bool) {
+	if m == nil {
+		return ModelRoute{}, false
+	}
+	return m.routes.match(model)
+}
+
+// routeProviders orders providers for a request of model: a routed provider is tried alone, or first
+// when the route falls back to the default pool.
+func (m *Manager) routeProviders(model string, providers []string) []string {
+	route, ok := m.routes.match(model)
+	if !ok || route.Provider == "" {
+		return providers
+	}
+	if !route.Fallback {
+		return []string{route.Provider}
+	}
+	ordered := make([]string, 0, len(providers)+1)
+	ordered = append(ordered, route.Provider)
+	for _, provider := range providers {
+		if provider != route.Provider {
+			ordered = append(ordered, provider)
+		}
+	}
+	return ordered
+}
+
+// routedCandidatesLocked narrows pool to the routed pool of model before selection. It reports the
+// matched route so callers can tell an exhausted routed pool from an empty default pool.
+// Callers must hold m.mu.
+func (m *Manager) routedCandidatesLocked(pool []*Auth, model, reservation string, tried map[string]struct{}, now time.Time) ([]*Auth, *ModelRoute) {
+	route, ok := m.routes.match(model)
+	if !ok {
+		return m.selectionCandidatesLocked(pool, model, reservation, tried, now), nil
+	}
+	routed := make([]*Auth, 0, len(pool))
+	for _, auth := range pool {
+		if route.admits(auth) {
+			routed = append(routed, auth)
+		}
+	}
+	candidates := m.selectionCandidatesLocked(routed, model, reservation, tried, now)
+	if len(candidates) == 0 && route.Fallback {
+		candidates = m.selectionCandidatesLocked(pool, model, reservation, tried, now)
+	}
+	return candidates, &route
+}
+
+// routePoolUnavailable is returned when a route without fallback has no available account.
+func routePoolUnavailable(model string, route ModelRoute) *Error {
+	pool := route.Provider
+	if route.Tag != "" {
+		pool = strings.TrimPrefix(pool+" tag "+route.Tag, " ")
+	}
+	return &Error{
+		Code:       "route_pool_unavailable",
+		Message:    fmt.Sprintf("no account of the pool routed for model %s (%s) is available", model, pool),
+		HTTPStatus: http.StatusServiceUnavailable,
+	}
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestModelRoutePrecedence(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetModelRoutes([]ModelRoute{
+		{Model: "gpt-*", Provider: "codex"},
+		{Regex: "^gpt-4o(-mini)?$", Provider: "openai"},
+		{Model: "gpt-4o-mini", Provider: "azure"},
+		{Model: "claude-?-opus", Tag: "team-a"},
+		{Regex: "([", Provider: "broken"},
+		{Model: "no-pool"},
+	})
+
+	cases := map[string]string{
+		"gpt-4o-mini":   "azure", // an exact name beats every pattern
+		"gpt-4o":        "codex", // overlapping patterns: the first listed wins
+		"GPT-5":         "codex", // matching ignores case
+		"claude-4-opus": "",
+		"gemini-pro":    "-",
+	}
+	for model, want := range cases {
+		route, ok := m.RouteForModel(model)
+		switch {
+		case want == "-" && ok:
+			t.Errorf("%s routed to %+v", model, route)
+		case want != "-" && (!ok || route.Provider != want):
+			t.Errorf("%s: route = %+v, %t; want provider %q", model, route, ok, want)
+		}
+	}
+	if route, _ := m.RouteForModel("claude-4-opus"); route.Tag != "team-a" {
+		t.Errorf("claude-4-opus: tag = %q", route.Tag)
+	}
+	if routes := m.ModelRoutes(); len(routes) != 4 || routes[0].Model != "gpt-4o-mini" {
+		t.Fatalf("effective routes = %+v", routes)
+	}
+}
+
+func TestModelRouteRestrictsSelectionToPool(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&batchingExecutor{})
+	reg := registry.GetGlobalRegistry()
+	for _, auth := range []*Auth{
+		{ID: "route-team", Provider: "batchy", Tags: []string{"team-a"}},
+		{ID: "route-shared", Provider: "batchy"},
+	} {
+		if _, err := m.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+		reg.RegisterClient(auth.ID, "batchy", []*registry.ModelInfo{{ID: "routed-model"}})
+		id := auth.ID
+		t.Cleanup(func() { reg.UnregisterClient(id) })
+	}
+	m.SetModelRoutes([]ModelRoute{{Model: "routed-*", Tag: "team-a"}})
+
+	for i := 0; i < 3; i++ {
+		auth, _, err := m.pickNext(context.Background(), "batchy", "routed-model", cliproxyexecutor.Options{}, map[string]struct{}{})
+		if err != nil || auth.ID != "route-team" {
+			t.Fatalf("pick %d = %v, %v; want route-team", i, auth, err)
+		}
+	}
+
+	tried := map[string]struct{}{"route-team": {}}
+	_, _, err := m.pickNext(context.Background(), "batchy", "routed-model", cliproxyexecutor.Options{}, tried)
+	var authErr *Error
+	if !errors.As(err, &authErr) || authErr.Code != "route_pool_unavailable" || authErr.HTTPStatus != http.StatusServiceUnavailable {
+		t.Fatalf("exhausted pool without fallback: %v", err)
+	}
+
+	m.SetModelRoutes([]ModelRoute{{Model: "routed-*", Tag: "team-a", Fallback: true}})
+	auth, _, err := m.pickNext(context.Background(), "batchy", "routed-model", cliproxyexecutor.Options{}, tried)
+	if err != nil || auth.ID != "route-shared" {
+		t.Fatalf("fallback pick = %v, %v; want route-shared", auth, err)
+	}
+}
+
+func TestModelRouteOrdersProviders(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetModelRoutes([]ModelRoute{
+		{Model: "forced", Provider: "claude"},
+		{Model: "preferred", Provider: "claude", Fallback: true},
+	})
+	providers := []string{"gemini", "claude", "vertex"}
+	if got := m.routeProviders("forced", providers); len(got) != 1 || got[0] != "claude" {
+		t.Fatalf("forced providers = %v", got)
+	}
+	if got := m.routeProviders("preferred", providers); len(got) != 3 || got[0] != "claude" || got[1] != "gemini" {
+		t.Fatalf("preferred providers = %v", got)
+	}
+	if got := m.routeProviders("other", providers); len(got) != 3 || got[0] != "gemini" {
+		t.Fatalf("unrouted providers = %v", got)
+	}
+}
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// Peeker is implemented by selectors that can report their next pick without advancing their
+// rotation state. Selectors without it cannot be dry-run.
+type Peeker interface {
+	Peek(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error)
+}
+
+// Skip reasons reported by the next-account dry run on top of the selector exclusion reasons.
+const (
+	SkipDuplicate         = ExclusionDuplicate
+	SkipDrillCordoned     = ExclusionDrillCordoned
+	SkipRateLimitCordoned = ExclusionRateLimitCordoned
+	SkipLowerPriority     = "lower_priority"
+	SkipLessHeadroom      = "less_headroom"
+	// SkipNotNext accounts are eligible but the strategy's rotation points at another account.
+	SkipNotNext = "not_next"
+)
+
+// SkippedAccount explains why the dry run did not pick an account. Until is set when the account
+// becomes eligible again at a known time.
+type SkippedAccount struct {
+	ID     string     `json:"id"`
+	Label  string     `json:"label,omitempty"`
+	Reason string     `json:"reason"`
+	Until  *time.Time `json:"until,omitempty"`
+}
+
+// NextAccountPreview is the result of running account selection without dispatching a request.
+type NextAccountPreview struct {
+	Provider string           `json:"provider"`
+	Model    string           `json:"model,omitempty"`
+	Strategy string           `json:"strategy"`
+	Selected *Auth            `json:"-"`
+	Error    string           `json:"error,omitempty"`
+	Skipped  []SkippedAccount `json:"skipped"`
+	// TokenPenalties holds the token expiry penalty of accounts with a non-zero one: the probability
+	// that selection passes them over in favour of fresher accounts. The dry run does not apply it.
+	TokenPenalties map[string]float64 `json:"token_penalties,omitempty"`
+}
+
+// PreviewNextAccount runs the selection logic for provider and model and reports the account the next
+// request would use and why every other account of the provider was passed over. Rotation cursors,
+// counters and in-flight state are left untouched; prefix affinity and client overrides are ignored.
+func (m *Manager) PreviewNextAccount(ctx context.Context, provider, model string) NextAccountPreview {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	model = strings.TrimSpace(model)
+	preview := NextAccountPreview{Provider: provider, Model: model, Skipped: []SkippedAccount{}}
+	if m == nil {
+		preview.Error = "auth manager not available"
+		return preview
+	}
+	now := time.Now()
+	open := make(map[string]struct{})
+	for _, st := range m.CircuitBreakers() {
+		if st.State == CircuitOpen && (st.NextProbeAt == nil || now.Before(*st.NextProbeAt)) {
+			open[st.Provider] = struct{}{}
+		}
+	}
+	registryRef := registry.GetGlobalRegistry()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	preview.Strategy = strategyName(m.selector)
+	pool := m.providerPoolLocked(provider)
+	candidates, _ := m.routedCandidatesLocked(pool, model, "", nil, now)
+	prioritized := preferPriority(model, candidates, now)
+	narrowed := m.caps.preferHeadroom(model, prioritized, now)
+
+	var selected *Auth
+	switch _, circuitOpen := open[provider]; {
+	case len(pool) == 0:
+		preview.Error = "no accounts registered for provider"
+	case circuitOpen:
+		preview.Error = "provider circuit breaker is open"
+	case len(candidates) == 0:
+		preview.Error = "no auth available"
+	default:
+		peeker, ok := m.selector.(Peeker)
+		if !ok {
+			preview.Error = "selection strategy " + preview.Strategy + " does not support dry runs"
+			break
+		}
+		picked, err := peeker.Peek(ctx, provider, model, cliproxyexecutor.Options{}, narrowed)
+		if err != nil {
+			preview.Error = err.Error()
+			break
+		}
+		selected = picked
+		preview.Selected = picked.Clone()
+	}
+
+	inSet := func(set []*Auth, id string) bool {
+		for _, a := range set {
+			if a.ID == id {
+				return true
+			}
+		}
+		return false
+	}
+	for _, auth := range pool {
+		if penalty := m.TokenExpiryPenalty(auth, now); penalty > 0 {
+			if preview.TokenPenalties == nil {
+				preview.TokenPenalties = make(map[string]float64)
+			}
+			preview.TokenPenalties[auth.ID] = math.Round(penalty*1000) / 1000
+		}
+		if selected != nil && auth.ID == selected.ID {
+			continue
+		}
+		skip := SkippedAccount{ID: auth.ID, Label: auth.Label, Reason: m.exclusionReasonLocked(auth, model, open, registryRef, now)}
+		switch skip.Reason {
+		case ExclusionResting, ExclusionRateLimitCordoned, ExclusionSaturated:
+			// Deprioritized accounts the selection fell back to are weighed like any other.
+			if inSet(candidates, auth.ID) {
+				skip.Reason = ""
+			}
+		}
+		switch skip.Reason {
+		case "":
+			switch {
+			case !inSet(prioritized, auth.ID):
+				skip.Reason = SkipLowerPriority
+			case !inSet(narrowed, auth.ID):
+				skip.Reason = SkipLessHeadroom
+			default:
+				skip.Reason = SkipNotNext
+			}
+		case ExclusionCooldown, ExclusionUnavailable:
+			if _, _, next := isAuthBlockedForModel(auth, model, now); !next.IsZero() {
+				skip.Until = &next
+			}
+		case ExclusionResting:
+			until := auth.RotationRestUntil
+			skip.Until = &until
+		}
+		preview.Skipped = append(preview.Skipped, skip)
+	}
+	sort.Slice(preview.Skipped, func(i, j int) bool { return preview.Skipped[i].ID < preview.Skipped[j].ID })
+	return preview
+}
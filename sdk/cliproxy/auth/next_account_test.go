@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestPreviewNextAccountDoesNotAdvanceRotation(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&batchingExecutor{})
+	cooldownUntil := time.Now().Add(time.Minute)
+	for _, auth := range []*Auth{
+		{ID: "a", Provider: "batchy"},
+		{ID: "b", Provider: "batchy"},
+		{ID: "c", Provider: "batchy", Disabled: true},
+		{ID: "d", Provider: "batchy", Unavailable: true, NextRetryAfter: cooldownUntil, Quota: QuotaState{Exceeded: true}},
+	} {
+		if _, err := m.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		preview := m.PreviewNextAccount(context.Background(), "batchy", "")
+		if preview.Selected == nil || preview.Selected.ID != "a" {
+			t.Fatalf("dry run %d: expected a, got %+v", i, preview)
+		}
+	}
+	preview := m.PreviewNextAccount(context.Background(), "batchy", "")
+	reasons := make(map[string]SkippedAccount)
+	for _, skip := range preview.Skipped {
+		reasons[skip.ID] = skip
+	}
+	if reasons["b"].Reason != SkipNotNext || reasons["c"].Reason != ExclusionDisabled || reasons["d"].Reason != ExclusionCooldown {
+		t.Fatalf("unexpected skip reasons %+v", preview.Skipped)
+	}
+	if reasons["d"].Until == nil || !reasons["d"].Until.Equal(cooldownUntil) {
+		t.Fatalf("expected cooldown until %v, got %v", cooldownUntil, reasons["d"].Until)
+	}
+	if len(m.history.snapshot()) != 0 {
+		t.Fatalf("dry runs must not record selections")
+	}
+
+	picked, _, err := m.pickNext(context.Background(), "batchy", "", cliproxyexecutor.Options{}, map[string]struct{}{})
+	if err != nil || picked.ID != "a" {
+		t.Fatalf("the real pick should match the dry run, got %v (%v)", picked, err)
+	}
+	if preview = m.PreviewNextAccount(context.Background(), "batchy", ""); preview.Selected == nil || preview.Selected.ID != "b" {
+		t.Fatalf("expected the dry run to follow the rotation to b, got %+v", preview)
+	}
+}
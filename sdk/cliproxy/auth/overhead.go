@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// overheadHistorySize bounds how many recent requests contribute to overhead percentiles.
+const overheadHistorySize = 2000
+
+type upstreamClockKey struct{}
+
+// UpstreamClock accumulates the time a request spends waiting on upstream providers: round trips,
+// response body reads and cooldown waits caused by upstream failures. It separately accumulates the
+// time the request waited in the proxy for a concurrency slot or for its batch to complete.
+type UpstreamClock struct {
+	nanos  atomic.Int64
+	waited atomic.Int64
+}
+
+// Add counts d as upstream time. It is a no-op on a nil clock.
+func (c *UpstreamClock) Add(d time.Duration) {
+	if c != nil && d > 0 {
+		c.nanos.Add(int64(d))
+	}
+}
+
+// Elapsed returns the upstream time accumulated so far.
+func (c *UpstreamClock) Elapsed() time.Duration {
+	if c == nil {
+		return 0
+	}
+	return time.Duration(c.nanos.Load())
+}
+
+// AddWait counts d as time queued for a concurrency slot or a batch. It is a no-op on a nil clock.
+func (c *UpstreamClock) AddWait(d time.Duration) {
+	if c != nil && d > 0 {
+		c.waited.Add(int64(d))
+	}
+}
+
+// Waited returns the queued time accumulated so far.
+func (c *UpstreamClock) Waited() time.Duration {
+	if c == nil {
+		return 0
+	}
+	return time.Duration(c.waited.Load())
+}
+
+// WithUpstreamClock attaches a fresh upstream clock to ctx.
+func WithUpstreamClock(ctx context.Context) (context.Context, *UpstreamClock) {
+	clock := &UpstreamClock{}
+	return context.WithValue(ctx, upstreamClockKey{}, clock), clock
+}
+
+// UpstreamClockFrom returns the clock attached to ctx, or nil.
+func UpstreamClockFrom(ctx context.Context) *UpstreamClock {
+	if ctx == nil {
+		return nil
+	}
+	clock, _ := ctx.Value(upstreamClockKey{}).(*UpstreamClock)
+	return clock
+}
+
+// TimeUpstream wraps rt so round trips and response body reads count towards the upstream clock of
+// ctx. rt is returned unchanged when ctx carries no clock; a nil rt means http.DefaultTransport.
+func TimeUpstream(ctx context.Context, rt http.RoundTripper) http.RoundTripper {
+	clock := UpstreamClockFrom(ctx)
+	if clock == nil {
+		return rt
+	}
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &timedTransport{base: rt, clock: clock}
+}
+
+type timedTransport struct {
+	base  http.RoundTripper
+	clock *UpstreamClock
+}
+
+func (t *timedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	t.clock.Add(time.Since(start))
+	if resp != nil && resp.Body != nil {
+		resp.Body = &timedBody{ReadCloser: resp.Body, clock: t.clock}
+	}
+	return resp, err
+}
+
+// timedBody counts time blocked reading the upstream response. Time the caller spends between
+// reads, translating or waiting on the client, is not counted.
+type timedBody struct {
+	io.ReadCloser
+	clock *UpstreamClock
+}
+
+func (b *timedBody) Read(p []byte) (int, error) {
+	start := time.Now()
+	n, err := b.ReadCloser.Read(p)
+	b.clock.Add(time.Since(start))
+	return n, err
+}
+
+// OverheadStats summarises the proxy's own share of recent request time.
+type OverheadStats struct {
+	Requests    int           `json:"requests"`
+	OverheadP50 time.Duration `json:"overhead_p50"`
+	OverheadP95 time.Duration `json:"overhead_p95"`
+	OverheadP99 time.Duration `json:"overhead_p99"`
+	UpstreamP50 time.Duration `json:"upstream_p50"`
+	UpstreamP95 time.Duration `json:"upstream_p95"`
+	UpstreamP99 time.Duration `json:"upstream_p99"`
+	WaitP50     time.Duration `json:"wait_p50"`
+	WaitP95     time.Duration `json:"wait_p95"`
+	WaitP99     time.Duration `json:"wait_p99"`
+}
+
+type overheadSample struct {
+	overhead time.Duration
+	upstream time.Duration
+	waited   time.Duration
+}
+
+// overheadHistory is a fixed-size ring of recent request timings.
+type overheadHistory struct {
+	mu      sync.Mutex
+	samples []overheadSample
+	next    int
+}
+
+func (h *overheadHistory) record(sample overheadSample) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.samples) < overheadHistorySize {
+		h.samples = append(h.samples, sample)
+		return
+	}
+	h.samples[h.next] = sample
+	h.next = (h.next + 1) % overheadHistorySize
+}
+
+// RecordOverhead records a finished request that took total overall, of which upstream was spent
+// waiting on providers, waited queued for a concurrency slot or a batch, and clientWait waiting for a
+// streaming client to accept chunks.
+func (m *Manager) RecordOverhead(total, upstream, waited, clientWait time.Duration) {
+	if m == nil || total <= 0 {
+		return
+	}
+	overhead := total - upstream - waited - clientWait
+	if overhead < 0 {
+		overhead = 0
+	}
+	m.overhead.record(overheadSample{overhead: overhead, upstream: upstream, waited: waited})
+}
+
+// Overhead returns percentiles of the proxy overhead and upstream time over recent requests.
+func (m *Manager) Overhead() OverheadStats {
+	if m == nil {
+		return OverheadStats{}
+	}
+	m.overhead.mu.Lock()
+	overheads := make([]time.Duration, len(m.overhead.samples))
+	upstreams := make([]time.Duration, len(m.overhead.samples))
+	waits := make([]time.Duration, len(m.overhead.samples))
+	for i, sample := range m.overhead.samples {
+		overheads[i] = sample.overhead
+		upstreams[i] = sample.upstream
+		waits[i] = sample.waited
+	}
+	m.overhead.mu.Unlock()
+
+	stats := OverheadStats{Requests: len(overheads)}
+	if stats.Requests == 0 {
+		return stats
+	}
+	sort.Slice(overheads, func(i, j int) bool { return overheads[i] < overheads[j] })
+	sort.Slice(upstreams, func(i, j int) bool { return upstreams[i] < upstreams[j] })
+	sort.Slice(waits, func(i, j int) bool { return waits[i] < waits[j] })
+	stats.OverheadP50 = latencyPercentile(overheads, 0.50)
+	stats.OverheadP95 = latencyPercentile(overheads, 0.95)
+	stats.OverheadP99 = latencyPercentile(overheads, 0.99)
+	stats.UpstreamP50 = latencyPercentile(upstreams, 0.50)
+	stats.UpstreamP95 = latencyPercentile(upstreams, 0.95)
+	stats.UpstreamP99 = latencyPercentile(upstreams, 0.99)
+	stats.WaitP50 = latencyPercentile(waits, 0.50)
+	stats.WaitP95 = latencyPercentile(waits, 0.95)
+	stats.WaitP99 = latencyPercentile(waits, 0.99)
+	return stats
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestTimeUpstreamCountsRoundTripAndBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.(http.Flusher).Flush()
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	ctx, clock := WithUpstreamClock(context.Background())
+	if TimeUpstream(context.Background(), nil) != nil {
+		t.Fatalf("contexts without a clock must keep the transport unchanged")
+	}
+	client := &http.Client{Transport: TimeUpstream(ctx, nil)}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	_, _ = io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if got := clock.Elapsed(); got < 40*time.Millisecond {
+		t.Fatalf("expected header and body waits to be counted, got %s", got)
+	}
+}
+
+func TestOverheadPercentiles(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	for i := 1; i <= 100; i++ {
+		m.RecordOverhead(time.Second+time.Duration(i)*time.Millisecond, time.Second, 0, 0)
+	}
+	m.RecordOverhead(2*time.Second, 500*time.Millisecond, 0, 1500*time.Millisecond)
+	stats := m.Overhead()
+	if stats.Requests != 101 {
+		t.Fatalf("requests = %d", stats.Requests)
+	}
+	if stats.OverheadP50 != 50*time.Millisecond || stats.OverheadP99 != 99*time.Millisecond {
+		t.Fatalf("unexpected overhead percentiles: %+v", stats)
+	}
+	if stats.UpstreamP50 != time.Second {
+		t.Fatalf("unexpected upstream p50: %s", stats.UpstreamP50)
+	}
+
+	queued := NewManager(nil, nil, nil)
+	queued.RecordOverhead(2*time.Second, 500*time.Millisecond, 1400*time.Millisecond, 0)
+	if stats = queued.Overhead(); stats.OverheadP50 != 100*time.Millisecond || stats.WaitP50 != 1400*time.Millisecond {
+		t.Fatalf("queued time counted as overhead: %+v", stats)
+	}
+}
+
+func TestConcurrencyQueueWaitIsNotOverhead(t *testing.T) {
+	m := newConcurrencyManager(t, "a")
+	m.SetConcurrencyQueueTimeout(time.Second)
+	ctx, clock := WithUpstreamClock(context.Background())
+	if _, _, err := m.pickNext(ctx, "streamy", "", cliproxyexecutor.Options{}, nil); err != nil {
+		t.Fatalf("first pick: %v", err)
+	}
+	if clock.Waited() != 0 {
+		t.Fatalf("pick with a free slot waited %s", clock.Waited())
+	}
+	finish := m.beginRequest("a")
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		finish()
+	}()
+	if _, _, err := m.pickNext(ctx, "streamy", "", cliproxyexecutor.Options{}, nil); err != nil {
+		t.Fatalf("queued pick: %v", err)
+	}
+	if clock.Waited() < 30*time.Millisecond || clock.Elapsed() != 0 {
+		t.Fatalf("waited %s, upstream %s; want the queue wait on its own", clock.Waited(), clock.Elapsed())
+	}
+}
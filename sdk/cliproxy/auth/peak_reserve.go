@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultPeakReserveTag marks accounts held back for scheduled peak windows.
+const defaultPeakReserveTag = "peak-reserve"
+
+// PeakWindow is a recurring time-of-day window. Start and End are offsets from local midnight;
+// an End at or before Start wraps past midnight into the next day.
+type PeakWindow struct {
+	// Days restricts the window to these start weekdays; empty means every day.
+	Days  []time.Weekday
+	Start time.Duration
+	End   time.Duration
+}
+
+// PeakReservePolicy keeps reserve accounts out of selection except around scheduled peak windows.
+type PeakReservePolicy struct {
+	Enabled bool
+	// Tag identifies reserve accounts (default "peak-reserve").
+	Tag string
+	// Location is the timezone the windows are defined in (default UTC).
+	Location *time.Location
+	// Lead releases and pre-refreshes reserves this long before each window starts.
+	Lead    time.Duration
+	Windows []PeakWindow
+}
+
+// peakReserve holds the pre-scaling policy and whether reserves are currently released.
+type peakReserve struct {
+	mu       sync.RWMutex
+	policy   PeakReservePolicy
+	released bool
+}
+
+// SetPeakReservePolicy replaces the schedule-driven reserve pre-scaling policy.
+func (m *Manager) SetPeakReservePolicy(policy PeakReservePolicy) {
+	if m == nil {
+		return
+	}
+	policy.Tag = strings.ToLower(strings.TrimSpace(policy.Tag))
+	if policy.Tag == "" {
+		policy.Tag = defaultPeakReserveTag
+	}
+	if policy.Location == nil {
+		policy.Location = time.UTC
+	}
+	if policy.Lead < 0 {
+		policy.Lead = 0
+	}
+	m.peakReserve.mu.Lock()
+	m.peakReserve.policy = policy
+	m.peakReserve.mu.Unlock()
+}
+
+// active reports whether now falls inside a window, counting the lead time before its start.
+func (p PeakReservePolicy) active(now time.Time) bool {
+	local := now.In(p.Location)
+	year, month, day := local.Date()
+	// A window starting yesterday may still run, and tomorrow's lead may already have begun.
+	for offset := -1; offset <= 1; offset++ {
+		midnight := time.Date(year, month, day+offset, 0, 0, 0, 0, p.Location)
+		for _, w := range p.Windows {
+			if !w.onDay(midnight.Weekday()) {
+				continue
+			}
+			end := w.End
+			if end <= w.Start {
+				end += 24 * time.Hour
+			}
+			start := midnight.Add(w.Start - p.Lead)
+			if !local.Before(start) && local.Before(midnight.Add(end)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (w PeakWindow) onDay(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// holdsBack reports whether auth is a reserve that must stay out of selection at now.
+func (r *peakReserve) holdsBack(auth *Auth, now time.Time) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if !r.policy.Enabled || !auth.HasTag(r.policy.Tag) {
+		return false
+	}
+	return !r.policy.active(now)
+}
+
+// IsPeakReserve reports whether auth is a scheduled peak reserve account.
+func (m *Manager) IsPeakReserve(auth *Auth) bool {
+	if m == nil {
+		return false
+	}
+	m.peakReserve.mu.RLock()
+	defer m.peakReserve.mu.RUnlock()
+	return m.peakReserve.policy.Enabled && auth.HasTag(m.peakReserve.policy.Tag)
+}
+
+// PeakReservesReleased reports whether reserve accounts are currently available for selection.
+func (m *Manager) PeakReservesReleased(now time.Time) bool {
+	if m == nil {
+		return false
+	}
+	m.peakReserve.mu.RLock()
+	defer m.peakReserve.mu.RUnlock()
+	return m.peakReserve.policy.Enabled && m.peakReserve.policy.active(now)
+}
+
+// checkPeakReserve pre-refreshes reserve accounts once when they are released ahead of a peak window.
+func (m *Manager) checkPeakReserve(ctx context.Context, now time.Time) {
+	m.peakReserve.mu.Lock()
+	policy := m.peakReserve.policy
+	active := policy.Enabled && policy.active(now)
+	wasReleased := m.peakReserve.released
+	m.peakReserve.released = active
+	m.peakReserve.mu.Unlock()
+	if wasReleased && !active {
+		log.Infof("peak window over: holding back reserve accounts tagged %q", policy.Tag)
+	}
+	if !active || wasReleased {
+		return
+	}
+	warmed := 0
+	for _, a := range m.snapshotAuths() {
+		if !a.HasTag(policy.Tag) || a.Disabled {
+			continue
+		}
+		if typ, _ := a.AccountInfo(); typ == "api_key" || m.executorFor(a.Provider) == nil {
+			continue
+		}
+		if !m.markRefreshPending(a.ID, now) {
+			continue
+		}
+		m.refreshCoalesced(ctx, a.ID)
+		warmed++
+	}
+	log.Infof("peak window approaching: released reserve accounts tagged %q, pre-refreshing %d", policy.Tag, warmed)
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestPeakReservePolicyActive(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	policy := PeakReservePolicy{
+		Location: berlin,
+		Lead:     15 * time.Minute,
+		Windows: []PeakWindow{
+			{Days: []time.Weekday{time.Monday}, Start: 9 * time.Hour, End: 12 * time.Hour},
+			{Days: []time.Weekday{time.Friday}, Start: 22 * time.Hour, End: 2 * time.Hour},
+		},
+	}
+	cases := []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2026, 10, 12, 8, 50, 0, 0, berlin), true},   // Monday, inside the lead
+		{time.Date(2026, 10, 12, 8, 40, 0, 0, berlin), false},  // Monday, before the lead
+		{time.Date(2026, 10, 12, 6, 50, 0, 0, time.UTC), true}, // same instant as 08:50 Berlin
+		{time.Date(2026, 10, 12, 12, 0, 0, 0, berlin), false},  // window end is exclusive
+		{time.Date(2026, 10, 13, 10, 0, 0, 0, berlin), false},  // Tuesday
+		{time.Date(2026, 10, 17, 1, 30, 0, 0, berlin), true},   // Friday window past midnight
+	}
+	for _, tc := range cases {
+		if got := policy.active(tc.at); got != tc.want {
+			t.Fatalf("active(%s) = %t, want %t", tc.at, got, tc.want)
+		}
+	}
+}
+
+func TestPeakReserveHoldsBackAndWarms(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	exec := &slowRefreshExecutor{}
+	m.RegisterExecutor(exec)
+	for _, auth := range []*Auth{
+		{ID: "regular", Provider: "eager", Status: StatusActive},
+		{ID: "reserve", Provider: "eager", Status: StatusActive, Tags: []string{"peak-reserve"}, Metadata: map[string]any{"access_token": "stale"}},
+	} {
+		if _, err := m.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	reserve, _ := m.GetByID("reserve")
+	now := time.Now().UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	offset := now.Sub(midnight)
+
+	// A window far from now keeps the reserve out of selection.
+	m.SetPeakReservePolicy(PeakReservePolicy{Enabled: true, Windows: []PeakWindow{{Start: offset + 6*time.Hour, End: offset + 7*time.Hour}}})
+	if !m.IsPeakReserve(reserve) || !m.peakReserve.holdsBack(reserve, now) {
+		t.Fatalf("expected reserve to be held back outside its window")
+	}
+	for i := 0; i < 3; i++ {
+		picked, _, err := m.pickNext(context.Background(), "eager", "", cliproxyexecutor.Options{}, nil)
+		if err != nil || picked.ID != "regular" {
+			t.Fatalf("expected only the regular account to be selected, got %v, %v", picked, err)
+		}
+	}
+
+	// Entering the lead releases the reserve and pre-refreshes it once.
+	m.SetPeakReservePolicy(PeakReservePolicy{Enabled: true, Lead: time.Hour, Windows: []PeakWindow{{Start: offset + 30*time.Minute, End: offset + 2*time.Hour}}})
+	if m.peakReserve.holdsBack(reserve, now) {
+		t.Fatalf("expected reserve to be released during the lead")
+	}
+	m.checkPeakReserve(context.Background(), now)
+	m.checkPeakReserve(context.Background(), now)
+	if done, ok := m.refreshInFlight("reserve"); ok {
+		<-done
+	}
+	if got := exec.refreshes.Load(); got != 1 {
+		t.Fatalf("expected one pre-refresh, got %d", got)
+	}
+}
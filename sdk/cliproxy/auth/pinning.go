@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	log "github.com/sirupsen/logrus"
+)
+
+// pinnedAuth returns the account a request is pinned to and whether it may fall back to normal
+// selection.
+func pinnedAuth(opts cliproxyexecutor.Options) (string, bool) {
+	id, _ := opts.Metadata[cliproxyexecutor.PinnedAuthMetadataKey].(string)
+	fallback, _ := opts.Metadata[cliproxyexecutor.PinFallbackMetadataKey].(bool)
+	return strings.TrimSpace(id), fallback
+}
+
+// pinProviders orders providers for a pinned request: the pinned account's provider is tried alone,
+// or first when the request allows fallback. Unknown accounts leave providers unchanged; selection
+// reports them.
+func (m *Manager) pinProviders(opts cliproxyexecutor.Options, providers []string) []string {
+	id, fallback := pinnedAuth(opts)
+	if id == "" {
+		return providers
+	}
+	m.mu.RLock()
+	auth := m.auths[id]
+	m.mu.RUnlock()
+	if auth == nil {
+		return providers
+	}
+	provider := strings.ToLower(strings.TrimSpace(auth.Provider))
+	if !fallback {
+		return []string{provider}
+	}
+	ordered := make([]string, 0, len(providers)+1)
+	ordered = append(ordered, provider)
+	for _, p := range providers {
+		if p != provider {
+			ordered = append(ordered, p)
+		}
+	}
+	return ordered
+}
+
+// pickPinnedLocked returns the pinned account id when it can serve model on provider, or an error
+// naming why not. A pin only chooses among accounts selection may use: reservations, peak reserve,
+// draining and cordons still apply, while soft rotation and concurrency limits do not exclude it.
+// Callers must hold m.mu.
+func (m *Manager) pickPinnedLocked(id, provider, model, reservation string, tried map[string]struct{}, now time.Time) (*Auth, *Error) {
+	auth := m.auths[id]
+	if auth == nil {
+		return nil, pinnedAccountUnavailable(id, "does not exist")
+	}
+	if !strings.EqualFold(auth.Provider, provider) {
+		return nil, pinnedAccountUnavailable(id, "belongs to provider "+auth.Provider)
+	}
+	if _, used := tried[id]; used {
+		return nil, pinnedAccountUnavailable(id, "already failed this request")
+	}
+	tier, reason := m.selectionTierLocked(auth, strings.TrimSpace(model), reservation, registry.GetGlobalRegistry(), now)
+	switch tier {
+	case tierExcluded, tierCordoned:
+		return nil, pinnedAccountUnavailable(id, pinnedExclusionReason(reason, model))
+	case tierBlocked:
+		if _, _, next := isAuthBlockedForModel(auth, model, now); reason == ExclusionCooldown && !next.IsZero() {
+			return nil, pinnedAccountUnavailable(id, "is cooling down until "+next.Format(time.RFC3339))
+		}
+		return nil, pinnedAccountUnavailable(id, pinnedExclusionReason(reason, model))
+	}
+	log.Infof("request for model %s pinned to account %s", model, id)
+	return auth, nil
+}
+
+// pinnedExclusionReason describes a selection exclusion reason for a pinned account error.
+func pinnedExclusionReason(reason, model string) string {
+	switch reason {
+	case ExclusionDisabled:
+		return "is disabled"
+	case ExclusionModelMismatch:
+		return "does not serve model " + model
+	case ExclusionModelWindow:
+		return "is outside its window for model " + model
+	case ExclusionDraining:
+		return "is draining"
+	case ExclusionDrillCordoned, ExclusionRateLimitCordoned:
+		return "is cordoned"
+	case ExclusionReserved:
+		return "is reserved"
+	case ExclusionPeakReserve:
+		return "is held back as peak reserve"
+	case ExclusionDuplicate:
+		return "duplicates another account"
+	case ExclusionCooldown:
+		return "is cooling down"
+	default:
+		return "is unavailable"
+	}
+}
+
+// pinnedAccountUnavailable is returned when a pinned request without fallback cannot use its account.
+func pinnedAccountUnavailable(id, reason string) *Error {
+	return &Error{
+		Code:       "pinned_account_unavailable",
+		Message:    fmt.Sprintf("pinned account %s %s", id, reason),
+		HTTPStatus: http.StatusConflict,
+	}
+}
+
+// isPinnedAccountUnavailable reports whether err is a pinned account failure, which waiting for
+// cooldowns must not retry behind the client's back.
+func isPinnedAccountUnavailable(err error) bool {
+	authErr, ok := err.(*Error)
+	return ok && authErr != nil && authErr.Code == "pinned_account_unavailable"
+}
+
+// pinnedProviderLocked returns the provider of the pinned account, or "". Callers must hold m.mu.
+func (m *Manager) pinnedProviderLocked(id string) string {
+	if auth := m.auths[id]; auth != nil {
+		return auth.Provider
+	}
+	return ""
+}
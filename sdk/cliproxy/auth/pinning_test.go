@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestPinnedAccountOverridesSelector(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&batchingExecutor{})
+	reg := registry.GetGlobalRegistry()
+	for _, auth := range []*Auth{
+		{ID: "pin-a", Provider: "batchy"},
+		{ID: "pin-b", Provider: "batchy"},
+	} {
+		if _, err := m.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+		reg.RegisterClient(auth.ID, "batchy", []*registry.ModelInfo{{ID: "pinned-model"}})
+		id := auth.ID
+		t.Cleanup(func() { reg.UnregisterClient(id) })
+	}
+	pinned := func(id string, fallback bool) cliproxyexecutor.Options {
+		metadata := map[string]any{cliproxyexecutor.PinnedAuthMetadataKey: id}
+		if fallback {
+			metadata[cliproxyexecutor.PinFallbackMetadataKey] = true
+		}
+		return cliproxyexecutor.Options{Metadata: metadata}
+	}
+
+	for i := 0; i < 3; i++ {
+		auth, _, err := m.pickNext(context.Background(), "batchy", "pinned-model", pinned("pin-b", false), map[string]struct{}{})
+		if err != nil || auth.ID != "pin-b" {
+			t.Fatalf("pick %d = %v, %v; want pin-b", i, auth, err)
+		}
+	}
+
+	retryAt := time.Now().Add(time.Minute)
+	m.MarkResult(context.Background(), Result{AuthID: "pin-b", Provider: "batchy", Model: "pinned-model", RetryAfterAt: &retryAt,
+		Error: &Error{Message: "rate limited", HTTPStatus: http.StatusTooManyRequests}})
+	_, _, err := m.pickNext(context.Background(), "batchy", "pinned-model", pinned("pin-b", false), map[string]struct{}{})
+	var authErr *Error
+	if !errors.As(err, &authErr) || authErr.Code != "pinned_account_unavailable" || authErr.HTTPStatus != http.StatusConflict {
+		t.Fatalf("cooling pinned account: %v", err)
+	}
+	if _, _, err = m.pickNext(context.Background(), "batchy", "pinned-model", pinned("missing", false), map[string]struct{}{}); !isPinnedAccountUnavailable(err) {
+		t.Fatalf("unknown pinned account: %v", err)
+	}
+
+	auth, _, err := m.pickNext(context.Background(), "batchy", "pinned-model", pinned("pin-b", true), map[string]struct{}{})
+	if err != nil || auth.ID != "pin-a" {
+		t.Fatalf("fallback pick = %v, %v; want pin-a", auth, err)
+	}
+	if got := m.pinProviders(pinned("pin-a", false), []string{"gemini", "batchy"}); len(got) != 1 || got[0] != "batchy" {
+		t.Fatalf("pinned providers = %v", got)
+	}
+	if got := m.pinProviders(pinned("pin-a", true), []string{"gemini", "batchy"}); len(got) != 2 || got[0] != "batchy" {
+		t.Fatalf("pinned providers with fallback = %v", got)
+	}
+}
+
+func TestPinnedAccountKeepsSelectionGuards(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&batchingExecutor{})
+	for _, id := range []string{"held", "drain"} {
+		if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: "batchy"}); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	if _, err := m.Reserve("batch", []string{"held"}, time.Hour); err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+	m.drains.byAuth = map[string]*drainState{"drain": {started: time.Now()}}
+
+	for _, id := range []string{"held", "drain"} {
+		opts := cliproxyexecutor.Options{Metadata: map[string]any{cliproxyexecutor.PinnedAuthMetadataKey: id}}
+		if _, _, err := m.pickNext(context.Background(), "batchy", "", opts, map[string]struct{}{}); !isPinnedAccountUnavailable(err) {
+			t.Fatalf("pin to %s = %v, want pinned_account_unavailable", id, err)
+		}
+	}
+	opts := cliproxyexecutor.Options{Metadata: map[string]any{cliproxyexecutor.PinnedAuthMetadataKey: "held", cliproxyexecutor.ReservationMetadataKey: "batch"}}
+	if auth, _, err := m.pickNext(context.Background(), "batchy", "", opts, map[string]struct{}{}); err != nil || auth.ID != "held" {
+		t.Fatalf("pin within the reservation = %v, %v; want held", auth, err)
+	}
+}
@@ -0,0 +1,266 @@
+package auth
+
+import (
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// PrefixAffinityStats reports how often prefix affinity landed on the preferred account.
+type PrefixAffinityStats struct {
+	Provider string  `json:"provider"`
+	Requests int64   `json:"requests"`
+	Hits     int64   `json:"hits"`
+	Misses   int64   `json:"misses"`
+	HitRate  float64 `json:"hit_rate"`
+}
+
+// Prefix affinity modes. Hash mode keeps a prefix on its rendezvous-hashed account indefinitely; the
+// pinning modes pin a prefix to the account first selected for it until the pin expires.
+const (
+	PrefixAffinityModeHash    = "hash"
+	PrefixAffinityModeSliding = "sliding"
+	PrefixAffinityModeFixed   = "fixed"
+)
+
+// prefixPinSweepSize is the pin count above which expired pins are swept before adding another.
+const prefixPinSweepSize = 10000
+
+// PrefixAffinityPolicy controls how long a prompt prefix stays on its account. A zero TTL selects
+// hash mode. Sliding pins are extended on every use, fixed pins expire TTL after they were created;
+// MaxLifetime caps either so long conversations eventually rebalance.
+type PrefixAffinityPolicy struct {
+	Mode        string
+	TTL         time.Duration
+	MaxLifetime time.Duration
+}
+
+// prefixPin is the account a prompt prefix is pinned to.
+type prefixPin struct {
+	authID    string
+	pinnedAt  time.Time
+	expiresAt time.Time
+}
+
+// prefixAffinity tracks affinity outcomes per provider and, in the pinning modes, the prefix pins.
+type prefixAffinity struct {
+	mu     sync.Mutex
+	stats  map[string]*PrefixAffinityStats
+	policy PrefixAffinityPolicy
+	pins   map[string]*prefixPin
+}
+
+// SetPrefixAffinityPolicy replaces the prefix affinity TTL policy. Unknown modes fall back to
+// sliding; changing the policy drops existing pins.
+func (m *Manager) SetPrefixAffinityPolicy(policy PrefixAffinityPolicy) {
+	if m == nil {
+		return
+	}
+	if policy.TTL <= 0 {
+		policy = PrefixAffinityPolicy{Mode: PrefixAffinityModeHash}
+	} else if policy.Mode != PrefixAffinityModeFixed {
+		policy.Mode = PrefixAffinityModeSliding
+	}
+	if policy.MaxLifetime < 0 {
+		policy.MaxLifetime = 0
+	}
+	m.affinity.mu.Lock()
+	defer m.affinity.mu.Unlock()
+	if m.affinity.policy != policy {
+		m.affinity.pins = nil
+	}
+	m.affinity.policy = policy
+}
+
+// PrefixAffinityMode reports the active prefix affinity mode.
+func (m *Manager) PrefixAffinityMode() string {
+	if m == nil {
+		return PrefixAffinityModeHash
+	}
+	m.affinity.mu.Lock()
+	defer m.affinity.mu.Unlock()
+	if m.affinity.policy.Mode == "" {
+		return PrefixAffinityModeHash
+	}
+	return m.affinity.policy.Mode
+}
+
+// pinning reports whether prefixes are pinned with a TTL rather than hashed.
+func (p *prefixAffinity) pinning() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.policy.TTL > 0
+}
+
+// pick returns the account for key and whether it is the prefix's preferred account. In hash mode
+// that is the rendezvous winner; in the pinning modes it is the pinned account while its pin is live
+// and the account available, nil otherwise so the regular selector picks a new account to pin.
+// Accounts rejected by eligible (e.g. out of request-cap headroom) are treated as unavailable.
+func (p *prefixAffinity) pick(key, provider, model string, candidates []*Auth, eligible func(*Auth) bool, now time.Time) (*Auth, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.policy.TTL <= 0 {
+		return pickByPrefixAffinity(key, provider, model, candidates, eligible, now)
+	}
+	pin, ok := p.pins[key]
+	if !ok {
+		return nil, false
+	}
+	if !now.Before(pin.expiresAt) {
+		delete(p.pins, key)
+		return nil, false
+	}
+	available, err := availableAuths(provider, model, candidates, now)
+	if err != nil {
+		delete(p.pins, key)
+		return nil, false
+	}
+	for _, candidate := range available {
+		if candidate.ID != pin.authID || !eligible(candidate) {
+			continue
+		}
+		if p.policy.Mode == PrefixAffinityModeSliding {
+			pin.expiresAt = p.expiryLocked(pin.pinnedAt, now)
+		}
+		return candidate, true
+	}
+	delete(p.pins, key)
+	return nil, false
+}
+
+// pin pins key to auth in the pinning modes, as long as auth is one of the affinity candidates.
+func (p *prefixAffinity) pin(key string, auth *Auth, candidates []*Auth, now time.Time) {
+	if auth == nil {
+		return
+	}
+	eligible := false
+	for _, candidate := range candidates {
+		if candidate.ID == auth.ID {
+			eligible = true
+			break
+		}
+	}
+	if !eligible {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.policy.TTL <= 0 {
+		return
+	}
+	if p.pins == nil {
+		p.pins = make(map[string]*prefixPin)
+	}
+	if len(p.pins) >= prefixPinSweepSize {
+		for pinned, existing := range p.pins {
+			if !now.Before(existing.expiresAt) {
+				delete(p.pins, pinned)
+			}
+		}
+	}
+	p.pins[key] = &prefixPin{authID: auth.ID, pinnedAt: now, expiresAt: p.expiryLocked(now, now)}
+}
+
+// expiryLocked returns when a pin created at pinnedAt and last used at now expires. Callers hold p.mu.
+func (p *prefixAffinity) expiryLocked(pinnedAt, now time.Time) time.Time {
+	expires := pinnedAt.Add(p.policy.TTL)
+	if p.policy.Mode == PrefixAffinityModeSliding {
+		expires = now.Add(p.policy.TTL)
+	}
+	if p.policy.MaxLifetime > 0 {
+		if limit := pinnedAt.Add(p.policy.MaxLifetime); limit.Before(expires) {
+			expires = limit
+		}
+	}
+	return expires
+}
+
+func (p *prefixAffinity) record(provider string, hit bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stats == nil {
+		p.stats = make(map[string]*PrefixAffinityStats)
+	}
+	st, ok := p.stats[provider]
+	if !ok {
+		st = &PrefixAffinityStats{Provider: provider}
+		p.stats[provider] = st
+	}
+	st.Requests++
+	if hit {
+		st.Hits++
+	} else {
+		st.Misses++
+	}
+}
+
+// PrefixAffinityStats returns affinity hit rates per provider ordered by provider.
+func (m *Manager) PrefixAffinityStats() []PrefixAffinityStats {
+	if m == nil {
+		return nil
+	}
+	m.affinity.mu.Lock()
+	defer m.affinity.mu.Unlock()
+	out := make([]PrefixAffinityStats, 0, len(m.affinity.stats))
+	for _, st := range m.affinity.stats {
+		entry := *st
+		if entry.Requests > 0 {
+			entry.HitRate = float64(entry.Hits) / float64(entry.Requests)
+		}
+		out = append(out, entry)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Provider < out[j].Provider })
+	return out
+}
+
+// prefixAffinityKey returns the prompt prefix hash attached by the handler, if any.
+func prefixAffinityKey(opts cliproxyexecutor.Options) string {
+	key, _ := opts.Metadata[cliproxyexecutor.PrefixAffinityMetadataKey].(string)
+	return key
+}
+
+// affinityScore ranks auth for key with rendezvous hashing, so each prefix keeps its account
+// while the pool changes and only the prefixes of a removed account move.
+func affinityScore(key, authID string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(authID))
+	return h.Sum64()
+}
+
+// pickByPrefixAffinity returns the highest ranked available candidate for key and whether it is the
+// preferred account, i.e. the highest ranked candidate overall. It returns nil when no candidate is
+// available so the regular selector can report why.
+func pickByPrefixAffinity(key, provider, model string, candidates []*Auth, eligible func(*Auth) bool, now time.Time) (*Auth, bool) {
+	available, err := availableAuths(provider, model, candidates, now)
+	if err != nil {
+		return nil, false
+	}
+	eligibleAuths := available[:0:0]
+	for _, candidate := range available {
+		if eligible(candidate) {
+			eligibleAuths = append(eligibleAuths, candidate)
+		}
+	}
+	available = eligibleAuths
+	if len(available) == 0 {
+		return nil, false
+	}
+	best := func(auths []*Auth) *Auth {
+		var winner *Auth
+		var winnerScore uint64
+		for _, candidate := range auths {
+			if score := affinityScore(key, candidate.ID); winner == nil || score > winnerScore {
+				winner, winnerScore = candidate, score
+			}
+		}
+		return winner
+	}
+	preferred := best(candidates)
+	selected := best(available)
+	return selected, preferred != nil && preferred.ID == selected.ID
+}
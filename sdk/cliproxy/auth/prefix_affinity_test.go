@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestPickNextPrefersAffinityAccount(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&batchingExecutor{})
+	for _, id := range []string{"a", "b", "c", "d"} {
+		if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: "batchy"}); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	opts := cliproxyexecutor.Options{Metadata: map[string]any{cliproxyexecutor.PrefixAffinityMetadataKey: "prefix-1"}}
+
+	first, _, err := m.pickNext(context.Background(), "batchy", "", opts, map[string]struct{}{})
+	if err != nil {
+		t.Fatalf("pickNext: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		again, _, _ := m.pickNext(context.Background(), "batchy", "", opts, map[string]struct{}{})
+		if again.ID != first.ID {
+			t.Fatalf("expected the same account for the same prefix, got %s then %s", first.ID, again.ID)
+		}
+	}
+
+	// The preferred account cooling down forces a miss onto a stable fallback.
+	m.mu.Lock()
+	m.auths[first.ID].Unavailable = true
+	m.auths[first.ID].NextRetryAfter = time.Now().Add(time.Minute)
+	m.mu.Unlock()
+	fallback, _, _ := m.pickNext(context.Background(), "batchy", "", opts, map[string]struct{}{})
+	if fallback.ID == first.ID {
+		t.Fatalf("expected fallback away from the unavailable preferred account")
+	}
+
+	stats := m.PrefixAffinityStats()
+	if len(stats) != 1 || stats[0].Requests != 7 || stats[0].Hits != 6 || stats[0].Misses != 1 {
+		t.Fatalf("unexpected affinity stats %+v", stats)
+	}
+}
+
+func TestPrefixAffinityPinTTLModes(t *testing.T) {
+	auths := []*Auth{{ID: "a"}, {ID: "b"}}
+	anyAuth := func(*Auth) bool { return true }
+	start := time.Now()
+
+	var sliding prefixAffinity
+	sliding.policy = PrefixAffinityPolicy{Mode: PrefixAffinityModeSliding, TTL: time.Minute, MaxLifetime: 3 * time.Minute}
+	if picked, _ := sliding.pick("k", "p", "", auths, anyAuth, start); picked != nil {
+		t.Fatalf("an unpinned prefix should defer to the selector, got %s", picked.ID)
+	}
+	sliding.pin("k", auths[1], auths, start)
+	for i := 1; i <= 2; i++ {
+		// Each use within the TTL slides the pin forward.
+		picked, hit := sliding.pick("k", "p", "", auths, anyAuth, start.Add(time.Duration(i)*50*time.Second))
+		if picked == nil || picked.ID != "b" || !hit {
+			t.Fatalf("expected sliding pin on b at use %d, got %v", i, picked)
+		}
+	}
+	if picked, _ := sliding.pick("k", "p", "", auths, anyAuth, start.Add(3*time.Minute)); picked != nil {
+		t.Fatalf("max lifetime must end a sliding pin, got %s", picked.ID)
+	}
+
+	var fixed prefixAffinity
+	fixed.policy = PrefixAffinityPolicy{Mode: PrefixAffinityModeFixed, TTL: time.Minute}
+	fixed.pin("k", auths[0], auths, start)
+	if picked, _ := fixed.pick("k", "p", "", auths, anyAuth, start.Add(50*time.Second)); picked == nil || picked.ID != "a" {
+		t.Fatalf("expected fixed pin on a, got %v", picked)
+	}
+	if picked, _ := fixed.pick("k", "p", "", auths, anyAuth, start.Add(70*time.Second)); picked != nil {
+		t.Fatalf("a fixed pin must expire a TTL after creation despite use, got %s", picked.ID)
+	}
+}
+
+func TestSetPrefixAffinityPolicyModes(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	if mode := m.PrefixAffinityMode(); mode != PrefixAffinityModeHash {
+		t.Fatalf("expected hash mode by default, got %s", mode)
+	}
+	m.SetPrefixAffinityPolicy(PrefixAffinityPolicy{Mode: "bogus", TTL: time.Minute})
+	if mode := m.PrefixAffinityMode(); mode != PrefixAffinityModeSliding {
+		t.Fatalf("expected sliding fallback, got %s", mode)
+	}
+	m.SetPrefixAffinityPolicy(PrefixAffinityPolicy{Mode: PrefixAffinityModeFixed})
+	if mode := m.PrefixAffinityMode(); mode != PrefixAffinityModeHash {
+		t.Fatalf("a zero TTL should select hash mode, got %s", mode)
+	}
+}
+
+func TestPrefixAffinityRespectsCapsAndSoftRotation(t *testing.T) {
+	opts := cliproxyexecutor.Options{Metadata: map[string]any{cliproxyexecutor.PrefixAffinityMetadataKey: "prefix-1"}}
+	for name, configure := range map[string]func(*Manager){
+		"caps": func(m *Manager) { m.SetRequestCapPolicy(RequestCapPolicy{Enabled: true, Daily: 1}) },
+		"soft rotation": func(m *Manager) {
+			m.SetSoftRotationPolicy(SoftRotationPolicy{Enabled: true, Requests: 1, Rest: time.Minute})
+		},
+	} {
+		m := NewManager(nil, nil, nil)
+		m.RegisterExecutor(&batchingExecutor{})
+		for _, id := range []string{"a", "b"} {
+			if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: "batchy"}); err != nil {
+				t.Fatalf("register: %v", err)
+			}
+		}
+		configure(m)
+		first, _, err := m.pickNext(context.Background(), "batchy", "", opts, map[string]struct{}{})
+		if err != nil {
+			t.Fatalf("%s: first pick: %v", name, err)
+		}
+		m.beginRequest(first.ID)()
+		second, _, err := m.pickNext(context.Background(), "batchy", "", opts, map[string]struct{}{})
+		if err != nil || second.ID == first.ID {
+			t.Fatalf("%s: second pick = %v, %v; affinity must not override it on %s", name, second, err, first.ID)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// priorityMetadataKey mirrors Auth.Priority in metadata so file-backed accounts keep it across reloads.
+const priorityMetadataKey = "priority"
+
+// SetPriority replaces the auth's selection priority, mirroring it into metadata.
+func (a *Auth) SetPriority(priority int) {
+	if a == nil {
+		return
+	}
+	a.Priority = priority
+	if a.Metadata != nil {
+		a.Metadata[priorityMetadataKey] = priority
+	}
+}
+
+// syncPriorityFromMetadata hydrates Auth.Priority from persisted metadata, falling back to the
+// "priority" attribute for accounts without one.
+func syncPriorityFromMetadata(a *Auth) {
+	if a == nil {
+		return
+	}
+	if a.Metadata != nil {
+		switch raw := a.Metadata[priorityMetadataKey].(type) {
+		case int:
+			a.Priority = raw
+			return
+		case float64:
+			a.Priority = int(raw)
+			return
+		case string:
+			if v, err := strconv.Atoi(strings.TrimSpace(raw)); err == nil {
+				a.Priority = v
+				return
+			}
+		}
+	}
+	if a.Priority != 0 || a.Attributes == nil {
+		return
+	}
+	if v, err := strconv.Atoi(strings.TrimSpace(a.Attributes[priorityMetadataKey])); err == nil {
+		a.Priority = v
+	}
+}
+
+// preferPriority narrows candidates to the available accounts of the highest priority, so lower
+// priorities only serve while every higher one is unavailable or cooling down. Candidates are returned
+// unchanged when none is available so the selector can report why.
+func preferPriority(model string, candidates []*Auth, now time.Time) []*Auth {
+	if len(candidates) < 2 {
+		return candidates
+	}
+	best := 0
+	found := false
+	for _, candidate := range candidates {
+		if blocked, _, _ := isAuthBlockedForModel(candidate, model, now); blocked {
+			continue
+		}
+		if !found || candidate.Priority > best {
+			best, found = candidate.Priority, true
+		}
+	}
+	if !found {
+		return candidates
+	}
+	out := make([]*Auth, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.Priority != best {
+			continue
+		}
+		if blocked, _, _ := isAuthBlockedForModel(candidate, model, now); !blocked {
+			out = append(out, candidate)
+		}
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestPickNextPrefersHighestPriority(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&batchingExecutor{})
+	for id, priority := range map[string]int{"premium-1": 10, "premium-2": 10, "free-1": 0, "free-2": 0} {
+		if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: "batchy", Priority: priority}); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	pick := func() string {
+		t.Helper()
+		picked, _, err := m.pickNext(context.Background(), "batchy", "", cliproxyexecutor.Options{}, map[string]struct{}{})
+		if err != nil {
+			t.Fatalf("pickNext: %v", err)
+		}
+		return picked.ID
+	}
+
+	picks := make(map[string]int)
+	for i := 0; i < 8; i++ {
+		picks[pick()]++
+	}
+	if picks["premium-1"] != 4 || picks["premium-2"] != 4 {
+		t.Fatalf("equal priorities should round-robin among premium accounts, got %v", picks)
+	}
+
+	// Every premium account exceeding its quota falls through to the free tier.
+	m.mu.Lock()
+	for _, id := range []string{"premium-1", "premium-2"} {
+		m.auths[id].Unavailable = true
+		m.auths[id].Quota.Exceeded = true
+		m.auths[id].NextRetryAfter = time.Now().Add(time.Minute)
+	}
+	m.mu.Unlock()
+	picks = make(map[string]int)
+	for i := 0; i < 4; i++ {
+		picks[pick()]++
+	}
+	if picks["free-1"] != 2 || picks["free-2"] != 2 {
+		t.Fatalf("expected fallthrough to the free accounts, got %v", picks)
+	}
+
+	// One premium account recovering takes the traffic back.
+	m.mu.Lock()
+	m.auths["premium-2"].Unavailable = false
+	m.auths["premium-2"].Quota.Exceeded = false
+	m.auths["premium-2"].NextRetryAfter = time.Time{}
+	m.mu.Unlock()
+	if id := pick(); id != "premium-2" {
+		t.Fatalf("expected the recovered premium account, got %s", id)
+	}
+}
+
+func TestSyncPriorityFromMetadata(t *testing.T) {
+	a := &Auth{ID: "a", Metadata: map[string]any{}}
+	a.SetPriority(5)
+	restored := &Auth{ID: "a", Metadata: map[string]any{"priority": float64(5)}}
+	syncPriorityFromMetadata(restored)
+	if a.Metadata["priority"] != 5 || restored.Priority != 5 {
+		t.Fatalf("priority not mirrored: %v / %d", a.Metadata["priority"], restored.Priority)
+	}
+	fromAttr := &Auth{ID: "b", Attributes: map[string]string{"priority": "3"}}
+	syncPriorityFromMetadata(fromAttr)
+	if fromAttr.Priority != 3 {
+		t.Fatalf("expected priority from attribute, got %d", fromAttr.Priority)
+	}
+}
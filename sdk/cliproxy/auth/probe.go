@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// ProbeAccount sends req through the account directly, bypassing selection, cooldowns and pinning.
+// The outcome is not recorded against the account, so a probe leaves its quota state and counters
+// untouched; only the upstream cost of the call itself is incurred.
+func (m *Manager) ProbeAccount(ctx context.Context, id string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	if m == nil {
+		return cliproxyexecutor.Response{}, &Error{Code: "auth_not_found", Message: "auth manager not available", HTTPStatus: http.StatusServiceUnavailable}
+	}
+	auth, ok := m.GetByID(id)
+	if !ok || auth == nil {
+		return cliproxyexecutor.Response{}, &Error{Code: "auth_not_found", Message: "account not found", HTTPStatus: http.StatusNotFound}
+	}
+	executor := m.executorFor(auth.Provider)
+	if executor == nil {
+		return cliproxyexecutor.Response{}, &Error{Code: "executor_not_found", Message: "executor not registered", HTTPStatus: http.StatusServiceUnavailable}
+	}
+	auth = m.ensureFreshToken(ctx, auth)
+	execCtx := ctx
+	if rt := m.roundTripperFor(auth); rt != nil {
+		execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
+		execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
+	}
+	return executor.Execute(execCtx, auth, remapRequestModel(auth, req), opts)
+}
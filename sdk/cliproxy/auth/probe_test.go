@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestProbeAccountBypassesCooldownWithoutRecording(t *testing.T) {
+	exec := &payloadExecutor{payloads: map[string]string{"a": `{"choices":[{"message":{"content":"pong"}}],"usage":{"prompt_tokens":1,"completion_tokens":1}}`}}
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(exec)
+	if _, err := m.Register(context.Background(), &Auth{ID: "a", Provider: "streamy"}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	retryAt := time.Now().Add(time.Hour)
+	m.MarkResult(context.Background(), Result{AuthID: "a", Provider: "streamy", Model: "probe-model", RetryAfterAt: &retryAt,
+		Error: &Error{Message: "rate limited", HTTPStatus: http.StatusTooManyRequests}})
+	before := m.RequestCounts("a")
+
+	resp, err := m.ProbeAccount(context.Background(), "a", cliproxyexecutor.Request{Model: "probe-model"}, cliproxyexecutor.Options{})
+	if err != nil || len(resp.Payload) == 0 {
+		t.Fatalf("probe = %s, %v", resp.Payload, err)
+	}
+	if len(exec.calls) != 1 || exec.calls[0] != "a" {
+		t.Fatalf("unexpected calls %v", exec.calls)
+	}
+	if got := m.RequestCounts("a"); got != before {
+		t.Fatalf("probe changed request counts: %+v -> %+v", before, got)
+	}
+	if got := m.TokenUsage("a"); got != (TokenUsage{}) {
+		t.Fatalf("probe recorded token usage %+v", got)
+	}
+	auth, _ := m.GetByID("a")
+	if blocked, _, _ := isAuthBlockedForModel(auth, "probe-model", time.Now()); !blocked {
+		t.Fatal("probe cleared the cooldown")
+	}
+
+	_, err = m.ProbeAccount(context.Background(), "missing", cliproxyexecutor.Request{}, cliproxyexecutor.Options{})
+	var rerr *Error
+	if !errors.As(err, &rerr) || rerr.StatusCode() != http.StatusNotFound {
+		t.Fatalf("probe of unknown account: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"sort"
+	"time"
+)
+
+// ProviderStats summarises recent execution results for a provider. Streams count the time to their
+// first chunk as latency.
+type ProviderStats struct {
+	// RequestsPerMinute counts results recorded during the last minute.
+	RequestsPerMinute int `json:"rpm"`
+	// Requests counts results recorded in the stats window.
+	Requests int `json:"requests"`
+	// Failures counts failed results recorded in the stats window.
+	Failures int `json:"failures"`
+	// ErrorRate is Failures divided by Requests, or zero without traffic.
+	ErrorRate float64 `json:"error_rate"`
+	// LatencyP50 is the median latency of results with a known latency.
+	LatencyP50 time.Duration `json:"latency_p50"`
+	// LatencyP95 is the 95th percentile latency of results with a known latency.
+	LatencyP95 time.Duration `json:"latency_p95"`
+}
+
+func summariseSamples(list []resultSample, now time.Time) ProviderStats {
+	stats := ProviderStats{Requests: len(list)}
+	minuteAgo := now.Add(-time.Minute)
+	latencies := make([]time.Duration, 0, len(list))
+	for _, sample := range list {
+		if sample.failed {
+			stats.Failures++
+		}
+		if sample.at.After(minuteAgo) {
+			stats.RequestsPerMinute++
+		}
+		if sample.latency > 0 {
+			latencies = append(latencies, sample.latency)
+		}
+	}
+	if stats.Requests > 0 {
+		stats.ErrorRate = float64(stats.Failures) / float64(stats.Requests)
+	}
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		stats.LatencyP50 = latencyPercentile(latencies, 0.50)
+		stats.LatencyP95 = latencyPercentile(latencies, 0.95)
+	}
+	return stats
+}
+
+// latencyPercentile returns the nearest-rank percentile from an ascending slice.
+func latencyPercentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(float64(len(sorted))*p+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// ProviderStats returns recent per-provider request aggregates keyed by provider, combined from the
+// recent results of each account.
+func (m *Manager) ProviderStats() map[string]ProviderStats {
+	if m == nil {
+		return nil
+	}
+	now := time.Now()
+	recent := m.requestCounts.recentSamples(now)
+	byProvider := make(map[string][]resultSample)
+	m.mu.RLock()
+	for id, samples := range recent {
+		if auth := m.auths[id]; auth != nil && auth.Provider != "" {
+			byProvider[auth.Provider] = append(byProvider[auth.Provider], samples...)
+		}
+	}
+	m.mu.RUnlock()
+	out := make(map[string]ProviderStats, len(byProvider))
+	for provider, samples := range byProvider {
+		out[provider] = summariseSamples(samples, now)
+	}
+	return out
+}
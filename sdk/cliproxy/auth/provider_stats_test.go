@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// slowTailExecutor streams one chunk right away and the next after tail.
+type slowTailExecutor struct{ tail time.Duration }
+
+func (e slowTailExecutor) Identifier() string { return "tail" }
+
+func (e slowTailExecutor) Execute(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{}, errors.New("not supported")
+}
+
+func (e slowTailExecutor) ExecuteStream(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (<-chan cliproxyexecutor.StreamChunk, error) {
+	out := make(chan cliproxyexecutor.StreamChunk)
+	go func() {
+		defer close(out)
+		out <- cliproxyexecutor.StreamChunk{Payload: []byte(`{"text":"a"}`)}
+		time.Sleep(e.tail)
+		out <- cliproxyexecutor.StreamChunk{Payload: []byte(`{"text":"b"}`)}
+	}()
+	return out, nil
+}
+
+func (e slowTailExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) { return auth, nil }
+
+func (e slowTailExecutor) CountTokens(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{}, nil
+}
+
+func TestProviderStatsAggregateAccountResults(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	for _, auth := range []*Auth{
+		{ID: "stats-a", Provider: "gemini", Status: StatusActive},
+		{ID: "stats-b", Provider: "gemini", Status: StatusActive},
+		{ID: "stats-c", Provider: "claude", Status: StatusActive},
+	} {
+		if _, err := m.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	for i, latency := range []time.Duration{10, 20, 30, 40} {
+		id := "stats-a"
+		if i%2 == 1 {
+			id = "stats-b"
+		}
+		m.MarkResult(context.Background(), Result{AuthID: id, Provider: "gemini", Success: i != 3, Latency: latency * time.Millisecond})
+	}
+	m.MarkResult(context.Background(), Result{AuthID: "stats-c", Provider: "claude", Success: true, Latency: time.Second, FirstChunkLatency: 5 * time.Millisecond})
+
+	stats := m.ProviderStats()
+	gemini := stats["gemini"]
+	if gemini.Requests != 4 || gemini.RequestsPerMinute != 4 || gemini.Failures != 1 || gemini.ErrorRate != 0.25 {
+		t.Fatalf("gemini counts = %+v", gemini)
+	}
+	if gemini.LatencyP50 != 20*time.Millisecond || gemini.LatencyP95 != 40*time.Millisecond {
+		t.Fatalf("gemini latency p50/p95 = %s/%s", gemini.LatencyP50, gemini.LatencyP95)
+	}
+	if claude := stats["claude"]; claude.Requests != 1 || claude.LatencyP95 != 5*time.Millisecond {
+		t.Fatalf("claude = %+v; want the first chunk latency", claude)
+	}
+	if counts := m.RequestCounts("stats-a"); counts.Requests != 2 || counts.Successes != 2 {
+		t.Fatalf("account counters = %+v", counts)
+	}
+
+	m.ResetRequestCounts("stats-c")
+	if _, ok := m.ProviderStats()["claude"]; ok {
+		t.Fatal("reset account still contributes to provider stats")
+	}
+}
+
+func TestStreamResultRecordsFirstChunkLatency(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(slowTailExecutor{tail: 80 * time.Millisecond})
+	if _, err := m.Register(context.Background(), &Auth{ID: "tail-a", Provider: "tail", Status: StatusActive}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	chunks, err := m.ExecuteStream(context.Background(), []string{"tail"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{Stream: true})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	for range chunks {
+	}
+	stats := m.ProviderStats()["tail"]
+	if stats.Requests != 1 || stats.LatencyP95 <= 0 || stats.LatencyP95 >= 80*time.Millisecond {
+		t.Fatalf("stream stats = %+v; want the latency up to the first chunk", stats)
+	}
+}
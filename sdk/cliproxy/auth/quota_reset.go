@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// quotaResetHeaderReason marks quota cooldowns timed by a provider reset header.
+const quotaResetHeaderReason = "quota_reset_header"
+
+// defaultQuotaResetHeaders are the generic reset headers checked for every provider.
+var defaultQuotaResetHeaders = []string{"x-ratelimit-reset", "ratelimit-reset", "x-ratelimit-reset-requests", "x-ratelimit-reset-tokens"}
+
+// providerQuotaResetHeaders lists provider specific reset headers, most authoritative first; they are
+// checked before the defaults.
+var providerQuotaResetHeaders = map[string][]string{
+	"claude": {"anthropic-ratelimit-unified-reset", "anthropic-ratelimit-requests-reset", "anthropic-ratelimit-tokens-reset", "anthropic-ratelimit-input-tokens-reset", "anthropic-ratelimit-output-tokens-reset"},
+	"codex":  {"x-codex-primary-reset-after-seconds"},
+}
+
+// quotaRemainingHeaders maps reset headers to the header reporting what is left of the same limit.
+var quotaRemainingHeaders = map[string]string{
+	"anthropic-ratelimit-requests-reset":      "anthropic-ratelimit-requests-remaining",
+	"anthropic-ratelimit-tokens-reset":        "anthropic-ratelimit-tokens-remaining",
+	"anthropic-ratelimit-input-tokens-reset":  "anthropic-ratelimit-input-tokens-remaining",
+	"anthropic-ratelimit-output-tokens-reset": "anthropic-ratelimit-output-tokens-remaining",
+	"x-ratelimit-reset":                       "x-ratelimit-remaining",
+	"ratelimit-reset":                         "ratelimit-remaining",
+	"x-ratelimit-reset-requests":              "x-ratelimit-remaining-requests",
+	"x-ratelimit-reset-tokens":                "x-ratelimit-remaining-tokens",
+}
+
+// quotaResetFromError returns when the quota window resets according to the upstream response
+// headers carried by err. The reset of the exhausted limit (remaining 0) wins, the latest one when
+// several are exhausted; otherwise the first header in priority order holding a future time.
+func quotaResetFromError(provider string, err error, now time.Time) *time.Time {
+	var hp interface{ ResponseHeader() http.Header }
+	if err == nil || !errors.As(err, &hp) || hp == nil {
+		return nil
+	}
+	header := hp.ResponseHeader()
+	if len(header) == 0 {
+		return nil
+	}
+	names := append(append([]string(nil), providerQuotaResetHeaders[strings.ToLower(provider)]...), defaultQuotaResetHeaders...)
+	var exhausted *time.Time
+	for _, name := range names {
+		if !limitExhausted(header, name) {
+			continue
+		}
+		if at, ok := parseQuotaReset(header.Get(name), now); ok && (exhausted == nil || at.After(*exhausted)) {
+			exhausted = &at
+		}
+	}
+	if exhausted != nil {
+		return exhausted
+	}
+	for _, name := range names {
+		if at, ok := parseQuotaReset(header.Get(name), now); ok {
+			return &at
+		}
+	}
+	return nil
+}
+
+// limitExhausted reports whether the limit timed by the reset header name has nothing left.
+func limitExhausted(header http.Header, name string) bool {
+	remaining, ok := quotaRemainingHeaders[name]
+	if !ok {
+		return false
+	}
+	raw := strings.TrimSpace(header.Get(remaining))
+	if raw == "" {
+		return false
+	}
+	n, err := strconv.ParseFloat(raw, 64)
+	return err == nil && n <= 0
+}
+
+// parseQuotaReset accepts unix seconds or milliseconds, delta seconds, Go durations such as "6m0s",
+// RFC 3339 and RFC 1123 timestamps. Times not in the future are ignored.
+func parseQuotaReset(raw string, now time.Time) (time.Time, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, false
+	}
+	var at time.Time
+	if n, err := strconv.ParseFloat(raw, 64); err == nil {
+		switch {
+		case n >= 1e12:
+			at = time.UnixMilli(int64(n))
+		case n >= 1e9:
+			at = time.Unix(int64(n), 0)
+		default:
+			at = now.Add(time.Duration(n * float64(time.Second)))
+		}
+	} else if d, errDur := time.ParseDuration(raw); errDur == nil {
+		at = now.Add(d)
+	} else if t, errTime := time.Parse(time.RFC3339, raw); errTime == nil {
+		at = t
+	} else if t, errTime = http.ParseTime(raw); errTime == nil {
+		at = t
+	} else {
+		return time.Time{}, false
+	}
+	if !at.After(now) {
+		return time.Time{}, false
+	}
+	return at, true
+}
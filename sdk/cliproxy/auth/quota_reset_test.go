@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type headerError struct{ header http.Header }
+
+func (e headerError) Error() string               { return "rate limited" }
+func (e headerError) StatusCode() int             { return http.StatusTooManyRequests }
+func (e headerError) ResponseHeader() http.Header { return e.header }
+
+func TestParseQuotaReset(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := now.Add(90 * time.Second)
+	cases := map[string]time.Time{
+		strconv.FormatInt(at.Unix(), 10):      at,
+		strconv.FormatInt(at.UnixMilli(), 10): at,
+		"90":                                  at,
+		"1m30s":                               at,
+		at.Format(time.RFC3339):               at,
+		at.Format(http.TimeFormat):            at,
+	}
+	for raw, want := range cases {
+		got, ok := parseQuotaReset(raw, now)
+		if !ok || !got.Equal(want) {
+			t.Errorf("parseQuotaReset(%q) = %v, %v; want %v", raw, got, ok, want)
+		}
+	}
+	for _, raw := range []string{"", "soon", now.Add(-time.Minute).Format(time.RFC3339), "0"} {
+		if _, ok := parseQuotaReset(raw, now); ok {
+			t.Errorf("parseQuotaReset(%q) should be rejected", raw)
+		}
+	}
+}
+
+func TestQuotaResetHeaderSetsRecoveryTime(t *testing.T) {
+	now := time.Now()
+	reset := now.Add(42 * time.Minute).Truncate(time.Second)
+	err := headerError{header: http.Header{
+		"Anthropic-Ratelimit-Unified-Reset": {reset.Format(time.RFC3339)},
+		"X-Ratelimit-Reset":                 {strconv.FormatInt(now.Add(time.Hour).Unix(), 10)},
+	}}
+	resetAt := quotaResetFromError("claude", err, now)
+	if resetAt == nil || !resetAt.Equal(reset) {
+		t.Fatalf("provider header should win, got %v", resetAt)
+	}
+
+	m := NewManager(nil, nil, nil)
+	if _, errReg := m.Register(context.Background(), &Auth{ID: "a", Provider: "claude", Status: StatusActive}); errReg != nil {
+		t.Fatalf("register: %v", errReg)
+	}
+	m.MarkResult(context.Background(), Result{AuthID: "a", Provider: "claude", Model: "m", QuotaResetAt: resetAt,
+		Error: &Error{Message: "rate limited", HTTPStatus: http.StatusTooManyRequests}})
+	auth, _ := m.GetByID("a")
+	state := auth.ModelStates["m"]
+	if !state.NextRetryAfter.Equal(reset) || state.Quota.Reason != quotaResetHeaderReason {
+		t.Fatalf("model cooldown should follow the reset header, got %v (%s)", state.NextRetryAfter, state.Quota.Reason)
+	}
+	if !auth.Quota.NextRecoverAt.Equal(reset) || auth.Quota.Reason != quotaResetHeaderReason {
+		t.Fatalf("auth quota should report the reset header, got %v (%s)", auth.Quota.NextRecoverAt, auth.Quota.Reason)
+	}
+}
+
+func TestQuotaResetPrefersExhaustedLimit(t *testing.T) {
+	now := time.Now()
+	unified := now.Add(5 * time.Hour).Truncate(time.Second)
+	tokens := now.Add(40 * time.Second).Truncate(time.Second)
+	requests := now.Add(20 * time.Second).Truncate(time.Second)
+	err := headerError{header: http.Header{
+		"Anthropic-Ratelimit-Unified-Reset":      {unified.Format(time.RFC3339)},
+		"Anthropic-Ratelimit-Requests-Reset":     {requests.Format(time.RFC3339)},
+		"Anthropic-Ratelimit-Requests-Remaining": {"12"},
+		"Anthropic-Ratelimit-Tokens-Reset":       {tokens.Format(time.RFC3339)},
+		"Anthropic-Ratelimit-Tokens-Remaining":   {"0"},
+	}}
+	if resetAt := quotaResetFromError("claude", err, now); resetAt == nil || !resetAt.Equal(tokens) {
+		t.Fatalf("reset = %v, want the exhausted token limit's %v", resetAt, tokens)
+	}
+
+	err.header.Set("Anthropic-Ratelimit-Tokens-Remaining", "500")
+	if resetAt := quotaResetFromError("claude", err, now); resetAt == nil || !resetAt.Equal(unified) {
+		t.Fatalf("reset = %v, want the unified reset when no limit reports exhaustion", resetAt)
+	}
+}
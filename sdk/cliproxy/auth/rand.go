@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"math/rand/v2"
+	"sync"
+)
+
+// RandSource abstracts the random number generator used by probabilistic selection strategies.
+// Implementations must be safe for concurrent use; tests may inject a seeded source to obtain
+// reproducible selection sequences.
+type RandSource interface {
+	// IntN returns a non-negative pseudo-random number in [0, n). It panics if n <= 0.
+	IntN(n int) int
+	// Float64 returns a pseudo-random number in [0.0, 1.0).
+	Float64() float64
+}
+
+// SelectorOption customises selector construction.
+type SelectorOption func(*selectorOptions)
+
+type selectorOptions struct {
+	rand RandSource
+}
+
+// WithRandSource overrides the random source used by the selector.
+// Passing nil keeps the default process-wide generator.
+func WithRandSource(src RandSource) SelectorOption {
+	return func(o *selectorOptions) {
+		if src != nil {
+			o.rand = src
+		}
+	}
+}
+
+// NewSeededRandSource returns a concurrency-safe RandSource producing a deterministic sequence for the seed.
+func NewSeededRandSource(seed uint64) RandSource {
+	return &lockedRandSource{r: rand.New(rand.NewPCG(seed, seed))}
+}
+
+func applySelectorOptions(opts []SelectorOption) selectorOptions {
+	options := selectorOptions{rand: globalRandSource{}}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+	return options
+}
+
+// globalRandSource delegates to the automatically seeded math/rand/v2 top-level functions.
+type globalRandSource struct{}
+
+func (globalRandSource) IntN(n int) int { return rand.IntN(n) }
+
+func (globalRandSource) Float64() float64 { return rand.Float64() }
+
+// lockedRandSource serialises access to a *rand.Rand which is not safe for concurrent use.
+type lockedRandSource struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func (s *lockedRandSource) IntN(n int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.IntN(n)
+}
+
+func (s *lockedRandSource) Float64() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.Float64()
+}
@@ -0,0 +1,239 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultRateLimitCordonThreshold = 1
+	defaultRateLimitFallbackCordon  = time.Minute
+	defaultRateLimitMaxCordon       = time.Hour
+)
+
+// rateLimitHeaders names the remaining-request, limit and reset headers read from successful
+// responses. Token budgets are not tracked because the cordon threshold counts requests.
+type rateLimitHeaders struct {
+	remaining []string
+	limit     []string
+	reset     []string
+}
+
+var defaultRateLimitHeaders = rateLimitHeaders{
+	remaining: []string{"x-ratelimit-remaining-requests", "x-ratelimit-remaining", "ratelimit-remaining"},
+	limit:     []string{"x-ratelimit-limit-requests", "x-ratelimit-limit", "ratelimit-limit"},
+	reset:     []string{"x-ratelimit-reset-requests", "x-ratelimit-reset", "ratelimit-reset"},
+}
+
+// providerRateLimitHeaders lists provider specific headers; they are checked before the defaults.
+var providerRateLimitHeaders = map[string]rateLimitHeaders{
+	"claude": {
+		remaining: []string{"anthropic-ratelimit-requests-remaining"},
+		limit:     []string{"anthropic-ratelimit-requests-limit"},
+		reset:     []string{"anthropic-ratelimit-requests-reset"},
+	},
+}
+
+func rateLimitHeadersFor(provider string) rateLimitHeaders {
+	specific := providerRateLimitHeaders[strings.ToLower(provider)]
+	return rateLimitHeaders{
+		remaining: append(append([]string(nil), specific.remaining...), defaultRateLimitHeaders.remaining...),
+		limit:     append(append([]string(nil), specific.limit...), defaultRateLimitHeaders.limit...),
+		reset:     append(append([]string(nil), specific.reset...), defaultRateLimitHeaders.reset...),
+	}
+}
+
+// RateLimitPolicy controls tracking of remaining-quota headers and proactive cordoning of accounts
+// about to hit their limit.
+type RateLimitPolicy struct {
+	// Enabled turns header tracking and cordoning on.
+	Enabled bool
+	// CordonThreshold cordons an account once its remaining requests drop to this value or below.
+	CordonThreshold int64
+	// FallbackCordon is how long an account stays cordoned when the response names no reset time.
+	FallbackCordon time.Duration
+	// MaxCordon caps cordons timed by a reset header, for providers that reset earlier than announced.
+	MaxCordon time.Duration
+}
+
+// RateLimitObservation is the last rate-limit state an account's provider reported.
+type RateLimitObservation struct {
+	Remaining     int64      `json:"remaining"`
+	Limit         int64      `json:"limit,omitempty"`
+	ResetAt       *time.Time `json:"reset_at,omitempty"`
+	ObservedAt    time.Time  `json:"observed_at"`
+	CordonedUntil *time.Time `json:"cordoned_until,omitempty"`
+}
+
+// rateLimitTracker holds the latest observation per account.
+type rateLimitTracker struct {
+	mu       sync.Mutex
+	policy   RateLimitPolicy
+	observed map[string]*RateLimitObservation
+}
+
+// SetRateLimitPolicy replaces the rate-limit header policy. Disabling it drops every observation and
+// lifts all cordons.
+func (m *Manager) SetRateLimitPolicy(policy RateLimitPolicy) {
+	if m == nil {
+		return
+	}
+	if policy.CordonThreshold <= 0 {
+		policy.CordonThreshold = defaultRateLimitCordonThreshold
+	}
+	if policy.FallbackCordon <= 0 {
+		policy.FallbackCordon = defaultRateLimitFallbackCordon
+	}
+	if policy.MaxCordon <= 0 {
+		policy.MaxCordon = defaultRateLimitMaxCordon
+	}
+	m.rateLimits.mu.Lock()
+	m.rateLimits.policy = policy
+	if !policy.Enabled {
+		m.rateLimits.observed = nil
+	}
+	m.rateLimits.mu.Unlock()
+}
+
+func (t *rateLimitTracker) enabled() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.policy.Enabled
+}
+
+// observe records the rate-limit headers of a successful response. A remaining count at or below the
+// threshold cordons the account until the reported reset, or for the fallback window when none is
+// given. Every observation replaces the previous one, so a counter the provider reset early lifts the
+// cordon on the next response.
+func (t *rateLimitTracker) observe(authID, provider string, header http.Header, now time.Time) {
+	names := rateLimitHeadersFor(provider)
+	remaining, ok := rateLimitHeaderInt(header, names.remaining)
+	if !ok {
+		return
+	}
+	obs := &RateLimitObservation{Remaining: remaining, ObservedAt: now}
+	obs.Limit, _ = rateLimitHeaderInt(header, names.limit)
+	for _, name := range names.reset {
+		if at, okReset := parseQuotaReset(header.Get(name), now); okReset {
+			obs.ResetAt = &at
+			break
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.policy.Enabled {
+		return
+	}
+	if remaining <= t.policy.CordonThreshold {
+		until := now.Add(t.policy.FallbackCordon)
+		if obs.ResetAt != nil {
+			until = *obs.ResetAt
+			if limit := now.Add(t.policy.MaxCordon); until.After(limit) {
+				until = limit
+			}
+		}
+		obs.CordonedUntil = &until
+		if prev := t.observed[authID]; prev == nil || prev.CordonedUntil == nil || !prev.CordonedUntil.After(now) {
+			log.Infof("rate limit: cordoning account %s (%d requests remaining) until %s", authID, remaining, until.Format(time.RFC3339))
+		}
+	}
+	if t.observed == nil {
+		t.observed = make(map[string]*RateLimitObservation)
+	}
+	t.observed[authID] = obs
+}
+
+// cordoned reports whether the account is proactively kept out of selection at now.
+func (t *rateLimitTracker) cordoned(authID string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	obs := t.observed[authID]
+	return t.policy.Enabled && obs != nil && obs.CordonedUntil != nil && obs.CordonedUntil.After(now)
+}
+
+// rateLimitHeaderInt returns the first non-negative count found in the named headers.
+func rateLimitHeaderInt(header http.Header, names []string) (int64, bool) {
+	for _, name := range names {
+		raw := strings.TrimSpace(header.Get(name))
+		// Structured fields such as "100;w=60" carry the count first.
+		if i := strings.IndexAny(raw, ",;"); i >= 0 {
+			raw = strings.TrimSpace(raw[:i])
+		}
+		if raw == "" {
+			continue
+		}
+		if n, err := strconv.ParseFloat(raw, 64); err == nil && n >= 0 {
+			return int64(n), true
+		}
+	}
+	return 0, false
+}
+
+// RateLimit returns the last rate-limit state observed for the account.
+func (m *Manager) RateLimit(id string) (RateLimitObservation, bool) {
+	if m == nil {
+		return RateLimitObservation{}, false
+	}
+	m.rateLimits.mu.Lock()
+	defer m.rateLimits.mu.Unlock()
+	obs := m.rateLimits.observed[id]
+	if obs == nil {
+		return RateLimitObservation{}, false
+	}
+	out := *obs
+	if out.CordonedUntil != nil && !out.CordonedUntil.After(time.Now()) {
+		out.CordonedUntil = nil
+	}
+	return out, true
+}
+
+type rateLimitObserverKey struct{}
+
+type rateLimitObserver func(http.Header)
+
+// withRateLimitObserver makes the upstream responses of ctx report their headers for authID.
+func (m *Manager) withRateLimitObserver(ctx context.Context, authID, provider string) context.Context {
+	if !m.rateLimits.enabled() {
+		return ctx
+	}
+	return context.WithValue(ctx, rateLimitObserverKey{}, rateLimitObserver(func(header http.Header) {
+		m.rateLimits.observe(authID, provider, header, time.Now())
+	}))
+}
+
+// ObserveRateLimits wraps rt so successful responses report their rate-limit headers to the account
+// the request of ctx runs on. rt is returned unchanged when ctx carries no observer; a nil rt means
+// http.DefaultTransport.
+func ObserveRateLimits(ctx context.Context, rt http.RoundTripper) http.RoundTripper {
+	if ctx == nil {
+		return rt
+	}
+	observer, _ := ctx.Value(rateLimitObserverKey{}).(rateLimitObserver)
+	if observer == nil {
+		return rt
+	}
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &rateLimitTransport{base: rt, observe: observer}
+}
+
+type rateLimitTransport struct {
+	base    http.RoundTripper
+	observe rateLimitObserver
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && resp != nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		t.observe(resp.Header)
+	}
+	return resp, err
+}
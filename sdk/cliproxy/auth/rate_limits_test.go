@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestRateLimitObservationsCordonNearZero(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetRateLimitPolicy(RateLimitPolicy{Enabled: true, CordonThreshold: 2, FallbackCordon: time.Minute, MaxCordon: 10 * time.Minute})
+	now := time.Now()
+	header := func(remaining, reset string) http.Header {
+		h := http.Header{}
+		h.Set("x-ratelimit-remaining-requests", remaining)
+		h.Set("x-ratelimit-limit-requests", "100")
+		if reset != "" {
+			h.Set("x-ratelimit-reset-requests", reset)
+		}
+		return h
+	}
+
+	m.rateLimits.observe("a", "codex", header("40", ""), now)
+	if obs, ok := m.RateLimit("a"); !ok || obs.Remaining != 40 || obs.Limit != 100 || obs.CordonedUntil != nil {
+		t.Fatalf("unexpected observation: %+v", obs)
+	}
+	m.rateLimits.observe("a", "codex", header("2", "30s"), now)
+	if !m.rateLimits.cordoned("a", now.Add(29*time.Second)) || m.rateLimits.cordoned("a", now.Add(31*time.Second)) {
+		t.Fatal("cordon should last until the reported reset")
+	}
+	// A provider resetting its counter early lifts the cordon on the next response.
+	m.rateLimits.observe("a", "codex", header("99", ""), now.Add(time.Second))
+	if m.rateLimits.cordoned("a", now.Add(2*time.Second)) {
+		t.Fatal("cordon kept after the counter reset")
+	}
+	m.rateLimits.observe("a", "codex", header("0", ""), now)
+	if !m.rateLimits.cordoned("a", now.Add(59*time.Second)) || m.rateLimits.cordoned("a", now.Add(61*time.Second)) {
+		t.Fatal("cordon without reset header should use the fallback window")
+	}
+	m.rateLimits.observe("a", "codex", header("0", "24h"), now)
+	if m.rateLimits.cordoned("a", now.Add(11*time.Minute)) {
+		t.Fatal("cordon should be capped by the maximum")
+	}
+
+	claude := http.Header{}
+	claude.Set("anthropic-ratelimit-requests-remaining", "1")
+	claude.Set("x-ratelimit-remaining", "500")
+	m.rateLimits.observe("c", "claude", claude, now)
+	if obs, _ := m.RateLimit("c"); obs.Remaining != 1 {
+		t.Fatalf("provider specific header not preferred: %+v", obs)
+	}
+
+	m.SetRateLimitPolicy(RateLimitPolicy{})
+	if _, ok := m.RateLimit("a"); ok || m.rateLimits.cordoned("c", now) {
+		t.Fatal("disabling the policy should drop observations")
+	}
+}
+
+func TestObserveRateLimitsTransportRecordsSuccessfulResponses(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-ratelimit-remaining", "7")
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	m := NewManager(nil, nil, nil)
+	client := &http.Client{Transport: ObserveRateLimits(m.withRateLimitObserver(context.Background(), "a", "openai"), nil)}
+	if _, ok := client.Transport.(*rateLimitTransport); ok {
+		t.Fatal("transport wrapped while the policy is disabled")
+	}
+
+	m.SetRateLimitPolicy(RateLimitPolicy{Enabled: true})
+	ctx := m.withRateLimitObserver(context.Background(), "a", "openai")
+	client = &http.Client{Transport: ObserveRateLimits(ctx, nil)}
+	status = http.StatusTooManyRequests
+	if resp, err := client.Get(server.URL); err == nil {
+		_ = resp.Body.Close()
+	}
+	if _, ok := m.RateLimit("a"); ok {
+		t.Fatal("error responses must not be observed")
+	}
+	status = http.StatusOK
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	_ = resp.Body.Close()
+	if obs, ok := m.RateLimit("a"); !ok || obs.Remaining != 7 {
+		t.Fatalf("observation not recorded: %+v", obs)
+	}
+}
+
+func TestPickNextAvoidsCordonedAccounts(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&slowRefreshExecutor{})
+	m.SetRateLimitPolicy(RateLimitPolicy{Enabled: true})
+	for _, id := range []string{"a", "b"} {
+		if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: "eager", Status: StatusActive}); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	low := http.Header{}
+	low.Set("x-ratelimit-remaining", "0")
+	m.rateLimits.observe("a", "eager", low, time.Now())
+
+	for i := 0; i < 4; i++ {
+		picked, _, err := m.pickNext(context.Background(), "eager", "", cliproxyexecutor.Options{}, nil)
+		if err != nil || picked.ID != "b" {
+			t.Fatalf("pick %d: got %v, %v", i, picked, err)
+		}
+	}
+	picked, _, err := m.pickNext(context.Background(), "eager", "", cliproxyexecutor.Options{}, map[string]struct{}{"b": {}})
+	if err != nil || picked.ID != "a" {
+		t.Fatalf("cordoned account should serve when nothing else can: %v, %v", picked, err)
+	}
+}
+
+func TestSelectionFallsBackToCordonedWhenOthersCoolDown(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&streamingExecutor{})
+	m.SetRateLimitPolicy(RateLimitPolicy{Enabled: true, CordonThreshold: 2, FallbackCordon: time.Minute})
+	now := time.Now()
+	for _, auth := range []*Auth{
+		{ID: "cordoned", Provider: "streamy", Status: StatusActive},
+		{ID: "cooling", Provider: "streamy", Status: StatusActive, Unavailable: true, NextRetryAfter: now.Add(time.Minute), Quota: QuotaState{Exceeded: true}},
+	} {
+		if _, err := m.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	until := now.Add(time.Minute)
+	m.rateLimits.observed = map[string]*RateLimitObservation{"cordoned": {CordonedUntil: &until}}
+	picked, _, err := m.pickNext(context.Background(), "streamy", "", cliproxyexecutor.Options{}, nil)
+	if err != nil || picked.ID != "cordoned" {
+		t.Fatalf("pick = %v, %v; want the cordoned account while the other cools down", picked, err)
+	}
+}
@@ -0,0 +1,298 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultReaperInterval   = 10 * time.Minute
+	defaultReaperStaleAfter = 24 * time.Hour
+	defaultReaperPruneAfter = 7 * 24 * time.Hour
+)
+
+// Reconcile action types reported by Reconcile.
+const (
+	ReconcileActionDisableStale = "disable_stale_account"
+	ReconcileActionPruneState   = "prune_runtime_state"
+)
+
+// ReaperPolicy configures the background reconciler that disables long-failing accounts and
+// prunes runtime state left behind by removed accounts.
+type ReaperPolicy struct {
+	Enabled bool
+	// Interval is how often the background pass runs.
+	Interval time.Duration
+	// StaleAfter disables accounts that kept failing for at least this long.
+	StaleAfter time.Duration
+	// PruneAfter drops persisted runtime state of unknown accounts not updated for this long.
+	PruneAfter time.Duration
+}
+
+func (p ReaperPolicy) withDefaults() ReaperPolicy {
+	if p.Interval <= 0 {
+		p.Interval = defaultReaperInterval
+	}
+	if p.StaleAfter <= 0 {
+		p.StaleAfter = defaultReaperStaleAfter
+	}
+	if p.PruneAfter <= 0 {
+		p.PruneAfter = defaultReaperPruneAfter
+	}
+	return p
+}
+
+// ReconcileAction describes one change made (or planned, in dry-run mode) by a reconcile pass.
+type ReconcileAction struct {
+	Type     string `json:"type"`
+	AuthID   string `json:"auth_id"`
+	Provider string `json:"provider,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+// ReconcileReport summarises a reconcile pass.
+type ReconcileReport struct {
+	DryRun    bool              `json:"dry_run"`
+	StartedAt time.Time         `json:"started_at"`
+	Actions   []ReconcileAction `json:"actions"`
+	Errors    []string          `json:"errors,omitempty"`
+}
+
+// RuntimeStatePruner is implemented by runtime state stores that can delete entries.
+type RuntimeStatePruner interface {
+	DeleteRuntimeStates(ctx context.Context, ids []string) error
+}
+
+// reaper holds the reconciler policy, loop and per-account failure tracking.
+type reaper struct {
+	mu sync.Mutex
+	// run serialises reconcile passes so manual and background runs never interleave.
+	run      sync.Mutex
+	policy   ReaperPolicy
+	cancel   context.CancelFunc
+	erroring map[string]*erroringAccount
+}
+
+// erroringAccount tracks a failing account across reconcile passes.
+type erroringAccount struct {
+	// since is the earliest failure seen, bounded from below by the auth's UpdatedAt.
+	since time.Time
+	// lastFailure is the UpdatedAt of the latest failure seen.
+	lastFailure time.Time
+	// passes counts the passes that saw a new failure.
+	passes int
+}
+
+// SetReaperPolicy applies the reconciler policy and (re)starts or stops its background loop.
+func (m *Manager) SetReaperPolicy(ctx context.Context, policy ReaperPolicy) {
+	if m == nil {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	policy = policy.withDefaults()
+	m.reaper.mu.Lock()
+	defer m.reaper.mu.Unlock()
+	if m.reaper.cancel != nil && m.reaper.policy == policy {
+		return
+	}
+	if m.reaper.cancel != nil {
+		m.reaper.cancel()
+		m.reaper.cancel = nil
+	}
+	m.reaper.policy = policy
+	if !policy.Enabled {
+		return
+	}
+	loopCtx, cancel := context.WithCancel(ctx)
+	m.reaper.cancel = cancel
+	go func() {
+		ticker := time.NewTicker(policy.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-loopCtx.Done():
+				return
+			case <-ticker.C:
+				m.Reconcile(loopCtx, false)
+			}
+		}
+	}()
+}
+
+// StopReaper cancels the background reconciler loop, if running.
+func (m *Manager) StopReaper() {
+	if m == nil {
+		return
+	}
+	m.reaper.mu.Lock()
+	if m.reaper.cancel != nil {
+		m.reaper.cancel()
+		m.reaper.cancel = nil
+	}
+	m.reaper.mu.Unlock()
+}
+
+// isReapable reports whether the auth is currently failing in a way the reaper tracks.
+// Config-backed API keys are skipped because a reload would re-enable them anyway.
+func isReapable(auth *Auth) bool {
+	if auth == nil || auth.Disabled || auth.Status == StatusBillingSuspended {
+		return false
+	}
+	if typ, _ := auth.AccountInfo(); typ == "api_key" {
+		return false
+	}
+	return auth.Status == StatusError && auth.LastError != nil && !auth.Quota.Exceeded
+}
+
+// Reconcile runs one reaper pass. With dryRun set it only reports the actions it would take.
+func (m *Manager) Reconcile(ctx context.Context, dryRun bool) ReconcileReport {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	m.reaper.run.Lock()
+	defer m.reaper.run.Unlock()
+
+	now := time.Now()
+	report := ReconcileReport{DryRun: dryRun, StartedAt: now, Actions: []ReconcileAction{}}
+	m.reaper.mu.Lock()
+	policy := m.reaper.policy.withDefaults()
+	if m.reaper.erroring == nil {
+		m.reaper.erroring = make(map[string]*erroringAccount)
+	}
+	erroring := m.reaper.erroring
+	m.reaper.mu.Unlock()
+
+	// Stale accounts: failing for at least StaleAfter, with failures seen on more than one pass so a
+	// single old failure (e.g. restored after a restart) never disables an account on its own.
+	stale := make(map[string]struct{})
+	for _, auth := range m.snapshotAuths() {
+		if !isReapable(auth) {
+			delete(erroring, auth.ID)
+			continue
+		}
+		failedAt := auth.UpdatedAt
+		if failedAt.IsZero() {
+			failedAt = now
+		}
+		state, ok := erroring[auth.ID]
+		switch {
+		case !ok:
+			state = &erroringAccount{since: failedAt, lastFailure: failedAt, passes: 1}
+			erroring[auth.ID] = state
+		case failedAt.After(state.lastFailure):
+			state.lastFailure = failedAt
+			state.passes++
+		}
+		// UpdatedAt marks the latest failure, so it bounds the failure onset from below.
+		if failedAt.Before(state.since) {
+			state.since = failedAt
+		}
+		since := state.since
+		if state.passes < 2 || now.Sub(since) < policy.StaleAfter {
+			continue
+		}
+		stale[auth.ID] = struct{}{}
+		report.Actions = append(report.Actions, ReconcileAction{
+			Type:     ReconcileActionDisableStale,
+			AuthID:   auth.ID,
+			Provider: auth.Provider,
+			Reason:   fmt.Sprintf("failing since %s: %s", since.UTC().Format(time.RFC3339), auth.LastError.Message),
+		})
+	}
+
+	// Orphaned runtime state: persisted entries of accounts that no longer exist.
+	m.mu.RLock()
+	store := m.stateStore
+	var orphans []string
+	for id, state := range m.runtimeStates {
+		if _, ok := m.auths[id]; ok {
+			continue
+		}
+		if !state.UpdatedAt.IsZero() && now.Sub(state.UpdatedAt) < policy.PruneAfter {
+			continue
+		}
+		orphans = append(orphans, id)
+	}
+	m.mu.RUnlock()
+	sort.Strings(orphans)
+	pruner, canPrune := store.(RuntimeStatePruner)
+	if len(orphans) > 0 && !canPrune {
+		report.Errors = append(report.Errors, "runtime state backend does not support pruning")
+		orphans = nil
+	}
+	for _, id := range orphans {
+		report.Actions = append(report.Actions, ReconcileAction{
+			Type:   ReconcileActionPruneState,
+			AuthID: id,
+			Reason: "runtime state belongs to an account that no longer exists",
+		})
+	}
+
+	if !dryRun {
+		if len(stale) > 0 {
+			m.UpdateMatching(ctx, func(a *Auth) bool {
+				_, ok := stale[a.ID]
+				return ok
+			}, func(a *Auth) bool {
+				a.Disabled = true
+				a.Status = StatusDisabled
+				a.StatusMessage = "disabled by reaper: account kept failing"
+				return true
+			})
+			for id := range stale {
+				delete(erroring, id)
+			}
+		}
+		if len(orphans) > 0 {
+			if err := pruner.DeleteRuntimeStates(ctx, orphans); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("prune runtime state: %v", err))
+			} else {
+				m.mu.Lock()
+				for _, id := range orphans {
+					delete(m.runtimeStates, id)
+					delete(m.stateDirty, id)
+				}
+				m.mu.Unlock()
+			}
+		}
+	}
+
+	if len(report.Actions) > 0 || len(report.Errors) > 0 {
+		mode := "applied"
+		if dryRun {
+			mode = "planned"
+		}
+		log.Infof("reconcile %s %d action(s) (%s)%s", mode, len(report.Actions), summariseReconcileActions(report.Actions), formatReconcileErrors(report.Errors))
+	} else {
+		log.Debugf("reconcile found nothing to do (dry_run=%t)", dryRun)
+	}
+	return report
+}
+
+func summariseReconcileActions(actions []ReconcileAction) string {
+	counts := make(map[string]int)
+	for _, action := range actions {
+		counts[action.Type]++
+	}
+	parts := make([]string, 0, len(counts))
+	for typ, count := range counts {
+		parts = append(parts, fmt.Sprintf("%s=%d", typ, count))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
+
+func formatReconcileErrors(errs []string) string {
+	if len(errs) == 0 {
+		return ""
+	}
+	return "; errors: " + strings.Join(errs, "; ")
+}
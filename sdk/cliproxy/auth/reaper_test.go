@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func (s *memoryRuntimeStateStore) DeleteRuntimeStates(_ context.Context, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		delete(s.states, id)
+	}
+	return nil
+}
+
+func TestReconcileDryRunThenApply(t *testing.T) {
+	ctx := context.Background()
+	old := time.Now().Add(-72 * time.Hour)
+	backing := &memoryRuntimeStateStore{states: map[string]RuntimeState{"gone": {Status: StatusError, UpdatedAt: old.Add(-10 * 24 * time.Hour)}}}
+	m := NewManager(nil, nil, nil)
+	if err := m.SetRuntimeStateStore(ctx, backing, time.Hour); err != nil {
+		t.Fatalf("set store: %v", err)
+	}
+	defer m.StopRuntimeStatePersistence(ctx)
+	if _, err := m.Register(ctx, &Auth{ID: "failing", Provider: "claude", Metadata: map[string]any{"email": "a@example.com"}}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if _, err := m.Register(ctx, &Auth{ID: "healthy", Provider: "claude", Metadata: map[string]any{"email": "b@example.com"}}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	m.mu.Lock()
+	failing := m.auths["failing"]
+	failing.Status = StatusError
+	failing.LastError = &Error{HTTPStatus: 401, Message: "invalid grant"}
+	failing.UpdatedAt = old
+	m.mu.Unlock()
+
+	report := m.Reconcile(ctx, true)
+	if len(report.Actions) != 1 || report.Actions[0].Type != ReconcileActionPruneState {
+		t.Fatalf("first pass actions = %+v, want only the prune: one old failure is not enough", report.Actions)
+	}
+
+	// A new failure seen on a later pass confirms the account keeps failing.
+	m.mu.Lock()
+	failing.UpdatedAt = time.Now()
+	m.mu.Unlock()
+	report = m.Reconcile(ctx, true)
+	if len(report.Actions) != 2 {
+		t.Fatalf("dry run actions = %+v, want disable + prune", report.Actions)
+	}
+	if auth, _ := m.GetByID("failing"); auth.Disabled {
+		t.Fatal("dry run disabled the account")
+	}
+	if _, ok := backing.states["gone"]; !ok {
+		t.Fatal("dry run pruned runtime state")
+	}
+
+	report = m.Reconcile(ctx, false)
+	if len(report.Actions) != 2 || len(report.Errors) != 0 {
+		t.Fatalf("apply report = %+v", report)
+	}
+	if auth, _ := m.GetByID("failing"); !auth.Disabled || auth.Status != StatusDisabled {
+		t.Fatalf("stale account not disabled: %+v", auth)
+	}
+	if auth, _ := m.GetByID("healthy"); auth.Disabled {
+		t.Fatal("healthy account disabled")
+	}
+	if _, ok := backing.states["gone"]; ok {
+		t.Fatal("orphaned runtime state not pruned")
+	}
+	if report = m.Reconcile(ctx, true); len(report.Actions) != 0 {
+		t.Fatalf("second pass still has actions: %+v", report.Actions)
+	}
+}
+
+func TestReconcileIgnoresSingleOldFailure(t *testing.T) {
+	ctx := context.Background()
+	m := NewManager(nil, nil, nil)
+	if _, err := m.Register(ctx, &Auth{ID: "restored", Provider: "claude", Metadata: map[string]any{"email": "a@example.com"}}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	m.mu.Lock()
+	restored := m.auths["restored"]
+	restored.Status = StatusError
+	restored.LastError = &Error{HTTPStatus: 500, Message: "upstream error"}
+	restored.UpdatedAt = time.Now().Add(-72 * time.Hour)
+	m.mu.Unlock()
+
+	for pass := 0; pass < 3; pass++ {
+		if report := m.Reconcile(ctx, false); len(report.Actions) != 0 {
+			t.Fatalf("pass %d disabled an account with a single old failure: %+v", pass, report.Actions)
+		}
+	}
+	if auth, _ := m.GetByID("restored"); auth.Disabled {
+		t.Fatal("account disabled without repeated failures")
+	}
+}
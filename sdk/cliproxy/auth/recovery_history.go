@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultRecoveryHistorySize is the number of recovery attempts kept per account by default.
+const DefaultRecoveryHistorySize = 10
+
+// RecoveryAttempt is one request made against an account that was backing off after a failure.
+type RecoveryAttempt struct {
+	At         time.Time `json:"at"`
+	Result     string    `json:"result"`
+	HTTPStatus int       `json:"http_status,omitempty"`
+	Model      string    `json:"model,omitempty"`
+}
+
+// Recovery attempt results.
+const (
+	RecoveryResultSuccess = "success"
+	RecoveryResultFailure = "failure"
+)
+
+// recoveryHistory keeps a ring buffer of the latest recovery attempts per account, in memory only.
+type recoveryHistory struct {
+	mu     sync.Mutex
+	size   int
+	byAuth map[string][]RecoveryAttempt
+}
+
+func (h *recoveryHistory) limit() int {
+	if h.size <= 0 {
+		return DefaultRecoveryHistorySize
+	}
+	return h.size
+}
+
+func (h *recoveryHistory) record(authID string, attempt RecoveryAttempt) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.byAuth == nil {
+		h.byAuth = make(map[string][]RecoveryAttempt)
+	}
+	attempts := append(h.byAuth[authID], attempt)
+	if n := h.limit(); len(attempts) > n {
+		attempts = append([]RecoveryAttempt(nil), attempts[len(attempts)-n:]...)
+	}
+	h.byAuth[authID] = attempts
+}
+
+// SetRecoveryHistorySize sets how many recovery attempts are kept per account; <= 0 uses the
+// default. Shrinking trims the existing histories.
+func (m *Manager) SetRecoveryHistorySize(size int) {
+	if m == nil {
+		return
+	}
+	m.recovery.mu.Lock()
+	defer m.recovery.mu.Unlock()
+	m.recovery.size = size
+	n := m.recovery.limit()
+	for id, attempts := range m.recovery.byAuth {
+		if len(attempts) > n {
+			m.recovery.byAuth[id] = append([]RecoveryAttempt(nil), attempts[len(attempts)-n:]...)
+		}
+	}
+}
+
+// RecoveryHistory returns the latest recovery attempts of the account, oldest first.
+func (m *Manager) RecoveryHistory(id string) []RecoveryAttempt {
+	if m == nil {
+		return nil
+	}
+	m.recovery.mu.Lock()
+	defer m.recovery.mu.Unlock()
+	if attempts := m.recovery.byAuth[id]; len(attempts) > 0 {
+		return append([]RecoveryAttempt(nil), attempts...)
+	}
+	return nil
+}
+
+// isRecoveryAttemptLocked reports whether a result for model is a retry of an account (or of its
+// model state) that was backing off. It must be evaluated before the result is applied. Callers
+// must hold m.mu.
+func isRecoveryAttemptLocked(auth *Auth, model string) bool {
+	if model != "" {
+		if state, ok := auth.ModelStates[model]; ok && state != nil {
+			return !state.NextRetryAfter.IsZero()
+		}
+	}
+	return !auth.NextRetryAfter.IsZero()
+}
+
+// newRecoveryAttempt describes the result as a recovery attempt.
+func newRecoveryAttempt(result Result, now time.Time) RecoveryAttempt {
+	attempt := RecoveryAttempt{At: now, Result: RecoveryResultFailure, Model: result.Model}
+	if result.Success {
+		attempt.Result = RecoveryResultSuccess
+		attempt.HTTPStatus = http.StatusOK
+	} else if result.Error != nil {
+		attempt.HTTPStatus = result.Error.StatusCode()
+	}
+	return attempt
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestRecoveryHistoryRecordsRetriesOfBackingOffAccounts(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	ctx := context.Background()
+	if _, err := m.Register(ctx, &Auth{ID: "a", Provider: "gemini", Status: StatusActive}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	m.MarkResult(ctx, Result{AuthID: "a", Provider: "gemini", Model: "m", Success: true})
+	if got := m.RecoveryHistory("a"); len(got) != 0 {
+		t.Fatalf("healthy request recorded as recovery attempt: %+v", got)
+	}
+
+	// The first failure starts the backoff; only the requests after it are retries.
+	m.MarkResult(ctx, Result{AuthID: "a", Provider: "gemini", Model: "m", Error: &Error{HTTPStatus: http.StatusServiceUnavailable}})
+	m.MarkResult(ctx, Result{AuthID: "a", Provider: "gemini", Model: "m", Error: &Error{HTTPStatus: http.StatusTooManyRequests}})
+	m.MarkResult(ctx, Result{AuthID: "a", Provider: "gemini", Model: "m", Success: true})
+	m.MarkResult(ctx, Result{AuthID: "a", Provider: "gemini", Model: "m", Success: true})
+
+	got := m.RecoveryHistory("a")
+	if len(got) != 2 {
+		t.Fatalf("recovery history = %+v, want 2 attempts", got)
+	}
+	if got[0].Result != RecoveryResultFailure || got[0].HTTPStatus != http.StatusTooManyRequests || got[0].Model != "m" {
+		t.Fatalf("first attempt = %+v", got[0])
+	}
+	if got[1].Result != RecoveryResultSuccess || got[1].HTTPStatus != http.StatusOK || got[1].At.Before(got[0].At) {
+		t.Fatalf("second attempt = %+v", got[1])
+	}
+}
+
+func TestRecoveryHistoryIsBounded(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	ctx := context.Background()
+	if _, err := m.Register(ctx, &Auth{ID: "a", Provider: "gemini", Status: StatusActive}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	m.SetRecoveryHistorySize(3)
+	for i := 0; i < 6; i++ {
+		m.MarkResult(ctx, Result{AuthID: "a", Provider: "gemini", Error: &Error{HTTPStatus: http.StatusBadGateway}})
+	}
+	if got := m.RecoveryHistory("a"); len(got) != 3 {
+		t.Fatalf("history has %d attempts, want 3", len(got))
+	}
+	m.SetRecoveryHistorySize(1)
+	if got := m.RecoveryHistory("a"); len(got) != 1 || got[0].HTTPStatus != http.StatusBadGateway {
+		t.Fatalf("shrunk history = %+v", got)
+	}
+}
@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RefreshConcurrencyPolicy caps how many token refreshes run at once per provider, so bulk and
+// startup refreshes do not trip the rate limits of a provider's auth endpoint.
+type RefreshConcurrencyPolicy struct {
+	// Default applies to providers without an explicit entry; 0 leaves them unlimited.
+	Default int
+	// Providers overrides the default per provider; 0 leaves that provider unlimited.
+	Providers map[string]int
+}
+
+// RefreshConcurrency reports the refresh slots of one provider.
+type RefreshConcurrency struct {
+	Provider string `json:"provider"`
+	// Limit is the configured maximum; 0 means unlimited.
+	Limit   int `json:"limit"`
+	Active  int `json:"active"`
+	Waiting int `json:"waiting"`
+}
+
+// refreshLimiter is a per-provider semaphore for token refreshes whose limits can change at runtime.
+type refreshLimiter struct {
+	mu      sync.Mutex
+	policy  RefreshConcurrencyPolicy
+	active  map[string]int
+	waiters map[string][]chan struct{}
+}
+
+// SetRefreshConcurrencyPolicy replaces the per-provider refresh limits. Raising a limit admits
+// waiting refreshes immediately; lowering it lets running ones finish.
+func (m *Manager) SetRefreshConcurrencyPolicy(policy RefreshConcurrencyPolicy) {
+	if m == nil {
+		return
+	}
+	providers := make(map[string]int, len(policy.Providers))
+	for provider, limit := range policy.Providers {
+		providers[strings.ToLower(strings.TrimSpace(provider))] = max(limit, 0)
+	}
+	policy.Providers = providers
+	policy.Default = max(policy.Default, 0)
+	l := &m.refreshLimit
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.policy = policy
+	for provider := range l.waiters {
+		l.dispatchLocked(provider)
+	}
+}
+
+// RefreshConcurrency reports the limit and current refresh concurrency of every configured or busy
+// provider, sorted by provider.
+func (m *Manager) RefreshConcurrency() []RefreshConcurrency {
+	if m == nil {
+		return nil
+	}
+	l := &m.refreshLimit
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	providers := make(map[string]struct{})
+	for provider := range l.policy.Providers {
+		providers[provider] = struct{}{}
+	}
+	for provider, n := range l.active {
+		if n > 0 {
+			providers[provider] = struct{}{}
+		}
+	}
+	for provider, waiting := range l.waiters {
+		if len(waiting) > 0 {
+			providers[provider] = struct{}{}
+		}
+	}
+	out := make([]RefreshConcurrency, 0, len(providers))
+	for provider := range providers {
+		out = append(out, RefreshConcurrency{
+			Provider: provider,
+			Limit:    l.limitLocked(provider),
+			Active:   l.active[provider],
+			Waiting:  len(l.waiters[provider]),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Provider < out[j].Provider })
+	return out
+}
+
+// DefaultRefreshConcurrency returns the limit applied to providers without their own entry.
+func (m *Manager) DefaultRefreshConcurrency() int {
+	if m == nil {
+		return 0
+	}
+	m.refreshLimit.mu.Lock()
+	defer m.refreshLimit.mu.Unlock()
+	return m.refreshLimit.policy.Default
+}
+
+func (l *refreshLimiter) limitLocked(provider string) int {
+	if limit, ok := l.policy.Providers[provider]; ok {
+		return limit
+	}
+	return l.policy.Default
+}
+
+// acquire blocks until a refresh slot of provider is free or ctx ends. The returned release must be
+// called once the refresh finished.
+func (l *refreshLimiter) acquire(ctx context.Context, provider string) (release func(), err error) {
+	provider = strings.ToLower(provider)
+	release = func() { l.release(provider) }
+	l.mu.Lock()
+	if l.active == nil {
+		l.active = make(map[string]int)
+		l.waiters = make(map[string][]chan struct{})
+	}
+	if limit := l.limitLocked(provider); limit <= 0 || l.active[provider] < limit {
+		l.active[provider]++
+		l.mu.Unlock()
+		return release, nil
+	}
+	ready := make(chan struct{})
+	l.waiters[provider] = append(l.waiters[provider], ready)
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+		return release, nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		waiting := l.waiters[provider]
+		for i, ch := range waiting {
+			if ch == ready {
+				l.waiters[provider] = append(waiting[:i], waiting[i+1:]...)
+				return nil, ctx.Err()
+			}
+		}
+		// The slot was granted while ctx ended; hand it on.
+		l.active[provider]--
+		l.dispatchLocked(provider)
+		return nil, ctx.Err()
+	}
+}
+
+func (l *refreshLimiter) release(provider string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active[provider]--
+	l.dispatchLocked(provider)
+}
+
+// dispatchLocked admits waiting refreshes of provider while slots are free.
+func (l *refreshLimiter) dispatchLocked(provider string) {
+	limit := l.limitLocked(provider)
+	for len(l.waiters[provider]) > 0 && (limit <= 0 || l.active[provider] < limit) {
+		l.active[provider]++
+		close(l.waiters[provider][0])
+		l.waiters[provider] = l.waiters[provider][1:]
+	}
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRefreshLimiterQueuesBeyondProviderLimit(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetRefreshConcurrencyPolicy(RefreshConcurrencyPolicy{Providers: map[string]int{"Codex": 1}})
+	ctx := context.Background()
+
+	release, err := m.refreshLimit.acquire(ctx, "codex")
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	// Providers without a limit are never queued.
+	if other, errOther := m.refreshLimit.acquire(ctx, "gemini"); errOther != nil {
+		t.Fatalf("acquire unlimited: %v", errOther)
+	} else {
+		other()
+	}
+
+	acquired := make(chan func())
+	go func() {
+		next, _ := m.refreshLimit.acquire(ctx, "codex")
+		acquired <- next
+	}()
+	waitFor(t, func() bool { return len(m.RefreshConcurrency()) == 1 && m.RefreshConcurrency()[0].Waiting == 1 })
+	if got := m.RefreshConcurrency()[0]; got.Provider != "codex" || got.Limit != 1 || got.Active != 1 {
+		t.Fatalf("unexpected concurrency report: %+v", got)
+	}
+
+	release()
+	select {
+	case next := <-acquired:
+		next()
+	case <-time.After(time.Second):
+		t.Fatal("queued refresh was not admitted after release")
+	}
+	if got := m.RefreshConcurrency()[0]; got.Active != 0 || got.Waiting != 0 {
+		t.Fatalf("expected idle limiter, got %+v", got)
+	}
+}
+
+func TestRefreshLimiterCancelledWaiterLeavesQueue(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetRefreshConcurrencyPolicy(RefreshConcurrencyPolicy{Default: 1})
+	release, _ := m.refreshLimit.acquire(context.Background(), "claude")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := m.refreshLimit.acquire(ctx, "claude"); err == nil {
+		t.Fatal("expected the waiter to give up when its context ended")
+	}
+	release()
+	if got := m.RefreshConcurrency(); len(got) != 0 {
+		t.Fatalf("expected no busy providers, got %+v", got)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// headroomTolerance groups accounts whose remaining headroom differs by less than this fraction, so
+// near-equal accounts keep rotating through the configured selector.
+const headroomTolerance = 0.01
+
+// RequestCapPolicy configures soft daily and monthly request caps. Caps are not enforced; with the
+// policy enabled the selector prefers the accounts furthest from their caps. Accounts override the
+// defaults with the "daily_cap" and "monthly_cap" attributes.
+type RequestCapPolicy struct {
+	Enabled bool
+	// Daily and Monthly are the default caps; zero leaves the period uncapped.
+	Daily   int64
+	Monthly int64
+	// Location defines where days and months start; nil means UTC.
+	Location *time.Location
+}
+
+// RequestHeadroom reports an account's usage against its soft caps.
+type RequestHeadroom struct {
+	DailyCap    int64 `json:"daily_cap,omitempty"`
+	DailyUsed   int64 `json:"daily_used"`
+	MonthlyCap  int64 `json:"monthly_cap,omitempty"`
+	MonthlyUsed int64 `json:"monthly_used"`
+	// Remaining is the smallest fraction of any cap still unused, between 0 and 1.
+	Remaining float64 `json:"remaining"`
+}
+
+type capUsage struct {
+	day     string
+	month   string
+	daily   int64
+	monthly int64
+}
+
+// requestCaps counts selections per account and calendar period. Counters live in memory only.
+type requestCaps struct {
+	mu     sync.Mutex
+	policy RequestCapPolicy
+	usage  map[string]*capUsage
+}
+
+// SetRequestCapPolicy replaces the soft request cap policy used by selection.
+func (m *Manager) SetRequestCapPolicy(policy RequestCapPolicy) {
+	if m == nil {
+		return
+	}
+	m.caps.mu.Lock()
+	m.caps.policy = policy
+	m.caps.mu.Unlock()
+}
+
+func (c *requestCaps) enabled() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.policy.Enabled
+}
+
+func (c *requestCaps) periods(now time.Time) (day, month string) {
+	loc := c.policy.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+	return local.Format("2006-01-02"), local.Format("2006-01")
+}
+
+// usageLocked returns the counters of authID for the current periods. Callers must hold c.mu.
+func (c *requestCaps) usageLocked(authID string, now time.Time) *capUsage {
+	day, month := c.periods(now)
+	usage := c.usage[authID]
+	if usage == nil {
+		if c.usage == nil {
+			c.usage = make(map[string]*capUsage)
+		}
+		usage = &capUsage{day: day, month: month}
+		c.usage[authID] = usage
+	}
+	if usage.day != day {
+		usage.day, usage.daily = day, 0
+	}
+	if usage.month != month {
+		usage.month, usage.monthly = month, 0
+	}
+	return usage
+}
+
+// record counts a selection of authID.
+func (c *requestCaps) record(authID string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.policy.Enabled {
+		return
+	}
+	usage := c.usageLocked(authID, now)
+	usage.daily++
+	usage.monthly++
+}
+
+// headroom reports the auth's usage against its caps; ok is false when the auth has no caps.
+func (c *requestCaps) headroom(auth *Auth, now time.Time) (RequestHeadroom, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.policy.Enabled || auth == nil {
+		return RequestHeadroom{}, false
+	}
+	daily := capAttribute(auth, "daily_cap", c.policy.Daily)
+	monthly := capAttribute(auth, "monthly_cap", c.policy.Monthly)
+	if daily <= 0 && monthly <= 0 {
+		return RequestHeadroom{}, false
+	}
+	usage := c.usageLocked(auth.ID, now)
+	h := RequestHeadroom{DailyCap: daily, DailyUsed: usage.daily, MonthlyCap: monthly, MonthlyUsed: usage.monthly, Remaining: 1}
+	if daily > 0 {
+		h.Remaining = min(h.Remaining, remainingFraction(usage.daily, daily))
+	}
+	if monthly > 0 {
+		h.Remaining = min(h.Remaining, remainingFraction(usage.monthly, monthly))
+	}
+	return h, true
+}
+
+func remainingFraction(used, limit int64) float64 {
+	if used >= limit {
+		return 0
+	}
+	return float64(limit-used) / float64(limit)
+}
+
+// capAttribute returns the per-account cap attribute, falling back to def when unset or invalid.
+func capAttribute(a *Auth, key string, def int64) int64 {
+	if a.Attributes == nil {
+		return def
+	}
+	raw := strings.TrimSpace(a.Attributes[key])
+	if raw == "" {
+		return def
+	}
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || v < 0 {
+		return def
+	}
+	return v
+}
+
+// hasHeadroom reports whether auth may take another request under its caps. Uncapped accounts and
+// disabled caps always have headroom.
+func (c *requestCaps) hasHeadroom(auth *Auth, now time.Time) bool {
+	if !c.enabled() {
+		return true
+	}
+	h, ok := c.headroom(auth, now)
+	return !ok || h.Remaining > 0
+}
+
+// preferHeadroom narrows candidates to the available accounts with the most remaining headroom.
+// Uncapped accounts count as full headroom. Candidates are returned unchanged when none is available.
+func (c *requestCaps) preferHeadroom(model string, candidates []*Auth, now time.Time) []*Auth {
+	if len(candidates) < 2 || !c.enabled() {
+		return candidates
+	}
+	type scored struct {
+		auth      *Auth
+		remaining float64
+	}
+	available := make([]scored, 0, len(candidates))
+	best := -1.0
+	for _, candidate := range candidates {
+		if blocked, _, _ := isAuthBlockedForModel(candidate, model, now); blocked {
+			continue
+		}
+		remaining := 1.0
+		if h, ok := c.headroom(candidate, now); ok {
+			remaining = h.Remaining
+		}
+		available = append(available, scored{auth: candidate, remaining: remaining})
+		best = max(best, remaining)
+	}
+	if len(available) == 0 {
+		return candidates
+	}
+	out := make([]*Auth, 0, len(available))
+	for _, s := range available {
+		if s.remaining >= best-headroomTolerance {
+			out = append(out, s.auth)
+		}
+	}
+	return out
+}
+
+// RequestHeadroom reports the auth's usage against its soft request caps, or nil when the policy is
+// off or the auth has no caps.
+func (m *Manager) RequestHeadroom(auth *Auth) *RequestHeadroom {
+	if m == nil {
+		return nil
+	}
+	h, ok := m.caps.headroom(auth, time.Now())
+	if !ok {
+		return nil
+	}
+	return &h
+}
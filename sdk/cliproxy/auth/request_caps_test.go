@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestRequestCapsPreferHeadroom(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&slowRefreshExecutor{})
+	m.SetRequestCapPolicy(RequestCapPolicy{Enabled: true, Daily: 100})
+	for _, auth := range []*Auth{
+		{ID: "small", Provider: "eager", Status: StatusActive, Attributes: map[string]string{"daily_cap": "10"}},
+		{ID: "large", Provider: "eager", Status: StatusActive},
+	} {
+		if _, err := m.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	counts := make(map[string]int)
+	for i := 0; i < 55; i++ {
+		picked, _, err := m.pickNext(context.Background(), "eager", "", cliproxyexecutor.Options{}, nil)
+		if err != nil {
+			t.Fatalf("pick: %v", err)
+		}
+		counts[picked.ID]++
+	}
+	// Both accounts should end near the same fraction of their caps: 5/10 and 50/100.
+	if counts["small"] < 4 || counts["small"] > 6 {
+		t.Fatalf("expected load proportional to caps, got %v", counts)
+	}
+	small, _ := m.GetByID("small")
+	headroom := m.RequestHeadroom(small)
+	if headroom == nil || headroom.DailyCap != 10 || headroom.DailyUsed != int64(counts["small"]) {
+		t.Fatalf("unexpected headroom %+v", headroom)
+	}
+}
+
+func TestRequestCapsResetDaily(t *testing.T) {
+	caps := &requestCaps{policy: RequestCapPolicy{Enabled: true, Daily: 2, Monthly: 10}}
+	auth := &Auth{ID: "a"}
+	day := time.Date(2026, 10, 14, 23, 0, 0, 0, time.UTC)
+	caps.record("a", day)
+	caps.record("a", day)
+	if h, _ := caps.headroom(auth, day); h.Remaining != 0 {
+		t.Fatalf("expected exhausted daily cap, got %+v", h)
+	}
+	h, _ := caps.headroom(auth, day.Add(2*time.Hour))
+	if h.DailyUsed != 0 || h.MonthlyUsed != 2 || h.Remaining != 0.8 {
+		t.Fatalf("expected daily reset with monthly usage kept, got %+v", h)
+	}
+}
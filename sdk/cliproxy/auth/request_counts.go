@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// recentResultsWindow bounds how far back the rate, error and latency aggregates look.
+	recentResultsWindow = 5 * time.Minute
+	// recentResultsMax caps the results kept per account to bound memory under heavy load.
+	recentResultsMax = 2000
+)
+
+// RequestCounts tallies the results recorded for an account since the process started or the
+// counters were last reset. Counters live in memory only.
+type RequestCounts struct {
+	Requests  int64 `json:"requests"`
+	Successes int64 `json:"successes"`
+	Failures  int64 `json:"failures"`
+	// EmptyResponses counts upstream responses that succeeded without carrying any content.
+	EmptyResponses int64 `json:"empty_responses"`
+}
+
+// resultSample is one recorded result kept for the recent aggregates.
+type resultSample struct {
+	at      time.Time
+	failed  bool
+	latency time.Duration
+}
+
+type requestCounters struct {
+	mu     sync.Mutex
+	byAuth map[string]*RequestCounts
+	// recent holds each account's results of the last recentResultsWindow, oldest first.
+	recent map[string][]resultSample
+}
+
+func (c *requestCounters) record(result Result, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.byAuth == nil {
+		c.byAuth = make(map[string]*RequestCounts)
+		c.recent = make(map[string][]resultSample)
+	}
+	counts := c.byAuth[result.AuthID]
+	if counts == nil {
+		counts = &RequestCounts{}
+		c.byAuth[result.AuthID] = counts
+	}
+	counts.Requests++
+	if result.Success {
+		counts.Successes++
+	} else {
+		counts.Failures++
+	}
+
+	// Streams are judged by how soon they start, not by how long the client kept reading.
+	latency := result.Latency
+	if result.FirstChunkLatency > 0 {
+		latency = result.FirstChunkLatency
+	}
+	samples := pruneSamples(c.recent[result.AuthID], now)
+	samples = append(samples, resultSample{at: now, failed: !result.Success, latency: latency})
+	if len(samples) > recentResultsMax {
+		samples = samples[len(samples)-recentResultsMax:]
+	}
+	c.recent[result.AuthID] = samples
+}
+
+func (c *requestCounters) recordEmpty(authID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.byAuth == nil {
+		c.byAuth = make(map[string]*RequestCounts)
+		c.recent = make(map[string][]resultSample)
+	}
+	counts := c.byAuth[authID]
+	if counts == nil {
+		counts = &RequestCounts{}
+		c.byAuth[authID] = counts
+	}
+	counts.EmptyResponses++
+}
+
+// recentSamples returns a copy of each account's results of the last recentResultsWindow.
+func (c *requestCounters) recentSamples(now time.Time) map[string][]resultSample {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string][]resultSample, len(c.recent))
+	for id, samples := range c.recent {
+		samples = pruneSamples(samples, now)
+		c.recent[id] = samples
+		if len(samples) > 0 {
+			out[id] = append([]resultSample(nil), samples...)
+		}
+	}
+	return out
+}
+
+// pruneSamples drops the samples that fell out of the recent window.
+func pruneSamples(list []resultSample, now time.Time) []resultSample {
+	cutoff := now.Add(-recentResultsWindow)
+	idx := sort.Search(len(list), func(i int) bool { return list[i].at.After(cutoff) })
+	if idx == 0 {
+		return list
+	}
+	return append(list[:0], list[idx:]...)
+}
+
+// RequestCounts returns the request counters of the account.
+func (m *Manager) RequestCounts(id string) RequestCounts {
+	if m == nil {
+		return RequestCounts{}
+	}
+	m.requestCounts.mu.Lock()
+	defer m.requestCounts.mu.Unlock()
+	if counts := m.requestCounts.byAuth[id]; counts != nil {
+		return *counts
+	}
+	return RequestCounts{}
+}
+
+// ResetRequestCounts clears the counters and recent results of the given accounts, or of every
+// account when ids is empty, and returns how many accounts had counters.
+func (m *Manager) ResetRequestCounts(ids ...string) int {
+	if m == nil {
+		return 0
+	}
+	m.requestCounts.mu.Lock()
+	defer m.requestCounts.mu.Unlock()
+	if len(ids) == 0 {
+		n := len(m.requestCounts.byAuth)
+		m.requestCounts.byAuth = nil
+		m.requestCounts.recent = nil
+		return n
+	}
+	n := 0
+	for _, id := range ids {
+		if _, ok := m.requestCounts.byAuth[id]; ok {
+			delete(m.requestCounts.byAuth, id)
+			delete(m.requestCounts.recent, id)
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRequestCountsRecordResultsAndReset(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	ctx := context.Background()
+	m.MarkResult(ctx, Result{AuthID: "a", Provider: "eager", Success: true})
+	m.MarkResult(ctx, Result{AuthID: "a", Provider: "eager", Success: false})
+	m.MarkResult(ctx, Result{AuthID: "b", Provider: "eager", Success: true})
+
+	if got := m.RequestCounts("a"); got != (RequestCounts{Requests: 2, Successes: 1, Failures: 1}) {
+		t.Fatalf("unexpected counts for a: %+v", got)
+	}
+	if n := m.ResetRequestCounts("a", "missing"); n != 1 {
+		t.Fatalf("reset %d accounts, want 1", n)
+	}
+	if got := m.RequestCounts("a"); got != (RequestCounts{}) {
+		t.Fatalf("counts not reset: %+v", got)
+	}
+	if n := m.ResetRequestCounts(); n != 1 || m.RequestCounts("b").Requests != 0 {
+		t.Fatalf("reset all returned %d", n)
+	}
+}
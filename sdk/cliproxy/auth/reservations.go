@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// Reservation is a snapshot of accounts set aside for requests naming the reservation.
+type Reservation struct {
+	Name      string    `json:"name"`
+	Accounts  []string  `json:"accounts"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// Served counts requests dispatched to the reserved accounts.
+	Served int64 `json:"requests_served"`
+	// InFlight counts reserved requests still running.
+	InFlight int64 `json:"in_flight"`
+}
+
+// reservationBook tracks active reservations and which reservation holds each account.
+type reservationBook struct {
+	mu     sync.Mutex
+	byName map[string]*Reservation
+	byAuth map[string]*Reservation
+}
+
+// pruneLocked drops expired reservations so their accounts return to the general pool.
+func (b *reservationBook) pruneLocked(now time.Time) {
+	for name, r := range b.byName {
+		if now.Before(r.ExpiresAt) {
+			continue
+		}
+		b.removeLocked(name, r)
+	}
+}
+
+func (b *reservationBook) removeLocked(name string, r *Reservation) {
+	delete(b.byName, name)
+	for _, id := range r.Accounts {
+		if b.byAuth[id] == r {
+			delete(b.byAuth, id)
+		}
+	}
+}
+
+// admits reports whether a request naming reservation (empty for general traffic) may use auth.
+// Reserved accounts only serve their own reservation; an unknown or expired reservation falls back
+// to the general pool.
+func (b *reservationBook) admits(auth *Auth, name string, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pruneLocked(now)
+	holder := b.byAuth[auth.ID]
+	if _, active := b.byName[name]; name == "" || !active {
+		return holder == nil
+	}
+	return holder != nil && holder.Name == name
+}
+
+// begin counts a request dispatched to auth against its reservation and returns the function that
+// marks it finished.
+func (b *reservationBook) begin(authID string) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	r := b.byAuth[authID]
+	if r == nil {
+		return func() {}
+	}
+	r.Served++
+	r.InFlight++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			r.InFlight--
+			b.mu.Unlock()
+		})
+	}
+}
+
+// reservationName returns the reservation a request asked for, if any.
+func reservationName(opts cliproxyexecutor.Options) string {
+	name, _ := opts.Metadata[cliproxyexecutor.ReservationMetadataKey].(string)
+	return strings.TrimSpace(name)
+}
+
+// Reserve sets the accounts aside for requests naming the reservation until ttl elapses.
+func (m *Manager) Reserve(name string, authIDs []string, ttl time.Duration) (Reservation, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(authIDs) == 0 || ttl <= 0 {
+		return Reservation{}, &Error{Code: "invalid_reservation", Message: "name, accounts and a positive ttl are required"}
+	}
+	m.mu.RLock()
+	for _, id := range authIDs {
+		if _, ok := m.auths[id]; !ok {
+			m.mu.RUnlock()
+			return Reservation{}, &Error{Code: "auth_not_found", Message: "unknown account " + id}
+		}
+	}
+	m.mu.RUnlock()
+
+	now := time.Now()
+	b := &m.reservations
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pruneLocked(now)
+	if b.byName == nil {
+		b.byName = make(map[string]*Reservation)
+		b.byAuth = make(map[string]*Reservation)
+	}
+	if _, exists := b.byName[name]; exists {
+		return Reservation{}, &Error{Code: "reservation_exists", Message: "reservation " + name + " already exists"}
+	}
+	for _, id := range authIDs {
+		if holder := b.byAuth[id]; holder != nil {
+			return Reservation{}, &Error{Code: "account_reserved", Message: "account " + id + " is held by reservation " + holder.Name}
+		}
+	}
+	r := &Reservation{Name: name, Accounts: append([]string(nil), authIDs...), CreatedAt: now, ExpiresAt: now.Add(ttl)}
+	b.byName[name] = r
+	for _, id := range authIDs {
+		b.byAuth[id] = r
+	}
+	return *r, nil
+}
+
+// Reservations lists active reservations ordered by name.
+func (m *Manager) Reservations() []Reservation {
+	if m == nil {
+		return nil
+	}
+	b := &m.reservations
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pruneLocked(time.Now())
+	out := make([]Reservation, 0, len(b.byName))
+	for _, r := range b.byName {
+		snapshot := *r
+		snapshot.Accounts = append([]string(nil), r.Accounts...)
+		out = append(out, snapshot)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// ReleaseReservation returns the reservation's accounts to the general pool immediately. Requests
+// already running on them finish normally; the returned snapshot reports how many are in flight.
+func (m *Manager) ReleaseReservation(name string) (Reservation, bool) {
+	if m == nil {
+		return Reservation{}, false
+	}
+	b := &m.reservations
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pruneLocked(time.Now())
+	r, ok := b.byName[name]
+	if !ok {
+		return Reservation{}, false
+	}
+	b.removeLocked(name, r)
+	return *r, true
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestReservationsRouteListAndRelease(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&batchingExecutor{})
+	for _, id := range []string{"a", "b"} {
+		if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: "batchy", Status: StatusActive}); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	if _, err := m.Reserve("job", []string{"b"}, time.Hour); err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+	if _, err := m.Reserve("other", []string{"b"}, time.Hour); err == nil {
+		t.Fatalf("expected an account to be held by one reservation only")
+	}
+
+	reserved := cliproxyexecutor.Options{Metadata: map[string]any{cliproxyexecutor.ReservationMetadataKey: "job"}}
+	for i := 0; i < 3; i++ {
+		if picked, _, err := m.pickNext(context.Background(), "batchy", "", cliproxyexecutor.Options{}, nil); err != nil || picked.ID != "a" {
+			t.Fatalf("general traffic must skip reserved accounts, got %v, %v", picked, err)
+		}
+		if picked, _, err := m.pickNext(context.Background(), "batchy", "", reserved, nil); err != nil || picked.ID != "b" {
+			t.Fatalf("reserved traffic must use its accounts, got %v, %v", picked, err)
+		}
+	}
+	if _, err := m.Execute(context.Background(), []string{"batchy"}, cliproxyexecutor.Request{}, reserved); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	list := m.Reservations()
+	if len(list) != 1 || list[0].Name != "job" || list[0].Served != 1 || list[0].InFlight != 0 {
+		t.Fatalf("unexpected reservations %+v", list)
+	}
+
+	finish := m.reservations.begin("b")
+	released, ok := m.ReleaseReservation("job")
+	if !ok || released.InFlight != 1 {
+		t.Fatalf("expected release to report one in-flight request, got %+v, %t", released, ok)
+	}
+	finish()
+	if len(m.Reservations()) != 0 {
+		t.Fatalf("expected no active reservations after release")
+	}
+	if picked, _, err := m.pickNext(context.Background(), "batchy", "", cliproxyexecutor.Options{}, map[string]struct{}{"a": {}}); err != nil || picked.ID != "b" {
+		t.Fatalf("released account must return to the general pool, got %v, %v", picked, err)
+	}
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// ResultOutcome describes what recording a result did to the account.
+type ResultOutcome struct {
+	// Quota is set when the result was a quota or rate-limit failure.
+	Quota bool
+	// CooldownUntil is when the account (or its model) becomes selectable again when the result put
+	// it into cooldown or backoff; zero otherwise.
+	CooldownUntil time.Time
+}
+
+// ResultObserver receives every recorded result with its outcome. ctx is the context the result
+// was recorded with, so request-scoped values reach the observer.
+type ResultObserver func(ctx context.Context, result Result, outcome ResultOutcome)
+
+// SetResultObserver installs fn to observe recorded results; nil removes it.
+func (m *Manager) SetResultObserver(fn ResultObserver) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.resultObserver = fn
+	m.mu.Unlock()
+}
+
+// resultOutcomeLocked reads the outcome of a just-recorded failure from auth.
+func resultOutcomeLocked(auth *Auth, result Result, now time.Time) ResultOutcome {
+	outcome := ResultOutcome{Quota: statusCodeFromResult(result.Error) == 429}
+	next := auth.NextRetryAfter
+	if auth.Quota.NextRecoverAt.After(next) {
+		next = auth.Quota.NextRecoverAt
+	}
+	if result.Model != "" {
+		if state := auth.ModelStates[result.Model]; state != nil {
+			next = state.NextRetryAfter
+			if state.Quota.Exceeded {
+				outcome.Quota = true
+			}
+		}
+	}
+	if next.After(now) {
+		outcome.CooldownUntil = next
+	}
+	return outcome
+}
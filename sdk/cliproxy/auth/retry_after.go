@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// retryAfterHeaderReason marks cooldowns timed by an upstream Retry-After header.
+const retryAfterHeaderReason = "retry_after_header"
+
+// retryAfterHeaderFromError returns when the upstream asked to be retried through the Retry-After
+// header of the 429 or 503 response carried by err.
+func retryAfterHeaderFromError(err error, now time.Time) *time.Time {
+	var hp interface{ ResponseHeader() http.Header }
+	if err == nil || !errors.As(err, &hp) || hp == nil {
+		return nil
+	}
+	var se interface{ StatusCode() int }
+	if !errors.As(err, &se) || se == nil {
+		return nil
+	}
+	if status := se.StatusCode(); status != http.StatusTooManyRequests && status != http.StatusServiceUnavailable {
+		return nil
+	}
+	if at, ok := parseRetryAfter(hp.ResponseHeader().Get("Retry-After"), now); ok {
+		return &at
+	}
+	return nil
+}
+
+// parseRetryAfter accepts the two forms RFC 9110 allows: delay seconds and an HTTP-date. Malformed
+// values and times not in the future are ignored.
+func parseRetryAfter(raw string, now time.Time) (time.Time, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, false
+	}
+	var at time.Time
+	if seconds, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		if seconds <= 0 {
+			return time.Time{}, false
+		}
+		at = now.Add(time.Duration(seconds) * time.Second)
+	} else if t, errTime := http.ParseTime(raw); errTime == nil {
+		at = t
+	} else {
+		return time.Time{}, false
+	}
+	if !at.After(now) {
+		return time.Time{}, false
+	}
+	return at, true
+}
+
+// laterRetryAfter returns retryAt when the server asked for a longer wait than the computed next
+// retry time, reporting whether it did.
+func laterRetryAfter(next time.Time, retryAt *time.Time) (time.Time, bool) {
+	if retryAt == nil || !retryAt.After(next) {
+		return next, false
+	}
+	return *retryAt, true
+}
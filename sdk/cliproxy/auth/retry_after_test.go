@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := now.Add(90 * time.Second)
+	for _, raw := range []string{"90", at.Format(http.TimeFormat)} {
+		if got, ok := parseRetryAfter(raw, now); !ok || !got.Equal(at) {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v", raw, got, ok, at)
+		}
+	}
+	for _, raw := range []string{"", "soon", "1m30s", "-5", "0", now.Add(-time.Minute).Format(http.TimeFormat)} {
+		if _, ok := parseRetryAfter(raw, now); ok {
+			t.Errorf("parseRetryAfter(%q) should be rejected", raw)
+		}
+	}
+	err := headerError{header: http.Header{"Retry-After": {"90"}}}
+	if got := retryAfterHeaderFromError(err, now); got == nil || !got.Equal(at) {
+		t.Fatalf("retryAfterHeaderFromError = %v", got)
+	}
+}
+
+func TestRetryAfterHeaderOverridesShorterBackoff(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	if _, errReg := m.Register(context.Background(), &Auth{ID: "a", Provider: "gemini", Status: StatusActive}); errReg != nil {
+		t.Fatalf("register: %v", errReg)
+	}
+	rateLimited := &Error{Message: "rate limited", HTTPStatus: http.StatusTooManyRequests}
+
+	soon := time.Now().Add(100 * time.Millisecond)
+	m.MarkResult(context.Background(), Result{AuthID: "a", Provider: "gemini", Model: "m", RetryAfterAt: &soon, Error: rateLimited})
+	auth, _ := m.GetByID("a")
+	if state := auth.ModelStates["m"]; state.Quota.Reason != "quota" || !state.NextRetryAfter.After(soon) {
+		t.Fatalf("a shorter Retry-After should keep the backoff, got %v (%s)", state.NextRetryAfter, state.Quota.Reason)
+	}
+
+	later := time.Now().Add(10 * time.Minute)
+	m.MarkResult(context.Background(), Result{AuthID: "a", Provider: "gemini", Model: "m", RetryAfterAt: &later, Error: rateLimited})
+	auth, _ = m.GetByID("a")
+	if state := auth.ModelStates["m"]; !state.Quota.NextRecoverAt.Equal(later) || state.Quota.Reason != retryAfterHeaderReason {
+		t.Fatalf("model cooldown should follow Retry-After, got %v (%s)", state.Quota.NextRecoverAt, state.Quota.Reason)
+	}
+
+}
+
+func TestRetryAfterOn503LeavesQuotaAlone(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	if _, errReg := m.Register(context.Background(), &Auth{ID: "a", Provider: "gemini", Status: StatusActive}); errReg != nil {
+		t.Fatalf("register: %v", errReg)
+	}
+	later := time.Now().Add(10 * time.Minute)
+	unavailable := &Error{Message: "overloaded", HTTPStatus: http.StatusServiceUnavailable}
+	m.MarkResult(context.Background(), Result{AuthID: "a", Provider: "gemini", RetryAfterAt: &later, Error: unavailable})
+	m.MarkResult(context.Background(), Result{AuthID: "a", Provider: "gemini", Model: "m", RetryAfterAt: &later, Error: unavailable})
+	auth, _ := m.GetByID("a")
+	if !auth.NextRetryAfter.Equal(later) || auth.Quota != (QuotaState{}) {
+		t.Fatalf("503 should only delay retries, got %v with quota %+v", auth.NextRetryAfter, auth.Quota)
+	}
+	if state := auth.ModelStates["m"]; !state.NextRetryAfter.Equal(later) || state.Quota != (QuotaState{}) {
+		t.Fatalf("model 503 should only delay retries, got %v with quota %+v", state.NextRetryAfter, state.Quota)
+	}
+}
@@ -0,0 +1,409 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultRuntimeStateFlushInterval debounces runtime state writes so bursts of results share one batch.
+const defaultRuntimeStateFlushInterval = 2 * time.Second
+
+// RuntimeState captures the scheduling state of an auth (status, cooldowns, per-model state) that
+// should survive restarts and credential file reloads.
+type RuntimeState struct {
+	Status         Status                 `json:"status,omitempty"`
+	StatusMessage  string                 `json:"status_message,omitempty"`
+	Unavailable    bool                   `json:"unavailable,omitempty"`
+	Quota          QuotaState             `json:"quota"`
+	NextRetryAfter time.Time              `json:"next_retry_after"`
+	LastError      *Error                 `json:"last_error,omitempty"`
+	ModelStates    map[string]*ModelState `json:"model_states,omitempty"`
+	UpdatedAt      time.Time              `json:"updated_at"`
+
+	LastUnavailableReason string    `json:"last_unavailable_reason,omitempty"`
+	LastUnavailableAt     time.Time `json:"last_unavailable_at"`
+
+	// Requests and Tokens carry the account's counters when stats persistence is on.
+	Requests *RequestCounts `json:"requests,omitempty"`
+	Tokens   *TokenUsage    `json:"tokens,omitempty"`
+}
+
+// RuntimeStatePersistence reports whether runtime state is currently reaching its backend.
+type RuntimeStatePersistence struct {
+	Backend string `json:"backend"`
+	// Healthy is false while writes (or the initial load) fail; state is kept in memory meanwhile.
+	Healthy      bool       `json:"healthy"`
+	LastError    string     `json:"last_error,omitempty"`
+	FailingSince *time.Time `json:"failing_since,omitempty"`
+}
+
+type persistenceFailure struct {
+	backend string
+	err     error
+	since   time.Time
+}
+
+// RuntimeStateStore persists runtime state snapshots keyed by auth ID.
+type RuntimeStateStore interface {
+	// Backend returns a short identifier of the storage backend (e.g. "file", "sqlite").
+	Backend() string
+	// LoadRuntimeStates returns every persisted runtime state.
+	LoadRuntimeStates(ctx context.Context) (map[string]RuntimeState, error)
+	// SaveRuntimeStates writes the batch atomically, replacing existing entries with the same ID.
+	SaveRuntimeStates(ctx context.Context, states map[string]RuntimeState) error
+	// Close releases resources held by the store.
+	Close() error
+}
+
+func runtimeStateFromAuth(a *Auth) RuntimeState {
+	state := RuntimeState{
+		Status:         a.Status,
+		StatusMessage:  a.StatusMessage,
+		Unavailable:    a.Unavailable,
+		Quota:          a.Quota,
+		NextRetryAfter: a.NextRetryAfter,
+		LastError:      cloneError(a.LastError),
+		UpdatedAt:      a.UpdatedAt,
+
+		LastUnavailableReason: a.LastUnavailableReason,
+		LastUnavailableAt:     a.LastUnavailableAt,
+	}
+	if len(a.ModelStates) > 0 {
+		state.ModelStates = make(map[string]*ModelState, len(a.ModelStates))
+		for model, ms := range a.ModelStates {
+			state.ModelStates[model] = ms.Clone()
+		}
+	}
+	return state
+}
+
+func (s RuntimeState) applyTo(a *Auth) {
+	a.Status = s.Status
+	a.StatusMessage = s.StatusMessage
+	a.Unavailable = s.Unavailable
+	a.Quota = s.Quota
+	a.NextRetryAfter = s.NextRetryAfter
+	a.LastError = cloneError(s.LastError)
+	a.LastUnavailableReason = s.LastUnavailableReason
+	a.LastUnavailableAt = s.LastUnavailableAt
+	a.ModelStates = nil
+	if len(s.ModelStates) > 0 {
+		a.ModelStates = make(map[string]*ModelState, len(s.ModelStates))
+		for model, ms := range s.ModelStates {
+			a.ModelStates[model] = ms.Clone()
+		}
+	}
+}
+
+// clearExpiredCooldowns drops cooldown timers that elapsed while the process was down so restored
+// accounts do not report stale waits. Backoff levels and last errors are kept.
+func (s RuntimeState) clearExpiredCooldowns(now time.Time) RuntimeState {
+	expired := func(t time.Time) bool { return !t.IsZero() && !t.After(now) }
+	if expired(s.NextRetryAfter) {
+		s.NextRetryAfter = time.Time{}
+		s.Unavailable = false
+	}
+	if expired(s.Quota.NextRecoverAt) {
+		s.Quota.Exceeded = false
+		s.Quota.NextRecoverAt = time.Time{}
+	}
+	for _, ms := range s.ModelStates {
+		if ms == nil {
+			continue
+		}
+		if expired(ms.NextRetryAfter) {
+			ms.NextRetryAfter = time.Time{}
+			ms.Unavailable = false
+		}
+		if expired(ms.Quota.NextRecoverAt) {
+			ms.Quota.Exceeded = false
+			ms.Quota.NextRecoverAt = time.Time{}
+		}
+	}
+	return s
+}
+
+// hasRuntimeState reports whether the auth already carries scheduling state that must not be overwritten.
+func hasRuntimeState(a *Auth) bool {
+	if a.Unavailable || a.LastError != nil || a.Quota.Exceeded || len(a.ModelStates) > 0 || !a.NextRetryAfter.IsZero() {
+		return true
+	}
+	return a.Status != "" && a.Status != StatusActive && a.Status != StatusUnknown
+}
+
+// SetRuntimeStateStore enables runtime state persistence. Persisted states are restored onto auths that
+// arrive without their own state, and changes are flushed in batches every flushInterval. When the
+// backend was marked unavailable, the in-memory state of every auth is written over the store instead.
+// Passing a nil store flushes pending changes, closes the previous store and disables persistence.
+func (m *Manager) SetRuntimeStateStore(ctx context.Context, store RuntimeStateStore, flushInterval time.Duration) error {
+	if m == nil {
+		return nil
+	}
+	m.StopRuntimeStatePersistence(ctx)
+	if store == nil {
+		return nil
+	}
+	states, err := store.LoadRuntimeStates(ctx)
+	if err != nil {
+		return err
+	}
+	if states == nil {
+		states = make(map[string]RuntimeState)
+	}
+	now := time.Now()
+	for id, state := range states {
+		states[id] = state.clearExpiredCooldowns(now)
+	}
+	if flushInterval <= 0 {
+		flushInterval = defaultRuntimeStateFlushInterval
+	}
+
+	m.mu.Lock()
+	// A backend attached after MarkRuntimeStateUnavailable finds requests already served from memory:
+	// memory is newer than anything persisted, so it overwrites the store instead of being restored.
+	reattach := m.stateFailure != nil
+	if m.persistStats && !reattach {
+		m.restoreStatsLocked(states)
+	}
+	m.stateStore = store
+	m.stateFailure = nil
+	m.runtimeStates = states
+	m.stateDirty = make(map[string]struct{})
+	for _, auth := range m.auths {
+		if reattach {
+			m.recordRuntimeStateLocked(auth)
+			continue
+		}
+		m.restoreRuntimeStateLocked(auth)
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	m.stateStop = stop
+	m.stateDone = done
+	m.mu.Unlock()
+
+	go m.runtimeStateFlushLoop(flushInterval, stop, done)
+	return nil
+}
+
+// SetRuntimeStatsPersistence adds the request and token counters of each account to its persisted
+// runtime state, restoring them when the store is attached. Call it before SetRuntimeStateStore.
+func (m *Manager) SetRuntimeStatsPersistence(enabled bool) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.persistStats = enabled
+	m.mu.Unlock()
+}
+
+// restoreStatsLocked seeds the counters of accounts without any from persisted states. Callers must
+// hold m.mu.
+func (m *Manager) restoreStatsLocked(states map[string]RuntimeState) {
+	for id, state := range states {
+		if state.Requests != nil {
+			m.requestCounts.mu.Lock()
+			if m.requestCounts.byAuth == nil {
+				m.requestCounts.byAuth = make(map[string]*RequestCounts)
+			}
+			if _, ok := m.requestCounts.byAuth[id]; !ok {
+				counts := *state.Requests
+				m.requestCounts.byAuth[id] = &counts
+			}
+			m.requestCounts.mu.Unlock()
+		}
+		if state.Tokens != nil {
+			m.tokenCounts.mu.Lock()
+			if m.tokenCounts.byAuth == nil {
+				m.tokenCounts.byAuth = make(map[string]*TokenUsage)
+			}
+			if _, ok := m.tokenCounts.byAuth[id]; !ok {
+				usage := *state.Tokens
+				m.tokenCounts.byAuth[id] = &usage
+			}
+			m.tokenCounts.mu.Unlock()
+		}
+	}
+}
+
+// stageStatsLocked copies counters that changed since the last flush into the runtime states and
+// marks them dirty. Callers must hold m.mu.
+func (m *Manager) stageStatsLocked() {
+	for id, auth := range m.auths {
+		requests, tokens := m.RequestCounts(id), m.TokenUsage(id)
+		state, ok := m.runtimeStates[id]
+		if !ok {
+			if requests == (RequestCounts{}) && tokens == (TokenUsage{}) {
+				continue
+			}
+			state = runtimeStateFromAuth(auth)
+		}
+		if state.Requests != nil && *state.Requests == requests && state.Tokens != nil && *state.Tokens == tokens {
+			continue
+		}
+		state.Requests, state.Tokens = &requests, &tokens
+		m.runtimeStates[id] = state
+		m.stateDirty[id] = struct{}{}
+	}
+}
+
+// RuntimeStateBackend returns the identifier of the active runtime state backend, or "none".
+func (m *Manager) RuntimeStateBackend() string {
+	if m == nil {
+		return "none"
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.stateStore == nil {
+		return "none"
+	}
+	return m.stateStore.Backend()
+}
+
+// MarkRuntimeStateUnavailable records that the configured backend could not be opened or loaded, so
+// runtime state lives in memory only until a later SetRuntimeStateStore succeeds.
+func (m *Manager) MarkRuntimeStateUnavailable(backend string, err error) {
+	if m == nil || err == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stateFailure == nil {
+		m.stateFailure = &persistenceFailure{backend: backend, since: time.Now()}
+	}
+	m.stateFailure.err = err
+}
+
+// RuntimeStatePersistenceHealth reports the runtime state backend and whether persistence is working.
+func (m *Manager) RuntimeStatePersistenceHealth() RuntimeStatePersistence {
+	if m == nil {
+		return RuntimeStatePersistence{Backend: "none", Healthy: true}
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	health := RuntimeStatePersistence{Backend: "none", Healthy: true}
+	if m.stateStore != nil {
+		health.Backend = m.stateStore.Backend()
+	}
+	if failure := m.stateFailure; failure != nil {
+		since := failure.since
+		health.Backend = failure.backend
+		health.Healthy = false
+		health.LastError = failure.err.Error()
+		health.FailingSince = &since
+	}
+	return health
+}
+
+// StopRuntimeStatePersistence flushes pending runtime state and closes the active store.
+func (m *Manager) StopRuntimeStatePersistence(ctx context.Context) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	store := m.stateStore
+	stop, done := m.stateStop, m.stateDone
+	m.stateStop, m.stateDone = nil, nil
+	m.mu.Unlock()
+	if store == nil {
+		return
+	}
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+	m.flushRuntimeStates(ctx)
+	m.mu.Lock()
+	m.stateStore = nil
+	m.stateFailure = nil
+	m.runtimeStates = nil
+	m.stateDirty = nil
+	m.mu.Unlock()
+	if err := store.Close(); err != nil {
+		log.Warnf("failed to close runtime state store: %v", err)
+	}
+}
+
+func (m *Manager) runtimeStateFlushLoop(interval time.Duration, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			m.flushRuntimeStates(context.Background())
+		}
+	}
+}
+
+// flushRuntimeStates writes every dirty state in a single batch; failed batches are retried on the next tick
+// while requests keep being served from in-memory state.
+func (m *Manager) flushRuntimeStates(ctx context.Context) {
+	m.mu.Lock()
+	store := m.stateStore
+	if store != nil && m.persistStats {
+		m.stageStatsLocked()
+	}
+	if store == nil || len(m.stateDirty) == 0 {
+		m.mu.Unlock()
+		return
+	}
+	batch := make(map[string]RuntimeState, len(m.stateDirty))
+	for id := range m.stateDirty {
+		if state, ok := m.runtimeStates[id]; ok {
+			batch[id] = state
+		}
+	}
+	m.stateDirty = make(map[string]struct{})
+	m.mu.Unlock()
+
+	err := store.SaveRuntimeStates(ctx, batch)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err == nil {
+		if m.stateFailure != nil {
+			log.Infof("runtime state persistence recovered (backend=%s) after failing since %s", store.Backend(), m.stateFailure.since.Format(time.RFC3339))
+			m.stateFailure = nil
+		}
+		return
+	}
+	if m.stateFailure == nil {
+		log.Warnf("failed to persist runtime state for %d auths, serving from memory and retrying: %v", len(batch), err)
+		m.stateFailure = &persistenceFailure{backend: store.Backend(), since: time.Now()}
+	} else {
+		log.Debugf("runtime state persistence still failing for %d auths: %v", len(batch), err)
+	}
+	m.stateFailure.err = err
+	if m.stateDirty != nil {
+		for id := range batch {
+			m.stateDirty[id] = struct{}{}
+		}
+	}
+}
+
+// recordRuntimeStateLocked snapshots the auth state for the next flush. Callers must hold m.mu.
+func (m *Manager) recordRuntimeStateLocked(auth *Auth) {
+	if m.stateStore == nil || auth == nil || auth.ID == "" {
+		return
+	}
+	state := runtimeStateFromAuth(auth)
+	if previous, ok := m.runtimeStates[auth.ID]; ok {
+		state.Requests, state.Tokens = previous.Requests, previous.Tokens
+	}
+	m.runtimeStates[auth.ID] = state
+	m.stateDirty[auth.ID] = struct{}{}
+}
+
+// restoreRuntimeStateLocked applies the latest known state to an auth arriving without one. Callers must hold m.mu.
+func (m *Manager) restoreRuntimeStateLocked(auth *Auth) {
+	if m.stateStore == nil || auth == nil || auth.Disabled || hasRuntimeState(auth) {
+		return
+	}
+	if state, ok := m.runtimeStates[auth.ID]; ok {
+		state.applyTo(auth)
+	}
+}
@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryRuntimeStateStore struct {
+	mu      sync.Mutex
+	states  map[string]RuntimeState
+	batches int
+}
+
+func (s *memoryRuntimeStateStore) Backend() string { return "memory" }
+
+func (s *memoryRuntimeStateStore) LoadRuntimeStates(context.Context) (map[string]RuntimeState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]RuntimeState, len(s.states))
+	for id, state := range s.states {
+		out[id] = state
+	}
+	return out, nil
+}
+
+func (s *memoryRuntimeStateStore) SaveRuntimeStates(_ context.Context, states map[string]RuntimeState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.states == nil {
+		s.states = make(map[string]RuntimeState)
+	}
+	for id, state := range states {
+		s.states[id] = state
+	}
+	s.batches++
+	return nil
+}
+
+func (s *memoryRuntimeStateStore) Close() error { return nil }
+
+func TestRuntimeState_BatchedAndRestored(t *testing.T) {
+	ctx := context.Background()
+	backing := &memoryRuntimeStateStore{}
+	m := NewManager(nil, nil, nil)
+	if err := m.SetRuntimeStateStore(ctx, backing, time.Hour); err != nil {
+		t.Fatalf("set store: %v", err)
+	}
+	for _, id := range []string{"a", "b"} {
+		if _, err := m.Register(ctx, &Auth{ID: id, Provider: "claude"}); err != nil {
+			t.Fatalf("register %s: %v", id, err)
+		}
+		m.MarkResult(ctx, Result{AuthID: id, Provider: "claude", Error: &Error{HTTPStatus: 429, Message: "rate limited"}})
+	}
+	m.StopRuntimeStatePersistence(ctx)
+	if backing.batches != 1 || len(backing.states) != 2 {
+		t.Fatalf("expected one batch with 2 states, got %d batches and %d states", backing.batches, len(backing.states))
+	}
+
+	restarted := NewManager(nil, nil, nil)
+	if err := restarted.SetRuntimeStateStore(ctx, backing, time.Hour); err != nil {
+		t.Fatalf("set store: %v", err)
+	}
+	if _, err := restarted.Register(ctx, &Auth{ID: "a", Provider: "claude"}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	auth, _ := restarted.GetByID("a")
+	if !auth.Quota.Exceeded || auth.Status != StatusError {
+		t.Fatalf("expected restored quota state, got status=%s quota=%+v", auth.Status, auth.Quota)
+	}
+	if got := restarted.RuntimeStateBackend(); got != "memory" {
+		t.Fatalf("unexpected backend %q", got)
+	}
+}
+
+type flakyRuntimeStateStore struct {
+	memoryRuntimeStateStore
+	failing bool
+}
+
+func (s *flakyRuntimeStateStore) SaveRuntimeStates(ctx context.Context, states map[string]RuntimeState) error {
+	if s.failing {
+		return errors.New("no space left on device")
+	}
+	return s.memoryRuntimeStateStore.SaveRuntimeStates(ctx, states)
+}
+
+func TestRuntimeState_DegradesAndRecovers(t *testing.T) {
+	ctx := context.Background()
+	backing := &flakyRuntimeStateStore{failing: true}
+	m := NewManager(nil, nil, nil)
+	if err := m.SetRuntimeStateStore(ctx, backing, time.Hour); err != nil {
+		t.Fatalf("set store: %v", err)
+	}
+	if _, err := m.Register(ctx, &Auth{ID: "a", Provider: "claude"}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	m.MarkResult(ctx, Result{AuthID: "a", Provider: "claude", Error: &Error{HTTPStatus: 429, Message: "rate limited"}})
+
+	m.flushRuntimeStates(ctx)
+	health := m.RuntimeStatePersistenceHealth()
+	if health.Healthy || health.Backend != "memory" || health.LastError == "" || health.FailingSince == nil {
+		t.Fatalf("expected degraded persistence, got %+v", health)
+	}
+	if auth, _ := m.GetByID("a"); !auth.Quota.Exceeded {
+		t.Fatalf("in-memory state must keep serving while persistence fails")
+	}
+
+	backing.failing = false
+	m.flushRuntimeStates(ctx)
+	if health = m.RuntimeStatePersistenceHealth(); !health.Healthy {
+		t.Fatalf("expected recovery, got %+v", health)
+	}
+	if _, ok := backing.states["a"]; !ok {
+		t.Fatalf("failed batch must be retried")
+	}
+	m.StopRuntimeStatePersistence(ctx)
+}
+
+func TestRuntimeState_MarkUnavailable(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	if !m.RuntimeStatePersistenceHealth().Healthy {
+		t.Fatalf("no backend configured must report healthy")
+	}
+	m.MarkRuntimeStateUnavailable("sqlite", errors.New("permission denied"))
+	if health := m.RuntimeStatePersistenceHealth(); health.Healthy || health.Backend != "sqlite" {
+		t.Fatalf("expected unavailable sqlite backend, got %+v", health)
+	}
+	if err := m.SetRuntimeStateStore(context.Background(), &memoryRuntimeStateStore{}, time.Hour); err != nil {
+		t.Fatalf("set store: %v", err)
+	}
+	if !m.RuntimeStatePersistenceHealth().Healthy {
+		t.Fatalf("attaching a store must clear the failure")
+	}
+	m.StopRuntimeStatePersistence(context.Background())
+}
+
+func TestRuntimeState_PersistsStats(t *testing.T) {
+	ctx := context.Background()
+	backing := &memoryRuntimeStateStore{}
+	m := NewManager(nil, nil, nil)
+	m.SetRuntimeStatsPersistence(true)
+	if err := m.SetRuntimeStateStore(ctx, backing, time.Hour); err != nil {
+		t.Fatalf("set store: %v", err)
+	}
+	if _, err := m.Register(ctx, &Auth{ID: "a", Provider: "claude"}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	m.MarkResult(ctx, Result{AuthID: "a", Provider: "claude", Success: true})
+	m.tokenCounts.record("a", TokenUsage{PromptTokens: 3, CompletionTokens: 2})
+	m.StopRuntimeStatePersistence(ctx)
+
+	restarted := NewManager(nil, nil, nil)
+	restarted.SetRuntimeStatsPersistence(true)
+	if err := restarted.SetRuntimeStateStore(ctx, backing, time.Hour); err != nil {
+		t.Fatalf("set store: %v", err)
+	}
+	if got := restarted.RequestCounts("a"); got != (RequestCounts{Requests: 1, Successes: 1}) {
+		t.Fatalf("request counts not restored: %+v", got)
+	}
+	if got := restarted.TokenUsage("a"); got != (TokenUsage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5}) {
+		t.Fatalf("token usage not restored: %+v", got)
+	}
+	restarted.StopRuntimeStatePersistence(ctx)
+
+	disabled := NewManager(nil, nil, nil)
+	if err := disabled.SetRuntimeStateStore(ctx, backing, time.Hour); err != nil {
+		t.Fatalf("set store: %v", err)
+	}
+	if got := disabled.RequestCounts("a"); got != (RequestCounts{}) {
+		t.Fatalf("counters restored with stats persistence off: %+v", got)
+	}
+	disabled.StopRuntimeStatePersistence(ctx)
+}
+
+func TestRuntimeState_ClearsExpiredCooldownsOnLoad(t *testing.T) {
+	ctx := context.Background()
+	past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+	backing := &memoryRuntimeStateStore{states: map[string]RuntimeState{
+		"a": {
+			Status: StatusError, Unavailable: true, NextRetryAfter: past,
+			Quota:       QuotaState{Exceeded: true, NextRecoverAt: past, BackoffLevel: 2},
+			ModelStates: map[string]*ModelState{"m": {Unavailable: true, NextRetryAfter: past}, "n": {Unavailable: true, NextRetryAfter: future}},
+		},
+	}}
+	m := NewManager(nil, nil, nil)
+	if err := m.SetRuntimeStateStore(ctx, backing, time.Hour); err != nil {
+		t.Fatalf("set store: %v", err)
+	}
+	if _, err := m.Register(ctx, &Auth{ID: "a", Provider: "claude"}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	auth, _ := m.GetByID("a")
+	if auth.Unavailable || !auth.NextRetryAfter.IsZero() || auth.Quota.Exceeded || !auth.Quota.NextRecoverAt.IsZero() {
+		t.Fatalf("expired auth cooldown kept: %+v", auth)
+	}
+	if auth.Quota.BackoffLevel != 2 {
+		t.Fatalf("backoff level lost: %d", auth.Quota.BackoffLevel)
+	}
+	if auth.ModelStates["m"].Unavailable || !auth.ModelStates["n"].Unavailable {
+		t.Fatalf("unexpected model states: m=%+v n=%+v", auth.ModelStates["m"], auth.ModelStates["n"])
+	}
+	m.StopRuntimeStatePersistence(ctx)
+}
+
+func TestRuntimeState_ReattachKeepsMemoryState(t *testing.T) {
+	ctx := context.Background()
+	future := time.Now().Add(time.Hour)
+	backing := &memoryRuntimeStateStore{states: map[string]RuntimeState{
+		"a": {Status: StatusError, Unavailable: true, NextRetryAfter: future, Requests: &RequestCounts{Requests: 40, Failures: 40}},
+	}}
+	m := NewManager(nil, nil, nil)
+	m.SetRuntimeStatsPersistence(true)
+	m.MarkRuntimeStateUnavailable("memory", errors.New("database is locked"))
+	if _, err := m.Register(ctx, &Auth{ID: "a", Provider: "claude"}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	m.MarkResult(ctx, Result{AuthID: "a", Provider: "claude", Success: true})
+
+	if err := m.SetRuntimeStateStore(ctx, backing, time.Hour); err != nil {
+		t.Fatalf("set store: %v", err)
+	}
+	auth, _ := m.GetByID("a")
+	if auth.Unavailable || !auth.NextRetryAfter.IsZero() || auth.Status == StatusError {
+		t.Fatalf("stale persisted cooldown restored over live state: %+v", auth)
+	}
+	if got := m.RequestCounts("a"); got != (RequestCounts{Requests: 1, Successes: 1}) {
+		t.Fatalf("stale persisted counters restored over live ones: %+v", got)
+	}
+	m.StopRuntimeStatePersistence(ctx)
+	saved := backing.states["a"]
+	if saved.Unavailable || saved.Requests == nil || *saved.Requests != (RequestCounts{Requests: 1, Successes: 1}) {
+		t.Fatalf("changes made while the store was down were not written: %+v", saved)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -102,14 +103,106 @@ func (e *modelCooldownError) Headers() http.Header {
 func (s *RoundRobinSelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
 	_ = ctx
 	_ = opts
-	if len(auths) == 0 {
-		return nil, &Error{Code: "auth_not_found", Message: "no auth candidates"}
+	return s.next(provider, model, auths, true)
+}
+
+// Peek reports the auth Pick would return without advancing the cursor.
+func (s *RoundRobinSelector) Peek(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	_ = ctx
+	_ = opts
+	return s.next(provider, model, auths, false)
+}
+
+func (s *RoundRobinSelector) next(provider, model string, auths []*Auth, advance bool) (*Auth, error) {
+	available, err := availableAuths(provider, model, auths, time.Now())
+	if err != nil {
+		return nil, err
 	}
+	key := provider + ":" + model
+	s.mu.Lock()
 	if s.cursors == nil {
 		s.cursors = make(map[string]int)
 	}
+	index := s.cursors[key]
+
+	if index >= 2_147_483_640 {
+		index = 0
+	}
+
+	if advance {
+		s.cursors[key] = index + 1
+	}
+	s.mu.Unlock()
+	// log.Debugf("available: %d, index: %d, key: %d", len(available), index, index%len(available))
+	return available[index%len(available)], nil
+}
+
+// WeightedRandomSelector picks an available auth at random, proportionally to its configured weight.
+type WeightedRandomSelector struct {
+	rand RandSource
+}
+
+// NewWeightedRandomSelector constructs a weighted random selector.
+// Use WithRandSource to inject a seeded generator for reproducible tests.
+func NewWeightedRandomSelector(opts ...SelectorOption) *WeightedRandomSelector {
+	options := applySelectorOptions(opts)
+	return &WeightedRandomSelector{rand: options.rand}
+}
+
+// Pick selects an available auth where each candidate's chance is weight / total weight.
+func (s *WeightedRandomSelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	_ = ctx
+	_ = opts
+	available, err := availableAuths(provider, model, auths, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	src := s.rand
+	if src == nil {
+		src = globalRandSource{}
+	}
+	total := 0
+	for _, candidate := range available {
+		total += authWeight(candidate)
+	}
+	target := src.IntN(total)
+	for _, candidate := range available {
+		target -= authWeight(candidate)
+		if target < 0 {
+			return candidate, nil
+		}
+	}
+	return available[len(available)-1], nil
+}
+
+// Peek picks like Pick; weighted random selection keeps no rotation state to preserve.
+func (s *WeightedRandomSelector) Peek(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	return s.Pick(ctx, provider, model, opts, auths)
+}
+
+// authWeight returns the selection weight of an auth, defaulting to 1 when unset or invalid.
+func authWeight(a *Auth) int {
+	if a == nil || a.Attributes == nil {
+		return 1
+	}
+	raw := strings.TrimSpace(a.Attributes["weight"])
+	if raw == "" {
+		return 1
+	}
+	weight, err := strconv.Atoi(raw)
+	if err != nil || weight < 1 {
+		return 1
+	}
+	return weight
+}
+
+// availableAuths filters out blocked candidates and returns the remaining auths sorted by ID.
+// When every candidate is cooling down it returns a model cooldown error carrying the earliest reset.
+func availableAuths(provider, model string, auths []*Auth, now time.Time) ([]*Auth, error) {
+	if len(auths) == 0 {
+		return nil, &Error{Code: "auth_not_found", Message: "no auth candidates"}
+	}
 	available := make([]*Auth, 0, len(auths))
-	now := time.Now()
 	cooldownCount := 0
 	var earliest time.Time
 	for i := 0; i < len(auths); i++ {
@@ -136,31 +229,23 @@ func (s *RoundRobinSelector) Pick(ctx context.Context, provider, model string, o
 		}
 		return nil, &Error{Code: "auth_unavailable", Message: "no auth available"}
 	}
-	// Make round-robin deterministic even if caller's candidate order is unstable.
+	// Keep selection deterministic even if caller's candidate order is unstable.
 	if len(available) > 1 {
 		sort.Slice(available, func(i, j int) bool { return available[i].ID < available[j].ID })
 	}
-	key := provider + ":" + model
-	s.mu.Lock()
-	index := s.cursors[key]
-
-	if index >= 2_147_483_640 {
-		index = 0
-	}
-
-	s.cursors[key] = index + 1
-	s.mu.Unlock()
-	// log.Debugf("available: %d, index: %d, key: %d", len(available), index, index%len(available))
-	return available[index%len(available)], nil
+	return available, nil
 }
 
 func isAuthBlockedForModel(auth *Auth, model string, now time.Time) (bool, blockReason, time.Time) {
 	if auth == nil {
 		return true, blockReasonOther, time.Time{}
 	}
-	if auth.Disabled || auth.Status == StatusDisabled {
+	if auth.Disabled || auth.Status == StatusDisabled || auth.Status == StatusBillingSuspended {
 		return true, blockReasonDisabled, time.Time{}
 	}
+	if auth.Status == StatusEgressBlocked && auth.NextRetryAfter.After(now) {
+		return true, blockReasonOther, auth.NextRetryAfter
+	}
 	if model != "" {
 		if len(auth.ModelStates) > 0 {
 			if state, ok := auth.ModelStates[model]; ok && state != nil {
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func weightedAuths() []*Auth {
+	return []*Auth{
+		{ID: "a", Provider: "claude", Attributes: map[string]string{"weight": "1"}},
+		{ID: "b", Provider: "claude", Attributes: map[string]string{"weight": "3"}},
+		{ID: "c", Provider: "claude"},
+	}
+}
+
+func pickSequence(t *testing.T, selector Selector, auths []*Auth, n int) []string {
+	t.Helper()
+	out := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		picked, err := selector.Pick(context.Background(), "claude", "", cliproxyexecutor.Options{}, auths)
+		if err != nil {
+			t.Fatalf("pick %d: unexpected error: %v", i, err)
+		}
+		out = append(out, picked.ID)
+	}
+	return out
+}
+
+func TestWeightedRandomSelector_SeededSequenceIsReproducible(t *testing.T) {
+	first := NewWeightedRandomSelector(WithRandSource(NewSeededRandSource(42)))
+	second := NewWeightedRandomSelector(WithRandSource(NewSeededRandSource(42)))
+
+	seqA := pickSequence(t, first, weightedAuths(), 20)
+	seqB := pickSequence(t, second, weightedAuths(), 20)
+	for i := range seqA {
+		if seqA[i] != seqB[i] {
+			t.Fatalf("sequences diverge at %d: %v vs %v", i, seqA, seqB)
+		}
+	}
+}
+
+type fixedRandSource struct {
+	values []int
+	pos    int
+}
+
+func (f *fixedRandSource) IntN(n int) int {
+	v := f.values[f.pos%len(f.values)] % n
+	f.pos++
+	return v
+}
+
+func (f *fixedRandSource) Float64() float64 { return 0 }
+
+func TestWeightedRandomSelector_RespectsWeights(t *testing.T) {
+	// Total weight is 5: a owns [0], b owns [1,4), c owns [4].
+	src := &fixedRandSource{values: []int{0, 1, 2, 3, 4}}
+	selector := NewWeightedRandomSelector(WithRandSource(src))
+
+	got := pickSequence(t, selector, weightedAuths(), 5)
+	want := []string{"a", "b", "b", "b", "c"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("pick %d: got %s want %s (sequence %v)", i, got[i], want[i], got)
+		}
+	}
+}
+
+func TestWeightedRandomSelector_AllCoolingDown(t *testing.T) {
+	next := time.Now().Add(time.Minute)
+	auths := []*Auth{{
+		ID:             "a",
+		Provider:       "claude",
+		Unavailable:    true,
+		NextRetryAfter: next,
+		Quota:          QuotaState{Exceeded: true, NextRecoverAt: next},
+	}}
+	selector := NewWeightedRandomSelector(WithRandSource(NewSeededRandSource(1)))
+	_, err := selector.Pick(context.Background(), "claude", "", cliproxyexecutor.Options{}, auths)
+	if _, ok := err.(*modelCooldownError); !ok {
+		t.Fatalf("expected model cooldown error, got %v", err)
+	}
+}
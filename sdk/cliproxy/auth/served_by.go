@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"context"
+	"sync"
+)
+
+type servedByKey struct{}
+
+// ServedBy records the provider that produced the successful response of a request.
+type ServedBy struct {
+	mu       sync.Mutex
+	provider string
+}
+
+// WithServedBy attaches a fresh served-by record to ctx.
+func WithServedBy(ctx context.Context) (context.Context, *ServedBy) {
+	served := &ServedBy{}
+	return context.WithValue(ctx, servedByKey{}, served), served
+}
+
+// servedByFrom returns the record attached to ctx, or nil.
+func servedByFrom(ctx context.Context) *ServedBy {
+	if ctx == nil {
+		return nil
+	}
+	served, _ := ctx.Value(servedByKey{}).(*ServedBy)
+	return served
+}
+
+func (s *ServedBy) set(provider string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.provider = provider
+	s.mu.Unlock()
+}
+
+// Provider returns the provider that served the request, or "" before a provider succeeded.
+func (s *ServedBy) Provider() string {
+	if s == nil {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.provider
+}
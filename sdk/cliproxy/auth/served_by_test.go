@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestExecuteProvidersOnceRecordsServingProvider(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	ctx, served := WithServedBy(context.Background())
+	_, err := m.executeProvidersOnce(ctx, []string{"claude", "gemini"}, func(_ context.Context, provider string) (cliproxyexecutor.Response, error) {
+		if provider == "claude" {
+			return cliproxyexecutor.Response{}, errors.New("unavailable")
+		}
+		return cliproxyexecutor.Response{}, nil
+	})
+	if err != nil || served.Provider() != "gemini" {
+		t.Fatalf("served by %q, err %v; want gemini", served.Provider(), err)
+	}
+}
@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// defaultShardVirtualNodes is the number of ring points per shard when the policy leaves it unset.
+const defaultShardVirtualNodes = 128
+
+// ShardPolicy splits the account pool across instances with consistent hashing. Each instance owns the
+// accounts hashed to its shard and only selects and refreshes those. A load balancer in front spreads
+// requests across instances by a shard key, such as the client key; ShardForKey maps keys the same way.
+type ShardPolicy struct {
+	Enabled bool
+	// Count is the number of instances sharing the pool; Index is this instance, from 0 to Count-1.
+	Count int
+	Index int
+	// VirtualNodes is the number of ring points per shard; zero means defaultShardVirtualNodes.
+	VirtualNodes int
+}
+
+// ShardAssignment reports this instance's share of the pool.
+type ShardAssignment struct {
+	Enabled       bool `json:"enabled"`
+	Index         int  `json:"index"`
+	Count         int  `json:"count"`
+	OwnedAccounts int  `json:"owned_accounts"`
+	TotalAccounts int  `json:"total_accounts"`
+}
+
+// shardRing is a consistent-hash ring; adding or removing a shard only moves the accounts of the
+// neighbouring ring segments.
+type shardRing struct {
+	points []uint64
+	owners []int
+}
+
+func shardHash(key string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	// fnv clusters similar keys; a final avalanche spreads them around the ring.
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	return x
+}
+
+func newShardRing(count, virtualNodes int) *shardRing {
+	if virtualNodes <= 0 {
+		virtualNodes = defaultShardVirtualNodes
+	}
+	type point struct {
+		hash  uint64
+		owner int
+	}
+	points := make([]point, 0, count*virtualNodes)
+	for shard := 0; shard < count; shard++ {
+		for v := 0; v < virtualNodes; v++ {
+			points = append(points, point{hash: shardHash("shard-" + strconv.Itoa(shard) + "#" + strconv.Itoa(v)), owner: shard})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].hash < points[j].hash })
+	ring := &shardRing{points: make([]uint64, len(points)), owners: make([]int, len(points))}
+	for i, p := range points {
+		ring.points[i], ring.owners[i] = p.hash, p.owner
+	}
+	return ring
+}
+
+// owner returns the shard of the first ring point at or after the key's hash.
+func (r *shardRing) owner(key string) int {
+	hash := shardHash(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= hash })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[i]
+}
+
+// accountShards holds the shard ring and the accounts each provider selects from. Guarded by
+// Manager.mu.
+type accountShards struct {
+	policy ShardPolicy
+	// ring is nil when sharding is off.
+	ring *shardRing
+	// pools lists every account per provider, or only the owned ones when sharding is on, so
+	// selection scans a provider's share instead of the whole pool.
+	pools map[string][]*Auth
+	owned int
+}
+
+// SetShardPolicy replaces the sharding policy and recomputes which accounts this instance owns.
+func (m *Manager) SetShardPolicy(policy ShardPolicy) error {
+	if m == nil {
+		return nil
+	}
+	if policy.Enabled && (policy.Count < 1 || policy.Index < 0 || policy.Index >= policy.Count) {
+		return &Error{Code: "invalid_shard", Message: fmt.Sprintf("shard index %d out of range for %d shards", policy.Index, policy.Count)}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if policy == m.shards.policy {
+		return nil
+	}
+	m.shards.policy = policy
+	m.shards.ring = nil
+	if policy.Enabled {
+		m.shards.ring = newShardRing(policy.Count, policy.VirtualNodes)
+	}
+	m.reindexShardsLocked()
+	return nil
+}
+
+// reindexShardsLocked rebuilds the per-provider selection pools. Callers must hold m.mu and call it
+// whenever m.auths gains or replaces an entry.
+func (m *Manager) reindexShardsLocked() {
+	pools := make(map[string][]*Auth)
+	owned := 0
+	for _, a := range m.auths {
+		if a == nil || !m.shards.ownsLocked(a.ID) {
+			continue
+		}
+		pools[a.Provider] = append(pools[a.Provider], a)
+		owned++
+	}
+	m.shards.pools = pools
+	m.shards.owned = owned
+}
+
+// ownsLocked reports whether the account belongs to this instance's shard.
+func (s *accountShards) ownsLocked(id string) bool {
+	return s.ring == nil || s.ring.owner(id) == s.policy.Index
+}
+
+// providerPoolLocked returns the accounts of provider this instance selects from.
+func (m *Manager) providerPoolLocked(provider string) []*Auth {
+	return m.shards.pools[provider]
+}
+
+// OwnsAccount reports whether this instance's shard owns the account; always true without sharding.
+func (m *Manager) OwnsAccount(id string) bool {
+	if m == nil {
+		return false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.shards.ownsLocked(id)
+}
+
+// AccountShard returns the shard owning the account, or false when sharding is off.
+func (m *Manager) AccountShard(id string) (int, bool) {
+	if m == nil {
+		return 0, false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.shards.ring == nil {
+		return 0, false
+	}
+	return m.shards.ring.owner(id), true
+}
+
+// ShardForKey returns the shard a request shard key maps to, or false when sharding is off. Routing
+// layers use it to send a key to the instance that owns its shard.
+func (m *Manager) ShardForKey(key string) (int, bool) {
+	return m.AccountShard(key)
+}
+
+// ShardAssignment reports this instance's shard and how many accounts it owns.
+func (m *Manager) ShardAssignment() ShardAssignment {
+	if m == nil {
+		return ShardAssignment{}
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := ShardAssignment{OwnedAccounts: m.shards.owned, TotalAccounts: len(m.auths), Count: 1}
+	if m.shards.ring != nil {
+		out.Enabled = true
+		out.Index = m.shards.policy.Index
+		out.Count = m.shards.policy.Count
+	}
+	return out
+}
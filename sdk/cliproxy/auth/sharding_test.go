@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+type listStore struct{ auths []*Auth }
+
+func (s *listStore) List(context.Context) ([]*Auth, error)       { return s.auths, nil }
+func (s *listStore) Save(context.Context, *Auth) (string, error) { return "", nil }
+func (s *listStore) Delete(context.Context, string) error        { return nil }
+
+// shardedPool loads size accounts spread over four providers, one of them "eager".
+func shardedPool(tb testing.TB, size int, policy ShardPolicy) *Manager {
+	tb.Helper()
+	store := &listStore{}
+	providers := []string{"eager", "p1", "p2", "p3"}
+	for i := 0; i < size; i++ {
+		store.auths = append(store.auths, &Auth{ID: fmt.Sprintf("account-%05d.json", i), Provider: providers[i%len(providers)], Status: StatusActive,
+			Metadata: map[string]any{"access_token": fmt.Sprintf("at-%d", i)}})
+	}
+	m := NewManager(store, nil, nil)
+	m.RegisterExecutor(&slowRefreshExecutor{})
+	if err := m.SetShardPolicy(policy); err != nil {
+		tb.Fatalf("set shard policy: %v", err)
+	}
+	if err := m.Load(context.Background()); err != nil {
+		tb.Fatalf("load: %v", err)
+	}
+	return m
+}
+
+func TestShardRingBalancedAndStable(t *testing.T) {
+	four, five := newShardRing(4, 0), newShardRing(5, 0)
+	counts := make([]int, 4)
+	moved := 0
+	const keys = 20000
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("account-%d.json", i)
+		owner := four.owner(key)
+		counts[owner]++
+		if next := five.owner(key); next != owner && next != 4 {
+			moved++
+		}
+	}
+	for shard, n := range counts {
+		if n < keys/4*8/10 || n > keys/4*12/10 {
+			t.Fatalf("shard %d owns %d of %d keys: %v", shard, n, keys, counts)
+		}
+	}
+	if moved != 0 {
+		t.Fatalf("adding a shard moved %d keys between existing shards", moved)
+	}
+}
+
+func TestShardedManagerSelectsOwnedAccounts(t *testing.T) {
+	total := 0
+	for index := 0; index < 3; index++ {
+		m := shardedPool(t, 300, ShardPolicy{Enabled: true, Count: 3, Index: index})
+		assignment := m.ShardAssignment()
+		if !assignment.Enabled || assignment.Index != index || assignment.TotalAccounts != 300 {
+			t.Fatalf("unexpected assignment: %+v", assignment)
+		}
+		total += assignment.OwnedAccounts
+		for i := 0; i < 20; i++ {
+			selected, _, err := m.pickNext(context.Background(), "eager", "", cliproxyexecutor.Options{}, nil)
+			if err != nil {
+				t.Fatalf("pick: %v", err)
+			}
+			if shard, ok := m.AccountShard(selected.ID); !ok || shard != index || !m.OwnsAccount(selected.ID) {
+				t.Fatalf("shard %d selected %s owned by %d", index, selected.ID, shard)
+			}
+		}
+	}
+	if total != 300 {
+		t.Fatalf("shards own %d accounts, want every account exactly once", total)
+	}
+
+	if err := shardedPool(t, 4, ShardPolicy{}).SetShardPolicy(ShardPolicy{Enabled: true, Count: 2, Index: 2}); err == nil {
+		t.Fatal("out of range index must be rejected")
+	}
+}
+
+func benchmarkPickNext(b *testing.B, policy ShardPolicy) {
+	m := shardedPool(b, 8000, policy)
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := m.pickNext(ctx, "eager", "", cliproxyexecutor.Options{}, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPickNextUnsharded(b *testing.B) { benchmarkPickNext(b, ShardPolicy{}) }
+
+func BenchmarkPickNextSharded8(b *testing.B) {
+	benchmarkPickNext(b, ShardPolicy{Enabled: true, Count: 8, Index: 0})
+}
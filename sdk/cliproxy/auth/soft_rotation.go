@@ -0,0 +1,50 @@
+package auth
+
+import "time"
+
+// SoftRotationPolicy rests an account after it has served a number of requests, even while healthy,
+// so traffic spreads across the pool instead of concentrating on a single account.
+type SoftRotationPolicy struct {
+	Enabled bool
+	// Requests is the number of selections after which the account rests.
+	Requests int
+	// Rest is how long a rotated account is deprioritized.
+	Rest time.Duration
+}
+
+// SetSoftRotationPolicy replaces the soft rotation policy applied by account selection.
+func (m *Manager) SetSoftRotationPolicy(policy SoftRotationPolicy) {
+	if m == nil {
+		return
+	}
+	if policy.Requests <= 0 || policy.Rest <= 0 {
+		policy.Enabled = false
+	}
+	m.mu.Lock()
+	m.softRotation = policy
+	if !policy.Enabled {
+		for _, auth := range m.auths {
+			auth.ServedSinceRotation = 0
+			auth.RotationRestUntil = time.Time{}
+		}
+	}
+	m.mu.Unlock()
+}
+
+// isResting reports whether the auth is inside its soft rotation rest window.
+func (a *Auth) isResting(now time.Time) bool {
+	return !a.RotationRestUntil.IsZero() && now.Before(a.RotationRestUntil)
+}
+
+// recordSoftRotationLocked counts a selection of auth and starts its rest window once the
+// configured request count is reached. Callers must hold m.mu.
+func (m *Manager) recordSoftRotationLocked(auth *Auth, now time.Time) {
+	if !m.softRotation.Enabled || auth == nil {
+		return
+	}
+	auth.ServedSinceRotation++
+	if auth.ServedSinceRotation >= m.softRotation.Requests {
+		auth.ServedSinceRotation = 0
+		auth.RotationRestUntil = now.Add(m.softRotation.Rest)
+	}
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestSoftRotationRestsAfterRequests(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetSoftRotationPolicy(SoftRotationPolicy{Enabled: true, Requests: 2, Rest: time.Minute})
+	auth := &Auth{ID: "a"}
+	now := time.Now()
+
+	m.recordSoftRotationLocked(auth, now)
+	if auth.ServedSinceRotation != 1 || auth.isResting(now) {
+		t.Fatalf("after one request: served=%d resting=%t", auth.ServedSinceRotation, auth.isResting(now))
+	}
+	m.recordSoftRotationLocked(auth, now)
+	if auth.ServedSinceRotation != 0 || !auth.isResting(now) {
+		t.Fatalf("after rotation: served=%d resting=%t", auth.ServedSinceRotation, auth.isResting(now))
+	}
+	if auth.isResting(now.Add(time.Minute)) {
+		t.Fatal("auth still resting after rest window")
+	}
+}
+
+func TestSoftRotationDisabledWithoutLimits(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetSoftRotationPolicy(SoftRotationPolicy{Enabled: true})
+	auth := &Auth{ID: "a"}
+	m.recordSoftRotationLocked(auth, time.Now())
+	if auth.ServedSinceRotation != 0 {
+		t.Fatalf("served = %d, want 0 when policy has no limits", auth.ServedSinceRotation)
+	}
+}
+
+func TestSelectionFallsBackToRestingWhenOthersCoolDown(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&streamingExecutor{})
+	m.SetSoftRotationPolicy(SoftRotationPolicy{Enabled: true, Requests: 10, Rest: time.Minute})
+	now := time.Now()
+	for _, auth := range []*Auth{
+		{ID: "resting", Provider: "streamy", Status: StatusActive, RotationRestUntil: now.Add(time.Minute)},
+		{ID: "cooling", Provider: "streamy", Status: StatusActive, Unavailable: true, NextRetryAfter: now.Add(time.Minute), Quota: QuotaState{Exceeded: true}},
+	} {
+		if _, err := m.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	picked, _, err := m.pickNext(context.Background(), "streamy", "", cliproxyexecutor.Options{}, nil)
+	if err != nil || picked.ID != "resting" {
+		t.Fatalf("pick = %v, %v; want the resting account while the other cools down", picked, err)
+	}
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SetRefreshStartupStagger spreads the token refreshes due when auto refresh first starts over window,
+// so a large pool does not hit provider token endpoints all at once. Zero or negative disables it.
+func (m *Manager) SetRefreshStartupStagger(window time.Duration) {
+	if m == nil {
+		return
+	}
+	if window < 0 {
+		window = 0
+	}
+	m.refreshes.startupStagger.Store(int64(window))
+}
+
+// staggerInitialRefreshes assigns every auth due for a refresh a slot within the startup window by
+// deferring its NextRefreshAfter. Slots are evenly spaced with random jitter inside each slot. It runs
+// once per manager; later auto refresh restarts refresh immediately.
+func (m *Manager) staggerInitialRefreshes(now time.Time) {
+	window := time.Duration(m.refreshes.startupStagger.Load())
+	if window <= 0 || !m.refreshes.staggered.CompareAndSwap(false, true) {
+		return
+	}
+	due := make([]*Auth, 0)
+	for _, a := range m.snapshotAuths() {
+		if typ, _ := a.AccountInfo(); typ == "api_key" || m.executorFor(a.Provider) == nil {
+			continue
+		}
+		if m.shouldRefresh(a, now) {
+			due = append(due, a)
+		}
+	}
+	if len(due) < 2 {
+		return
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].ID < due[j].ID })
+	rand.Shuffle(len(due), func(i, j int) { due[i], due[j] = due[j], due[i] })
+
+	slot := window / time.Duration(len(due))
+	perProvider := make(map[string]int)
+	m.mu.Lock()
+	for i, a := range due {
+		current, ok := m.auths[a.ID]
+		if !ok {
+			continue
+		}
+		at := now.Add(time.Duration(i)*slot + time.Duration(rand.Int64N(int64(slot)+1)))
+		if at.After(current.NextRefreshAfter) {
+			current.NextRefreshAfter = at
+		}
+		perProvider[current.Provider]++
+		log.Debugf("startup stagger: refresh of %s (%s) scheduled in %s", current.ID, current.Provider, at.Sub(now).Round(time.Second))
+	}
+	m.mu.Unlock()
+
+	providers := make([]string, 0, len(perProvider))
+	for provider, n := range perProvider {
+		providers = append(providers, fmt.Sprintf("%s=%d", provider, n))
+	}
+	sort.Strings(providers)
+	log.Infof("staggering %d initial token refreshes over %s (%s)", len(due), window, strings.Join(providers, ", "))
+}
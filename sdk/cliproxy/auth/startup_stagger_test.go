@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// dueRefresh reports every auth as due for a refresh.
+type dueRefresh struct{}
+
+func (dueRefresh) ShouldRefresh(time.Time, *Auth) bool { return true }
+
+func TestStaggerInitialRefreshesSpreadsDueAuths(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&slowRefreshExecutor{})
+	for i := 0; i < 10; i++ {
+		if _, err := m.Register(context.Background(), &Auth{ID: fmt.Sprintf("a%d", i), Provider: "eager", Status: StatusActive, Runtime: dueRefresh{}, Metadata: map[string]any{}}); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	window := 100 * time.Second
+	m.SetRefreshStartupStagger(window)
+	now := time.Now()
+	m.staggerInitialRefreshes(now)
+
+	slots := make(map[time.Duration]bool)
+	for _, a := range m.snapshotAuths() {
+		offset := a.NextRefreshAfter.Sub(now)
+		if offset < 0 || offset > window {
+			t.Fatalf("%s scheduled outside the window: %s", a.ID, offset)
+		}
+		slots[offset/(window/10)] = true
+		if m.shouldRefresh(a, now) {
+			t.Fatalf("%s should wait for its slot", a.ID)
+		}
+	}
+	if len(slots) < 9 {
+		t.Fatalf("refreshes should occupy distinct slots, got %d", len(slots))
+	}
+
+	// The stagger applies once; a second call leaves the schedule alone.
+	before := m.snapshotAuths()[0]
+	m.staggerInitialRefreshes(now.Add(time.Hour))
+	if after, _ := m.GetByID(before.ID); !after.NextRefreshAfter.Equal(before.NextRefreshAfter) {
+		t.Fatalf("second stagger must be a no-op")
+	}
+}
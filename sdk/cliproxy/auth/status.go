@@ -16,4 +16,8 @@ const (
 	StatusError Status = "error"
 	// StatusDisabled marks the auth as intentionally disabled.
 	StatusDisabled Status = "disabled"
+	// StatusBillingSuspended marks the auth as blocked by the upstream billing system until an operator intervenes.
+	StatusBillingSuspended Status = "billing_suspended"
+	// StatusEgressBlocked marks the auth as refused because of the egress IP or region, not its credentials.
+	StatusEgressBlocked Status = "egress_blocked"
 )
@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// selectionHistorySize bounds how many recent first-attempt selections are kept for strategy previews.
+const selectionHistorySize = 1000
+
+// Selection strategies understood by StrategyPreview.
+const (
+	StrategyRoundRobin     = "round-robin"
+	StrategyWeightedRandom = "weighted-random"
+)
+
+// supportedStrategies lists the strategies selectorForStrategy can build.
+var supportedStrategies = []string{StrategyRoundRobin, StrategyWeightedRandom, StrategyFamilyBalanced, StrategyLeastRecentlyUsed, StrategyLeastLoaded}
+
+// SupportedStrategies returns the names accepted by SetSelectionStrategy.
+func SupportedStrategies() []string {
+	return append([]string(nil), supportedStrategies...)
+}
+
+// normalizeStrategy lowercases a strategy name and accepts underscores in place of hyphens.
+func normalizeStrategy(strategy string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(strategy)), "_", "-")
+}
+
+// StrategyPreviewAccount compares the recorded and projected load of one account.
+type StrategyPreviewAccount struct {
+	ID       string `json:"id"`
+	Provider string `json:"provider"`
+	Label    string `json:"label,omitempty"`
+	// Current and CurrentShare describe what the active strategy actually selected.
+	Current      int     `json:"current"`
+	CurrentShare float64 `json:"current_share"`
+	// Projected and ProjectedShare describe what the proposed strategy would have selected.
+	// Shares are relative to the account's provider.
+	Projected      int     `json:"projected"`
+	ProjectedShare float64 `json:"projected_share"`
+}
+
+// StrategyPreview is the dry-run result of replaying recent selections with another strategy.
+type StrategyPreview struct {
+	Current  string `json:"current"`
+	Proposed string `json:"proposed"`
+	Requests int    `json:"requests"`
+	// Unserved counts replayed requests the proposed strategy could not place on any current account.
+	Unserved int                      `json:"unserved"`
+	Accounts []StrategyPreviewAccount `json:"accounts"`
+}
+
+type selectionRecord struct {
+	provider string
+	model    string
+	authID   string
+}
+
+// selectionHistory is a fixed-size ring of recent first-attempt selections.
+type selectionHistory struct {
+	mu      sync.Mutex
+	records []selectionRecord
+	next    int
+}
+
+func (h *selectionHistory) record(provider, model, authID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	rec := selectionRecord{provider: provider, model: model, authID: authID}
+	if len(h.records) < selectionHistorySize {
+		h.records = append(h.records, rec)
+		return
+	}
+	h.records[h.next] = rec
+	h.next = (h.next + 1) % selectionHistorySize
+}
+
+// snapshot returns the recorded selections oldest first.
+func (h *selectionHistory) snapshot() []selectionRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]selectionRecord, 0, len(h.records))
+	out = append(out, h.records[h.next:]...)
+	return append(out, h.records[:h.next]...)
+}
+
+// selectorForStrategy returns a fresh selector implementing the named strategy. inFlight feeds the
+// least-loaded strategy; nil treats every account as idle.
+func selectorForStrategy(strategy string, inFlight func(authID string) int) (Selector, bool) {
+	switch normalizeStrategy(strategy) {
+	case StrategyRoundRobin:
+		return &RoundRobinSelector{}, true
+	case StrategyWeightedRandom:
+		return NewWeightedRandomSelector(), true
+	case StrategyFamilyBalanced:
+		return &FamilyBalancedSelector{}, true
+	case StrategyLeastRecentlyUsed:
+		return &LeastRecentlyUsedSelector{}, true
+	case StrategyLeastLoaded:
+		return NewLeastLoadedSelector(inFlight), true
+	default:
+		return nil, false
+	}
+}
+
+// strategyName reports the strategy implemented by selector, or "custom" for unknown selectors.
+func strategyName(selector Selector) string {
+	switch selector.(type) {
+	case *RoundRobinSelector:
+		return StrategyRoundRobin
+	case *WeightedRandomSelector:
+		return StrategyWeightedRandom
+	case *FamilyBalancedSelector:
+		return StrategyFamilyBalanced
+	case *LeastRecentlyUsedSelector:
+		return StrategyLeastRecentlyUsed
+	case *LeastLoadedSelector:
+		return StrategyLeastLoaded
+	default:
+		return "custom"
+	}
+}
+
+// StrategyPreview replays the recent selection history against the current pool using the proposed
+// strategy and returns the projected per-account load next to the recorded one. The active selector
+// is left untouched.
+func (m *Manager) StrategyPreview(strategy string) (*StrategyPreview, error) {
+	if m == nil {
+		return nil, &Error{Code: "auth_manager_unavailable", Message: "auth manager not available"}
+	}
+	// Replayed requests carry no load, so least-loaded previews as rotation among idle accounts.
+	selector, ok := selectorForStrategy(strategy, nil)
+	if !ok {
+		return nil, &Error{Code: "unknown_strategy", Message: "unknown strategy " + strategy + "; supported: " + strings.Join(supportedStrategies, ", ")}
+	}
+	history := m.history.snapshot()
+
+	m.mu.RLock()
+	current := strategyName(m.selector)
+	byProvider := make(map[string][]*Auth)
+	accounts := make(map[string]*StrategyPreviewAccount)
+	now := time.Now()
+	for _, a := range m.auths {
+		if a.Disabled || m.peakReserve.holdsBack(a, now) {
+			continue
+		}
+		clone := a.Clone()
+		byProvider[a.Provider] = append(byProvider[a.Provider], clone)
+		accounts[a.ID] = &StrategyPreviewAccount{ID: a.ID, Provider: a.Provider, Label: a.Label}
+	}
+	m.mu.RUnlock()
+
+	preview := &StrategyPreview{Current: current, Proposed: normalizeStrategy(strategy), Requests: len(history)}
+	registryRef := registry.GetGlobalRegistry()
+	totals := make(map[string]int)
+	for _, rec := range history {
+		totals[rec.provider]++
+		if acc, ok := accounts[rec.authID]; ok {
+			acc.Current++
+		}
+		candidates := make([]*Auth, 0, len(byProvider[rec.provider]))
+		for _, candidate := range byProvider[rec.provider] {
+			if rec.model != "" && registryRef != nil && !registryRef.ClientSupportsModel(candidate.ID, rec.model) {
+				continue
+			}
+			candidates = append(candidates, candidate)
+		}
+		picked, errPick := selector.Pick(context.Background(), rec.provider, rec.model, cliproxyexecutor.Options{}, candidates)
+		if errPick != nil || picked == nil {
+			preview.Unserved++
+			continue
+		}
+		accounts[picked.ID].Projected++
+	}
+
+	preview.Accounts = make([]StrategyPreviewAccount, 0, len(accounts))
+	for _, acc := range accounts {
+		if total := totals[acc.Provider]; total > 0 {
+			acc.CurrentShare = float64(acc.Current) / float64(total)
+			acc.ProjectedShare = float64(acc.Projected) / float64(total)
+		}
+		preview.Accounts = append(preview.Accounts, *acc)
+	}
+	sort.Slice(preview.Accounts, func(i, j int) bool {
+		if preview.Accounts[i].Provider != preview.Accounts[j].Provider {
+			return preview.Accounts[i].Provider < preview.Accounts[j].Provider
+		}
+		return preview.Accounts[i].ID < preview.Accounts[j].ID
+	})
+	return preview, nil
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestStrategyPreviewReplaysRecentSelections(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&batchingExecutor{})
+	for _, auth := range []*Auth{
+		{ID: "a", Provider: "batchy", Status: StatusActive, Attributes: map[string]string{"weight": "1000"}},
+		{ID: "b", Provider: "batchy", Status: StatusActive},
+	} {
+		if _, err := m.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	for i := 0; i < 4; i++ {
+		if _, err := m.Execute(context.Background(), []string{"batchy"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{}); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	}
+
+	if _, err := m.StrategyPreview("least-used"); err == nil {
+		t.Fatalf("expected unknown strategy to be rejected")
+	}
+	preview, err := m.StrategyPreview("weighted-random")
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if preview.Current != StrategyRoundRobin || preview.Requests != 4 || preview.Unserved != 0 || len(preview.Accounts) != 2 {
+		t.Fatalf("unexpected preview %+v", preview)
+	}
+	projected := 0
+	for _, acc := range preview.Accounts {
+		if acc.Current != 2 || acc.CurrentShare != 0.5 {
+			t.Fatalf("expected round-robin to have split load evenly, got %+v", acc)
+		}
+		projected += acc.Projected
+	}
+	if projected != 4 {
+		t.Fatalf("expected every replayed request to be projected, got %d", projected)
+	}
+
+	// The preview must not switch the active selector.
+	if _, ok := m.selector.(*RoundRobinSelector); !ok {
+		t.Fatalf("preview changed the active selector")
+	}
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// SetStreamRetryBeforeFirstByte toggles failover for streams that fail before their first
+// payload reached the client. Streams that already delivered bytes are never retried.
+func (m *Manager) SetStreamRetryBeforeFirstByte(enabled bool) {
+	if m == nil {
+		return
+	}
+	m.streamRetryDisabled.Store(!enabled)
+}
+
+// awaitFirstStreamChunk reads the stream until it yields a payload or an error, or closes.
+// Chunks read are returned as prefix so they can be replayed. failure is set when the stream
+// errored or ctx ended before any payload was delivered.
+func awaitFirstStreamChunk(ctx context.Context, chunks <-chan cliproxyexecutor.StreamChunk) (prefix []cliproxyexecutor.StreamChunk, failure error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return prefix, ctx.Err()
+		case chunk, ok := <-chunks:
+			if !ok {
+				return prefix, nil
+			}
+			if chunk.Err != nil && len(chunk.Payload) == 0 {
+				return prefix, chunk.Err
+			}
+			prefix = append(prefix, chunk)
+			if len(chunk.Payload) > 0 || chunk.Err != nil {
+				return prefix, nil
+			}
+		}
+	}
+}
+
+// drainStream discards the rest of an abandoned stream so its producer can exit.
+func drainStream(chunks <-chan cliproxyexecutor.StreamChunk) {
+	go func() {
+		for range chunks {
+		}
+	}()
+}
+
+// resultErrorFrom converts an execution failure into a result error.
+func resultErrorFrom(err error) *Error {
+	rerr := &Error{Message: err.Error()}
+	var se cliproxyexecutor.StatusError
+	if errors.As(err, &se) && se != nil {
+		rerr.HTTPStatus = se.StatusCode()
+	}
+	return rerr
+}
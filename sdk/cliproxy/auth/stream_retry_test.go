@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// streamingExecutor streams per-auth scripted chunks.
+type streamingExecutor struct {
+	mu      sync.Mutex
+	scripts map[string][]cliproxyexecutor.StreamChunk
+	calls   []string
+}
+
+func (e *streamingExecutor) Identifier() string { return "streamy" }
+
+func (e *streamingExecutor) Execute(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{}, errors.New("not supported")
+}
+
+func (e *streamingExecutor) ExecuteStream(_ context.Context, auth *Auth, _ cliproxyexecutor.Request, _ cliproxyexecutor.Options) (<-chan cliproxyexecutor.StreamChunk, error) {
+	e.mu.Lock()
+	e.calls = append(e.calls, auth.ID)
+	script := e.scripts[auth.ID]
+	e.mu.Unlock()
+	out := make(chan cliproxyexecutor.StreamChunk, len(script))
+	for _, chunk := range script {
+		out <- chunk
+	}
+	close(out)
+	return out, nil
+}
+
+func (e *streamingExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) { return auth, nil }
+
+func (e *streamingExecutor) CountTokens(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{}, nil
+}
+
+func collectStream(t *testing.T, m *Manager) (payload string, streamErr error) {
+	t.Helper()
+	chunks, err := m.ExecuteStream(context.Background(), []string{"streamy"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{Stream: true})
+	if err != nil {
+		return "", err
+	}
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			streamErr = chunk.Err
+			continue
+		}
+		payload += string(chunk.Payload)
+	}
+	return payload, streamErr
+}
+
+func TestExecuteStreamRetriesOnlyBeforeFirstByte(t *testing.T) {
+	boom := &Error{HTTPStatus: 500, Message: "boom"}
+	exec := &streamingExecutor{scripts: map[string][]cliproxyexecutor.StreamChunk{
+		"a": {{Err: boom}},
+		"b": {{Payload: []byte("hello")}, {Err: boom}},
+	}}
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(exec)
+	m.SetStreamRetryBeforeFirstByte(true)
+	for _, id := range []string{"a", "b"} {
+		if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: "streamy"}); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+
+	payload, streamErr := collectStream(t, m)
+	if payload != "hello" || streamErr == nil {
+		t.Fatalf("expected truncated stream from b, got payload=%q err=%v", payload, streamErr)
+	}
+	if len(exec.calls) != 2 || exec.calls[0] != "a" || exec.calls[1] != "b" {
+		t.Fatalf("expected failover from a to b only, got %v", exec.calls)
+	}
+
+	// With failover disabled the pre-first-byte failure reaches the client.
+	exec.calls = nil
+	m.SetStreamRetryBeforeFirstByte(false)
+	m.MarkResult(context.Background(), Result{AuthID: "a", Provider: "streamy", Success: true})
+	m.MarkResult(context.Background(), Result{AuthID: "b", Provider: "streamy", Success: true})
+	if _, errStream := collectStream(t, m); errStream == nil || len(exec.calls) != 1 {
+		t.Fatalf("expected no failover when disabled, calls=%v err=%v", exec.calls, errStream)
+	}
+}
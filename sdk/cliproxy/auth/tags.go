@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"sort"
+	"strings"
+)
+
+// tagsMetadataKey is the metadata key mirroring Auth.Tags so file-backed accounts keep their tags across reloads.
+const tagsMetadataKey = "tags"
+
+// NormalizeTags trims, lowercases, deduplicates and sorts tag names, dropping empty entries.
+func NormalizeTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, raw := range tags {
+		tag := strings.ToLower(strings.TrimSpace(raw))
+		if tag == "" {
+			continue
+		}
+		if _, exists := seen[tag]; exists {
+			continue
+		}
+		seen[tag] = struct{}{}
+		out = append(out, tag)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	sort.Strings(out)
+	return out
+}
+
+// HasTag reports whether the auth carries the given tag.
+func (a *Auth) HasTag(tag string) bool {
+	if a == nil {
+		return false
+	}
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	for _, existing := range a.Tags {
+		if existing == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTags merges tags into the auth and reports whether anything changed.
+func (a *Auth) AddTags(tags ...string) bool {
+	if a == nil {
+		return false
+	}
+	merged := NormalizeTags(append(append([]string(nil), a.Tags...), tags...))
+	return a.setTags(merged)
+}
+
+// RemoveTags drops tags from the auth and reports whether anything changed.
+func (a *Auth) RemoveTags(tags ...string) bool {
+	if a == nil || len(a.Tags) == 0 {
+		return false
+	}
+	drop := make(map[string]struct{}, len(tags))
+	for _, tag := range NormalizeTags(tags) {
+		drop[tag] = struct{}{}
+	}
+	kept := make([]string, 0, len(a.Tags))
+	for _, tag := range a.Tags {
+		if _, remove := drop[tag]; !remove {
+			kept = append(kept, tag)
+		}
+	}
+	return a.setTags(NormalizeTags(kept))
+}
+
+func (a *Auth) setTags(tags []string) bool {
+	if equalStringSlices(a.Tags, tags) {
+		return false
+	}
+	a.Tags = tags
+	if len(tags) == 0 {
+		delete(a.Metadata, tagsMetadataKey)
+		return true
+	}
+	if a.Metadata == nil {
+		a.Metadata = make(map[string]any)
+	}
+	a.Metadata[tagsMetadataKey] = append([]string(nil), tags...)
+	return true
+}
+
+// syncTagsFromMetadata hydrates Auth.Tags from persisted metadata when the field is unset.
+func syncTagsFromMetadata(a *Auth) {
+	if a == nil || len(a.Tags) > 0 || a.Metadata == nil {
+		return
+	}
+	switch raw := a.Metadata[tagsMetadataKey].(type) {
+	case []string:
+		a.Tags = NormalizeTags(raw)
+	case []any:
+		tags := make([]string, 0, len(raw))
+		for _, item := range raw {
+			if s, ok := item.(string); ok {
+				tags = append(tags, s)
+			}
+		}
+		a.Tags = NormalizeTags(tags)
+	case string:
+		a.Tags = NormalizeTags(strings.Split(raw, ","))
+	}
+}
+
+func equalStringSlices(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
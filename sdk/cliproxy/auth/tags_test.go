@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"context"
+	"testing"
+)
+
+// savingStore keeps the last saved copy of each account.
+type savingStore struct {
+	listStore
+	saved map[string]*Auth
+}
+
+func (s *savingStore) Save(_ context.Context, auth *Auth) (string, error) {
+	if s.saved == nil {
+		s.saved = make(map[string]*Auth)
+	}
+	s.saved[auth.ID] = auth.Clone()
+	return auth.ID, nil
+}
+
+func TestTagsPersistWithoutPriorMetadata(t *testing.T) {
+	store := &savingStore{}
+	m := NewManager(store, nil, nil)
+	if _, err := m.Register(context.Background(), &Auth{ID: "tagged", Provider: "gemini", Status: StatusActive}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	changed := m.UpdateMatching(context.Background(), func(*Auth) bool { return true }, func(auth *Auth) bool {
+		return auth.AddTags("Team-A", "batch")
+	})
+	if len(changed) != 1 {
+		t.Fatalf("changed = %d accounts", len(changed))
+	}
+	saved := store.saved["tagged"]
+	if saved == nil {
+		t.Fatal("tagged account was not persisted")
+	}
+
+	reloaded := NewManager(&listStore{auths: []*Auth{{ID: "tagged", Provider: "gemini", Status: StatusActive, Metadata: saved.Metadata}}}, nil, nil)
+	if err := reloaded.Load(context.Background()); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	auth, ok := reloaded.GetByID("tagged")
+	if !ok || !auth.HasTag("team-a") || !auth.HasTag("batch") {
+		t.Fatalf("reloaded tags = %v", auth.Tags)
+	}
+
+	if !auth.RemoveTags("team-a", "batch") || auth.Metadata[tagsMetadataKey] != nil {
+		t.Fatalf("removing every tag left metadata %v", auth.Metadata)
+	}
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// maxTokenExpiryPenalty keeps near-expiry accounts selectable however the penalty is configured.
+const maxTokenExpiryPenalty = 0.9
+
+// TokenExpiryPenaltyPolicy makes selection mildly prefer accounts whose token is further from
+// expiry. Within Window of expiry an account is passed over with a probability rising linearly from
+// zero to MaxPenalty at expiry; it is still used when no fresher account is available.
+type TokenExpiryPenaltyPolicy struct {
+	Window time.Duration
+	// MaxPenalty is the skip probability of an account whose token expires now, capped at 0.9.
+	MaxPenalty float64
+}
+
+// tokenPenalty holds the token expiry penalty policy and its random source.
+type tokenPenalty struct {
+	mu     sync.RWMutex
+	policy TokenExpiryPenaltyPolicy
+	rand   RandSource
+}
+
+// SetTokenExpiryPenaltyPolicy configures the selection penalty of accounts with near-expiry tokens.
+// A zero window or penalty turns it off.
+func (m *Manager) SetTokenExpiryPenaltyPolicy(policy TokenExpiryPenaltyPolicy) {
+	if m == nil {
+		return
+	}
+	policy.MaxPenalty = min(max(policy.MaxPenalty, 0), maxTokenExpiryPenalty)
+	m.tokenPenalty.mu.Lock()
+	m.tokenPenalty.policy = policy
+	m.tokenPenalty.mu.Unlock()
+}
+
+// TokenExpiryPenalty returns the selection penalty factor of auth in [0, 0.9]: the probability that
+// selection passes it over in favour of fresher accounts.
+func (m *Manager) TokenExpiryPenalty(auth *Auth, now time.Time) float64 {
+	if m == nil {
+		return 0
+	}
+	m.tokenPenalty.mu.RLock()
+	defer m.tokenPenalty.mu.RUnlock()
+	return m.tokenPenalty.factor(auth, now)
+}
+
+// factor computes the penalty of auth. Callers must hold p.mu.
+func (p *tokenPenalty) factor(auth *Auth, now time.Time) float64 {
+	if p.policy.Window <= 0 || p.policy.MaxPenalty <= 0 || auth == nil {
+		return 0
+	}
+	expiry, ok := auth.ExpirationTime()
+	if !ok {
+		return 0
+	}
+	remaining := expiry.Sub(now)
+	if remaining >= p.policy.Window {
+		return 0
+	}
+	if remaining <= 0 {
+		return p.policy.MaxPenalty
+	}
+	return p.policy.MaxPenalty * (1 - float64(remaining)/float64(p.policy.Window))
+}
+
+// thin drops each penalised candidate with its penalty probability. Blocked candidates are left for
+// the selector to skip, and the candidates are returned unchanged when no available one would remain.
+func (p *tokenPenalty) thin(model string, candidates []*Auth, now time.Time) []*Auth {
+	if len(candidates) < 2 {
+		return candidates
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.policy.Window <= 0 || p.policy.MaxPenalty <= 0 {
+		return candidates
+	}
+	source := p.rand
+	if source == nil {
+		source = globalRandSource{}
+	}
+	kept := make([]*Auth, 0, len(candidates))
+	available := 0
+	for _, candidate := range candidates {
+		if blocked, _, _ := isAuthBlockedForModel(candidate, model, now); !blocked {
+			if penalty := p.factor(candidate, now); penalty > 0 && source.Float64() < penalty {
+				continue
+			}
+			available++
+		}
+		kept = append(kept, candidate)
+	}
+	if available == 0 {
+		return candidates
+	}
+	return kept
+}
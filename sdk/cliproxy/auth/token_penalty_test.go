@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+// fixedRand returns the same value from every draw.
+type fixedRand float64
+
+func (r fixedRand) IntN(int) int     { return 0 }
+func (r fixedRand) Float64() float64 { return float64(r) }
+
+func TestTokenExpiryPenaltyPrefersFresherTokens(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&batchingExecutor{})
+	now := time.Now()
+	expiring := func(in time.Duration) map[string]any {
+		return map[string]any{"expired": now.Add(in).Format(time.RFC3339)}
+	}
+	for _, auth := range []*Auth{
+		{ID: "stale", Provider: "batchy", Metadata: expiring(6 * time.Minute)},
+		{ID: "fresh", Provider: "batchy", Metadata: expiring(time.Hour)},
+		{ID: "cooling", Provider: "batchy", Metadata: expiring(time.Hour), Unavailable: true, NextRetryAfter: now.Add(time.Hour)},
+	} {
+		if _, err := m.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	m.SetTokenExpiryPenaltyPolicy(TokenExpiryPenaltyPolicy{Window: 30 * time.Minute, MaxPenalty: 2})
+
+	stale, _ := m.GetByID("stale")
+	if got := m.TokenExpiryPenalty(stale, now); math.Abs(got-0.72) > 0.01 {
+		t.Fatalf("stale penalty = %v; want 0.72 (0.9 cap, 6 of 30 minutes left)", got)
+	}
+	fresh, _ := m.GetByID("fresh")
+	if got := m.TokenExpiryPenalty(fresh, now); got != 0 {
+		t.Fatalf("fresh penalty = %v", got)
+	}
+
+	m.tokenPenalty.rand = fixedRand(0.5)
+	kept := m.tokenPenalty.thin("", []*Auth{stale, fresh}, now)
+	if len(kept) != 1 || kept[0].ID != "fresh" {
+		t.Fatalf("kept %v; want only fresh", kept)
+	}
+	cooling, _ := m.GetByID("cooling")
+	if kept = m.tokenPenalty.thin("", []*Auth{stale, cooling}, now); len(kept) != 2 {
+		t.Fatalf("the only available account must not be dropped, kept %d", len(kept))
+	}
+	m.tokenPenalty.rand = fixedRand(0.8)
+	if kept = m.tokenPenalty.thin("", []*Auth{stale, fresh}, now); len(kept) != 2 {
+		t.Fatalf("a draw above the penalty keeps the account, kept %d", len(kept))
+	}
+
+	preview := m.PreviewNextAccount(context.Background(), "batchy", "")
+	if len(preview.TokenPenalties) != 1 || preview.TokenPenalties["stale"] < 0.7 {
+		t.Fatalf("preview penalties = %v", preview.TokenPenalties)
+	}
+}
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/tidwall/gjson"
+)
+
+// TokenUsage totals the tokens upstream responses reported for an account since the process started
+// or the counters were last reset. Counters live in memory only.
+type TokenUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+}
+
+func (u TokenUsage) isZero() bool {
+	return u.PromptTokens == 0 && u.CompletionTokens == 0 && u.TotalTokens == 0
+}
+
+// merge overlays the non-zero fields of later onto u. Streams report cumulative usage, sometimes
+// split across events, so the latest value of each field wins.
+func (u TokenUsage) merge(later TokenUsage) TokenUsage {
+	if later.PromptTokens > 0 {
+		u.PromptTokens = later.PromptTokens
+	}
+	if later.CompletionTokens > 0 {
+		u.CompletionTokens = later.CompletionTokens
+	}
+	if later.TotalTokens > 0 {
+		u.TotalTokens = later.TotalTokens
+	}
+	return u
+}
+
+// completed fills in a total the upstream did not report.
+func (u TokenUsage) completed() TokenUsage {
+	if sum := u.PromptTokens + u.CompletionTokens; u.TotalTokens < sum {
+		u.TotalTokens = sum
+	}
+	return u
+}
+
+type tokenCounters struct {
+	mu     sync.Mutex
+	byAuth map[string]*TokenUsage
+}
+
+func (c *tokenCounters) record(authID string, usage TokenUsage) {
+	if authID == "" || usage.isZero() {
+		return
+	}
+	usage = usage.completed()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.byAuth == nil {
+		c.byAuth = make(map[string]*TokenUsage)
+	}
+	totals := c.byAuth[authID]
+	if totals == nil {
+		totals = &TokenUsage{}
+		c.byAuth[authID] = totals
+	}
+	totals.PromptTokens += usage.PromptTokens
+	totals.CompletionTokens += usage.CompletionTokens
+	totals.TotalTokens += usage.TotalTokens
+}
+
+// TokenUsage returns the token counters of the account.
+func (m *Manager) TokenUsage(id string) TokenUsage {
+	if m == nil {
+		return TokenUsage{}
+	}
+	m.tokenCounts.mu.Lock()
+	defer m.tokenCounts.mu.Unlock()
+	if totals := m.tokenCounts.byAuth[id]; totals != nil {
+		return *totals
+	}
+	return TokenUsage{}
+}
+
+// ResetTokenUsage clears the token counters of the given accounts, or of every account when ids is
+// empty, and returns how many accounts had counters.
+func (m *Manager) ResetTokenUsage(ids ...string) int {
+	if m == nil {
+		return 0
+	}
+	m.tokenCounts.mu.Lock()
+	defer m.tokenCounts.mu.Unlock()
+	if len(ids) == 0 {
+		n := len(m.tokenCounts.byAuth)
+		m.tokenCounts.byAuth = nil
+		return n
+	}
+	n := 0
+	for _, id := range ids {
+		if _, ok := m.tokenCounts.byAuth[id]; ok {
+			delete(m.tokenCounts.byAuth, id)
+			n++
+		}
+	}
+	return n
+}
+
+// payloadTokenUsage extracts the usage reported by a response payload, which may hold raw JSON or
+// SSE "data:" lines. Later events override earlier ones field by field.
+func payloadTokenUsage(payload []byte) TokenUsage {
+	var usage TokenUsage
+	for _, line := range bytes.Split(payload, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if rest, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			line = bytes.TrimSpace(rest)
+		}
+		if len(line) == 0 || !gjson.ValidBytes(line) {
+			continue
+		}
+		usage = usage.merge(eventTokenUsage(gjson.ParseBytes(line)))
+	}
+	return usage
+}
+
+// eventTokenUsage reads the OpenAI, Anthropic and Gemini usage shapes, including Gemini CLI and
+// Responses API events wrapping them in "response" and Anthropic message_start events.
+func eventTokenUsage(event gjson.Result) TokenUsage {
+	if inner := event.Get("response"); inner.IsObject() {
+		event = inner
+	} else if inner = event.Get("message"); event.Get("type").String() == "message_start" && inner.IsObject() {
+		event = inner
+	}
+	if meta := event.Get("usageMetadata"); meta.IsObject() {
+		return TokenUsage{
+			PromptTokens:     meta.Get("promptTokenCount").Int(),
+			CompletionTokens: meta.Get("candidatesTokenCount").Int() + meta.Get("thoughtsTokenCount").Int(),
+			TotalTokens:      meta.Get("totalTokenCount").Int(),
+		}
+	}
+	usage := event.Get("usage")
+	if !usage.IsObject() {
+		return TokenUsage{}
+	}
+	if usage.Get("prompt_tokens").Exists() || usage.Get("completion_tokens").Exists() {
+		return TokenUsage{
+			PromptTokens:     usage.Get("prompt_tokens").Int(),
+			CompletionTokens: usage.Get("completion_tokens").Int(),
+			TotalTokens:      usage.Get("total_tokens").Int(),
+		}
+	}
+	// Anthropic reports cached input apart from input_tokens; the Responses API includes it.
+	return TokenUsage{
+		PromptTokens:     usage.Get("input_tokens").Int() + usage.Get("cache_read_input_tokens").Int() + usage.Get("cache_creation_input_tokens").Int(),
+		CompletionTokens: usage.Get("output_tokens").Int(),
+		TotalTokens:      usage.Get("total_tokens").Int(),
+	}
+}
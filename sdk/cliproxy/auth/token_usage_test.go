@@ -0,0 +1,40 @@
+package auth
+
+import "testing"
+
+func TestPayloadTokenUsageShapes(t *testing.T) {
+	cases := map[string]struct {
+		payload string
+		want    TokenUsage
+	}{
+		"openai":           {`{"choices":[],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`, TokenUsage{10, 5, 15}},
+		"anthropic":        {`{"type":"message","usage":{"input_tokens":7,"cache_read_input_tokens":3,"output_tokens":4}}`, TokenUsage{10, 4, 0}},
+		"responses":        {`{"type":"response.completed","response":{"usage":{"input_tokens":8,"output_tokens":2,"total_tokens":10}}}`, TokenUsage{8, 2, 10}},
+		"gemini":           {`{"usageMetadata":{"promptTokenCount":6,"candidatesTokenCount":3,"thoughtsTokenCount":2,"totalTokenCount":11}}`, TokenUsage{6, 5, 11}},
+		"gemini cli":       {`{"response":{"usageMetadata":{"promptTokenCount":1,"candidatesTokenCount":1,"totalTokenCount":2}}}`, TokenUsage{1, 1, 2}},
+		"anthropic stream": {"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":12,\"output_tokens\":1}}}\n\nevent: message_delta\ndata: {\"type\":\"message_delta\",\"usage\":{\"output_tokens\":30}}\n", TokenUsage{12, 30, 0}},
+		"no usage":         {`{"choices":[{"delta":{"content":"hi"}}],"usage":null}`, TokenUsage{}},
+	}
+	for name, tc := range cases {
+		if got := payloadTokenUsage([]byte(tc.payload)); got != tc.want {
+			t.Errorf("%s: got %+v, want %+v", name, got, tc.want)
+		}
+	}
+}
+
+func TestTokenUsageRecordAndReset(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.tokenCounts.record("a", TokenUsage{PromptTokens: 10, CompletionTokens: 5})
+	m.tokenCounts.record("a", TokenUsage{PromptTokens: 1, CompletionTokens: 1, TotalTokens: 2})
+	m.tokenCounts.record("b", TokenUsage{})
+
+	if got := m.TokenUsage("a"); got != (TokenUsage{PromptTokens: 11, CompletionTokens: 6, TotalTokens: 17}) {
+		t.Fatalf("unexpected usage for a: %+v", got)
+	}
+	if n := m.ResetTokenUsage("b"); n != 0 {
+		t.Fatalf("reset %d accounts without usage", n)
+	}
+	if n := m.ResetTokenUsage(); n != 1 || m.TokenUsage("a") != (TokenUsage{}) {
+		t.Fatalf("reset all returned %d", n)
+	}
+}
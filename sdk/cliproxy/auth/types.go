@@ -55,6 +55,29 @@ type Auth struct {
 	NextRetryAfter time.Time `json:"next_retry_after"`
 	// ModelStates tracks per-model runtime availability data.
 	ModelStates map[string]*ModelState `json:"model_states,omitempty"`
+	// LastUnavailableReason summarises why the auth last became unavailable; it is kept after recovery.
+	LastUnavailableReason string `json:"last_unavailable_reason,omitempty"`
+	// LastUnavailableAt records when the auth last became unavailable.
+	LastUnavailableAt time.Time `json:"last_unavailable_at"`
+	// Tags holds operator assigned labels used to organise and filter accounts.
+	Tags []string `json:"tags,omitempty"`
+	// FeatureFlags turns experimental subsystems on or off for this account, overriding the default.
+	FeatureFlags map[string]bool `json:"feature_flags,omitempty"`
+	// Priority ranks the auth for selection; higher priorities serve first and lower ones only while
+	// every higher one is unavailable.
+	Priority int `json:"priority,omitempty"`
+	// ServedSinceRotation counts selections since the last soft rotation rest (in-memory only).
+	ServedSinceRotation int `json:"-"`
+	// RotationRestUntil deprioritizes the auth for selection until this time (in-memory only).
+	RotationRestUntil time.Time `json:"-"`
+	// ClientErrors counts failures caused by malformed client requests (in-memory only).
+	ClientErrors int `json:"-"`
+	// LastClientErrorAt records the latest client-caused failure (in-memory only).
+	LastClientErrorAt time.Time `json:"-"`
+	// LastEagerRefreshAt records the latest refresh triggered before dispatching a request (in-memory only).
+	LastEagerRefreshAt time.Time `json:"-"`
+	// DuplicateOf names the account already holding the same credential (in-memory only).
+	DuplicateOf string `json:"-"`
 
 	// Runtime carries non-serialisable data used during execution (in-memory only).
 	Runtime any `json:"-"`
@@ -122,6 +145,10 @@ func (a *Auth) Clone() *Auth {
 			copyAuth.ModelStates[key] = state.Clone()
 		}
 	}
+	if len(a.Tags) > 0 {
+		copyAuth.Tags = append([]string(nil), a.Tags...)
+	}
+	copyAuth.FeatureFlags = cloneFeatureFlags(a.FeatureFlags)
 	copyAuth.Runtime = a.Runtime
 	return &copyAuth
 }
@@ -208,6 +235,97 @@ func (a *Auth) AccountInfo() (string, string) {
 	return "", ""
 }
 
+// Family returns the account family sharing an upstream quota with this auth, read from the
+// "family" attribute or metadata key. Empty means the auth does not belong to a family.
+func (a *Auth) Family() string {
+	if a == nil {
+		return ""
+	}
+	if a.Attributes != nil {
+		if v := strings.TrimSpace(a.Attributes["family"]); v != "" {
+			return v
+		}
+	}
+	if a.Metadata != nil {
+		if v, ok := a.Metadata["family"].(string); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// ModelRemap returns the account's canonical-to-upstream model name overrides read from the
+// "model_remap" metadata object. Nil means the account uses canonical names.
+func (a *Auth) ModelRemap() map[string]string {
+	if a == nil || a.Metadata == nil {
+		return nil
+	}
+	raw, ok := a.Metadata["model_remap"].(map[string]any)
+	if !ok || len(raw) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(raw))
+	for canonical, v := range raw {
+		upstream, okStr := v.(string)
+		canonical = strings.TrimSpace(canonical)
+		upstream = strings.TrimSpace(upstream)
+		if !okStr || canonical == "" || upstream == "" {
+			continue
+		}
+		out[canonical] = upstream
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// UpstreamModel maps a canonical model name to the identifier this account must send upstream.
+func (a *Auth) UpstreamModel(model string) string {
+	if upstream, ok := a.ModelRemap()[model]; ok {
+		return upstream
+	}
+	return model
+}
+
+// BillingHeaderAttrPrefix prefixes attribute keys holding billing attribution headers.
+const BillingHeaderAttrPrefix = "billing-header:"
+
+// BillingHeaders returns the cost-center/project headers sent upstream so provider billing
+// reports attribute this account's usage. They come from "billing-header:" attributes
+// (config-backed keys) and the "billing_headers" metadata object (auth files); unlike
+// generic custom headers they exist for billing attribution only.
+func (a *Auth) BillingHeaders() map[string]string {
+	if a == nil {
+		return nil
+	}
+	out := make(map[string]string)
+	if raw, ok := a.Metadata["billing_headers"].(map[string]any); ok {
+		for name, v := range raw {
+			value, okStr := v.(string)
+			name = strings.TrimSpace(name)
+			value = strings.TrimSpace(value)
+			if okStr && name != "" && value != "" {
+				out[name] = value
+			}
+		}
+	}
+	for k, v := range a.Attributes {
+		if !strings.HasPrefix(k, BillingHeaderAttrPrefix) {
+			continue
+		}
+		name := strings.TrimSpace(strings.TrimPrefix(k, BillingHeaderAttrPrefix))
+		value := strings.TrimSpace(v)
+		if name != "" && value != "" {
+			out[name] = value
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
 // ExpirationTime attempts to extract the credential expiration timestamp from metadata.
 // It inspects common keys such as "expired", "expire", "expires_at", and also
 // nested "token" objects to remain compatible with legacy auth file formats.
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// refreshingExecutor fills in the account email on refresh, or fails when the token is bad.
+type refreshingExecutor struct{ streamingExecutor }
+
+func (e *refreshingExecutor) Identifier() string { return "refreshy" }
+
+func (e *refreshingExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) {
+	if auth.Metadata["refresh_token"] != "good" {
+		return nil, errors.New("invalid_grant")
+	}
+	auth.Metadata["email"] = "user@example.com"
+	auth.Metadata["refresh_token"] = "rotated"
+	return auth, nil
+}
+
+// checkingExecutor verifies API keys read-only and refuses to refresh.
+type checkingExecutor struct{ refreshingExecutor }
+
+func (e *checkingExecutor) Identifier() string { return "checky" }
+
+func (e *checkingExecutor) Refresh(context.Context, *Auth) (*Auth, error) {
+	return nil, errors.New("refresh must not run when the credential can be checked")
+}
+
+func (e *checkingExecutor) CheckCredential(_ context.Context, auth *Auth) error {
+	switch auth.Attributes["api_key"] {
+	case "":
+		return ErrCredentialCheckUnsupported
+	case "good":
+		return nil
+	default:
+		return errors.New("invalid api key")
+	}
+}
+
+func (e *refreshingExecutor) Execute(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{}, nil
+}
+
+func TestValidateAuthDoesNotRegister(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&refreshingExecutor{})
+
+	validated, err := m.ValidateAuth(context.Background(), &Auth{ID: "candidate", Provider: "refreshy", Metadata: map[string]any{"refresh_token": "good"}})
+	if err != nil || validated.Metadata["email"] != "user@example.com" || validated.Metadata["refresh_token"] != "rotated" {
+		t.Fatalf("expected successful validation returning the rotated credential, got %v, %v", validated, err)
+	}
+	if _, ok := m.GetByID("candidate"); ok || len(m.List()) != 0 {
+		t.Fatalf("validation must not register the account")
+	}
+	if _, err = m.ValidateAuth(context.Background(), &Auth{Provider: "refreshy", Metadata: map[string]any{"refresh_token": "bad"}}); err == nil {
+		t.Fatalf("expected refresh failure to be reported")
+	}
+	if _, err = m.ValidateAuth(context.Background(), &Auth{Provider: "unknown"}); err == nil {
+		t.Fatalf("expected error for provider without executor")
+	}
+}
+
+func TestValidateAuthPrefersReadOnlyCheck(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&checkingExecutor{})
+	m.RegisterExecutor(&refreshingExecutor{})
+
+	if _, err := m.ValidateAuth(context.Background(), &Auth{Provider: "checky", Attributes: map[string]string{"api_key": "good"}}); err != nil {
+		t.Fatalf("good api key rejected: %v", err)
+	}
+	if _, err := m.ValidateAuth(context.Background(), &Auth{Provider: "checky", Attributes: map[string]string{"api_key": "bad"}}); err == nil {
+		t.Fatal("bad api key accepted")
+	}
+	if _, err := m.ValidateAuth(context.Background(), &Auth{Provider: "checky", Metadata: map[string]any{"refresh_token": "good"}}); err == nil {
+		t.Fatal("unsupported check must fall back to the refresh")
+	}
+
+	// Without a read-only check or a refresh token nothing is verified.
+	_, err := m.ValidateAuth(context.Background(), &Auth{Provider: "refreshy", Attributes: map[string]string{"api_key": "k"}})
+	var authErr *Error
+	if !errors.As(err, &authErr) || authErr.Code != "validation_unsupported" {
+		t.Fatalf("expected validation_unsupported, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// weightMetadataKey mirrors the "weight" attribute in metadata so file-backed accounts keep weights
+// set at runtime across reloads.
+const weightMetadataKey = "weight"
+
+// AccountWeight is the weighted-random selection weight of one account.
+type AccountWeight struct {
+	ID     string `json:"id"`
+	Weight int    `json:"weight"`
+}
+
+// Weight returns the selection weight of the auth, 1 when unset.
+func (a *Auth) Weight() int {
+	return authWeight(a)
+}
+
+// SetWeight replaces the auth's selection weight, mirroring it into metadata. A weight below 1
+// clears it back to the default of 1.
+func (a *Auth) SetWeight(weight int) {
+	if a == nil {
+		return
+	}
+	if weight < 1 {
+		delete(a.Attributes, weightMetadataKey)
+		delete(a.Metadata, weightMetadataKey)
+		return
+	}
+	if a.Attributes == nil {
+		a.Attributes = make(map[string]string)
+	}
+	a.Attributes[weightMetadataKey] = strconv.Itoa(weight)
+	if a.Metadata != nil {
+		a.Metadata[weightMetadataKey] = weight
+	}
+}
+
+// syncWeightFromMetadata restores a weight persisted in metadata into the "weight" attribute.
+func syncWeightFromMetadata(a *Auth) {
+	if a == nil || a.Metadata == nil {
+		return
+	}
+	var weight int
+	switch raw := a.Metadata[weightMetadataKey].(type) {
+	case int:
+		weight = raw
+	case float64:
+		weight = int(raw)
+	case string:
+		v, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return
+		}
+		weight = v
+	default:
+		return
+	}
+	if weight < 1 {
+		return
+	}
+	if a.Attributes == nil {
+		a.Attributes = make(map[string]string)
+	}
+	a.Attributes[weightMetadataKey] = strconv.Itoa(weight)
+}
+
+// SetWeights applies the given weights in one step. Every id must name a registered account and
+// every weight must be at least 1; otherwise nothing changes. It returns the weights of all accounts.
+func (m *Manager) SetWeights(ctx context.Context, weights []AccountWeight) ([]AccountWeight, error) {
+	if m == nil {
+		return nil, nil
+	}
+	m.mu.Lock()
+	var missing []string
+	for _, w := range weights {
+		if w.Weight < 1 {
+			m.mu.Unlock()
+			return nil, fmt.Errorf("weight of %s must be at least 1", w.ID)
+		}
+		if m.auths[w.ID] == nil {
+			missing = append(missing, w.ID)
+		}
+	}
+	if len(missing) > 0 {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("unknown accounts: %s", strings.Join(missing, ", "))
+	}
+	updated := make([]*Auth, 0, len(weights))
+	now := time.Now()
+	for _, w := range weights {
+		auth := m.auths[w.ID]
+		auth.SetWeight(w.Weight)
+		auth.UpdatedAt = now
+		updated = append(updated, auth.Clone())
+	}
+	out := m.weightsLocked()
+	m.mu.Unlock()
+	m.publishWeights(ctx, updated)
+	return out, nil
+}
+
+// ResetWeights sets every account to the same weight, or clears all weights back to the default
+// of 1 when weight is below 1. It returns the resulting weights.
+func (m *Manager) ResetWeights(ctx context.Context, weight int) []AccountWeight {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	updated := make([]*Auth, 0, len(m.auths))
+	now := time.Now()
+	for _, auth := range m.auths {
+		auth.SetWeight(weight)
+		auth.UpdatedAt = now
+		updated = append(updated, auth.Clone())
+	}
+	out := m.weightsLocked()
+	m.mu.Unlock()
+	m.publishWeights(ctx, updated)
+	return out
+}
+
+// Weights returns the selection weight of every account, sorted by ID.
+func (m *Manager) Weights() []AccountWeight {
+	if m == nil {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.weightsLocked()
+}
+
+func (m *Manager) weightsLocked() []AccountWeight {
+	out := make([]AccountWeight, 0, len(m.auths))
+	for id, auth := range m.auths {
+		out = append(out, AccountWeight{ID: id, Weight: auth.Weight()})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// publishWeights persists accounts whose weight changed and notifies the hook.
+func (m *Manager) publishWeights(ctx context.Context, updated []*Auth) {
+	for _, auth := range updated {
+		_ = m.persist(ctx, auth)
+		m.hook.OnAuthUpdated(ctx, auth.Clone())
+	}
+}
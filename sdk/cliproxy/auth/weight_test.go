@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSetWeightsIsAllOrNothing(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	for _, id := range []string{"a", "b"} {
+		if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: "batchy", Metadata: map[string]any{}}); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+
+	if _, err := m.SetWeights(context.Background(), []AccountWeight{{ID: "a", Weight: 5}, {ID: "missing", Weight: 2}}); err == nil {
+		t.Fatal("expected unknown id to be rejected")
+	}
+	if _, err := m.SetWeights(context.Background(), []AccountWeight{{ID: "a", Weight: 5}, {ID: "b", Weight: 0}}); err == nil {
+		t.Fatal("expected zero weight to be rejected")
+	}
+	if got := m.Weights(); got[0].Weight != 1 || got[1].Weight != 1 {
+		t.Fatalf("rejected update changed weights: %+v", got)
+	}
+
+	got, err := m.SetWeights(context.Background(), []AccountWeight{{ID: "a", Weight: 5}, {ID: "b", Weight: 2}})
+	if err != nil || len(got) != 2 || got[0] != (AccountWeight{ID: "a", Weight: 5}) || got[1] != (AccountWeight{ID: "b", Weight: 2}) {
+		t.Fatalf("SetWeights = %+v, %v", got, err)
+	}
+	auth, _ := m.GetByID("a")
+	if auth.Metadata[weightMetadataKey] != 5 {
+		t.Fatalf("weight not mirrored into metadata: %+v", auth.Metadata)
+	}
+
+	// A reload from metadata keeps the weight.
+	reloaded := &Auth{ID: "a", Provider: "batchy", Metadata: map[string]any{weightMetadataKey: float64(5)}}
+	if _, err = m.Update(context.Background(), reloaded); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if auth, _ = m.GetByID("a"); auth.Weight() != 5 {
+		t.Fatalf("weight lost on reload: %d", auth.Weight())
+	}
+
+	if got = m.ResetWeights(context.Background(), 3); got[0].Weight != 3 || got[1].Weight != 3 {
+		t.Fatalf("uniform reset = %+v", got)
+	}
+	if got = m.ResetWeights(context.Background(), 0); got[0].Weight != 1 || got[1].Weight != 1 {
+		t.Fatalf("default reset = %+v", got)
+	}
+}
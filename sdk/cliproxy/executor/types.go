@@ -7,6 +7,31 @@ import (
 	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
 )
 
+// BatchMetadataKey marks, in Options.Metadata, a request that opted into upstream batching.
+const BatchMetadataKey = "batch"
+
+// PrefixAffinityMetadataKey carries, in Options.Metadata, the prompt prefix hash used to prefer
+// the same account for requests sharing a prefix.
+const PrefixAffinityMetadataKey = "prefix_affinity"
+
+// ReservationMetadataKey names, in Options.Metadata, the account reservation a request belongs to.
+const ReservationMetadataKey = "reservation"
+
+// ClientKeyMetadataKey carries, in Options.Metadata, the client API key so selection can apply
+// per-client overrides.
+const ClientKeyMetadataKey = "client_key"
+
+// PinnedAuthMetadataKey names, in Options.Metadata, the account a request is pinned to.
+const PinnedAuthMetadataKey = "pinned_auth"
+
+// PinFallbackMetadataKey marks, in Options.Metadata, a pinned request that may fall back to normal
+// account selection when the pinned account cannot serve it.
+const PinFallbackMetadataKey = "pin_fallback"
+
+// LiveUsageMetadataKey marks, in Options.Metadata, a streaming request whose running usage is
+// reported to the accounts monitor while it streams.
+const LiveUsageMetadataKey = "live_usage"
+
 // Request encapsulates the translated payload that will be sent to a provider executor.
 type Request struct {
 	// Model is the upstream model identifier after translation.
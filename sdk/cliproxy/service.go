@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -16,6 +17,7 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/runtime/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/store"
 	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/watcher"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/wsrelay"
@@ -309,12 +311,83 @@ func (s *Service) applyCoreAuthRemoval(ctx context.Context, id string) {
 	}
 }
 
-func (s *Service) applyRetryConfig(cfg *config.Config) {
+// applyManagerPolicies configures the core manager from cfg.
+func (s *Service) applyManagerPolicies(cfg *config.Config) {
 	if s == nil || s.coreManager == nil || cfg == nil {
 		return
 	}
-	maxInterval := time.Duration(cfg.MaxRetryInterval) * time.Second
-	s.coreManager.SetRetryConfig(cfg.RequestRetry, maxInterval)
+	api.ApplyManagerPolicies(s.coreManager, cfg)
+}
+
+// runtimeStateRetryInterval is how often an unavailable runtime state backend is reopened.
+const runtimeStateRetryInterval = 30 * time.Second
+
+// applyRuntimeStateStore opens the configured runtime state backend and attaches it to the core manager.
+// Failures are logged and leave runtime state in memory only while the backend is retried periodically.
+func (s *Service) applyRuntimeStateStore(ctx context.Context, cfg *config.Config) {
+	if s == nil || s.coreManager == nil || cfg == nil {
+		return
+	}
+	backend := strings.ToLower(strings.TrimSpace(cfg.RuntimeState.Backend))
+	path := strings.TrimSpace(cfg.RuntimeState.Path)
+	var open func() (coreauth.RuntimeStateStore, error)
+	switch backend {
+	case "", "none", "memory":
+		return
+	case "file":
+		if path == "" {
+			path = filepath.Join(cfg.AuthDir, "runtime-state.json")
+		}
+		open = func() (coreauth.RuntimeStateStore, error) { return store.NewFileRuntimeStateStore(path) }
+	case "sqlite":
+		if path == "" {
+			path = filepath.Join(cfg.AuthDir, "runtime-state.db")
+		}
+		open = func() (coreauth.RuntimeStateStore, error) { return store.NewSQLiteRuntimeStateStore(ctx, path) }
+	default:
+		log.Warnf("unknown runtime-state backend %q; runtime state will not be persisted", cfg.RuntimeState.Backend)
+		return
+	}
+	interval := time.Duration(cfg.RuntimeState.FlushIntervalSeconds) * time.Second
+	s.coreManager.SetRuntimeStatsPersistence(cfg.RuntimeState.PersistStats)
+	attach := func() error {
+		stateStore, err := open()
+		if err != nil {
+			return fmt.Errorf("failed to open %s runtime state store: %w", backend, err)
+		}
+		if err = s.coreManager.SetRuntimeStateStore(ctx, stateStore, interval); err != nil {
+			_ = stateStore.Close()
+			return fmt.Errorf("failed to load runtime state from %s backend: %w", backend, err)
+		}
+		return nil
+	}
+	if err := attach(); err != nil {
+		log.Warnf("%v; serving from memory and retrying every %s", err, runtimeStateRetryInterval)
+		s.coreManager.MarkRuntimeStateUnavailable(backend, err)
+		go s.retryRuntimeStateStore(ctx, backend, attach)
+		return
+	}
+	log.Infof("runtime state persistence enabled (backend=%s, path=%s, stats=%t)", backend, path, cfg.RuntimeState.PersistStats)
+}
+
+// retryRuntimeStateStore reattaches an unavailable runtime state backend once it can be opened again.
+func (s *Service) retryRuntimeStateStore(ctx context.Context, backend string, attach func() error) {
+	ticker := time.NewTicker(runtimeStateRetryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := attach(); err != nil {
+				log.Debugf("runtime state backend still unavailable: %v", err)
+				s.coreManager.MarkRuntimeStateUnavailable(backend, err)
+				continue
+			}
+			log.Infof("runtime state persistence recovered (backend=%s)", backend)
+			return
+		}
+	}
 }
 
 func openAICompatInfoFromAuth(a *coreauth.Auth) (providerKey string, compatName string, ok bool) {
@@ -430,12 +503,13 @@ func (s *Service) Run(ctx context.Context) error {
 		return err
 	}
 
-	s.applyRetryConfig(s.cfg)
+	s.applyManagerPolicies(s.cfg)
 
 	if s.coreManager != nil {
 		if errLoad := s.coreManager.Load(ctx); errLoad != nil {
 			log.Warnf("failed to load auth store: %v", errLoad)
 		}
+		s.applyRuntimeStateStore(ctx, s.cfg)
 	}
 
 	tokenResult, err := s.tokenProvider.Load(ctx, s.cfg)
@@ -514,7 +588,7 @@ func (s *Service) Run(ctx context.Context) error {
 		if newCfg == nil {
 			return
 		}
-		s.applyRetryConfig(newCfg)
+		s.applyManagerPolicies(newCfg)
 		if s.server != nil {
 			s.server.UpdateClients(newCfg)
 		}
@@ -584,6 +658,8 @@ func (s *Service) Shutdown(ctx context.Context) error {
 		}
 		if s.coreManager != nil {
 			s.coreManager.StopAutoRefresh()
+			s.coreManager.StopReaper()
+			s.coreManager.StopRuntimeStatePersistence(ctx)
 		}
 		if s.watcher != nil {
 			if err := s.watcher.Stop(); err != nil {
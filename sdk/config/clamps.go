@@ -0,0 +1,76 @@
+package config
+
+// NumberClamp bounds a numeric request parameter. Nil bounds are not enforced.
+type NumberClamp struct {
+	Min *float64 `yaml:"min,omitempty" json:"min,omitempty"`
+	Max *float64 `yaml:"max,omitempty" json:"max,omitempty"`
+}
+
+// RequestClamp limits generation parameters requested by clients.
+type RequestClamp struct {
+	// APIKeys lists the client keys this rule applies to; an empty list makes it the default rule.
+	APIKeys []string `yaml:"api-keys,omitempty" json:"api-keys,omitempty"`
+
+	// Temperature bounds the sampling temperature.
+	Temperature *NumberClamp `yaml:"temperature,omitempty" json:"temperature,omitempty"`
+
+	// TopP bounds nucleus sampling.
+	TopP *NumberClamp `yaml:"top-p,omitempty" json:"top-p,omitempty"`
+
+	// MaxTokens caps the requested output token limit.
+	MaxTokens *int `yaml:"max-tokens,omitempty" json:"max-tokens,omitempty"`
+
+	// MaxMessages caps the number of messages in a request. Longer conversations are truncated to the
+	// system messages and the most recent turns, or rejected when Reject is set.
+	MaxMessages *int `yaml:"max-messages,omitempty" json:"max-messages,omitempty"`
+
+	// Reject fails out-of-range requests with 400 instead of clamping them silently.
+	Reject bool `yaml:"reject,omitempty" json:"reject,omitempty"`
+}
+
+// RequestClampFor returns the clamp rule applying to apiKey: a rule naming the key wins over the default rule.
+func (c *SDKConfig) RequestClampFor(apiKey string) *RequestClamp {
+	if c == nil {
+		return nil
+	}
+	var fallback *RequestClamp
+	for i := range c.RequestClamps {
+		rule := &c.RequestClamps[i]
+		if len(rule.APIKeys) == 0 {
+			if fallback == nil {
+				fallback = rule
+			}
+			continue
+		}
+		for _, key := range rule.APIKeys {
+			if key == apiKey {
+				return rule
+			}
+		}
+	}
+	return fallback
+}
+
+// ResolveRequestClamps resolves the clamp rule for every configured client key.
+// Keys without an applicable rule are omitted.
+func (c *SDKConfig) ResolveRequestClamps() map[string]RequestClamp {
+	if c == nil || len(c.RequestClamps) == 0 {
+		return nil
+	}
+	out := make(map[string]RequestClamp)
+	keys := append([]string(nil), c.APIKeys...)
+	for _, rule := range c.RequestClamps {
+		keys = append(keys, rule.APIKeys...)
+	}
+	for _, key := range keys {
+		if _, seen := out[key]; seen {
+			continue
+		}
+		if rule := c.RequestClampFor(key); rule != nil {
+			effective := *rule
+			effective.APIKeys = nil
+			out[key] = effective
+		}
+	}
+	return out
+}
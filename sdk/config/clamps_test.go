@@ -0,0 +1,28 @@
+package config
+
+import "testing"
+
+func TestResolveRequestClamps(t *testing.T) {
+	low, high := 0.0, 1.0
+	cfg := &SDKConfig{
+		APIKeys: []string{"plain", "strict"},
+		RequestClamps: []RequestClamp{
+			{Temperature: &NumberClamp{Max: &high}},
+			{APIKeys: []string{"strict", "extra"}, Temperature: &NumberClamp{Min: &low, Max: &high}, Reject: true},
+		},
+	}
+	resolved := cfg.ResolveRequestClamps()
+	if len(resolved) != 3 {
+		t.Fatalf("resolved %d keys, want plain, strict and extra: %+v", len(resolved), resolved)
+	}
+	if plain := resolved["plain"]; plain.Reject || plain.Temperature.Min != nil || plain.APIKeys != nil {
+		t.Fatalf("plain = %+v, want the default rule without its key list", plain)
+	}
+	if strict := resolved["strict"]; !strict.Reject || strict.Temperature.Min == nil {
+		t.Fatalf("strict = %+v, want its own rule", strict)
+	}
+
+	if (&SDKConfig{APIKeys: []string{"k"}}).ResolveRequestClamps() != nil {
+		t.Fatal("keys without rules resolved to a clamp")
+	}
+}
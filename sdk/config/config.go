@@ -4,6 +4,8 @@
 // debug settings, proxy configuration, and API keys.
 package config
 
+import "strings"
+
 // SDKConfig represents the application's configuration, loaded from a YAML file.
 type SDKConfig struct {
 	// ProxyURL is the URL of an optional proxy server to use for outbound requests.
@@ -15,8 +17,272 @@ type SDKConfig struct {
 	// APIKeys is a list of keys for authenticating clients to this proxy server.
 	APIKeys []string `yaml:"api-keys" json:"api-keys"`
 
+	// MaskPoolDetails replaces account ids, counts, provider names and account-specific upstream error
+	// bodies in client-facing errors with a generic reason code. Client-caused errors (e.g. 400
+	// validation failures) pass through unchanged. Full details are still logged.
+	MaskPoolDetails bool `yaml:"mask-pool-details" json:"mask-pool-details"`
+
 	// Access holds request authentication provider configuration.
 	Access AccessConfig `yaml:"auth,omitempty" json:"auth,omitempty"`
+
+	// ModelCapabilities maps model IDs to the capabilities they support for capability-based routing,
+	// used when a client requests a model named "capabilities:<cap>[,<cap>...]".
+	ModelCapabilities map[string][]string `yaml:"model-capabilities,omitempty" json:"model-capabilities,omitempty"`
+
+	// RequestClamps bounds generation parameters (temperature, top_p, max tokens) per client key.
+	RequestClamps []RequestClamp `yaml:"request-clamps,omitempty" json:"request-clamps,omitempty"`
+
+	// ClientModelPolicies restricts the models each client key may request and sets its default model.
+	ClientModelPolicies []ClientModelPolicy `yaml:"client-model-policies,omitempty" json:"client-model-policies,omitempty"`
+
+	// NormalizeRoles canonicalises request message roles before translation: aliases such as
+	// "developer" are mapped, system messages are merged into one system prompt and consecutive
+	// messages of the same role are merged.
+	NormalizeRoles bool `yaml:"normalize-roles" json:"normalize-roles"`
+
+	// PostProcessing configures normalisation passes applied to completions before they reach clients.
+	PostProcessing PostProcessing `yaml:"post-processing" json:"post-processing"`
+
+	// PrefixAffinity routes requests sharing a prompt prefix to the same account to improve provider cache hits.
+	PrefixAffinity PrefixAffinity `yaml:"prefix-affinity" json:"prefix-affinity"`
+
+	// RequestRecording samples real requests and their provider-native forms into a replayable file.
+	RequestRecording RequestRecording `yaml:"request-recording" json:"request-recording"`
+
+	// ContextLengthErrors normalises provider context-length errors and optionally suggests or routes to a
+	// model with a larger context window.
+	ContextLengthErrors ContextLengthErrors `yaml:"context-length-errors" json:"context-length-errors"`
+
+	// SeedPolicy decides what happens to a client-supplied sampling seed when a request may be served by
+	// a provider that cannot honour it: "drop" (default) forwards the request without the seed, "reject"
+	// keeps only providers that honour it and fails the request when none remain.
+	SeedPolicy string `yaml:"seed-policy,omitempty" json:"seed-policy,omitempty"`
+
+	// StopSequenceLimits enforces per-provider limits on the number of stop sequences before dispatch.
+	StopSequenceLimits StopSequenceLimits `yaml:"stop-sequence-limits" json:"stop-sequence-limits"`
+
+	// EffectiveRequestClamps reports the resolved clamp rule per client key; populated by the management API only.
+	EffectiveRequestClamps map[string]RequestClamp `yaml:"-" json:"effective-request-clamps,omitempty"`
+
+	// EffectiveClientModelPolicies reports the resolved model policy per client key; populated by the management API only.
+	EffectiveClientModelPolicies map[string]ClientModelPolicy `yaml:"-" json:"effective-client-model-policies,omitempty"`
+
+	// EffectiveContextLimits reports the context window per available model; populated by the management API only.
+	EffectiveContextLimits map[string]int `yaml:"-" json:"effective-context-limits,omitempty"`
+
+	// EffectiveSeedSupport reports whether each built-in provider honours a sampling seed; populated by the
+	// management API only.
+	EffectiveSeedSupport map[string]bool `yaml:"-" json:"effective-seed-support,omitempty"`
+
+	// EffectiveStopSequenceLimits reports how many stop sequences each built-in provider accepts, after
+	// overrides, with 0 meaning unlimited; populated by the management API only.
+	EffectiveStopSequenceLimits map[string]int `yaml:"-" json:"effective-stop-sequence-limits,omitempty"`
+}
+
+// PostProcessing toggles completion normalisation passes.
+type PostProcessing struct {
+	// TrimStopSequences cuts output at the client's requested stop sequences regardless of provider behaviour.
+	TrimStopSequences bool `yaml:"trim-stop-sequences" json:"trim-stop-sequences"`
+
+	// NormalizeFinishReason maps OpenAI chat completion finish reasons to stop, length, tool_calls or
+	// content_filter, keeping the provider's value in native_finish_reason.
+	NormalizeFinishReason bool `yaml:"normalize-finish-reason" json:"normalize-finish-reason"`
+
+	// StripProviderMetadata removes provider-specific fields from OpenAI chat completion responses.
+	StripProviderMetadata bool `yaml:"strip-provider-metadata" json:"strip-provider-metadata"`
+
+	// TruncationWarning adds a warning field, and an X-Truncated header on non-streaming responses, to
+	// OpenAI chat completions cut short by the output token limit.
+	TruncationWarning bool `yaml:"truncation-warning" json:"truncation-warning"`
+
+	// KeepProviderMetadataKeys lists client keys that keep provider metadata while stripping is on.
+	KeepProviderMetadataKeys []string `yaml:"keep-provider-metadata-keys,omitempty" json:"keep-provider-metadata-keys,omitempty"`
+
+	// APIKeys limits the passes to these client keys; empty applies them to every key.
+	APIKeys []string `yaml:"api-keys,omitempty" json:"api-keys,omitempty"`
+}
+
+// TrimStopSequencesFor reports whether stop-sequence trimming applies to the client key.
+func (p PostProcessing) TrimStopSequencesFor(apiKey string) bool {
+	return p.TrimStopSequences && p.appliesTo(apiKey)
+}
+
+// NormalizeFinishReasonFor reports whether finish reason normalisation applies to the client key.
+func (p PostProcessing) NormalizeFinishReasonFor(apiKey string) bool {
+	return p.NormalizeFinishReason && p.appliesTo(apiKey)
+}
+
+// StripProviderMetadataFor reports whether provider metadata is stripped for the client key.
+func (p PostProcessing) StripProviderMetadataFor(apiKey string) bool {
+	if !p.StripProviderMetadata || !p.appliesTo(apiKey) {
+		return false
+	}
+	for _, key := range p.KeepProviderMetadataKeys {
+		if key == apiKey {
+			return false
+		}
+	}
+	return true
+}
+
+// TruncationWarningFor reports whether truncated completions carry a warning for the client key.
+func (p PostProcessing) TruncationWarningFor(apiKey string) bool {
+	return p.TruncationWarning && p.appliesTo(apiKey)
+}
+
+func (p PostProcessing) appliesTo(apiKey string) bool {
+	if len(p.APIKeys) == 0 {
+		return true
+	}
+	for _, key := range p.APIKeys {
+		if key == apiKey {
+			return true
+		}
+	}
+	return false
+}
+
+// StopSequenceLimits configures stop sequence limit enforcement.
+type StopSequenceLimits struct {
+	// Enabled turns enforcement on. Stop sequences given as a single string are then also sent as an array.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Mode is "trim" (default), which keeps the first sequences up to the strictest limit among the
+	// providers serving the model, or "reject", which keeps only providers accepting every sequence and
+	// fails the request when none remain.
+	Mode string `yaml:"mode,omitempty" json:"mode,omitempty"`
+
+	// Limits overrides the built-in limit per provider; 0 means unlimited.
+	Limits map[string]int `yaml:"limits,omitempty" json:"limits,omitempty"`
+}
+
+// Rejects reports whether excess stop sequences fail the request instead of being trimmed.
+func (s StopSequenceLimits) Rejects() bool {
+	return strings.EqualFold(strings.TrimSpace(s.Mode), "reject")
+}
+
+// PrefixAffinity configures prompt-prefix based account affinity.
+type PrefixAffinity struct {
+	// Enabled turns prefix affinity on for the listed client keys.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// APIKeys lists the client keys that opted in; "*" opts in every key. Affinity concentrates
+	// load on fewer accounts, so an empty list leaves it off.
+	APIKeys []string `yaml:"api-keys,omitempty" json:"api-keys,omitempty"`
+
+	// PrefixBytes caps how much of the prompt prefix (tools, system and the first messages) is
+	// hashed (default 4096).
+	PrefixBytes int `yaml:"prefix-bytes,omitempty" json:"prefix-bytes,omitempty"`
+
+	// PrefixMessages is how many leading conversation messages belong to the prefix (default 2).
+	// Later turns are left out so a conversation keeps its account as it grows.
+	PrefixMessages int `yaml:"prefix-messages,omitempty" json:"prefix-messages,omitempty"`
+
+	// TTLSeconds pins a prefix to the account first selected for it for this long instead of hashing
+	// it to a fixed account. 0 keeps hashing, which never rebalances a prefix.
+	TTLSeconds int `yaml:"ttl-seconds,omitempty" json:"ttl-seconds,omitempty"`
+
+	// TTLMode is "sliding" (default; every request extends the pin) or "fixed" (the pin expires TTL
+	// after it was created).
+	TTLMode string `yaml:"ttl-mode,omitempty" json:"ttl-mode,omitempty"`
+
+	// MaxLifetimeSeconds caps how long a pin lives even while sliding, so long conversations
+	// eventually rebalance. 0 leaves sliding pins uncapped.
+	MaxLifetimeSeconds int `yaml:"max-lifetime-seconds,omitempty" json:"max-lifetime-seconds,omitempty"`
+
+	// EffectiveMode reports the resolved mode ("hash", "sliding" or "fixed"); populated by the
+	// management API only.
+	EffectiveMode string `yaml:"-" json:"effective-mode,omitempty"`
+}
+
+// Mode resolves the affinity mode: "hash" without a TTL, otherwise "fixed" or "sliding".
+func (p PrefixAffinity) Mode() string {
+	if p.TTLSeconds <= 0 {
+		return "hash"
+	}
+	if strings.EqualFold(strings.TrimSpace(p.TTLMode), "fixed") {
+		return "fixed"
+	}
+	return "sliding"
+}
+
+// EnabledFor reports whether prefix affinity applies to the client key.
+func (p PrefixAffinity) EnabledFor(apiKey string) bool {
+	if !p.Enabled {
+		return false
+	}
+	for _, key := range p.APIKeys {
+		if key == "*" || (key != "" && key == apiKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequestRecording configures sampled, redacted recording of client and upstream requests.
+type RequestRecording struct {
+	// Enabled turns recording on.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Path is the JSON Lines output file (default "recordings/requests.jsonl" under the working directory).
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+
+	// SampleRate is the fraction of matching requests recorded, between 0 and 1 (default 0.01).
+	SampleRate float64 `yaml:"sample-rate,omitempty" json:"sample-rate,omitempty"`
+
+	// APIKeys limits recording to these client keys; empty records every key.
+	APIKeys []string `yaml:"api-keys,omitempty" json:"api-keys,omitempty"`
+
+	// Accounts limits recording to upstream attempts on these auth IDs; empty records every account.
+	Accounts []string `yaml:"accounts,omitempty" json:"accounts,omitempty"`
+
+	// RedactFields lists extra JSON body keys whose values are replaced before writing. Credentials
+	// in bodies, headers and query strings are always redacted.
+	RedactFields []string `yaml:"redact-fields,omitempty" json:"redact-fields,omitempty"`
+
+	// MaxFileMB rotates the file once it grows past this size (default 50).
+	MaxFileMB int `yaml:"max-file-mb,omitempty" json:"max-file-mb,omitempty"`
+
+	// MaxBackups is how many rotated files are kept (default 3).
+	MaxBackups int `yaml:"max-backups,omitempty" json:"max-backups,omitempty"`
+}
+
+// RecordsKey reports whether requests from the client key are eligible for recording.
+func (r RequestRecording) RecordsKey(apiKey string) bool {
+	if !r.Enabled {
+		return false
+	}
+	return len(r.APIKeys) == 0 || containsString(r.APIKeys, apiKey)
+}
+
+// RecordsAccount reports whether upstream attempts on the auth are recorded.
+func (r RequestRecording) RecordsAccount(authID string) bool {
+	return len(r.Accounts) == 0 || containsString(r.Accounts, authID)
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ContextLengthErrors configures the response to requests that exceed the model's context window.
+type ContextLengthErrors struct {
+	// Enabled replaces provider context-length errors with a normalised OpenAI-style error stating the
+	// model's context limit.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// SuggestModel names the available model with the closest larger context window in the error.
+	SuggestModel bool `yaml:"suggest-model" json:"suggest-model"`
+
+	// AutoRoute retries the request once on the suggested model instead of returning the error.
+	AutoRoute bool `yaml:"auto-route" json:"auto-route"`
+
+	// Limits overrides the registry context window, in tokens, per model name.
+	Limits map[string]int `yaml:"limits,omitempty" json:"limits,omitempty"`
 }
 
 // AccessConfig groups request authentication providers.
@@ -0,0 +1,99 @@
+package config
+
+import "strings"
+
+// ClientModelPolicy restricts the models a set of client keys may request.
+type ClientModelPolicy struct {
+	// APIKeys lists the client keys this policy applies to; an empty list makes it the default policy.
+	APIKeys []string `yaml:"api-keys,omitempty" json:"api-keys,omitempty"`
+
+	// Models lists the models the keys may use. Entries match case-insensitively and may contain "*"
+	// wildcards; an empty list allows every model.
+	Models []string `yaml:"models,omitempty" json:"models,omitempty"`
+
+	// DefaultModel is used when a request does not name a model.
+	DefaultModel string `yaml:"default-model,omitempty" json:"default-model,omitempty"`
+}
+
+// Allows reports whether the policy lets its keys request model.
+func (p *ClientModelPolicy) Allows(model string) bool {
+	if p == nil || len(p.Models) == 0 {
+		return true
+	}
+	model = strings.ToLower(strings.TrimSpace(model))
+	for _, pattern := range p.Models {
+		if matchModelPattern(strings.ToLower(strings.TrimSpace(pattern)), model) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientModelPolicyFor returns the model policy applying to apiKey: a policy naming the key wins over
+// the default policy.
+func (c *SDKConfig) ClientModelPolicyFor(apiKey string) *ClientModelPolicy {
+	if c == nil {
+		return nil
+	}
+	var fallback *ClientModelPolicy
+	for i := range c.ClientModelPolicies {
+		policy := &c.ClientModelPolicies[i]
+		if len(policy.APIKeys) == 0 {
+			if fallback == nil {
+				fallback = policy
+			}
+			continue
+		}
+		for _, key := range policy.APIKeys {
+			if key == apiKey {
+				return policy
+			}
+		}
+	}
+	return fallback
+}
+
+// ResolveClientModelPolicies resolves the model policy for every configured client key.
+// Keys without an applicable policy are omitted.
+func (c *SDKConfig) ResolveClientModelPolicies() map[string]ClientModelPolicy {
+	if c == nil || len(c.ClientModelPolicies) == 0 {
+		return nil
+	}
+	out := make(map[string]ClientModelPolicy)
+	keys := append([]string(nil), c.APIKeys...)
+	for _, policy := range c.ClientModelPolicies {
+		keys = append(keys, policy.APIKeys...)
+	}
+	for _, key := range keys {
+		if _, seen := out[key]; seen {
+			continue
+		}
+		if policy := c.ClientModelPolicyFor(key); policy != nil {
+			effective := *policy
+			effective.APIKeys = nil
+			out[key] = effective
+		}
+	}
+	return out
+}
+
+// matchModelPattern matches model against pattern, where "*" matches any run of characters.
+func matchModelPattern(pattern, model string) bool {
+	if !strings.Contains(pattern, "*") {
+		return pattern == model
+	}
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(model, parts[0]) {
+		return false
+	}
+	model = model[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(model, part)
+		if idx < 0 {
+			return false
+		}
+		model = model[idx+len(part):]
+	}
+	return strings.HasSuffix(model, last)
+}